@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/services"
+)
+
+// AccountView espone le letture dell'account usate dagli endpoint /account
+// È implementato da orderprocessor.CachedAccountReader, così le richieste ripetute non colpiscono l'exchange
+type AccountView interface {
+	// GetWalletBalance recupera il saldo del wallet di un account, filtrato per coin se indicata
+	GetWalletBalance(ctx context.Context, accountType, coin string) (*models.WalletBalanceResponse, error)
+
+	// GetPositions recupera le posizioni aperte di un simbolo (tutte se vuoto)
+	GetPositions(ctx context.Context, symbol string) ([]models.Position, error)
+}
+
+// SetAccount abilita gli endpoint di lettura dell'account
+func (s *Server) SetAccount(account AccountView) {
+	s.account = account
+}
+
+// SetFees abilita l'endpoint della configurazione degli account e delle commissioni effettive
+func (s *Server) SetFees(fees *services.FeeService) {
+	s.fees = fees
+}
+
+// handleAccountBalance restituisce il saldo del wallet (GET /account/balance?account_type=UNIFIED&coin=USDT)
+func (s *Server) handleAccountBalance(w http.ResponseWriter, r *http.Request) {
+	if s.account == nil {
+		writeError(w, http.StatusServiceUnavailable, "account data is not available")
+		return
+	}
+
+	params := r.URL.Query()
+	accountType := strings.ToUpper(params.Get("account_type"))
+	if accountType == "" {
+		accountType = models.AccountTypeUnified
+	}
+
+	balance, err := s.account.GetWalletBalance(r.Context(), accountType, strings.ToUpper(params.Get("coin")))
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, balance)
+}
+
+// handleAccountPositions restituisce le posizioni aperte (GET /account/positions?symbol=DOGEUSDT)
+func (s *Server) handleAccountPositions(w http.ResponseWriter, r *http.Request) {
+	if s.account == nil {
+		writeError(w, http.StatusServiceUnavailable, "account data is not available")
+		return
+	}
+
+	positions, err := s.account.GetPositions(r.Context(), strings.ToUpper(r.URL.Query().Get("symbol")))
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, positions)
+}
+
+// handleAccountInfo restituisce configurazione degli account e commissioni effettive lette all'avvio (GET /account/info)
+func (s *Server) handleAccountInfo(w http.ResponseWriter, r *http.Request) {
+	if s.fees == nil {
+		writeError(w, http.StatusServiceUnavailable, "account info is not available")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.fees.Overview())
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/services"
+)
+
+// handleOrderAudit restituisce l'audit trail di un ordine come diff tipizzate (GET /orders/{id}/audit?limit=&offset=)
+func (s *Server) handleOrderAudit(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	diffs, err := s.orderService.GetOrderAuditDiffs(r.Context(), r.PathValue("id"), limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, diffs)
+}
+
+// handleAuditTrace restituisce le modifiche agli ordini di un ciclo o di una richiesta (GET /audit?correlation_id=&limit=&offset=)
+func (s *Server) handleAuditTrace(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	diffs, err := s.orderService.GetAuditTrace(r.Context(), r.URL.Query().Get("correlation_id"), limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, diffs)
+}
+
+// maxAuditedBodyBytes è la dimensione massima del corpo di una richiesta salvato nell'audit degli operatori
+const maxAuditedBodyBytes = 16 << 10
+
+// SetOperatorAudit abilita la registrazione delle azioni manuali: ogni richiesta che non sia GET o HEAD
+// viene salvata con autore, route, corpo ed esito
+func (s *Server) SetOperatorAudit(operatorAudit *services.OperatorAuditService) {
+	s.operatorAudit = operatorAudit
+}
+
+// withOperatorAudit registra le richieste che modificano lo stato del bot dopo averle eseguite
+// Deve stare dentro withAuth, per conoscere l'autore, e avvolgere direttamente il mux, che imposta r.Pattern
+func (s *Server) withOperatorAudit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.operatorAudit == nil || r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Il corpo viene letto per l'audit e ricostruito per l'handler
+		var body []byte
+		if r.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(r.Body, maxAuditedBodyBytes+1))
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		}
+
+		rec, ok := w.(*responseRecorder)
+		if !ok {
+			rec = &responseRecorder{ResponseWriter: w}
+		}
+		next.ServeHTTP(rec, r)
+
+		action := services.OperatorAction{
+			Actor:   "anonymous",
+			Channel: models.OperatorChannelAPI,
+			Action:  r.Pattern,
+			Target:  r.URL.Path,
+		}
+		if principal, ok := principalFromContext(r.Context()); ok {
+			action.Actor = principal.Name
+		}
+		if action.Action == "" {
+			action.Action = r.Method + " " + r.URL.Path
+		}
+		if len(body) <= maxAuditedBodyBytes && json.Valid(body) {
+			action.Details = body
+		}
+		if rec.status >= http.StatusBadRequest {
+			message := rec.message
+			if message == "" {
+				message = http.StatusText(rec.status)
+			}
+			action.Err = fmt.Errorf("%d %s", rec.status, message)
+		}
+		s.operatorAudit.Record(context.WithoutCancel(r.Context()), action)
+	})
+}
+
+// handleOperatorAudit restituisce le azioni manuali registrate, dalla più recente
+// (GET /audit/operators?actor=&action=&channel=&from=&to=&limit=&offset=)
+func (s *Server) handleOperatorAudit(w http.ResponseWriter, r *http.Request) {
+	if s.operatorAudit == nil {
+		writeError(w, http.StatusServiceUnavailable, "operator audit is not available")
+		return
+	}
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := r.URL.Query()
+	filter := repositories.OperatorAuditFilter{
+		Actor:   params.Get("actor"),
+		Action:  params.Get("action"),
+		Channel: models.OperatorChannel(params.Get("channel")),
+	}
+	from, err := parseTimeParam(params.Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, err := parseTimeParam(params.Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if from != nil {
+		filter.From = *from
+	}
+	if to != nil {
+		filter.To = *to
+	}
+
+	audits, err := s.operatorAudit.Search(r.Context(), filter, limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, audits)
+}
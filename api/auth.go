@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"cross-exchange-arbitrage/database"
+)
+
+// Role è il ruolo di un token delle REST API
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"   // Solo richieste GET
+	RoleOperator Role = "operator" // Tutte le richieste, compresi i comandi
+)
+
+// Credential è un token di accesso alle REST API con il suo ruolo
+type Credential struct {
+	Name  string // Nome dell'utente o del client
+	Role  Role
+	Token string
+}
+
+// Principal è l'utente autenticato di una richiesta
+type Principal struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+// principalKey è la chiave di contesto dell'utente autenticato
+type principalKey struct{}
+
+// SetCredentials abilita l'autenticazione: ogni richiesta deve inviare uno dei token come bearer token
+// Senza credenziali le REST API restano aperte, come quando sono esposte solo su localhost
+func (s *Server) SetCredentials(credentials []Credential) {
+	s.credentials = credentials
+}
+
+// AuthEnabled indica se le REST API richiedono un token
+func (s *Server) AuthEnabled() bool {
+	return len(s.credentials) > 0
+}
+
+// withAuth autentica la richiesta e verifica che il ruolo consenta il metodo:
+// i viewer possono solo leggere, gli operator anche inviare comandi
+// Le modifiche agli ordini vengono attribuite nell'audit trail al nome del token
+// La pagina di stato pubblica resta accessibile senza token
+func (s *Server) withAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.AuthEnabled() || r.URL.Path == publicStatusPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, ok := s.authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mkybot"`)
+			writeError(w, http.StatusUnauthorized, "invalid or missing API token")
+			return
+		}
+		if principal.Role != RoleOperator && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeError(w, http.StatusForbidden, "operator role required")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), principalKey{}, principal)
+		ctx = database.WithChangedBy(ctx, "api:"+principal.Name)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authenticate cerca il token della richiesta tra le credenziali, confrontandolo con tutte in tempo costante
+func (s *Server) authenticate(r *http.Request) (Principal, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return Principal{}, false
+	}
+
+	var principal Principal
+	found := false
+	for _, credential := range s.credentials {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(credential.Token)) == 1 {
+			principal = Principal{Name: credential.Name, Role: credential.Role}
+			found = true
+		}
+	}
+	return principal, found
+}
+
+// principalFromContext restituisce l'utente autenticato della richiesta; false se l'autenticazione è disabilitata
+func principalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// handleWhoAmI restituisce nome e ruolo del token usato (GET /auth/me)
+func (s *Server) handleWhoAmI(w http.ResponseWriter, r *http.Request) {
+	principal, ok := principalFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusServiceUnavailable, "authentication is not enabled")
+		return
+	}
+	writeJSON(w, http.StatusOK, principal)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultBasisWindow è la finestra restituita se from non è indicato
+const defaultBasisWindow = 24 * time.Hour
+
+// handleBasisSeries restituisce la serie storica del basis spot/perpetual di un simbolo
+// (GET /basis/{symbol}?spot_exchange=&perp_exchange=&from=&to=, default bybit/bybit nelle ultime 24 ore)
+func (s *Server) handleBasisSeries(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	to := time.Now().UTC()
+	if parsed, err := parseTimeParam(params.Get("to")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid to: "+err.Error())
+		return
+	} else if parsed != nil {
+		to = *parsed
+	}
+
+	from := to.Add(-defaultBasisWindow)
+	if parsed, err := parseTimeParam(params.Get("from")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid from: "+err.Error())
+		return
+	} else if parsed != nil {
+		from = *parsed
+	}
+
+	spotExchange := strings.ToLower(params.Get("spot_exchange"))
+	if spotExchange == "" {
+		spotExchange = "bybit"
+	}
+	perpExchange := strings.ToLower(params.Get("perp_exchange"))
+	if perpExchange == "" {
+		perpExchange = "bybit"
+	}
+
+	series, err := s.reportService.GetBasisSeries(r.Context(), strings.ToUpper(r.PathValue("symbol")), spotExchange, perpExchange, from, to)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, series)
+}
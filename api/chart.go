@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+
+	"cross-exchange-arbitrage/chart"
+)
+
+// SetTradeCharts abilita l'endpoint dei grafici degli ingressi, letti dalla cartella indicata
+func (s *Server) SetTradeCharts(dir string) {
+	s.chartDir = dir
+}
+
+// handleOrderChart restituisce il grafico PNG disegnato all'apertura del trade (GET /orders/{id}/chart)
+func (s *Server) handleOrderChart(w http.ResponseWriter, r *http.Request) {
+	if s.chartDir == "" {
+		writeError(w, http.StatusServiceUnavailable, "trade charts are not enabled")
+		return
+	}
+
+	path, err := chart.Path(s.chartDir, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		writeError(w, http.StatusNotFound, "chart not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to read chart")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultDataWindow è la finestra restituita se from non è indicato (le sorgenti sono per lo più giornaliere)
+const defaultDataWindow = 30 * 24 * time.Hour
+
+// handleDataSeries restituisce la serie storica di una sorgente dati esterna
+// (GET /data/{source}?from=&to=, default ultimi 30 giorni)
+func (s *Server) handleDataSeries(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	to := time.Now().UTC()
+	if parsed, err := parseTimeParam(params.Get("to")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid to: "+err.Error())
+		return
+	} else if parsed != nil {
+		to = *parsed
+	}
+
+	from := to.Add(-defaultDataWindow)
+	if parsed, err := parseTimeParam(params.Get("from")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid from: "+err.Error())
+		return
+	} else if parsed != nil {
+		from = *parsed
+	}
+
+	series, err := s.reportService.GetDataSeries(r.Context(), strings.ToLower(r.PathValue("source")), from, to)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, series)
+}
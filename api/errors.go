@@ -0,0 +1,97 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cross-exchange-arbitrage/correlation"
+	"cross-exchange-arbitrage/errorreport"
+)
+
+// requestIDHeader è l'header con l'identificativo della richiesta, riportato negli errori inviati a Sentry
+const requestIDHeader = "X-Request-ID"
+
+// SetErrorReporter invia a Sentry i panic degli handler e le risposte 500 e 502
+func (s *Server) SetErrorReporter(reporter *errorreport.Reporter) {
+	s.errors = reporter
+}
+
+// responseRecorder registra status e messaggio di errore della risposta
+type responseRecorder struct {
+	http.ResponseWriter
+	status  int
+	message string // Messaggio scritto da writeError
+}
+
+// WriteHeader registra lo status prima di inoltrarlo
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap espone il ResponseWriter originale a http.ResponseController
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// withErrorReporting assegna un identificativo a ogni richiesta e segnala panic ed errori lato server
+// L'identificativo arriva dal client nell'header X-Request-ID o viene generato, ed è restituito nella risposta;
+// viaggia nel contesto fino alle chiamate Bybit e all'audit trail degli ordini
+func (s *Server) withErrorReporting(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := correlation.Sanitize(r.Header.Get(requestIDHeader))
+		if requestID == "" {
+			requestID = correlation.NewID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+		r = r.WithContext(correlation.WithRequestID(r.Context(), requestID))
+		rec := &responseRecorder{ResponseWriter: w}
+
+		defer func() {
+			if v := recover(); v != nil {
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				correlation.Logf(r.Context(), "❌ REST API %s %s: PANIC recuperato: %v", r.Method, r.URL.Path, v)
+				s.errors.CapturePanic(v, s.errorContext(r, requestID, "panic"))
+				if rec.status == 0 {
+					writeError(rec, http.StatusInternalServerError, "internal server error")
+				}
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+
+		// 503 indica una funzione disabilitata da configurazione, non un errore da segnalare
+		if rec.status == http.StatusInternalServerError || rec.status == http.StatusBadGateway {
+			message := rec.message
+			if message == "" {
+				message = http.StatusText(rec.status)
+			}
+			correlation.Logf(r.Context(), "❌ REST API %s %s: %d %s", r.Method, r.URL.Path, rec.status, message)
+			s.errors.CaptureError(errors.New(message), s.errorContext(r, requestID, strconv.Itoa(rec.status)))
+		}
+	})
+}
+
+// errorContext descrive la richiesta; gli errori sono raggruppati per route e status, non per messaggio
+func (s *Server) errorContext(r *http.Request, requestID, kind string) errorreport.Context {
+	symbol := r.PathValue("symbol")
+	if symbol == "" {
+		symbol = r.URL.Query().Get("symbol")
+	}
+	return errorreport.Context{
+		Component:   "api",
+		Symbol:      symbol,
+		RequestID:   requestID,
+		Fingerprint: []string{"api", r.Method, r.Pattern, kind},
+		Extra: map[string]interface{}{
+			"method": r.Method,
+			"path":   r.URL.Path,
+			"query":  r.URL.RawQuery,
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"cross-exchange-arbitrage/features"
+)
+
+// featureFlagRequest è il corpo della modifica di un feature flag
+type featureFlagRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// SetFeatureFlags abilita gli endpoint dei feature flag
+func (s *Server) SetFeatureFlags(flags *features.Flags) {
+	s.features = flags
+}
+
+// handleListFeatureFlags restituisce lo stato di tutti i feature flag (GET /features)
+func (s *Server) handleListFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	if s.features == nil {
+		writeError(w, http.StatusServiceUnavailable, "feature flags are not available")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.features.States())
+}
+
+// handleSetFeatureFlag attiva o disattiva un feature flag fino al riavvio (PUT /features/{name})
+func (s *Server) handleSetFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	if s.features == nil {
+		writeError(w, http.StatusServiceUnavailable, "feature flags are not available")
+		return
+	}
+
+	var request featureFlagRequest
+	if err := decodeJSON(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if request.Enabled == nil {
+		writeError(w, http.StatusBadRequest, "enabled is required")
+		return
+	}
+
+	s.writeFeatureFlag(w, r.PathValue("name"), s.features.Set(r.PathValue("name"), *request.Enabled))
+}
+
+// handleResetFeatureFlag riporta un feature flag alla regola configurata (DELETE /features/{name})
+func (s *Server) handleResetFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	if s.features == nil {
+		writeError(w, http.StatusServiceUnavailable, "feature flags are not available")
+		return
+	}
+
+	s.writeFeatureFlag(w, r.PathValue("name"), s.features.Reset(r.PathValue("name")))
+}
+
+// writeFeatureFlag risponde con lo stato aggiornato del flag o con l'errore della modifica
+func (s *Server) writeFeatureFlag(w http.ResponseWriter, name string, err error) {
+	if errors.Is(err, features.ErrUnknownFlag) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, state := range s.features.States() {
+		if state.Name == name {
+			writeJSON(w, http.StatusOK, state)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "unknown feature flag "+name)
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/services"
+)
+
+// SetLevels abilita l'endpoint dei livelli chiave
+func (s *Server) SetLevels(levels *services.LevelsService) {
+	s.levels = levels
+}
+
+// handleKeyLevels restituisce i livelli chiave di un simbolo, da disegnare sul grafico dei prezzi
+// (GET /levels/{symbol}?at=, default adesso)
+func (s *Server) handleKeyLevels(w http.ResponseWriter, r *http.Request) {
+	if s.levels == nil {
+		writeError(w, http.StatusServiceUnavailable, "key levels are not available")
+		return
+	}
+
+	symbol := strings.ToUpper(r.PathValue("symbol"))
+	if !symbolPattern.MatchString(symbol) {
+		writeError(w, http.StatusBadRequest, "invalid symbol: "+symbol)
+		return
+	}
+
+	at := time.Now().UTC()
+	if parsed, err := parseTimeParam(r.URL.Query().Get("at")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid at: "+err.Error())
+		return
+	} else if parsed != nil {
+		at = *parsed
+	}
+
+	levels, err := s.levels.GetLevels(r.Context(), symbol, at)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, levels)
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/book"
+)
+
+// liquidationsResponse contiene i cluster di liquidazioni recenti di un simbolo
+type liquidationsResponse struct {
+	Symbol   string                    `json:"symbol"`
+	Since    time.Time                 `json:"since"`
+	Clusters []book.LiquidationCluster `json:"clusters"`
+}
+
+// SetLiquidations abilita l'endpoint delle liquidazioni
+func (s *Server) SetLiquidations(liquidations *book.LiquidationFeed) {
+	s.liquidations = liquidations
+}
+
+// handleLiquidations restituisce i cluster di liquidazioni di un simbolo terminati da since in poi
+// (GET /liquidations/{symbol}?since=, default tutte quelle conservate)
+func (s *Server) handleLiquidations(w http.ResponseWriter, r *http.Request) {
+	if s.liquidations == nil {
+		writeError(w, http.StatusServiceUnavailable, "liquidation feed is disabled")
+		return
+	}
+
+	symbol := strings.ToUpper(r.PathValue("symbol"))
+	if !symbolPattern.MatchString(symbol) {
+		writeError(w, http.StatusBadRequest, "invalid symbol: "+symbol)
+		return
+	}
+
+	since := time.Now().UTC().Add(-s.liquidations.Retention())
+	if parsed, err := parseTimeParam(r.URL.Query().Get("since")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid since: "+err.Error())
+		return
+	} else if parsed != nil {
+		since = *parsed
+	}
+
+	clusters := s.liquidations.Clusters(symbol, since)
+	if clusters == nil {
+		clusters = []book.LiquidationCluster{}
+	}
+	writeJSON(w, http.StatusOK, liquidationsResponse{Symbol: symbol, Since: since, Clusters: clusters})
+}
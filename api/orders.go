@@ -0,0 +1,172 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/reporting"
+	"cross-exchange-arbitrage/repositories"
+)
+
+// orderWithTags rappresenta un ordine con i relativi tag di journaling
+type orderWithTags struct {
+	*models.Order
+	Tags []*models.OrderTag `json:"tags"`
+}
+
+// handleListOrders restituisce una pagina di ordini (GET /orders?symbol=&limit=&sort=&order=&cursor=)
+// Per la pagina successiva passare come cursor il next_cursor della risposta
+func (s *Server) handleListOrders(w http.ResponseWriter, r *http.Request) {
+	pageQuery, err := parseOrderPageQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := s.orderService.ListOrders(r.Context(), r.URL.Query().Get("symbol"), pageQuery)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// handleSearchOrders ricerca gli ordini combinando i filtri
+// (GET /orders/search?symbol=&side=&result=&status=&from=&to=&min_pnl=&max_pnl=&tag=&q= + parametri di paginazione)
+func (s *Server) handleSearchOrders(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseOrderSearchFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pageQuery, err := parseOrderPageQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := s.orderService.SearchOrders(r.Context(), filter, pageQuery)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// parseOrderPageQuery legge limit, sort, order e cursor dalla query string
+func parseOrderPageQuery(r *http.Request) (repositories.OrderPageQuery, error) {
+	params := r.URL.Query()
+
+	limit, err := parseLimit(r)
+	if err != nil {
+		return repositories.OrderPageQuery{}, err
+	}
+	sortBy, err := repositories.ParseOrderSortField(params.Get("sort"))
+	if err != nil {
+		return repositories.OrderPageQuery{}, err
+	}
+	direction, err := repositories.ParseSortDirection(params.Get("order"))
+	if err != nil {
+		return repositories.OrderPageQuery{}, err
+	}
+
+	return repositories.OrderPageQuery{
+		SortBy:    sortBy,
+		Direction: direction,
+		Limit:     limit,
+		Cursor:    params.Get("cursor"),
+	}, nil
+}
+
+// parseOrderSearchFilter legge i filtri di ricerca dalla query string
+func parseOrderSearchFilter(r *http.Request) (repositories.OrderSearchFilter, error) {
+	params := r.URL.Query()
+	filter := repositories.OrderSearchFilter{
+		Symbol:     params.Get("symbol"),
+		Side:       models.OrderSideType(params.Get("side")),
+		Result:     models.OrderResult(params.Get("result")),
+		StatusName: params.Get("status"),
+		Tag:        params.Get("tag"),
+		ConfigHash: params.Get("config_version"),
+		Text:       params.Get("q"),
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(params.Get("from")); err != nil {
+		return filter, fmt.Errorf("invalid from: %w", err)
+	}
+	if filter.To, err = parseTimeParam(params.Get("to")); err != nil {
+		return filter, fmt.Errorf("invalid to: %w", err)
+	}
+	if filter.MinPnL, err = parseFloatParam(params.Get("min_pnl")); err != nil {
+		return filter, fmt.Errorf("invalid min_pnl: %w", err)
+	}
+	if filter.MaxPnL, err = parseFloatParam(params.Get("max_pnl")); err != nil {
+		return filter, fmt.Errorf("invalid max_pnl: %w", err)
+	}
+
+	return filter, nil
+}
+
+// handleGetOrder restituisce un ordine con i suoi tag (GET /orders/{id})
+func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("id")
+
+	order, err := s.orderService.GetOrder(r.Context(), orderID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	tags, err := s.orderService.GetOrderTags(r.Context(), orderID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, orderWithTags{Order: order, Tags: tags})
+}
+
+// handleExportOrders esporta gli ordini in CSV includendo tag e note (GET /orders/export?symbol=)
+func (s *Server) handleExportOrders(w http.ResponseWriter, r *http.Request) {
+	orders, err := s.orderService.GetOrders(r.Context(), r.URL.Query().Get("symbol"), 0, 0)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	orderIDs := make([]string, 0, len(orders))
+	for _, order := range orders {
+		orderIDs = append(orderIDs, order.OrderID)
+	}
+
+	tagsByOrder, err := s.orderService.GetOrderTagsByOrderIDs(r.Context(), orderIDs)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	filename := fmt.Sprintf("orders_%s.csv", time.Now().UTC().Format("20060102_150405"))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := reporting.WriteOrdersCSV(w, orders, tagsByOrder); err != nil {
+		log.Printf("Errore durante l'export CSV degli ordini: %v", err)
+	}
+}
+
+// handleRejectReport restituisce il numero di ordini rifiutati per motivo (GET /reports/rejections?symbol=)
+func (s *Server) handleRejectReport(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.reportService.GetRejectReasons(r.Context(), r.URL.Query().Get("symbol"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
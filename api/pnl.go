@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/services"
+)
+
+const (
+	// defaultPnLWindow è la finestra della serie storica del PnL restituita se from non è indicato
+	defaultPnLWindow = 24 * time.Hour
+
+	// pnlStreamHeartbeat è l'intervallo dei commenti inviati sullo stream per tenere aperta la connessione senza aggiornamenti
+	pnlStreamHeartbeat = 15 * time.Second
+)
+
+// SetPositionPnL abilita gli endpoint del PnL in tempo reale delle posizioni
+func (s *Server) SetPositionPnL(tracker *services.PositionPnLTracker) {
+	s.pnl = tracker
+}
+
+// handlePositionPnL restituisce l'ultimo PnL non realizzato delle posizioni aperte (GET /positions/pnl?symbol=)
+func (s *Server) handlePositionPnL(w http.ResponseWriter, r *http.Request) {
+	if s.pnl == nil {
+		writeError(w, http.StatusServiceUnavailable, "position pnl tracker is disabled")
+		return
+	}
+
+	pnls := s.pnl.All()
+	if symbol := r.URL.Query().Get("symbol"); symbol != "" {
+		pnls = s.pnl.Latest(symbol)
+	}
+	if pnls == nil {
+		pnls = []*models.PositionPnL{}
+	}
+	writeJSON(w, http.StatusOK, pnls)
+}
+
+// handlePositionPnLStream invia il PnL delle posizioni a ogni ricalcolo come Server-Sent Events
+// (GET /positions/pnl/stream?symbol=): prima l'ultimo PnL noto, poi un evento "pnl" per aggiornamento
+func (s *Server) handlePositionPnLStream(w http.ResponseWriter, r *http.Request) {
+	if s.pnl == nil {
+		writeError(w, http.StatusServiceUnavailable, "position pnl tracker is disabled")
+		return
+	}
+
+	symbol := strings.ToUpper(r.URL.Query().Get("symbol"))
+	if symbol != "" && !symbolPattern.MatchString(symbol) {
+		writeError(w, http.StatusBadRequest, "invalid symbol: "+symbol)
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	updates := s.pnl.Subscribe()
+	defer s.pnl.Unsubscribe(updates)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(pnl *models.PositionPnL) error {
+		if symbol != "" && pnl.Symbol != symbol {
+			return nil
+		}
+		data, err := json.Marshal(pnl)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: pnl\ndata: %s\n\n", data); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+
+	for _, pnl := range s.pnl.All() {
+		if err := send(pnl); err != nil {
+			return
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(pnlStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case pnl, ok := <-updates:
+			if !ok {
+				return
+			}
+			if err := send(pnl); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handlePositionPnLHistory restituisce gli snapshot periodici del PnL delle posizioni di un simbolo
+// (GET /positions/pnl/{symbol}/history?from=&to=, default ultime 24 ore)
+func (s *Server) handlePositionPnLHistory(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	to := time.Now().UTC()
+	if parsed, err := parseTimeParam(params.Get("to")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid to: "+err.Error())
+		return
+	} else if parsed != nil {
+		to = *parsed
+	}
+
+	from := to.Add(-defaultPnLWindow)
+	if parsed, err := parseTimeParam(params.Get("from")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid from: "+err.Error())
+		return
+	} else if parsed != nil {
+		from = *parsed
+	}
+
+	series, err := s.reportService.GetPositionPnLSeries(r.Context(), strings.ToUpper(r.PathValue("symbol")), from, to)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, series)
+}
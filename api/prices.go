@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// handleListPrices restituisce l'ultima vista consolidata di tutti i simboli (GET /prices)
+func (s *Server) handleListPrices(w http.ResponseWriter, r *http.Request) {
+	if s.prices == nil {
+		writeError(w, http.StatusServiceUnavailable, "price aggregator is disabled")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.prices.All())
+}
+
+// handleGetPrice restituisce miglior bid/ask consolidato di un simbolo (GET /prices/{symbol})
+// Con ?refresh=true le venue vengono interrogate subito invece di usare l'ultimo aggiornamento
+// (locale o pubblicato nella cache condivisa)
+func (s *Server) handleGetPrice(w http.ResponseWriter, r *http.Request) {
+	if s.prices == nil {
+		writeError(w, http.StatusServiceUnavailable, "price aggregator is disabled")
+		return
+	}
+
+	symbol := strings.ToUpper(r.PathValue("symbol"))
+	if price, ok := s.prices.Shared(r.Context(), symbol); ok && r.URL.Query().Get("refresh") != "true" {
+		writeJSON(w, http.StatusOK, price)
+		return
+	}
+
+	price, err := s.prices.Refresh(r.Context(), symbol)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, price)
+}
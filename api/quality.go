@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+
+	"cross-exchange-arbitrage/dataquality"
+)
+
+// SetCandleQuality abilita l'endpoint della qualità delle candele
+func (s *Server) SetCandleQuality(quality *dataquality.CandleMonitor) {
+	s.quality = quality
+}
+
+// handleCandleQuality restituisce le metriche di qualità delle candele di ogni simbolo osservato (GET /quality/candles)
+func (s *Server) handleCandleQuality(w http.ResponseWriter, r *http.Request) {
+	if s.quality == nil {
+		writeError(w, http.StatusServiceUnavailable, "candle quality monitoring is disabled")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.quality.Stats())
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/repositories"
+)
+
+// queryTimeout è il tempo massimo di esecuzione di una query della console
+const queryTimeout = 10 * time.Second
+
+// SetQueryConsole abilita la console delle query
+// Con l'autenticazione attiva la console è riservata agli operator; altrimenti è protetta dal token indicato
+// e resta disabilitata se il token è vuoto, perché le altre REST API non hanno autenticazione
+func (s *Server) SetQueryConsole(queries repositories.QueryRepository, token string) {
+	s.queries = queries
+	s.queryToken = token
+}
+
+// withQueryAuth verifica l'accesso alla console prima di eseguire l'handler
+func (s *Server) withQueryAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.queries == nil || (!s.AuthEnabled() && s.queryToken == "") {
+			writeError(w, http.StatusServiceUnavailable, "query console is not enabled")
+			return
+		}
+		if principal, ok := principalFromContext(r.Context()); ok {
+			if principal.Role != RoleOperator {
+				writeError(w, http.StatusForbidden, "operator role required")
+				return
+			}
+			next(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.queryToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="query"`)
+			writeError(w, http.StatusUnauthorized, "invalid or missing query token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleQueryTables restituisce tabelle e colonne interrogabili (GET /query/tables)
+func (s *Server) handleQueryTables(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.queries.Tables())
+}
+
+// handleQuery esegue una SELECT in sola lettura costruita da tabella, colonne, filtri e aggregazioni (POST /query)
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	var query repositories.ConsoleQuery
+	if err := decodeJSON(r, &query); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), queryTimeout)
+	defer cancel()
+
+	result, err := s.queries.Run(ctx, query)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// defaultReportWindow è il periodo dei report di performance se from non è indicato
+const defaultReportWindow = 30 * 24 * time.Hour
+
+// handlePerformanceReport restituisce le metriche di performance della curva di equity
+// (GET /reports/performance?from=&to=, live negli ultimi 30 giorni; con run_id l'intera run di backtest)
+func (s *Server) handlePerformanceReport(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	if runID := params.Get("run_id"); runID != "" {
+		metrics, err := s.reportService.GetBacktestPerformanceMetrics(r.Context(), runID)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, metrics)
+		return
+	}
+
+	from, to, ok := parseReportWindow(w, params)
+	if !ok {
+		return
+	}
+
+	metrics, err := s.reportService.GetLivePerformanceMetrics(r.Context(), from, to)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, metrics)
+}
+
+// handleBenchmarkReport confronta la curva di equity live con il buy-and-hold del simbolo
+// (GET /reports/benchmark?symbol=&from=&to=, default DOGEUSDT negli ultimi 30 giorni)
+func (s *Server) handleBenchmarkReport(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	symbol := strings.ToUpper(params.Get("symbol"))
+	if symbol == "" {
+		symbol = "DOGEUSDT"
+	}
+	if !symbolPattern.MatchString(symbol) {
+		writeError(w, http.StatusBadRequest, "invalid symbol: "+symbol)
+		return
+	}
+
+	from, to, ok := parseReportWindow(w, params)
+	if !ok {
+		return
+	}
+
+	comparison, err := s.reportService.GetBenchmarkComparison(r.Context(), symbol, models.SnapshotSourceLive, "", from, to)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, comparison)
+}
+
+// parseReportWindow legge il periodo di un report da from e to, scrivendo l'errore se non sono validi
+func parseReportWindow(w http.ResponseWriter, params url.Values) (time.Time, time.Time, bool) {
+	to := time.Now().UTC()
+	if parsed, err := parseTimeParam(params.Get("to")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid to: "+err.Error())
+		return time.Time{}, time.Time{}, false
+	} else if parsed != nil {
+		to = *parsed
+	}
+
+	from := to.Add(-defaultReportWindow)
+	if parsed, err := parseTimeParam(params.Get("from")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid from: "+err.Error())
+		return time.Time{}, time.Time{}, false
+	} else if parsed != nil {
+		from = *parsed
+	}
+
+	if !from.Before(to) {
+		writeError(w, http.StatusBadRequest, "from must be before to")
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
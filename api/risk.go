@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"regexp"
+
+	"cross-exchange-arbitrage/risk"
+)
+
+// symbolPattern accetta i simboli nel formato del bot (es. DOGEUSDT)
+var symbolPattern = regexp.MustCompile(`^[A-Z0-9]{2,30}$`)
+
+// SetRiskManager abilita gli endpoint dei limiti di rischio
+func (s *Server) SetRiskManager(manager *risk.Manager) {
+	s.risk = manager
+}
+
+// handleGetSymbolLists restituisce allow e deny list dei simboli (GET /risk/symbols)
+func (s *Server) handleGetSymbolLists(w http.ResponseWriter, r *http.Request) {
+	if s.risk == nil {
+		writeError(w, http.StatusServiceUnavailable, "risk manager is not available")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.risk.SymbolLists())
+}
+
+// handleSetSymbolLists sostituisce allow e deny list dei simboli (PUT /risk/symbols)
+// Il corpo contiene entrambe le liste: una lista omessa viene svuotata
+func (s *Server) handleSetSymbolLists(w http.ResponseWriter, r *http.Request) {
+	if s.risk == nil {
+		writeError(w, http.StatusServiceUnavailable, "risk manager is not available")
+		return
+	}
+
+	var lists risk.SymbolLists
+	if err := decodeJSON(r, &lists); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, list := range [][]string{lists.Allow, lists.Deny} {
+		for _, symbol := range list {
+			if !symbolPattern.MatchString(risk.NormalizeSymbol(symbol)) {
+				writeError(w, http.StatusBadRequest, "invalid symbol "+symbol)
+				return
+			}
+		}
+	}
+
+	s.risk.SetSymbolLists(lists)
+	writeJSON(w, http.StatusOK, s.risk.SymbolLists())
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cross-exchange-arbitrage/book"
+	"cross-exchange-arbitrage/dataquality"
+	"cross-exchange-arbitrage/errorreport"
+	"cross-exchange-arbitrage/features"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/risk"
+	"cross-exchange-arbitrage/services"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Limite di default per la paginazione delle liste
+	defaultPageLimit = 50
+
+	// Limite massimo per la paginazione delle liste
+	maxPageLimit = 500
+)
+
+// Server espone le REST API del bot
+type Server struct {
+	httpServer    *http.Server
+	orderService  *services.OrderService
+	reportService *services.ReportService
+	prices        *services.PriceAggregator      // nil se l'aggregatore è disabilitato
+	workers       WorkerController               // nil finché non viene collegato il WorkerManager
+	account       AccountView                    // nil se le credenziali dell'account non sono configurate
+	errors        *errorreport.Reporter          // nil se l'invio degli errori a Sentry è disabilitato
+	risk          *risk.Manager                  // nil finché non viene collegato il risk manager
+	levels        *services.LevelsService        // nil finché non viene collegato il calcolo dei livelli chiave
+	liquidations  *book.LiquidationFeed          // nil se il feed delle liquidazioni è disabilitato
+	pnl           *services.PositionPnLTracker   // nil se il PnL in tempo reale delle posizioni è disabilitato
+	features      *features.Flags                // nil finché non vengono collegati i feature flag
+	quality       *dataquality.CandleMonitor     // nil se la misura della qualità delle candele è disabilitata
+	fees          *services.FeeService           // nil finché non vengono collegate le commissioni effettive
+	queries       repositories.QueryRepository   // nil se la console delle query è disabilitata
+	queryToken    string                         // Bearer token richiesto dalla console delle query
+	credentials   []Credential                   // Token di accesso con ruolo; vuoto = API senza autenticazione
+	operatorAudit *services.OperatorAuditService // nil finché non viene collegato l'audit delle azioni manuali
+	publicStatus  *publicStatusPage              // nil se la pagina di stato pubblica è disabilitata
+	chartDir      string                         // Cartella dei grafici degli ingressi; vuota se disabilitati
+}
+
+// NewServer crea una nuova istanza di Server in ascolto sull'indirizzo indicato (es. ":8080")
+func NewServer(addr string, orderService *services.OrderService, reportService *services.ReportService, prices *services.PriceAggregator) *Server {
+	s := &Server{
+		orderService:  orderService,
+		reportService: reportService,
+		prices:        prices,
+	}
+
+	s.httpServer = &http.Server{
+		Addr:              addr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s
+}
+
+// routes registra tutti gli endpoint
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// Ordini
+	mux.HandleFunc("GET /orders", s.handleListOrders)
+	mux.HandleFunc("GET /orders/export", s.handleExportOrders)
+	mux.HandleFunc("GET /orders/search", s.handleSearchOrders)
+	mux.HandleFunc("GET /orders/{id}", s.handleGetOrder)
+	mux.HandleFunc("GET /orders/{id}/audit", s.handleOrderAudit)
+	mux.HandleFunc("GET /orders/{id}/chart", s.handleOrderChart)
+	mux.HandleFunc("GET /orders/{id}/signal", s.handleOrderSignal)
+	mux.HandleFunc("GET /signals", s.handleSignals)
+	mux.HandleFunc("GET /audit", s.handleAuditTrace)
+	mux.HandleFunc("GET /audit/operators", s.handleOperatorAudit)
+
+	// Tag e note
+	mux.HandleFunc("GET /orders/{id}/tags", s.handleListOrderTags)
+	mux.HandleFunc("POST /orders/{id}/tags", s.handleCreateOrderTag)
+	mux.HandleFunc("DELETE /orders/{id}/tags/{tagID}", s.handleDeleteOrderTag)
+
+	// Report
+	mux.HandleFunc("GET /reports/tags", s.handleTagReport)
+	mux.HandleFunc("GET /reports/rejections", s.handleRejectReport)
+	mux.HandleFunc("GET /reports/performance", s.handlePerformanceReport)
+	mux.HandleFunc("GET /reports/benchmark", s.handleBenchmarkReport)
+	mux.HandleFunc("GET /basis/{symbol}", s.handleBasisSeries)
+	mux.HandleFunc("GET /data/{source}", s.handleDataSeries)
+	mux.HandleFunc("GET /levels/{symbol}", s.handleKeyLevels)
+	mux.HandleFunc("GET /liquidations/{symbol}", s.handleLiquidations)
+	mux.HandleFunc("GET /quality/candles", s.handleCandleQuality)
+
+	// Prezzi consolidati tra venue
+	mux.HandleFunc("GET /prices", s.handleListPrices)
+	mux.HandleFunc("GET /prices/{symbol}", s.handleGetPrice)
+
+	// Saldo e posizioni dell'account, serviti dalla cache
+	mux.HandleFunc("GET /account/balance", s.handleAccountBalance)
+	mux.HandleFunc("GET /account/positions", s.handleAccountPositions)
+	mux.HandleFunc("GET /account/info", s.handleAccountInfo)
+
+	// PnL non realizzato delle posizioni, ricalcolato sul mark price
+	mux.HandleFunc("GET /positions/pnl", s.handlePositionPnL)
+	mux.HandleFunc("GET /positions/pnl/stream", s.handlePositionPnLStream)
+	mux.HandleFunc("GET /positions/pnl/{symbol}/history", s.handlePositionPnLHistory)
+
+	// Console delle query in sola lettura, protetta da token
+	mux.HandleFunc("GET /query/tables", s.withQueryAuth(s.handleQueryTables))
+	mux.HandleFunc("POST /query", s.withQueryAuth(s.handleQuery))
+
+	// Controllo dei worker a runtime
+	mux.HandleFunc("GET /workers", s.handleListWorkers)
+	mux.HandleFunc("POST /workers/{name}/pause", s.handlePauseWorker)
+	mux.HandleFunc("POST /workers/{name}/resume", s.handleResumeWorker)
+	mux.HandleFunc("POST /workers/{name}/run", s.handleRunWorker)
+
+	// Limiti di rischio modificabili a runtime
+	mux.HandleFunc("GET /risk/symbols", s.handleGetSymbolLists)
+	mux.HandleFunc("PUT /risk/symbols", s.handleSetSymbolLists)
+
+	// Feature flag modificabili a runtime
+	mux.HandleFunc("GET /features", s.handleListFeatureFlags)
+	mux.HandleFunc("PUT /features/{name}", s.handleSetFeatureFlag)
+	mux.HandleFunc("DELETE /features/{name}", s.handleResetFeatureFlag)
+
+	// Utente autenticato
+	mux.HandleFunc("GET /auth/me", s.handleWhoAmI)
+
+	// Pagina di stato pubblica, senza autenticazione
+	mux.HandleFunc("GET "+publicStatusPath, s.handlePublicStatus)
+
+	return s.withErrorReporting(s.withAuth(s.withOperatorAudit(mux)))
+}
+
+// Start avvia il server HTTP in background
+func (s *Server) Start() {
+	go func() {
+		log.Printf("🌐 REST API in ascolto su %s", s.httpServer.Addr)
+		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("❌ Errore server REST API: %v", err)
+		}
+	}()
+}
+
+// Shutdown ferma il server attendendo la chiusura delle richieste in corso
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// errorResponse rappresenta il corpo di una risposta di errore
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON serializza la risposta in JSON
+func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		log.Printf("Errore serializzazione risposta API: %v", err)
+	}
+}
+
+// writeError scrive una risposta di errore JSON
+func writeError(w http.ResponseWriter, status int, message string) {
+	if rec, ok := w.(*responseRecorder); ok {
+		rec.message = message
+	}
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// writeServiceError traduce un errore dei servizi nello status HTTP appropriato
+func writeServiceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrInvalidInput) || errors.Is(err, repositories.ErrInvalidCursor) || errors.Is(err, repositories.ErrInvalidQuery) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
+// decodeJSON decodifica il corpo della richiesta
+func decodeJSON(r *http.Request, dest interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dest); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// parsePagination legge limit e offset dalla query string
+func parsePagination(r *http.Request) (int, int, error) {
+	limit, err := parseLimit(r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	offset := 0
+	if value := r.URL.Query().Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return 0, 0, fmt.Errorf("invalid offset: %s", value)
+		}
+		offset = parsed
+	}
+
+	return limit, offset, nil
+}
+
+// parseLimit legge il limite di elementi per pagina dalla query string
+func parseLimit(r *http.Request) (int, error) {
+	limit := defaultPageLimit
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return 0, fmt.Errorf("invalid limit: %s", value)
+		}
+		limit = parsed
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	return limit, nil
+}
+
+// parseTimeParam interpreta una data in formato RFC3339 o YYYY-MM-DD (UTC); vuoto = nil
+func parseTimeParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return &parsed, nil
+		}
+	}
+	return nil, fmt.Errorf("expected RFC3339 or YYYY-MM-DD, got %s", value)
+}
+
+// parseFloatParam interpreta un numero decimale; vuoto = nil
+func parseFloatParam(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
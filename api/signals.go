@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+)
+
+// handleOrderSignal restituisce la spiegazione del segnale che ha aperto l'ordine (GET /orders/{id}/signal)
+func (s *Server) handleOrderSignal(w http.ResponseWriter, r *http.Request) {
+	explanation, err := s.orderService.GetOrderSignal(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, explanation)
+}
+
+// handleSignals restituisce le decisioni delle strategie sui segnali, con le condizioni valutate, dalla più recente
+// (GET /signals?strategy=&symbol=&outcome=&from=&to=&limit=&offset=)
+func (s *Server) handleSignals(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params := r.URL.Query()
+	filter := repositories.SignalDecisionFilter{
+		Strategy: params.Get("strategy"),
+		Symbol:   strings.ToUpper(params.Get("symbol")),
+		Outcome:  models.SignalOutcome(params.Get("outcome")),
+	}
+	from, err := parseTimeParam(params.Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, err := parseTimeParam(params.Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if from != nil {
+		filter.From = *from
+	}
+	if to != nil {
+		filter.To = *to
+	}
+	signals, err := s.orderService.SearchSignals(r.Context(), filter, limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, signals)
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// publicStatusPath è il percorso della pagina di stato pubblica, esclusa dall'autenticazione
+	publicStatusPath = "/public/status"
+
+	// publicStatusCacheTTL è la durata della cache di equity e posizioni, così le richieste ripetute
+	// non colpiscono database ed exchange
+	publicStatusCacheTTL = time.Minute
+
+	// publicStatusTimeout è il tempo massimo per leggere equity e posizioni
+	publicStatusTimeout = 10 * time.Second
+)
+
+// publicStatusPeriods sono i periodi su cui viene calcolata la variazione dell'equity
+var publicStatusPeriods = []struct {
+	label  string
+	period time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// PublicStatus è lo stato del bot condivisibile senza autenticazione:
+// nessun saldo, dimensione o prezzo, solo variazioni percentuali e conteggi
+type PublicStatus struct {
+	Status          string              `json:"status"`
+	StartedAt       time.Time           `json:"started_at"`
+	UptimeSeconds   int64               `json:"uptime_seconds"`
+	EquityChangePct map[string]*float64 `json:"equity_change_pct"`        // Rendimento time-weighted per periodo, null senza snapshot sufficienti
+	OpenPositions   *int                `json:"open_positions,omitempty"` // Assente se i dati dell'account non sono disponibili
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+// publicStatusPage contiene il limitatore delle richieste e la cache della pagina di stato pubblica
+type publicStatusPage struct {
+	startedAt time.Time
+	limit     int // Richieste al minuto per indirizzo IP
+
+	mu     sync.Mutex
+	window time.Time      // Inizio del minuto corrente
+	hits   map[string]int // Richieste per indirizzo IP nel minuto corrente
+
+	cacheMu   sync.Mutex
+	cached    *PublicStatus
+	fetchedAt time.Time
+}
+
+// SetPublicStatus abilita GET /public/status, accessibile senza token anche quando l'autenticazione è attiva
+// Ogni indirizzo IP può fare al massimo ratePerMinute richieste al minuto; l'uptime parte da questa chiamata
+func (s *Server) SetPublicStatus(ratePerMinute int) {
+	s.publicStatus = &publicStatusPage{
+		startedAt: time.Now(),
+		limit:     ratePerMinute,
+		hits:      make(map[string]int),
+	}
+}
+
+// allow registra una richiesta di ip e indica se rientra nel limite del minuto corrente
+func (p *publicStatusPage) allow(ip string, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if window := now.Truncate(time.Minute); !window.Equal(p.window) {
+		p.window = window
+		p.hits = make(map[string]int)
+	}
+	p.hits[ip]++
+	return p.hits[ip] <= p.limit
+}
+
+// handlePublicStatus restituisce uptime, variazione dell'equity e numero di posizioni aperte (GET /public/status)
+// Senza pagina di stato abilitata risponde 404, così l'endpoint non rivela nulla
+func (s *Server) handlePublicStatus(w http.ResponseWriter, r *http.Request) {
+	page := s.publicStatus
+	if page == nil {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	now := time.Now()
+	if !page.allow(clientIP(r), now) {
+		w.Header().Set("Retry-After", strconv.Itoa(60-now.Second()))
+		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+
+	status := s.cachedPublicStatus(r.Context(), now)
+	status.UptimeSeconds = int64(now.Sub(page.startedAt).Seconds())
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	writeJSON(w, http.StatusOK, status)
+}
+
+// cachedPublicStatus restituisce una copia dello stato, ricalcolandolo se la cache è scaduta
+// Il lock resta preso durante il calcolo, così richieste concorrenti non ripetono le letture
+func (s *Server) cachedPublicStatus(ctx context.Context, now time.Time) PublicStatus {
+	page := s.publicStatus
+	page.cacheMu.Lock()
+	defer page.cacheMu.Unlock()
+
+	if page.cached == nil || now.Sub(page.fetchedAt) >= publicStatusCacheTTL {
+		page.cached = s.buildPublicStatus(ctx, now)
+		page.fetchedAt = now
+	}
+	return *page.cached
+}
+
+// buildPublicStatus legge rendimenti e posizioni aperte; una lettura fallita lascia il valore vuoto
+func (s *Server) buildPublicStatus(ctx context.Context, now time.Time) *PublicStatus {
+	ctx, cancel := context.WithTimeout(ctx, publicStatusTimeout)
+	defer cancel()
+
+	status := &PublicStatus{
+		Status:          "ok",
+		StartedAt:       s.publicStatus.startedAt,
+		EquityChangePct: make(map[string]*float64, len(publicStatusPeriods)),
+		UpdatedAt:       now,
+	}
+
+	for _, p := range publicStatusPeriods {
+		status.EquityChangePct[p.label] = nil
+		if s.reportService == nil {
+			continue
+		}
+		metrics, err := s.reportService.GetLivePerformanceMetrics(ctx, now.Add(-p.period), now)
+		if err != nil {
+			continue
+		}
+		change := metrics.TotalReturn
+		status.EquityChangePct[p.label] = &change
+	}
+
+	if s.account != nil {
+		positions, err := s.account.GetPositions(ctx, "")
+		if err != nil {
+			log.Printf("⚠️  Posizioni non disponibili per la pagina di stato pubblica: %v", err)
+		} else {
+			open := 0
+			for i := range positions {
+				if positions[i].IsActive() {
+					open++
+				}
+			}
+			status.OpenPositions = &open
+		}
+	}
+	return status
+}
+
+// clientIP restituisce l'indirizzo IP del client dalla connessione
+// X-Forwarded-For viene ignorato perché il client potrebbe impostarlo per aggirare il limite
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// createOrderTagRequest rappresenta il corpo della richiesta di creazione di un tag
+type createOrderTagRequest struct {
+	Tag       string `json:"tag"`
+	Note      string `json:"note"`
+	CreatedBy string `json:"created_by"`
+}
+
+// handleListOrderTags restituisce i tag di un ordine (GET /orders/{id}/tags)
+func (s *Server) handleListOrderTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := s.orderService.GetOrderTags(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tags)
+}
+
+// handleCreateOrderTag aggiunge un tag e/o una nota ad un ordine (POST /orders/{id}/tags)
+func (s *Server) handleCreateOrderTag(w http.ResponseWriter, r *http.Request) {
+	var req createOrderTagRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Tag == "" && req.Note == "" {
+		writeError(w, http.StatusBadRequest, "tag or note is required")
+		return
+	}
+	if principal, ok := principalFromContext(r.Context()); ok {
+		// Con l'autenticazione attiva l'autore è il nome del token, non quello dichiarato dal client
+		req.CreatedBy = principal.Name
+	} else if req.CreatedBy == "" {
+		req.CreatedBy = "api"
+	}
+
+	tag, err := s.orderService.AddOrderTag(r.Context(), r.PathValue("id"), req.Tag, req.Note, req.CreatedBy)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, tag)
+}
+
+// handleDeleteOrderTag elimina un tag di un ordine (DELETE /orders/{id}/tags/{tagID})
+func (s *Server) handleDeleteOrderTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := strconv.ParseUint(r.PathValue("tagID"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid tag ID")
+		return
+	}
+
+	if err := s.orderService.RemoveOrderTag(r.Context(), r.PathValue("id"), uint(tagID)); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleTagReport restituisce le statistiche di trading per tag (GET /reports/tags?symbol=)
+func (s *Server) handleTagReport(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.reportService.GetTagPerformance(r.Context(), r.URL.Query().Get("symbol"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrWorkerNotFound indica un worker non registrato
+	ErrWorkerNotFound = errors.New("worker not found")
+	// ErrWorkerConflict indica un'operazione non applicabile allo stato del worker (es. già in pausa o in esecuzione)
+	ErrWorkerConflict = errors.New("worker state conflict")
+)
+
+// WorkerStatus rappresenta lo stato di un worker registrato
+type WorkerStatus struct {
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	Schedule     string     `json:"schedule"`
+	DependsOn    []string   `json:"depends_on,omitempty"`
+	LeaderOnly   bool       `json:"leader_only,omitempty"`
+	Standby      bool       `json:"standby,omitempty"` // Istanza in standby: il worker aggiorna solo lo stato
+	Enabled      bool       `json:"enabled"`
+	Paused       bool       `json:"paused"`
+	Running      bool       `json:"running"`
+	NextRun      *time.Time `json:"next_run,omitempty"`
+	LastRun      *time.Time `json:"last_run,omitempty"`
+	LastDuration string     `json:"last_duration,omitempty"`
+}
+
+// WorkerController permette di controllare i worker a runtime senza riavviare il processo
+// È implementato dal WorkerManager
+type WorkerController interface {
+	// Workers restituisce lo stato di tutti i worker registrati
+	Workers() []WorkerStatus
+
+	// PauseWorker sospende le esecuzioni pianificate di un worker
+	PauseWorker(name string) error
+
+	// ResumeWorker riprende le esecuzioni pianificate di un worker in pausa
+	ResumeWorker(name string) error
+
+	// RunWorkerNow avvia subito un ciclo fuori schedule
+	RunWorkerNow(name string) error
+}
+
+// SetWorkerController abilita gli endpoint di controllo dei worker
+func (s *Server) SetWorkerController(workers WorkerController) {
+	s.workers = workers
+}
+
+// handleListWorkers restituisce lo stato dei worker (GET /workers)
+func (s *Server) handleListWorkers(w http.ResponseWriter, r *http.Request) {
+	if s.workers == nil {
+		writeError(w, http.StatusServiceUnavailable, "worker control is not available")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.workers.Workers())
+}
+
+// handlePauseWorker sospende un worker (POST /workers/{name}/pause)
+func (s *Server) handlePauseWorker(w http.ResponseWriter, r *http.Request) {
+	s.controlWorker(w, r, WorkerController.PauseWorker)
+}
+
+// handleResumeWorker riprende un worker in pausa (POST /workers/{name}/resume)
+func (s *Server) handleResumeWorker(w http.ResponseWriter, r *http.Request) {
+	s.controlWorker(w, r, WorkerController.ResumeWorker)
+}
+
+// handleRunWorker avvia subito un ciclo del worker (POST /workers/{name}/run)
+func (s *Server) handleRunWorker(w http.ResponseWriter, r *http.Request) {
+	s.controlWorker(w, r, WorkerController.RunWorkerNow)
+}
+
+// controlWorker applica un'operazione al worker indicato nel path e restituisce il suo nuovo stato
+func (s *Server) controlWorker(w http.ResponseWriter, r *http.Request, action func(workers WorkerController, name string) error) {
+	if s.workers == nil {
+		writeError(w, http.StatusServiceUnavailable, "worker control is not available")
+		return
+	}
+
+	name := r.PathValue("name")
+	if err := action(s.workers, name); err != nil {
+		switch {
+		case errors.Is(err, ErrWorkerNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrWorkerConflict):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	for _, status := range s.workers.Workers() {
+		if status.Name == name {
+			writeJSON(w, http.StatusAccepted, status)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
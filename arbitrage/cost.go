@@ -0,0 +1,202 @@
+package arbitrage
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// DepthSource fornisce l'ultimo order book di una venue (es. book.Store alimentato dallo streamer)
+type DepthSource interface {
+	Get(exchange, symbol string) (*models.OrderBookData, bool)
+}
+
+// FeeSource fornisce le commissioni taker effettive dell'account per simbolo (es. services.FeeService)
+type FeeSource interface {
+	TakerFee(exchange, symbol string) (float64, bool)
+}
+
+// CostConfig contiene i parametri del modello di costo di esecuzione
+type CostConfig struct {
+	TakerFees         map[string]float64       // Fee taker per venue (0.00055 = 0.055%)
+	DefaultTakerFee   float64                  // Fee usata per le venue senza fee configurata
+	Fees              FeeSource                // Fee effettive dell'account, prioritarie su quelle configurate; nil se non disponibili
+	OrderLatency      map[string]time.Duration // Latenza di invio ordine per venue
+	DriftBpsPerSecond float64                  // Movimento avverso atteso del prezzo per secondo di latenza, in basis point
+	MaxBookAge        time.Duration            // Età massima di un order book per stimare lo slippage (0 = nessun limite)
+}
+
+// LegCost rappresenta il costo atteso di una gamba dell'arbitraggio
+type LegCost struct {
+	Exchange    string           `json:"exchange"`
+	Side        models.OrderSide `json:"side"`
+	Quantity    float64          `json:"quantity"`     // Quantità eseguibile (ridotta se la profondità non basta)
+	TopPrice    float64          `json:"top_price"`    // Miglior prezzo quotato
+	FillPrice   float64          `json:"fill_price"`   // Prezzo medio atteso consumando il book
+	Slippage    float64          `json:"slippage"`     // Costo dello slippage in valuta di quotazione
+	Fee         float64          `json:"fee"`          // Fee taker in valuta di quotazione
+	Latency     time.Duration    `json:"latency"`      // Latenza attesa (dati + ordine)
+	LatencyCost float64          `json:"latency_cost"` // Movimento avverso atteso durante la latenza
+	DepthBased  bool             `json:"depth_based"`  // true se lo slippage è stimato sulla profondità del book
+}
+
+// Total restituisce il costo complessivo della gamba
+func (lc LegCost) Total() float64 {
+	return lc.Slippage + lc.Fee + lc.LatencyCost
+}
+
+// CostModel stima il costo di esecuzione di un ordine taker su una venue
+// combinando fee, slippage sulla profondità del book e latenza osservata
+type CostModel struct {
+	cfg     CostConfig
+	depth   DepthSource // nil se non è disponibile la profondità
+	latency *LatencyTracker
+}
+
+// NewCostModel crea un nuovo modello di costo; depth può essere nil
+func NewCostModel(cfg CostConfig, depth DepthSource, latency *LatencyTracker) *CostModel {
+	if latency == nil {
+		latency = NewLatencyTracker(0.2)
+	}
+	return &CostModel{cfg: cfg, depth: depth, latency: latency}
+}
+
+// Latency restituisce il tracker delle latenze osservate
+func (m *CostModel) Latency() *LatencyTracker {
+	return m.latency
+}
+
+// TakerFee restituisce la fee taker di un simbolo su una venue: quella effettiva dell'account se nota,
+// altrimenti quella configurata per la venue
+func (m *CostModel) TakerFee(exchange, symbol string) float64 {
+	if m.cfg.Fees != nil {
+		if fee, ok := m.cfg.Fees.TakerFee(exchange, symbol); ok {
+			return fee
+		}
+	}
+	if fee, ok := m.cfg.TakerFees[strings.ToLower(exchange)]; ok {
+		return fee
+	}
+	return m.cfg.DefaultTakerFee
+}
+
+// ExpectedLatency restituisce la latenza attesa di una venue: ritardo osservato dei dati più invio dell'ordine
+func (m *CostModel) ExpectedLatency(exchange string) time.Duration {
+	return m.latency.Latency(exchange) + m.cfg.OrderLatency[strings.ToLower(exchange)]
+}
+
+// LegCost stima il costo di un ordine taker di quantity su una venue
+// topPrice e topLiquidity sono il miglior livello quotato, usati se la profondità non è disponibile
+func (m *CostModel) LegCost(exchange, symbol string, side models.OrderSide, quantity, topPrice, topLiquidity float64) LegCost {
+	leg := LegCost{
+		Exchange:  exchange,
+		Side:      side,
+		Quantity:  quantity,
+		TopPrice:  topPrice,
+		FillPrice: topPrice,
+	}
+
+	if levels, ok := m.bookSide(exchange, symbol, side); ok {
+		fillPrice, filled := EstimateFill(levels, quantity)
+		if filled > 0 {
+			leg.FillPrice = fillPrice
+			leg.Quantity = filled
+			leg.DepthBased = true
+		}
+	}
+	if !leg.DepthBased && topLiquidity > 0 && leg.Quantity > topLiquidity {
+		// Senza profondità si esegue solo la liquidità del miglior livello
+		leg.Quantity = topLiquidity
+	}
+
+	notional := leg.FillPrice * leg.Quantity
+	if side == models.OrderSideBuy {
+		leg.Slippage = (leg.FillPrice - topPrice) * leg.Quantity
+	} else {
+		leg.Slippage = (topPrice - leg.FillPrice) * leg.Quantity
+	}
+	leg.Fee = notional * m.TakerFee(exchange, symbol)
+	leg.Latency = m.ExpectedLatency(exchange)
+	leg.LatencyCost = notional * m.cfg.DriftBpsPerSecond / 10000 * leg.Latency.Seconds()
+
+	return leg
+}
+
+// bookSide restituisce il lato del book consumato da un ordine (ask per gli acquisti, bid per le vendite)
+func (m *CostModel) bookSide(exchange, symbol string, side models.OrderSide) ([]models.OrderBookLevel, bool) {
+	if m.depth == nil {
+		return nil, false
+	}
+	data, ok := m.depth.Get(exchange, symbol)
+	if !ok || (m.cfg.MaxBookAge > 0 && time.Since(data.Timestamp) > m.cfg.MaxBookAge) {
+		return nil, false
+	}
+	if side == models.OrderSideBuy {
+		return data.Asks, len(data.Asks) > 0
+	}
+	return data.Bids, len(data.Bids) > 0
+}
+
+// EstimateFill calcola il prezzo medio di esecuzione di quantity consumando i livelli in ordine
+// Restituisce la quantità eseguibile, inferiore a quantity se la profondità non basta
+func EstimateFill(levels []models.OrderBookLevel, quantity float64) (float64, float64) {
+	var filled, notional float64
+	for _, level := range levels {
+		if filled >= quantity {
+			break
+		}
+		take := level.Quantity
+		if remaining := quantity - filled; take > remaining {
+			take = remaining
+		}
+		filled += take
+		notional += take * level.Price
+	}
+	if filled == 0 {
+		return 0, 0
+	}
+	return notional / filled, filled
+}
+
+// LatencyTracker mantiene una media mobile esponenziale della latenza osservata per venue
+type LatencyTracker struct {
+	mu      sync.RWMutex
+	alpha   float64
+	latency map[string]time.Duration
+}
+
+// NewLatencyTracker crea un tracker; alpha è il peso delle nuove osservazioni (0-1)
+func NewLatencyTracker(alpha float64) *LatencyTracker {
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.2
+	}
+	return &LatencyTracker{alpha: alpha, latency: make(map[string]time.Duration)}
+}
+
+// Observe registra una nuova osservazione di latenza per una venue
+func (t *LatencyTracker) Observe(exchange string, latency time.Duration) {
+	if latency < 0 {
+		latency = 0
+	}
+	exchange = strings.ToLower(exchange)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current, ok := t.latency[exchange]
+	if !ok {
+		t.latency[exchange] = latency
+		return
+	}
+	t.latency[exchange] = time.Duration(t.alpha*float64(latency) + (1-t.alpha)*float64(current))
+}
+
+// Latency restituisce la latenza media osservata di una venue (0 se non osservata)
+func (t *LatencyTracker) Latency(exchange string) time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.latency[strings.ToLower(exchange)]
+}
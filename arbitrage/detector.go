@@ -0,0 +1,119 @@
+package arbitrage
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// Opportunity rappresenta un arbitraggio cross-exchange al netto dei costi di esecuzione
+// Si compra sull'ask di BuyExchange e si vende sul bid di SellExchange
+type Opportunity struct {
+	Symbol       string    `json:"symbol"`
+	BuyExchange  string    `json:"buy_exchange"`
+	SellExchange string    `json:"sell_exchange"`
+	Quantity     float64   `json:"quantity"`
+	Buy          LegCost   `json:"buy"`
+	Sell         LegCost   `json:"sell"`
+	GrossProfit  float64   `json:"gross_profit"` // (bid - ask) * quantità ai migliori prezzi quotati
+	Costs        float64   `json:"costs"`        // Fee + slippage + latenza di entrambe le gambe
+	NetProfit    float64   `json:"net_profit"`
+	NetBps       float64   `json:"net_bps"` // Profitto netto in basis point del nozionale di acquisto
+	DetectedAt   time.Time `json:"detected_at"`
+}
+
+// DetectorConfig contiene i parametri del rilevamento
+type DetectorConfig struct {
+	Quantity  float64 // Quantità massima per opportunità, in base coin
+	MinNetBps float64 // Profitto netto minimo in basis point per segnalare l'opportunità
+}
+
+// Detector individua gli arbitraggi tra venue dalla vista consolidata dei prezzi
+// Un'opportunità viene segnalata solo se lo spread resta positivo dopo fee, slippage e latenza
+type Detector struct {
+	cfg  DetectorConfig
+	cost *CostModel
+}
+
+// NewDetector crea un nuovo Detector
+func NewDetector(cfg DetectorConfig, cost *CostModel) *Detector {
+	return &Detector{cfg: cfg, cost: cost}
+}
+
+// Evaluate valuta una vista consolidata e restituisce l'opportunità se netta positiva
+// Aggiorna anche la latenza osservata delle venue con l'età delle quotazioni
+func (d *Detector) Evaluate(price *models.AggregatedPrice) (*Opportunity, bool) {
+	for _, quote := range price.Quotes {
+		if quote.Error == "" && !quote.Stale && !quote.Timestamp.IsZero() {
+			d.cost.Latency().Observe(quote.Exchange, price.Timestamp.Sub(quote.Timestamp))
+		}
+	}
+
+	if !price.IsCrossed() {
+		return nil, false
+	}
+
+	// La quantità viene limitata dalla profondità disponibile o, senza book, dalla liquidità del miglior livello
+	quantity := d.cfg.Quantity
+	if quantity <= 0 {
+		return nil, false
+	}
+
+	buy := d.cost.LegCost(price.BestAskExchange, price.Symbol, models.OrderSideBuy, quantity, price.BestAsk, price.BestAskLiquidity)
+	sell := d.cost.LegCost(price.BestBidExchange, price.Symbol, models.OrderSideSell, quantity, price.BestBid, price.BestBidLiquidity)
+
+	// Le due gambe devono avere la stessa quantità: se una venue ha meno profondità si ricalcola sull'altra
+	if executable := math.Min(buy.Quantity, sell.Quantity); executable < quantity {
+		quantity = executable
+		buy = d.cost.LegCost(price.BestAskExchange, price.Symbol, models.OrderSideBuy, quantity, price.BestAsk, price.BestAskLiquidity)
+		sell = d.cost.LegCost(price.BestBidExchange, price.Symbol, models.OrderSideSell, quantity, price.BestBid, price.BestBidLiquidity)
+	}
+	if quantity <= 0 {
+		return nil, false
+	}
+
+	opportunity := &Opportunity{
+		Symbol:       price.Symbol,
+		BuyExchange:  price.BestAskExchange,
+		SellExchange: price.BestBidExchange,
+		Quantity:     quantity,
+		Buy:          buy,
+		Sell:         sell,
+		GrossProfit:  (price.BestBid - price.BestAsk) * quantity,
+		Costs:        buy.Total() + sell.Total(),
+		DetectedAt:   price.Timestamp,
+	}
+	opportunity.NetProfit = opportunity.GrossProfit - opportunity.Costs
+	if notional := price.BestAsk * quantity; notional > 0 {
+		opportunity.NetBps = opportunity.NetProfit / notional * 10000
+	}
+
+	if opportunity.NetProfit <= 0 || opportunity.NetBps < d.cfg.MinNetBps {
+		return opportunity, false
+	}
+	return opportunity, true
+}
+
+// Run valuta ogni vista consolidata ricevuta e invia su out le opportunità nette positive
+// Termina alla cancellazione del contesto o alla chiusura di prices
+func (d *Detector) Run(ctx context.Context, prices <-chan *models.AggregatedPrice, out chan<- *Opportunity) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case price, ok := <-prices:
+			if !ok {
+				return
+			}
+			if opportunity, ok := d.Evaluate(price); ok {
+				select {
+				case out <- opportunity:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// FillPriority stabilisce quale uscita considerare eseguita quando stop loss e take profit
+// cadono entrambi dentro la stessa candela: l'OHLC non dice quale livello è stato toccato per primo
+type FillPriority string
+
+const (
+	FillPessimistic    FillPriority = "pessimistic"     // Lo stop loss viene eseguito per primo (default)
+	FillOptimistic     FillPriority = "optimistic"      // Il take profit viene eseguito per primo
+	FillLowerTimeframe FillPriority = "lower_timeframe" // L'ordine si ricava dalle candele di timeframe inferiore
+)
+
+// ParseFillPriority valida il nome di un modello di priorità (vuoto = pessimistic)
+func ParseFillPriority(value string) (FillPriority, error) {
+	switch priority := FillPriority(strings.ToLower(strings.TrimSpace(value))); priority {
+	case "":
+		return FillPessimistic, nil
+	case FillPessimistic, FillOptimistic, FillLowerTimeframe:
+		return priority, nil
+	default:
+		return "", fmt.Errorf("priorità di esecuzione %q non valida: usare pessimistic, optimistic o lower_timeframe", value)
+	}
+}
+
+// ExitReason indica quale livello ha chiuso la posizione
+type ExitReason string
+
+const (
+	ExitStopLoss   ExitReason = "stop_loss"
+	ExitTakeProfit ExitReason = "take_profit"
+)
+
+// Bracket descrive una posizione aperta con i suoi livelli di uscita (0 = livello non impostato)
+type Bracket struct {
+	Symbol     string // Simbolo della posizione, per caricare le candele di timeframe inferiore
+	Side       models.OrderSide
+	StopLoss   float64
+	TakeProfit float64
+}
+
+// Exit è l'uscita simulata di una posizione
+type Exit struct {
+	Reason    ExitReason
+	Price     float64
+	Time      time.Time // Apertura della candela (o sotto-candela) in cui avviene l'uscita
+	Ambiguous bool      // SL e TP nella stessa candela risolti con una regola e non con dati più fini
+}
+
+// LowerTimeframeLoader restituisce in ordine cronologico le candele di timeframe inferiore del simbolo aperte in [start, end)
+type LowerTimeframeLoader func(ctx context.Context, symbol string, start, end time.Time) ([]models.Candle, error)
+
+// FillModel simula l'esecuzione di stop loss e take profit sulle candele di un backtest
+type FillModel struct {
+	priority FillPriority
+	interval time.Duration
+	lower    LowerTimeframeLoader
+}
+
+// NewFillModel crea il modello di esecuzione; interval è la durata delle candele del backtest
+// Con FillLowerTimeframe serve il loader delle candele di timeframe inferiore
+func NewFillModel(priority FillPriority, interval time.Duration, lower LowerTimeframeLoader) (*FillModel, error) {
+	if _, err := ParseFillPriority(string(priority)); err != nil {
+		return nil, err
+	}
+	if priority == "" {
+		priority = FillPessimistic
+	}
+	if priority == FillLowerTimeframe && (lower == nil || interval <= 0) {
+		return nil, fmt.Errorf("la priorità lower_timeframe richiede la durata delle candele e il loader del timeframe inferiore")
+	}
+	return &FillModel{priority: priority, interval: interval, lower: lower}, nil
+}
+
+// Exit verifica se la candela chiude la posizione; restituisce nil se nessun livello viene toccato
+// Se la candela apre già oltre un livello (gap) l'uscita avviene al prezzo di apertura
+func (m *FillModel) Exit(ctx context.Context, bracket Bracket, candle models.Candle) (*Exit, error) {
+	stopHit, takeHit := bracket.touches(candle)
+	if !stopHit && !takeHit {
+		return nil, nil
+	}
+	if exit := bracket.gapExit(candle); exit != nil {
+		return exit, nil
+	}
+	if stopHit != takeHit {
+		return bracket.exitAt(stopHit, candle, false), nil
+	}
+
+	switch m.priority {
+	case FillOptimistic:
+		return bracket.exitAt(false, candle, true), nil
+	case FillLowerTimeframe:
+		return m.lowerTimeframeExit(ctx, bracket, candle)
+	default:
+		return bracket.exitAt(true, candle, true), nil
+	}
+}
+
+// lowerTimeframeExit ripercorre la candela con le candele di timeframe inferiore
+// Se mancano o sono anch'esse ambigue si ricade sulla regola pessimistica
+func (m *FillModel) lowerTimeframeExit(ctx context.Context, bracket Bracket, candle models.Candle) (*Exit, error) {
+	candles, err := m.lower(ctx, bracket.Symbol, candle.Timestamp, candle.Timestamp.Add(m.interval))
+	if err != nil {
+		return nil, fmt.Errorf("errore caricamento candele di timeframe inferiore: %w", err)
+	}
+
+	for _, sub := range candles {
+		stopHit, takeHit := bracket.touches(sub)
+		if !stopHit && !takeHit {
+			continue
+		}
+		if exit := bracket.gapExit(sub); exit != nil {
+			return exit, nil
+		}
+		return bracket.exitAt(stopHit, sub, stopHit && takeHit), nil
+	}
+
+	// Le sotto-candele non toccano alcun livello (dati incompleti): vale la regola pessimistica
+	return bracket.exitAt(true, candle, true), nil
+}
+
+// touches verifica quali livelli rientrano nel range della candela
+func (b Bracket) touches(candle models.Candle) (stopHit, takeHit bool) {
+	if b.Side == models.OrderSideSell {
+		stopHit = b.StopLoss > 0 && candle.High >= b.StopLoss
+		takeHit = b.TakeProfit > 0 && candle.Low <= b.TakeProfit
+		return stopHit, takeHit
+	}
+	stopHit = b.StopLoss > 0 && candle.Low <= b.StopLoss
+	takeHit = b.TakeProfit > 0 && candle.High >= b.TakeProfit
+	return stopHit, takeHit
+}
+
+// gapExit gestisce le candele che aprono già oltre uno dei livelli
+func (b Bracket) gapExit(candle models.Candle) *Exit {
+	long := b.Side != models.OrderSideSell
+	switch {
+	case b.StopLoss > 0 && ((long && candle.Open <= b.StopLoss) || (!long && candle.Open >= b.StopLoss)):
+		return &Exit{Reason: ExitStopLoss, Price: candle.Open, Time: candle.Timestamp}
+	case b.TakeProfit > 0 && ((long && candle.Open >= b.TakeProfit) || (!long && candle.Open <= b.TakeProfit)):
+		return &Exit{Reason: ExitTakeProfit, Price: candle.Open, Time: candle.Timestamp}
+	default:
+		return nil
+	}
+}
+
+// exitAt costruisce l'uscita al livello di stop loss o di take profit
+func (b Bracket) exitAt(stop bool, candle models.Candle, ambiguous bool) *Exit {
+	if stop {
+		return &Exit{Reason: ExitStopLoss, Price: b.StopLoss, Time: candle.Timestamp, Ambiguous: ambiguous}
+	}
+	return &Exit{Reason: ExitTakeProfit, Price: b.TakeProfit, Time: candle.Timestamp, Ambiguous: ambiguous}
+}
@@ -0,0 +1,102 @@
+package backtest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+
+	"gorm.io/gorm/logger"
+)
+
+// openCandleCache apre un database su file temporaneo e restituisce il repository della cache delle candele
+func openCandleCache(t *testing.T) repositories.CandleRepository {
+	t.Helper()
+
+	db, err := database.Connect(&database.Config{
+		FilePath:      filepath.Join(t.TempDir(), "trading_bot.db"),
+		JournalMode:   "WAL",
+		BusyTimeoutMs: 5000,
+		MaxOpenConns:  1,
+	})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	db.Logger = logger.Discard
+	t.Cleanup(func() { _ = database.Close(db) })
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return repositories.NewRepositoryManager(db).Candle()
+}
+
+// Una candela da 5 minuti tocca sia lo stop loss (0.098) sia il take profit (0.103) di un long:
+// senza dati più fini vale la priorità, con le candele da 1 minuto in cache vince il livello toccato per primo
+func TestFillModelBothLevelsInOneBar(t *testing.T) {
+	ctx := context.Background()
+	cache := openCandleCache(t)
+
+	open := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	bar := models.Candle{Timestamp: open, Open: 0.100, High: 0.104, Low: 0.097, Close: 0.099}
+	bracket := Bracket{Symbol: "DOGEUSDT", Side: models.OrderSideBuy, StopLoss: 0.098, TakeProfit: 0.103}
+
+	// Il prezzo sale al take profit nel secondo minuto e scende sotto lo stop solo nel quarto
+	minutes := []models.Candle{
+		{Open: 0.100, High: 0.101, Low: 0.0995, Close: 0.1005},
+		{Open: 0.1005, High: 0.104, Low: 0.100, Close: 0.102},
+		{Open: 0.102, High: 0.102, Low: 0.099, Close: 0.0995},
+		{Open: 0.0995, High: 0.0998, Low: 0.097, Close: 0.0975},
+		{Open: 0.0975, High: 0.099, Low: 0.097, Close: 0.099},
+	}
+	records := make([]*models.CandleRecord, len(minutes))
+	for i, candle := range minutes {
+		candle.Timestamp = open.Add(time.Duration(i) * time.Minute)
+		records[i] = models.NewCandleRecord("DOGEUSDT", models.DerivativesMarket, models.Timeframe1m, candle)
+	}
+	if err := cache.CreateBatch(ctx, records); err != nil {
+		t.Fatalf("candles: %v", err)
+	}
+	loader := CandleCacheLoader(cache, models.DerivativesMarket, models.Timeframe1m)
+
+	tests := []struct {
+		name          string
+		priority      FillPriority
+		symbol        string
+		wantReason    ExitReason
+		wantTime      time.Time
+		wantAmbiguous bool
+	}{
+		{"pessimistic", FillPessimistic, "DOGEUSDT", ExitStopLoss, open, true},
+		{"optimistic", FillOptimistic, "DOGEUSDT", ExitTakeProfit, open, true},
+		{"lower_timeframe", FillLowerTimeframe, "DOGEUSDT", ExitTakeProfit, open.Add(time.Minute), false},
+		{"lower_timeframe_without_cache", FillLowerTimeframe, "XRPUSDT", ExitStopLoss, open, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := NewFillModel(tt.priority, 5*time.Minute, loader)
+			if err != nil {
+				t.Fatalf("fill model: %v", err)
+			}
+			b := bracket
+			b.Symbol = tt.symbol
+
+			exit, err := model.Exit(ctx, b, bar)
+			if err != nil {
+				t.Fatalf("exit: %v", err)
+			}
+			if exit == nil {
+				t.Fatal("exit = nil, want an exit")
+			}
+			if exit.Reason != tt.wantReason || !exit.Time.Equal(tt.wantTime) || exit.Ambiguous != tt.wantAmbiguous {
+				t.Errorf("exit = %s at %v (ambiguous %v), want %s at %v (ambiguous %v)",
+					exit.Reason, exit.Time, exit.Ambiguous, tt.wantReason, tt.wantTime, tt.wantAmbiguous)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package backtest
+
+import (
+	"math"
+
+	"cross-exchange-arbitrage/arbitrage"
+	"cross-exchange-arbitrage/models"
+)
+
+// LiquidityModel limita la quantità eseguibile per candela per simulare fill parziali
+// Il limite è una frazione del volume della candela e, se disponibile, la profondità registrata del book
+type LiquidityModel struct {
+	MaxVolumeFraction float64               // Frazione massima del volume della candela eseguibile (0 = nessun limite)
+	Depth             arbitrage.DepthSource // Order book registrato della venue; nil se non disponibile
+	Exchange          string                // Venue da cui leggere il book
+}
+
+// Fill restituisce prezzo medio e quantità eseguibile di quantity sulla candela
+// limit è il prezzo limite dell'ordine (0 = ordine a mercato): il book viene consumato solo fino al limite
+func (m LiquidityModel) Fill(symbol string, side models.OrderSide, quantity, limit float64, candle models.Candle) (float64, float64) {
+	if quantity <= 0 {
+		return 0, 0
+	}
+
+	filled := quantity
+	if m.MaxVolumeFraction > 0 {
+		filled = math.Min(filled, candle.Volume*m.MaxVolumeFraction)
+	}
+	if filled <= 0 {
+		return 0, 0
+	}
+
+	price := candle.Close
+	if m.Depth != nil {
+		if book, ok := m.Depth.Get(m.Exchange, symbol); ok {
+			levels := book.Asks
+			if side == models.OrderSideSell {
+				levels = book.Bids
+			}
+			if avgPrice, depthFilled := arbitrage.EstimateFill(withinLimit(levels, side, limit), filled); depthFilled > 0 {
+				return avgPrice, depthFilled
+			}
+			return 0, 0
+		}
+	}
+	if limit > 0 && !crossesLimit(side, limit, candle) {
+		return 0, 0
+	}
+	if limit > 0 {
+		price = limit
+	}
+	return price, filled
+}
+
+// FillStatus restituisce lo stato di un ordine in base alla quantità eseguita
+func FillStatus(filled, quantity float64) models.OrderStatus {
+	switch {
+	case filled <= 0:
+		return models.OrderStatusNew
+	case filled < quantity:
+		return models.OrderStatusPartiallyFilled
+	default:
+		return models.OrderStatusFilled
+	}
+}
+
+// withinLimit restituisce i livelli del book eseguibili al prezzo limite
+func withinLimit(levels []models.OrderBookLevel, side models.OrderSide, limit float64) []models.OrderBookLevel {
+	if limit <= 0 {
+		return levels
+	}
+	for i, level := range levels {
+		if (side == models.OrderSideSell && level.Price < limit) || (side != models.OrderSideSell && level.Price > limit) {
+			return levels[:i]
+		}
+	}
+	return levels
+}
+
+// crossesLimit verifica se la candela raggiunge il prezzo limite dell'ordine
+func crossesLimit(side models.OrderSide, limit float64, candle models.Candle) bool {
+	if side == models.OrderSideSell {
+		return candle.High >= limit
+	}
+	return candle.Low <= limit
+}
@@ -0,0 +1,25 @@
+package backtest
+
+import (
+	"context"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+)
+
+// CandleCacheLoader legge le candele di timeframe inferiore dalla cache locale delle candele
+// (es. le candele da 1 minuto salvate dal worker DOGE, per un backtest su candele da 1 ora)
+func CandleCacheLoader(repo repositories.CandleRepository, market models.Market, timeframe models.Timeframe) LowerTimeframeLoader {
+	return func(ctx context.Context, symbol string, start, end time.Time) ([]models.Candle, error) {
+		records, err := repo.GetRange(ctx, symbol, market, timeframe, start, end)
+		if err != nil {
+			return nil, err
+		}
+		candles := make([]models.Candle, len(records))
+		for i, record := range records {
+			candles[i] = record.ToCandle()
+		}
+		return candles, nil
+	}
+}
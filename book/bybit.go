@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"strconv"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -38,13 +37,20 @@ type BybitSubscriptionMessage struct {
 	Args []string `json:"args"`
 }
 
-// NewBybitOrderBookStreamer crea una nuova istanza di BybitOrderBookStreamer
+// NewBybitOrderBookStreamer crea una nuova istanza di BybitOrderBookStreamer per il mercato spot
 func NewBybitOrderBookStreamer() *BybitOrderBookStreamer {
 	return &BybitOrderBookStreamer{
 		wsURL: "wss://stream.bybit.com/v5/public/spot",
 	}
 }
 
+// NewBybitLinearOrderBookStreamer crea uno streamer per i perpetual lineari (USDT)
+func NewBybitLinearOrderBookStreamer() *BybitOrderBookStreamer {
+	return &BybitOrderBookStreamer{
+		wsURL: "wss://stream.bybit.com/v5/public/linear",
+	}
+}
+
 // OrderBookStream implementa il metodo dell'interfaccia OrderBookStreamer
 func (b *BybitOrderBookStreamer) OrderBookStream(
 	ctx context.Context,
@@ -59,18 +65,11 @@ func (b *BybitOrderBookStreamer) OrderBookStream(
 	}
 
 	// Connessione WebSocket
-	var err error
-	b.conn, _, err = websocket.DefaultDialer.DialContext(ctx, b.wsURL, nil)
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, b.wsURL, nil)
 	if err != nil {
 		return fmt.Errorf("errore connessione WebSocket Bybit: %w", err)
 	}
-
-	// Cleanup alla chiusura
-	defer func() {
-		if b.conn != nil {
-			b.conn.Close()
-		}
-	}()
+	b.conn = conn
 
 	// Sottoscrizione al topic dell'orderbook
 	subscribeMsg := BybitSubscriptionMessage{
@@ -78,20 +77,41 @@ func (b *BybitOrderBookStreamer) OrderBookStream(
 		Args: []string{fmt.Sprintf("orderbook.%d.%s", depth, symbol)},
 	}
 
-	if err := b.conn.WriteJSON(subscribeMsg); err != nil {
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
+		conn.Close()
 		return fmt.Errorf("errore sottoscrizione simbolo %s: %w", symbol, err)
 	}
 
-	// Goroutine per la gestione dei messaggi
+	// Chiude la connessione alla cancellazione del contesto per sbloccare la lettura in corso
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	// sendError invia un errore senza bloccare lo stream se il contesto è stato cancellato
+	sendError := func(err error) {
+		select {
+		case errChan <- err:
+		case <-ctx.Done():
+		}
+	}
+
+	// Goroutine per la gestione dei messaggi; la connessione resta aperta finché legge
+	// Con depth > 1 Bybit invia uno snapshot seguito da delta: il book viene mantenuto localmente
 	go func() {
+		defer conn.Close()
+
+		local := newLocalBook()
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			default:
-				_, message, err := b.conn.ReadMessage()
+				_, message, err := conn.ReadMessage()
 				if err != nil {
-					errChan <- fmt.Errorf("errore lettura messaggio WebSocket: %w", err)
+					if ctx.Err() == nil {
+						sendError(fmt.Errorf("%w: %v", ErrStreamClosed, err))
+					}
 					return
 				}
 
@@ -103,9 +123,10 @@ func (b *BybitOrderBookStreamer) OrderBookStream(
 
 				// Processa solo messaggi dell'order book
 				if response.Topic != "" && response.Data.Symbol != "" {
-					orderBookData, err := b.convertToOrderBookData(&response)
+					local.apply(response.Type == "snapshot", response.Data.Bids, response.Data.Asks)
+					orderBookData, err := b.convertToOrderBookData(&response, local, depth)
 					if err != nil {
-						errChan <- fmt.Errorf("errore conversione dati orderbook: %w", err)
+						sendError(fmt.Errorf("errore conversione dati orderbook: %w", err))
 						continue
 					}
 
@@ -124,52 +145,13 @@ func (b *BybitOrderBookStreamer) OrderBookStream(
 	return nil
 }
 
-// convertToOrderBookData converte la risposta di Bybit nel formato OrderBookData
-func (b *BybitOrderBookStreamer) convertToOrderBookData(response *BybitOrderBookResponse) (*models.OrderBookData, error) {
-	if len(response.Data.Bids) == 0 || len(response.Data.Asks) == 0 {
+// convertToOrderBookData converte il book locale nel formato OrderBookData
+func (b *BybitOrderBookStreamer) convertToOrderBookData(response *BybitOrderBookResponse, local *localBook, depth int) (*models.OrderBookData, error) {
+	bids, asks := local.levels(depth)
+	if len(bids) == 0 || len(asks) == 0 {
 		return nil, fmt.Errorf("orderbook vuoto")
 	}
 
-	// Converti i bid
-	bids := make([]models.OrderBookLevel, 0, len(response.Data.Bids))
-	for _, bid := range response.Data.Bids {
-		if len(bid) != 2 {
-			continue
-		}
-		price, err := strconv.ParseFloat(bid[0], 64)
-		if err != nil {
-			continue
-		}
-		quantity, err := strconv.ParseFloat(bid[1], 64)
-		if err != nil {
-			continue
-		}
-		bids = append(bids, models.OrderBookLevel{
-			Price:    price,
-			Quantity: quantity,
-		})
-	}
-
-	// Converti gli ask
-	asks := make([]models.OrderBookLevel, 0, len(response.Data.Asks))
-	for _, ask := range response.Data.Asks {
-		if len(ask) != 2 {
-			continue
-		}
-		price, err := strconv.ParseFloat(ask[0], 64)
-		if err != nil {
-			continue
-		}
-		quantity, err := strconv.ParseFloat(ask[1], 64)
-		if err != nil {
-			continue
-		}
-		asks = append(asks, models.OrderBookLevel{
-			Price:    price,
-			Quantity: quantity,
-		})
-	}
-
 	// Crea l'OrderBookData
 	orderBookData := &models.OrderBookData{
 		Symbol:    response.Data.Symbol,
@@ -183,4 +165,3 @@ func (b *BybitOrderBookStreamer) convertToOrderBookData(response *BybitOrderBook
 
 	return orderBookData, nil
 }
-
@@ -0,0 +1,75 @@
+package book
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// BybitLiquidationStreamer implementa l'interfaccia LiquidationStreamer per Bybit
+type BybitLiquidationStreamer struct {
+	wsURL string
+}
+
+// BybitLiquidationResponse rappresenta un messaggio del topic allLiquidation di Bybit
+// Il lato è quello della posizione liquidata: Buy indica la liquidazione di un long
+type BybitLiquidationResponse struct {
+	Topic string `json:"topic"`
+	Type  string `json:"type"`
+	Data  []struct {
+		Time   int64  `json:"T"`
+		Symbol string `json:"s"`
+		Side   string `json:"S"`
+		Size   string `json:"v"`
+		Price  string `json:"p"`
+	} `json:"data"`
+	Ts int64 `json:"ts"`
+}
+
+// NewBybitLinearLiquidationStreamer crea uno streamer delle liquidazioni dei perpetual lineari (USDT)
+func NewBybitLinearLiquidationStreamer() *BybitLiquidationStreamer {
+	return &BybitLiquidationStreamer{
+		wsURL: "wss://stream.bybit.com/v5/public/linear",
+	}
+}
+
+// LiquidationStream implementa il metodo dell'interfaccia LiquidationStreamer
+func (b *BybitLiquidationStreamer) LiquidationStream(
+	ctx context.Context,
+	symbol string,
+	liquidationChan chan<- Liquidation,
+	errChan chan<- error,
+) error {
+	return subscribePublic(ctx, b.wsURL, "allLiquidation."+symbol, func(message []byte) error {
+		var response BybitLiquidationResponse
+		if err := json.Unmarshal(message, &response); err != nil || response.Topic == "" {
+			// Ignora le risposte alla sottoscrizione e i messaggi di altro tipo
+			return nil
+		}
+
+		for _, data := range response.Data {
+			price, priceErr := strconv.ParseFloat(data.Price, 64)
+			size, sizeErr := strconv.ParseFloat(data.Size, 64)
+			if priceErr != nil || sizeErr != nil {
+				return fmt.Errorf("liquidazione %s non valida: prezzo %q, quantità %q", data.Symbol, data.Price, data.Size)
+			}
+
+			select {
+			case liquidationChan <- Liquidation{
+				Symbol: data.Symbol,
+				Side:   models.OrderSide(data.Side),
+				Price:  price,
+				Size:   size,
+				Time:   time.UnixMilli(data.Time),
+			}:
+			default:
+				log.Printf("Canale liquidazioni pieno, skip liquidazione per %s", symbol)
+			}
+		}
+		return nil
+	}, errChan)
+}
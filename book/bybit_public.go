@@ -0,0 +1,59 @@
+package book
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gorilla/websocket"
+)
+
+// subscribePublic si connette a uno stream pubblico di Bybit, sottoscrive il topic e passa ogni messaggio a handle
+// in una goroutine, finché il contesto non viene cancellato o la lettura fallisce (errore ErrStreamClosed su errChan).
+// handle restituisce gli errori dei singoli messaggi, inviati su errChan senza interrompere lo stream
+func subscribePublic(ctx context.Context, wsURL, topic string, handle func(message []byte) error, errChan chan<- error) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return fmt.Errorf("errore connessione WebSocket Bybit: %w", err)
+	}
+
+	subscribeMsg := BybitSubscriptionMessage{
+		Op:   "subscribe",
+		Args: []string{topic},
+	}
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
+		conn.Close()
+		return fmt.Errorf("errore sottoscrizione %s: %w", topic, err)
+	}
+
+	// Chiude la connessione alla cancellazione del contesto per sbloccare la lettura in corso
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	sendError := func(err error) {
+		select {
+		case errChan <- err:
+		case <-ctx.Done():
+		}
+	}
+
+	go func() {
+		defer conn.Close()
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				if ctx.Err() == nil {
+					sendError(fmt.Errorf("%w: %v", ErrStreamClosed, err))
+				}
+				return
+			}
+			if err := handle(message); err != nil {
+				sendError(err)
+			}
+		}
+	}()
+
+	return nil
+}
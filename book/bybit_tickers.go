@@ -0,0 +1,105 @@
+package book
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// BybitTickerStreamer implementa l'interfaccia MarkPriceStreamer per Bybit
+type BybitTickerStreamer struct {
+	wsURL string
+}
+
+// BybitTickerResponse rappresenta un messaggio del topic tickers di Bybit
+// Dopo lo snapshot iniziale i messaggi delta contengono solo i campi cambiati: markPrice può mancare
+type BybitTickerResponse struct {
+	Topic string `json:"topic"`
+	Type  string `json:"type"`
+	Data  struct {
+		Symbol    string `json:"symbol"`
+		MarkPrice string `json:"markPrice"`
+	} `json:"data"`
+	Ts int64 `json:"ts"`
+}
+
+// NewBybitLinearTickerStreamer crea uno streamer dei ticker dei perpetual lineari (USDT)
+func NewBybitLinearTickerStreamer() *BybitTickerStreamer {
+	return &BybitTickerStreamer{
+		wsURL: "wss://stream.bybit.com/v5/public/linear",
+	}
+}
+
+// MarkPriceStream implementa il metodo dell'interfaccia MarkPriceStreamer
+func (b *BybitTickerStreamer) MarkPriceStream(
+	ctx context.Context,
+	symbol string,
+	priceChan chan<- MarkPrice,
+	errChan chan<- error,
+) error {
+	return subscribePublic(ctx, b.wsURL, "tickers."+symbol, func(message []byte) error {
+		var response BybitTickerResponse
+		if err := json.Unmarshal(message, &response); err != nil || response.Topic == "" || response.Data.MarkPrice == "" {
+			// Ignora le risposte alla sottoscrizione e i delta senza mark price
+			return nil
+		}
+
+		price, err := strconv.ParseFloat(response.Data.MarkPrice, 64)
+		if err != nil || price <= 0 {
+			return fmt.Errorf("mark price %s non valido: %q", symbol, response.Data.MarkPrice)
+		}
+
+		select {
+		case priceChan <- MarkPrice{Symbol: symbol, Price: price, Time: time.UnixMilli(response.Ts)}:
+		default:
+			log.Printf("Canale mark price pieno, skip aggiornamento per %s", symbol)
+		}
+		return nil
+	}, errChan)
+}
+
+// FollowMarkPrice apre lo stream del mark price di un simbolo e passa ogni aggiornamento a handle
+// fino alla cancellazione del contesto. Se lo stream si interrompe viene riaperto dopo reconnectDelay
+func FollowMarkPrice(ctx context.Context, streamer MarkPriceStreamer, symbol string, handle func(MarkPrice)) {
+	go func() {
+		for ctx.Err() == nil {
+			streamCtx, cancel := context.WithCancel(ctx)
+			prices := make(chan MarkPrice, 100)
+			errs := make(chan error, 10)
+
+			if err := streamer.MarkPriceStream(streamCtx, symbol, prices, errs); err != nil {
+				log.Printf("⚠️  Stream mark price %s: %v", symbol, err)
+			} else {
+				consumeMarkPrices(streamCtx, symbol, prices, errs, handle)
+			}
+			cancel()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reconnectDelay):
+			}
+		}
+	}()
+}
+
+// consumeMarkPrices inoltra gli aggiornamenti finché lo stream non restituisce un errore di lettura
+func consumeMarkPrices(ctx context.Context, symbol string, prices <-chan MarkPrice, errs <-chan error, handle func(MarkPrice)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case price := <-prices:
+			handle(price)
+		case err := <-errs:
+			log.Printf("⚠️  Stream mark price %s: %v", symbol, err)
+			if errors.Is(err, ErrStreamClosed) {
+				return
+			}
+		}
+	}
+}
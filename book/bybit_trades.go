@@ -0,0 +1,79 @@
+package book
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// BybitTradeStreamer implementa l'interfaccia TradeStreamer per Bybit
+type BybitTradeStreamer struct {
+	wsURL string
+}
+
+// BybitTradeResponse rappresenta un messaggio del topic publicTrade di Bybit
+type BybitTradeResponse struct {
+	Topic string `json:"topic"`
+	Type  string `json:"type"`
+	Data  []struct {
+		Time   int64  `json:"T"`
+		Symbol string `json:"s"`
+		Side   string `json:"S"`
+		Size   string `json:"v"`
+		Price  string `json:"p"`
+		Block  bool   `json:"BT"`
+	} `json:"data"`
+	Ts int64 `json:"ts"`
+}
+
+// NewBybitLinearTradeStreamer crea uno streamer dei trade pubblici dei perpetual lineari (USDT)
+func NewBybitLinearTradeStreamer() *BybitTradeStreamer {
+	return &BybitTradeStreamer{
+		wsURL: "wss://stream.bybit.com/v5/public/linear",
+	}
+}
+
+// TradeStream implementa il metodo dell'interfaccia TradeStreamer
+func (b *BybitTradeStreamer) TradeStream(
+	ctx context.Context,
+	symbol string,
+	tradeChan chan<- Trade,
+	errChan chan<- error,
+) error {
+	return subscribePublic(ctx, b.wsURL, "publicTrade."+symbol, func(message []byte) error {
+		var response BybitTradeResponse
+		if err := json.Unmarshal(message, &response); err != nil || response.Topic == "" {
+			// Ignora le risposte alla sottoscrizione e i messaggi di altro tipo
+			return nil
+		}
+
+		for _, data := range response.Data {
+			// I block trade sono negoziati fuori dal book e non indicano pressione degli aggressori
+			if data.Block {
+				continue
+			}
+			price, priceErr := strconv.ParseFloat(data.Price, 64)
+			size, sizeErr := strconv.ParseFloat(data.Size, 64)
+			if priceErr != nil || sizeErr != nil {
+				return fmt.Errorf("trade %s non valido: prezzo %q, quantità %q", data.Symbol, data.Price, data.Size)
+			}
+
+			select {
+			case tradeChan <- Trade{
+				Symbol: data.Symbol,
+				Side:   models.OrderSide(data.Side),
+				Price:  price,
+				Size:   size,
+				Time:   time.UnixMilli(data.Time),
+			}:
+			default:
+				log.Printf("Canale trade pieno, skip trade per %s", symbol)
+			}
+		}
+		return nil
+	}, errChan)
+}
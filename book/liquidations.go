@@ -0,0 +1,144 @@
+package book
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// LiquidationCluster è una sequenza di liquidazioni dello stesso lato, ciascuna entro Gap dalla precedente
+type LiquidationCluster struct {
+	Symbol   string           `json:"symbol"`
+	Side     models.OrderSide `json:"side"` // Lato delle posizioni liquidate: Buy per i long, Sell per gli short
+	Start    time.Time        `json:"start"`
+	End      time.Time        `json:"end"`
+	Count    int              `json:"count"`
+	Size     float64          `json:"size"`
+	Notional float64          `json:"notional"` // Controvalore in USDT
+	Price    float64          `json:"price"`    // Prezzo medio ponderato per quantità
+}
+
+// LiquidationFeed conserva le liquidazioni recenti di ogni simbolo e le raggruppa in cluster
+// Una cascata di liquidazioni di long spinge il prezzo in basso (e viceversa): le strategie la usano
+// come segnale contrarian di ingresso o come motivo per stringere lo stop
+type LiquidationFeed struct {
+	mu           sync.Mutex
+	retention    time.Duration
+	gap          time.Duration
+	liquidations map[string][]Liquidation
+}
+
+// NewLiquidationFeed crea un feed vuoto che conserva le liquidazioni per retention
+// e unisce nello stesso cluster quelle distanti al più gap
+func NewLiquidationFeed(retention, gap time.Duration) *LiquidationFeed {
+	return &LiquidationFeed{retention: retention, gap: gap, liquidations: make(map[string][]Liquidation)}
+}
+
+// Retention restituisce per quanto tempo vengono conservate le liquidazioni
+func (f *LiquidationFeed) Retention() time.Duration {
+	return f.retention
+}
+
+// Add registra una liquidazione e scarta quelle più vecchie di retention
+func (f *LiquidationFeed) Add(liquidation Liquidation) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	symbol := strings.ToUpper(liquidation.Symbol)
+	liquidations := append(f.liquidations[symbol], liquidation)
+	since := liquidation.Time.Add(-f.retention)
+	first := 0
+	for first < len(liquidations) && liquidations[first].Time.Before(since) {
+		first++
+	}
+	f.liquidations[symbol] = liquidations[first:]
+}
+
+// Clusters restituisce i cluster di liquidazioni del simbolo terminati da since in poi, ordinati per fine
+func (f *LiquidationFeed) Clusters(symbol string, since time.Time) []LiquidationCluster {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	symbol = strings.ToUpper(symbol)
+	open := make(map[models.OrderSide]*LiquidationCluster)
+	var clusters []LiquidationCluster
+	for _, liquidation := range f.liquidations[symbol] {
+		cluster := open[liquidation.Side]
+		if cluster != nil && liquidation.Time.Sub(cluster.End) > f.gap {
+			clusters = append(clusters, *cluster)
+			cluster = nil
+		}
+		if cluster == nil {
+			cluster = &LiquidationCluster{Symbol: symbol, Side: liquidation.Side, Start: liquidation.Time}
+			open[liquidation.Side] = cluster
+		}
+		cluster.End = liquidation.Time
+		cluster.Count++
+		cluster.Size += liquidation.Size
+		cluster.Notional += liquidation.Price * liquidation.Size
+	}
+	for _, cluster := range open {
+		clusters = append(clusters, *cluster)
+	}
+
+	clusters = slices.DeleteFunc(clusters, func(cluster LiquidationCluster) bool {
+		return cluster.End.Before(since)
+	})
+	for i := range clusters {
+		if clusters[i].Size > 0 {
+			clusters[i].Price = clusters[i].Notional / clusters[i].Size
+		}
+	}
+	slices.SortFunc(clusters, func(a, b LiquidationCluster) int {
+		return a.End.Compare(b.End)
+	})
+	return clusters
+}
+
+// Run apre lo stream delle liquidazioni di un simbolo e aggiorna il feed fino alla cancellazione del contesto
+// Se lo stream si interrompe viene riaperto dopo reconnectDelay
+func (f *LiquidationFeed) Run(ctx context.Context, streamer LiquidationStreamer, symbol string) {
+	go func() {
+		for ctx.Err() == nil {
+			streamCtx, cancel := context.WithCancel(ctx)
+			liquidations := make(chan Liquidation, 100)
+			errs := make(chan error, 10)
+
+			if err := streamer.LiquidationStream(streamCtx, symbol, liquidations, errs); err != nil {
+				log.Printf("⚠️  Stream liquidazioni %s: %v", symbol, err)
+			} else {
+				f.consume(streamCtx, symbol, liquidations, errs)
+			}
+			cancel()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reconnectDelay):
+			}
+		}
+	}()
+}
+
+// consume registra le liquidazioni finché lo stream non restituisce un errore di lettura
+func (f *LiquidationFeed) consume(ctx context.Context, symbol string, liquidations <-chan Liquidation, errs <-chan error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case liquidation := <-liquidations:
+			f.Add(liquidation)
+		case err := <-errs:
+			log.Printf("⚠️  Stream liquidazioni %s: %v", symbol, err)
+			if errors.Is(err, ErrStreamClosed) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package book
+
+import (
+	"sort"
+	"strconv"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// localBook mantiene l'order book ricostruito da snapshot e delta
+// I livelli sono indicizzati per prezzo; una quantità 0 in un delta rimuove il livello
+type localBook struct {
+	bids map[float64]float64
+	asks map[float64]float64
+}
+
+// newLocalBook crea un book locale vuoto
+func newLocalBook() *localBook {
+	return &localBook{
+		bids: make(map[float64]float64),
+		asks: make(map[float64]float64),
+	}
+}
+
+// apply applica un aggiornamento; snapshot sostituisce l'intero book
+func (lb *localBook) apply(snapshot bool, bids, asks [][]string) {
+	if snapshot {
+		lb.bids = make(map[float64]float64, len(bids))
+		lb.asks = make(map[float64]float64, len(asks))
+	}
+	applyLevels(lb.bids, bids)
+	applyLevels(lb.asks, asks)
+}
+
+// applyLevels aggiorna un lato del book con i livelli nel formato [prezzo, quantità]
+func applyLevels(side map[float64]float64, levels [][]string) {
+	for _, level := range levels {
+		if len(level) != 2 {
+			continue
+		}
+		price, err := strconv.ParseFloat(level[0], 64)
+		if err != nil {
+			continue
+		}
+		quantity, err := strconv.ParseFloat(level[1], 64)
+		if err != nil {
+			continue
+		}
+		if quantity == 0 {
+			delete(side, price)
+			continue
+		}
+		side[price] = quantity
+	}
+}
+
+// levels restituisce bid (prezzo decrescente) e ask (prezzo crescente) limitati a depth livelli
+func (lb *localBook) levels(depth int) ([]models.OrderBookLevel, []models.OrderBookLevel) {
+	return sortedLevels(lb.bids, depth, true), sortedLevels(lb.asks, depth, false)
+}
+
+// sortedLevels ordina un lato del book dal livello migliore
+func sortedLevels(side map[float64]float64, depth int, descending bool) []models.OrderBookLevel {
+	levels := make([]models.OrderBookLevel, 0, len(side))
+	for price, quantity := range side {
+		levels = append(levels, models.OrderBookLevel{Price: price, Quantity: quantity})
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if descending {
+			return levels[i].Price > levels[j].Price
+		}
+		return levels[i].Price < levels[j].Price
+	})
+	if depth > 0 && len(levels) > depth {
+		levels = levels[:depth]
+	}
+	return levels
+}
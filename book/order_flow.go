@@ -0,0 +1,129 @@
+package book
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// FlowDelta è il volume scambiato dagli aggressori in acquisto e in vendita su un simbolo nella finestra
+type FlowDelta struct {
+	Symbol     string
+	BuyVolume  float64
+	SellVolume float64
+	Trades     int
+	Since      time.Time // Inizio della finestra
+}
+
+// Delta restituisce la differenza tra volume in acquisto e in vendita
+func (d FlowDelta) Delta() float64 {
+	return d.BuyVolume - d.SellVolume
+}
+
+// OrderFlow mantiene i trade pubblici recenti di ogni simbolo e ne calcola il delta su una finestra mobile
+// È la conferma dei breakout basata sugli aggressori, più precisa del volume della candela che non ne distingue il lato
+type OrderFlow struct {
+	mu     sync.Mutex
+	window time.Duration
+	trades map[string][]Trade
+}
+
+// NewOrderFlow crea un OrderFlow vuoto con la finestra indicata
+func NewOrderFlow(window time.Duration) *OrderFlow {
+	return &OrderFlow{window: window, trades: make(map[string][]Trade)}
+}
+
+// Window restituisce la durata della finestra mobile
+func (f *OrderFlow) Window() time.Duration {
+	return f.window
+}
+
+// Add registra un trade e scarta quelli usciti dalla finestra
+func (f *OrderFlow) Add(trade Trade) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	symbol := strings.ToUpper(trade.Symbol)
+	trades := append(f.trades[symbol], trade)
+	f.trades[symbol] = trades[firstInWindow(trades, trade.Time.Add(-f.window)):]
+}
+
+// Delta restituisce il delta del simbolo nella finestra che termina ora
+// ok è false se nella finestra non ci sono trade (stream non ancora avviato o interrotto)
+func (f *OrderFlow) Delta(symbol string) (FlowDelta, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	since := time.Now().Add(-f.window)
+	delta := FlowDelta{Symbol: strings.ToUpper(symbol), Since: since}
+	trades := f.trades[delta.Symbol]
+	for _, trade := range trades[firstInWindow(trades, since):] {
+		switch trade.Side {
+		case models.OrderSideBuy:
+			delta.BuyVolume += trade.Size
+		case models.OrderSideSell:
+			delta.SellVolume += trade.Size
+		default:
+			continue
+		}
+		delta.Trades++
+	}
+	return delta, delta.Trades > 0
+}
+
+// Run apre lo stream dei trade di un simbolo e aggiorna la finestra fino alla cancellazione del contesto
+// Se lo stream si interrompe viene riaperto dopo reconnectDelay
+func (f *OrderFlow) Run(ctx context.Context, streamer TradeStreamer, symbol string) {
+	go func() {
+		for ctx.Err() == nil {
+			streamCtx, cancel := context.WithCancel(ctx)
+			trades := make(chan Trade, 1000)
+			errs := make(chan error, 10)
+
+			if err := streamer.TradeStream(streamCtx, symbol, trades, errs); err != nil {
+				log.Printf("⚠️  Stream trade %s: %v", symbol, err)
+			} else {
+				f.consume(streamCtx, symbol, trades, errs)
+			}
+			cancel()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reconnectDelay):
+			}
+		}
+	}()
+}
+
+// consume registra i trade finché lo stream non restituisce un errore di lettura
+func (f *OrderFlow) consume(ctx context.Context, symbol string, trades <-chan Trade, errs <-chan error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case trade := <-trades:
+			f.Add(trade)
+		case err := <-errs:
+			log.Printf("⚠️  Stream trade %s: %v", symbol, err)
+			if errors.Is(err, ErrStreamClosed) {
+				return
+			}
+		}
+	}
+}
+
+// firstInWindow restituisce l'indice del primo trade (in ordine cronologico) non precedente a since
+func firstInWindow(trades []Trade, since time.Time) int {
+	for i, trade := range trades {
+		if !trade.Time.Before(since) {
+			return i
+		}
+	}
+	return len(trades)
+}
@@ -0,0 +1,91 @@
+package book
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// reconnectDelay è l'attesa prima di riaprire uno stream interrotto
+const reconnectDelay = 5 * time.Second
+
+// Store mantiene l'ultimo order book ricevuto per ogni coppia venue/simbolo
+// È la sorgente di profondità usata per stimare lo slippage
+type Store struct {
+	mu    sync.RWMutex
+	books map[string]*models.OrderBookData
+}
+
+// NewStore crea un nuovo Store vuoto
+func NewStore() *Store {
+	return &Store{books: make(map[string]*models.OrderBookData)}
+}
+
+// Get restituisce l'ultimo order book di una venue per un simbolo
+func (s *Store) Get(exchange, symbol string) (*models.OrderBookData, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.books[storeKey(exchange, symbol)]
+	return data, ok
+}
+
+// Update registra un nuovo order book
+func (s *Store) Update(data *models.OrderBookData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.books[storeKey(data.Exchange, data.Symbol)] = data
+}
+
+// Run apre lo stream di un simbolo e aggiorna lo store fino alla cancellazione del contesto
+// Se lo stream si interrompe viene riaperto dopo reconnectDelay
+func (s *Store) Run(ctx context.Context, streamer OrderBookStreamer, symbol string, depth int) {
+	go func() {
+		for ctx.Err() == nil {
+			streamCtx, cancel := context.WithCancel(ctx)
+			updates := make(chan *models.OrderBookData, 100)
+			errs := make(chan error, 10)
+
+			if err := streamer.OrderBookStream(streamCtx, symbol, depth, updates, errs); err != nil {
+				log.Printf("⚠️  Stream order book %s: %v", symbol, err)
+			} else {
+				s.consume(streamCtx, symbol, updates, errs)
+			}
+			cancel()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reconnectDelay):
+			}
+		}
+	}()
+}
+
+// consume applica gli aggiornamenti finché lo stream non restituisce un errore di lettura
+func (s *Store) consume(ctx context.Context, symbol string, updates <-chan *models.OrderBookData, errs <-chan error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case data := <-updates:
+			s.Update(data)
+		case err := <-errs:
+			log.Printf("⚠️  Stream order book %s: %v", symbol, err)
+			if errors.Is(err, ErrStreamClosed) {
+				return
+			}
+		}
+	}
+}
+
+// storeKey costruisce la chiave venue/simbolo
+func storeKey(exchange, symbol string) string {
+	return strings.ToLower(exchange) + ":" + strings.ToUpper(symbol)
+}
@@ -2,10 +2,15 @@ package book
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"cross-exchange-arbitrage/models"
 )
 
+// ErrStreamClosed is sent on the error channel when the stream stops reading and must be reopened
+var ErrStreamClosed = errors.New("errore lettura messaggio WebSocket")
+
 // OrderBookStreamer defines the interface for streaming orderbook data
 type OrderBookStreamer interface {
 	// OrderBookStream opens a websocket connection to stream orderbook data for a specific symbol
@@ -22,3 +27,70 @@ type OrderBookStreamer interface {
 		errChan chan<- error,
 	) error
 }
+
+// Trade is a single public trade; Side is the taker side (Buy when the aggressor lifted the ask)
+type Trade struct {
+	Symbol string
+	Side   models.OrderSide
+	Price  float64
+	Size   float64
+	Time   time.Time
+}
+
+// TradeStreamer defines the interface for streaming public trades
+type TradeStreamer interface {
+	// TradeStream opens a websocket connection to stream public trades for a specific symbol
+	// ctx is used to control the lifecycle of the stream
+	// tradeChan is the channel where trades will be sent
+	// errChan is the channel where any errors will be sent
+	TradeStream(
+		ctx context.Context,
+		symbol string,
+		tradeChan chan<- Trade,
+		errChan chan<- error,
+	) error
+}
+
+// Liquidation is a forced close of a position; Side is the side of the liquidated position (Buy for a long)
+type Liquidation struct {
+	Symbol string
+	Side   models.OrderSide
+	Price  float64
+	Size   float64
+	Time   time.Time
+}
+
+// LiquidationStreamer defines the interface for streaming liquidations
+type LiquidationStreamer interface {
+	// LiquidationStream opens a websocket connection to stream the liquidations of a specific symbol
+	// ctx is used to control the lifecycle of the stream
+	// liquidationChan is the channel where liquidations will be sent
+	// errChan is the channel where any errors will be sent
+	LiquidationStream(
+		ctx context.Context,
+		symbol string,
+		liquidationChan chan<- Liquidation,
+		errChan chan<- error,
+	) error
+}
+
+// MarkPrice is an update of the mark price of a symbol, the price used by the exchange for unrealized PnL
+type MarkPrice struct {
+	Symbol string
+	Price  float64
+	Time   time.Time
+}
+
+// MarkPriceStreamer defines the interface for streaming mark prices
+type MarkPriceStreamer interface {
+	// MarkPriceStream opens a websocket connection to stream the mark price of a specific symbol
+	// ctx is used to control the lifecycle of the stream
+	// priceChan is the channel where mark price updates will be sent
+	// errChan is the channel where any errors will be sent
+	MarkPriceStream(
+		ctx context.Context,
+		symbol string,
+		priceChan chan<- MarkPrice,
+		errChan chan<- error,
+	) error
+}
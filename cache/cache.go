@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ErrUnavailable indica che il backend della cache non risponde
+var ErrUnavailable = errors.New("cache unavailable")
+
+// Store è una cache chiave/valore con scadenza per i dati letti spesso (prezzi, saldi, posizioni)
+// I valori sono serializzati in JSON, così un backend condiviso (Redis) è leggibile da più processi
+type Store interface {
+	// Get decodifica in dest il valore di key; false se assente o scaduto
+	Get(ctx context.Context, key string, dest any) (bool, error)
+
+	// Set salva value in key per ttl
+	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+
+	// Delete rimuove le chiavi indicate
+	Delete(ctx context.Context, keys ...string) error
+
+	// Close rilascia le connessioni del backend
+	Close() error
+}
+
+// GetOrLoad restituisce il valore in cache di key o lo carica con load e lo salva per ttl
+// Gli errori della cache non bloccano la lettura: in quel caso il valore viene sempre caricato
+func GetOrLoad[T any](ctx context.Context, store Store, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	var cached T
+	found, err := store.Get(ctx, key, &cached)
+	if err != nil {
+		log.Printf("⚠️  Cache %s: %v", key, err)
+	} else if found {
+		return cached, nil
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return value, err
+	}
+	if err := store.Set(ctx, key, value, ttl); err != nil {
+		log.Printf("⚠️  Cache %s: %v", key, err)
+	}
+	return value, nil
+}
+
+// encode serializza un valore per il backend
+func encode(value any) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cache value: %w", err)
+	}
+	return data, nil
+}
+
+// decode deserializza un valore letto dal backend
+func decode(data []byte, dest any) error {
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("failed to decode cache value: %w", err)
+	}
+	return nil
+}
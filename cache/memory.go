@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryEntry è un valore serializzato con la sua scadenza
+type memoryEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// MemoryStore è una cache locale al processo, condivisa tra REST API e worker della stessa istanza
+// I valori sono serializzati come in Redis, così i chiamanti non condividono puntatori mutabili
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+// NewMemoryStore crea una cache in memoria vuota
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+// Get decodifica in dest il valore di key; le voci scadute vengono rimosse alla lettura
+func (s *MemoryStore) Get(ctx context.Context, key string, dest any) (bool, error) {
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	if ok && !time.Now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		ok = false
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+	return true, decode(entry.data, dest)
+}
+
+// Set salva value in key per ttl
+func (s *MemoryStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	data, err := encode(value)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryEntry{data: data, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Delete rimuove le chiavi indicate
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// Close svuota la cache
+func (s *MemoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[string]memoryEntry)
+	return nil
+}
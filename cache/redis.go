@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisOptions contiene i parametri di connessione a Redis
+type RedisOptions struct {
+	Addr     string // host:porta
+	Password string
+	DB       int
+	Prefix   string // Prefisso delle chiavi, per condividere un'istanza Redis tra più deployment
+}
+
+// RedisStore è una cache condivisa tra processi e host (più istanze del bot, dashboard)
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore si connette a Redis e verifica che risponda
+func NewRedisStore(ctx context.Context, options RedisOptions) (*RedisStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     options.Addr,
+		Password: options.Password,
+		DB:       options.DB,
+	})
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("%w: redis %s: %v", ErrUnavailable, options.Addr, err)
+	}
+	return &RedisStore{client: client, prefix: options.Prefix}, nil
+}
+
+// Get decodifica in dest il valore di key
+func (s *RedisStore) Get(ctx context.Context, key string, dest any) (bool, error) {
+	data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return true, decode(data, dest)
+}
+
+// Set salva value in key per ttl
+func (s *RedisStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	data, err := encode(value)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, s.prefix+key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// Delete rimuove le chiavi indicate
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = s.prefix + key
+	}
+	if err := s.client.Del(ctx, prefixed...).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// Close chiude le connessioni a Redis
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireScript rinnova il lease se owner lo detiene già, altrimenti lo acquisisce con SET NX PX
+// A ogni nuova acquisizione incrementa il fencing token della chiave, che non torna mai indietro:
+// restituisce il token del lease detenuto da owner, 0 se è detenuto da un'altra istanza
+var acquireScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return tonumber(redis.call('GET', KEYS[2]))
+end
+if not redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 0
+end
+return redis.call('INCR', KEYS[2])
+`)
+
+// releaseScript elimina il lease solo se detenuto da owner
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisLocker assegna lease condivisi tra istanze su host diversi, a differenza della tabella
+// distributed_locks che è visibile solo ai processi che aprono lo stesso file SQLite
+type RedisLocker struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisLocker si connette a Redis e verifica che risponda
+func NewRedisLocker(ctx context.Context, options RedisOptions) (*RedisLocker, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     options.Addr,
+		Password: options.Password,
+		DB:       options.DB,
+	})
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("%w: redis %s: %v", ErrUnavailable, options.Addr, err)
+	}
+	return &RedisLocker{client: client, prefix: options.Prefix}, nil
+}
+
+// TryAcquire acquisisce o rinnova il lease di key per owner; false se è detenuto da un'altra istanza
+func (l *RedisLocker) TryAcquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	token, err := l.TryAcquireToken(ctx, key, owner, ttl)
+	return token > 0, err
+}
+
+// TryAcquireToken acquisisce o rinnova il lease di key per owner e ne restituisce il fencing token,
+// 0 se è detenuto da un'altra istanza. Il token cresce a ogni passaggio di mano del lease:
+// un token diverso da quello dell'acquisizione precedente indica che il lease è stato perso nel frattempo
+func (l *RedisLocker) TryAcquireToken(ctx context.Context, key, owner string, ttl time.Duration) (int64, error) {
+	keys := []string{l.prefix + key, l.prefix + key + ":fence"}
+	token, err := acquireScript.Run(ctx, l.client, keys, owner, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return token, nil
+}
+
+// Release rilascia il lease se detenuto da owner
+func (l *RedisLocker) Release(ctx context.Context, key, owner string) error {
+	if err := releaseScript.Run(ctx, l.client, []string{l.prefix + key}, owner).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// Close chiude le connessioni a Redis
+func (l *RedisLocker) Close() error {
+	return l.client.Close()
+}
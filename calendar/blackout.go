@@ -0,0 +1,141 @@
+package calendar
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BlackoutConfig contiene i parametri delle finestre di blackout
+type BlackoutConfig struct {
+	Before    time.Duration // Inizio del blackout prima dell'evento
+	After     time.Duration // Fine del blackout dopo l'evento
+	Keywords  []string      // Eventi rilevanti per nome (es. FOMC, CPI); vuoto = tutti
+	Countries []string      // Paesi/valute rilevanti (es. USD); vuoto = tutti
+	MinImpact string        // Impatto minimo (Low, Medium, High); gli eventi senza impatto sono sempre inclusi
+	Refresh   time.Duration // Intervallo di rilettura del calendario
+}
+
+// Window rappresenta una finestra di blackout attorno a un evento
+type Window struct {
+	Event Event     `json:"event"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Contains verifica se l'istante cade nella finestra
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// Blackout calcola le finestre di blackout attorno agli eventi macro rilevanti
+// Durante una finestra i worker non aprono nuove posizioni
+type Blackout struct {
+	provider Provider
+	cfg      BlackoutConfig
+
+	mu          sync.Mutex
+	windows     []Window
+	refreshedAt time.Time
+}
+
+// NewBlackout crea un nuovo Blackout sul provider indicato
+func NewBlackout(provider Provider, cfg BlackoutConfig) *Blackout {
+	return &Blackout{provider: provider, cfg: cfg}
+}
+
+// Active restituisce la finestra di blackout in corso, se presente
+// Il calendario viene riletto ogni cfg.Refresh; se la lettura fallisce restano valide le finestre già note
+func (b *Blackout) Active(ctx context.Context, now time.Time) (*Window, bool) {
+	for _, window := range b.Windows(ctx) {
+		if window.Contains(now) {
+			return &window, true
+		}
+	}
+	return nil, false
+}
+
+// Windows restituisce le finestre di blackout degli eventi rilevanti, ordinate per inizio
+func (b *Blackout) Windows(ctx context.Context) []Window {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.refreshedAt.IsZero() || time.Since(b.refreshedAt) >= b.cfg.Refresh {
+		events, err := b.provider.Events(ctx)
+		if err != nil {
+			log.Printf("⚠️  Calendario economico non aggiornato: %v", err)
+		} else {
+			b.windows = b.buildWindows(events)
+		}
+		// Anche in caso di errore si attende il prossimo intervallo, per non interrogare il provider ad ogni ciclo
+		b.refreshedAt = time.Now()
+	}
+
+	windows := make([]Window, len(b.windows))
+	copy(windows, b.windows)
+	return windows
+}
+
+// buildWindows filtra gli eventi rilevanti e costruisce le relative finestre
+func (b *Blackout) buildWindows(events []Event) []Window {
+	windows := make([]Window, 0, len(events))
+	for _, event := range events {
+		if !b.matches(event) {
+			continue
+		}
+		windows = append(windows, Window{
+			Event: event,
+			Start: event.Time.Add(-b.cfg.Before),
+			End:   event.Time.Add(b.cfg.After),
+		})
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+	return windows
+}
+
+// matches verifica se un evento rientra nei filtri configurati
+func (b *Blackout) matches(event Event) bool {
+	if event.Impact != "" && impactLevel(event.Impact) < impactLevel(b.cfg.MinImpact) {
+		return false
+	}
+	if len(b.cfg.Countries) > 0 && event.Country != "" && !containsFold(b.cfg.Countries, event.Country) {
+		return false
+	}
+	if len(b.cfg.Keywords) == 0 {
+		return true
+	}
+	name := strings.ToLower(event.Name)
+	for _, keyword := range b.cfg.Keywords {
+		if strings.Contains(name, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// impactLevel converte l'impatto in un livello confrontabile; valori sconosciuti valgono 0
+func impactLevel(impact string) int {
+	switch strings.ToLower(impact) {
+	case "low":
+		return 1
+	case "medium":
+		return 2
+	case "high":
+		return 3
+	default:
+		return 0
+	}
+}
+
+// containsFold verifica se values contiene value ignorando maiuscole e minuscole
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Event rappresenta un evento macroeconomico del calendario (es. FOMC, CPI)
+type Event struct {
+	Name    string    `json:"name"`
+	Country string    `json:"country,omitempty"` // Valuta o paese interessato (es. USD)
+	Impact  string    `json:"impact,omitempty"`  // Low, Medium, High; vuoto se non specificato
+	Time    time.Time `json:"time"`
+}
+
+// Provider fornisce gli eventi del calendario economico
+type Provider interface {
+	Events(ctx context.Context) ([]Event, error)
+}
+
+// FileProvider legge gli eventi da un file JSON statico mantenuto a mano
+// Il file contiene un array di eventi con name, time (RFC3339) e opzionalmente country e impact
+type FileProvider struct {
+	path string
+}
+
+// NewFileProvider crea un provider che legge il calendario dal file indicato
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+// Events legge e decodifica il file ad ogni chiamata, così le modifiche sono visibili senza riavvio
+func (p *FileProvider) Events(ctx context.Context) ([]Event, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("errore lettura calendario %s: %w", p.path, err)
+	}
+
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("errore decodifica calendario %s: %w", p.path, err)
+	}
+	for i, event := range events {
+		if event.Name == "" || event.Time.IsZero() {
+			return nil, fmt.Errorf("evento %d del calendario %s senza nome o orario", i, p.path)
+		}
+	}
+	return events, nil
+}
+
+// HTTPProvider scarica gli eventi da un feed JSON nel formato di Forex Factory
+// (array di oggetti con title, country, date RFC3339 e impact)
+type HTTPProvider struct {
+	url        string
+	httpClient *http.Client
+}
+
+// httpEvent rappresenta un evento del feed HTTP
+type httpEvent struct {
+	Title   string `json:"title"`
+	Country string `json:"country"`
+	Date    string `json:"date"`
+	Impact  string `json:"impact"`
+}
+
+// NewHTTPProvider crea un provider che scarica il calendario dall'URL indicato
+func NewHTTPProvider(url string) *HTTPProvider {
+	return &HTTPProvider{
+		url:        url,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Events scarica il feed e converte gli eventi; quelli con data non valida vengono scartati
+func (p *HTTPProvider) Events(ctx context.Context) ([]Event, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("errore creazione richiesta calendario: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("errore download calendario: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("errore lettura risposta calendario: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calendario: status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var feed []httpEvent
+	if err := json.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("errore decodifica calendario: %w", err)
+	}
+
+	events := make([]Event, 0, len(feed))
+	for _, item := range feed {
+		eventTime, err := time.Parse(time.RFC3339, item.Date)
+		if err != nil {
+			continue
+		}
+		events = append(events, Event{
+			Name:    item.Title,
+			Country: item.Country,
+			Impact:  item.Impact,
+			Time:    eventTime,
+		})
+	}
+	return events, nil
+}
@@ -0,0 +1,233 @@
+// Package chart disegna i grafici a candele dei trade aperti (PNG), con ingresso, stop loss, take profit
+// e livello rotto evidenziati, per una verifica visiva rapida del segnale
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// Dimensioni di default del grafico in pixel
+const (
+	DefaultWidth  = 800
+	DefaultHeight = 400
+)
+
+// Margini dell'area delle candele; a destra c'è lo spazio per le etichette colorate delle linee
+const (
+	marginTop    = 12
+	marginBottom = 12
+	marginLeft   = 8
+	marginRight  = 56
+	labelWidth   = 44
+	labelHeight  = 9
+	gridLines    = 6
+)
+
+// Colori del grafico, su sfondo scuro
+var (
+	colorBackground = color.RGBA{R: 19, G: 23, B: 34, A: 255}
+	colorGrid       = color.RGBA{R: 42, G: 46, B: 57, A: 255}
+	colorUp         = color.RGBA{R: 38, G: 166, B: 154, A: 255}
+	colorDown       = color.RGBA{R: 239, G: 83, B: 80, A: 255}
+	colorEntry      = color.RGBA{R: 41, G: 98, B: 255, A: 255}
+	colorStopLoss   = color.RGBA{R: 255, G: 82, B: 82, A: 255}
+	colorTakeProfit = color.RGBA{R: 0, G: 200, B: 83, A: 255}
+	colorLevel      = color.RGBA{R: 255, G: 152, B: 0, A: 255}
+)
+
+// chartIDPattern limita gli identificativi usati come nome di file (ID degli ordini Bybit e simulati)
+var chartIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Annotations sono i prezzi evidenziati sul grafico; un prezzo a 0 non viene disegnato
+type Annotations struct {
+	Side       models.OrderSide // Direzione dell'ingresso, indicata da una freccia sull'ultima candela
+	Entry      float64          // Linea blu continua
+	StopLoss   float64          // Linea rossa tratteggiata
+	TakeProfit float64          // Linea verde tratteggiata
+	Level      float64          // Livello rotto (muro o supporto), linea arancione punteggiata
+}
+
+// Options contiene le dimensioni del grafico; i valori a 0 usano quelle di default
+type Options struct {
+	Width  int
+	Height int
+}
+
+// canvas è l'immagine con la scala dei prezzi dell'area delle candele
+type canvas struct {
+	img            *image.RGBA
+	top, bottom    int
+	left, right    int
+	minPrice, span float64
+}
+
+// RenderPNG disegna le candele (dalla più vecchia alla più recente) con le linee delle annotazioni
+func RenderPNG(candles []models.Candle, annotations Annotations, opts Options) ([]byte, error) {
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("nessuna candela da disegnare")
+	}
+	width, height := opts.Width, opts.Height
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	if height <= 0 {
+		height = DefaultHeight
+	}
+	if width <= marginLeft+marginRight+len(candles) || height <= marginTop+marginBottom+gridLines {
+		return nil, fmt.Errorf("dimensioni %dx%d troppo piccole per %d candele", width, height, len(candles))
+	}
+
+	c := newCanvas(width, height, candles, annotations)
+	c.drawGrid()
+	c.drawCandles(candles)
+	c.drawLine(annotations.Level, colorLevel, 2, 4)
+	c.drawLine(annotations.StopLoss, colorStopLoss, 6, 4)
+	c.drawLine(annotations.TakeProfit, colorTakeProfit, 6, 4)
+	c.drawLine(annotations.Entry, colorEntry, 1, 0)
+	c.drawArrow(candles, annotations.Side)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.img); err != nil {
+		return nil, fmt.Errorf("errore nella codifica del grafico: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Path restituisce il percorso del grafico di un ordine nella cartella indicata
+// L'ID diventa il nome del file, quindi sono ammessi solo lettere, cifre, trattini e underscore
+func Path(dir, orderID string) (string, error) {
+	if !chartIDPattern.MatchString(orderID) {
+		return "", fmt.Errorf("ID ordine non valido per il grafico: %q", orderID)
+	}
+	return filepath.Join(dir, orderID+".png"), nil
+}
+
+// Save scrive il grafico di un ordine nella cartella indicata, creandola se serve, e ne restituisce il percorso
+func Save(dir, orderID string, data []byte) (string, error) {
+	path, err := Path(dir, orderID)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("impossibile creare la cartella dei grafici: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("impossibile salvare il grafico: %w", err)
+	}
+	return path, nil
+}
+
+// newCanvas crea l'immagine e calcola la scala dei prezzi includendo candele e annotazioni, con un margine del 5%
+func newCanvas(width, height int, candles []models.Candle, annotations Annotations) *canvas {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, candle := range candles {
+		low = math.Min(low, candle.Low)
+		high = math.Max(high, candle.High)
+	}
+	for _, price := range []float64{annotations.Entry, annotations.StopLoss, annotations.TakeProfit, annotations.Level} {
+		if price > 0 {
+			low = math.Min(low, price)
+			high = math.Max(high, price)
+		}
+	}
+	pad := (high - low) * 0.05
+	if pad <= 0 {
+		pad = math.Max(high*0.001, 1e-9)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, 0, 0, width, height, colorBackground)
+	return &canvas{
+		img:      img,
+		top:      marginTop,
+		bottom:   height - marginBottom,
+		left:     marginLeft,
+		right:    width - marginRight,
+		minPrice: low - pad,
+		span:     high - low + 2*pad,
+	}
+}
+
+// y converte un prezzo nella riga dell'immagine
+func (c *canvas) y(price float64) int {
+	return c.bottom - int(math.Round((price-c.minPrice)/c.span*float64(c.bottom-c.top)))
+}
+
+// drawGrid disegna le linee orizzontali di riferimento
+func (c *canvas) drawGrid() {
+	for i := 0; i <= gridLines; i++ {
+		y := c.top + (c.bottom-c.top)*i/gridLines
+		fillRect(c.img, c.left, y, c.right, y+1, colorGrid)
+	}
+}
+
+// drawCandles disegna corpo e stoppini delle candele, distribuite sulla larghezza dell'area
+func (c *canvas) drawCandles(candles []models.Candle) {
+	slot := float64(c.right-c.left) / float64(len(candles))
+	body := max(1, int(slot*0.7))
+	for i, candle := range candles {
+		clr := colorUp
+		if candle.Close < candle.Open {
+			clr = colorDown
+		}
+		center := c.left + int(slot*(float64(i)+0.5))
+		fillRect(c.img, center, c.y(candle.High), center+1, c.y(candle.Low)+1, clr)
+
+		top, bottom := c.y(math.Max(candle.Open, candle.Close)), c.y(math.Min(candle.Open, candle.Close))
+		fillRect(c.img, center-body/2, top, center-body/2+body, bottom+1, clr)
+	}
+}
+
+// drawLine disegna una linea orizzontale al prezzo indicato, tratteggiata se gap > 0, con l'etichetta colorata a destra
+func (c *canvas) drawLine(price float64, clr color.RGBA, dash, gap int) {
+	if price <= 0 {
+		return
+	}
+	y := c.y(price)
+	for x := c.left; x < c.right; x += dash + gap {
+		fillRect(c.img, x, y, min(x+dash, c.right), y+1, clr)
+	}
+	fillRect(c.img, c.right+4, y-labelHeight/2, c.right+4+labelWidth, y-labelHeight/2+labelHeight, clr)
+}
+
+// drawArrow indica la direzione dell'ingresso sotto (long) o sopra (short) l'ultima candela
+func (c *canvas) drawArrow(candles []models.Candle, side models.OrderSide) {
+	if side == "" {
+		return
+	}
+	last := candles[len(candles)-1]
+	slot := float64(c.right-c.left) / float64(len(candles))
+	center := c.left + int(slot*(float64(len(candles))-0.5))
+	const size = 6
+	for row := 0; row < size; row++ {
+		if side == models.OrderSideSell {
+			// Punta verso il basso, sopra il massimo
+			y := c.y(last.High) - 4 - row
+			fillRect(c.img, center-row, y, center+row+1, y+1, colorEntry)
+		} else {
+			// Punta verso l'alto, sotto il minimo
+			y := c.y(last.Low) + 4 + row
+			fillRect(c.img, center-row, y, center+row+1, y+1, colorEntry)
+		}
+	}
+}
+
+// fillRect colora il rettangolo [x0, x1) x [y0, y1), limitato ai bordi dell'immagine
+func fillRect(img *image.RGBA, x0, y0, x1, y1 int, clr color.RGBA) {
+	rect := image.Rect(x0, y0, x1, y1).Intersect(img.Bounds())
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img.SetRGBA(x, y, clr)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/services"
+)
+
+// cliAction descrive un sottocomando che modifica lo stato del bot, attribuito all'utente di sistema
+func cliAction(action, target string, details any, err error) services.OperatorAction {
+	return services.OperatorAction{
+		Actor:   services.LocalOperator(),
+		Channel: models.OperatorChannelCLI,
+		Action:  action,
+		Target:  target,
+		Details: details,
+		Err:     err,
+	}
+}
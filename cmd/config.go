@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/services"
+)
+
+// runConfigCommand gestisce le versioni della configurazione di strategia e rischio
+func runConfigCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: mkybot config list | mkybot config show VERSION | mkybot config rollback VERSION")
+		return 2
+	}
+
+	switch args[0] {
+	case "list":
+		return runConfigList(args[1:])
+	case "show":
+		return runConfigShow(args[1:])
+	case "rollback":
+		return runConfigRollback(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown config command %q\n", args[0])
+		return 2
+	}
+}
+
+// runConfigList elenca le versioni dalla più recentemente applicata
+func runConfigList(args []string) int {
+	fs := flag.NewFlagSet("config list", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "maximum number of versions")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	return withConfigVersions(func(ctx context.Context, versionService *services.ConfigVersionService, _ *services.OperatorAuditService) error {
+		versions, err := versionService.List(ctx, *limit)
+		if err != nil {
+			return err
+		}
+		for _, version := range versions {
+			fmt.Printf("%s  first applied %s  last applied %s\n", version.ShortHash(),
+				version.CreatedAt.UTC().Format(time.RFC3339), version.LastAppliedAt.UTC().Format(time.RFC3339))
+		}
+		return nil
+	})
+}
+
+// runConfigShow stampa i parametri effettivi e le variabili d'ambiente di una versione
+func runConfigShow(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: mkybot config show VERSION")
+		return 2
+	}
+
+	return withConfigVersions(func(ctx context.Context, versionService *services.ConfigVersionService, _ *services.OperatorAuditService) error {
+		version, err := versionService.Get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		var settings bytes.Buffer
+		if err := json.Indent(&settings, []byte(version.Settings), "", "  "); err != nil {
+			return fmt.Errorf("failed to decode config version %s: %w", version.ShortHash(), err)
+		}
+		fmt.Printf("Version %s (last applied %s)\n\nEnvironment:\n%s\n\nEffective settings:\n%s\n",
+			version.Hash, version.LastAppliedAt.UTC().Format(time.RFC3339), version.Env, settings.String())
+		return nil
+	})
+}
+
+// runConfigRollback riscrive le variabili di strategia del file .env con quelle di una versione precedente
+func runConfigRollback(args []string) int {
+	fs := flag.NewFlagSet("config rollback", flag.ContinueOnError)
+	envFile := fs.String("env", ".env", "environment file to rewrite")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: mkybot config rollback [-env .env] VERSION")
+		return 2
+	}
+
+	return withConfigVersions(func(ctx context.Context, versionService *services.ConfigVersionService, audit *services.OperatorAuditService) error {
+		version, err := versionService.Rollback(ctx, fs.Arg(0), *envFile)
+		audit.Record(ctx, cliAction("config rollback", fs.Arg(0), map[string]string{"env": *envFile}, err))
+		if err != nil {
+			return err
+		}
+		fmt.Println(i18n.T("cli.config_rolled_back", *envFile, version.ShortHash()))
+		return nil
+	})
+}
+
+// withConfigVersions apre il database ed esegue fn con il servizio delle versioni di configurazione
+// e quello dell'audit degli operatori, con cui registrare i sottocomandi che modificano la configurazione
+func withConfigVersions(fn func(ctx context.Context, versionService *services.ConfigVersionService, audit *services.OperatorAuditService) error) int {
+	db, err := database.InitializeDatabaseWithData(database.DefaultConfig())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.open_db_failed", err))
+		return 1
+	}
+	defer database.Close(db)
+
+	repoManager := repositories.NewRepositoryManager(db)
+	versionService := services.NewConfigVersionService(repoManager)
+	if err := fn(context.Background(), versionService, services.NewOperatorAuditService(repoManager)); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/reporting"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/services"
+)
+
+// runDatasetCommand gestisce l'export dei dataset di addestramento
+func runDatasetCommand(args []string) int {
+	if len(args) == 0 || args[0] != "export" {
+		fmt.Fprintln(os.Stderr, "usage: mkybot dataset export [-symbol DOGEUSDT] [-from DATE] [-to DATE] [-format csv|parquet] [-out FILE]")
+		return 2
+	}
+	return runDatasetExport(args[1:])
+}
+
+// runDatasetExport esporta un esempio etichettato per ogni ordine chiuso, con le feature ricostruite dalla cache delle candele
+func runDatasetExport(args []string) int {
+	fs := flag.NewFlagSet("dataset export", flag.ContinueOnError)
+	symbol := fs.String("symbol", "DOGEUSDT", "symbol of the orders to export")
+	fromFlag := fs.String("from", "", "first order creation date, YYYY-MM-DD or RFC3339 (default 90 days ago)")
+	toFlag := fs.String("to", "", "order creation date upper bound, YYYY-MM-DD or RFC3339 (default now)")
+	format := fs.String("format", "csv", "output format: csv or parquet")
+	out := fs.String("out", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "csv" && *format != "parquet" {
+		fmt.Fprintf(os.Stderr, "unsupported format %q (use csv or parquet)\n", *format)
+		return 2
+	}
+
+	to := time.Now().UTC()
+	if *toFlag != "" {
+		parsed, err := parseDatasetTime(*toFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -to: %v\n", err)
+			return 2
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -90)
+	if *fromFlag != "" {
+		parsed, err := parseDatasetTime(*fromFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -from: %v\n", err)
+			return 2
+		}
+		from = parsed
+	}
+
+	// Le feature devono essere calcolate sulle stesse sorgenti di prezzo del worker live
+	sources, err := config.IndicatorSources()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	db, err := database.InitializeDatabaseWithData(database.DefaultConfig())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.open_db_failed", err))
+		return 1
+	}
+	defer database.Close(db)
+
+	datasetService := services.NewDatasetService(repositories.NewRepositoryManager(db), sources)
+	rows, skipped, err := datasetService.BuildTrainingSet(context.Background(), *symbol, from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if *format == "parquet" {
+		err = reporting.WriteDatasetParquet(w, rows)
+	} else {
+		err = reporting.WriteDatasetCSV(w, rows)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write dataset: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintln(os.Stderr, i18n.T("cli.dataset_exported", len(rows), skipped))
+	return 0
+}
+
+// parseDatasetTime accetta una data (YYYY-MM-DD, UTC) o un timestamp RFC3339
+func parseDatasetTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/orderprocessor"
+)
+
+// runDebugCommand esegue i sottocomandi di debug
+func runDebugCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: mkybot debug sign -method POST -path /v5/order/create -body '{...}'")
+		fmt.Fprintln(os.Stderr, "       mkybot debug outage -mode timeout -down 5 -up 3")
+		return 2
+	}
+
+	switch args[0] {
+	case "sign":
+		return runDebugSign(args[1:])
+	case "outage":
+		return runDebugOutage(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown debug command %q\n", args[0])
+		return 2
+	}
+}
+
+// runDebugSign stampa payload, timestamp e firma che verrebbero inviati a Bybit, senza inviare nulla
+// Serve a diagnosticare gli errori 10004 (signature error) confrontando la firma con quella attesa
+func runDebugSign(args []string) int {
+	fs := flag.NewFlagSet("debug sign", flag.ContinueOnError)
+	method := fs.String("method", "GET", "HTTP method (GET or POST)")
+	path := fs.String("path", "", "endpoint path including the query string, e.g. /v5/order/realtime?category=linear&symbol=DOGEUSDT")
+	body := fs.String("body", "", "JSON body for POST requests, exactly as it would be sent")
+	timestamp := fs.Int64("timestamp", 0, "timestamp in milliseconds to reproduce a previous signature (default: now)")
+	recvWindow := fs.String("recv-window", "", "recv window in milliseconds (default: the one used by the bot)")
+	readOnly := fs.Bool("readonly", false, "sign with the read-only key used by analytics instead of the trading key")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *path == "" {
+		fmt.Fprintln(os.Stderr, "-path is required")
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+
+	at := time.Now()
+	if *timestamp > 0 {
+		at = time.UnixMilli(*timestamp)
+	}
+
+	creds := cfg.Bybit.BybitCredentials
+	if *readOnly {
+		creds = cfg.Bybit.ReadOnly
+	}
+
+	signer, err := orderprocessor.NewSigner(creds.AuthType, creds.SecretKey, creds.RSAPrivateKeyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to configure signer: %v\n", err)
+		return 1
+	}
+
+	signed, err := orderprocessor.SignBybitRequest(creds.APIKey, signer, *method, *path, *body, *recvWindow, at)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to sign request: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Method:       %s\n", signed.Method)
+	fmt.Printf("Path:         %s\n", signed.Path)
+	if signed.Query != "" {
+		fmt.Printf("Query:        %s\n", signed.Query)
+	}
+	if signed.Body != "" {
+		fmt.Printf("Body:         %s\n", signed.Body)
+	}
+	fmt.Printf("Timestamp:    %s (%s)\n", signed.Timestamp, at.UTC().Format(time.RFC3339Nano))
+	fmt.Printf("Recv window:  %s\n", signed.RecvWindow)
+	fmt.Printf("Sign payload: %s\n", signed.Payload)
+	fmt.Printf("Auth type:    %s\n", signed.AuthType)
+	fmt.Printf("Signature:    %s\n", signed.Signature)
+
+	fmt.Println("\nHeaders:")
+	headers := signed.Headers(creds.APIKey)
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %s: %s\n", name, headers[name])
+	}
+
+	if len(signed.Warnings) > 0 {
+		fmt.Println("\n⚠️  Warnings:")
+		for _, warning := range signed.Warnings {
+			fmt.Printf("  - %s\n", warning)
+		}
+	}
+
+	// Il timestamp deve cadere nella recv window rispetto all'orario del server Bybit
+	if window, err := strconv.ParseInt(signed.RecvWindow, 10, 64); err == nil && *timestamp > 0 {
+		if age := time.Since(at).Milliseconds(); age > window {
+			fmt.Printf("\n⚠️  The timestamp is %dms old: Bybit would reject it (recv window %dms)\n", age, window)
+		}
+	}
+
+	return 0
+}
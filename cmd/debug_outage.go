@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/outage"
+	"cross-exchange-arbitrage/risk"
+)
+
+// runDebugOutage simula un'interruzione di un exchange con la policy della modalità degradata configurata
+// Le richieste vanno a un server locale attraverso lo stesso Transport dei client degli exchange:
+// nella fase "down" ogni richiesta subisce il guasto scelto, nella fase "up" il server risponde normalmente.
+// Il comando verifica che la venue entri e esca dalla modalità degradata quando previsto dalla policy,
+// che in freeze i nuovi ingressi siano bloccati e che la recvWindow venga allargata; esce con 1 se qualcosa non torna
+func runDebugOutage(args []string) int {
+	fs := flag.NewFlagSet("debug outage", flag.ContinueOnError)
+	venue := fs.String("venue", "bybit", "venue to simulate the outage on")
+	mode := fs.String("mode", "error", "failure to inject: error (HTTP 503) or timeout")
+	down := fs.Int("down", 5, "requests sent while the exchange is down")
+	up := fs.Int("up", 3, "requests sent after the exchange is back")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *down < 0 || *up < 0 {
+		fmt.Fprintln(os.Stderr, "-down and -up must not be negative")
+		return 2
+	}
+
+	chaos := &outage.Chaos{}
+	switch *mode {
+	case "error":
+		chaos.ErrorRate = 1
+	case "timeout":
+		chaos.TimeoutRate = 1
+	default:
+		fmt.Fprintf(os.Stderr, "invalid -mode %q: expected error or timeout\n", *mode)
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	policy := outage.Policy{
+		Action:     cfg.Degraded.Action,
+		Failures:   cfg.Degraded.Failures,
+		Recovery:   cfg.Degraded.Recovery,
+		RecvWindow: cfg.Degraded.RecvWindow,
+	}
+	monitor, err := outage.NewMonitor(policy, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid degraded mode policy: %v\n", err)
+		return 1
+	}
+	manager := risk.NewManager(risk.Limits{})
+	manager.AddEntryGate(monitor.CheckEntry)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"retCode":0,"retMsg":"OK"}`)
+	}))
+	defer server.Close()
+	client := outage.NewClient(*venue, &http.Client{Timeout: 200 * time.Millisecond})
+
+	fmt.Printf("Policy: action %s, degraded after %d failures, recovered after %d successes, recv window %v\n\n",
+		policy.Action, policy.Failures, policy.Recovery, policy.RecvWindow)
+
+	const normalRecvWindow = "5000"
+	failed := false
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			failed = true
+			fmt.Printf("  ❌ "+format+"\n", args...)
+		}
+	}
+	step := func(phase string, i int) {
+		result := "ok"
+		resp, err := client.Get(server.URL + "/v5/market/time")
+		if err != nil {
+			result = err.Error()
+		} else {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			result = resp.Status
+		}
+		entry := "allowed"
+		if err := manager.CheckEntry(); err != nil {
+			entry = "blocked (" + err.Error() + ")"
+		}
+		fmt.Printf("%-4s #%d  %-40.40s degraded=%-5t recvWindow=%-5s entries %s\n",
+			phase, i, result, monitor.IsDegraded(*venue), monitor.RecvWindow(*venue, normalRecvWindow), entry)
+	}
+
+	if err := outage.Install(monitor, chaos); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to install chaos: %v\n", err)
+		return 1
+	}
+	for i := 1; i <= *down; i++ {
+		step("down", i)
+		shouldDegrade := i >= policy.Failures
+		check(monitor.IsDegraded(*venue) == shouldDegrade, "expected degraded=%t after %d failures", shouldDegrade, i)
+		if shouldDegrade {
+			check((manager.CheckEntry() != nil) == (policy.Action == outage.ActionFreeze), "entries not handled as %s", policy.Action)
+			if policy.RecvWindow > 0 {
+				check(monitor.RecvWindow(*venue, normalRecvWindow) != normalRecvWindow, "recv window not widened")
+			}
+		}
+	}
+
+	wasDegraded := monitor.IsDegraded(*venue)
+	if err := outage.Install(monitor, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to remove chaos: %v\n", err)
+		return 1
+	}
+	for i := 1; i <= *up; i++ {
+		step("up", i)
+		shouldDegrade := wasDegraded && i < policy.Recovery
+		check(monitor.IsDegraded(*venue) == shouldDegrade, "expected degraded=%t after %d successes", shouldDegrade, i)
+		if !shouldDegrade {
+			check(manager.CheckEntry() == nil, "entries still blocked after recovery")
+			check(monitor.RecvWindow(*venue, normalRecvWindow) == normalRecvWindow, "recv window not restored")
+		}
+	}
+
+	if failed {
+		fmt.Println("\n❌ The degraded mode policy did not behave as configured")
+		return 1
+	}
+	fmt.Println("\n✅ The degraded mode policy behaved as configured")
+	return 0
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/keystore"
+)
+
+// runKeysCommand gestisce le credenziali salvate nel file cifrato
+func runKeysCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: mkybot keys set NAME | mkybot keys list")
+		return 2
+	}
+
+	switch args[0] {
+	case "set":
+		return runKeysSet(args[1:])
+	case "list":
+		return runKeysList(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown keys command %q\n", args[0])
+		return 2
+	}
+}
+
+// runKeysSet salva una credenziale nel file cifrato, creandolo se non esiste
+// Il valore viene letto senza eco dal terminale (o dallo stdin) per non finire nella history della shell
+func runKeysSet(args []string) int {
+	fs := flag.NewFlagSet("keys set", flag.ContinueOnError)
+	file := fs.String("file", config.CredentialsFile(), "encrypted credentials file")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: mkybot keys set [-file path] NAME   (e.g. BYBIT_SECRET_KEY)")
+		return 2
+	}
+	name := fs.Arg(0)
+
+	store, passphrase, err := openOrCreateStore(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	value, err := keystore.ReadSecret(fmt.Sprintf("Value for %s: ", name))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	if value == "" {
+		fmt.Fprintln(os.Stderr, "value cannot be empty")
+		return 1
+	}
+
+	if err := store.Set(name, value); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	if err := store.Save(*file, passphrase); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	fmt.Println(i18n.T("cli.key_saved", name, *file))
+	return 0
+}
+
+// runKeysList elenca i nomi delle credenziali salvate, senza mostrarne i valori
+func runKeysList(args []string) int {
+	fs := flag.NewFlagSet("keys list", flag.ContinueOnError)
+	file := fs.String("file", config.CredentialsFile(), "encrypted credentials file")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	passphrase, err := keystore.ReadPassphrase("Credentials passphrase: ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	store, err := keystore.Load(*file, passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	for _, name := range store.Names() {
+		fmt.Println(name)
+	}
+	return 0
+}
+
+// openOrCreateStore apre il file cifrato esistente o ne prepara uno nuovo con una nuova passphrase
+func openOrCreateStore(path string) (*keystore.Store, []byte, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.keystore_creating", path))
+		passphrase, err := keystore.ReadNewPassphrase()
+		if err != nil {
+			return nil, nil, err
+		}
+		return keystore.NewStore(), passphrase, nil
+	}
+
+	passphrase, err := keystore.ReadPassphrase("Credentials passphrase: ")
+	if err != nil {
+		return nil, nil, err
+	}
+	store, err := keystore.Load(path, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, passphrase, nil
+}
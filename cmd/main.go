@@ -1,12 +1,58 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/i18n"
 	"cross-exchange-arbitrage/worker"
 )
 
 // Esempio di utilizzo del nuovo sistema worker con cron
 func main() {
+	// Sottocomandi di utilità (es. debug sign); senza argomenti avvia il bot
+	if len(os.Args) > 1 {
+		// Lingua dell'output (LOCALE); con un valore non valido resta quella predefinita
+		if locale, err := i18n.ParseLocale(config.Locale()); err == nil {
+			i18n.SetLocale(locale)
+		}
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	// Avvia il sistema worker completo
 	// Questo sostituisce il vecchio sistema con ticker
 	worker.StartWorkerSystem()
 }
+
+// runCommand esegue un sottocomando e restituisce l'exit code
+func runCommand(args []string) int {
+	switch args[0] {
+	case "debug":
+		return runDebugCommand(args[1:])
+	case "keys":
+		return runKeysCommand(args[1:])
+	case "config":
+		return runConfigCommand(args[1:])
+	case "dataset":
+		return runDatasetCommand(args[1:])
+	case "state":
+		return runStateCommand(args[1:])
+	case "tax":
+		return runTaxCommand(args[1:])
+	case "subaccount":
+		return runSubAccountCommand(args[1:])
+	case "help", "-h", "--help":
+		printUsage()
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "%s\n\n", i18n.T("cli.unknown_command", args[0]))
+		printUsage()
+		return 2
+	}
+}
+
+// printUsage stampa l'elenco dei comandi disponibili
+func printUsage() {
+	fmt.Fprintln(os.Stderr, i18n.T("cli.usage"))
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/services"
+)
+
+// runStateCommand gestisce l'export e l'import dello stato del bot per la migrazione tra host
+func runStateCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: mkybot state export [-out FILE] | mkybot state import [-force] FILE")
+		return 2
+	}
+
+	switch args[0] {
+	case "export":
+		return runStateExport(args[1:])
+	case "import":
+		return runStateImport(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown state command %q\n", args[0])
+		return 2
+	}
+}
+
+// runStateExport scrive l'archivio con database, credenziali cifrate e, se richiesto, il file .env
+func runStateExport(args []string) int {
+	fs := flag.NewFlagSet("state export", flag.ContinueOnError)
+	out := fs.String("out", "mkybot-state-"+time.Now().UTC().Format("20060102T150405")+".tar.gz", "output archive")
+	credentials := fs.String("credentials", config.CredentialsFile(), "encrypted credentials file to include if present (empty to skip)")
+	envFile := fs.String("env", "", "environment file to include (may contain plaintext secrets)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	db, err := database.InitializeDatabaseWithData(database.DefaultConfig())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.open_db_failed", err))
+		return 1
+	}
+	defer database.Close(db)
+
+	file, err := os.OpenFile(*out, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	archiveService := services.NewStateArchiveService(db, repositories.NewRepositoryManager(db))
+	manifest, err := archiveService.Export(context.Background(), file, services.StateExportOptions{
+		CredentialsFile: *credentials,
+		EnvFile:         *envFile,
+	})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*out)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	fmt.Println(i18n.T("cli.state_exported", *out))
+	printStateManifest(manifest)
+	return 0
+}
+
+// runStateImport verifica l'archivio e ripristina i file; il bot deve essere fermo
+func runStateImport(args []string) int {
+	fs := flag.NewFlagSet("state import", flag.ContinueOnError)
+	dbPath := fs.String("db", database.DefaultConfig().FilePath, "database file to restore")
+	credentials := fs.String("credentials", config.CredentialsFile(), "encrypted credentials file to restore")
+	envFile := fs.String("env", ".env", "environment file to restore, if the archive contains one")
+	force := fs.Bool("force", false, "replace existing files, keeping a .bak copy")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: mkybot state import [-db path] [-credentials path] [-env path] [-force] FILE")
+		return 2
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	defer file.Close()
+
+	manifest, err := services.ImportStateArchive(file, services.StateImportOptions{
+		DatabasePath:    *dbPath,
+		CredentialsFile: *credentials,
+		EnvFile:         *envFile,
+		Force:           *force,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	recordStateImport(*dbPath, fs.Arg(0), manifest)
+
+	fmt.Println(i18n.T("cli.state_restored", manifest.Host, manifest.CreatedAt.Format(time.RFC3339)))
+	printStateManifest(manifest)
+	if manifest.ConfigHash != "" {
+		fmt.Println(i18n.T("cli.state_config_hint"))
+	}
+	return 0
+}
+
+// recordStateImport registra il ripristino nell'audit degli operatori del database appena ripristinato
+// Il ripristino è già avvenuto: un errore di apertura del database viene solo segnalato
+func recordStateImport(dbPath, archive string, manifest *services.StateManifest) {
+	dbConfig := database.DefaultConfig()
+	dbConfig.FilePath = dbPath
+	db, err := database.InitializeDatabaseWithData(dbConfig)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.open_db_failed", err))
+		return
+	}
+	defer database.Close(db)
+
+	details := map[string]string{"host": manifest.Host, "created_at": manifest.CreatedAt.Format(time.RFC3339), "config_hash": manifest.ConfigHash}
+	services.NewOperatorAuditService(repositories.NewRepositoryManager(db)).Record(context.Background(), cliAction("state import", archive, details, nil))
+}
+
+// printStateManifest stampa file, versione della configurazione e righe per tabella dell'archivio
+func printStateManifest(manifest *services.StateManifest) {
+	for _, file := range manifest.Files {
+		fmt.Printf("  %-16s %10d bytes  sha256 %s\n", file.Name, file.Size, file.SHA256)
+	}
+	if manifest.ConfigHash != "" {
+		fmt.Printf("  config version   %s\n", manifest.ConfigHash)
+	}
+
+	tables := make([]string, 0, len(manifest.Tables))
+	for table := range manifest.Tables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		fmt.Printf("  %-24s %d rows\n", table, manifest.Tables[table])
+	}
+}
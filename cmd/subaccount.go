@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/services"
+)
+
+// runSubAccountCommand gestisce i sub-account Bybit in cui isolare le strategie
+func runSubAccountCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: mkybot subaccount create [-username NAME] [-ips IP,...] STRATEGY | mkybot subaccount list")
+		return 2
+	}
+
+	switch args[0] {
+	case "create":
+		return runSubAccountCreate(args[1:])
+	case "list":
+		return runSubAccountList(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown subaccount command %q\n", args[0])
+		return 2
+	}
+}
+
+// runSubAccountCreate crea il sub-account di una strategia e la sua API key con la key del master account
+// Il secret della key esiste solo nella risposta dell'exchange e viene salvato direttamente nel file cifrato
+func runSubAccountCreate(args []string) int {
+	fs := flag.NewFlagSet("subaccount create", flag.ContinueOnError)
+	username := fs.String("username", "", "sub-account username, 6-16 letters and digits (default: derived from the strategy)")
+	ips := fs.String("ips", "", "comma-separated IPs allowed to use the key (default: no restriction, the key expires after 90 days)")
+	file := fs.String("file", config.CredentialsFile(), "encrypted credentials file where the key is stored")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: mkybot subaccount create [-username NAME] [-ips IP,...] STRATEGY   (one of: %s)\n", strings.Join(config.SubAccountStrategies, ", "))
+		return 2
+	}
+	strategy := fs.Arg(0)
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	if !cfg.Bybit.HasCredentials() {
+		fmt.Fprintln(os.Stderr, "the master account trading key (BYBIT_API_KEY) is not configured")
+		return 1
+	}
+	signer, err := orderprocessor.NewSigner(cfg.Bybit.AuthType, cfg.Bybit.SecretKey, cfg.Bybit.RSAPrivateKeyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to configure signer: %v\n", err)
+		return 1
+	}
+
+	// Il file cifrato viene aperto prima di creare la key: con una passphrase errata il secret andrebbe perso
+	store, passphrase, err := openOrCreateStore(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	db, err := database.InitializeDatabaseWithData(database.DefaultConfig())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.open_db_failed", err))
+		return 1
+	}
+	defer database.Close(db)
+
+	var allowedIPs []string
+	for _, ip := range strings.Split(*ips, ",") {
+		if ip = strings.TrimSpace(ip); ip != "" {
+			allowedIPs = append(allowedIPs, ip)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	master := orderprocessor.NewBybitOrderProcessorWithSigner(cfg.Bybit.APIKey, signer)
+	repoManager := repositories.NewRepositoryManager(db)
+	service := services.NewSubAccountService(master, repoManager, "bybit")
+	options := services.ProvisionOptions{Username: *username, IPs: allowedIPs}
+	result, err := service.Provision(ctx, strategy, options)
+	services.NewOperatorAuditService(repoManager).Record(ctx, cliAction("subaccount create", strategy, options, err))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	prefix := config.SubAccountPrefix(strategy)
+	if err := store.Set(prefix+"API_KEY", result.Key.APIKey); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	if err := store.Set(prefix+"SECRET_KEY", result.Key.Secret); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	if err := store.Save(*file, passphrase); err != nil {
+		// Ultima possibilità di recuperare il secret: la key esiste già sull'exchange
+		fmt.Fprintf(os.Stderr, "%v\nAPI key %s, secret %s: store them manually as %sAPI_KEY and %sSECRET_KEY\n",
+			err, result.Key.APIKey, result.Key.Secret, prefix, prefix)
+		return 1
+	}
+
+	if result.Created {
+		fmt.Println(i18n.T("cli.subaccount_created", result.Account.Username, result.Account.UID, strategy))
+	}
+	fmt.Println(i18n.T("cli.subaccount_key_saved", result.Key.APIKey, prefix, prefix, *file))
+	return 0
+}
+
+// runSubAccountList elenca i sub-account registrati per le strategie
+func runSubAccountList(args []string) int {
+	fs := flag.NewFlagSet("subaccount list", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	db, err := database.InitializeDatabaseWithData(database.DefaultConfig())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.open_db_failed", err))
+		return 1
+	}
+	defer database.Close(db)
+
+	accounts, err := services.NewSubAccountService(nil, repositories.NewRepositoryManager(db), "bybit").List(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	fmt.Printf("%-20s %-8s %-12s %-18s %-24s %s\n", "STRATEGY", "EXCHANGE", "UID", "USERNAME", "API KEY", "KEY CREATED")
+	for _, account := range accounts {
+		fmt.Printf("%-20s %-8s %-12d %-18s %-24s %s\n", account.Strategy, account.Exchange, account.UID, account.Username,
+			account.APIKey, account.KeyCreated.UTC().Format(time.RFC3339))
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/reporting"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/services"
+)
+
+// runTaxCommand gestisce l'export del report fiscale
+func runTaxCommand(args []string) int {
+	if len(args) == 0 || args[0] != "export" {
+		fmt.Fprintln(os.Stderr, "usage: mkybot tax export [-year YYYY] [-format generic|form8949] [-tz Europe/Rome] [-out FILE]")
+		return 2
+	}
+	return runTaxExport(args[1:])
+}
+
+// runTaxExport esporta le plusvalenze realizzate nell'anno, abbinando in FIFO le esecuzioni importate
+func runTaxExport(args []string) int {
+	fs := flag.NewFlagSet("tax export", flag.ContinueOnError)
+	year := fs.Int("year", time.Now().Year()-1, "tax year of the realized gains (default last year)")
+	format := fs.String("format", reporting.TaxFormatGeneric, "output format: generic or form8949")
+	tz := fs.String("tz", "UTC", "timezone of the tax jurisdiction (IANA name), used for the year boundaries and the dates")
+	out := fs.String("out", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format != reporting.TaxFormatGeneric && *format != reporting.TaxFormatForm8949 {
+		fmt.Fprintf(os.Stderr, "unsupported format %q (use generic or form8949)\n", *format)
+		return 2
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -tz: %v\n", err)
+		return 2
+	}
+
+	db, err := database.InitializeDatabaseWithData(database.DefaultConfig())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.open_db_failed", err))
+		return 1
+	}
+	defer database.Close(db)
+
+	taxService := services.NewTaxService(repositories.NewRepositoryManager(db))
+	lots, err := taxService.BuildTaxReport(context.Background(), *year, loc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := reporting.WriteTaxLotsCSV(w, lots, *format, loc); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write tax report: %v\n", err)
+		return 1
+	}
+
+	var gains, losses float64
+	for _, lot := range lots {
+		if lot.Gain >= 0 {
+			gains += lot.Gain
+		} else {
+			losses += lot.Gain
+		}
+	}
+	fmt.Fprintln(os.Stderr, i18n.T("cli.tax_exported", len(lots), *year, gains, losses, gains+losses))
+	return 0
+}
@@ -1,21 +1,637 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/features"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/keystore"
+	"cross-exchange-arbitrage/taprocess"
 
 	"github.com/joho/godotenv"
 )
 
 // Config contiene tutte le configurazioni dell'applicazione
 type Config struct {
-	Bybit    BybitConfig
-	LogLevel string
+	Bybit       BybitConfig
+	Kraken      KrakenConfig
+	Binance     BinanceConfig
+	Reporting   ReportingConfig
+	API         APIConfig
+	Maintenance MaintenanceConfig
+	Preflight   PreflightConfig
+	FundingArb  FundingArbConfig
+	BalanceSync BalanceSyncConfig
+	Rebalance   PortfolioRebalanceConfig
+	Watch       AccountWatchConfig
+	Orphans     OrphanSweepConfig
+	Prices      PriceAggregatorConfig
+	Arbitrage   ArbitrageConfig
+	Basis       BasisTrackerConfig
+	Calendar    CalendarConfig
+	DataSources DataSourcesConfig
+	Sentiment   SentimentFilterConfig
+	Trend       TrendFilterConfig
+	Retest      BreakoutRetestConfig
+	Profile     VolumeProfileConfig
+	OrderFlow   OrderFlowConfig
+	Liquidation LiquidationConfig
+	PnL         PositionPnLConfig
+	AVWAP       AnchoredVWAPConfig
+	Pyramid     PyramidConfig
+	Hedge       HedgeConfig
+	Regime      RegimeConfig
+	Scorer      ScorerConfig
+	Charts      TradeChartConfig
+	Indicators  IndicatorConfig
+	Paper       PaperTradingConfig
+	Observer    ObserverConfig
+	Jobs        JobQueueConfig
+	Workers     WorkerSchedulingConfig
+	Lock        LockConfig
+	Cache       CacheConfig
+	Events      EventsConfig
+	Errors      ErrorReportingConfig
+	Watchdog    WatchdogConfig
+	Latency     LatencySLOConfig
+	Quality     CandleQualityConfig
+	Retry       OrderRetryConfig
+	OrderCheck  OrderPreflightConfig
+	Throttle    OrderThrottleConfig
+	Slicing     OrderSlicingConfig
+	Execution   ExecutionConfig
+	Spread      SpreadGuardConfig
+	Features    FeatureFlagsConfig
+	Risk        RiskConfig
+	Degraded    DegradedModeConfig
+	Chaos       ChaosConfig
+	Simulation  SimulationConfig
+	LogLevel    string
+	Locale      i18n.Locale // Lingua di notifiche, report e output della CLI
 }
 
 // BybitConfig contiene le configurazioni per Bybit
+// Le credenziali incorporate sono quelle di trading (lettura e scrittura), usate solo per l'esecuzione
 type BybitConfig struct {
+	BybitCredentials
+	ReadOnly    BybitCredentials            // Key di sola lettura per analytics e reportistica
+	SubAccounts map[string]BybitCredentials // Key dei sub-account per strategia (BYBIT_SUB_<STRATEGIA>_*)
+}
+
+// SubAccountStrategies sono le strategie che possono operare in un sub-account Bybit dedicato
+// Le altre strategie leggono le posizioni del master account e restano sulla key di trading
+var SubAccountStrategies = []string{"funding-arbitrage"}
+
+// BybitCredentials contiene le credenziali di una API key Bybit
+type BybitCredentials struct {
+	APIKey            string
+	SecretKey         string
+	AuthType          string // hmac (default) o rsa
+	RSAPrivateKeyPath string // File PEM con la chiave privata, usato solo con AuthType rsa
+}
+
+// HasCredentials verifica se sono configurate le credenziali necessarie al tipo di autenticazione
+func (c BybitCredentials) HasCredentials() bool {
+	if c.APIKey == "" {
+		return false
+	}
+	if c.AuthType == "rsa" {
+		return c.RSAPrivateKeyPath != ""
+	}
+	return c.SecretKey != ""
+}
+
+// KrakenConfig contiene le credenziali per Kraken Futures
+type KrakenConfig struct {
+	APIKey    string
+	SecretKey string // Secret in base64 come fornito da Kraken
+	Demo      bool   // Usa l'ambiente demo-futures.kraken.com
+}
+
+// HasCredentials verifica se sono configurate le credenziali Kraken
+func (c KrakenConfig) HasCredentials() bool {
+	return c.APIKey != "" && c.SecretKey != ""
+}
+
+// BinanceConfig contiene le credenziali per Binance USDT-M futures
+type BinanceConfig struct {
 	APIKey    string
 	SecretKey string
+	Testnet   bool // Usa testnet.binancefuture.com
+}
+
+// HasCredentials verifica se sono configurate le credenziali Binance
+func (c BinanceConfig) HasCredentials() bool {
+	return c.APIKey != "" && c.SecretKey != ""
+}
+
+// ReportingConfig contiene le configurazioni per la reportistica
+type ReportingConfig struct {
+	RiskFreeRate float64       // Tasso privo di rischio annuo per Sharpe/Sortino (es. 0.04)
+	SessionOpen  time.Duration // Apertura della sessione dopo le 00:00 UTC, per i livelli chiave (es. 13h30)
+}
+
+// APIConfig contiene le configurazioni per le REST API
+type APIConfig struct {
+	Enabled bool   // Se il server REST è abilitato
+	Addr    string // Indirizzo di ascolto (es. ":8080")
+
+	QueryToken string     // Bearer token della console delle query (vuoto = console disabilitata)
+	Tokens     []APIToken // Token di accesso con ruolo (vuoto = API senza autenticazione)
+
+	PublicStatus     bool // Abilita GET /public/status, accessibile senza token
+	PublicStatusRate int  // Richieste al minuto consentite per indirizzo IP sulla pagina di stato pubblica
+}
+
+// Ruoli dei token delle REST API
+const (
+	APIRoleViewer   = "viewer"   // Solo letture (GET)
+	APIRoleOperator = "operator" // Letture e comandi (pausa dei worker, feature flag, liste dei simboli, tag)
+)
+
+// APIToken è un token di accesso alle REST API, nel formato nome:ruolo:token di API_TOKENS
+type APIToken struct {
+	Name  string // Nome dell'utente o del client, registrato come autore delle modifiche
+	Role  string // viewer o operator
+	Token string
+}
+
+// MaintenanceConfig contiene le configurazioni per la manutenzione del database
+type MaintenanceConfig struct {
+	AuditRetentionDays int  // Giorni di conservazione dell'audit trail e delle decisioni sui segnali (0 = nessuna pulizia)
+	OrderArchiveDays   int  // Giorni dopo la chiusura oltre i quali un ordine viene archiviato (0 = nessuna archiviazione)
+	Vacuum             bool // Esegue VACUUM/ANALYZE dopo la pulizia
+}
+
+// PreflightConfig contiene le configurazioni dei controlli eseguiti all'avvio
+type PreflightConfig struct {
+	Enabled           bool     // Verifica permessi e scadenza della API key prima di avviare i worker
+	KeyExpiryWarnDays int      // Giorni di preavviso prima della scadenza della API key
+	ExpectedIPs       []string // IP o reti CIDR da cui il bot deve uscire (vuoto = nessun controllo)
+	IPCheckURL        string   // Servizio usato per rilevare l'IP pubblico
+}
+
+// FundingArbConfig contiene le configurazioni della strategia di funding arbitrage
+// (long spot su SpotVenue, short perpetual su PerpVenue)
+type FundingArbConfig struct {
+	Enabled       bool
+	Schedule      string  // Cron schedule del worker
+	Symbol        string  // Simbolo nel formato del bot (es. DOGEUSDT)
+	SpotVenue     string  // Venue della gamba spot (bybit)
+	PerpVenue     string  // Venue della gamba perpetual (bybit, kraken)
+	Quantity      float64 // Quantità in base coin di ciascuna gamba
+	EntryAPR      float64 // Funding annualizzato minimo per aprire
+	ExitAPR       float64 // Funding annualizzato sotto cui chiudere
+	MinEntryBasis float64 // Basis (perp - spot) / spot minimo all'ingresso
+	MaxBasis      float64 // Basis assoluto oltre cui chiudere (0 = disabilitato)
+}
+
+// BalanceSyncConfig contiene le configurazioni del controllo dei margini tra venue
+type BalanceSyncConfig struct {
+	Enabled     bool
+	Schedule    string             // Cron schedule del worker
+	Coin        string             // Valuta di margine controllata (es. USDT)
+	Thresholds  map[string]float64 // Margine disponibile minimo per venue
+	AutoTopUp   bool               // Rifornisce dal funding account le venue che lo supportano (Bybit)
+	TopUpBuffer float64            // Frazione oltre la soglia a cui riportare il margine
+}
+
+// PortfolioRebalanceConfig contiene le configurazioni del ribilanciamento del capitale tra master e sub-account
+type PortfolioRebalanceConfig struct {
+	Enabled     bool
+	Schedule    string             // Cron schedule del worker
+	Coin        string             // Valuta ribilanciata (es. USDT)
+	Weights     map[string]float64 // Peso obiettivo per allocazione: "master" o il nome della strategia con sub-account
+	Drift       float64            // Scostamento dal peso obiettivo oltre cui ribilanciare (0.05 = 5 punti percentuali)
+	MinTransfer float64            // Importo minimo di un trasferimento
+}
+
+// AccountWatchConfig contiene le configurazioni del controllo di ordini e posizioni non creati dal bot
+type AccountWatchConfig struct {
+	Enabled       bool
+	Schedule      string        // Cron schedule del worker
+	Grace         time.Duration // Età minima di ordini e posizioni sconosciuti prima dell'alert
+	CancelUnknown bool          // Cancella gli ordini aperti sconosciuti
+	IgnoreSymbols []string      // Simboli gestiti fuori dal bot
+}
+
+// OrphanSweepConfig contiene le configurazioni della pulizia all'avvio degli ordini condizionali sconosciuti
+type OrphanSweepConfig struct {
+	Enabled bool
+	Action  string        // cancel: cancella gli ordini orfani; adopt: li registra nel database
+	Grace   time.Duration // Età minima di un ordine sconosciuto (un'altra istanza può averlo appena inviato)
+	Symbols []string      // Simboli gestiti dal bot di cui controllare gli ordini
+}
+
+// PriceAggregatorConfig contiene le configurazioni dell'aggregatore dei prezzi tra venue
+type PriceAggregatorConfig struct {
+	Enabled  bool
+	Symbols  []string      // Simboli consolidati (es. DOGEUSDT)
+	Interval time.Duration // Frequenza di aggiornamento
+	MaxAge   time.Duration // Età massima di una quotazione per entrare nel consolidato
+}
+
+// ArbitrageConfig contiene le configurazioni del rilevamento degli arbitraggi tra venue
+type ArbitrageConfig struct {
+	Enabled           bool
+	Quantity          float64                  // Quantità massima per opportunità, in base coin
+	MinNetBps         float64                  // Profitto netto minimo in basis point
+	TakerFees         map[string]float64       // Fee taker per venue
+	DefaultTakerFee   float64                  // Fee per le venue non configurate
+	OrderLatency      map[string]time.Duration // Latenza di invio ordine per venue
+	DriftBpsPerSecond float64                  // Movimento avverso atteso per secondo di latenza
+	BookDepth         int                      // Livelli dell'order book per stimare lo slippage
+	MaxBookAge        time.Duration            // Età massima dell'order book per lo slippage
+}
+
+// BasisTrackerConfig contiene le configurazioni del monitoraggio del basis spot/perpetual su Bybit
+type BasisTrackerConfig struct {
+	Enabled        bool
+	Schedule       string   // Cron schedule del worker
+	Symbols        []string // Simboli monitorati (es. DOGEUSDT)
+	UpperThreshold float64  // Alert se il basis (perp - spot) / spot supera questa soglia
+	LowerThreshold float64  // Alert se il basis scende sotto questa soglia
+}
+
+// CalendarConfig contiene le configurazioni del blackout attorno agli eventi macro (FOMC, CPI)
+type CalendarConfig struct {
+	Enabled        bool
+	Provider       string        // file (calendario statico) o http (feed JSON)
+	File           string        // File JSON del calendario statico
+	URL            string        // URL del feed JSON nel formato Forex Factory
+	Before         time.Duration // Inizio del blackout prima dell'evento
+	After          time.Duration // Fine del blackout dopo l'evento
+	Keywords       []string      // Eventi rilevanti per nome
+	Countries      []string      // Paesi/valute rilevanti
+	MinImpact      string        // Impatto minimo degli eventi (Low, Medium, High)
+	Refresh        time.Duration // Intervallo di rilettura del calendario
+	TightenStops   bool          // Avvicina lo stop loss delle posizioni aperte durante il blackout
+	TightenStopPct float64       // Distanza dello stop dal mark price durante il blackout (0.005 = 0.5%)
+}
+
+// DataSourcesConfig contiene le configurazioni dell'importazione delle sorgenti dati esterne
+type DataSourcesConfig struct {
+	Enabled      bool
+	Schedule     string   // Cron schedule del worker
+	Sources      []string // Sorgenti importate (es. fear_greed)
+	BackfillDays int      // Giorni di storico importati al primo avvio
+}
+
+// SentimentFilterConfig contiene le soglie del filtro di sentiment sugli ingressi del worker DOGE
+type SentimentFilterConfig struct {
+	Enabled  bool
+	MaxLong  float64       // Nessun long con Fear & Greed pari o superiore
+	MinShort float64       // Nessuno short con Fear & Greed pari o inferiore
+	MaxAge   time.Duration // Oltre questa età il valore viene ignorato e il filtro non blocca
+}
+
+// TrendFilterConfig contiene i parametri del filtro di trend sull'EMA applicato agli ingressi del worker DOGE
+type TrendFilterConfig struct {
+	Enabled      bool
+	Period       int // Periodo dell'EMA: long solo sopra, short solo sotto
+	SlopeCandles int // Candele su cui l'EMA deve salire (long) o scendere (short); 0 disattiva
+}
+
+// BreakoutRetestConfig contiene i parametri dell'ingresso su retest del worker DOGE: dopo la rottura di muro
+// o supporto l'ordine parte solo quando il prezzo torna sul livello rotto e conferma la direzione
+type BreakoutRetestConfig struct {
+	Enabled   bool
+	Candles   int     // Candele 1m dopo la rottura entro cui il retest deve essere confermato
+	Tolerance float64 // Distanza dal livello che conta come ritorno (0.001 = 0.1%)
+}
+
+// TradeChartConfig contiene le configurazioni dei grafici PNG disegnati all'apertura dei trade DOGE
+type TradeChartConfig struct {
+	Enabled bool
+	Dir     string // Cartella in cui salvare i grafici, uno per ordine di ingresso
+	Candles int    // Candele chiuse da 1 minuto disegnate prima dell'ingresso
+}
+
+// IndicatorConfig contiene le configurazioni della pipeline degli indicatori tecnici (taprocess)
+type IndicatorConfig struct {
+	Sources taprocess.IndicatorSources // Prezzo della candela (close, hl2, hlc3, ohlc4) da cui viene calcolato ogni indicatore
+}
+
+// VolumeProfileConfig contiene i parametri del volume profile usato come supporto/resistenza negli ingressi DOGE
+type VolumeProfileConfig struct {
+	Enabled   bool
+	Bins      int     // Fasce di prezzo di ogni sessione
+	ValueArea float64 // Quota del volume compresa nella value area (0.7 = 70%)
+	Sessions  int     // Sessioni giornaliere (UTC) chiuse da cui prendere POC, VAH e VAL
+	MinRoom   float64 // Distanza minima dal prossimo livello nella direzione dell'ingresso (0.005 = 0.5%)
+}
+
+// OrderFlowConfig contiene i parametri del delta dei trade pubblici usato come conferma dei breakout DOGE
+type OrderFlowConfig struct {
+	Enabled      bool
+	Symbols      []string      // Simboli di cui seguire lo stream dei trade pubblici di Bybit
+	Window       time.Duration // Finestra mobile su cui calcolare il delta
+	MinImbalance float64       // Delta minimo nella direzione della rottura, in rapporto al volume (0.1 = 10%)
+	MinTrades    int           // Trade minimi nella finestra; con meno trade il filtro non blocca l'ingresso
+}
+
+// LiquidationConfig contiene i parametri del feed delle liquidazioni di Bybit e del suo uso nel worker DOGE
+type LiquidationConfig struct {
+	Enabled         bool
+	Symbols         []string      // Simboli di cui seguire lo stream delle liquidazioni
+	Retention       time.Duration // Per quanto tempo conservare le liquidazioni ricevute
+	Gap             time.Duration // Distanza massima tra due liquidazioni dello stesso cluster
+	MinNotional     float64       // Controvalore minimo (USDT) di un cluster perché il worker DOGE lo consideri
+	Lookback        time.Duration // Un cluster è considerato se è terminato entro questo intervallo
+	ContrarianEntry bool          // Ingresso contrarian dopo una cascata di liquidazioni (long dopo i long liquidati)
+	TightenStops    bool          // Stringe lo stop della posizione aperta se vengono liquidate posizioni dello stesso lato
+	TightenStopPct  float64       // Distanza dello stop stretto dal mark price (0.005 = 0.5%)
+}
+
+// PositionPnLConfig contiene i parametri del ricalcolo in tempo reale del PnL non realizzato delle posizioni
+type PositionPnLConfig struct {
+	Enabled          bool
+	Symbols          []string      // Simboli di cui seguire il mark price e le posizioni aperte
+	RefreshInterval  time.Duration // Ogni quanto rileggere le posizioni aperte dall'exchange
+	SnapshotInterval time.Duration // Ogni quanto salvare uno snapshot del PnL di ogni posizione aperta
+	BreakEvenPct     float64       // PnL minimo (sul valore di ingresso) oltre cui lo stop DOGE va sull'ingresso; 0 se disabilitato
+	RetentionDays    int           // Giorni di conservazione degli snapshot del PnL (0 = nessuna pulizia)
+}
+
+// AnchoredVWAPConfig contiene i parametri dello stop della posizione DOGE sul VWAP ancorato alla rottura
+type AnchoredVWAPConfig struct {
+	Enabled    bool
+	Buffer     float64 // Distanza dello stop oltre il VWAP (0.002 = 0.2%)
+	MinCandles int     // Candele 1m dall'ancora prima di seguire il VWAP
+}
+
+// PyramidConfig contiene i parametri degli incrementi delle posizioni in profitto del worker DOGE
+type PyramidConfig struct {
+	Enabled   bool
+	MaxAdds   int     // Numero massimo di incrementi oltre l'ingresso iniziale
+	Step      float64 // Movimento favorevole dall'ultimo ingresso che fa scattare l'incremento (0.01 = 1%)
+	SizeDecay float64 // Ogni incremento è questa frazione del precedente (0.5 = metà)
+}
+
+// HedgeConfig contiene i parametri della copertura del portafoglio con uno short sul perpetual indice
+type HedgeConfig struct {
+	Enabled      bool
+	Schedule     string        // Cron schedule del worker
+	Venue        string        // Venue su cui aprire lo short di copertura (bybit)
+	Symbol       string        // Perpetual di copertura (es. BTCUSDT)
+	Ratio        float64       // Nozionale dello short rispetto ai long aperti sulle altcoin (0.5 = 50%)
+	Tolerance    float64       // Scostamento dalla copertura obiettivo entro cui non si ribilancia (0.2 = 20%)
+	QtyStep      float64       // Passo della quantità del perpetual di copertura (0.001 per BTCUSDT su Bybit)
+	Signals      []string      // Segnali di risk-off attivi (fear_greed, btc_trend): ne basta uno
+	FearGreedMax float64       // Fear & Greed Index pari o sotto cui si è in risk-off
+	FearGreedAge time.Duration // Età massima della rilevazione del Fear & Greed Index
+	TrendPeriod  int           // Periodo dell'EMA oraria del segnale btc_trend
+}
+
+// RegimeConfig contiene i parametri del rilevamento del regime di mercato (trend o laterale) per simbolo
+// e gli ingressi del worker DOGE attivi in ciascun regime
+type RegimeConfig struct {
+	Enabled     bool
+	Schedule    string   // Cron schedule del worker
+	Symbols     []string // Simboli di cui misurare il regime
+	Timeframe   string   // Timeframe delle candele su cui misurare il regime (es. 15)
+	Method      string   // adx o variance_ratio
+	ADXPeriod   int      // Periodo dell'ADX
+	ADXTrending float64  // ADX oltre cui il mercato è in trend
+	ADXRanging  float64  // ADX sotto cui il mercato è laterale
+	VRLag       int      // Candele dei rendimenti aggregati del rapporto di varianza
+	VRWindow    int      // Rendimenti su cui calcolare il rapporto di varianza
+	VRTrending  float64  // Rapporto di varianza oltre cui il mercato è in trend
+	VRRanging   float64  // Rapporto di varianza sotto cui il mercato è laterale
+	Trending    []string // Ingressi attivi in trend (breakout, liquidation)
+	Ranging     []string // Ingressi attivi in laterale (breakout, liquidation)
+}
+
+// ScorerConfig contiene le configurazioni del modello esterno che valuta i segnali del worker DOGE
+type ScorerConfig struct {
+	Enabled     bool
+	Type        string        // http o onnx
+	URL         string        // Endpoint dello scorer HTTP
+	Token       string        // Bearer token opzionale dello scorer HTTP
+	Timeout     time.Duration // Timeout delle richieste allo scorer HTTP
+	ONNXModel   string        // File del modello ONNX
+	ONNXLibrary string        // Libreria condivisa di ONNX Runtime
+	ONNXInput   string        // Nome del tensore di input
+	ONNXOutput  string        // Nome del tensore di output
+	Features    []string      // Ordine delle feature atteso dal modello ONNX
+	MinScore    float64       // Punteggio minimo per eseguire il trade
+	MinSize     float64       // Frazione della quantità al punteggio minimo
+	MaxSize     float64       // Frazione della quantità al punteggio massimo
+	FailOpen    bool          // Se lo scorer non risponde il trade procede a quantità piena
+}
+
+// PaperTradingConfig contiene le configurazioni del paper trading, che simula gli ordini sulle candele reali
+type PaperTradingConfig struct {
+	Enabled           bool
+	InitialBalance    float64 // Saldo USDT simulato iniziale
+	MaxVolumeFraction float64 // Frazione massima del volume di ogni candela da 1 minuto eseguibile (0 = nessun limite)
+	FillPriority      string  // pessimistic, optimistic: priorità tra SL e TP nella stessa candela
+
+	LatencyDistribution string        // fixed, uniform, normal: distribuzione della latenza simulata di ogni ordine
+	Latency             time.Duration // Latenza media simulata tra invio ed esecuzione (0 = nessuna)
+	LatencyJitter       time.Duration // Variazione della latenza: semi-ampiezza per uniform, deviazione standard per normal
+	SlippageBps         float64       // Peggioramento del prezzo di ogni esecuzione in punti base
+}
+
+// ObserverConfig contiene la modalità observer, in cui il bot raccoglie dati ed esegue strategie e reportistica
+// senza inviare ordini: ogni ordine viene solo registrato nel log
+type ObserverConfig struct {
+	Enabled bool
+}
+
+// JobQueueConfig contiene le configurazioni della coda persistente dei job differiti
+type JobQueueConfig struct {
+	Enabled             bool
+	Schedule            string        // Cron schedule (con secondi) del worker che esegue i job scaduti
+	RetryDelay          time.Duration // Attesa base prima di ripetere un job fallito (moltiplicata per il tentativo)
+	RetentionDays       int           // Giorni di conservazione dei job conclusi (0 = nessuna pulizia)
+	CancelUnfilledAfter time.Duration // Cancella gli ordini DOGE non eseguiti dopo questo intervallo (0 = disabilitato)
+}
+
+// Modalità di esecuzione dei worker
+const (
+	WorkerModeCron        = "cron"         // Ogni worker segue la propria schedule cron
+	WorkerModeCandleClose = "candle_close" // I worker legati a un timeframe partono alla chiusura di ogni candela
+)
+
+// WorkerSchedulingConfig contiene le configurazioni per distribuire nel tempo le esecuzioni dei worker
+type WorkerSchedulingConfig struct {
+	Jitter           time.Duration // Ritardo casuale massimo prima di ogni esecuzione (0 = nessun jitter)
+	Stagger          time.Duration // Sfasamento tra worker con la stessa schedule (0 = partenza simultanea)
+	Mode             string        // cron o candle_close
+	CandleCloseDelay time.Duration // Ritardo dopo la chiusura della candela, perché l'exchange la renda disponibile
+}
+
+// LockConfig contiene le configurazioni del lock distribuito tra più istanze sullo stesso account
+type LockConfig struct {
+	Enabled bool
+	// Backend dei lease: database (tabella distributed_locks, solo istanze sullo stesso host)
+	// o redis (REDIS_ADDR, condiviso tra host diversi)
+	Backend string
+	Account string        // Account a cui si riferiscono i lock: istanze sullo stesso account devono usare lo stesso valore
+	Owner   string        // Identificativo dell'istanza (vuoto = hostname e pid)
+	TTL     time.Duration // Durata del lease, rinnovato ogni terzo di TTL
+	// Standby abilita l'elezione del leader: solo il leader esegue i worker di trading,
+	// le altre istanze restano in standby mantenendo lo stato aggiornato e subentrano se il leader sparisce
+	Standby bool
+}
+
+// CacheConfig contiene le configurazioni della cache di prezzi, saldi e posizioni
+type CacheConfig struct {
+	Backend       string // none, memory (locale al processo) o redis (condivisa tra istanze e dashboard)
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	Prefix        string // Prefisso delle chiavi Redis
+	PriceTTL      time.Duration
+	BalanceTTL    time.Duration
+	PositionsTTL  time.Duration
+}
+
+// EventsConfig contiene le configurazioni della pubblicazione degli eventi di trading
+type EventsConfig struct {
+	Backend      string // none, nats o kafka (tramite Kafka REST Proxy)
+	NATSURL      string
+	KafkaRESTURL string
+	TopicPrefix  string // Prefisso di topic e subject (es. mkybot → mkybot.order.placed)
+	Buffer       int    // Eventi in coda oltre i quali i nuovi vengono scartati
+}
+
+// ErrorReportingConfig contiene le configurazioni dell'invio di panic ed errori a Sentry
+type ErrorReportingConfig struct {
+	SentryDSN   string  // DSN del progetto Sentry; vuoto disabilita l'invio
+	Environment string  // Ambiente riportato sugli eventi (es. production, paper)
+	Release     string  // Versione del bot riportata sugli eventi
+	SampleRate  float64 // Frazione di errori inviati (1 = tutti)
+}
+
+// WatchdogConfig contiene le configurazioni del watchdog su worker e WebSocket
+type WatchdogConfig struct {
+	Enabled       bool
+	Interval      time.Duration // Intervallo tra i controlli
+	WorkerTimeout time.Duration // Durata oltre cui un ciclo di worker è considerato bloccato
+	StreamTimeout time.Duration // Silenzio del WebSocket dei prezzi oltre cui viene riavviato
+}
+
+// LatencySLOConfig contiene l'obiettivo di latenza tra il segnale e la conferma degli ordini
+type LatencySLOConfig struct {
+	Target   time.Duration // Latenza massima dalla chiusura della candela del segnale alla conferma; 0 disattiva gli alert
+	Breaches int           // Superamenti consecutivi dopo cui scatta l'alert
+}
+
+// CandleQualityConfig contiene le soglie di qualità delle candele recuperate dagli exchange
+type CandleQualityConfig struct {
+	Enabled       bool
+	Window        int     // Recuperi recenti su cui calcolare le metriche di ogni simbolo
+	MaxGaps       int     // Candele mancanti ammesse nella finestra
+	MaxZeroVolume float64 // Quota massima (0-1) di candele chiuse con volume zero
+	MaxErrorRate  float64 // Quota massima (0-1) di recuperi falliti
+}
+
+// OrderRetryConfig contiene i tentativi di piazzamento degli ordini di ingresso
+type OrderRetryConfig struct {
+	Attempts int           // Tentativi totali, compreso il primo
+	Backoff  time.Duration // Attesa prima del secondo tentativo, raddoppiata a ogni tentativo successivo
+}
+
+// OrderPreflightConfig contiene il controllo degli ordini di ingresso prima dell'invio
+type OrderPreflightConfig struct {
+	Enabled bool // Prevede i rifiuti per quantità fuori dai limiti o margine insufficiente senza inviare l'ordine
+}
+
+// OrderThrottleConfig contiene i limiti di ordini per simbolo e per account, a protezione dal ban dell'account
+type OrderThrottleConfig struct {
+	Enabled          bool
+	SymbolPerMinute  int           // Ordini al minuto per simbolo (0 = nessun limite)
+	SymbolPerHour    int           // Ordini all'ora per simbolo (0 = nessun limite)
+	AccountPerMinute int           // Ordini al minuto per account (0 = nessun limite)
+	AccountPerHour   int           // Ordini all'ora per account (0 = nessun limite)
+	Mode             string        // reject scarta gli ordini oltre il limite, queue li mette in attesa
+	MaxWait          time.Duration // Attesa massima di un ordine in coda
+}
+
+// OrderSlicingConfig contiene il frazionamento degli ingressi troppo grandi per la liquidità al miglior prezzo
+type OrderSlicingConfig struct {
+	Enabled         bool
+	Method          string        // twap (frazioni uguali) o iceberg (frazioni sulla liquidità visibile)
+	MaxBookFraction float64       // Frazione della liquidità al miglior prezzo oltre cui l'ingresso viene frazionato
+	Slices          int           // Numero di frazioni con twap, massimo con iceberg
+	Interval        time.Duration // Attesa tra due frazioni
+}
+
+// ExecutionConfig contiene lo strato di esecuzione tra strategie e OrderProcessor
+type ExecutionConfig struct {
+	Algos          map[string]string // Urgenza (high, normal, low) -> algoritmo (immediate, twap, passive_then_cross, peg)
+	DogeUrgency    string            // Urgenza degli ingressi DOGE
+	TWAPSlices     int               // Ordini figli dell'algoritmo twap
+	TWAPDuration   time.Duration     // Finestra su cui twap distribuisce gli ordini
+	PassiveTimeout time.Duration     // Attesa dell'ordine limite di passive_then_cross prima di andare a mercato
+	PegDuration    time.Duration     // Tempo in cui l'ordine di peg segue il miglior prezzo prima di andare a mercato
+	PegInterval    time.Duration     // Intervallo di controllo del book per spostare l'ordine di peg
+}
+
+// SpreadGuardConfig contiene il controllo dello spread prima degli ordini a mercato di apertura
+type SpreadGuardConfig struct {
+	Enabled bool
+	MaxBps  float64       // Spread massimo tra denaro e lettera, in punti base sul prezzo medio
+	Mode    string        // block (ordine scartato) o defer (attesa che lo spread rientri)
+	MaxWait time.Duration // Attesa massima in modalità defer
+}
+
+// FeatureFlagsConfig contiene i flag delle funzionalità rischiose, attivabili gradualmente per ambiente o account
+type FeatureFlagsConfig struct {
+	Environment string            // Ambiente dell'istanza confrontato con le regole (es. production, paper)
+	Account     string            // Account dell'istanza confrontato con le regole
+	Rules       map[string]string // Flag -> on, off o ambienti e account separati da | in cui è attivo
+}
+
+// RiskConfig contiene i controlli applicati prima di ogni ordine
+type RiskConfig struct {
+	MaxDataAge        time.Duration      // Età massima di prezzi e candele usati per operare; 0 disattiva il controllo
+	PriceReference    string             // Seconda fonte di prezzo: none, index (index price della venue perpetual) o una venue (bybit, kraken)
+	MaxPriceDeviation float64            // Scostamento massimo dal riferimento (0.02 = 2%) oltre cui l'ordine è bloccato
+	SymbolAllowList   []string           // Se non vuota, solo questi simboli sono negoziabili
+	SymbolDenyList    []string           // Simboli mai negoziabili, qualunque sia il segnale
+	MaxOpenPositions  int                // Numero massimo di simboli con una posizione aperta; 0 senza limite
+	WorkerBudgets     map[string]float64 // Quota del saldo assegnata a ogni worker (0.5 = 50%); i worker assenti usano l'intero saldo
+	SizingMode        string             // compound (size dal capitale corrente) o fixed (size da un capitale base)
+	BaseCapital       float64            // Capitale base in fixed; 0 lo ancora al capitale disponibile al primo ordine
+	BaseCapitalReset  string             // Riancoraggio del capitale base ancorato: never, daily, weekly, monthly
+	BaseCapitalChange float64            // Riancora quando il capitale si discosta dal base oltre questa frazione; 0 disattiva
+	StopModes         map[string]string  // Posizionamento dello stop per worker: fixed, swing o chandelier; i worker assenti usano fixed
+	StopSwingLookback int                // Candele chiuse in cui cercare l'ultimo swing
+	StopATRPeriod     int                // Periodo dell'ATR e della finestra del massimo/minimo del chandelier
+	StopATRMultiplier float64            // Multiplo dell'ATR del chandelier
+}
+
+// DegradedModeConfig definisce cosa fa il bot quando un exchange non è raggiungibile
+type DegradedModeConfig struct {
+	Action     string        // freeze (nessun nuovo ingresso e alert) o alert (solo alert)
+	Failures   int           // Errori consecutivi (rete, timeout, 5xx) dopo cui la venue è degradata
+	Recovery   int           // Richieste riuscite consecutive dopo cui la venue torna operativa
+	RecvWindow time.Duration // recvWindow delle richieste firmate in modalità degradata; 0 lascia quello normale
+}
+
+// ChaosConfig contiene i guasti simulati sulle chiamate HTTP agli exchange; mai da abilitare in produzione
+type ChaosConfig struct {
+	Enabled     bool
+	TimeoutRate float64       // Quota delle richieste che vanno in timeout
+	ErrorRate   float64       // Quota delle richieste a cui viene risposto 503
+	Latency     time.Duration // Ritardo aggiunto alle richieste colpite
+	Venues      []string      // Venue colpite; vuoto per tutte
+	Paths       []string      // Prefissi dei path colpiti (es. /v5/order); vuoto per tutti
+}
+
+// SimulationConfig contiene il seme delle estrazioni casuali dei componenti di simulazione
+// (latenza del paper trading, guasti simulati), così le run sono ripetibili
+type SimulationConfig struct {
+	Seed uint64 // Seme delle estrazioni casuali (0 = scelto a caso e registrato nel log all'avvio)
 }
 
 // Load carica le configurazioni dalle variabili d'ambiente
@@ -23,17 +639,1003 @@ func Load() (*Config, error) {
 	// Carica il file .env se esiste
 	_ = godotenv.Load()
 
+	// Carica le credenziali cifrate se il file esiste (le variabili già valorizzate hanno la precedenza)
+	if err := loadEncryptedCredentials(CredentialsFile()); err != nil {
+		return nil, err
+	}
+
 	config := &Config{
 		Bybit: BybitConfig{
-			APIKey:    os.Getenv("BYBIT_API_KEY"),
-			SecretKey: os.Getenv("BYBIT_SECRET_KEY"),
+			BybitCredentials: loadBybitCredentials("BYBIT_"),
+			ReadOnly:         loadBybitCredentials("BYBIT_READONLY_"),
+			SubAccounts:      loadSubAccountCredentials(),
+		},
+		Kraken: KrakenConfig{
+			APIKey:    os.Getenv("KRAKEN_API_KEY"),
+			SecretKey: os.Getenv("KRAKEN_SECRET_KEY"),
+			Demo:      getEnvBoolOrDefault("KRAKEN_DEMO", false),
+		},
+		Binance: BinanceConfig{
+			APIKey:    os.Getenv("BINANCE_API_KEY"),
+			SecretKey: os.Getenv("BINANCE_SECRET_KEY"),
+			Testnet:   getEnvBoolOrDefault("BINANCE_TESTNET", false),
+		},
+		Reporting: ReportingConfig{
+			RiskFreeRate: getEnvFloatOrDefault("REPORT_RISK_FREE_RATE", 0),
+		},
+		API: APIConfig{
+			Enabled: getEnvBoolOrDefault("API_ENABLED", true),
+			Addr:    getEnvOrDefault("API_ADDR", ":8080"),
+
+			QueryToken: os.Getenv("API_QUERY_TOKEN"),
+
+			PublicStatus:     getEnvBoolOrDefault("API_PUBLIC_STATUS", false),
+			PublicStatusRate: getEnvIntOrDefault("API_PUBLIC_STATUS_RATE_PER_MINUTE", 30),
+		},
+		Maintenance: MaintenanceConfig{
+			AuditRetentionDays: getEnvIntOrDefault("AUDIT_RETENTION_DAYS", 180),
+			OrderArchiveDays:   getEnvIntOrDefault("ORDER_ARCHIVE_DAYS", 90),
+			Vacuum:             getEnvBoolOrDefault("MAINTENANCE_VACUUM", true),
+		},
+		Preflight: PreflightConfig{
+			Enabled:           getEnvBoolOrDefault("PREFLIGHT_ENABLED", true),
+			KeyExpiryWarnDays: getEnvIntOrDefault("API_KEY_EXPIRY_WARN_DAYS", 14),
+			ExpectedIPs:       getEnvList("PREFLIGHT_EXPECTED_IPS"),
+			IPCheckURL:        getEnvOrDefault("PREFLIGHT_IP_CHECK_URL", "https://api.ipify.org"),
+		},
+		FundingArb: FundingArbConfig{
+			Enabled:       getEnvBoolOrDefault("FUNDING_ARB_ENABLED", false),
+			Schedule:      getEnvOrDefault("FUNDING_ARB_SCHEDULE", "0 */5 * * * *"),
+			Symbol:        getEnvOrDefault("FUNDING_ARB_SYMBOL", "DOGEUSDT"),
+			SpotVenue:     strings.ToLower(getEnvOrDefault("FUNDING_ARB_SPOT_VENUE", "bybit")),
+			PerpVenue:     strings.ToLower(getEnvOrDefault("FUNDING_ARB_PERP_VENUE", "kraken")),
+			Quantity:      getEnvFloatOrDefault("FUNDING_ARB_QUANTITY", 0),
+			EntryAPR:      getEnvFloatOrDefault("FUNDING_ARB_ENTRY_APR", 0.15),
+			ExitAPR:       getEnvFloatOrDefault("FUNDING_ARB_EXIT_APR", 0.03),
+			MinEntryBasis: getEnvFloatOrDefault("FUNDING_ARB_MIN_ENTRY_BASIS", -0.002),
+			MaxBasis:      getEnvFloatOrDefault("FUNDING_ARB_MAX_BASIS", 0.02),
+		},
+		BalanceSync: BalanceSyncConfig{
+			Enabled:     getEnvBoolOrDefault("BALANCE_SYNC_ENABLED", false),
+			Schedule:    getEnvOrDefault("BALANCE_SYNC_SCHEDULE", "0 */15 * * * *"),
+			Coin:        strings.ToUpper(getEnvOrDefault("BALANCE_SYNC_COIN", "USDT")),
+			AutoTopUp:   getEnvBoolOrDefault("BALANCE_SYNC_AUTO_TOPUP", false),
+			TopUpBuffer: getEnvFloatOrDefault("BALANCE_SYNC_TOPUP_BUFFER", 0.2),
+		},
+		Rebalance: PortfolioRebalanceConfig{
+			Enabled:     getEnvBoolOrDefault("PORTFOLIO_REBALANCE_ENABLED", false),
+			Schedule:    getEnvOrDefault("PORTFOLIO_REBALANCE_SCHEDULE", "0 0 * * * *"),
+			Coin:        strings.ToUpper(getEnvOrDefault("PORTFOLIO_REBALANCE_COIN", "USDT")),
+			Drift:       getEnvFloatOrDefault("PORTFOLIO_REBALANCE_DRIFT", 0.05),
+			MinTransfer: getEnvFloatOrDefault("PORTFOLIO_REBALANCE_MIN_TRANSFER", 10),
+		},
+		Watch: AccountWatchConfig{
+			Enabled:       getEnvBoolOrDefault("ACCOUNT_WATCH_ENABLED", true),
+			Schedule:      getEnvOrDefault("ACCOUNT_WATCH_SCHEDULE", "*/30 * * * * *"),
+			Grace:         time.Duration(getEnvIntOrDefault("ACCOUNT_WATCH_GRACE_SECONDS", 120)) * time.Second,
+			CancelUnknown: getEnvBoolOrDefault("ACCOUNT_WATCH_CANCEL_UNKNOWN", false),
+			IgnoreSymbols: getEnvList("ACCOUNT_WATCH_IGNORE_SYMBOLS"),
+		},
+		Orphans: OrphanSweepConfig{
+			Enabled: getEnvBoolOrDefault("ORPHAN_SWEEP_ENABLED", true),
+			Action:  strings.ToLower(getEnvOrDefault("ORPHAN_SWEEP_ACTION", "cancel")),
+			Grace:   time.Duration(getEnvIntOrDefault("ORPHAN_SWEEP_GRACE_SECONDS", 120)) * time.Second,
+			Symbols: getEnvList("ORPHAN_SWEEP_SYMBOLS"),
+		},
+		Prices: PriceAggregatorConfig{
+			Enabled:  getEnvBoolOrDefault("PRICE_AGGREGATOR_ENABLED", true),
+			Symbols:  getEnvList("PRICE_AGGREGATOR_SYMBOLS"),
+			Interval: time.Duration(getEnvIntOrDefault("PRICE_AGGREGATOR_INTERVAL_SECONDS", 5)) * time.Second,
+			MaxAge:   time.Duration(getEnvIntOrDefault("PRICE_AGGREGATOR_MAX_AGE_SECONDS", 30)) * time.Second,
+		},
+		Arbitrage: ArbitrageConfig{
+			Enabled:           getEnvBoolOrDefault("ARB_DETECTOR_ENABLED", false),
+			Quantity:          getEnvFloatOrDefault("ARB_QUANTITY", 0),
+			MinNetBps:         getEnvFloatOrDefault("ARB_MIN_NET_BPS", 5),
+			DefaultTakerFee:   getEnvFloatOrDefault("ARB_DEFAULT_TAKER_FEE", 0.001),
+			DriftBpsPerSecond: getEnvFloatOrDefault("ARB_LATENCY_DRIFT_BPS", 1),
+			BookDepth:         getEnvIntOrDefault("ARB_BOOK_DEPTH", 50),
+			MaxBookAge:        time.Duration(getEnvIntOrDefault("ARB_MAX_BOOK_AGE_SECONDS", 5)) * time.Second,
+		},
+		Basis: BasisTrackerConfig{
+			Enabled:        getEnvBoolOrDefault("BASIS_TRACKER_ENABLED", false),
+			Schedule:       getEnvOrDefault("BASIS_TRACKER_SCHEDULE", "0 * * * * *"),
+			Symbols:        getEnvList("BASIS_TRACKER_SYMBOLS"),
+			UpperThreshold: getEnvFloatOrDefault("BASIS_ALERT_UPPER", 0.005),
+			LowerThreshold: getEnvFloatOrDefault("BASIS_ALERT_LOWER", -0.005),
+		},
+		Calendar: CalendarConfig{
+			Enabled:        getEnvBoolOrDefault("CALENDAR_ENABLED", false),
+			Provider:       strings.ToLower(getEnvOrDefault("CALENDAR_PROVIDER", "file")),
+			File:           getEnvOrDefault("CALENDAR_FILE", "calendar.json"),
+			URL:            getEnvOrDefault("CALENDAR_URL", "https://nfs.faireconomy.media/ff_calendar_thisweek.json"),
+			Before:         time.Duration(getEnvIntOrDefault("CALENDAR_BLACKOUT_BEFORE_MINUTES", 30)) * time.Minute,
+			After:          time.Duration(getEnvIntOrDefault("CALENDAR_BLACKOUT_AFTER_MINUTES", 30)) * time.Minute,
+			Keywords:       getEnvList("CALENDAR_EVENTS"),
+			Countries:      getEnvList("CALENDAR_COUNTRIES"),
+			MinImpact:      getEnvOrDefault("CALENDAR_MIN_IMPACT", "High"),
+			Refresh:        time.Duration(getEnvIntOrDefault("CALENDAR_REFRESH_MINUTES", 60)) * time.Minute,
+			TightenStops:   getEnvBoolOrDefault("CALENDAR_TIGHTEN_STOPS", false),
+			TightenStopPct: getEnvFloatOrDefault("CALENDAR_TIGHTEN_STOP_PCT", 0.005),
+		},
+		DataSources: DataSourcesConfig{
+			Enabled:      getEnvBoolOrDefault("DATA_SOURCES_ENABLED", false),
+			Schedule:     getEnvOrDefault("DATA_SOURCES_SCHEDULE", "0 15 0 * * *"),
+			Sources:      getEnvList("DATA_SOURCES"),
+			BackfillDays: getEnvIntOrDefault("DATA_SOURCES_BACKFILL_DAYS", 365),
+		},
+		Sentiment: SentimentFilterConfig{
+			Enabled:  getEnvBoolOrDefault("SENTIMENT_FILTER_ENABLED", false),
+			MaxLong:  getEnvFloatOrDefault("SENTIMENT_MAX_LONG", 80),
+			MinShort: getEnvFloatOrDefault("SENTIMENT_MIN_SHORT", 20),
+			MaxAge:   time.Duration(getEnvIntOrDefault("SENTIMENT_MAX_AGE_HOURS", 48)) * time.Hour,
+		},
+		Trend: TrendFilterConfig{
+			Enabled:      getEnvBoolOrDefault("TREND_FILTER_ENABLED", false),
+			Period:       getEnvIntOrDefault("TREND_FILTER_EMA_PERIOD", 223),
+			SlopeCandles: getEnvIntOrDefault("TREND_FILTER_SLOPE_CANDLES", 0),
+		},
+		Retest: BreakoutRetestConfig{
+			Enabled:   getEnvBoolOrDefault("BREAKOUT_RETEST_ENABLED", false),
+			Candles:   getEnvIntOrDefault("BREAKOUT_RETEST_CANDLES", 60),
+			Tolerance: getEnvFloatOrDefault("BREAKOUT_RETEST_TOLERANCE_PCT", 0.001),
+		},
+		Charts: TradeChartConfig{
+			Enabled: getEnvBoolOrDefault("TRADE_CHART_ENABLED", true),
+			Dir:     getEnvOrDefault("TRADE_CHART_DIR", "./charts"),
+			Candles: getEnvIntOrDefault("TRADE_CHART_CANDLES", 100),
+		},
+		Profile: VolumeProfileConfig{
+			Enabled:   getEnvBoolOrDefault("VOLUME_PROFILE_ENABLED", false),
+			Bins:      getEnvIntOrDefault("VOLUME_PROFILE_BINS", 50),
+			ValueArea: getEnvFloatOrDefault("VOLUME_PROFILE_VALUE_AREA", 0.7),
+			Sessions:  getEnvIntOrDefault("VOLUME_PROFILE_SESSIONS", 3),
+			MinRoom:   getEnvFloatOrDefault("VOLUME_PROFILE_MIN_ROOM_PCT", 0.005),
+		},
+		OrderFlow: OrderFlowConfig{
+			Enabled:      getEnvBoolOrDefault("ORDER_FLOW_ENABLED", false),
+			Symbols:      getEnvList("ORDER_FLOW_SYMBOLS"),
+			Window:       time.Duration(getEnvIntOrDefault("ORDER_FLOW_WINDOW_SECONDS", 60)) * time.Second,
+			MinImbalance: getEnvFloatOrDefault("ORDER_FLOW_MIN_IMBALANCE", 0.1),
+			MinTrades:    getEnvIntOrDefault("ORDER_FLOW_MIN_TRADES", 20),
+		},
+		Liquidation: LiquidationConfig{
+			Enabled:         getEnvBoolOrDefault("LIQUIDATIONS_ENABLED", false),
+			Symbols:         getEnvList("LIQUIDATIONS_SYMBOLS"),
+			Retention:       time.Duration(getEnvIntOrDefault("LIQUIDATIONS_RETENTION_MINUTES", 60)) * time.Minute,
+			Gap:             time.Duration(getEnvIntOrDefault("LIQUIDATIONS_CLUSTER_GAP_SECONDS", 30)) * time.Second,
+			MinNotional:     getEnvFloatOrDefault("LIQUIDATIONS_MIN_CLUSTER_USD", 100000),
+			Lookback:        time.Duration(getEnvIntOrDefault("LIQUIDATIONS_LOOKBACK_MINUTES", 5)) * time.Minute,
+			ContrarianEntry: getEnvBoolOrDefault("LIQUIDATIONS_CONTRARIAN_ENTRY", false),
+			TightenStops:    getEnvBoolOrDefault("LIQUIDATIONS_TIGHTEN_STOPS", false),
+			TightenStopPct:  getEnvFloatOrDefault("LIQUIDATIONS_TIGHTEN_STOP_PCT", 0.005),
+		},
+		PnL: PositionPnLConfig{
+			Enabled:          getEnvBoolOrDefault("POSITION_PNL_ENABLED", false),
+			Symbols:          getEnvList("POSITION_PNL_SYMBOLS"),
+			RefreshInterval:  time.Duration(getEnvIntOrDefault("POSITION_PNL_REFRESH_SECONDS", 30)) * time.Second,
+			SnapshotInterval: time.Duration(getEnvIntOrDefault("POSITION_PNL_SNAPSHOT_SECONDS", 60)) * time.Second,
+			BreakEvenPct:     getEnvFloatOrDefault("POSITION_PNL_BREAKEVEN_PCT", 0),
+			RetentionDays:    getEnvIntOrDefault("POSITION_PNL_RETENTION_DAYS", 30),
+		},
+		AVWAP: AnchoredVWAPConfig{
+			Enabled:    getEnvBoolOrDefault("AVWAP_TRAIL_ENABLED", false),
+			Buffer:     getEnvFloatOrDefault("AVWAP_TRAIL_BUFFER_PCT", 0.002),
+			MinCandles: getEnvIntOrDefault("AVWAP_TRAIL_MIN_CANDLES", 15),
+		},
+		Pyramid: PyramidConfig{
+			Enabled:   getEnvBoolOrDefault("PYRAMID_ENABLED", false),
+			MaxAdds:   getEnvIntOrDefault("PYRAMID_MAX_ADDS", 2),
+			Step:      getEnvFloatOrDefault("PYRAMID_STEP_PCT", 0.01),
+			SizeDecay: getEnvFloatOrDefault("PYRAMID_SIZE_DECAY", 0.5),
+		},
+		Hedge: HedgeConfig{
+			Enabled:      getEnvBoolOrDefault("HEDGE_ENABLED", false),
+			Schedule:     getEnvOrDefault("HEDGE_SCHEDULE", "0 */15 * * * *"),
+			Venue:        strings.ToLower(getEnvOrDefault("HEDGE_VENUE", "bybit")),
+			Symbol:       strings.ToUpper(getEnvOrDefault("HEDGE_SYMBOL", "BTCUSDT")),
+			Ratio:        getEnvFloatOrDefault("HEDGE_RATIO", 0.5),
+			Tolerance:    getEnvFloatOrDefault("HEDGE_TOLERANCE", 0.2),
+			QtyStep:      getEnvFloatOrDefault("HEDGE_QTY_STEP", 0.001),
+			Signals:      getEnvList("HEDGE_SIGNALS"),
+			FearGreedMax: getEnvFloatOrDefault("HEDGE_FEAR_GREED_MAX", 25),
+			FearGreedAge: time.Duration(getEnvIntOrDefault("HEDGE_FEAR_GREED_MAX_AGE_HOURS", 48)) * time.Hour,
+			TrendPeriod:  getEnvIntOrDefault("HEDGE_TREND_EMA_PERIOD", 200),
+		},
+		Regime: RegimeConfig{
+			Enabled:     getEnvBoolOrDefault("REGIME_ENABLED", false),
+			Schedule:    getEnvOrDefault("REGIME_SCHEDULE", "5 */5 * * * *"),
+			Symbols:     getEnvList("REGIME_SYMBOLS"),
+			Timeframe:   getEnvOrDefault("REGIME_TIMEFRAME", "15"),
+			Method:      strings.ToLower(getEnvOrDefault("REGIME_METHOD", "adx")),
+			ADXPeriod:   getEnvIntOrDefault("REGIME_ADX_PERIOD", 14),
+			ADXTrending: getEnvFloatOrDefault("REGIME_ADX_TRENDING", 25),
+			ADXRanging:  getEnvFloatOrDefault("REGIME_ADX_RANGING", 20),
+			VRLag:       getEnvIntOrDefault("REGIME_VR_LAG", 4),
+			VRWindow:    getEnvIntOrDefault("REGIME_VR_WINDOW", 100),
+			VRTrending:  getEnvFloatOrDefault("REGIME_VR_TRENDING", 1.1),
+			VRRanging:   getEnvFloatOrDefault("REGIME_VR_RANGING", 0.9),
+			Trending:    getEnvList("REGIME_TRENDING_ENTRIES"),
+			Ranging:     getEnvList("REGIME_RANGING_ENTRIES"),
+		},
+		Scorer: ScorerConfig{
+			Enabled:     getEnvBoolOrDefault("SCORER_ENABLED", false),
+			Type:        strings.ToLower(getEnvOrDefault("SCORER_TYPE", "http")),
+			URL:         getEnvOrDefault("SCORER_URL", "http://localhost:8500/score"),
+			Token:       os.Getenv("SCORER_TOKEN"),
+			Timeout:     time.Duration(getEnvIntOrDefault("SCORER_TIMEOUT_MS", 2000)) * time.Millisecond,
+			ONNXModel:   os.Getenv("SCORER_ONNX_MODEL"),
+			ONNXLibrary: os.Getenv("SCORER_ONNX_LIBRARY"),
+			ONNXInput:   getEnvOrDefault("SCORER_ONNX_INPUT", "input"),
+			ONNXOutput:  getEnvOrDefault("SCORER_ONNX_OUTPUT", "output"),
+			Features:    getEnvList("SCORER_FEATURES"),
+			MinScore:    getEnvFloatOrDefault("SCORER_MIN_SCORE", 0.5),
+			MinSize:     getEnvFloatOrDefault("SCORER_MIN_SIZE", 0.5),
+			MaxSize:     getEnvFloatOrDefault("SCORER_MAX_SIZE", 1),
+			FailOpen:    getEnvBoolOrDefault("SCORER_FAIL_OPEN", true),
+		},
+		Paper: PaperTradingConfig{
+			Enabled:           getEnvBoolOrDefault("PAPER_TRADING_ENABLED", false),
+			InitialBalance:    getEnvFloatOrDefault("PAPER_INITIAL_BALANCE", 1000),
+			MaxVolumeFraction: getEnvFloatOrDefault("PAPER_MAX_VOLUME_FRACTION", 0.1),
+			FillPriority:      strings.ToLower(getEnvOrDefault("PAPER_FILL_PRIORITY", "pessimistic")),
+
+			LatencyDistribution: strings.ToLower(getEnvOrDefault("PAPER_LATENCY_DISTRIBUTION", "fixed")),
+			Latency:             time.Duration(getEnvIntOrDefault("PAPER_LATENCY_MS", 0)) * time.Millisecond,
+			LatencyJitter:       time.Duration(getEnvIntOrDefault("PAPER_LATENCY_JITTER_MS", 0)) * time.Millisecond,
+			SlippageBps:         getEnvFloatOrDefault("PAPER_SLIPPAGE_BPS", 0),
+		},
+		Observer: ObserverConfig{
+			Enabled: getEnvBoolOrDefault("OBSERVER_MODE", false),
+		},
+		Jobs: JobQueueConfig{
+			Enabled:             getEnvBoolOrDefault("JOB_QUEUE_ENABLED", true),
+			Schedule:            getEnvOrDefault("JOB_QUEUE_SCHEDULE", "*/15 * * * * *"),
+			RetryDelay:          time.Duration(getEnvIntOrDefault("JOB_RETRY_DELAY_SECONDS", 30)) * time.Second,
+			RetentionDays:       getEnvIntOrDefault("JOB_RETENTION_DAYS", 30),
+			CancelUnfilledAfter: time.Duration(getEnvIntOrDefault("ORDER_CANCEL_UNFILLED_MINUTES", 5)) * time.Minute,
+		},
+		Workers: WorkerSchedulingConfig{
+			Jitter:           time.Duration(getEnvIntOrDefault("WORKER_JITTER_SECONDS", 0)) * time.Second,
+			Stagger:          time.Duration(getEnvIntOrDefault("WORKER_STAGGER_SECONDS", 0)) * time.Second,
+			Mode:             strings.ToLower(getEnvOrDefault("WORKER_SCHEDULE_MODE", WorkerModeCron)),
+			CandleCloseDelay: time.Duration(getEnvIntOrDefault("WORKER_CANDLE_CLOSE_DELAY_MS", 2000)) * time.Millisecond,
+		},
+		Cache: CacheConfig{
+			Backend:       strings.ToLower(getEnvOrDefault("CACHE_BACKEND", "none")),
+			RedisAddr:     getEnvOrDefault("REDIS_ADDR", "localhost:6379"),
+			RedisPassword: os.Getenv("REDIS_PASSWORD"),
+			RedisDB:       getEnvIntOrDefault("REDIS_DB", 0),
+			Prefix:        getEnvOrDefault("CACHE_PREFIX", "mkybot:"),
+			PriceTTL:      time.Duration(getEnvIntOrDefault("CACHE_PRICE_TTL_SECONDS", 5)) * time.Second,
+			BalanceTTL:    time.Duration(getEnvIntOrDefault("CACHE_BALANCE_TTL_SECONDS", 30)) * time.Second,
+			PositionsTTL:  time.Duration(getEnvIntOrDefault("CACHE_POSITIONS_TTL_SECONDS", 10)) * time.Second,
+		},
+		Events: EventsConfig{
+			Backend:      strings.ToLower(getEnvOrDefault("EVENTS_BACKEND", "none")),
+			NATSURL:      getEnvOrDefault("EVENTS_NATS_URL", "nats://localhost:4222"),
+			KafkaRESTURL: getEnvOrDefault("EVENTS_KAFKA_REST_URL", "http://localhost:8082"),
+			TopicPrefix:  getEnvOrDefault("EVENTS_TOPIC_PREFIX", "mkybot"),
+			Buffer:       getEnvIntOrDefault("EVENTS_BUFFER", 1000),
+		},
+		Errors: ErrorReportingConfig{
+			SentryDSN:   os.Getenv("SENTRY_DSN"),
+			Environment: getEnvOrDefault("SENTRY_ENVIRONMENT", "production"),
+			Release:     os.Getenv("SENTRY_RELEASE"),
+			SampleRate:  getEnvFloatOrDefault("SENTRY_SAMPLE_RATE", 1),
+		},
+		Watchdog: WatchdogConfig{
+			Enabled:       getEnvBoolOrDefault("WATCHDOG_ENABLED", true),
+			Interval:      time.Duration(getEnvIntOrDefault("WATCHDOG_INTERVAL_SECONDS", 15)) * time.Second,
+			WorkerTimeout: time.Duration(getEnvIntOrDefault("WATCHDOG_WORKER_TIMEOUT_SECONDS", 600)) * time.Second,
+			StreamTimeout: time.Duration(getEnvIntOrDefault("WATCHDOG_STREAM_TIMEOUT_SECONDS", 60)) * time.Second,
+		},
+		Latency: LatencySLOConfig{
+			Target:   time.Duration(getEnvIntOrDefault("ORDER_LATENCY_SLO_MS", 10000)) * time.Millisecond,
+			Breaches: getEnvIntOrDefault("ORDER_LATENCY_SLO_BREACHES", 3),
+		},
+		Quality: CandleQualityConfig{
+			Enabled:       getEnvBoolOrDefault("CANDLE_QUALITY_ENABLED", true),
+			Window:        getEnvIntOrDefault("CANDLE_QUALITY_WINDOW", 20),
+			MaxGaps:       getEnvIntOrDefault("CANDLE_QUALITY_MAX_GAPS", 0),
+			MaxZeroVolume: getEnvFloatOrDefault("CANDLE_QUALITY_MAX_ZERO_VOLUME", 0.05),
+			MaxErrorRate:  getEnvFloatOrDefault("CANDLE_QUALITY_MAX_ERROR_RATE", 0.2),
+		},
+		Retry: OrderRetryConfig{
+			Attempts: getEnvIntOrDefault("ORDER_RETRY_ATTEMPTS", 3),
+			Backoff:  time.Duration(getEnvIntOrDefault("ORDER_RETRY_BACKOFF_MS", 1000)) * time.Millisecond,
+		},
+		OrderCheck: OrderPreflightConfig{
+			Enabled: getEnvBoolOrDefault("ORDER_PREFLIGHT_ENABLED", true),
+		},
+		Throttle: OrderThrottleConfig{
+			Enabled:          getEnvBoolOrDefault("ORDER_THROTTLE_ENABLED", true),
+			SymbolPerMinute:  getEnvIntOrDefault("ORDER_THROTTLE_SYMBOL_PER_MINUTE", 5),
+			SymbolPerHour:    getEnvIntOrDefault("ORDER_THROTTLE_SYMBOL_PER_HOUR", 30),
+			AccountPerMinute: getEnvIntOrDefault("ORDER_THROTTLE_ACCOUNT_PER_MINUTE", 20),
+			AccountPerHour:   getEnvIntOrDefault("ORDER_THROTTLE_ACCOUNT_PER_HOUR", 200),
+			Mode:             strings.ToLower(getEnvOrDefault("ORDER_THROTTLE_MODE", "reject")),
+			MaxWait:          time.Duration(getEnvIntOrDefault("ORDER_THROTTLE_MAX_WAIT_SECONDS", 30)) * time.Second,
+		},
+		Slicing: OrderSlicingConfig{
+			Enabled:         getEnvBoolOrDefault("ORDER_SLICING_ENABLED", false),
+			Method:          strings.ToLower(getEnvOrDefault("ORDER_SLICING_METHOD", "twap")),
+			MaxBookFraction: getEnvFloatOrDefault("ORDER_SLICING_MAX_BOOK_FRACTION", 0.5),
+			Slices:          getEnvIntOrDefault("ORDER_SLICING_SLICES", 5),
+			Interval:        time.Duration(getEnvIntOrDefault("ORDER_SLICING_INTERVAL_SECONDS", 30)) * time.Second,
+		},
+		Execution: ExecutionConfig{
+			DogeUrgency:    strings.ToLower(getEnvOrDefault("EXECUTION_DOGE_URGENCY", "high")),
+			TWAPSlices:     getEnvIntOrDefault("EXECUTION_TWAP_SLICES", 4),
+			TWAPDuration:   time.Duration(getEnvIntOrDefault("EXECUTION_TWAP_DURATION_SECONDS", 60)) * time.Second,
+			PassiveTimeout: time.Duration(getEnvIntOrDefault("EXECUTION_PASSIVE_TIMEOUT_SECONDS", 10)) * time.Second,
+			PegDuration:    time.Duration(getEnvIntOrDefault("EXECUTION_PEG_DURATION_SECONDS", 30)) * time.Second,
+			PegInterval:    time.Duration(getEnvIntOrDefault("EXECUTION_PEG_REPEG_SECONDS", 2)) * time.Second,
+		},
+		Spread: SpreadGuardConfig{
+			Enabled: getEnvBoolOrDefault("SPREAD_GUARD_ENABLED", true),
+			MaxBps:  getEnvFloatOrDefault("SPREAD_GUARD_MAX_BPS", 20),
+			Mode:    strings.ToLower(getEnvOrDefault("SPREAD_GUARD_MODE", "block")),
+			MaxWait: time.Duration(getEnvIntOrDefault("SPREAD_GUARD_MAX_WAIT_SECONDS", 30)) * time.Second,
+		},
+		Features: FeatureFlagsConfig{
+			Environment: getEnvOrDefault("FEATURE_FLAGS_ENVIRONMENT", getEnvOrDefault("SENTRY_ENVIRONMENT", "production")),
+			Account:     getEnvOrDefault("FEATURE_FLAGS_ACCOUNT", getEnvOrDefault("LOCK_ACCOUNT", "default")),
+		},
+		Risk: RiskConfig{
+			MaxDataAge:        time.Duration(getEnvIntOrDefault("RISK_MAX_DATA_AGE_SECONDS", 30)) * time.Second,
+			PriceReference:    strings.ToLower(getEnvOrDefault("RISK_PRICE_REFERENCE", "index")),
+			MaxPriceDeviation: getEnvFloatOrDefault("RISK_MAX_PRICE_DEVIATION", 0.02),
+			SymbolAllowList:   getEnvList("RISK_SYMBOL_ALLOWLIST"),
+			SymbolDenyList:    getEnvList("RISK_SYMBOL_DENYLIST"),
+			MaxOpenPositions:  getEnvIntOrDefault("RISK_MAX_OPEN_POSITIONS", 0),
+			SizingMode:        strings.ToLower(getEnvOrDefault("RISK_SIZING_MODE", "compound")),
+			BaseCapital:       getEnvFloatOrDefault("RISK_BASE_CAPITAL", 0),
+			BaseCapitalReset:  strings.ToLower(getEnvOrDefault("RISK_BASE_CAPITAL_RESET", "never")),
+			BaseCapitalChange: getEnvFloatOrDefault("RISK_BASE_CAPITAL_RESET_CHANGE", 0),
+			StopSwingLookback: getEnvIntOrDefault("RISK_STOP_SWING_LOOKBACK", 20),
+			StopATRPeriod:     getEnvIntOrDefault("RISK_STOP_ATR_PERIOD", 22),
+			StopATRMultiplier: getEnvFloatOrDefault("RISK_STOP_ATR_MULTIPLIER", 3),
+		},
+		Degraded: DegradedModeConfig{
+			Action:     strings.ToLower(getEnvOrDefault("DEGRADED_MODE_ACTION", "freeze")),
+			Failures:   getEnvIntOrDefault("DEGRADED_MODE_FAILURES", 3),
+			Recovery:   getEnvIntOrDefault("DEGRADED_MODE_RECOVERY", 2),
+			RecvWindow: time.Duration(getEnvIntOrDefault("DEGRADED_MODE_RECV_WINDOW_MS", 20000)) * time.Millisecond,
+		},
+		Chaos: ChaosConfig{
+			Enabled:     getEnvBoolOrDefault("CHAOS_ENABLED", false),
+			TimeoutRate: getEnvFloatOrDefault("CHAOS_TIMEOUT_RATE", 0),
+			ErrorRate:   getEnvFloatOrDefault("CHAOS_ERROR_RATE", 0),
+			Latency:     time.Duration(getEnvIntOrDefault("CHAOS_LATENCY_MS", 0)) * time.Millisecond,
+			Venues:      getEnvList("CHAOS_VENUES"),
+			Paths:       getEnvList("CHAOS_PATHS"),
+		},
+		Lock: LockConfig{
+			Enabled: getEnvBoolOrDefault("LOCK_ENABLED", false),
+			Backend: strings.ToLower(getEnvOrDefault("LOCK_BACKEND", "database")),
+			Account: getEnvOrDefault("LOCK_ACCOUNT", "default"),
+			Owner:   os.Getenv("LOCK_OWNER"),
+			TTL:     time.Duration(getEnvIntOrDefault("LOCK_TTL_SECONDS", 60)) * time.Second,
+			Standby: getEnvBoolOrDefault("LOCK_STANDBY_ENABLED", false),
 		},
 		LogLevel: getEnvOrDefault("LOG_LEVEL", "info"),
 	}
 
+	locale, err := i18n.ParseLocale(getEnvOrDefault("LOCALE", string(i18n.DefaultLocale)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOCALE: %w", err)
+	}
+	config.Locale = locale
+
+	sessionOpen, err := time.Parse("15:04", getEnvOrDefault("LEVELS_SESSION_OPEN_UTC", "00:00"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEVELS_SESSION_OPEN_UTC (expected HH:MM): %w", err)
+	}
+	config.Reporting.SessionOpen = time.Duration(sessionOpen.Hour())*time.Hour + time.Duration(sessionOpen.Minute())*time.Minute
+
+	if config.Bybit.AuthType != "hmac" && config.Bybit.AuthType != "rsa" {
+		return nil, fmt.Errorf("invalid BYBIT_AUTH_TYPE %q: expected hmac or rsa", config.Bybit.AuthType)
+	}
+	if config.Bybit.ReadOnly.AuthType != "hmac" && config.Bybit.ReadOnly.AuthType != "rsa" {
+		return nil, fmt.Errorf("invalid BYBIT_READONLY_AUTH_TYPE %q: expected hmac or rsa", config.Bybit.ReadOnly.AuthType)
+	}
+	for strategy, creds := range config.Bybit.SubAccounts {
+		if creds.AuthType != "hmac" && creds.AuthType != "rsa" {
+			return nil, fmt.Errorf("invalid %sAUTH_TYPE %q: expected hmac or rsa", SubAccountPrefix(strategy), creds.AuthType)
+		}
+	}
+
+	if len(config.Prices.Symbols) == 0 {
+		config.Prices.Symbols = []string{"DOGEUSDT"}
+	}
+	if config.Prices.Interval <= 0 {
+		return nil, fmt.Errorf("PRICE_AGGREGATOR_INTERVAL_SECONDS must be positive")
+	}
+
+	if len(config.Basis.Symbols) == 0 {
+		config.Basis.Symbols = []string{"DOGEUSDT"}
+	}
+	if config.Basis.LowerThreshold >= config.Basis.UpperThreshold {
+		return nil, fmt.Errorf("BASIS_ALERT_LOWER must be lower than BASIS_ALERT_UPPER")
+	}
+
+	if len(config.Calendar.Keywords) == 0 {
+		config.Calendar.Keywords = []string{"FOMC", "Federal Funds Rate", "CPI"}
+	}
+	if config.Calendar.Enabled {
+		if config.Calendar.Provider != "file" && config.Calendar.Provider != "http" {
+			return nil, fmt.Errorf("invalid CALENDAR_PROVIDER %q: expected file or http", config.Calendar.Provider)
+		}
+		if config.Calendar.Before < 0 || config.Calendar.After < 0 {
+			return nil, fmt.Errorf("CALENDAR_BLACKOUT_BEFORE_MINUTES and CALENDAR_BLACKOUT_AFTER_MINUTES must not be negative")
+		}
+		if config.Calendar.TightenStops && (config.Calendar.TightenStopPct <= 0 || config.Calendar.TightenStopPct >= 1) {
+			return nil, fmt.Errorf("CALENDAR_TIGHTEN_STOP_PCT must be between 0 and 1")
+		}
+	}
+
+	if len(config.DataSources.Sources) == 0 {
+		config.DataSources.Sources = []string{"fear_greed"}
+	}
+	if config.DataSources.BackfillDays < 0 {
+		return nil, fmt.Errorf("DATA_SOURCES_BACKFILL_DAYS must not be negative")
+	}
+
+	if config.Charts.Enabled && (config.Charts.Candles < 10 || config.Charts.Candles > 500) {
+		return nil, fmt.Errorf("TRADE_CHART_CANDLES must be between 10 and 500")
+	}
+
+	if config.Profile.Enabled && (config.Profile.Sessions <= 0 || config.Profile.MinRoom < 0) {
+		return nil, fmt.Errorf("VOLUME_PROFILE_SESSIONS must be positive and VOLUME_PROFILE_MIN_ROOM_PCT must not be negative")
+	}
+
+	if len(config.OrderFlow.Symbols) == 0 {
+		config.OrderFlow.Symbols = []string{"DOGEUSDT"}
+	}
+	if config.OrderFlow.Enabled && config.OrderFlow.Window <= 0 {
+		return nil, fmt.Errorf("ORDER_FLOW_WINDOW_SECONDS must be positive")
+	}
+
+	if len(config.Liquidation.Symbols) == 0 {
+		config.Liquidation.Symbols = []string{"DOGEUSDT"}
+	}
+	if config.Liquidation.Enabled {
+		if config.Liquidation.Retention <= 0 || config.Liquidation.Gap <= 0 || config.Liquidation.Lookback <= 0 {
+			return nil, fmt.Errorf("LIQUIDATIONS_RETENTION_MINUTES, LIQUIDATIONS_CLUSTER_GAP_SECONDS and LIQUIDATIONS_LOOKBACK_MINUTES must be positive")
+		}
+		if config.Liquidation.TightenStopPct <= 0 || config.Liquidation.TightenStopPct >= 1 {
+			return nil, fmt.Errorf("LIQUIDATIONS_TIGHTEN_STOP_PCT must be between 0 and 1")
+		}
+	}
+
+	if len(config.PnL.Symbols) == 0 {
+		config.PnL.Symbols = []string{"DOGEUSDT"}
+	}
+	if config.PnL.Enabled && (config.PnL.RefreshInterval <= 0 || config.PnL.SnapshotInterval <= 0) {
+		return nil, fmt.Errorf("POSITION_PNL_REFRESH_SECONDS and POSITION_PNL_SNAPSHOT_SECONDS must be positive")
+	}
+	if config.PnL.BreakEvenPct < 0 || config.PnL.BreakEvenPct >= 1 {
+		return nil, fmt.Errorf("POSITION_PNL_BREAKEVEN_PCT must be between 0 and 1")
+	}
+	if config.PnL.RetentionDays < 0 {
+		return nil, fmt.Errorf("POSITION_PNL_RETENTION_DAYS must not be negative")
+	}
+
+	if len(config.Regime.Symbols) == 0 {
+		config.Regime.Symbols = []string{"DOGEUSDT"}
+	}
+	// Una lista vuota impostata esplicitamente disattiva tutti gli ingressi nel regime
+	if _, ok := os.LookupEnv("REGIME_TRENDING_ENTRIES"); !ok {
+		config.Regime.Trending = []string{"breakout"}
+	}
+	if _, ok := os.LookupEnv("REGIME_RANGING_ENTRIES"); !ok {
+		config.Regime.Ranging = []string{"liquidation"}
+	}
+
+	if len(config.Hedge.Signals) == 0 {
+		config.Hedge.Signals = []string{"fear_greed", "btc_trend"}
+	}
+	if config.Hedge.Enabled {
+		if config.Hedge.Ratio <= 0 || config.Hedge.Ratio > 1 {
+			return nil, fmt.Errorf("HEDGE_RATIO must be between 0 (excluded) and 1")
+		}
+		if config.Hedge.Tolerance < 0 || config.Hedge.Tolerance >= 1 {
+			return nil, fmt.Errorf("HEDGE_TOLERANCE must be between 0 and 1")
+		}
+		if config.Hedge.QtyStep <= 0 {
+			return nil, fmt.Errorf("HEDGE_QTY_STEP must be positive")
+		}
+	}
+
+	if config.Scorer.Enabled {
+		if config.Scorer.Type != "http" && config.Scorer.Type != "onnx" {
+			return nil, fmt.Errorf("invalid SCORER_TYPE %q: expected http or onnx", config.Scorer.Type)
+		}
+		if config.Scorer.Type == "onnx" && config.Scorer.ONNXModel == "" {
+			return nil, fmt.Errorf("SCORER_ONNX_MODEL must be set when SCORER_TYPE is onnx")
+		}
+		if config.Scorer.MinSize <= 0 || config.Scorer.MinSize > config.Scorer.MaxSize || config.Scorer.MaxSize > 1 {
+			return nil, fmt.Errorf("SCORER_MIN_SIZE and SCORER_MAX_SIZE must satisfy 0 < min <= max <= 1")
+		}
+	}
+
+	if config.Paper.Enabled {
+		if config.Paper.InitialBalance <= 0 {
+			return nil, fmt.Errorf("PAPER_INITIAL_BALANCE must be positive")
+		}
+		if config.Paper.MaxVolumeFraction < 0 || config.Paper.MaxVolumeFraction > 1 {
+			return nil, fmt.Errorf("PAPER_MAX_VOLUME_FRACTION must be between 0 and 1")
+		}
+		if config.Paper.FillPriority != "pessimistic" && config.Paper.FillPriority != "optimistic" {
+			return nil, fmt.Errorf("invalid PAPER_FILL_PRIORITY %q: expected pessimistic or optimistic", config.Paper.FillPriority)
+		}
+		switch config.Paper.LatencyDistribution {
+		case "fixed", "uniform", "normal":
+		default:
+			return nil, fmt.Errorf("invalid PAPER_LATENCY_DISTRIBUTION %q: expected fixed, uniform or normal", config.Paper.LatencyDistribution)
+		}
+		if config.Paper.Latency < 0 || config.Paper.LatencyJitter < 0 {
+			return nil, fmt.Errorf("PAPER_LATENCY_MS and PAPER_LATENCY_JITTER_MS must not be negative")
+		}
+		if config.Paper.SlippageBps < 0 {
+			return nil, fmt.Errorf("PAPER_SLIPPAGE_BPS must not be negative")
+		}
+	}
+
+	if config.Observer.Enabled && config.Paper.Enabled {
+		return nil, fmt.Errorf("OBSERVER_MODE and PAPER_TRADING_ENABLED cannot both be enabled")
+	}
+
+	if config.Jobs.RetryDelay <= 0 {
+		return nil, fmt.Errorf("JOB_RETRY_DELAY_SECONDS must be positive")
+	}
+	if config.Jobs.RetentionDays < 0 || config.Jobs.CancelUnfilledAfter < 0 {
+		return nil, fmt.Errorf("JOB_RETENTION_DAYS and ORDER_CANCEL_UNFILLED_MINUTES must not be negative")
+	}
+
+	if config.Workers.Jitter < 0 || config.Workers.Stagger < 0 {
+		return nil, fmt.Errorf("WORKER_JITTER_SECONDS and WORKER_STAGGER_SECONDS must not be negative")
+	}
+	if config.Workers.Mode != WorkerModeCron && config.Workers.Mode != WorkerModeCandleClose {
+		return nil, fmt.Errorf("invalid WORKER_SCHEDULE_MODE %q (use cron or candle_close)", config.Workers.Mode)
+	}
+	// Il ritardo deve restare sotto la durata della candela più breve, quella da 1 minuto
+	if config.Workers.CandleCloseDelay < 0 || config.Workers.CandleCloseDelay >= time.Minute {
+		return nil, fmt.Errorf("WORKER_CANDLE_CLOSE_DELAY_MS must be between 0 and 59999")
+	}
+
+	if config.Lock.Enabled && config.Lock.TTL < 3*time.Second {
+		return nil, fmt.Errorf("LOCK_TTL_SECONDS must be at least 3")
+	}
+	if config.Lock.Standby && !config.Lock.Enabled {
+		return nil, fmt.Errorf("LOCK_STANDBY_ENABLED requires LOCK_ENABLED")
+	}
+	if config.Lock.Backend != "database" && config.Lock.Backend != "redis" {
+		return nil, fmt.Errorf("invalid LOCK_BACKEND %q (use database or redis)", config.Lock.Backend)
+	}
+
+	switch config.Cache.Backend {
+	case "none":
+	case "memory", "redis":
+		if config.Cache.PriceTTL <= 0 || config.Cache.BalanceTTL <= 0 || config.Cache.PositionsTTL <= 0 {
+			return nil, fmt.Errorf("CACHE_*_TTL_SECONDS must be positive")
+		}
+	default:
+		return nil, fmt.Errorf("invalid CACHE_BACKEND %q (use none, memory or redis)", config.Cache.Backend)
+	}
+
+	switch config.Events.Backend {
+	case "none", "nats", "kafka":
+	default:
+		return nil, fmt.Errorf("invalid EVENTS_BACKEND %q (use none, nats or kafka)", config.Events.Backend)
+	}
+	if config.Events.Backend != "none" && config.Events.Buffer <= 0 {
+		return nil, fmt.Errorf("EVENTS_BUFFER must be positive")
+	}
+
+	if config.Errors.SampleRate <= 0 || config.Errors.SampleRate > 1 {
+		return nil, fmt.Errorf("SENTRY_SAMPLE_RATE must be between 0 (excluded) and 1")
+	}
+
+	if config.Watchdog.Enabled {
+		if config.Watchdog.Interval <= 0 {
+			return nil, fmt.Errorf("WATCHDOG_INTERVAL_SECONDS must be positive")
+		}
+		if config.Watchdog.WorkerTimeout <= config.Watchdog.Interval || config.Watchdog.StreamTimeout <= config.Watchdog.Interval {
+			return nil, fmt.Errorf("WATCHDOG_WORKER_TIMEOUT_SECONDS and WATCHDOG_STREAM_TIMEOUT_SECONDS must be longer than WATCHDOG_INTERVAL_SECONDS")
+		}
+	}
+
+	if config.Latency.Target < 0 {
+		return nil, fmt.Errorf("ORDER_LATENCY_SLO_MS must not be negative")
+	}
+	if config.Latency.Target > 0 && config.Latency.Breaches < 1 {
+		return nil, fmt.Errorf("ORDER_LATENCY_SLO_BREACHES must be at least 1")
+	}
+	if config.Quality.Enabled {
+		if config.Quality.Window < 1 {
+			return nil, fmt.Errorf("CANDLE_QUALITY_WINDOW must be at least 1")
+		}
+		if config.Quality.MaxGaps < 0 {
+			return nil, fmt.Errorf("CANDLE_QUALITY_MAX_GAPS must not be negative")
+		}
+		if config.Quality.MaxZeroVolume < 0 || config.Quality.MaxZeroVolume > 1 {
+			return nil, fmt.Errorf("CANDLE_QUALITY_MAX_ZERO_VOLUME must be between 0 and 1")
+		}
+		if config.Quality.MaxErrorRate < 0 || config.Quality.MaxErrorRate > 1 {
+			return nil, fmt.Errorf("CANDLE_QUALITY_MAX_ERROR_RATE must be between 0 and 1")
+		}
+	}
+	if config.Retry.Attempts < 1 {
+		return nil, fmt.Errorf("ORDER_RETRY_ATTEMPTS must be at least 1")
+	}
+	if config.Retry.Backoff < 0 {
+		return nil, fmt.Errorf("ORDER_RETRY_BACKOFF_MS must not be negative")
+	}
+	if config.Throttle.Mode != "reject" && config.Throttle.Mode != "queue" {
+		return nil, fmt.Errorf("ORDER_THROTTLE_MODE must be reject or queue, got %q", config.Throttle.Mode)
+	}
+	if config.Throttle.SymbolPerMinute < 0 || config.Throttle.SymbolPerHour < 0 ||
+		config.Throttle.AccountPerMinute < 0 || config.Throttle.AccountPerHour < 0 {
+		return nil, fmt.Errorf("ORDER_THROTTLE_* limits must not be negative")
+	}
+	if config.Throttle.MaxWait < 0 {
+		return nil, fmt.Errorf("ORDER_THROTTLE_MAX_WAIT_SECONDS must not be negative")
+	}
+	if config.Slicing.Enabled {
+		if config.Slicing.Method != "twap" && config.Slicing.Method != "iceberg" {
+			return nil, fmt.Errorf("ORDER_SLICING_METHOD must be twap or iceberg, got %q", config.Slicing.Method)
+		}
+		if config.Slicing.MaxBookFraction <= 0 {
+			return nil, fmt.Errorf("ORDER_SLICING_MAX_BOOK_FRACTION must be positive")
+		}
+		if config.Slicing.Slices < 2 {
+			return nil, fmt.Errorf("ORDER_SLICING_SLICES must be at least 2")
+		}
+		if config.Slicing.Interval <= 0 {
+			return nil, fmt.Errorf("ORDER_SLICING_INTERVAL_SECONDS must be positive")
+		}
+		if !config.Jobs.Enabled {
+			return nil, fmt.Errorf("ORDER_SLICING_ENABLED requires JOB_QUEUE_ENABLED=true")
+		}
+	}
+	if !isUrgency(config.Execution.DogeUrgency) {
+		return nil, fmt.Errorf("EXECUTION_DOGE_URGENCY must be high, normal or low, got %q", config.Execution.DogeUrgency)
+	}
+	if config.Execution.TWAPSlices < 1 {
+		return nil, fmt.Errorf("EXECUTION_TWAP_SLICES must be at least 1")
+	}
+	if config.Execution.TWAPDuration < 0 {
+		return nil, fmt.Errorf("EXECUTION_TWAP_DURATION_SECONDS must not be negative")
+	}
+	if config.Execution.PassiveTimeout <= 0 {
+		return nil, fmt.Errorf("EXECUTION_PASSIVE_TIMEOUT_SECONDS must be positive")
+	}
+	if config.Execution.PegDuration <= 0 || config.Execution.PegInterval <= 0 {
+		return nil, fmt.Errorf("EXECUTION_PEG_DURATION_SECONDS and EXECUTION_PEG_REPEG_SECONDS must be positive")
+	}
+	if config.Spread.Enabled {
+		if config.Spread.MaxBps <= 0 {
+			return nil, fmt.Errorf("SPREAD_GUARD_MAX_BPS must be positive")
+		}
+		if config.Spread.Mode != "block" && config.Spread.Mode != "defer" {
+			return nil, fmt.Errorf("SPREAD_GUARD_MODE must be block or defer, got %q", config.Spread.Mode)
+		}
+		if config.Spread.MaxWait < 0 {
+			return nil, fmt.Errorf("SPREAD_GUARD_MAX_WAIT_SECONDS must not be negative")
+		}
+	}
+	if config.Risk.MaxDataAge < 0 {
+		return nil, fmt.Errorf("RISK_MAX_DATA_AGE_SECONDS cannot be negative")
+	}
+	if config.Risk.MaxOpenPositions < 0 {
+		return nil, fmt.Errorf("RISK_MAX_OPEN_POSITIONS cannot be negative")
+	}
+	switch config.Risk.PriceReference {
+	case "none", "index", "bybit", "kraken":
+	default:
+		return nil, fmt.Errorf("invalid RISK_PRICE_REFERENCE %q (use none, index, bybit or kraken)", config.Risk.PriceReference)
+	}
+	if config.Risk.PriceReference != "none" && (config.Risk.MaxPriceDeviation <= 0 || config.Risk.MaxPriceDeviation >= 1) {
+		return nil, fmt.Errorf("RISK_MAX_PRICE_DEVIATION must be between 0 and 1 (both excluded)")
+	}
+	budgets, err := getEnvFloatMap("RISK_WORKER_BUDGETS", "")
+	if err != nil {
+		return nil, err
+	}
+	var totalShare float64
+	for worker, share := range budgets {
+		if share <= 0 || share > 1 {
+			return nil, fmt.Errorf("RISK_WORKER_BUDGETS share for %q must be between 0 (excluded) and 1", worker)
+		}
+		totalShare += share
+	}
+	if totalShare > 1+1e-9 {
+		return nil, fmt.Errorf("RISK_WORKER_BUDGETS shares add up to %.2f, must not exceed 1", totalShare)
+	}
+	config.Risk.WorkerBudgets = budgets
+	if config.Risk.SizingMode != "compound" && config.Risk.SizingMode != "fixed" {
+		return nil, fmt.Errorf("invalid RISK_SIZING_MODE %q: expected compound or fixed", config.Risk.SizingMode)
+	}
+	switch config.Risk.BaseCapitalReset {
+	case "never", "daily", "weekly", "monthly":
+	default:
+		return nil, fmt.Errorf("invalid RISK_BASE_CAPITAL_RESET %q (use never, daily, weekly or monthly)", config.Risk.BaseCapitalReset)
+	}
+	if config.Risk.BaseCapital < 0 || config.Risk.BaseCapitalChange < 0 {
+		return nil, fmt.Errorf("RISK_BASE_CAPITAL and RISK_BASE_CAPITAL_RESET_CHANGE cannot be negative")
+	}
+	if config.Risk.BaseCapital > 0 && (config.Risk.BaseCapitalReset != "never" || config.Risk.BaseCapitalChange > 0) {
+		return nil, fmt.Errorf("RISK_BASE_CAPITAL_RESET and RISK_BASE_CAPITAL_RESET_CHANGE require RISK_BASE_CAPITAL=0 (base anchored on the balance)")
+	}
+	stopModes, err := getEnvStringMap("RISK_STOP_MODES")
+	if err != nil {
+		return nil, err
+	}
+	for worker, mode := range stopModes {
+		switch mode {
+		case "fixed":
+		case "swing":
+			if config.Risk.StopSwingLookback <= 4 {
+				return nil, fmt.Errorf("RISK_STOP_SWING_LOOKBACK must be greater than 4 when %s uses swing stops", worker)
+			}
+		case "chandelier":
+			if config.Risk.StopATRPeriod <= 0 || config.Risk.StopATRMultiplier <= 0 {
+				return nil, fmt.Errorf("RISK_STOP_ATR_PERIOD and RISK_STOP_ATR_MULTIPLIER must be positive when %s uses chandelier stops", worker)
+			}
+		default:
+			return nil, fmt.Errorf("invalid RISK_STOP_MODES mode %q for %s (use fixed, swing or chandelier)", mode, worker)
+		}
+	}
+	config.Risk.StopModes = stopModes
+
+	featureRules, err := getEnvStringMap("FEATURE_FLAGS")
+	if err != nil {
+		return nil, err
+	}
+	if err := features.ValidateRules(featureRules); err != nil {
+		return nil, fmt.Errorf("invalid FEATURE_FLAGS: %w", err)
+	}
+	config.Features.Rules = featureRules
+
+	config.Indicators.Sources, err = parseIndicatorSources()
+	if err != nil {
+		return nil, err
+	}
+
+	executionAlgos, err := getEnvStringMap("EXECUTION_ALGOS")
+	if err != nil {
+		return nil, err
+	}
+	config.Execution.Algos = map[string]string{"high": "immediate", "normal": "passive_then_cross", "low": "twap"}
+	for urgency, algo := range executionAlgos {
+		if !isUrgency(urgency) {
+			return nil, fmt.Errorf("invalid EXECUTION_ALGOS urgency %q: expected high, normal or low", urgency)
+		}
+		if algo != "immediate" && algo != "twap" && algo != "passive_then_cross" && algo != "peg" {
+			return nil, fmt.Errorf("invalid EXECUTION_ALGOS algorithm %q: expected immediate, twap, passive_then_cross or peg", algo)
+		}
+		config.Execution.Algos[urgency] = algo
+	}
+
+	if config.Degraded.Action != "freeze" && config.Degraded.Action != "alert" {
+		return nil, fmt.Errorf("invalid DEGRADED_MODE_ACTION %q: expected freeze or alert", config.Degraded.Action)
+	}
+	if config.Degraded.Failures < 1 || config.Degraded.Recovery < 1 {
+		return nil, fmt.Errorf("DEGRADED_MODE_FAILURES and DEGRADED_MODE_RECOVERY must be at least 1")
+	}
+	if config.Degraded.RecvWindow < 0 {
+		return nil, fmt.Errorf("DEGRADED_MODE_RECV_WINDOW_MS must not be negative")
+	}
+	if config.Chaos.Enabled {
+		if config.Chaos.TimeoutRate < 0 || config.Chaos.ErrorRate < 0 || config.Chaos.TimeoutRate+config.Chaos.ErrorRate > 1 {
+			return nil, fmt.Errorf("CHAOS_TIMEOUT_RATE and CHAOS_ERROR_RATE must be between 0 and 1 and sum to at most 1")
+		}
+		if config.Chaos.Latency < 0 {
+			return nil, fmt.Errorf("CHAOS_LATENCY_MS must not be negative")
+		}
+	}
+	if value := os.Getenv("SIMULATION_SEED"); value != "" {
+		seed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SIMULATION_SEED %q: expected an unsigned integer", value)
+		}
+		config.Simulation.Seed = seed
+	}
+
+	takerFees, err := getEnvFloatMap("ARB_TAKER_FEES", "bybit=0.00055,kraken=0.0005,binance=0.0005")
+	if err != nil {
+		return nil, err
+	}
+	config.Arbitrage.TakerFees = takerFees
+	latencies, err := getEnvFloatMap("ARB_ORDER_LATENCY_MS", "bybit=150,kraken=250,binance=150")
+	if err != nil {
+		return nil, err
+	}
+	config.Arbitrage.OrderLatency = make(map[string]time.Duration, len(latencies))
+	for venue, ms := range latencies {
+		config.Arbitrage.OrderLatency[venue] = time.Duration(ms * float64(time.Millisecond))
+	}
+	if config.Arbitrage.Enabled && config.Arbitrage.Quantity <= 0 {
+		return nil, fmt.Errorf("ARB_QUANTITY must be positive when ARB_DETECTOR_ENABLED is true")
+	}
+
+	thresholds, err := getEnvFloatMap("BALANCE_SYNC_THRESHOLDS", "")
+	if err != nil {
+		return nil, err
+	}
+	config.BalanceSync.Thresholds = thresholds
+	if config.BalanceSync.Enabled && len(thresholds) == 0 {
+		return nil, fmt.Errorf("BALANCE_SYNC_THRESHOLDS must be set when BALANCE_SYNC_ENABLED is true")
+	}
+
+	weights, err := getEnvFloatMap("PORTFOLIO_REBALANCE_WEIGHTS", "")
+	if err != nil {
+		return nil, err
+	}
+	for account, weight := range weights {
+		if account != "master" && !slices.Contains(SubAccountStrategies, account) {
+			return nil, fmt.Errorf("PORTFOLIO_REBALANCE_WEIGHTS account %q must be master or a strategy with a sub-account (%s)", account, strings.Join(SubAccountStrategies, ", "))
+		}
+		if weight <= 0 {
+			return nil, fmt.Errorf("PORTFOLIO_REBALANCE_WEIGHTS weight for %q must be positive", account)
+		}
+	}
+	config.Rebalance.Weights = weights
+	if config.Rebalance.Enabled {
+		if len(weights) < 2 {
+			return nil, fmt.Errorf("PORTFOLIO_REBALANCE_WEIGHTS must list at least two accounts when PORTFOLIO_REBALANCE_ENABLED is true")
+		}
+		if config.Rebalance.Drift <= 0 || config.Rebalance.Drift >= 1 {
+			return nil, fmt.Errorf("PORTFOLIO_REBALANCE_DRIFT must be between 0 and 1 (both excluded)")
+		}
+		if config.Rebalance.MinTransfer < 0 {
+			return nil, fmt.Errorf("PORTFOLIO_REBALANCE_MIN_TRANSFER must not be negative")
+		}
+	}
+
+	if config.Watch.Grace < 0 {
+		return nil, fmt.Errorf("ACCOUNT_WATCH_GRACE_SECONDS must not be negative")
+	}
+
+	if config.Orphans.Action != "cancel" && config.Orphans.Action != "adopt" {
+		return nil, fmt.Errorf("ORPHAN_SWEEP_ACTION must be cancel or adopt, got %q", config.Orphans.Action)
+	}
+	if config.Orphans.Grace < 0 {
+		return nil, fmt.Errorf("ORPHAN_SWEEP_GRACE_SECONDS must not be negative")
+	}
+	if len(config.Orphans.Symbols) == 0 {
+		config.Orphans.Symbols = []string{"DOGEUSDT"}
+	}
+
+	tokens, err := parseAPITokens(os.Getenv("API_TOKENS"))
+	if err != nil {
+		return nil, err
+	}
+	config.API.Tokens = tokens
+	if config.API.PublicStatus && config.API.PublicStatusRate <= 0 {
+		return nil, fmt.Errorf("API_PUBLIC_STATUS_RATE_PER_MINUTE must be positive when API_PUBLIC_STATUS is true")
+	}
+
+	if config.FundingArb.Enabled {
+		if config.FundingArb.Quantity <= 0 {
+			return nil, fmt.Errorf("FUNDING_ARB_QUANTITY must be positive when FUNDING_ARB_ENABLED is true")
+		}
+		if config.FundingArb.SpotVenue == config.FundingArb.PerpVenue {
+			return nil, fmt.Errorf("FUNDING_ARB_SPOT_VENUE and FUNDING_ARB_PERP_VENUE must be different venues")
+		}
+	}
+
 	return config, nil
 }
 
+// CredentialsFile restituisce il percorso del file di credenziali cifrato (CREDENTIALS_FILE)
+func CredentialsFile() string {
+	_ = godotenv.Load()
+	return getEnvOrDefault("CREDENTIALS_FILE", keystore.DefaultPath)
+}
+
+// Locale restituisce la lingua configurata (LOCALE), per i sottocomandi che non caricano l'intera configurazione
+func Locale() string {
+	_ = godotenv.Load()
+	return getEnvOrDefault("LOCALE", string(i18n.DefaultLocale))
+}
+
+// IndicatorSources restituisce le sorgenti di prezzo degli indicatori (TA_PRICE_SOURCES),
+// per i sottocomandi che non caricano l'intera configurazione ma calcolano gli indicatori come il worker live
+func IndicatorSources() (taprocess.IndicatorSources, error) {
+	_ = godotenv.Load()
+	return parseIndicatorSources()
+}
+
+// parseIndicatorSources legge TA_PRICE_SOURCES; gli indicatori non indicati usano close
+func parseIndicatorSources() (taprocess.IndicatorSources, error) {
+	values, err := getEnvStringMap("TA_PRICE_SOURCES")
+	if err != nil {
+		return taprocess.IndicatorSources{}, err
+	}
+	sources, err := taprocess.ParseIndicatorSources(values)
+	if err != nil {
+		return taprocess.IndicatorSources{}, fmt.Errorf("invalid TA_PRICE_SOURCES: %w", err)
+	}
+	return sources, nil
+}
+
+// loadEncryptedCredentials decifra il file di credenziali ed esporta i valori come variabili d'ambiente
+// Se il file non esiste la configurazione resta quella di ambiente e .env
+func loadEncryptedCredentials(path string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	passphrase, err := keystore.ReadPassphrase("Credentials passphrase: ")
+	if err != nil {
+		return fmt.Errorf("failed to unlock %s: %w", path, err)
+	}
+	store, err := keystore.Load(path, passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to unlock %s: %w", path, err)
+	}
+	return store.ApplyToEnv()
+}
+
+// parseAPITokens legge i token delle REST API nel formato nome:ruolo:token separati da virgola
+func parseAPITokens(value string) ([]APIToken, error) {
+	var tokens []APIToken
+	names := make(map[string]bool)
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("API_TOKENS entries must be name:role:token")
+		}
+		token := APIToken{Name: parts[0], Role: strings.ToLower(parts[1]), Token: parts[2]}
+		if token.Role != APIRoleViewer && token.Role != APIRoleOperator {
+			return nil, fmt.Errorf("API_TOKENS role for %q must be viewer or operator, got %q", token.Name, parts[1])
+		}
+		if len(token.Token) < 16 {
+			return nil, fmt.Errorf("API_TOKENS token for %q must be at least 16 characters", token.Name)
+		}
+		if names[token.Name] {
+			return nil, fmt.Errorf("API_TOKENS name %q is used more than once", token.Name)
+		}
+		names[token.Name] = true
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// loadBybitCredentials carica le credenziali Bybit dalle variabili con il prefisso indicato
+// (es. BYBIT_API_KEY, BYBIT_READONLY_API_KEY)
+func loadBybitCredentials(prefix string) BybitCredentials {
+	return BybitCredentials{
+		APIKey:            os.Getenv(prefix + "API_KEY"),
+		SecretKey:         os.Getenv(prefix + "SECRET_KEY"),
+		AuthType:          strings.ToLower(getEnvOrDefault(prefix+"AUTH_TYPE", "hmac")),
+		RSAPrivateKeyPath: os.Getenv(prefix + "RSA_PRIVATE_KEY_PATH"),
+	}
+}
+
+// SubAccountPrefix restituisce il prefisso delle variabili delle credenziali del sub-account di una strategia
+// (es. funding-arbitrage -> BYBIT_SUB_FUNDING_ARBITRAGE_)
+func SubAccountPrefix(strategy string) string {
+	return "BYBIT_SUB_" + strings.ToUpper(strings.ReplaceAll(strategy, "-", "_")) + "_"
+}
+
+// loadSubAccountCredentials carica le credenziali dei sub-account configurati, indicizzate per strategia
+func loadSubAccountCredentials() map[string]BybitCredentials {
+	accounts := make(map[string]BybitCredentials)
+	for _, strategy := range SubAccountStrategies {
+		if creds := loadBybitCredentials(SubAccountPrefix(strategy)); creds.HasCredentials() {
+			accounts[strategy] = creds
+		}
+	}
+	return accounts
+}
+
 // getEnvOrDefault restituisce il valore della variabile d'ambiente o un valore di default
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -41,3 +1643,87 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvIntOrDefault restituisce il valore intero della variabile d'ambiente o un valore di default
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvFloatOrDefault restituisce il valore float della variabile d'ambiente o un valore di default
+func getEnvFloatOrDefault(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvBoolOrDefault restituisce il valore booleano della variabile d'ambiente o un valore di default
+func getEnvBoolOrDefault(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvFloatMap interpreta una lista di coppie chiave=valore separate da virgola (es. bybit=200,kraken=100)
+// Le chiavi vengono normalizzate in minuscolo; defaultValue viene usato se la variabile è vuota
+func getEnvFloatMap(key, defaultValue string) (map[string]float64, error) {
+	values := make(map[string]float64)
+	for _, pair := range strings.Split(getEnvOrDefault(key, defaultValue), ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s entry %q, expected name=value", key, pair)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value for %q: %w", key, name, err)
+		}
+		values[strings.ToLower(strings.TrimSpace(name))] = value
+	}
+	return values, nil
+}
+
+// getEnvStringMap interpreta una lista di coppie chiave=valore separate da virgola (es. worker=chandelier)
+// Chiavi e valori vengono normalizzati in minuscolo
+func getEnvStringMap(key string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s entry %q, expected name=value", key, pair)
+		}
+		values[strings.ToLower(strings.TrimSpace(name))] = strings.ToLower(strings.TrimSpace(value))
+	}
+	return values, nil
+}
+
+// isUrgency indica se value è un'urgenza di esecuzione valida
+func isUrgency(value string) bool {
+	return value == "high" || value == "normal" || value == "low"
+}
+
+// getEnvList restituisce i valori separati da virgola della variabile d'ambiente, senza spazi e vuoti
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// strategyEnvPrefixes elenca i prefissi delle variabili d'ambiente dei parametri di strategia e rischio
+var strategyEnvPrefixes = []string{
+	"FUNDING_ARB_", "BALANCE_SYNC_", "PORTFOLIO_REBALANCE_", "ARB_", "BASIS_", "CALENDAR_", "SENTIMENT_", "TREND_", "BREAKOUT_", "VOLUME_PROFILE_", "ORDER_FLOW_", "LIQUIDATIONS_", "AVWAP_", "PYRAMID_", "HEDGE_", "REGIME_", "SCORER_", "RISK_", "DEGRADED_MODE_", "FEATURE_", "TA_",
+}
+
+// secretEnvKeys sono escluse dagli snapshot: le versioni non devono contenere credenziali
+var secretEnvKeys = map[string]bool{
+	"SCORER_TOKEN": true,
+}
+
+// strategyEnvHeader introduce il blocco di variabili scritto da RewriteStrategyEnv
+const strategyEnvHeader = "# Strategy configuration "
+
+// StrategySettings è la parte della configurazione che determina le decisioni di trading
+type StrategySettings struct {
+	FundingArb  FundingArbConfig         `json:"funding_arb"`
+	BalanceSync BalanceSyncConfig        `json:"balance_sync"`
+	Rebalance   PortfolioRebalanceConfig `json:"portfolio_rebalance"`
+	Arbitrage   ArbitrageConfig          `json:"arbitrage"`
+	Basis       BasisTrackerConfig       `json:"basis"`
+	Calendar    CalendarConfig           `json:"calendar"`
+	Sentiment   SentimentFilterConfig    `json:"sentiment"`
+	Trend       TrendFilterConfig        `json:"trend"`
+	Retest      BreakoutRetestConfig     `json:"retest"`
+	Profile     VolumeProfileConfig      `json:"volume_profile"`
+	OrderFlow   OrderFlowConfig          `json:"order_flow"`
+	Liquidation LiquidationConfig        `json:"liquidation"`
+	AVWAP       AnchoredVWAPConfig       `json:"avwap"`
+	Pyramid     PyramidConfig            `json:"pyramid"`
+	Hedge       HedgeConfig              `json:"hedge"`
+	Regime      RegimeConfig             `json:"regime"`
+	Scorer      ScorerConfig             `json:"scorer"`
+	Indicators  IndicatorConfig          `json:"indicators"`
+	Risk        RiskConfig               `json:"risk"`
+	Degraded    DegradedModeConfig       `json:"degraded_mode"`
+	Features    FeatureFlagsConfig       `json:"features"`
+}
+
+// Snapshot è una versione della configurazione di strategia e rischio
+type Snapshot struct {
+	Hash     string            // SHA-256 dei parametri effettivi, default compresi
+	Settings []byte            // Parametri effettivi in JSON
+	Env      map[string]string // Variabili d'ambiente impostate, riscritte nel .env in caso di rollback
+}
+
+// Snapshot calcola la versione corrente della configurazione di strategia e rischio
+// L'hash dipende solo dai parametri effettivi: due avvii con gli stessi valori condividono la versione
+func (c *Config) Snapshot() (*Snapshot, error) {
+	settings := StrategySettings{
+		FundingArb:  c.FundingArb,
+		BalanceSync: c.BalanceSync,
+		Rebalance:   c.Rebalance,
+		Arbitrage:   c.Arbitrage,
+		Basis:       c.Basis,
+		Calendar:    c.Calendar,
+		Sentiment:   c.Sentiment,
+		Trend:       c.Trend,
+		Retest:      c.Retest,
+		Profile:     c.Profile,
+		OrderFlow:   c.OrderFlow,
+		Liquidation: c.Liquidation,
+		AVWAP:       c.AVWAP,
+		Pyramid:     c.Pyramid,
+		Hedge:       c.Hedge,
+		Regime:      c.Regime,
+		Scorer:      c.Scorer,
+		Indicators:  c.Indicators,
+		Risk:        c.Risk,
+		Degraded:    c.Degraded,
+		Features:    c.Features,
+	}
+	settings.Scorer.Token = ""
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode strategy settings: %w", err)
+	}
+	sum := sha256.Sum256(data)
+
+	return &Snapshot{
+		Hash:     hex.EncodeToString(sum[:]),
+		Settings: data,
+		Env:      strategyEnv(),
+	}, nil
+}
+
+// IsStrategyEnvKey verifica se una variabile d'ambiente fa parte della configurazione versionata
+func IsStrategyEnvKey(key string) bool {
+	if secretEnvKeys[key] {
+		return false
+	}
+	for _, prefix := range strategyEnvPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// strategyEnv restituisce le variabili d'ambiente di strategia e rischio attualmente impostate
+func strategyEnv() map[string]string {
+	env := make(map[string]string)
+	for _, entry := range os.Environ() {
+		key, value, ok := strings.Cut(entry, "=")
+		if ok && value != "" && IsStrategyEnvKey(key) {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// RewriteStrategyEnv riscrive le variabili di strategia e rischio del file .env con i valori di env
+// Le altre righe (credenziali, commenti, variabili di sistema) restano invariate; le variabili di strategia
+// assenti da env vengono rimosse, così tornano al valore di default; label identifica la versione nel commento del blocco
+func RewriteStrategyEnv(path string, env map[string]string, label string) error {
+	var kept []string
+	file, err := os.Open(path)
+	switch {
+	case err == nil:
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !IsStrategyEnvKey(envLineKey(line)) && !strings.HasPrefix(line, strategyEnvHeader) {
+				kept = append(kept, line)
+			}
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	for len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+		kept = kept[:len(kept)-1]
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		if IsStrategyEnvKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, line := range kept {
+		b.WriteString(line + "\n")
+	}
+	if len(keys) > 0 {
+		if len(kept) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(strategyEnvHeader + label + "\n")
+		for _, key := range keys {
+			b.WriteString(key + "=" + quoteEnvValue(env[key]) + "\n")
+		}
+	}
+
+	// Scrittura su file temporaneo e rename, per non lasciare un .env troncato in caso di errore
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// envLineKey restituisce il nome della variabile di una riga .env (vuoto per commenti e righe vuote)
+func envLineKey(line string) string {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ""
+	}
+	line = strings.TrimPrefix(line, "export ")
+	key, _, ok := strings.Cut(line, "=")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(key)
+}
+
+// quoteEnvValue racchiude tra virgolette i valori con spazi o caratteri speciali per godotenv
+func quoteEnvValue(value string) string {
+	if strings.ContainsAny(value, " \t#\"'\\\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,124 @@
+package correlation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// BybitHeader è l'header con cui l'identificativo di correlazione accompagna le chiamate alle API Bybit
+// Non fa parte della firma della richiesta, quindi può essere aggiunto a qualunque chiamata
+const BybitHeader = "X-Referer"
+
+// maxIDLength è la lunghezza massima di un identificativo ricevuto dall'esterno (header X-Request-ID)
+const maxIDLength = 64
+
+type cycleKey struct{}
+
+type requestKey struct{}
+
+// NewID genera un identificativo breve, leggibile nei log (12 caratteri esadecimali)
+func NewID() string {
+	var b [6]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("failed to generate correlation id: %v", err))
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// Sanitize accetta un identificativo ricevuto dall'esterno se corto e composto da caratteri stampabili;
+// altrimenti restituisce una stringa vuota e il chiamante ne genera uno nuovo
+func Sanitize(id string) string {
+	if id == "" || len(id) > maxIDLength {
+		return ""
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return ""
+		}
+	}
+	return id
+}
+
+// WithCycleID restituisce un contesto associato al ciclo di un worker
+func WithCycleID(ctx context.Context, cycleID string) context.Context {
+	return context.WithValue(ctx, cycleKey{}, cycleID)
+}
+
+// CycleID restituisce l'identificativo del ciclo del contesto, o una stringa vuota
+func CycleID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(cycleKey{}).(string)
+	return id
+}
+
+// WithRequestID restituisce un contesto associato a una richiesta REST
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestKey{}, requestID)
+}
+
+// RequestID restituisce l'identificativo della richiesta REST del contesto, o una stringa vuota
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestKey{}).(string)
+	return id
+}
+
+// ID restituisce l'identificativo con cui tracciare le operazioni del contesto: il ciclo del worker
+// se presente, altrimenti la richiesta REST
+func ID(ctx context.Context) string {
+	if id := CycleID(ctx); id != "" {
+		return id
+	}
+	return RequestID(ctx)
+}
+
+// Prefix restituisce il prefisso dei log per il contesto (es. "[ciclo 1a2b3c4d5e6f] "), vuoto senza identificativo
+func Prefix(ctx context.Context) string {
+	if id := CycleID(ctx); id != "" {
+		return "[ciclo " + id + "] "
+	}
+	if id := RequestID(ctx); id != "" {
+		return "[richiesta " + id + "] "
+	}
+	return ""
+}
+
+// Logf scrive un log preceduto dall'identificativo di correlazione del contesto
+func Logf(ctx context.Context, format string, args ...interface{}) {
+	log.Printf(Prefix(ctx)+format, args...)
+}
+
+// Transport aggiunge alle richieste HTTP l'header con l'identificativo di correlazione del loro contesto
+type Transport struct {
+	Header string            // Header da valorizzare (es. BybitHeader)
+	Base   http.RoundTripper // Transport sottostante; nil usa http.DefaultTransport
+}
+
+// NewClient crea un client HTTP che propaga l'identificativo di correlazione nell'header indicato
+func NewClient(header string, client *http.Client) *http.Client {
+	client.Transport = &Transport{Header: header, Base: client.Transport}
+	return client
+}
+
+// RoundTrip esegue la richiesta, clonandola se deve aggiungere l'header
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	id := ID(req.Context())
+	if id == "" || req.Header.Get(t.Header) != "" {
+		return base.RoundTrip(req)
+	}
+	clone := req.Clone(req.Context())
+	clone.Header.Set(t.Header, id)
+	return base.RoundTrip(clone)
+}
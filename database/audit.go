@@ -0,0 +1,246 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"cross-exchange-arbitrage/correlation"
+	"cross-exchange-arbitrage/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// defaultChangedBy è l'autore usato quando il contesto non ne specifica uno
+	defaultChangedBy = "system"
+
+	// auditOldOrdersKey è la chiave con cui il callback before_update passa gli ordini originali
+	auditOldOrdersKey = "order_audit:old_orders"
+)
+
+// changedByKey è la chiave di contesto per l'autore delle modifiche
+type changedByKey struct{}
+
+// WithChangedBy restituisce un contesto che attribuisce le modifiche agli ordini a changedBy
+func WithChangedBy(ctx context.Context, changedBy string) context.Context {
+	return context.WithValue(ctx, changedByKey{}, changedBy)
+}
+
+// ChangedByFromContext restituisce l'autore delle modifiche salvato nel contesto, o "system"
+func ChangedByFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if changedBy, ok := ctx.Value(changedByKey{}).(string); ok && changedBy != "" {
+			return changedBy
+		}
+	}
+	return defaultChangedBy
+}
+
+// auditedField descrive un campo dell'ordine tracciato nell'audit trail
+type auditedField struct {
+	name  string
+	value func(order *models.Order) *string
+}
+
+// auditedOrderFields elenca i campi dell'ordine le cui modifiche generano un record di audit
+// I formati devono restare compatibili con parseAuditValue
+var auditedOrderFields = []auditedField{
+	{"order_price", func(o *models.Order) *string { return formatFloat(&o.OrderPrice) }},
+	{"quantity", func(o *models.Order) *string { return formatFloat(&o.Quantity) }},
+	{"take_profit_price", func(o *models.Order) *string { return formatFloat(o.TakeProfitPrice) }},
+	{"stop_loss_price", func(o *models.Order) *string { return formatFloat(o.StopLossPrice) }},
+	{"order_status_id", func(o *models.Order) *string { v := fmt.Sprintf("%d", o.OrderStatusID); return &v }},
+	{"result", func(o *models.Order) *string { v := string(o.Result); return &v }},
+	{"pnl", func(o *models.Order) *string { return formatFloat(&o.PnL) }},
+	{"pnl_percentage", func(o *models.Order) *string { return formatFloat(&o.PnLPercentage) }},
+}
+
+// formatFloat formatta un valore opzionale per l'audit trail
+func formatFloat(value *float64) *string {
+	if value == nil {
+		return nil
+	}
+	v := fmt.Sprintf("%.8f", *value)
+	return &v
+}
+
+// OrderAuditPlugin scrive automaticamente i record di OrderAudit per ogni creazione o
+// modifica di un ordine, nella stessa transazione dell'operazione
+type OrderAuditPlugin struct{}
+
+// Name restituisce il nome del plugin
+func (OrderAuditPlugin) Name() string {
+	return "order_audit"
+}
+
+// Initialize registra i callback di creazione e aggiornamento
+func (p OrderAuditPlugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").
+		Register("order_audit:after_create", p.afterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").
+		Register("order_audit:before_update", p.beforeUpdate); err != nil {
+		return err
+	}
+	return db.Callback().Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").
+		Register("order_audit:after_update", p.afterUpdate)
+}
+
+// isOrderStatement verifica se lo statement opera sulla tabella degli ordini
+func isOrderStatement(db *gorm.DB) bool {
+	return db.Error == nil && db.Statement.Schema != nil && db.Statement.Schema.Table == (models.Order{}).TableName()
+}
+
+// afterCreate registra un record "created" per ogni ordine inserito
+func (OrderAuditPlugin) afterCreate(db *gorm.DB) {
+	if !isOrderStatement(db) || db.Statement.RowsAffected == 0 {
+		return
+	}
+
+	var audits []*models.OrderAudit
+	changedBy := ChangedByFromContext(db.Statement.Context)
+	correlationID := correlation.ID(db.Statement.Context)
+	for _, order := range collectOrders(db.Statement.ReflectValue) {
+		audits = append(audits, &models.OrderAudit{
+			OrderID:       order.OrderID,
+			FieldName:     "created",
+			NewValue:      func() *string { v := "Order created"; return &v }(),
+			ChangedBy:     changedBy,
+			CorrelationID: correlationID,
+		})
+	}
+
+	writeAudits(db, audits)
+}
+
+// beforeUpdate legge lo stato corrente degli ordini che verranno modificati
+func (OrderAuditPlugin) beforeUpdate(db *gorm.DB) {
+	if !isOrderStatement(db) {
+		return
+	}
+
+	query := db.Session(&gorm.Session{NewDB: true}).Model(&models.Order{})
+	scoped := false
+	if where, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+		query = query.Clauses(where)
+		scoped = true
+	}
+	if order, ok := db.Statement.Model.(*models.Order); ok && order != nil && order.ID != 0 {
+		query = query.Where("id = ?", order.ID)
+		scoped = true
+	}
+	if !scoped {
+		// Aggiornamenti senza condizioni vengono comunque bloccati da GORM
+		return
+	}
+
+	var oldOrders []*models.Order
+	if err := query.Find(&oldOrders).Error; err != nil {
+		db.AddError(fmt.Errorf("failed to load orders for audit: %w", err))
+		return
+	}
+	db.InstanceSet(auditOldOrdersKey, oldOrders)
+}
+
+// afterUpdate confronta gli ordini aggiornati con lo stato precedente e registra le differenze
+func (OrderAuditPlugin) afterUpdate(db *gorm.DB) {
+	if !isOrderStatement(db) || db.Statement.RowsAffected == 0 {
+		return
+	}
+
+	value, ok := db.InstanceGet(auditOldOrdersKey)
+	if !ok {
+		return
+	}
+	oldOrders, _ := value.([]*models.Order)
+	if len(oldOrders) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(oldOrders))
+	for _, order := range oldOrders {
+		ids = append(ids, order.ID)
+	}
+
+	var newOrders []*models.Order
+	if err := db.Session(&gorm.Session{NewDB: true}).Where("id IN ?", ids).Find(&newOrders).Error; err != nil {
+		db.AddError(fmt.Errorf("failed to reload orders for audit: %w", err))
+		return
+	}
+	updated := make(map[uint]*models.Order, len(newOrders))
+	for _, order := range newOrders {
+		updated[order.ID] = order
+	}
+
+	var audits []*models.OrderAudit
+	changedBy := ChangedByFromContext(db.Statement.Context)
+	correlationID := correlation.ID(db.Statement.Context)
+	for _, oldOrder := range oldOrders {
+		newOrder, ok := updated[oldOrder.ID]
+		if !ok {
+			continue
+		}
+		audits = append(audits, diffOrder(oldOrder, newOrder, changedBy, correlationID)...)
+	}
+
+	writeAudits(db, audits)
+}
+
+// diffOrder crea un record di audit per ogni campo tracciato che è cambiato
+func diffOrder(oldOrder, newOrder *models.Order, changedBy, correlationID string) []*models.OrderAudit {
+	var audits []*models.OrderAudit
+	for _, field := range auditedOrderFields {
+		audit := &models.OrderAudit{
+			OrderID:       newOrder.OrderID,
+			FieldName:     field.name,
+			OldValue:      field.value(oldOrder),
+			NewValue:      field.value(newOrder),
+			ChangedBy:     changedBy,
+			CorrelationID: correlationID,
+		}
+		if audit.IsSignificantChange() {
+			audits = append(audits, audit)
+		}
+	}
+	return audits
+}
+
+// writeAudits salva i record di audit usando la stessa connessione (e transazione) dello statement
+func writeAudits(db *gorm.DB, audits []*models.OrderAudit) {
+	if len(audits) == 0 {
+		return
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&audits).Error; err != nil {
+		db.AddError(fmt.Errorf("failed to create audit records: %w", err))
+	}
+}
+
+// collectOrders estrae gli ordini da un valore singolo o da una slice
+func collectOrders(value reflect.Value) []*models.Order {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if !value.CanAddr() {
+			return nil
+		}
+		if order, ok := value.Addr().Interface().(*models.Order); ok {
+			return []*models.Order{order}
+		}
+	case reflect.Slice, reflect.Array:
+		orders := make([]*models.Order, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			orders = append(orders, collectOrders(value.Index(i))...)
+		}
+		return orders
+	}
+	return nil
+}
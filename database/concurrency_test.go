@@ -0,0 +1,166 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"cross-exchange-arbitrage/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Carico usato dal test e dal benchmark: 1 scrittore che inserisce ordini e 4 lettori che li contano
+const (
+	workloadOrders  = 300
+	workloadReaders = 4
+	workloadQueries = 100
+)
+
+// openTestDB apre un database su file temporaneo con tabelle e stati degli ordini
+func openTestDB(tb testing.TB, journalMode string, maxOpenConns int) *gorm.DB {
+	tb.Helper()
+
+	db, err := Connect(&Config{
+		FilePath:      filepath.Join(tb.TempDir(), "trading_bot.db"),
+		JournalMode:   journalMode,
+		BusyTimeoutMs: 5000,
+		MaxOpenConns:  maxOpenConns,
+	})
+	if err != nil {
+		tb.Fatalf("connect: %v", err)
+	}
+	db.Logger = logger.Discard
+	tb.Cleanup(func() { _ = Close(db) })
+
+	if err := Migrate(db); err != nil {
+		tb.Fatalf("migrate: %v", err)
+	}
+	if err := InitializeOrderStatuses(db); err != nil {
+		tb.Fatalf("order statuses: %v", err)
+	}
+	return db
+}
+
+// runWorkload esegue in parallelo lo scrittore e i lettori; prefix rende univoci gli OrderID di ogni esecuzione
+func runWorkload(db *gorm.DB, statusID uint, prefix string) []error {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		errors []error
+	)
+	record := func(err error) {
+		mu.Lock()
+		errors = append(errors, err)
+		mu.Unlock()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < workloadOrders; i++ {
+			order := &models.Order{
+				OrderID:       fmt.Sprintf("%s-%d", prefix, i),
+				Symbol:        "DOGEUSDT",
+				Side:          models.OrderSideTypeBuy,
+				OrderPrice:    0.1,
+				Quantity:      100,
+				OrderStatusID: statusID,
+			}
+			if err := db.Create(order).Error; err != nil {
+				record(fmt.Errorf("insert %s: %w", order.OrderID, err))
+			}
+		}
+	}()
+
+	for r := 0; r < workloadReaders; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for q := 0; q < workloadQueries; q++ {
+				var count int64
+				if err := db.Model(&models.Order{}).Where("symbol = ?", "DOGEUSDT").Count(&count).Error; err != nil {
+					record(fmt.Errorf("count: %w", err))
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	return errors
+}
+
+// newStatusID restituisce l'ID dello stato New
+func newStatusID(tb testing.TB, db *gorm.DB) uint {
+	tb.Helper()
+
+	var status models.OrderStatusEntity
+	if err := db.Where("status_name = ?", "New").First(&status).Error; err != nil {
+		tb.Fatalf("status New: %v", err)
+	}
+	return status.ID
+}
+
+func TestConcurrentReadersAndWriter(t *testing.T) {
+	tests := []struct {
+		name         string
+		journalMode  string
+		maxOpenConns int
+	}{
+		{"wal_pool", "WAL", 4},
+		{"wal_single_connection", "WAL", 1},
+		{"rollback_journal_pool", "DELETE", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t, tt.journalMode, tt.maxOpenConns)
+
+			for _, err := range runWorkload(db, newStatusID(t, db), tt.name) {
+				if strings.Contains(err.Error(), "database is locked") {
+					t.Errorf("busy error: %v", err)
+				} else {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+
+			var count int64
+			if err := db.Model(&models.Order{}).Count(&count).Error; err != nil {
+				t.Fatalf("count: %v", err)
+			}
+			if count != workloadOrders {
+				t.Errorf("orders = %d, want %d", count, workloadOrders)
+			}
+		})
+	}
+}
+
+// BenchmarkConcurrentWorkload misura il carico di TestConcurrentReadersAndWriter in WAL con il pool
+// e con la configurazione precedente (rollback journal, una sola connessione)
+func BenchmarkConcurrentWorkload(b *testing.B) {
+	benchmarks := []struct {
+		name         string
+		journalMode  string
+		maxOpenConns int
+	}{
+		{"wal_4_conns", "WAL", 4},
+		{"delete_1_conn", "DELETE", 1},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			db := openTestDB(b, bm.journalMode, bm.maxOpenConns)
+			statusID := newStatusID(b, db)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if errs := runWorkload(db, statusID, fmt.Sprintf("bench-%d", i)); len(errs) > 0 {
+					b.Fatalf("%d errors, first: %v", len(errs), errs[0])
+				}
+			}
+		})
+	}
+}
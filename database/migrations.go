@@ -5,7 +5,10 @@ import (
 	"cross-exchange-arbitrage/models"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/driver/sqlite"
@@ -15,13 +18,19 @@ import (
 
 // Config rappresenta la configurazione del database
 type Config struct {
-	FilePath string // Percorso del file SQLite
+	FilePath      string // Percorso del file SQLite
+	JournalMode   string // Modalità journal SQLite (WAL consente letture concorrenti alle scritture)
+	BusyTimeoutMs int    // Attesa massima in millisecondi quando il database è bloccato
+	MaxOpenConns  int    // Numero massimo di connessioni (1 scrittore + lettori in WAL)
 }
 
 // DefaultConfig restituisce una configurazione di default
 func DefaultConfig() *Config {
 	return &Config{
-		FilePath: getEnv("DB_FILE_PATH", "./trading_bot.db"),
+		FilePath:      getEnv("DB_FILE_PATH", "./trading_bot.db"),
+		JournalMode:   getEnv("DB_JOURNAL_MODE", "WAL"),
+		BusyTimeoutMs: getEnvInt("DB_BUSY_TIMEOUT_MS", 5000),
+		MaxOpenConns:  getEnvInt("DB_MAX_OPEN_CONNS", 4),
 	}
 }
 
@@ -33,6 +42,50 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt restituisce il valore intero di una variabile d'ambiente o un valore di default
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// dsn costruisce la stringa di connessione con i pragma del driver SQLite
+// _txlock=immediate acquisisce subito il lock di scrittura ed evita deadlock tra transazioni concorrenti
+func (c *Config) dsn() string {
+	params := url.Values{}
+	if c.JournalMode != "" {
+		params.Set("_journal_mode", c.JournalMode)
+	}
+	if c.BusyTimeoutMs > 0 {
+		params.Set("_busy_timeout", strconv.Itoa(c.BusyTimeoutMs))
+	}
+	if c.MaxOpenConns > 1 {
+		params.Set("_txlock", "immediate")
+	}
+
+	if len(params) == 0 {
+		return c.FilePath
+	}
+
+	separator := "?"
+	if strings.Contains(c.FilePath, "?") {
+		separator = "&"
+	}
+	return c.FilePath + separator + params.Encode()
+}
+
+// maxOpenConns restituisce la dimensione del pool
+// Un database in memoria esiste solo all'interno della sua connessione, quindi resta a 1
+func (c *Config) maxOpenConns() int {
+	if c.MaxOpenConns < 1 || strings.Contains(c.FilePath, ":memory:") {
+		return 1
+	}
+	return c.MaxOpenConns
+}
+
 // Connect stabilisce una connessione al database SQLite
 func Connect(config *Config) (*gorm.DB, error) {
 	// Configurazione logger GORM
@@ -43,11 +96,16 @@ func Connect(config *Config) (*gorm.DB, error) {
 		},
 	}
 
-	db, err := gorm.Open(sqlite.Open(config.FilePath), gormConfig)
+	db, err := gorm.Open(sqlite.Open(config.dsn()), gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Audit automatico delle modifiche agli ordini
+	if err := db.Use(OrderAuditPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register order audit plugin: %w", err)
+	}
+
 	// Configurazione connection pool per SQLite
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -55,8 +113,11 @@ func Connect(config *Config) (*gorm.DB, error) {
 	}
 
 	// Configurazione connection pool ottimizzata per SQLite
-	sqlDB.SetMaxIdleConns(1)    // SQLite funziona meglio con poche connessioni
-	sqlDB.SetMaxOpenConns(1)    // Una sola connessione per SQLite
+	// In WAL le letture (es. statistiche) non bloccano il worker che scrive;
+	// le scritture restano serializzate da SQLite e attendono fino al busy timeout
+	maxConns := config.maxOpenConns()
+	sqlDB.SetMaxIdleConns(maxConns) // Mantieni le connessioni aperte: i pragma valgono per connessione
+	sqlDB.SetMaxOpenConns(maxConns)
 	sqlDB.SetConnMaxLifetime(0) // Nessun timeout per SQLite
 
 	return db, nil
@@ -64,11 +125,38 @@ func Connect(config *Config) (*gorm.DB, error) {
 
 // Migrate esegue le migrazioni per creare le tabelle
 func Migrate(db *gorm.DB) error {
+	// Allinea i nomi delle colonne dei database creati con le versioni precedenti
+	if err := renameLegacyColumns(db); err != nil {
+		return fmt.Errorf("failed to rename legacy columns: %w", err)
+	}
+
 	// Auto-migrazione per creare le tabelle (ordine importante per foreign key)
 	err := db.AutoMigrate(
 		&models.OrderStatusEntity{},
 		&models.Order{},
 		&models.OrderAudit{},
+		&models.BalanceSnapshot{},
+		&models.OrderTag{},
+		&models.Execution{},
+		&models.ArchivedOrder{},
+		&models.FundingArbPosition{},
+		&models.BasisSnapshot{},
+		&models.DataPoint{},
+		&models.CandleRecord{},
+		&models.ConfigVersion{},
+		&models.ScheduledJob{},
+		&models.DistributedLock{},
+		&models.WorkerBudget{},
+		&models.CashFlow{},
+		&models.SlicedOrder{},
+		&models.OrderSlice{},
+		&models.AccountSettings{},
+		&models.FeeRate{},
+		&models.StrategySubAccount{},
+		&models.RebalanceTransfer{},
+		&models.OperatorAudit{},
+		&models.SignalDecision{},
+		&models.PositionPnL{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
@@ -87,6 +175,30 @@ func Migrate(db *gorm.DB) error {
 	return nil
 }
 
+// renameLegacyColumns rinomina le colonne generate dalla naming strategy di GORM
+// prima che i nomi fossero esplicitati nel modello (PnL -> pn_l invece di pnl)
+func renameLegacyColumns(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.Order{}) {
+		return nil
+	}
+
+	renames := map[string]string{
+		"pn_l":            "pnl",
+		"pn_l_percentage": "pnl_percentage",
+	}
+	for oldName, newName := range renames {
+		if migrator.HasColumn(&models.Order{}, oldName) && !migrator.HasColumn(&models.Order{}, newName) {
+			if err := migrator.RenameColumn(&models.Order{}, oldName, newName); err != nil {
+				return err
+			}
+			log.Printf("Renamed column orders.%s to %s", oldName, newName)
+		}
+	}
+
+	return nil
+}
+
 // createIndexes crea indici aggiuntivi per performance
 func createIndexes(db *gorm.DB) error {
 	// Indici compositi per query frequenti
@@ -95,6 +207,12 @@ func createIndexes(db *gorm.DB) error {
 		"CREATE INDEX IF NOT EXISTS idx_symbol_result ON orders (symbol, result);",
 		"CREATE INDEX IF NOT EXISTS idx_created_status ON orders (created_at, order_status_id);",
 		"CREATE INDEX IF NOT EXISTS idx_orders_compound ON orders (symbol, side, result, created_at);",
+		"CREATE INDEX IF NOT EXISTS idx_symbol_created_id ON orders (symbol, created_at, id);",
+		"CREATE INDEX IF NOT EXISTS idx_symbol_pnl ON orders (symbol, pnl);",
+		"CREATE INDEX IF NOT EXISTS idx_status_created ON orders (order_status_id, created_at);",
+		"CREATE INDEX IF NOT EXISTS idx_tag_order_tag ON order_tags (order_id, tag);",
+		"CREATE INDEX IF NOT EXISTS idx_audit_order_changed ON order_audit (order_id, changed_at);",
+		"CREATE INDEX IF NOT EXISTS idx_audit_order_field_changed ON order_audit (order_id, field_name, changed_at);",
 	}
 
 	for _, indexSQL := range indexes {
@@ -153,12 +271,13 @@ func createConstraints(db *gorm.DB) error {
 		   END;
 		 END;`,
 
-		// Vincolo per result valido
+		// Vincolo per result valido; ricreato perché i database esistenti non ammettono Rejected
+		`DROP TRIGGER IF EXISTS chk_valid_result`,
 		`CREATE TRIGGER IF NOT EXISTS chk_valid_result 
 		 BEFORE INSERT ON orders
 		 BEGIN
 		   SELECT CASE
-		     WHEN NEW.result NOT IN ('Profit', 'Loss', 'Pending')
+		     WHEN NEW.result NOT IN ('Profit', 'Loss', 'Pending', 'Rejected')
 		     THEN RAISE(ABORT, 'Invalid order result')
 		   END;
 		 END;`,
@@ -224,3 +343,15 @@ func HealthCheck(db *gorm.DB) error {
 
 	return sqlDB.PingContext(ctx)
 }
+
+// Optimize compatta il database e aggiorna le statistiche del query planner
+// Da eseguire dopo cancellazioni massive: VACUUM recupera lo spazio, ANALYZE aggiorna gli indici
+func Optimize(db *gorm.DB) error {
+	if err := db.Exec("VACUUM;").Error; err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	if err := db.Exec("ANALYZE;").Error; err != nil {
+		return fmt.Errorf("failed to analyze database: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// SnapshotTo scrive in path una copia consistente del database senza fermare le scritture
+// VACUUM INTO produce un file compatto e autonomo, senza WAL da copiare a parte
+func SnapshotTo(db *gorm.DB, path string) error {
+	if err := db.Exec("VACUUM INTO ?", path).Error; err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	return nil
+}
+
+// VerifyFile controlla l'integrità di un file di database SQLite e restituisce il numero di righe per tabella
+// Il file viene aperto senza migrazioni né plugin, così la verifica non lo modifica
+func VerifyFile(path string) (map[string]int64, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	defer Close(db)
+
+	var results []string
+	if err := db.Raw("PRAGMA integrity_check").Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("failed to check database integrity: %w", err)
+	}
+	if len(results) != 1 || results[0] != "ok" {
+		return nil, fmt.Errorf("database integrity check failed: %v", results)
+	}
+
+	var tables []string
+	if err := db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name").
+		Scan(&tables).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	counts := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		var count int64
+		if err := db.Table(table).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to count rows of %s: %w", table, err)
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
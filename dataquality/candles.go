@@ -0,0 +1,269 @@
+package dataquality
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/errorreport"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/models"
+)
+
+// Thresholds sono le soglie oltre cui le candele di un simbolo sono considerate di qualità degradata
+// Le metriche sono calcolate sugli ultimi Window recuperi, così un problema passato non resta segnalato per sempre
+type Thresholds struct {
+	Window        int     // Recuperi recenti su cui calcolare le metriche
+	MaxGaps       int     // Candele mancanti ammesse nella finestra
+	MaxZeroVolume float64 // Quota massima (0-1) di candele chiuse con volume zero nella finestra
+	MaxErrorRate  float64 // Quota massima (0-1) di recuperi falliti, valutata a finestra piena
+}
+
+// Validate verifica la coerenza delle soglie
+func (t Thresholds) Validate() error {
+	if t.Window < 1 {
+		return fmt.Errorf("candle quality window must be at least 1")
+	}
+	if t.MaxGaps < 0 {
+		return fmt.Errorf("candle quality max gaps must not be negative")
+	}
+	if t.MaxZeroVolume < 0 || t.MaxZeroVolume > 1 || t.MaxErrorRate < 0 || t.MaxErrorRate > 1 {
+		return fmt.Errorf("candle quality rates must be between 0 and 1")
+	}
+	return nil
+}
+
+// Analysis descrive i difetti di un elenco di candele
+type Analysis struct {
+	Candles    int // Candele ricevute
+	Gaps       int // Candele mancanti tra la più vecchia e la più recente
+	ZeroVolume int // Candele chiuse con volume zero
+	OutOfOrder int // Candele fuori dall'ordine cronologico dell'elenco o duplicate
+}
+
+// Analyze conta buchi, candele a volume zero e timestamp fuori ordine di candles
+// Le candele possono essere in ordine cronologico o inverso; la più recente è ancora aperta
+// e non viene contata tra quelle a volume zero. I buchi non sono calcolati per il timeframe mensile
+func Analyze(candles []models.Candle, timeframe models.Timeframe) Analysis {
+	analysis := Analysis{Candles: len(candles)}
+	if len(candles) == 0 {
+		return analysis
+	}
+
+	// Il verso dell'elenco è quello tra la prima e l'ultima candela
+	descending := candles[0].Timestamp.After(candles[len(candles)-1].Timestamp)
+	timestamps := make([]time.Time, 0, len(candles))
+	latest := 0
+	for i, candle := range candles {
+		if i > 0 {
+			prev := candles[i-1].Timestamp
+			if (descending && !candle.Timestamp.Before(prev)) || (!descending && !candle.Timestamp.After(prev)) {
+				analysis.OutOfOrder++
+			}
+		}
+		if candle.Timestamp.After(candles[latest].Timestamp) {
+			latest = i
+		}
+		timestamps = append(timestamps, candle.Timestamp)
+	}
+	for i, candle := range candles {
+		if i != latest && candle.Volume == 0 {
+			analysis.ZeroVolume++
+		}
+	}
+
+	step := timeframe.Duration()
+	if step <= 0 {
+		return analysis
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i].Before(timestamps[j]) })
+	for i := 1; i < len(timestamps); i++ {
+		if elapsed := timestamps[i].Sub(timestamps[i-1]); elapsed > step {
+			analysis.Gaps += int(elapsed/step) - 1
+		}
+	}
+	return analysis
+}
+
+// CandleStats sono le metriche di qualità delle candele di un simbolo sugli ultimi recuperi
+type CandleStats struct {
+	Symbol     string           `json:"symbol"`
+	Timeframe  models.Timeframe `json:"timeframe"`
+	Fetches    int              `json:"fetches"`
+	Errors     int              `json:"errors"`
+	ErrorRate  float64          `json:"error_rate"`
+	Candles    int              `json:"candles"`
+	Gaps       int              `json:"gaps"`
+	ZeroVolume int              `json:"zero_volume"`
+	OutOfOrder int              `json:"out_of_order"`
+	Degraded   bool             `json:"degraded"`
+	Reasons    []string         `json:"reasons,omitempty"` // Soglie superate
+	LastFetch  time.Time        `json:"last_fetch"`
+	LastError  string           `json:"last_error,omitempty"`
+	Since      *time.Time       `json:"since,omitempty"` // Inizio della qualità degradata
+}
+
+// seriesKey identifica le candele di un simbolo in un timeframe
+type seriesKey struct {
+	symbol    string
+	timeframe models.Timeframe
+}
+
+// fetchResult è l'esito di un recupero di candele
+type fetchResult struct {
+	analysis Analysis
+	failed   bool
+}
+
+// series conserva gli ultimi recuperi di un simbolo e lo stato della qualità
+type series struct {
+	results   []fetchResult // Ultimi recuperi, dal più vecchio al più recente
+	lastFetch time.Time
+	lastError string
+	degraded  bool
+	since     time.Time
+}
+
+// CandleMonitor misura la qualità delle candele recuperate dagli exchange per ogni simbolo e timeframe
+// e segnala quando peggiora: le strategie usano le candele senza controllarle e con dati errati
+// prendono decisioni errate senza alcun errore visibile
+// Un CandleMonitor nil ignora i recuperi
+type CandleMonitor struct {
+	mu         sync.Mutex
+	thresholds Thresholds
+	series     map[seriesKey]*series
+	reporter   *errorreport.Reporter // Invio a Sentry degli alert; nil se disabilitato
+}
+
+// NewCandleMonitor crea il monitor validando le soglie
+func NewCandleMonitor(thresholds Thresholds, reporter *errorreport.Reporter) (*CandleMonitor, error) {
+	if err := thresholds.Validate(); err != nil {
+		return nil, err
+	}
+	return &CandleMonitor{thresholds: thresholds, series: make(map[seriesKey]*series), reporter: reporter}, nil
+}
+
+// ObserveCandles registra l'esito di un recupero di candele; err nil indica una risposta valida
+// Un recupero annullato dal chiamante non è un errore dell'API e non viene contato
+func (m *CandleMonitor) ObserveCandles(symbol string, timeframe models.Timeframe, candles []models.Candle, err error) {
+	if m == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	result := fetchResult{failed: err != nil}
+	if err == nil {
+		result.analysis = Analyze(candles, timeframe)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := seriesKey{symbol: strings.ToUpper(symbol), timeframe: timeframe}
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{}
+		m.series[key] = s
+	}
+	s.results = append(s.results, result)
+	if len(s.results) > m.thresholds.Window {
+		s.results = s.results[len(s.results)-m.thresholds.Window:]
+	}
+	s.lastFetch = time.Now()
+	if err != nil {
+		s.lastError = err.Error()
+	}
+
+	stats := m.statsLocked(key, s)
+	switch {
+	case stats.Degraded && !s.degraded:
+		s.degraded = true
+		s.since = s.lastFetch
+		m.alertDegraded(stats)
+	case !stats.Degraded && s.degraded:
+		s.degraded = false
+		log.Println(i18n.T("alert.candle_quality_back", key.symbol, key.timeframe, time.Since(s.since).Round(time.Second)))
+	}
+}
+
+// alertDegraded segnala il peggioramento della qualità delle candele di un simbolo
+func (m *CandleMonitor) alertDegraded(stats CandleStats) {
+	message := i18n.T("alert.candle_quality", stats.Symbol, stats.Timeframe, strings.Join(stats.Reasons, ", "), stats.Fetches)
+	log.Println(message)
+	m.reporter.CaptureError(errors.New(message), errorreport.Context{
+		Component:   "candles",
+		Symbol:      stats.Symbol,
+		Fingerprint: []string{"candle-quality", stats.Symbol, string(stats.Timeframe)},
+	})
+}
+
+// statsLocked calcola le metriche della serie sugli ultimi recuperi e le confronta con le soglie
+func (m *CandleMonitor) statsLocked(key seriesKey, s *series) CandleStats {
+	stats := CandleStats{
+		Symbol:    key.symbol,
+		Timeframe: key.timeframe,
+		Fetches:   len(s.results),
+		LastFetch: s.lastFetch,
+		LastError: s.lastError,
+	}
+	if s.degraded {
+		since := s.since
+		stats.Since = &since
+	}
+	for _, result := range s.results {
+		if result.failed {
+			stats.Errors++
+			continue
+		}
+		stats.Candles += result.analysis.Candles
+		stats.Gaps += result.analysis.Gaps
+		stats.ZeroVolume += result.analysis.ZeroVolume
+		stats.OutOfOrder += result.analysis.OutOfOrder
+	}
+	if stats.Fetches > 0 {
+		stats.ErrorRate = float64(stats.Errors) / float64(stats.Fetches)
+	}
+
+	t := m.thresholds
+	if stats.Gaps > t.MaxGaps {
+		stats.Reasons = append(stats.Reasons, fmt.Sprintf("gaps %d > %d", stats.Gaps, t.MaxGaps))
+	}
+	if stats.Candles > 0 {
+		if share := float64(stats.ZeroVolume) / float64(stats.Candles); share > t.MaxZeroVolume {
+			stats.Reasons = append(stats.Reasons, fmt.Sprintf("zero volume %.1f%% > %.1f%%", share*100, t.MaxZeroVolume*100))
+		}
+	}
+	if stats.OutOfOrder > 0 {
+		stats.Reasons = append(stats.Reasons, fmt.Sprintf("out of order %d", stats.OutOfOrder))
+	}
+	// Un singolo errore all'avvio non deve far scattare l'alert: il tasso è valutato a finestra piena
+	if stats.Fetches >= t.Window && stats.ErrorRate > t.MaxErrorRate {
+		stats.Reasons = append(stats.Reasons, fmt.Sprintf("error rate %.0f%% > %.0f%%", stats.ErrorRate*100, t.MaxErrorRate*100))
+	}
+	stats.Degraded = len(stats.Reasons) > 0
+	return stats
+}
+
+// Stats restituisce le metriche di tutti i simboli osservati, in ordine di simbolo e timeframe
+func (m *CandleMonitor) Stats() []CandleStats {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]CandleStats, 0, len(m.series))
+	for key, s := range m.series {
+		stats = append(stats, m.statsLocked(key, s))
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Symbol != stats[j].Symbol {
+			return stats[i].Symbol < stats[j].Symbol
+		}
+		return stats[i].Timeframe < stats[j].Timeframe
+	})
+	return stats
+}
@@ -0,0 +1,115 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// fearGreedURL è l'endpoint pubblico del Crypto Fear & Greed Index di alternative.me
+const fearGreedURL = "https://api.alternative.me/fng/"
+
+func init() {
+	Register(models.DataSourceFearGreed, func() Source {
+		return NewFearGreedSource(fearGreedURL)
+	})
+}
+
+// FearGreedSource legge il Crypto Fear & Greed Index, pubblicato una volta al giorno
+type FearGreedSource struct {
+	url        string
+	httpClient *http.Client
+}
+
+// fearGreedResponse rappresenta la risposta dell'API
+type fearGreedResponse struct {
+	Data []struct {
+		Value               string `json:"value"`
+		ValueClassification string `json:"value_classification"`
+		Timestamp           string `json:"timestamp"` // Unix in secondi, come stringa
+	} `json:"data"`
+	Metadata struct {
+		Error *string `json:"error"`
+	} `json:"metadata"`
+}
+
+// NewFearGreedSource crea una nuova sorgente per l'indice Fear & Greed
+func NewFearGreedSource(url string) *FearGreedSource {
+	return &FearGreedSource{
+		url:        url,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name implementa Source
+func (s *FearGreedSource) Name() string {
+	return models.DataSourceFearGreed
+}
+
+// Fetch scarica i valori giornalieri dell'indice dal giorno di since in poi
+// Con since zero viene restituito solo l'ultimo valore
+func (s *FearGreedSource) Fetch(ctx context.Context, since time.Time) ([]*models.DataPoint, error) {
+	limit := 1
+	if !since.IsZero() {
+		limit = int(math.Ceil(time.Since(since).Hours()/24)) + 1
+	}
+
+	url := fmt.Sprintf("%s?limit=%d&format=json", s.url, max(limit, 1))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("errore creazione richiesta fear & greed: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("errore richiesta fear & greed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("errore lettura risposta fear & greed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fear & greed: status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result fearGreedResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("errore decodifica fear & greed: %w", err)
+	}
+	if result.Metadata.Error != nil && *result.Metadata.Error != "" {
+		return nil, fmt.Errorf("fear & greed: %s", *result.Metadata.Error)
+	}
+
+	points := make([]*models.DataPoint, 0, len(result.Data))
+	for _, item := range result.Data {
+		value, err := strconv.ParseFloat(item.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("fear & greed: valore non valido %q", item.Value)
+		}
+		seconds, err := strconv.ParseInt(item.Timestamp, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("fear & greed: timestamp non valido %q", item.Timestamp)
+		}
+
+		observedAt := time.Unix(seconds, 0).UTC()
+		if !since.IsZero() && !observedAt.After(since) {
+			continue
+		}
+		points = append(points, &models.DataPoint{
+			Source:     models.DataSourceFearGreed,
+			Value:      value,
+			Label:      item.ValueClassification,
+			ObservedAt: observedAt,
+		})
+	}
+	return points, nil
+}
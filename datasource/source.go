@@ -0,0 +1,71 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// Source è una sorgente dati esterna (sentiment, on-chain, macro) le cui rilevazioni
+// vengono salvate nel database e usate come input o filtro dalle strategie
+type Source interface {
+	// Name restituisce il nome della sorgente, usato come chiave delle rilevazioni salvate
+	Name() string
+
+	// Fetch restituisce le rilevazioni osservate dopo since, in qualsiasi ordine
+	Fetch(ctx context.Context, since time.Time) ([]*models.DataPoint, error)
+}
+
+// Factory crea una nuova istanza di una sorgente
+type Factory func() Source
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register rende disponibile una sorgente con il nome indicato
+// Le implementazioni si registrano nel proprio init, così aggiungere una sorgente non richiede altre modifiche
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("sorgente dati %s già registrata", name))
+	}
+	registry[name] = factory
+}
+
+// New crea la sorgente registrata con il nome indicato
+func New(name string) (Source, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("sorgente dati %s sconosciuta (disponibili: %v)", name, available())
+	}
+	return factory(), nil
+}
+
+// Available restituisce i nomi delle sorgenti registrate, in ordine alfabetico
+func Available() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return available()
+}
+
+// available restituisce i nomi registrati; richiede il lock in lettura
+func available() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
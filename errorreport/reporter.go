@@ -0,0 +1,106 @@
+package errorreport
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Options configura l'invio degli errori a Sentry
+type Options struct {
+	DSN         string
+	Environment string  // Ambiente riportato sugli eventi (es. production, paper)
+	Release     string  // Versione del bot; vuota lascia decidere all'SDK
+	SampleRate  float64 // Frazione di errori inviati (1 = tutti)
+}
+
+// Context descrive dove è avvenuto un errore; i campi vuoti non vengono riportati
+type Context struct {
+	Component string // Componente che ha generato l'errore (es. worker, api)
+	Worker    string
+	Symbol    string
+	CycleID   string // Identificativo del ciclo del worker
+	RequestID string // Identificativo della richiesta REST (header X-Request-ID)
+
+	// Fingerprint è la chiave di raggruppamento su Sentry; vuota usa il raggruppamento per stack trace
+	// "{{ default }}" combina la chiave con il raggruppamento di default
+	Fingerprint []string
+
+	// Extra sono dati aggiuntivi non indicizzati (es. metodo e path della richiesta)
+	Extra map[string]interface{}
+}
+
+// Reporter invia panic ed errori a Sentry con il contesto in cui sono avvenuti
+// L'invio avviene in background; un Reporter nil scarta gli errori, così i chiamanti non devono controllare se è attivo
+type Reporter struct {
+	hub *sentry.Hub
+}
+
+// NewSentryReporter crea il reporter verso il progetto Sentry indicato dal DSN
+func NewSentryReporter(opts Options) (*Reporter, error) {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:              opts.DSN,
+		Environment:      opts.Environment,
+		Release:          opts.Release,
+		SampleRate:       opts.SampleRate,
+		AttachStacktrace: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure Sentry client: %w", err)
+	}
+	return &Reporter{hub: sentry.NewHub(client, sentry.NewScope())}, nil
+}
+
+// CapturePanic invia un panic recuperato; va chiamato nella funzione differita che ha eseguito recover(),
+// così lo stack trace riportato è quello del panic
+func (r *Reporter) CapturePanic(value interface{}, c Context) {
+	if r == nil {
+		return
+	}
+	hub := r.scoped(c, sentry.LevelFatal)
+	hub.Recover(value)
+}
+
+// CaptureError invia un errore con il suo contesto
+func (r *Reporter) CaptureError(err error, c Context) {
+	if r == nil || err == nil {
+		return
+	}
+	hub := r.scoped(c, sentry.LevelError)
+	hub.CaptureException(err)
+}
+
+// Flush attende l'invio degli errori in coda per al massimo timeout; false se alcuni non sono stati inviati
+func (r *Reporter) Flush(timeout time.Duration) bool {
+	if r == nil {
+		return true
+	}
+	return r.hub.Flush(timeout)
+}
+
+// scoped restituisce una copia dell'hub con scope dedicato, così gli errori concorrenti non si mescolano il contesto
+func (r *Reporter) scoped(c Context, level sentry.Level) *sentry.Hub {
+	hub := r.hub.Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetLevel(level)
+		for key, value := range map[string]string{
+			"component":  c.Component,
+			"worker":     c.Worker,
+			"symbol":     c.Symbol,
+			"cycle_id":   c.CycleID,
+			"request_id": c.RequestID,
+		} {
+			if value != "" {
+				scope.SetTag(key, value)
+			}
+		}
+		if len(c.Fingerprint) > 0 {
+			scope.SetFingerprint(c.Fingerprint)
+		}
+		if len(c.Extra) > 0 {
+			scope.SetExtras(c.Extra)
+		}
+	})
+	return hub
+}
@@ -0,0 +1,106 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// publishTimeout è il tempo massimo per pubblicare un evento
+const publishTimeout = 10 * time.Second
+
+// Publisher invia un messaggio a un broker (NATS, Kafka)
+type Publisher interface {
+	// Publish pubblica payload sul topic; key è la chiave di partizione (ignorata dai broker che non la usano)
+	Publish(ctx context.Context, topic, key string, payload []byte) error
+
+	// Close rilascia la connessione al broker
+	Close() error
+}
+
+// Emitter pubblica gli eventi in background, così il trading non attende il broker
+// Se il broker è lento e la coda è piena gli eventi vengono scartati con un log, senza bloccare il chiamante
+// Un Emitter nil scarta gli eventi: i chiamanti non devono controllare se la pubblicazione è attiva
+type Emitter struct {
+	publisher Publisher
+	prefix    string // Prefisso dei topic (es. mkybot → mkybot.order.placed)
+	queue     chan Event
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewEmitter crea l'emitter e avvia la pubblicazione; buffer è la dimensione della coda
+func NewEmitter(publisher Publisher, prefix string, buffer int) *Emitter {
+	e := &Emitter{
+		publisher: publisher,
+		prefix:    prefix,
+		queue:     make(chan Event, buffer),
+		done:      make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Topic restituisce il topic di un tipo di evento
+func (e *Emitter) Topic(eventType Type) string {
+	if e.prefix == "" {
+		return string(eventType)
+	}
+	return e.prefix + "." + string(eventType)
+}
+
+// Emit accoda un evento per la pubblicazione
+func (e *Emitter) Emit(event Event) {
+	if e == nil {
+		return
+	}
+	defer func() {
+		// Emit dopo Close: l'evento viene scartato
+		if recover() != nil {
+			log.Printf("⚠️  Evento %s scartato: publisher chiuso", event.ID)
+		}
+	}()
+
+	select {
+	case e.queue <- event:
+	default:
+		log.Printf("⚠️  Evento %s scartato: coda di pubblicazione piena", event.ID)
+	}
+}
+
+// Close pubblica gli eventi in coda fino alla scadenza di ctx e chiude la connessione al broker
+func (e *Emitter) Close(ctx context.Context) error {
+	if e == nil {
+		return nil
+	}
+	e.closeOnce.Do(func() { close(e.queue) })
+
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		log.Printf("⚠️  Arresto publisher eventi: %d eventi non pubblicati", len(e.queue))
+	}
+	return e.publisher.Close()
+}
+
+// run pubblica gli eventi in ordine di arrivo fino alla chiusura della coda
+func (e *Emitter) run() {
+	defer close(e.done)
+
+	for event := range e.queue {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			log.Printf("❌ Evento %s non serializzabile: %v", event.ID, err)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		err = e.publisher.Publish(ctx, e.Topic(event.Type), event.Symbol, payload)
+		cancel()
+		if err != nil {
+			log.Printf("❌ Evento %s non pubblicato: %v", event.ID, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package events
+
+import "time"
+
+// SchemaVersion è la versione dello schema degli eventi: cambia solo con modifiche incompatibili
+// (campi rimossi o con significato diverso); i campi nuovi vengono aggiunti senza cambiarla
+const SchemaVersion = 1
+
+// Type identifica il tipo di evento ed è anche il suffisso del topic/subject su cui viene pubblicato
+type Type string
+
+const (
+	TypeOrderPlaced    Type = "order.placed"    // Ordine salvato dopo il piazzamento sull'exchange
+	TypeOrderFilled    Type = "order.filled"    // Ordine eseguito: stato Filled o posizione aperta rilevata
+	TypePositionClosed Type = "position.closed" // Posizione chiusa con PnL realizzato
+	TypeRegimeChanged  Type = "regime.changed"  // Cambio del regime di mercato (trend o laterale) di un simbolo
+	TypeFundsTransfer  Type = "funds.transfer"  // Trasferimento di fondi tra master e sub-account del ribilanciamento
+	TypeSignalDecided  Type = "signal.decided"  // Decisione di una strategia su un segnale, con le condizioni valutate
+)
+
+// Event è la busta comune a tutti gli eventi pubblicati
+// ID è stabile per lo stesso fatto (es. order.filled:<order_id>): i consumer lo usano per scartare i duplicati
+type Event struct {
+	SchemaVersion int       `json:"schema_version"`
+	ID            string    `json:"id"`
+	Type          Type      `json:"type"`
+	Time          time.Time `json:"time"`
+	Source        string    `json:"source"` // Componente che ha generato l'evento (es. doge-trading-system)
+	Symbol        string    `json:"symbol"` // Usato anche come chiave di partizione su Kafka
+	Order         *Order    `json:"order,omitempty"`
+	Position      *Position `json:"position,omitempty"`
+	Regime        *Regime   `json:"regime,omitempty"`
+	Transfer      *Transfer `json:"transfer,omitempty"`
+	Signal        *Signal   `json:"signal,omitempty"`
+}
+
+// Order descrive un ordine negli eventi order.placed e order.filled
+type Order struct {
+	OrderID       string   `json:"order_id"`
+	Symbol        string   `json:"symbol"`
+	Side          string   `json:"side"` // Buy o Sell
+	Price         float64  `json:"price"`
+	Quantity      float64  `json:"quantity"`
+	StopLoss      *float64 `json:"stop_loss,omitempty"`
+	TakeProfit    *float64 `json:"take_profit,omitempty"`
+	Status        string   `json:"status"`
+	ConfigVersion string   `json:"config_version,omitempty"`
+}
+
+// Position descrive una posizione chiusa nell'evento position.closed
+type Position struct {
+	PositionID    string     `json:"position_id"` // ID dell'ordine di apertura o della posizione di funding arbitrage
+	Symbol        string     `json:"symbol"`
+	Side          string     `json:"side"`
+	Quantity      float64    `json:"quantity"`
+	EntryPrice    float64    `json:"entry_price"`
+	ExitPrice     *float64   `json:"exit_price,omitempty"`
+	PnL           float64    `json:"pnl"`
+	PnLPercentage *float64   `json:"pnl_percentage,omitempty"`
+	Result        string     `json:"result"` // Profit o Loss
+	OpenedAt      time.Time  `json:"opened_at"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty"`
+}
+
+// Regime descrive il cambio di regime di un simbolo nell'evento regime.changed
+type Regime struct {
+	Symbol   string  `json:"symbol"`
+	Regime   string  `json:"regime"`             // trending o ranging
+	Previous string  `json:"previous,omitempty"` // Vuoto alla prima misura
+	Method   string  `json:"method"`             // adx o variance_ratio
+	Value    float64 `json:"value"`              // Valore dell'indicatore che ha determinato il cambio
+}
+
+// Transfer descrive un trasferimento tra account nell'evento funds.transfer
+type Transfer struct {
+	TransferID string  `json:"transfer_id,omitempty"` // Vuoto se la richiesta è fallita prima di arrivare all'exchange
+	Coin       string  `json:"coin"`
+	Amount     float64 `json:"amount"`
+	From       string  `json:"from"` // Allocazione di origine: master o nome della strategia
+	To         string  `json:"to"`
+	Status     string  `json:"status"` // SUCCESS, PENDING o FAILED
+	Error      string  `json:"error,omitempty"`
+}
+
+// Signal descrive la decisione di una strategia su un segnale nell'evento signal.decided
+type Signal struct {
+	Strategy string        `json:"strategy"`
+	Side     string        `json:"side"`
+	Trigger  string        `json:"trigger"` // Origine del segnale (es. wall_break, retest, liquidation_cascade)
+	Outcome  string        `json:"outcome"` // entered, rejected, armed o failed
+	OrderID  string        `json:"order_id,omitempty"`
+	Checks   []SignalCheck `json:"checks"`
+}
+
+// SignalCheck è una condizione valutata sul segnale; Applied è false per i filtri saltati per mancanza di dati
+type SignalCheck struct {
+	Name      string   `json:"name"`
+	Passed    bool     `json:"passed"`
+	Applied   bool     `json:"applied"`
+	Value     *float64 `json:"value,omitempty"`
+	Threshold *float64 `json:"threshold,omitempty"`
+	Detail    string   `json:"detail,omitempty"`
+}
+
+// NewEvent crea un evento con ID stabile derivato da tipo e ref (es. l'ID dell'ordine)
+func NewEvent(eventType Type, ref, source, symbol string) Event {
+	return Event{
+		SchemaVersion: SchemaVersion,
+		ID:            string(eventType) + ":" + ref,
+		Type:          eventType,
+		Time:          time.Now().UTC(),
+		Source:        source,
+		Symbol:        symbol,
+	}
+}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// kafkaContentType è il formato JSON del Kafka REST Proxy (API v2)
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaRESTPublisher pubblica gli eventi su Kafka tramite un Kafka REST Proxy (es. Confluent REST Proxy)
+// Il proxy evita un client Kafka nativo nel bot e funziona anche con cluster gestiti esposti via HTTP
+type KafkaRESTPublisher struct {
+	baseURL string
+	client  *http.Client
+}
+
+// kafkaRecords è il corpo della richiesta di produzione
+type kafkaRecords struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+// kafkaRecord è un messaggio da produrre
+type kafkaRecord struct {
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+// kafkaProduceResponse riporta l'esito di ogni messaggio prodotto
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int     `json:"partition"`
+		Offset    int64   `json:"offset"`
+		ErrorCode *int    `json:"error_code"`
+		Error     *string `json:"error"`
+	} `json:"offsets"`
+}
+
+// NewKafkaRESTPublisher crea un publisher verso il REST Proxy in baseURL (es. http://localhost:8082)
+func NewKafkaRESTPublisher(baseURL string) *KafkaRESTPublisher {
+	return &KafkaRESTPublisher{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Publish produce payload sul topic con chiave key, così gli eventi dello stesso simbolo restano ordinati
+func (p *KafkaRESTPublisher) Publish(ctx context.Context, topic, key string, payload []byte) error {
+	body, err := json.Marshal(kafkaRecords{Records: []kafkaRecord{{Key: key, Value: payload}}})
+	if err != nil {
+		return fmt.Errorf("failed to encode Kafka record: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Kafka REST request: %w", err)
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish to Kafka topic %s: %w", topic, err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("kafka REST proxy returned %d for topic %s: %s", resp.StatusCode, topic, strings.TrimSpace(string(data)))
+	}
+
+	var produced kafkaProduceResponse
+	if err := json.Unmarshal(data, &produced); err != nil {
+		return fmt.Errorf("failed to decode Kafka REST response: %w", err)
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil {
+			message := ""
+			if offset.Error != nil {
+				message = *offset.Error
+			}
+			return fmt.Errorf("kafka rejected record on topic %s (code %d): %s", topic, *offset.ErrorCode, message)
+		}
+	}
+	return nil
+}
+
+// Close non ha connessioni persistenti da chiudere oltre a quelle inattive del client HTTP
+func (p *KafkaRESTPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
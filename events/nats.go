@@ -0,0 +1,35 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher pubblica gli eventi come messaggi NATS; il topic è il subject
+type NATSPublisher struct {
+	conn *nats.Conn
+}
+
+// NewNATSPublisher si connette al server NATS; la connessione si ricollega da sola se cade
+func NewNATSPublisher(url string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("mkybot"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS %s: %w", url, err)
+	}
+	return &NATSPublisher{conn: conn}, nil
+}
+
+// Publish pubblica payload sul subject topic; NATS non usa la chiave di partizione
+func (p *NATSPublisher) Publish(ctx context.Context, topic, key string, payload []byte) error {
+	if err := p.conn.Publish(topic, payload); err != nil {
+		return fmt.Errorf("failed to publish to NATS subject %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Close invia i messaggi ancora nel buffer del client e chiude la connessione
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
@@ -2,13 +2,17 @@ package exchange
 
 import (
 	"context"
+	"cross-exchange-arbitrage/correlation"
 	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/outage"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -24,6 +28,12 @@ const (
 	// Endpoint per le esecuzioni
 	bybitExecutionEndpoint = "/v5/execution/list"
 
+	// Endpoint per i ticker (prezzi, funding)
+	bybitTickersEndpoint = "/v5/market/tickers"
+
+	// Intervallo di funding di default dei perpetual Bybit (in ore)
+	bybitDefaultFundingIntervalHours = 8
+
 	// Limite massimo di candele per richiesta
 	maxCandlesPerRequest = 1000
 
@@ -36,12 +46,16 @@ const (
 
 // BybitExchange implementa l'interfaccia Exchange per Bybit
 type BybitExchange struct {
-	wsURL      string
-	conn       *websocket.Conn
-	priceData  map[string]*models.RealTimePriceData
-	subscriber map[string]chan *models.RealTimePriceData
-	httpClient *http.Client
-	testnet    bool
+	wsURL       string
+	mu          sync.RWMutex // Protegge connessione, prezzi, sottoscrizioni e subscriber
+	conn        *websocket.Conn
+	priceData   map[string]*models.RealTimePriceData
+	subscribed  map[string]bool // Simboli sottoscritti sulla connessione attiva
+	subscriber  map[string]chan *models.RealTimePriceData
+	lastMessage atomic.Int64 // Istante (UnixNano) dell'ultimo messaggio WebSocket, per il watchdog
+	httpClient  *http.Client
+	testnet     bool
+	candles     CandleObserver // Riceve l'esito di ogni recupero di candele; nil se la qualità non è misurata
 }
 
 // BybitOrderBookResponse rappresenta la risposta dell'order book di Bybit
@@ -105,15 +119,43 @@ type BybitExecutionResponse struct {
 	Time int64 `json:"time"`
 }
 
+// BybitTicker rappresenta un ticker restituito dall'API di Bybit
+// I campi di funding sono valorizzati solo per la categoria linear
+type BybitTicker struct {
+	Symbol              string `json:"symbol"`
+	LastPrice           string `json:"lastPrice"`
+	Bid1Price           string `json:"bid1Price"`
+	Bid1Size            string `json:"bid1Size"`
+	Ask1Price           string `json:"ask1Price"`
+	Ask1Size            string `json:"ask1Size"`
+	MarkPrice           string `json:"markPrice"`
+	IndexPrice          string `json:"indexPrice"`
+	FundingRate         string `json:"fundingRate"`
+	NextFundingTime     string `json:"nextFundingTime"`
+	FundingIntervalHour string `json:"fundingIntervalHour"`
+}
+
+// BybitTickersResponse rappresenta la risposta dei ticker di Bybit
+type BybitTickersResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		Category string        `json:"category"`
+		List     []BybitTicker `json:"list"`
+	} `json:"result"`
+	Time int64 `json:"time"`
+}
+
 func NewBybitExchange(testnet bool) *BybitExchange {
 	if testnet {
 		return &BybitExchange{
 			wsURL:      "wss://stream.bybit.com/v5/public/linear",
 			priceData:  make(map[string]*models.RealTimePriceData),
+			subscribed: make(map[string]bool),
 			subscriber: make(map[string]chan *models.RealTimePriceData),
-			httpClient: &http.Client{
+			httpClient: outage.NewClient("bybit", correlation.NewClient(correlation.BybitHeader, &http.Client{
 				Timeout: 10 * time.Second,
-			},
+			})),
 			testnet: true,
 		}
 	}
@@ -121,23 +163,33 @@ func NewBybitExchange(testnet bool) *BybitExchange {
 	return &BybitExchange{
 		wsURL:      "wss://stream.bybit.com/v5/public/linear",
 		priceData:  make(map[string]*models.RealTimePriceData),
+		subscribed: make(map[string]bool),
 		subscriber: make(map[string]chan *models.RealTimePriceData),
-		httpClient: &http.Client{
+		httpClient: outage.NewClient("bybit", correlation.NewClient(correlation.BybitHeader, &http.Client{
 			Timeout: 10 * time.Second,
-		},
+		})),
 	}
 }
 
 // Connect stabilisce la connessione WebSocket con Bybit
+// Il listener vive quanto la connessione, indipendentemente dal contesto della chiamata che l'ha aperta
 func (b *BybitExchange) Connect(ctx context.Context) error {
-	var err error
-	b.conn, _, err = websocket.DefaultDialer.DialContext(ctx, b.wsURL, nil)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.connectLocked(ctx)
+}
+
+// connectLocked apre la connessione e avvia il listener; il chiamante detiene mu
+func (b *BybitExchange) connectLocked(ctx context.Context) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, b.wsURL, nil)
 	if err != nil {
 		return fmt.Errorf("errore connessione WebSocket Bybit: %w", err)
 	}
+	b.conn = conn
+	b.lastMessage.Store(time.Now().UnixNano())
 
 	// Avvia il listener per i messaggi WebSocket
-	go b.messageListener(ctx)
+	go b.messageListener(conn)
 
 	log.Println("Connessione WebSocket Bybit stabilita")
 	return nil
@@ -145,6 +197,13 @@ func (b *BybitExchange) Connect(ctx context.Context) error {
 
 // Subscribe sottoscrive agli aggiornamenti dell'order book per un simbolo
 func (b *BybitExchange) Subscribe(symbol string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.subscribeLocked(symbol)
+}
+
+// subscribeLocked invia la sottoscrizione e la registra per le riconnessioni; il chiamante detiene mu
+func (b *BybitExchange) subscribeLocked(symbol string) error {
 	if b.conn == nil {
 		return fmt.Errorf("connessione WebSocket non stabilita")
 	}
@@ -157,44 +216,91 @@ func (b *BybitExchange) Subscribe(symbol string) error {
 	if err := b.conn.WriteJSON(subscribeMsg); err != nil {
 		return fmt.Errorf("errore sottoscrizione simbolo %s: %w", symbol, err)
 	}
+	b.subscribed[symbol] = true
 
 	log.Printf("Sottoscritto agli aggiornamenti dell'order book per %s", symbol)
 	return nil
 }
 
-// messageListener ascolta i messaggi WebSocket e aggiorna i dati dei prezzi
-func (b *BybitExchange) messageListener(ctx context.Context) {
-	defer func() {
-		if b.conn != nil {
-			b.conn.Close()
-		}
-	}()
+// messageListener ascolta i messaggi WebSocket e aggiorna i dati dei prezzi finché la connessione resta aperta
+func (b *BybitExchange) messageListener(conn *websocket.Conn) {
+	defer b.dropConnection(conn)
 
 	for {
-		select {
-		case <-ctx.Done():
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			log.Printf("Errore lettura messaggio WebSocket: %v", err)
 			return
-		default:
-			_, message, err := b.conn.ReadMessage()
-			if err != nil {
-				log.Printf("Errore lettura messaggio WebSocket: %v", err)
-				return
-			}
+		}
+		b.lastMessage.Store(time.Now().UnixNano())
 
-			var response BybitOrderBookResponse
-			if err := json.Unmarshal(message, &response); err != nil {
-				// Ignora messaggi che non sono order book updates
-				continue
-			}
+		var response BybitOrderBookResponse
+		if err := json.Unmarshal(message, &response); err != nil {
+			// Ignora messaggi che non sono order book updates
+			continue
+		}
 
-			// Processa solo messaggi dell'order book
-			if response.Topic != "" && response.Data.Symbol != "" {
-				b.processOrderBookUpdate(&response)
-			}
+		// Processa solo messaggi dell'order book
+		if response.Topic != "" && response.Data.Symbol != "" {
+			b.processOrderBookUpdate(&response)
 		}
 	}
 }
 
+// dropConnection chiude la connessione e, se è ancora quella attiva, scarta i prezzi ricevuti:
+// la chiamata successiva riapre la connessione invece di restituire prezzi non più aggiornati
+func (b *BybitExchange) dropConnection(conn *websocket.Conn) {
+	conn.Close()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn != conn {
+		return
+	}
+	b.conn = nil
+	b.priceData = make(map[string]*models.RealTimePriceData)
+	b.subscribed = make(map[string]bool)
+}
+
+// LastActivity restituisce l'istante dell'ultimo messaggio ricevuto dal WebSocket
+// Restituisce zero se non ci sono connessioni o sottoscrizioni attive, cioè nulla da controllare
+func (b *BybitExchange) LastActivity() time.Time {
+	b.mu.RLock()
+	active := b.conn != nil && len(b.subscribed) > 0
+	b.mu.RUnlock()
+	if !active {
+		return time.Time{}
+	}
+	return time.Unix(0, b.lastMessage.Load())
+}
+
+// Restart chiude il WebSocket, scarta i prezzi ricevuti e lo riapre sottoscrivendo gli stessi simboli
+func (b *BybitExchange) Restart(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	symbols := make([]string, 0, len(b.subscribed))
+	for symbol := range b.subscribed {
+		symbols = append(symbols, symbol)
+	}
+	if b.conn != nil {
+		b.conn.Close()
+	}
+	b.conn = nil
+	b.priceData = make(map[string]*models.RealTimePriceData)
+	b.subscribed = make(map[string]bool)
+
+	if err := b.connectLocked(ctx); err != nil {
+		return err
+	}
+	for _, symbol := range symbols {
+		if err := b.subscribeLocked(symbol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // processOrderBookUpdate processa gli aggiornamenti dell'order book
 func (b *BybitExchange) processOrderBookUpdate(response *BybitOrderBookResponse) {
 	symbol := response.Data.Symbol
@@ -244,6 +350,7 @@ func (b *BybitExchange) processOrderBookUpdate(response *BybitOrderBookResponse)
 	}
 
 	// Aggiorna i dati interni
+	b.mu.Lock()
 	b.priceData[symbol] = priceData
 
 	// Notifica i subscriber se presenti
@@ -254,6 +361,7 @@ func (b *BybitExchange) processOrderBookUpdate(response *BybitOrderBookResponse)
 			// Channel pieno, salta questo aggiornamento
 		}
 	}
+	b.mu.Unlock()
 
 	// Log dell'aggiornamento
 	log.Printf("PREZZO: %.4f, BID: %.4f (LIQUIDITA: %.4f), ASK: %.4f (LIQUIDITA: %.4f) - %s",
@@ -263,35 +371,42 @@ func (b *BybitExchange) processOrderBookUpdate(response *BybitOrderBookResponse)
 
 // GetRealTimePrice implementa l'interfaccia Exchange
 func (b *BybitExchange) GetRealTimePrice(ctx context.Context, symbol string) (*models.RealTimePriceData, error) {
+	b.mu.Lock()
+
 	// Se non siamo connessi, stabilisci la connessione
 	if b.conn == nil {
-		if err := b.Connect(ctx); err != nil {
+		if err := b.connectLocked(ctx); err != nil {
+			b.mu.Unlock()
 			return nil, err
 		}
 	}
 
 	// Se non siamo già sottoscritti a questo simbolo, sottoscriviti
-	if _, exists := b.priceData[symbol]; !exists {
-		if err := b.Subscribe(symbol); err != nil {
+	if !b.subscribed[symbol] {
+		if err := b.subscribeLocked(symbol); err != nil {
+			b.mu.Unlock()
 			return nil, err
 		}
+	}
 
-		// Crea un channel per questo simbolo se non esiste
-		if _, exists := b.subscriber[symbol]; !exists {
-			b.subscriber[symbol] = make(chan *models.RealTimePriceData, 10)
-		}
+	// Crea un channel per questo simbolo se non esiste
+	if _, exists := b.subscriber[symbol]; !exists {
+		b.subscriber[symbol] = make(chan *models.RealTimePriceData, 10)
 	}
 
-	// Aspetta il primo aggiornamento o usa quello cached
+	// Usa il dato cached, se presente
 	if priceData, exists := b.priceData[symbol]; exists {
+		b.mu.Unlock()
 		return priceData, nil
 	}
+	updates := b.subscriber[symbol]
+	b.mu.Unlock()
 
 	// Aspetta il primo aggiornamento
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
-	case priceData := <-b.subscriber[symbol]:
+	case priceData := <-updates:
 		return priceData, nil
 	case <-time.After(10 * time.Second):
 		return nil, fmt.Errorf("timeout: nessun dato ricevuto per %s entro 10 secondi", symbol)
@@ -300,28 +415,57 @@ func (b *BybitExchange) GetRealTimePrice(ctx context.Context, symbol string) (*m
 
 // Close chiude la connessione WebSocket
 func (b *BybitExchange) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	if b.conn != nil {
-		return b.conn.Close()
+		conn := b.conn
+		b.conn = nil
+		return conn.Close()
 	}
 	return nil
 }
 
 // GetLatestPrice restituisce l'ultimo prezzo cached per un simbolo
 func (b *BybitExchange) GetLatestPrice(symbol string) (*models.RealTimePriceData, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	priceData, exists := b.priceData[symbol]
 	return priceData, exists
 }
 
 // SubscribeToUpdates restituisce un channel per ricevere aggiornamenti in tempo reale
 func (b *BybitExchange) SubscribeToUpdates(symbol string) <-chan *models.RealTimePriceData {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	if _, exists := b.subscriber[symbol]; !exists {
 		b.subscriber[symbol] = make(chan *models.RealTimePriceData, 10)
 	}
 	return b.subscriber[symbol]
 }
 
+// SetCandleObserver registra chi misura la qualità delle candele recuperate; va chiamato prima dell'uso
+func (b *BybitExchange) SetCandleObserver(observer CandleObserver) {
+	b.candles = observer
+}
+
 // FetchLastCandles implementa l'interfaccia Exchange
 func (b *BybitExchange) FetchLastCandles(ctx context.Context, symbol string, market models.Market, timeframe models.Timeframe, limit int) (*models.CandleResponse, error) {
+	response, err := b.fetchLastCandles(ctx, symbol, market, timeframe, limit)
+	if b.candles != nil {
+		var candles []models.Candle
+		if response != nil {
+			candles = response.Candles
+		}
+		b.candles.ObserveCandles(symbol, timeframe, candles, err)
+	}
+	return response, err
+}
+
+// fetchLastCandles recupera le candele dall'API REST di Bybit, pagina per pagina
+func (b *BybitExchange) fetchLastCandles(ctx context.Context, symbol string, market models.Market, timeframe models.Timeframe, limit int) (*models.CandleResponse, error) {
 	// Se il market non è specificato, usa derivatives di default
 	if market == "" {
 		market = models.DerivativesMarket
@@ -334,10 +478,7 @@ func (b *BybitExchange) FetchLastCandles(ctx context.Context, symbol string, mar
 	}
 
 	// Inizializza la risposta
-	response := &models.CandleResponse{
-		Candles: make([]models.Candle, 0, limit),
-		HasMore: false,
-	}
+	response := models.NewCandleResponse("bybit", symbol, market, timeframe, limit)
 
 	// Calcola quante richieste sono necessarie
 	remainingCandles := limit
@@ -443,6 +584,7 @@ func (b *BybitExchange) FetchLastCandles(ctx context.Context, symbol string, mar
 
 	// Indica se ci sono altre candele disponibili
 	response.HasMore = remainingCandles > 0
+	response.Finish()
 
 	return response, nil
 }
@@ -582,3 +724,93 @@ func (b *BybitExchange) FetchMonthlyTrades(ctx context.Context, symbol string, s
 
 	return response, nil
 }
+
+// fetchTicker recupera il ticker di un simbolo per la categoria indicata (spot o linear)
+func (b *BybitExchange) fetchTicker(ctx context.Context, category, symbol string) (*BybitTicker, int64, error) {
+	url := fmt.Sprintf("%s%s?category=%s&symbol=%s", bybitRESTBaseURL, bybitTickersEndpoint, category, symbol)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("errore creazione richiesta: %w", err)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("errore esecuzione richiesta: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("errore lettura risposta: %w", err)
+	}
+
+	var tickersResp BybitTickersResponse
+	if err := json.Unmarshal(body, &tickersResp); err != nil {
+		return nil, 0, fmt.Errorf("errore decodifica risposta: %w", err)
+	}
+	if tickersResp.RetCode != 0 {
+		return nil, 0, fmt.Errorf("errore API Bybit: %s", tickersResp.RetMsg)
+	}
+	if len(tickersResp.Result.List) == 0 {
+		return nil, 0, fmt.Errorf("ticker %s non trovato per la categoria %s", symbol, category)
+	}
+
+	return &tickersResp.Result.List[0], tickersResp.Time, nil
+}
+
+// GetFundingRate implementa FundingRateProvider per i perpetual lineari
+func (b *BybitExchange) GetFundingRate(ctx context.Context, symbol string) (*models.FundingRate, error) {
+	ticker, serverTime, err := b.fetchTicker(ctx, "linear", symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := strconv.ParseFloat(ticker.FundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("funding rate non valido per %s: %w", symbol, err)
+	}
+	interval, err := strconv.ParseFloat(ticker.FundingIntervalHour, 64)
+	if err != nil || interval <= 0 {
+		interval = bybitDefaultFundingIntervalHours
+	}
+	nextFunding, _ := strconv.ParseInt(ticker.NextFundingTime, 10, 64)
+	markPrice, _ := strconv.ParseFloat(ticker.MarkPrice, 64)
+	indexPrice, _ := strconv.ParseFloat(ticker.IndexPrice, 64)
+
+	return &models.FundingRate{
+		Exchange:        "bybit",
+		Symbol:          symbol,
+		Rate:            rate,
+		IntervalHours:   interval,
+		NextFundingTime: time.UnixMilli(nextFunding),
+		MarkPrice:       markPrice,
+		IndexPrice:      indexPrice,
+		Timestamp:       time.UnixMilli(serverTime),
+	}, nil
+}
+
+// GetSpotPrice implementa SpotPriceProvider leggendo il ticker spot
+func (b *BybitExchange) GetSpotPrice(ctx context.Context, symbol string) (*models.RealTimePriceData, error) {
+	ticker, serverTime, err := b.fetchTicker(ctx, "spot", symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	price, _ := strconv.ParseFloat(ticker.LastPrice, 64)
+	bidPrice, _ := strconv.ParseFloat(ticker.Bid1Price, 64)
+	askPrice, _ := strconv.ParseFloat(ticker.Ask1Price, 64)
+	bidSize, _ := strconv.ParseFloat(ticker.Bid1Size, 64)
+	askSize, _ := strconv.ParseFloat(ticker.Ask1Size, 64)
+
+	return &models.RealTimePriceData{
+		Symbol:       symbol,
+		Price:        price,
+		BidPrice:     bidPrice,
+		AskPrice:     askPrice,
+		BidLiquidity: bidSize,
+		AskLiquidity: askSize,
+		Exchange:     "bybit",
+		Timestamp:    time.UnixMilli(serverTime),
+	}, nil
+}
@@ -22,3 +22,21 @@ type Exchange interface {
 	// - Se siamo in altri mesi: dall'inizio di Gennaio fino ad oggi
 	FetchMonthlyTrades(ctx context.Context, symbol string, startDate, endDate *time.Time) (*models.ExecutionResponse, error)
 }
+
+// CandleObserver riceve l'esito di ogni recupero di candele, per misurarne la qualità
+type CandleObserver interface {
+	// ObserveCandles registra le candele recuperate per symbol e timeframe; err non nil indica un recupero fallito
+	ObserveCandles(symbol string, timeframe models.Timeframe, candles []models.Candle, err error)
+}
+
+// FundingRateProvider è implementato dagli exchange che quotano perpetual con funding
+type FundingRateProvider interface {
+	// GetFundingRate restituisce il tasso di funding corrente del perpetual
+	GetFundingRate(ctx context.Context, symbol string) (*models.FundingRate, error)
+}
+
+// SpotPriceProvider è implementato dagli exchange con un mercato spot
+type SpotPriceProvider interface {
+	// GetSpotPrice restituisce miglior bid/ask e liquidità del mercato spot
+	GetSpotPrice(ctx context.Context, symbol string) (*models.RealTimePriceData, error)
+}
@@ -0,0 +1,365 @@
+package exchange
+
+import (
+	"context"
+	"cross-exchange-arbitrage/kraken"
+	"cross-exchange-arbitrage/models"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// Endpoint Kraken Futures (path senza il prefisso /derivatives)
+	krakenOrderBookEndpoint = "/api/v3/orderbook"
+	krakenFillsEndpoint     = "/api/v3/fills"
+	krakenTickersEndpoint   = "/api/v3/tickers"
+
+	// krakenFundingIntervalHours è l'intervallo di funding dei perpetual Kraken (pagato ogni ora)
+	krakenFundingIntervalHours = 1
+
+	// krakenFillsPageSize è il numero di fill restituiti da Kraken per ogni richiesta
+	krakenFillsPageSize = 100
+
+	// krakenMaxCandlesPerRequest è il numero massimo di candele restituite dalle API charts
+	krakenMaxCandlesPerRequest = 2000
+)
+
+// krakenResolutions mappa i timeframe del bot nelle risoluzioni delle API charts di Kraken
+var krakenResolutions = map[models.Timeframe]struct {
+	resolution string
+	duration   time.Duration
+}{
+	models.Timeframe1m:  {"1m", time.Minute},
+	models.Timeframe5m:  {"5m", 5 * time.Minute},
+	models.Timeframe15m: {"15m", 15 * time.Minute},
+	models.Timeframe30m: {"30m", 30 * time.Minute},
+	models.Timeframe1h:  {"1h", time.Hour},
+	models.Timeframe4h:  {"4h", 4 * time.Hour},
+	models.Timeframe1d:  {"1d", 24 * time.Hour},
+	models.Timeframe1w:  {"1w", 7 * 24 * time.Hour},
+}
+
+// KrakenExchange implementa l'interfaccia Exchange per Kraken Futures
+// A differenza di Bybit i prezzi vengono letti via REST dall'order book, e le candele
+// arrivano dalle API charts in ordine crescente e con paginazione temporale
+type KrakenExchange struct {
+	client *kraken.Client
+}
+
+// KrakenOrderBookResponse rappresenta la risposta dell'order book di Kraken Futures
+type KrakenOrderBookResponse struct {
+	kraken.Response
+	OrderBook struct {
+		Bids [][]kraken.Number `json:"bids"`
+		Asks [][]kraken.Number `json:"asks"`
+	} `json:"orderBook"`
+}
+
+// KrakenCandle rappresenta una candela delle API charts di Kraken
+type KrakenCandle struct {
+	Time   int64         `json:"time"` // millisecondi
+	Open   kraken.Number `json:"open"`
+	High   kraken.Number `json:"high"`
+	Low    kraken.Number `json:"low"`
+	Close  kraken.Number `json:"close"`
+	Volume kraken.Number `json:"volume"`
+}
+
+// KrakenCandlesResponse rappresenta la risposta delle API charts di Kraken
+type KrakenCandlesResponse struct {
+	Candles     []KrakenCandle `json:"candles"`
+	MoreCandles bool           `json:"more_candles"`
+}
+
+// KrakenFill rappresenta un'esecuzione restituita dall'endpoint fills di Kraken
+type KrakenFill struct {
+	FillID   string        `json:"fill_id"`
+	Symbol   string        `json:"symbol"`
+	Side     string        `json:"side"` // buy o sell
+	OrderID  string        `json:"order_id"`
+	CliOrdID string        `json:"cliOrdId"`
+	Size     kraken.Number `json:"size"`
+	Price    kraken.Number `json:"price"`
+	FillTime time.Time     `json:"fillTime"`
+	FillType string        `json:"fillType"` // maker, taker, liquidation...
+}
+
+// KrakenFillsResponse rappresenta la risposta dell'endpoint fills
+type KrakenFillsResponse struct {
+	kraken.Response
+	Fills []KrakenFill `json:"fills"`
+}
+
+// KrakenTicker rappresenta un ticker di Kraken Futures
+// Per i perpetual fundingRate è assoluto (valuta di quotazione per unità di contratto, all'ora)
+type KrakenTicker struct {
+	Symbol      string        `json:"symbol"`
+	Last        kraken.Number `json:"last"`
+	Bid         kraken.Number `json:"bid"`
+	Ask         kraken.Number `json:"ask"`
+	MarkPrice   kraken.Number `json:"markPrice"`
+	IndexPrice  kraken.Number `json:"indexPrice"`
+	FundingRate kraken.Number `json:"fundingRate"`
+	Tag         string        `json:"tag"` // perpetual, month, quarter...
+	Suspended   bool          `json:"suspended"`
+}
+
+// KrakenTickersResponse rappresenta la risposta dei ticker di Kraken Futures
+type KrakenTickersResponse struct {
+	kraken.Response
+	Tickers []KrakenTicker `json:"tickers"`
+}
+
+// NewKrakenExchange crea una nuova istanza di KrakenExchange
+// Le credenziali servono solo per FetchMonthlyTrades; prezzi e candele sono pubblici
+func NewKrakenExchange(apiKey, apiSecret string, demo bool) *KrakenExchange {
+	return &KrakenExchange{
+		client: kraken.NewClient(apiKey, apiSecret, demo),
+	}
+}
+
+// GetRealTimePrice legge il miglior bid/ask e la relativa liquidità dall'order book
+func (k *KrakenExchange) GetRealTimePrice(ctx context.Context, symbol string) (*models.RealTimePriceData, error) {
+	params := url.Values{}
+	params.Set("symbol", kraken.Symbol(symbol))
+
+	var bookResp KrakenOrderBookResponse
+	if err := k.client.Get(ctx, krakenOrderBookEndpoint, params, &bookResp); err != nil {
+		return nil, err
+	}
+
+	bidPrice, bidSize, okBid := bestLevel(bookResp.OrderBook.Bids, true)
+	askPrice, askSize, okAsk := bestLevel(bookResp.OrderBook.Asks, false)
+	if !okBid || !okAsk {
+		return nil, fmt.Errorf("order book vuoto per %s", symbol)
+	}
+
+	timestamp := time.Now()
+	if serverTime, err := time.Parse(time.RFC3339, bookResp.ServerTime); err == nil {
+		timestamp = serverTime
+	}
+
+	return &models.RealTimePriceData{
+		Symbol:       symbol,
+		Price:        (bidPrice + askPrice) / 2,
+		BidPrice:     bidPrice,
+		AskPrice:     askPrice,
+		BidLiquidity: bidSize,
+		AskLiquidity: askSize,
+		Exchange:     "kraken",
+		Timestamp:    timestamp,
+	}, nil
+}
+
+// bestLevel restituisce il livello migliore dell'order book (bid più alto o ask più basso)
+func bestLevel(levels [][]kraken.Number, highest bool) (float64, float64, bool) {
+	var price, size float64
+	found := false
+	for _, level := range levels {
+		if len(level) < 2 {
+			continue
+		}
+		p := level[0].Float64()
+		if !found || (highest && p > price) || (!highest && p < price) {
+			price, size, found = p, level[1].Float64(), true
+		}
+	}
+	return price, size, found
+}
+
+// FetchLastCandles recupera le ultime candele dalle API charts di Kraken
+// Le candele vengono restituite in ordine decrescente (più recenti prima), come per Bybit
+func (k *KrakenExchange) FetchLastCandles(ctx context.Context, symbol string, market models.Market, timeframe models.Timeframe, limit int) (*models.CandleResponse, error) {
+	if market == models.SpotMarket {
+		return nil, fmt.Errorf("Kraken Futures non supporta il mercato spot")
+	}
+
+	res, ok := krakenResolutions[timeframe]
+	if !ok {
+		return nil, fmt.Errorf("timeframe %s non supportato da Kraken Futures", timeframe)
+	}
+
+	response := models.NewCandleResponse("kraken", symbol, market, timeframe, limit)
+	if limit <= 0 {
+		response.Finish()
+		return response, nil
+	}
+
+	path := fmt.Sprintf("/trade/%s/%s", kraken.Symbol(symbol), res.resolution)
+	to := time.Now()
+	from := to.Add(-time.Duration(limit) * res.duration)
+
+	// Kraken pagina per intervallo temporale: si avanza da "from" finché ci sono candele
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		params := url.Values{}
+		params.Set("from", strconv.FormatInt(from.Unix(), 10))
+		params.Set("to", strconv.FormatInt(to.Unix(), 10))
+
+		var candlesResp KrakenCandlesResponse
+		if err := k.client.GetCharts(ctx, path, params, &candlesResp); err != nil {
+			return nil, err
+		}
+
+		for _, c := range candlesResp.Candles {
+			response.Candles = append(response.Candles, models.Candle{
+				Timestamp: time.UnixMilli(c.Time),
+				Open:      c.Open.Float64(),
+				High:      c.High.Float64(),
+				Low:       c.Low.Float64(),
+				Close:     c.Close.Float64(),
+				Volume:    c.Volume.Float64(),
+			})
+		}
+
+		if !candlesResp.MoreCandles || len(candlesResp.Candles) == 0 || len(candlesResp.Candles) < krakenMaxCandlesPerRequest {
+			break
+		}
+		from = time.UnixMilli(candlesResp.Candles[len(candlesResp.Candles)-1].Time).Add(res.duration)
+
+		time.Sleep(requestInterval)
+	}
+
+	// Ordine decrescente e al massimo limit candele
+	sort.Slice(response.Candles, func(i, j int) bool {
+		return response.Candles[i].Timestamp.After(response.Candles[j].Timestamp)
+	})
+	if len(response.Candles) > limit {
+		response.Candles = response.Candles[:limit]
+	}
+	response.HasMore = len(response.Candles) < limit
+	response.Finish()
+
+	return response, nil
+}
+
+// FetchMonthlyTrades recupera le esecuzioni dell'account nell'intervallo indicato
+// L'endpoint fills restituisce le ultime 100 esecuzioni precedenti a lastFillTime,
+// quindi si procede a ritroso dalla data di fine finché non si supera quella di inizio
+func (k *KrakenExchange) FetchMonthlyTrades(ctx context.Context, symbol string, startDate, endDate *time.Time) (*models.ExecutionResponse, error) {
+	if !k.client.HasCredentials() {
+		return nil, fmt.Errorf("credenziali Kraken necessarie per recuperare i trades")
+	}
+
+	start, end := calculateDateRange(startDate, endDate)
+	log.Printf("Recupero trades Kraken per %s dal %s al %s", symbol, start.Format("2006-01-02"), end.Format("2006-01-02"))
+
+	var allExecutions []models.Execution
+	seen := make(map[string]bool)
+	lastFillTime := end
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		params := url.Values{}
+		params.Set("lastFillTime", lastFillTime.UTC().Format(time.RFC3339Nano))
+
+		var fillsResp KrakenFillsResponse
+		if err := k.client.PrivateGet(ctx, krakenFillsEndpoint, params, &fillsResp); err != nil {
+			return nil, err
+		}
+
+		oldest := lastFillTime
+		for _, fill := range fillsResp.Fills {
+			if fill.FillTime.Before(oldest) {
+				oldest = fill.FillTime
+			}
+			if seen[fill.FillID] || fill.FillTime.Before(start) || fill.FillTime.After(end) {
+				continue
+			}
+			if symbol != "" && !kraken.SameSymbol(fill.Symbol, symbol) {
+				continue
+			}
+			seen[fill.FillID] = true
+			allExecutions = append(allExecutions, convertKrakenFill(fill))
+		}
+
+		log.Printf("Recuperati %d fill in questa pagina (totale: %d)", len(fillsResp.Fills), len(allExecutions))
+
+		if len(fillsResp.Fills) < krakenFillsPageSize || oldest.Before(start) || !oldest.Before(lastFillTime) {
+			break
+		}
+		lastFillTime = oldest
+
+		time.Sleep(requestInterval)
+	}
+
+	return &models.ExecutionResponse{
+		Executions: allExecutions,
+		HasMore:    false,
+		Total:      len(allExecutions),
+	}, nil
+}
+
+// convertKrakenFill converte un fill Kraken in models.Execution
+func convertKrakenFill(fill KrakenFill) models.Execution {
+	side := string(models.OrderSideBuy)
+	if fill.Side == "sell" {
+		side = string(models.OrderSideSell)
+	}
+
+	return models.Execution{
+		Symbol:    fill.Symbol,
+		Side:      side,
+		OrderID:   fill.OrderID,
+		ExecID:    fill.FillID,
+		Price:     fill.Price.Float64(),
+		Qty:       fill.Size.Float64(),
+		ExecType:  "Trade",
+		ExecTime:  fill.FillTime,
+		IsMaker:   fill.FillType == "maker",
+		TradeTime: fill.FillTime,
+		Exchange:  "kraken",
+	}
+}
+
+// GetFundingRate implementa FundingRateProvider
+// Kraken espone un funding assoluto orario: il tasso relativo si ottiene dividendo per il prezzo
+func (k *KrakenExchange) GetFundingRate(ctx context.Context, symbol string) (*models.FundingRate, error) {
+	var tickersResp KrakenTickersResponse
+	if err := k.client.Get(ctx, krakenTickersEndpoint, nil, &tickersResp); err != nil {
+		return nil, err
+	}
+
+	krakenSymbol := kraken.Symbol(symbol)
+	for _, ticker := range tickersResp.Tickers {
+		if ticker.Symbol != krakenSymbol {
+			continue
+		}
+
+		price := ticker.IndexPrice.Float64()
+		if price <= 0 {
+			price = ticker.MarkPrice.Float64()
+		}
+		if price <= 0 {
+			return nil, fmt.Errorf("prezzo non disponibile per %s", symbol)
+		}
+
+		now := time.Now().UTC()
+		return &models.FundingRate{
+			Exchange:        "kraken",
+			Symbol:          symbol,
+			Rate:            ticker.FundingRate.Float64() / price,
+			IntervalHours:   krakenFundingIntervalHours,
+			NextFundingTime: now.Truncate(time.Hour).Add(time.Hour),
+			MarkPrice:       ticker.MarkPrice.Float64(),
+			IndexPrice:      ticker.IndexPrice.Float64(),
+			Timestamp:       now,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("ticker %s non trovato su Kraken", krakenSymbol)
+}
@@ -0,0 +1,263 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/risk"
+)
+
+// Urgency indica quanto in fretta la strategia vuole raggiungere la quantità obiettivo
+type Urgency string
+
+const (
+	UrgencyHigh   Urgency = "high"   // Eseguire subito, anche pagando lo spread
+	UrgencyNormal Urgency = "normal" // Si possono attendere alcuni secondi per eseguire come maker
+	UrgencyLow    Urgency = "low"    // L'esecuzione può essere distribuita nel tempo
+)
+
+// Nomi degli algoritmi di esecuzione predefiniti
+const (
+	AlgoImmediate        = "immediate"
+	AlgoTWAP             = "twap"
+	AlgoPassiveThenCross = "passive_then_cross"
+	AlgoPeg              = "peg"
+)
+
+// Intent è ciò che la strategia vuole ottenere: quantità obiettivo e urgenza, non gli ordini da inviare
+type Intent struct {
+	Symbol     string
+	Side       models.OrderSide
+	Quantity   float64 // Quantità obiettivo nella base coin
+	Urgency    Urgency
+	Price      float64 // Prezzo del segnale, usato per gli ordini a mercato senza prezzo medio
+	StopLoss   float64 // Stop loss della posizione; 0 se non impostato
+	TakeProfit float64 // Take profit della posizione; 0 se non impostato
+	Prefix     string  // Prefisso degli ID cliente degli ordini (es. long_DOGEUSDT)
+}
+
+// Fill è un ordine inviato dall'algoritmo con la quantità eseguita
+type Fill struct {
+	Order    *models.OrderResponse
+	Quantity float64
+	Price    float64
+}
+
+// Report riassume l'esecuzione di un intent
+// Se l'algoritmo si interrompe dopo aver eseguito una parte della quantità restituisce il report parziale:
+// la strategia deve registrare la posizione effettivamente aperta
+type Report struct {
+	Algo     string
+	Target   float64 // Quantità obiettivo
+	Quantity float64 // Quantità eseguita
+	Price    float64 // Prezzo medio ponderato della quantità eseguita
+	Fills    []Fill
+}
+
+// add aggiunge un ordine eseguito e aggiorna quantità e prezzo medio
+func (r *Report) add(fill Fill) {
+	if fill.Quantity <= 0 {
+		return
+	}
+	r.Price = (r.Price*r.Quantity + fill.Price*fill.Quantity) / (r.Quantity + fill.Quantity)
+	r.Quantity += fill.Quantity
+	r.Fills = append(r.Fills, fill)
+}
+
+// Order restituisce il primo ordine eseguito, che rappresenta l'esecuzione nel database; nil se nessuno
+func (r *Report) Order() *models.OrderResponse {
+	if len(r.Fills) == 0 {
+		return nil
+	}
+	return r.Fills[0].Order
+}
+
+// Partial indica se l'esecuzione si è fermata prima della quantità obiettivo
+func (r *Report) Partial(step float64) bool {
+	return r.Target-r.Quantity >= step
+}
+
+// Algo è un algoritmo di esecuzione: trasforma un intent in ordini usando le operazioni dell'Executor
+type Algo interface {
+	Name() string
+	Execute(ctx context.Context, executor *Executor, intent Intent) (*Report, error)
+}
+
+// QuoteSource fornisce il miglior prezzo denaro e lettera di un simbolo
+type QuoteSource interface {
+	GetRealTimePrice(ctx context.Context, symbol string) (*models.RealTimePriceData, error)
+}
+
+// InstrumentReader fornisce le regole di negoziazione di un simbolo, tra cui il passo del prezzo
+type InstrumentReader interface {
+	GetInstrumentInfo(ctx context.Context, symbol string) (*models.InstrumentInfo, error)
+}
+
+// ExecutorParams configura l'Executor
+type ExecutorParams struct {
+	Venue   string                     // Account contato dal limite di ordini (es. bybit)
+	Algos   map[Urgency]string         // Algoritmo usato per ogni urgenza
+	Retry   orderprocessor.RetryPolicy // Tentativi di ogni ordine a mercato
+	QtyStep float64                    // Passo della quantità degli ordini
+	Spread  *risk.SpreadGuard          // Controllo dello spread prima di ogni ordine a mercato; nil se disabilitato
+}
+
+// Executor è lo strato tra strategie e OrderProcessor: sceglie l'algoritmo in base all'urgenza dell'intent
+// e invia gli ordini figli, ognuno soggetto al limite di ordini dell'account
+type Executor struct {
+	processor orderprocessor.OrderProcessor
+	quotes    QuoteSource
+	throttle  *orderprocessor.OrderThrottle
+	params    ExecutorParams
+	algos     map[string]Algo
+}
+
+// NewExecutor crea l'Executor con gli algoritmi indicati
+// Ogni urgenza deve usare uno degli algoritmi; un'urgenza non configurata usa immediate, che è sempre disponibile
+func NewExecutor(processor orderprocessor.OrderProcessor, quotes QuoteSource, throttle *orderprocessor.OrderThrottle, params ExecutorParams, algos ...Algo) (*Executor, error) {
+	if params.QtyStep <= 0 {
+		return nil, fmt.Errorf("passo della quantità non valido: %g", params.QtyStep)
+	}
+
+	e := &Executor{
+		processor: processor,
+		quotes:    quotes,
+		throttle:  throttle,
+		params:    params,
+		algos:     map[string]Algo{AlgoImmediate: Immediate{}},
+	}
+	for _, algo := range algos {
+		e.algos[algo.Name()] = algo
+	}
+	for urgency, name := range params.Algos {
+		if _, ok := e.algos[name]; !ok {
+			return nil, fmt.Errorf("algoritmo %q non disponibile per l'urgenza %s", name, urgency)
+		}
+	}
+	return e, nil
+}
+
+// Execute esegue l'intent con l'algoritmo associato alla sua urgenza
+func (e *Executor) Execute(ctx context.Context, intent Intent) (*Report, error) {
+	name, ok := e.params.Algos[intent.Urgency]
+	if !ok {
+		name = AlgoImmediate
+	}
+	intent.Quantity = e.RoundQuantity(intent.Quantity)
+	if intent.Quantity <= 0 {
+		return nil, fmt.Errorf("quantità %s %s inferiore al passo %g", intent.Side, intent.Symbol, e.params.QtyStep)
+	}
+
+	log.Printf("🎯 Esecuzione %s %s di %g (urgenza %s) con %s", intent.Side, intent.Symbol, intent.Quantity, intent.Urgency, name)
+	report, err := e.algos[name].Execute(ctx, e, intent)
+	if report != nil {
+		report.Algo = name
+		report.Target = intent.Quantity
+		if report.Partial(e.params.QtyStep) {
+			log.Printf("⚠️  Esecuzione %s %s parziale: %g di %g a %.6f", intent.Side, intent.Symbol, report.Quantity, intent.Quantity, report.Price)
+		}
+	}
+	return report, err
+}
+
+// RoundQuantity arrotonda la quantità per difetto al passo degli ordini
+func (e *Executor) RoundQuantity(quantity float64) float64 {
+	return models.FloorToStep(quantity, e.params.QtyStep)
+}
+
+// PlaceMarket invia un ordine a mercato per quantity con stop loss e take profit dell'intent,
+// riprovando con lo stesso ID cliente (prefix seguito da un suffisso casuale)
+// L'ordine non viene inviato se lo spread supera la soglia del controllo dello spread
+func (e *Executor) PlaceMarket(ctx context.Context, intent Intent, quantity float64, prefix string) (Fill, error) {
+	if err := e.params.Spread.Check(ctx, intent.Symbol, e.Book); err != nil {
+		return Fill{}, err
+	}
+	if err := e.throttle.Acquire(ctx, e.params.Venue, intent.Symbol); err != nil {
+		return Fill{}, err
+	}
+
+	place := e.processor.PlaceLongOrder
+	if intent.Side == models.OrderSideSell {
+		place = e.processor.PlaceShortOrder
+	}
+	resp, err := orderprocessor.PlaceWithRetry(ctx, e.processor, intent.Symbol, prefix, e.params.Retry, func(ctx context.Context) (*models.OrderResponse, error) {
+		return place(ctx, intent.Symbol, intent.Price, quantity, intent.StopLoss, intent.TakeProfit)
+	})
+	if err != nil {
+		return Fill{}, err
+	}
+
+	// Gli ordini a mercato sono IOC: la risposta di creazione non riporta il prezzo medio,
+	// si usa quello del segnale come per gli ingressi inviati direttamente
+	price := resp.AveragePrice
+	if price <= 0 {
+		price = intent.Price
+	}
+	return Fill{Order: resp, Quantity: quantity, Price: price}, nil
+}
+
+// Quote restituisce il miglior prezzo dal lato del book su cui l'intent attende come maker:
+// il denaro per un acquisto, la lettera per una vendita
+func (e *Executor) Quote(ctx context.Context, symbol string, side models.OrderSide) (float64, error) {
+	book, err := e.Book(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	if side == models.OrderSideSell {
+		return book.AskPrice, nil
+	}
+	return book.BidPrice, nil
+}
+
+// Book restituisce miglior denaro e miglior lettera del simbolo
+func (e *Executor) Book(ctx context.Context, symbol string) (*models.RealTimePriceData, error) {
+	if e.quotes == nil {
+		return nil, fmt.Errorf("prezzi di %s non disponibili", symbol)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	book, err := e.quotes.GetRealTimePrice(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if book.BidPrice <= 0 || book.AskPrice <= 0 {
+		return nil, fmt.Errorf("miglior prezzo di %s non disponibile", symbol)
+	}
+	return book, nil
+}
+
+// Tick restituisce il passo del prezzo del simbolo letto dal processor; 0 se non disponibile
+func (e *Executor) Tick(ctx context.Context, symbol string) float64 {
+	reader, ok := e.processor.(InstrumentReader)
+	if !ok {
+		return 0
+	}
+	info, err := reader.GetInstrumentInfo(ctx, symbol)
+	if err != nil {
+		log.Printf("⚠️  Passo del prezzo di %s non disponibile: %v", symbol, err)
+		return 0
+	}
+	return info.TickSize
+}
+
+// Immediate invia l'intera quantità con un solo ordine a mercato
+type Immediate struct{}
+
+// Name implementa Algo
+func (Immediate) Name() string { return AlgoImmediate }
+
+// Execute implementa Algo
+func (Immediate) Execute(ctx context.Context, executor *Executor, intent Intent) (*Report, error) {
+	fill, err := executor.PlaceMarket(ctx, intent, intent.Quantity, intent.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	report.add(fill)
+	return report, nil
+}
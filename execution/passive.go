@@ -0,0 +1,133 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+)
+
+// PassiveThenCross attende come maker al miglior prezzo del proprio lato del book per Timeout,
+// poi annulla l'ordine limite e invia a mercato la quantità non eseguita
+// Senza ordini limite o senza prezzi l'intera quantità viene inviata a mercato
+type PassiveThenCross struct {
+	Timeout time.Duration // Attesa massima dell'ordine limite
+	Poll    time.Duration // Intervallo di controllo dello stato dell'ordine limite
+}
+
+// Name implementa Algo
+func (PassiveThenCross) Name() string { return AlgoPassiveThenCross }
+
+// Execute implementa Algo
+func (a PassiveThenCross) Execute(ctx context.Context, executor *Executor, intent Intent) (*Report, error) {
+	limiter, ok := executor.processor.(orderprocessor.LimitOrderProcessor)
+	if !ok {
+		log.Printf("⚠️  Ordini limite non supportati dal processor: %s %s inviato a mercato", intent.Side, intent.Symbol)
+		return Immediate{}.Execute(ctx, executor, intent)
+	}
+	price, err := executor.Quote(ctx, intent.Symbol, intent.Side)
+	if err != nil {
+		log.Printf("⚠️  Miglior prezzo non disponibile (%v): %s %s inviato a mercato", err, intent.Side, intent.Symbol)
+		return Immediate{}.Execute(ctx, executor, intent)
+	}
+	if err := executor.throttle.Acquire(ctx, executor.params.Venue, intent.Symbol); err != nil {
+		return nil, err
+	}
+
+	orderLinkID := orderprocessor.GenerateOrderLinkID(intent.Prefix + "_p")
+	resp, err := limiter.PlaceLimitOrder(orderprocessor.WithOrderLinkID(ctx, orderLinkID), intent.Symbol, intent.Side,
+		price, intent.Quantity, intent.StopLoss, intent.TakeProfit, true)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		log.Printf("⚠️  Ordine limite %s rifiutato (%s): %s %s inviato a mercato", orderLinkID, resp.ErrorMessage, intent.Side, intent.Symbol)
+		return Immediate{}.Execute(ctx, executor, intent)
+	}
+	log.Printf("🎯 Ordine limite %s %s di %g a %.6f in attesa per %v", intent.Side, intent.Symbol, intent.Quantity, price, a.Timeout)
+
+	final, err := a.await(ctx, executor.processor, intent.Symbol, resp.OrderID)
+	if err != nil {
+		// Senza la quantità eseguita l'ordine a mercato potrebbe raddoppiare la posizione
+		return nil, fmt.Errorf("esito dell'ordine limite %s sconosciuto: %w", resp.OrderID, err)
+	}
+	return crossRemainder(ctx, executor, intent, resp, final, price)
+}
+
+// crossRemainder registra la quantità eseguita dall'ordine limite resp, il cui stato finale è final,
+// e invia a mercato la quantità ancora mancante
+func crossRemainder(ctx context.Context, executor *Executor, intent Intent, resp, final *models.OrderResponse, price float64) (*Report, error) {
+	filled := final.FilledQuantity
+	if final.Status == models.OrderStatusFilled && filled <= 0 {
+		filled = final.Quantity
+	}
+	if final.AveragePrice > 0 {
+		price = final.AveragePrice
+	}
+
+	report := &Report{}
+	report.add(Fill{Order: resp, Quantity: filled, Price: price})
+	remaining := executor.RoundQuantity(intent.Quantity - filled)
+	if remaining <= 0 {
+		return report, nil
+	}
+
+	log.Printf("🎯 Ordine limite %s eseguito per %g di %g: %g inviati a mercato", resp.OrderID, filled, intent.Quantity, remaining)
+	fill, err := executor.PlaceMarket(ctx, intent, remaining, intent.Prefix+"_x")
+	if err != nil {
+		return stopped(report, err)
+	}
+	report.add(fill)
+	return report, nil
+}
+
+// await attende che l'ordine limite venga eseguito, annullato dall'exchange (post-only che avrebbe attraversato
+// lo spread) o che scada il timeout; alla scadenza lo cancella. Restituisce lo stato finale dell'ordine
+func (a PassiveThenCross) await(ctx context.Context, processor orderprocessor.OrderProcessor, symbol, orderID string) (*models.OrderResponse, error) {
+	deadline := time.Now().Add(a.Timeout)
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+			continue
+		case <-time.After(a.Poll):
+		}
+
+		status, err := processor.GetOrderStatus(ctx, symbol, orderID)
+		if err != nil {
+			log.Printf("⚠️  Stato dell'ordine limite %s non disponibile: %v", orderID, err)
+			continue
+		}
+		if !isOpen(status.Status) {
+			return status, nil
+		}
+	}
+
+	return cancelLimit(ctx, processor, symbol, orderID)
+}
+
+// cancelLimit cancella l'ordine limite e restituisce il suo stato finale, con la quantità eseguita
+// Un errore indica che l'esito dell'ordine non è noto
+func cancelLimit(ctx context.Context, processor orderprocessor.OrderProcessor, symbol, orderID string) (*models.OrderResponse, error) {
+	// La cancellazione deve arrivare all'exchange anche se il contesto del chiamante è stato annullato
+	ctx = context.WithoutCancel(ctx)
+	if _, err := processor.DeleteOrder(ctx, symbol, orderID); err != nil {
+		log.Printf("⚠️  Cancellazione dell'ordine limite %s non riuscita: %v", orderID, err)
+	}
+	status, err := processor.GetOrderStatus(ctx, symbol, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if isOpen(status.Status) {
+		return nil, fmt.Errorf("ordine ancora aperto (%s) dopo la cancellazione", status.Status)
+	}
+	return status, nil
+}
+
+// isOpen indica se l'ordine è ancora nel book e può essere eseguito
+func isOpen(status models.OrderStatus) bool {
+	return status == models.OrderStatusNew || status == models.OrderStatusPartiallyFilled ||
+		status == models.OrderStatusUntriggered
+}
@@ -0,0 +1,134 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+)
+
+// Peg piazza un ordine limite post-only un tick dentro lo spread (miglior denaro + tick per un acquisto,
+// miglior lettera - tick per una vendita) e lo sposta quando un altro ordine lo supera, per Duration;
+// poi lo annulla e invia a mercato la quantità non eseguita. Eseguendo come maker si evita la commissione taker
+// Senza ordini limite o senza prezzi l'intera quantità viene inviata a mercato; se il processor non può
+// modificare gli ordini, l'ordine resta al prezzo iniziale
+type Peg struct {
+	Duration time.Duration // Tempo massimo in cui l'ordine segue il miglior prezzo
+	Repeg    time.Duration // Intervallo di controllo del book e dello stato dell'ordine
+}
+
+// Name implementa Algo
+func (Peg) Name() string { return AlgoPeg }
+
+// Execute implementa Algo
+func (a Peg) Execute(ctx context.Context, executor *Executor, intent Intent) (*Report, error) {
+	limiter, ok := executor.processor.(orderprocessor.LimitOrderProcessor)
+	if !ok {
+		log.Printf("⚠️  Ordini limite non supportati dal processor: %s %s inviato a mercato", intent.Side, intent.Symbol)
+		return Immediate{}.Execute(ctx, executor, intent)
+	}
+	book, err := executor.Book(ctx, intent.Symbol)
+	if err != nil {
+		log.Printf("⚠️  Book non disponibile (%v): %s %s inviato a mercato", err, intent.Side, intent.Symbol)
+		return Immediate{}.Execute(ctx, executor, intent)
+	}
+	tick := executor.Tick(ctx, intent.Symbol)
+	if err := executor.throttle.Acquire(ctx, executor.params.Venue, intent.Symbol); err != nil {
+		return nil, err
+	}
+
+	price := pegPrice(intent.Side, book, tick)
+	orderLinkID := orderprocessor.GenerateOrderLinkID(intent.Prefix + "_g")
+	resp, err := limiter.PlaceLimitOrder(orderprocessor.WithOrderLinkID(ctx, orderLinkID), intent.Symbol, intent.Side,
+		price, intent.Quantity, intent.StopLoss, intent.TakeProfit, true)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		log.Printf("⚠️  Ordine limite %s rifiutato (%s): %s %s inviato a mercato", orderLinkID, resp.ErrorMessage, intent.Side, intent.Symbol)
+		return Immediate{}.Execute(ctx, executor, intent)
+	}
+	log.Printf("🎯 Ordine limite %s %s di %g a %.6f (denaro %.6f, lettera %.6f) in attesa per %v",
+		intent.Side, intent.Symbol, intent.Quantity, price, book.BidPrice, book.AskPrice, a.Duration)
+
+	final, price, err := a.follow(ctx, executor, intent, resp.OrderID, price, tick)
+	if err != nil {
+		// Senza la quantità eseguita l'ordine a mercato potrebbe raddoppiare la posizione
+		return nil, fmt.Errorf("esito dell'ordine limite %s sconosciuto: %w", resp.OrderID, err)
+	}
+	return crossRemainder(ctx, executor, intent, resp, final, price)
+}
+
+// follow sposta l'ordine al nuovo miglior prezzo finché non viene eseguito, annullato dall'exchange
+// o scade Duration; alla scadenza lo cancella. Restituisce lo stato finale e l'ultimo prezzo dell'ordine
+func (a Peg) follow(ctx context.Context, executor *Executor, intent Intent, orderID string, price, tick float64) (*models.OrderResponse, float64, error) {
+	processor := executor.processor
+	amender, canAmend := processor.(orderprocessor.OrderAmender)
+	deadline := time.Now().Add(a.Duration)
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+			continue
+		case <-time.After(a.Repeg):
+		}
+
+		status, err := processor.GetOrderStatus(ctx, intent.Symbol, orderID)
+		if err != nil {
+			log.Printf("⚠️  Stato dell'ordine limite %s non disponibile: %v", orderID, err)
+			continue
+		}
+		if !isOpen(status.Status) {
+			return status, price, nil
+		}
+		if !canAmend {
+			continue
+		}
+
+		book, err := executor.Book(ctx, intent.Symbol)
+		if err != nil {
+			continue
+		}
+		if !outbid(intent.Side, book, price) {
+			continue
+		}
+		target := pegPrice(intent.Side, book, tick)
+		if err := amender.AmendOrderPrice(ctx, intent.Symbol, orderID, target); err != nil {
+			log.Printf("⚠️  Ordine limite %s non spostato a %.6f: %v", orderID, target, err)
+			continue
+		}
+		log.Printf("🎯 Ordine limite %s spostato da %.6f a %.6f", orderID, price, target)
+		price = target
+	}
+
+	final, err := cancelLimit(ctx, processor, intent.Symbol, orderID)
+	return final, price, err
+}
+
+// pegPrice restituisce il prezzo un tick dentro lo spread dal lato dell'ordine
+// Se lo spread è di un solo tick l'ordine si mette al miglior prezzo, dove resta maker
+func pegPrice(side models.OrderSide, book *models.RealTimePriceData, tick float64) float64 {
+	if side == models.OrderSideSell {
+		price := models.RoundToStep(book.AskPrice-tick, tick)
+		if price <= book.BidPrice {
+			return book.AskPrice
+		}
+		return price
+	}
+	price := models.RoundToStep(book.BidPrice+tick, tick)
+	if price >= book.AskPrice {
+		return book.BidPrice
+	}
+	return price
+}
+
+// outbid indica se un altro ordine offre un prezzo migliore di price dal lato dell'ordine
+// Quando l'ordine è il migliore il book riporta il suo stesso prezzo, che non va superato di nuovo
+func outbid(side models.OrderSide, book *models.RealTimePriceData, price float64) bool {
+	if side == models.OrderSideSell {
+		return book.AskPrice < price
+	}
+	return book.BidPrice > price
+}
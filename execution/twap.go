@@ -0,0 +1,64 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// TWAP distribuisce la quantità in ordini a mercato di pari quantità, inviati a intervalli regolari su Duration
+// Il primo ordine parte subito e l'ultimo alla fine della finestra, con il resto della quantità
+type TWAP struct {
+	Slices   int           // Numero di ordini figli
+	Duration time.Duration // Finestra su cui distribuire gli ordini
+}
+
+// Name implementa Algo
+func (TWAP) Name() string { return AlgoTWAP }
+
+// Execute implementa Algo
+// Un ordine figlio fallito interrompe l'esecuzione: se una parte è già eseguita restituisce il report parziale
+func (a TWAP) Execute(ctx context.Context, executor *Executor, intent Intent) (*Report, error) {
+	step := executor.params.QtyStep
+	slices := max(min(a.Slices, int(intent.Quantity/step+1e-9)), 1)
+	share := executor.RoundQuantity(intent.Quantity / float64(slices))
+	var interval time.Duration
+	if slices > 1 {
+		interval = a.Duration / time.Duration(slices-1)
+	}
+
+	report := &Report{}
+	for i := 1; i <= slices; i++ {
+		if i > 1 {
+			select {
+			case <-ctx.Done():
+				return stopped(report, fmt.Errorf("esecuzione TWAP annullata: %w", ctx.Err()))
+			case <-time.After(interval):
+			}
+		}
+
+		quantity := share
+		if i == slices {
+			quantity = executor.RoundQuantity(intent.Quantity - report.Quantity)
+		}
+		fill, err := executor.PlaceMarket(ctx, intent, quantity, fmt.Sprintf("%s_t%d", intent.Prefix, i))
+		if err != nil {
+			return stopped(report, fmt.Errorf("ordine TWAP %d/%d: %w", i, slices, err))
+		}
+		report.add(fill)
+		log.Printf("🎯 TWAP %s %s %d/%d: %g a %.6f, eseguiti %g di %g",
+			intent.Side, intent.Symbol, i, slices, quantity, fill.Price, report.Quantity, intent.Quantity)
+	}
+	return report, nil
+}
+
+// stopped restituisce l'esito di un algoritmo interrotto da err: il report parziale se una parte
+// della quantità è stata eseguita, altrimenti l'errore
+func stopped(report *Report, err error) (*Report, error) {
+	if report.Quantity <= 0 {
+		return nil, err
+	}
+	log.Printf("⚠️  Esecuzione interrotta dopo %g: %v", report.Quantity, err)
+	return report, nil
+}
@@ -0,0 +1,183 @@
+package features
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Funzionalità rischiose attivabili per ambiente o account
+const (
+	TrailingStop     = "trailing_stop"     // Stop che segue il VWAP ancorato alla rottura
+	Pyramiding       = "pyramiding"        // Incrementi delle posizioni in profitto
+	LiquidationEntry = "liquidation_entry" // Ingresso contrarian dopo una cascata di liquidazioni
+	HedgeOverlay     = "hedge_overlay"     // Apertura e aumento dello short di copertura del portafoglio
+)
+
+// Names elenca le funzionalità controllate da un flag
+var Names = []string{TrailingStop, Pyramiding, LiquidationEntry, HedgeOverlay}
+
+// ErrUnknownFlag indica un flag non previsto
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// Valori delle regole che attivano o disattivano un flag ovunque
+const (
+	ruleOn  = "on"
+	ruleOff = "off"
+)
+
+// State è lo stato di un flag esposto dalle API
+type State struct {
+	Name       string `json:"name"`
+	Enabled    bool   `json:"enabled"`
+	Rule       string `json:"rule"`       // Regola di configurazione (on, off o elenco di ambienti e account)
+	Overridden bool   `json:"overridden"` // true se lo stato è stato modificato a runtime
+}
+
+// Flags decide quali funzionalità rischiose sono attive su questa istanza
+// La configurazione assegna a ogni flag una regola: on, off oppure un elenco di ambienti e account
+// separati da | (es. paper|staging) in cui la funzionalità è attiva. Senza regola il flag è attivo e la
+// funzionalità dipende solo dalla propria configurazione. Lo stato può essere modificato a runtime;
+// le modifiche non sopravvivono al riavvio. Un Flags nil ammette tutte le funzionalità
+type Flags struct {
+	mu        sync.RWMutex
+	rules     map[string]string
+	defaults  map[string]bool
+	overrides map[string]bool
+}
+
+// ValidateRules verifica nomi e regole dei flag configurati
+func ValidateRules(rules map[string]string) error {
+	for name, rule := range rules {
+		if !slices.Contains(Names, name) {
+			return fmt.Errorf("%w %q (expected one of %s)", ErrUnknownFlag, name, strings.Join(Names, ", "))
+		}
+		if len(ruleTargets(rule)) == 0 {
+			return fmt.Errorf("empty rule for feature flag %q", name)
+		}
+	}
+	return nil
+}
+
+// New risolve le regole per l'ambiente e l'account dell'istanza
+func New(rules map[string]string, environment, account string) (*Flags, error) {
+	if err := ValidateRules(rules); err != nil {
+		return nil, err
+	}
+
+	f := &Flags{
+		rules:     make(map[string]string, len(Names)),
+		defaults:  make(map[string]bool, len(Names)),
+		overrides: make(map[string]bool),
+	}
+	for _, name := range Names {
+		rule, ok := rules[name]
+		if !ok {
+			rule = ruleOn
+		}
+		f.rules[name] = rule
+		f.defaults[name] = resolve(rule, environment, account)
+	}
+	return f, nil
+}
+
+// Enabled verifica se la funzionalità è attiva; i nomi non previsti sono sempre attivi
+func (f *Flags) Enabled(name string) bool {
+	if f == nil {
+		return true
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if enabled, ok := f.overrides[name]; ok {
+		return enabled
+	}
+	if enabled, ok := f.defaults[name]; ok {
+		return enabled
+	}
+	return true
+}
+
+// Set attiva o disattiva la funzionalità fino al riavvio, indipendentemente dalla regola configurata
+func (f *Flags) Set(name string, enabled bool) error {
+	if !slices.Contains(Names, name) {
+		return fmt.Errorf("%w %q", ErrUnknownFlag, name)
+	}
+
+	f.mu.Lock()
+	f.overrides[name] = enabled
+	f.mu.Unlock()
+
+	log.Printf("🚩 Feature flag %s impostato a runtime: %s", name, onOff(enabled))
+	return nil
+}
+
+// Reset rimuove la modifica a runtime e torna alla regola configurata
+func (f *Flags) Reset(name string) error {
+	if !slices.Contains(Names, name) {
+		return fmt.Errorf("%w %q", ErrUnknownFlag, name)
+	}
+
+	f.mu.Lock()
+	delete(f.overrides, name)
+	enabled := f.defaults[name]
+	f.mu.Unlock()
+
+	log.Printf("🚩 Feature flag %s riportato alla configurazione: %s", name, onOff(enabled))
+	return nil
+}
+
+// States restituisce lo stato di tutti i flag in ordine alfabetico
+func (f *Flags) States() []State {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	states := make([]State, 0, len(f.rules))
+	for name, rule := range f.rules {
+		state := State{Name: name, Enabled: f.defaults[name], Rule: rule}
+		if enabled, ok := f.overrides[name]; ok {
+			state.Enabled, state.Overridden = enabled, true
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// resolve valuta una regola per l'ambiente e l'account dell'istanza
+func resolve(rule, environment, account string) bool {
+	for _, target := range ruleTargets(rule) {
+		switch target {
+		case ruleOn:
+			return true
+		case ruleOff:
+			return false
+		case strings.ToLower(environment), strings.ToLower(account):
+			return true
+		}
+	}
+	return false
+}
+
+// ruleTargets restituisce i valori di una regola separati da |, in minuscolo e senza vuoti
+func ruleTargets(rule string) []string {
+	var targets []string
+	for _, target := range strings.Split(rule, "|") {
+		if target = strings.ToLower(strings.TrimSpace(target)); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// onOff descrive lo stato di un flag nei log
+func onOff(enabled bool) string {
+	if enabled {
+		return ruleOn
+	}
+	return ruleOff
+}
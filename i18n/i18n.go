@@ -0,0 +1,71 @@
+// Package i18n traduce i testi rivolti all'utente (alert, report e output della CLI)
+// I log tecnici restano nella lingua del codice che li produce
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// Locale identifica una lingua supportata
+type Locale string
+
+const (
+	Italian Locale = "it"
+	English Locale = "en"
+
+	// DefaultLocale è la lingua usata se LOCALE non è configurata, e per i testi senza traduzione
+	DefaultLocale = English
+)
+
+// current è la lingua attiva, scelta all'avvio da LOCALE
+var current atomic.Value
+
+func init() {
+	current.Store(DefaultLocale)
+}
+
+// ParseLocale converte un codice lingua (es. "it", "en-US") in una Locale supportata
+func ParseLocale(value string) (Locale, error) {
+	code, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(value)), "-")
+	code, _, _ = strings.Cut(code, "_")
+	switch Locale(code) {
+	case Italian, English:
+		return Locale(code), nil
+	default:
+		return "", fmt.Errorf("unsupported locale %q (use it or en)", value)
+	}
+}
+
+// SetLocale imposta la lingua dei testi rivolti all'utente
+func SetLocale(locale Locale) {
+	current.Store(locale)
+}
+
+// Current restituisce la lingua attiva
+func Current() Locale {
+	return current.Load().(Locale)
+}
+
+// T traduce il messaggio key nella lingua attiva e ne formatta gli argomenti come fmt.Sprintf
+func T(key string, args ...any) string {
+	return Translate(Current(), key, args...)
+}
+
+// Translate traduce il messaggio key nella lingua indicata
+// Un messaggio senza traduzione usa la lingua di default; una chiave sconosciuta viene restituita così com'è
+func Translate(locale Locale, key string, args ...any) string {
+	translations, ok := messages[key]
+	if !ok {
+		return key
+	}
+	format, ok := translations[locale]
+	if !ok {
+		format = translations[DefaultLocale]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
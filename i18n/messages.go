@@ -0,0 +1,183 @@
+package i18n
+
+// messages contiene le traduzioni dei testi rivolti all'utente, indicizzate per chiave e lingua
+// I formati usano i verbi di fmt; ogni traduzione deve avere gli stessi argomenti, nello stesso ordine
+var messages = map[string]map[Locale]string{
+	// Output della CLI
+	"cli.usage": {
+		English: `Usage:
+  mkybot                 start the trading bot and the workers
+  mkybot keys set NAME   store a credential (e.g. BYBIT_SECRET_KEY) in the encrypted credentials file
+  mkybot keys list       list the credentials stored in the encrypted credentials file
+  mkybot config ...      list, show or roll back the recorded strategy/risk configuration versions
+  mkybot dataset export  export labeled training data from closed orders and the candle cache (csv or parquet)
+  mkybot tax export      export the realized gains of a year, matched FIFO from the executions (csv)
+  mkybot state export    archive the database, the encrypted credentials and optionally .env for a host migration
+  mkybot state import    verify a state archive and restore it (run with the bot stopped)
+  mkybot subaccount ...  create a Bybit sub-account and API key for a strategy, or list them (see "mkybot subaccount")
+  mkybot debug sign ...  print the signed payload for a Bybit request (see "mkybot debug sign -h")
+  mkybot debug outage    simulate an exchange outage and check the degraded-mode policy (see "mkybot debug outage -h")`,
+		Italian: `Utilizzo:
+  mkybot                 avvia il bot di trading e i worker
+  mkybot keys set NOME   salva una credenziale (es. BYBIT_SECRET_KEY) nel file cifrato delle credenziali
+  mkybot keys list       elenca le credenziali salvate nel file cifrato delle credenziali
+  mkybot config ...      elenca, mostra o ripristina le versioni registrate della configurazione di strategia/rischio
+  mkybot dataset export  esporta i dati di addestramento etichettati da ordini chiusi e cache delle candele (csv o parquet)
+  mkybot tax export      esporta le plusvalenze realizzate in un anno, abbinate in FIFO dalle esecuzioni (csv)
+  mkybot state export    archivia database, credenziali cifrate e opzionalmente .env per migrare su un altro host
+  mkybot state import    verifica un archivio di stato e lo ripristina (da eseguire a bot fermo)
+  mkybot subaccount ...  crea un sub-account Bybit e la API key di una strategia, o li elenca (vedi "mkybot subaccount")
+  mkybot debug sign ...  stampa il payload firmato di una richiesta Bybit (vedi "mkybot debug sign -h")
+  mkybot debug outage    simula un'interruzione dell'exchange e verifica la policy della modalità degradata (vedi "mkybot debug outage -h")`,
+	},
+	"cli.unknown_command": {
+		English: "unknown command %q",
+		Italian: "comando sconosciuto %q",
+	},
+	"cli.open_db_failed": {
+		English: "failed to open database: %v",
+		Italian: "impossibile aprire il database: %v",
+	},
+	"cli.dataset_exported": {
+		English: "✅ %d rows exported, %d orders skipped (candle cache incomplete)",
+		Italian: "✅ %d righe esportate, %d ordini saltati (cache delle candele incompleta)",
+	},
+	"cli.tax_exported": {
+		English: "✅ %d lots closed in %d: gains %.2f, losses %.2f, net %.2f",
+		Italian: "✅ %d lotti chiusi nel %d: plusvalenze %.2f, minusvalenze %.2f, netto %.2f",
+	},
+	"cli.key_saved": {
+		English: "✅ %s saved to %s",
+		Italian: "✅ %s salvata in %s",
+	},
+	"cli.keystore_creating": {
+		English: "Creating encrypted credentials file %s",
+		Italian: "Creazione del file cifrato delle credenziali %s",
+	},
+	"cli.state_exported": {
+		English: "✅ State exported to %s",
+		Italian: "✅ Stato esportato in %s",
+	},
+	"cli.state_restored": {
+		English: "✅ State from %s (exported %s) restored; all checksums and row counts match",
+		Italian: "✅ Stato di %s (esportato il %s) ripristinato; checksum e numero di righe coincidono",
+	},
+	"cli.state_config_hint": {
+		English: `The bot records its configuration version on startup: compare it with the exported one using "mkybot config list"`,
+		Italian: `All'avvio il bot registra la versione della configurazione: confrontala con quella esportata con "mkybot config list"`,
+	},
+	"cli.config_rolled_back": {
+		English: "✅ %s now holds the strategy configuration of version %s; restart the bot to apply it",
+		Italian: "✅ %s contiene ora la configurazione di strategia della versione %s; riavvia il bot per applicarla",
+	},
+	"cli.subaccount_created": {
+		English: "✅ Sub-account %s (UID %d) created for %s",
+		Italian: "✅ Sub-account %s (UID %d) creato per %s",
+	},
+	"cli.subaccount_key_saved": {
+		English: "✅ API key %s saved as %sAPI_KEY / %sSECRET_KEY in %s; fund the sub-account and restart the bot to use it",
+		Italian: "✅ API key %s salvata come %sAPI_KEY / %sSECRET_KEY in %s; finanzia il sub-account e riavvia il bot per usarla",
+	},
+
+	// Alert
+	"alert.basis_above": {
+		English: "⚠️  ALERT basis %s: %.4f%% above the %.4f%% threshold (perp at a premium, spot %.6f, perp %.6f)",
+		Italian: "⚠️  ALERT basis %s: %.4f%% sopra la soglia di %.4f%% (perp a premio, spot %.6f, perp %.6f)",
+	},
+	"alert.basis_below": {
+		English: "⚠️  ALERT basis %s: %.4f%% below the %.4f%% threshold (perp at a discount, spot %.6f, perp %.6f)",
+		Italian: "⚠️  ALERT basis %s: %.4f%% sotto la soglia di %.4f%% (perp a sconto, spot %.6f, perp %.6f)",
+	},
+	"alert.basis_back": {
+		English: "✅ Basis %s back within the thresholds: %.4f%%",
+		Italian: "✅ Basis %s rientrato nelle soglie: %.4f%%",
+	},
+	"alert.watchdog": {
+		English: "🚨 ALERT watchdog: %s",
+		Italian: "🚨 ALERT watchdog: %s",
+	},
+	"alert.watchdog_stuck": {
+		English: "worker %s stuck for %v (cycle %s) and not interruptible",
+		Italian: "worker %s bloccato da %v (ciclo %s) e non interrompibile",
+	},
+	"alert.watchdog_interrupted": {
+		English: "worker %s stuck for %v (cycle %s): cycle interrupted",
+		Italian: "worker %s bloccato da %v (ciclo %s): ciclo interrotto",
+	},
+	"alert.watchdog_silent": {
+		English: "%s silent for %v: restarting",
+		Italian: "%s senza attività da %v: riavvio",
+	},
+	"alert.latency_slo": {
+		English: "🚨 ALERT latency: %s over the SLO for %d consecutive orders (target %v, last %v on %s)",
+		Italian: "🚨 ALERT latenza: %s oltre lo SLO per %d ordini consecutivi (obiettivo %v, ultimo %v su %s)",
+	},
+	"alert.candle_quality": {
+		English: "🚨 ALERT candles %s timeframe %s: degraded quality (%s over the last %d fetches)",
+		Italian: "🚨 ALERT candele %s timeframe %s: qualità degradata (%s negli ultimi %d recuperi)",
+	},
+	"alert.candle_quality_back": {
+		English: "✅ Candles %s timeframe %s: quality back to normal after %v",
+		Italian: "✅ Candele %s timeframe %s: qualità tornata nella norma dopo %v",
+	},
+	"alert.regime_change": {
+		English: "🔀 Market regime %s: %s → %s (%s %.2f)",
+		Italian: "🔀 Regime di mercato %s: %s → %s (%s %.2f)",
+	},
+	"alert.signal_entered": {
+		English: "🧭 Signal %s %s %s: order %s placed (%s)",
+		Italian: "🧭 Segnale %s %s %s: ordine %s piazzato (%s)",
+	},
+	"alert.signal_failed": {
+		English: "⚠️  Signal %s %s %s: conditions met but order not placed (%s)",
+		Italian: "⚠️  Segnale %s %s %s: condizioni soddisfatte ma ordine non piazzato (%s)",
+	},
+	"alert.exchange_degraded": {
+		English: "🚨 ALERT exchange %s unreachable (%d consecutive errors, last: %s): degraded mode, action %s",
+		Italian: "🚨 ALERT exchange %s non raggiungibile (%d errori consecutivi, ultimo: %s): modalità degradata, azione %s",
+	},
+	"alert.exchange_recovered": {
+		English: "✅ Exchange %s reachable again after %v: degraded mode over",
+		Italian: "✅ Exchange %s di nuovo raggiungibile dopo %v: fine della modalità degradata",
+	},
+	"alert.margin_low": {
+		English: "⚠️  ALERT rebalancing: %s margin %.2f %s below the %.2f threshold (%.2f missing)",
+		Italian: "⚠️  ALERT ribilanciamento: margine %s %.2f %s sotto la soglia di %.2f (mancano %.2f)",
+	},
+	"alert.transfer_suggested": {
+		English: "⚠️  Suggested transfer of %.2f %s from %s to %s",
+		Italian: "⚠️  Suggerito trasferimento di %.2f %s da %s a %s",
+	},
+	"alert.unknown_order": {
+		English: "🚨 ALERT account: open order %s %s %s %s (qty %g, price %g) not created by the bot: manual trade or leaked API key, consider rotating the key",
+		Italian: "🚨 ALERT account: ordine aperto %s %s %s %s (quantità %g, prezzo %g) non creato dal bot: operazione manuale o API key compromessa, valutare la rotazione della key",
+	},
+	"alert.unknown_position": {
+		English: "🚨 ALERT account: %s %s position of %s not opened by the bot: manual trade or leaked API key, consider rotating the key",
+		Italian: "🚨 ALERT account: posizione %s %s di %s non aperta dal bot: operazione manuale o API key compromessa, valutare la rotazione della key",
+	},
+	"alert.rebalance_transfer": {
+		English: "🔁 Rebalancing: moved %.2f %s from %s to %s (transfer %s, %s)",
+		Italian: "🔁 Ribilanciamento: spostati %.2f %s da %s a %s (transfer %s, %s)",
+	},
+	"alert.rebalance_failed": {
+		English: "🚨 ALERT rebalancing: transfer of %.2f %s from %s to %s failed: %s",
+		Italian: "🚨 ALERT ribilanciamento: trasferimento di %.2f %s da %s a %s fallito: %s",
+	},
+	"alert.orphan_canceled": {
+		English: "🧹 Startup: canceled orphan conditional order %s %s %s (qty %g, trigger %g) left open by a previous run",
+		Italian: "🧹 Avvio: cancellato l'ordine condizionale orfano %s %s %s (quantità %g, trigger %g) lasciato aperto da un'esecuzione precedente",
+	},
+	"alert.orphan_adopted": {
+		English: "🧹 Startup: adopted orphan conditional order %s %s %s (qty %g, trigger %g) left open by a previous run",
+		Italian: "🧹 Avvio: registrato l'ordine condizionale orfano %s %s %s (quantità %g, trigger %g) lasciato aperto da un'esecuzione precedente",
+	},
+	"alert.orphan_failed": {
+		English: "🚨 ALERT startup: orphan conditional order %s %s %s (qty %g, trigger %g) could not be handled, it may trigger unattended: %v",
+		Italian: "🚨 ALERT avvio: ordine condizionale orfano %s %s %s (quantità %g, trigger %g) non gestito, potrebbe attivarsi senza controllo: %v",
+	},
+	"alert.deposit_needed": {
+		English: "⚠️  No venue has excess margin: a deposit is needed",
+		Italian: "⚠️  Nessuna venue ha margine in eccesso: è necessario un deposito",
+	},
+}
@@ -0,0 +1,200 @@
+package keystore
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// DefaultPath è il percorso predefinito del file di credenziali cifrato
+	DefaultPath = "credentials.enc"
+
+	// fileVersion è la versione del formato del file cifrato
+	fileVersion = 1
+
+	// Parametri scrypt per la derivazione della chiave dalla passphrase
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	keyLength    = 32
+	saltLength   = 16
+	nonceLength  = 24
+	filePermMode = 0o600
+)
+
+var (
+	// ErrWrongPassphrase indica che la passphrase non decifra il file (o che il file è stato alterato)
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted credentials file")
+
+	// ErrInvalidName indica un nome di credenziale non valido
+	ErrInvalidName = errors.New("invalid credential name")
+
+	// namePattern accetta nomi nello stile delle variabili d'ambiente (es. BYBIT_API_KEY)
+	namePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
+
+// encryptedFile è il formato su disco: solo salt, parametri e testo cifrato sono in chiaro
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Store contiene le credenziali decifrate, indicizzate per nome (es. BYBIT_SECRET_KEY)
+type Store struct {
+	values map[string]string
+}
+
+// NewStore crea uno store vuoto
+func NewStore() *Store {
+	return &Store{values: make(map[string]string)}
+}
+
+// Load decifra il file di credenziali con la passphrase indicata
+func Load(path string, passphrase []byte) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials file: %w", err)
+	}
+	if file.Version != fileVersion || file.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported credentials file format (version %d, kdf %q)", file.Version, file.KDF)
+	}
+	if len(file.Nonce) != nonceLength {
+		return nil, ErrWrongPassphrase
+	}
+
+	key, err := scrypt.Key(passphrase, file.Salt, file.N, file.R, file.P, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	var nonce [nonceLength]byte
+	var secretKey [keyLength]byte
+	copy(nonce[:], file.Nonce)
+	copy(secretKey[:], key)
+
+	plaintext, ok := secretbox.Open(nil, file.Ciphertext, &nonce, &secretKey)
+	if !ok {
+		return nil, ErrWrongPassphrase
+	}
+
+	store := NewStore()
+	if err := json.Unmarshal(plaintext, &store.values); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+	return store, nil
+}
+
+// Save cifra lo store con la passphrase indicata e lo scrive su path in modo atomico
+// Ogni salvataggio usa salt e nonce nuovi
+func (s *Store) Save(path string, passphrase []byte) error {
+	plaintext, err := json.Marshal(s.values)
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+	var nonce [nonceLength]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return fmt.Errorf("failed to derive key: %w", err)
+	}
+	var secretKey [keyLength]byte
+	copy(secretKey[:], key)
+
+	data, err := json.MarshalIndent(encryptedFile{
+		Version:    fileVersion,
+		KDF:        "scrypt",
+		N:          scryptN,
+		R:          scryptR,
+		P:          scryptP,
+		Salt:       salt,
+		Nonce:      nonce[:],
+		Ciphertext: secretbox.Seal(nil, plaintext, &nonce, &secretKey),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials file: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("failed to create credentials directory: %w", err)
+		}
+	}
+
+	// Scrittura su file temporaneo + rename: un'interruzione non lascia il file a metà
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, filePermMode); err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace credentials file: %w", err)
+	}
+	return nil
+}
+
+// Set imposta il valore di una credenziale
+func (s *Store) Set(name, value string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w %q: use upper-case environment variable names such as BYBIT_API_KEY", ErrInvalidName, name)
+	}
+	s.values[name] = value
+	return nil
+}
+
+// Get restituisce il valore di una credenziale
+func (s *Store) Get(name string) (string, bool) {
+	value, ok := s.values[name]
+	return value, ok
+}
+
+// Names restituisce i nomi delle credenziali salvate, in ordine alfabetico
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyToEnv esporta le credenziali come variabili d'ambiente
+// Come per il file .env, le variabili già valorizzate non vengono sovrascritte
+func (s *Store) ApplyToEnv() error {
+	for name, value := range s.values {
+		if os.Getenv(name) != "" {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", name, err)
+		}
+	}
+	return nil
+}
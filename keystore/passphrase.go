@@ -0,0 +1,77 @@
+package keystore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// PassphraseEnv è la variabile d'ambiente con la passphrase, per gli avvii non interattivi (es. systemd)
+const PassphraseEnv = "CREDENTIALS_PASSPHRASE"
+
+// ErrNoPassphrase indica che la passphrase non è disponibile né da ambiente né da terminale
+var ErrNoPassphrase = errors.New("credentials passphrase not available: set " + PassphraseEnv + " or run from a terminal")
+
+// ReadPassphrase restituisce la passphrase da PassphraseEnv o, in un terminale, la chiede all'utente
+func ReadPassphrase(prompt string) ([]byte, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	return promptSecret(prompt)
+}
+
+// ReadNewPassphrase chiede una nuova passphrase due volte per evitare errori di battitura
+func ReadNewPassphrase() ([]byte, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+
+	passphrase, err := promptSecret("New credentials passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+	confirm, err := promptSecret("Repeat passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(passphrase, confirm) {
+		return nil, errors.New("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// ReadSecret legge un valore segreto: senza eco da terminale, altrimenti la prima riga dello stdin
+func ReadSecret(prompt string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		value, err := promptSecret(prompt)
+		return string(value), err
+	}
+
+	var value string
+	if _, err := fmt.Fscanln(os.Stdin, &value); err != nil {
+		return "", fmt.Errorf("failed to read value from stdin: %w", err)
+	}
+	return value, nil
+}
+
+// promptSecret chiede un valore sul terminale senza mostrarlo
+func promptSecret(prompt string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, ErrNoPassphrase
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	value, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from terminal: %w", err)
+	}
+	return value, nil
+}
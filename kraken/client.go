@@ -0,0 +1,270 @@
+package kraken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/outage"
+)
+
+const (
+	// BaseURL è l'URL di base delle API Kraken Futures di produzione
+	BaseURL = "https://futures.kraken.com"
+
+	// DemoBaseURL è l'URL di base dell'ambiente demo di Kraken Futures
+	DemoBaseURL = "https://demo-futures.kraken.com"
+
+	// derivativesPrefix precede i path delle API v3 ma non fa parte della stringa firmata
+	derivativesPrefix = "/derivatives"
+
+	// chartsPrefix è il path delle API delle candele (pubbliche, formato di risposta diverso)
+	chartsPrefix = "/api/charts/v1"
+
+	// resultSuccess è il valore del campo result per le richieste andate a buon fine
+	resultSuccess = "success"
+)
+
+// Client esegue le richieste REST verso Kraken Futures
+// Le API private sono firmate con HMAC SHA512 secondo lo schema Authent di Kraken
+type Client struct {
+	baseURL    string
+	apiKey     string
+	apiSecret  string
+	httpClient *http.Client
+
+	nonceMu   sync.Mutex
+	lastNonce int64
+}
+
+// NewClient crea un client Kraken Futures
+// apiKey e apiSecret possono essere vuoti se si usano solo le API pubbliche
+func NewClient(apiKey, apiSecret string, demo bool) *Client {
+	baseURL := BaseURL
+	if demo {
+		baseURL = DemoBaseURL
+	}
+	return &Client{
+		baseURL:   baseURL,
+		apiKey:    apiKey,
+		apiSecret: apiSecret,
+		httpClient: outage.NewClient("kraken", &http.Client{
+			Timeout: 10 * time.Second,
+		}),
+	}
+}
+
+// HasCredentials verifica se il client può chiamare le API private
+func (c *Client) HasCredentials() bool {
+	return c.apiKey != "" && c.apiSecret != ""
+}
+
+// Response è l'involucro comune delle risposte delle API v3
+type Response struct {
+	Result     string `json:"result"`
+	Error      string `json:"error"`
+	ServerTime string `json:"serverTime"`
+}
+
+// APIError rappresenta un errore restituito da Kraken Futures
+type APIError struct {
+	Endpoint string
+	Message  string
+}
+
+// Error implementa l'interfaccia error
+func (e *APIError) Error() string {
+	return fmt.Sprintf("errore API Kraken su %s: %s", e.Endpoint, e.Message)
+}
+
+// Get esegue una richiesta pubblica alle API v3 (es. /api/v3/orderbook)
+func (c *Client) Get(ctx context.Context, endpoint string, params url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, endpoint, params, false, out)
+}
+
+// PrivateGet esegue una richiesta firmata in GET alle API v3
+func (c *Client) PrivateGet(ctx context.Context, endpoint string, params url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, endpoint, params, true, out)
+}
+
+// PrivatePost esegue una richiesta firmata in POST alle API v3, con parametri form-encoded
+func (c *Client) PrivatePost(ctx context.Context, endpoint string, params url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodPost, endpoint, params, true, out)
+}
+
+// GetCharts esegue una richiesta alle API delle candele (es. /trade/PF_XBTUSD/1h)
+// Queste risposte non hanno l'involucro result/error delle API v3
+func (c *Client) GetCharts(ctx context.Context, path string, params url.Values, out interface{}) error {
+	target := c.baseURL + chartsPrefix + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("errore creazione richiesta: %w", err)
+	}
+
+	body, status, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return &APIError{Endpoint: chartsPrefix + path, Message: fmt.Sprintf("HTTP %d: %s", status, strings.TrimSpace(string(body)))}
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("errore decodifica risposta: %w", err)
+	}
+	return nil
+}
+
+// do costruisce, firma se necessario ed esegue una richiesta alle API v3
+func (c *Client) do(ctx context.Context, method, endpoint string, params url.Values, private bool, out interface{}) error {
+	if private && !c.HasCredentials() {
+		return fmt.Errorf("credenziali Kraken non configurate per %s", endpoint)
+	}
+
+	postData := params.Encode()
+	target := c.baseURL + derivativesPrefix + endpoint
+
+	var bodyReader io.Reader
+	if method == http.MethodGet {
+		if postData != "" {
+			target += "?" + postData
+		}
+	} else {
+		bodyReader = strings.NewReader(postData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bodyReader)
+	if err != nil {
+		return fmt.Errorf("errore creazione richiesta: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	if private {
+		nonce := c.nextNonce()
+		authent, err := Sign(c.apiSecret, endpoint, postData, nonce)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("APIKey", c.apiKey)
+		req.Header.Set("Nonce", nonce)
+		req.Header.Set("Authent", authent)
+	}
+
+	body, status, err := c.send(req)
+	if err != nil {
+		return err
+	}
+
+	// Gli errori applicativi arrivano come result=error, anche con status HTTP 4xx
+	var envelope Response
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		if status != http.StatusOK {
+			return &APIError{Endpoint: endpoint, Message: fmt.Sprintf("HTTP %d: %s", status, strings.TrimSpace(string(body)))}
+		}
+		return fmt.Errorf("errore decodifica risposta: %w", err)
+	}
+	if envelope.Result != resultSuccess {
+		message := envelope.Error
+		if message == "" {
+			message = fmt.Sprintf("HTTP %d: %s", status, strings.TrimSpace(string(body)))
+		}
+		return &APIError{Endpoint: endpoint, Message: message}
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("errore decodifica risposta: %w", err)
+		}
+	}
+	return nil
+}
+
+// send esegue la richiesta e restituisce il corpo della risposta
+func (c *Client) send(req *http.Request) ([]byte, int, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("errore esecuzione richiesta: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("errore lettura risposta: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
+
+// nextNonce restituisce un nonce strettamente crescente basato sui millisecondi
+// Kraken rifiuta nonce ripetuti o non crescenti per la stessa API key
+func (c *Client) nextNonce() string {
+	c.nonceMu.Lock()
+	defer c.nonceMu.Unlock()
+
+	nonce := time.Now().UnixMilli()
+	if nonce <= c.lastNonce {
+		nonce = c.lastNonce + 1
+	}
+	c.lastNonce = nonce
+	return strconv.FormatInt(nonce, 10)
+}
+
+// Sign calcola l'header Authent di una richiesta privata:
+// base64(HMAC-SHA512(base64decode(secret), SHA256(postData + nonce + endpoint)))
+// endpoint è il path senza il prefisso /derivatives (es. /api/v3/sendorder)
+func Sign(secret, endpoint, postData, nonce string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("secret Kraken non valido (atteso base64): %w", err)
+	}
+
+	digest := sha256.Sum256([]byte(postData + nonce + endpoint))
+	mac := hmac.New(sha512.New, key)
+	mac.Write(digest[:])
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Number è un valore numerico che Kraken restituisce a volte come numero e a volte come stringa
+type Number float64
+
+// UnmarshalJSON accetta sia 1.5 che "1.5"
+func (n *Number) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(data), `"`)
+	if raw == "" || raw == "null" {
+		*n = 0
+		return nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return fmt.Errorf("valore numerico non valido %s: %w", string(data), err)
+	}
+	*n = Number(value)
+	return nil
+}
+
+// Float64 restituisce il valore come float64
+func (n Number) Float64() float64 {
+	return float64(n)
+}
+
+// String formatta il valore senza notazione esponenziale
+func (n Number) String() string {
+	return strconv.FormatFloat(float64(n), 'f', -1, 64)
+}
@@ -0,0 +1,60 @@
+package kraken
+
+import "strings"
+
+// Prefissi dei contratti Kraken Futures
+const (
+	// perpetualPrefix identifica i perpetual lineari multi-collateral (es. PF_XBTUSD)
+	perpetualPrefix = "PF_"
+
+	// inversePrefix identifica i perpetual inversi (es. PI_XBTUSD)
+	inversePrefix = "PI_"
+)
+
+// Symbol converte un simbolo nel formato del bot (es. DOGEUSDT) nel perpetual Kraken (PF_DOGEUSD)
+// I simboli già in formato Kraken vengono restituiti invariati (in maiuscolo)
+func Symbol(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	if strings.HasPrefix(symbol, perpetualPrefix) || strings.HasPrefix(symbol, inversePrefix) ||
+		strings.HasPrefix(symbol, "FF_") || strings.HasPrefix(symbol, "FI_") {
+		return symbol
+	}
+
+	base := symbol
+	for _, quote := range []string{"USDT", "USDC", "USD"} {
+		if strings.HasSuffix(base, quote) && len(base) > len(quote) {
+			base = strings.TrimSuffix(base, quote)
+			break
+		}
+	}
+
+	// Kraken usa XBT per Bitcoin
+	if base == "BTC" {
+		base = "XBT"
+	}
+
+	return perpetualPrefix + base + "USD"
+}
+
+// SameSymbol verifica se due simboli (in formato bot o Kraken) indicano lo stesso contratto
+func SameSymbol(a, b string) bool {
+	return Symbol(a) == Symbol(b)
+}
+
+// BotSymbol converte un perpetual Kraken (es. PF_DOGEUSD) nel formato del bot (DOGEUSDT)
+// I simboli già nel formato del bot vengono restituiti invariati (in maiuscolo)
+func BotSymbol(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	base, ok := strings.CutPrefix(symbol, perpetualPrefix)
+	if !ok {
+		if base, ok = strings.CutPrefix(symbol, inversePrefix); !ok {
+			return symbol
+		}
+	}
+
+	base = strings.TrimSuffix(base, "USD")
+	if base == "XBT" {
+		base = "BTC"
+	}
+	return base + "USDT"
+}
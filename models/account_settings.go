@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MarginMode rappresenta la modalità di margine dell'account
+type MarginMode string
+
+const (
+	MarginModeRegular   MarginMode = "REGULAR_MARGIN"   // Margine incrociato
+	MarginModeIsolated  MarginMode = "ISOLATED_MARGIN"  // Margine isolato per posizione
+	MarginModePortfolio MarginMode = "PORTFOLIO_MARGIN" // Portfolio margin
+)
+
+// AccountSettings rappresenta la configurazione dell'account su una venue, registrata all'avvio
+type AccountSettings struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Exchange      string     `gorm:"type:varchar(20);not null;uniqueIndex:idx_account_settings_exchange" json:"exchange"`
+	Unified       bool       `gorm:"not null;default:false;comment:Account unificato (UTA su Bybit, multi-asset su Binance)" json:"unified"`
+	UnifiedStatus int        `gorm:"not null;default:0;comment:Stato dell'account unificato come restituito dalla venue" json:"unified_status"`
+	MarginMode    MarginMode `gorm:"type:varchar(20);comment:Modalità di margine dell'account" json:"margin_mode,omitempty"`
+	FetchedAt     time.Time  `gorm:"type:timestamp;not null" json:"fetched_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (AccountSettings) TableName() string {
+	return "account_settings"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (as *AccountSettings) BeforeCreate(tx *gorm.DB) error {
+	if as.Exchange == "" || as.FetchedAt.IsZero() {
+		return gorm.ErrInvalidData
+	}
+	return nil
+}
+
+// FeeRate rappresenta le commissioni maker e taker effettive dell'account su un simbolo
+// I valori sono frazioni del nozionale (0.00055 = 0.055%); una commissione maker negativa è un rebate
+type FeeRate struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Exchange  string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_fee_rate_exchange_symbol,priority:1" json:"exchange"`
+	Symbol    string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_fee_rate_exchange_symbol,priority:2" json:"symbol"`
+	MakerRate float64   `gorm:"type:REAL;not null;comment:Commissione maker (frazione del nozionale)" json:"maker_rate"`
+	TakerRate float64   `gorm:"type:REAL;not null;comment:Commissione taker (frazione del nozionale)" json:"taker_rate"`
+	FetchedAt time.Time `gorm:"type:timestamp;not null" json:"fetched_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (FeeRate) TableName() string {
+	return "fee_rates"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (fr *FeeRate) BeforeCreate(tx *gorm.DB) error {
+	if fr.Exchange == "" || fr.Symbol == "" || fr.TakerRate < 0 || fr.FetchedAt.IsZero() {
+		return gorm.ErrInvalidData
+	}
+	return nil
+}
+
+// BorrowRate rappresenta il tasso di prestito di una coin sull'account a margine unificato
+type BorrowRate struct {
+	Exchange     string    `json:"exchange"`
+	Coin         string    `json:"coin"`
+	HourlyRate   float64   `json:"hourly_rate"`   // Interesse orario sull'importo preso in prestito (0.00001 = 0.001%)
+	BorrowAmount float64   `json:"borrow_amount"` // Importo attualmente preso in prestito dall'account
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// AnnualizedRate restituisce il tasso di prestito annualizzato (senza capitalizzazione)
+// Permette il confronto diretto con il funding annualizzato
+func (br *BorrowRate) AnnualizedRate() float64 {
+	return br.HourlyRate * hoursPerYear
+}
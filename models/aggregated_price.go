@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// VenueQuote rappresenta il miglior bid/ask di una singola venue
+type VenueQuote struct {
+	Exchange     string    `json:"exchange"`
+	BidPrice     float64   `json:"bid_price"`
+	AskPrice     float64   `json:"ask_price"`
+	BidLiquidity float64   `json:"bid_liquidity"`
+	AskLiquidity float64   `json:"ask_liquidity"`
+	Timestamp    time.Time `json:"timestamp"`
+	Stale        bool      `json:"stale"`           // Quotazione più vecchia dell'età massima, esclusa dal consolidato
+	Error        string    `json:"error,omitempty"` // Errore di lettura della venue
+}
+
+// AggregatedPrice rappresenta la vista consolidata di un simbolo su tutte le venue configurate
+type AggregatedPrice struct {
+	Symbol           string       `json:"symbol"`
+	BestBid          float64      `json:"best_bid"`
+	BestBidExchange  string       `json:"best_bid_exchange"`
+	BestBidLiquidity float64      `json:"best_bid_liquidity"`
+	BestAsk          float64      `json:"best_ask"`
+	BestAskExchange  string       `json:"best_ask_exchange"`
+	BestAskLiquidity float64      `json:"best_ask_liquidity"`
+	Spread           float64      `json:"spread"`     // BestAsk - BestBid; negativo se il book consolidato è incrociato
+	SpreadPct        float64      `json:"spread_pct"` // Spread relativo al prezzo medio
+	Quotes           []VenueQuote `json:"quotes"`
+	Timestamp        time.Time    `json:"timestamp"`
+}
+
+// IsCrossed verifica se il miglior bid di una venue supera il miglior ask di un'altra
+// È la condizione di ingresso di un arbitraggio cross-exchange (compra sull'ask, vendi sul bid)
+func (ap *AggregatedPrice) IsCrossed() bool {
+	return ap.BestBidExchange != "" && ap.BestAskExchange != "" &&
+		ap.BestBidExchange != ap.BestAskExchange && ap.BestBid > ap.BestAsk
+}
+
+// MidPrice restituisce il prezzo medio tra miglior bid e miglior ask consolidati
+func (ap *AggregatedPrice) MidPrice() float64 {
+	return (ap.BestBid + ap.BestAsk) / 2
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// APIKeyInfo rappresenta le informazioni di una API key restituite da Bybit (/v5/user/query-api)
+type APIKeyInfo struct {
+	ID          string              `json:"id"`
+	Note        string              `json:"note"`
+	APIKey      string              `json:"apiKey"`
+	ReadOnly    int                 `json:"readOnly"`    // 0 = lettura e scrittura, 1 = sola lettura
+	Permissions map[string][]string `json:"permissions"` // Gruppo (es. "ContractTrade") -> permessi (es. "Order", "Position")
+	IPs         []string            `json:"ips"`         // IP autorizzati ("*" = nessuna restrizione)
+	Type        int                 `json:"type"`        // 1 = personale, 2 = applicazione di terze parti
+	DeadlineDay int                 `json:"deadlineDay"` // Giorni rimanenti alla scadenza
+	ExpiredAt   string              `json:"expiredAt"`   // Data di scadenza (vuota se la key non scade)
+	CreatedAt   string              `json:"createdAt"`
+	Unified     int                 `json:"unified"`
+	UTA         int                 `json:"uta"`
+	UserID      int64               `json:"userID"` // UID dell'account a cui appartiene la key
+}
+
+// APIKeyInfoResponse rappresenta la risposta completa dell'API Bybit per le informazioni della API key
+type APIKeyInfoResponse struct {
+	RetCode int        `json:"retCode"`
+	RetMsg  string     `json:"retMsg"`
+	Result  APIKeyInfo `json:"result"`
+	Time    int64      `json:"time"`
+}
+
+// IsSuccess verifica se la richiesta è andata a buon fine
+func (r *APIKeyInfoResponse) IsSuccess() bool {
+	return r.RetCode == 0
+}
+
+// IsReadOnly verifica se la API key è di sola lettura
+func (k *APIKeyInfo) IsReadOnly() bool {
+	return k.ReadOnly == 1
+}
+
+// HasPermission verifica se la API key ha il permesso indicato nel gruppo specificato
+func (k *APIKeyInfo) HasPermission(group, permission string) bool {
+	return slices.Contains(k.Permissions[group], permission)
+}
+
+// ExpiresAt restituisce la data di scadenza della API key
+// Restituisce false se la key non ha scadenza (es. key vincolate a IP)
+func (k *APIKeyInfo) ExpiresAt() (time.Time, bool) {
+	if k.ExpiredAt == "" {
+		return time.Time{}, false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, k.ExpiredAt)
+	if err != nil || expiresAt.IsZero() || expiresAt.Year() <= 1970 {
+		return time.Time{}, false
+	}
+	return expiresAt, true
+}
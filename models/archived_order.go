@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// ArchivedOrder rappresenta un ordine chiuso spostato nella tabella di archivio
+// Mantiene le stesse colonne di Order (compreso l'ID originale) per poter essere ripristinato o analizzato;
+// la tabella ha meno indici perché viene interrogata raramente
+type ArchivedOrder struct {
+	ID              uint          `gorm:"primaryKey;autoIncrement:false" json:"id"`
+	OrderID         string        `gorm:"type:varchar(50);not null;uniqueIndex:idx_archive_order_id" json:"order_id"`
+	Symbol          string        `gorm:"type:varchar(20);not null;index:idx_archive_symbol_created,priority:1" json:"symbol"`
+	Side            OrderSideType `gorm:"type:varchar(4);not null" json:"side"`
+	OrderPrice      float64       `gorm:"type:REAL;not null" json:"order_price"`
+	Quantity        float64       `gorm:"type:REAL;not null" json:"quantity"`
+	TakeProfitPrice *float64      `gorm:"type:REAL" json:"take_profit_price"`
+	StopLossPrice   *float64      `gorm:"type:REAL" json:"stop_loss_price"`
+	OrderStatusID   uint          `gorm:"not null" json:"order_status_id"`
+	Result          OrderResult   `gorm:"type:varchar(10)" json:"result"`
+	PnL             float64       `gorm:"column:pnl;type:REAL" json:"pnl"`
+	PnLPercentage   float64       `gorm:"column:pnl_percentage;type:REAL" json:"pnl_percentage"`
+	ParentOrderID   string        `gorm:"type:varchar(50);index:idx_archive_parent_order_id" json:"parent_order_id,omitempty"`
+	ScaleLevel      int           `gorm:"not null;default:0" json:"scale_level"`
+	SignalLatencyMs *int64        `gorm:"column:signal_latency_ms" json:"signal_latency_ms,omitempty"`
+	RejectReason    RejectReason  `gorm:"type:varchar(30);index:idx_archive_reject_reason" json:"reject_reason,omitempty"`
+	ConfigVersion   string        `gorm:"type:varchar(64)" json:"config_version,omitempty"`
+	Version         uint          `gorm:"not null;default:1" json:"version"`
+	CreatedAt       time.Time     `gorm:"type:timestamp;index:idx_archive_symbol_created,priority:2" json:"created_at"`
+	UpdatedAt       time.Time     `gorm:"type:timestamp" json:"updated_at"`
+	ArchivedAt      time.Time     `gorm:"type:timestamp;not null" json:"archived_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (ArchivedOrder) TableName() string {
+	return "orders_archive"
+}
+
+// ToOrder converte l'ordine archiviato in un Order
+func (ao *ArchivedOrder) ToOrder() *Order {
+	return &Order{
+		ID:              ao.ID,
+		OrderID:         ao.OrderID,
+		Symbol:          ao.Symbol,
+		Side:            ao.Side,
+		OrderPrice:      ao.OrderPrice,
+		Quantity:        ao.Quantity,
+		TakeProfitPrice: ao.TakeProfitPrice,
+		StopLossPrice:   ao.StopLossPrice,
+		OrderStatusID:   ao.OrderStatusID,
+		Result:          ao.Result,
+		PnL:             ao.PnL,
+		PnLPercentage:   ao.PnLPercentage,
+		ParentOrderID:   ao.ParentOrderID,
+		ScaleLevel:      ao.ScaleLevel,
+		SignalLatencyMs: ao.SignalLatencyMs,
+		RejectReason:    ao.RejectReason,
+		ConfigVersion:   ao.ConfigVersion,
+		Version:         ao.Version,
+		CreatedAt:       ao.CreatedAt,
+		UpdatedAt:       ao.UpdatedAt,
+	}
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SnapshotSource rappresenta l'origine di uno snapshot di equity
+type SnapshotSource string
+
+const (
+	SnapshotSourceLive     SnapshotSource = "live"
+	SnapshotSourceBacktest SnapshotSource = "backtest"
+)
+
+// BalanceSnapshot rappresenta lo snapshot dell'equity dell'account in un determinato istante
+// La serie temporale degli snapshot è la base per il calcolo delle metriche di performance
+type BalanceSnapshot struct {
+	ID            uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Source        SnapshotSource `gorm:"type:varchar(10);not null;default:'live';index:idx_snapshot_source_taken,priority:1" json:"source"`
+	RunID         string         `gorm:"type:varchar(50);index:idx_snapshot_run_id;comment:ID del backtest (vuoto per live)" json:"run_id,omitempty"`
+	Coin          string         `gorm:"type:varchar(10);not null;default:'USDT'" json:"coin"`
+	Equity        float64        `gorm:"type:REAL;not null;comment:Equity totale dell'account" json:"equity"`
+	WalletBalance float64        `gorm:"type:REAL;comment:Bilancio del wallet" json:"wallet_balance"`
+	TakenAt       time.Time      `gorm:"type:timestamp;not null;index:idx_snapshot_source_taken,priority:2" json:"taken_at"`
+	CreatedAt     time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (BalanceSnapshot) TableName() string {
+	return "balance_snapshots"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (bs *BalanceSnapshot) BeforeCreate(tx *gorm.DB) error {
+	if bs.Equity < 0 {
+		return gorm.ErrInvalidData
+	}
+
+	if bs.Source == "" {
+		bs.Source = SnapshotSourceLive
+	}
+
+	if bs.TakenAt.IsZero() {
+		bs.TakenAt = time.Now().UTC()
+	}
+
+	return nil
+}
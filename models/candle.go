@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 // Market rappresenta il tipo di mercato (spot o derivati)
 type Market string
@@ -25,6 +28,30 @@ const (
 	Timeframe1M  Timeframe = "M"
 )
 
+// Duration restituisce la durata del timeframe; 0 per il mensile, che ha durata variabile
+func (t Timeframe) Duration() time.Duration {
+	switch t {
+	case Timeframe1m:
+		return time.Minute
+	case Timeframe5m:
+		return 5 * time.Minute
+	case Timeframe15m:
+		return 15 * time.Minute
+	case Timeframe30m:
+		return 30 * time.Minute
+	case Timeframe1h:
+		return time.Hour
+	case Timeframe4h:
+		return 4 * time.Hour
+	case Timeframe1d:
+		return 24 * time.Hour
+	case Timeframe1w:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
 // Candle rappresenta una singola candela OHLCV
 type Candle struct {
 	Timestamp time.Time `json:"timestamp"`
@@ -35,8 +62,82 @@ type Candle struct {
 	Volume    float64   `json:"volume"`
 }
 
+// IsClosed indica se la candela del timeframe indicato è chiusa all'istante at
+// Una candela del timeframe mensile, di durata variabile, è considerata aperta
+func (c Candle) IsClosed(timeframe Timeframe, at time.Time) bool {
+	duration := timeframe.Duration()
+	return duration > 0 && !c.Timestamp.Add(duration).After(at)
+}
+
 // CandleResponse rappresenta la risposta paginata delle candele
+// I metadati descrivono il recupero, così chi usa le candele può verificare le proprie attese invece di dedurle
 type CandleResponse struct {
 	Candles []Candle `json:"candles"`
 	HasMore bool     `json:"has_more"`
+
+	Exchange   string        `json:"exchange"`
+	Symbol     string        `json:"symbol"`
+	Market     Market        `json:"market"`
+	Timeframe  Timeframe     `json:"timeframe"`
+	Requested  int           `json:"requested"`   // Candele richieste
+	FetchedAt  time.Time     `json:"fetched_at"`  // Inizio del recupero
+	Elapsed    time.Duration `json:"elapsed"`     // Durata del recupero, paginazione compresa
+	LastClosed bool          `json:"last_closed"` // La candela più recente era già chiusa al termine del recupero
+}
+
+// NewCandleResponse crea la risposta di un recupero di candele che inizia ora
+func NewCandleResponse(exchange, symbol string, market Market, timeframe Timeframe, requested int) *CandleResponse {
+	return &CandleResponse{
+		Candles:   make([]Candle, 0, max(requested, 0)),
+		Exchange:  exchange,
+		Symbol:    symbol,
+		Market:    market,
+		Timeframe: timeframe,
+		Requested: requested,
+		FetchedAt: time.Now(),
+	}
+}
+
+// Finish completa i metadati al termine del recupero: durata e stato della candela più recente
+func (r *CandleResponse) Finish() {
+	now := time.Now()
+	r.Elapsed = now.Sub(r.FetchedAt)
+	if latest, ok := r.Latest(); ok {
+		r.LastClosed = latest.IsClosed(r.Timeframe, now)
+	}
+}
+
+// Returned restituisce il numero di candele ricevute
+func (r *CandleResponse) Returned() int {
+	return len(r.Candles)
+}
+
+// Complete indica se sono state ricevute tutte le candele richieste
+func (r *CandleResponse) Complete() bool {
+	return len(r.Candles) >= r.Requested
+}
+
+// Latest restituisce la candela più recente, indipendentemente dall'ordine dell'elenco
+func (r *CandleResponse) Latest() (Candle, bool) {
+	if len(r.Candles) == 0 {
+		return Candle{}, false
+	}
+	latest := r.Candles[0]
+	for _, candle := range r.Candles[1:] {
+		if candle.Timestamp.After(latest.Timestamp) {
+			latest = candle
+		}
+	}
+	return latest, true
+}
+
+// Closed restituisce in ordine cronologico le candele chiuse, scartando la più recente se era ancora aperta
+// Le candele della risposta non vengono modificate
+func (r *CandleResponse) Closed() []Candle {
+	candles := append([]Candle(nil), r.Candles...)
+	sort.Slice(candles, func(i, j int) bool { return candles[i].Timestamp.Before(candles[j].Timestamp) })
+	if len(candles) > 0 && !r.LastClosed {
+		candles = candles[:len(candles)-1]
+	}
+	return candles
 }
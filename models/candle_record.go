@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CandleRecord rappresenta una candela chiusa salvata nella cache locale
+// La cache conserva la storia usata dal worker per ricostruire le feature dei segnali passati
+type CandleRecord struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Symbol    string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_candle_key,priority:1" json:"symbol"`
+	Market    Market    `gorm:"type:varchar(20);not null;uniqueIndex:idx_candle_key,priority:2" json:"market"`
+	Timeframe Timeframe `gorm:"type:varchar(5);not null;uniqueIndex:idx_candle_key,priority:3" json:"timeframe"`
+	OpenTime  time.Time `gorm:"type:timestamp;not null;uniqueIndex:idx_candle_key,priority:4" json:"open_time"`
+	Open      float64   `gorm:"type:REAL;not null" json:"open"`
+	High      float64   `gorm:"type:REAL;not null" json:"high"`
+	Low       float64   `gorm:"type:REAL;not null" json:"low"`
+	Close     float64   `gorm:"type:REAL;not null" json:"close"`
+	Volume    float64   `gorm:"type:REAL;not null" json:"volume"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (CandleRecord) TableName() string {
+	return "candles"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (cr *CandleRecord) BeforeCreate(tx *gorm.DB) error {
+	if cr.Symbol == "" || cr.OpenTime.IsZero() || cr.High < cr.Low {
+		return gorm.ErrInvalidData
+	}
+	return nil
+}
+
+// NewCandleRecord crea il record di cache di una candela
+func NewCandleRecord(symbol string, market Market, timeframe Timeframe, candle Candle) *CandleRecord {
+	return &CandleRecord{
+		Symbol:    symbol,
+		Market:    market,
+		Timeframe: timeframe,
+		OpenTime:  candle.Timestamp.UTC(),
+		Open:      candle.Open,
+		High:      candle.High,
+		Low:       candle.Low,
+		Close:     candle.Close,
+		Volume:    candle.Volume,
+	}
+}
+
+// ToCandle converte il record nella candela usata dalla strategia
+func (cr *CandleRecord) ToCandle() Candle {
+	return Candle{
+		Timestamp: cr.OpenTime,
+		Open:      cr.Open,
+		High:      cr.High,
+		Low:       cr.Low,
+		Close:     cr.Close,
+		Volume:    cr.Volume,
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CashFlowType distingue i movimenti esterni in entrata e in uscita dall'account di trading
+type CashFlowType string
+
+const (
+	CashFlowDeposit    CashFlowType = "deposit"    // Fondi entrati nell'account (deposito o trasferimento in entrata)
+	CashFlowWithdrawal CashFlowType = "withdrawal" // Fondi usciti dall'account (prelievo o trasferimento in uscita)
+)
+
+// CashFlow rappresenta un movimento esterno del wallet, letto dal transaction log dell'exchange
+// Depositi e prelievi cambiano l'equity senza essere PnL: le metriche di performance li escludono dai rendimenti
+type CashFlow struct {
+	ID            uint         `gorm:"primaryKey;autoIncrement" json:"id"`
+	Exchange      string       `gorm:"type:varchar(20);not null;uniqueIndex:idx_cash_flow_exchange_tx,priority:1" json:"exchange"`
+	TransactionID string       `gorm:"type:varchar(100);not null;uniqueIndex:idx_cash_flow_exchange_tx,priority:2;comment:ID del movimento sull'exchange" json:"transaction_id"`
+	Coin          string       `gorm:"type:varchar(10);not null;default:'USDT'" json:"coin"`
+	Type          CashFlowType `gorm:"type:varchar(10);not null" json:"type"`
+	Amount        float64      `gorm:"type:REAL;not null;comment:Importo con segno (positivo in entrata)" json:"amount"`
+	OccurredAt    time.Time    `gorm:"type:timestamp;not null;index:idx_cash_flow_occurred_at" json:"occurred_at"`
+	CreatedAt     time.Time    `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (CashFlow) TableName() string {
+	return "cash_flows"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (cf *CashFlow) BeforeCreate(tx *gorm.DB) error {
+	if cf.Exchange == "" || cf.TransactionID == "" || cf.Amount == 0 || cf.OccurredAt.IsZero() {
+		return gorm.ErrInvalidData
+	}
+	if cf.Type != CashFlowDeposit && cf.Type != CashFlowWithdrawal {
+		return gorm.ErrInvalidData
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ConfigVersion è una versione salvata della configurazione di strategia e rischio
+// Viene registrata ad ogni avvio dei worker; gli ordini riportano la versione attiva alla loro creazione
+type ConfigVersion struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Hash          string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_config_version_hash" json:"hash"`
+	Settings      string    `gorm:"type:text;not null;comment:Parametri effettivi in JSON" json:"settings"`
+	Env           string    `gorm:"type:text;not null;comment:Variabili d'ambiente impostate in JSON, usate per il rollback" json:"env"`
+	CreatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	LastAppliedAt time.Time `gorm:"type:timestamp;not null;index:idx_config_version_applied" json:"last_applied_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (ConfigVersion) TableName() string {
+	return "config_versions"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (cv *ConfigVersion) BeforeCreate(tx *gorm.DB) error {
+	if cv.Hash == "" || cv.LastAppliedAt.IsZero() {
+		return gorm.ErrInvalidData
+	}
+	return nil
+}
+
+// ShortHash restituisce le prime 12 cifre dell'hash, usate nei log e nei comandi
+func (cv *ConfigVersion) ShortHash() string {
+	if len(cv.Hash) <= 12 {
+		return cv.Hash
+	}
+	return cv.Hash[:12]
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Nomi delle sorgenti dati esterne
+const (
+	DataSourceFearGreed = "fear_greed" // Crypto Fear & Greed Index (0 = paura estrema, 100 = avidità estrema)
+)
+
+// DataPoint rappresenta una rilevazione di una sorgente dati esterna (es. indici di sentiment)
+// Ogni sorgente ha al più una rilevazione per istante, così le importazioni ripetute non creano duplicati
+type DataPoint struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Source     string    `gorm:"type:varchar(30);not null;uniqueIndex:idx_data_point_source_observed,priority:1" json:"source"`
+	Value      float64   `gorm:"type:REAL;not null" json:"value"`
+	Label      string    `gorm:"type:varchar(30);comment:Classificazione testuale del valore (es. Extreme Fear)" json:"label,omitempty"`
+	ObservedAt time.Time `gorm:"type:timestamp;not null;uniqueIndex:idx_data_point_source_observed,priority:2" json:"observed_at"`
+	CreatedAt  time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (DataPoint) TableName() string {
+	return "data_points"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (dp *DataPoint) BeforeCreate(tx *gorm.DB) error {
+	if dp.Source == "" || dp.ObservedAt.IsZero() {
+		return gorm.ErrInvalidData
+	}
+	return nil
+}
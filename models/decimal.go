@@ -0,0 +1,57 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// decimalPlaces sono le cifre decimali oltre cui un float64 contiene solo errori di rappresentazione
+// (es. 0.3 - 0.1 = 0.19999999999999998): i valori vengono arrotondati a queste cifre prima di ogni calcolo
+const decimalPlaces = 10
+
+// Decimal converte un float64 in decimale scartando gli errori di rappresentazione binaria
+func Decimal(value float64) decimal.Decimal {
+	return decimal.NewFromFloat(value).Round(decimalPlaces)
+}
+
+// ParseDecimal converte un valore numerico restituito dall'exchange come stringa; vuoto o non valido vale zero
+func ParseDecimal(value string) decimal.Decimal {
+	if value == "" {
+		return decimal.Zero
+	}
+	parsed, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero
+	}
+	return parsed
+}
+
+// FloorToStep arrotonda value per difetto a un multiplo di step; step non positivo lascia value invariato
+// Il calcolo è decimale: 0.3 con passo 0.1 resta 0.3 e il risultato non ha residui binari
+func FloorToStep(value, step float64) float64 {
+	if step <= 0 {
+		return value
+	}
+	s := Decimal(step)
+	return Decimal(value).Div(s).Floor().Mul(s).InexactFloat64()
+}
+
+// RoundToStep arrotonda value al multiplo di step più vicino; step non positivo lascia value invariato
+func RoundToStep(value, step float64) float64 {
+	if step <= 0 {
+		return value
+	}
+	s := Decimal(step)
+	return Decimal(value).Div(s).Round(0).Mul(s).InexactFloat64()
+}
+
+// FormatStep arrotonda value al multiplo di step più vicino e lo formatta con le sole cifre decimali di step
+// (es. passo 0.0001 → "0.1881"); step non positivo formatta value con le cifre strettamente necessarie
+func FormatStep(value, step float64) string {
+	if step <= 0 {
+		return Decimal(value).String()
+	}
+	// Le cifre decimali sono quelle della rappresentazione più breve del passo (0.0001 → 4)
+	places := max(-decimal.NewFromFloat(step).Exponent(), 0)
+	s := Decimal(step)
+	return Decimal(value).Div(s).Round(0).Mul(s).StringFixed(places)
+}
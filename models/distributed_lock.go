@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// DistributedLock è un lease salvato nel database che assegna una risorsa (es. il trading di un simbolo
+// su un account) ad una sola istanza del bot; scaduto il lease un'altra istanza può acquisirlo
+type DistributedLock struct {
+	Key        string    `gorm:"primaryKey;type:varchar(191)" json:"key"`
+	Owner      string    `gorm:"type:varchar(100);not null" json:"owner"`
+	ExpiresAt  time.Time `gorm:"type:timestamp;not null" json:"expires_at"`
+	AcquiredAt time.Time `gorm:"type:timestamp;not null" json:"acquired_at"`
+	UpdatedAt  time.Time `gorm:"type:timestamp;not null" json:"updated_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (DistributedLock) TableName() string {
+	return "distributed_locks"
+}
@@ -4,14 +4,15 @@ import "time"
 
 // Execution rappresenta un singolo trade/esecuzione
 type Execution struct {
-	Symbol      string    `json:"symbol" gorm:"column:symbol"`
+	ID          uint      `json:"id,omitempty" gorm:"primaryKey;autoIncrement"`
+	Symbol      string    `json:"symbol" gorm:"column:symbol;index:idx_execution_symbol_time,priority:1"`
 	Side        string    `json:"side" gorm:"column:side"`
-	OrderID     string    `json:"orderId" gorm:"column:order_id"`
-	ExecID      string    `json:"execId" gorm:"column:exec_id"`
+	OrderID     string    `json:"orderId" gorm:"column:order_id;index:idx_execution_order_id"`
+	ExecID      string    `json:"execId" gorm:"column:exec_id;type:varchar(50);not null;uniqueIndex:idx_exec_id"`
 	Price       float64   `json:"price" gorm:"column:price"`
 	Qty         float64   `json:"qty" gorm:"column:qty"`
 	ExecType    string    `json:"execType" gorm:"column:exec_type"`
-	ExecTime    time.Time `json:"execTime" gorm:"column:exec_time"`
+	ExecTime    time.Time `json:"execTime" gorm:"column:exec_time;index:idx_execution_symbol_time,priority:2"`
 	IsMaker     bool      `json:"isMaker" gorm:"column:is_maker"`
 	Fee         float64   `json:"fee" gorm:"column:fee"`
 	FeeCurrency string    `json:"feeCurrency" gorm:"column:fee_currency"`
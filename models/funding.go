@@ -0,0 +1,144 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// hoursPerYear è usato per annualizzare i tassi di funding
+const hoursPerYear = 365 * 24
+
+// FundingRate rappresenta il tasso di funding corrente di un perpetual
+type FundingRate struct {
+	Exchange        string    `json:"exchange"`
+	Symbol          string    `json:"symbol"`
+	Rate            float64   `json:"rate"`           // Tasso relativo pagato ad ogni intervallo (0.0001 = 0.01%)
+	IntervalHours   float64   `json:"interval_hours"` // Ore tra due pagamenti di funding (8 su Bybit, 1 su Kraken)
+	NextFundingTime time.Time `json:"next_funding_time"`
+	MarkPrice       float64   `json:"mark_price"`
+	IndexPrice      float64   `json:"index_price"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// AnnualizedRate restituisce il tasso di funding annualizzato (senza capitalizzazione)
+// Permette di confrontare venue con intervalli di funding diversi
+func (fr *FundingRate) AnnualizedRate() float64 {
+	if fr.IntervalHours <= 0 {
+		return 0
+	}
+	return fr.Rate * hoursPerYear / fr.IntervalHours
+}
+
+// FundingArbStatus rappresenta lo stato di una posizione di funding arbitrage
+type FundingArbStatus string
+
+const (
+	FundingArbStatusOpening  FundingArbStatus = "opening"  // Gamba spot eseguita, perpetual in corso
+	FundingArbStatusOpen     FundingArbStatus = "open"     // Entrambe le gambe aperte, posizione coperta
+	FundingArbStatusClosing  FundingArbStatus = "closing"  // Perpetual chiuso, vendita spot in corso
+	FundingArbStatusClosed   FundingArbStatus = "closed"   // Entrambe le gambe chiuse
+	FundingArbStatusFailed   FundingArbStatus = "failed"   // Apertura fallita e gamba spot rientrata
+	FundingArbStatusUnhedged FundingArbStatus = "unhedged" // Una sola gamba aperta: richiede intervento
+)
+
+// FundingArbPosition rappresenta una posizione coperta: long spot su una venue, short perpetual su un'altra
+type FundingArbPosition struct {
+	ID               uint             `gorm:"primaryKey;autoIncrement" json:"id"`
+	Symbol           string           `gorm:"type:varchar(20);not null;index:idx_funding_arb_symbol_status,priority:1" json:"symbol"`
+	SpotExchange     string           `gorm:"type:varchar(20);not null" json:"spot_exchange"`
+	PerpExchange     string           `gorm:"type:varchar(20);not null" json:"perp_exchange"`
+	Status           FundingArbStatus `gorm:"type:varchar(10);not null;index:idx_funding_arb_symbol_status,priority:2" json:"status"`
+	Quantity         float64          `gorm:"type:REAL;not null;comment:Quantità in base coin di entrambe le gambe" json:"quantity"`
+	SpotOrderID      string           `gorm:"type:varchar(50)" json:"spot_order_id"`
+	PerpOrderID      string           `gorm:"type:varchar(50)" json:"perp_order_id"`
+	SpotEntryPrice   float64          `gorm:"type:REAL" json:"spot_entry_price"`
+	PerpEntryPrice   float64          `gorm:"type:REAL" json:"perp_entry_price"`
+	EntryBasis       float64          `gorm:"type:REAL;comment:(perp - spot) / spot all'apertura" json:"entry_basis"`
+	EntryFundingAPR  float64          `gorm:"column:entry_funding_apr;type:REAL" json:"entry_funding_apr"`
+	SpotExitPrice    *float64         `gorm:"type:REAL" json:"spot_exit_price,omitempty"`
+	PerpExitPrice    *float64         `gorm:"type:REAL" json:"perp_exit_price,omitempty"`
+	ExitBasis        *float64         `gorm:"type:REAL" json:"exit_basis,omitempty"`
+	FundingCollected float64          `gorm:"type:REAL;not null;default:0;comment:Funding stimato incassato dalla gamba short" json:"funding_collected"`
+	BorrowCost       float64          `gorm:"type:REAL;not null;default:0;comment:Interessi stimati sul prestito della gamba spot a margine" json:"borrow_cost"`
+	PnL              *float64         `gorm:"column:pnl;type:REAL;comment:PnL delle due gambe più il funding, al netto degli interessi" json:"pnl,omitempty"`
+	Note             string           `gorm:"type:text" json:"note,omitempty"`
+	OpenedAt         time.Time        `gorm:"type:timestamp;not null" json:"opened_at"`
+	ClosedAt         *time.Time       `gorm:"type:timestamp" json:"closed_at,omitempty"`
+	CreatedAt        time.Time        `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt        time.Time        `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (FundingArbPosition) TableName() string {
+	return "funding_arb_positions"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (p *FundingArbPosition) BeforeCreate(tx *gorm.DB) error {
+	if p.Quantity <= 0 || p.Symbol == "" || p.SpotExchange == "" || p.PerpExchange == "" {
+		return gorm.ErrInvalidData
+	}
+	if p.Status == "" {
+		p.Status = FundingArbStatusOpening
+	}
+	if p.OpenedAt.IsZero() {
+		p.OpenedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// IsActive verifica se la posizione ha almeno una gamba aperta
+func (p *FundingArbPosition) IsActive() bool {
+	return p.Status == FundingArbStatusOpening || p.Status == FundingArbStatusOpen ||
+		p.Status == FundingArbStatusClosing || p.Status == FundingArbStatusUnhedged
+}
+
+// CalculatePnL calcola il PnL complessivo: long spot + short perpetual + funding incassato - interessi sul prestito
+func (p *FundingArbPosition) CalculatePnL(spotExit, perpExit float64) float64 {
+	quantity := Decimal(p.Quantity)
+	spotPnL := Decimal(spotExit).Sub(Decimal(p.SpotEntryPrice)).Mul(quantity)
+	perpPnL := Decimal(p.PerpEntryPrice).Sub(Decimal(perpExit)).Mul(quantity)
+	return spotPnL.Add(perpPnL).Add(Decimal(p.FundingCollected)).Sub(Decimal(p.BorrowCost)).InexactFloat64()
+}
+
+// BasisSnapshot rappresenta una rilevazione di basis e funding tra una venue spot e una perpetual
+type BasisSnapshot struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Symbol        string    `gorm:"type:varchar(20);not null;index:idx_basis_symbol_taken,priority:1" json:"symbol"`
+	SpotExchange  string    `gorm:"type:varchar(20);not null" json:"spot_exchange"`
+	PerpExchange  string    `gorm:"type:varchar(20);not null" json:"perp_exchange"`
+	SpotPrice     float64   `gorm:"type:REAL;not null" json:"spot_price"`
+	PerpPrice     float64   `gorm:"type:REAL;not null" json:"perp_price"`
+	Basis         float64   `gorm:"type:REAL;not null;comment:(perp - spot) / spot" json:"basis"`
+	FundingRate   float64   `gorm:"type:REAL;not null;comment:Tasso per intervallo" json:"funding_rate"`
+	IntervalHours float64   `gorm:"type:REAL" json:"interval_hours"`
+	FundingAPR    float64   `gorm:"column:funding_apr;type:REAL;not null" json:"funding_apr"`
+	PositionID    *uint     `gorm:"index:idx_basis_position_id" json:"position_id,omitempty"`
+	TakenAt       time.Time `gorm:"type:timestamp;not null;index:idx_basis_symbol_taken,priority:2" json:"taken_at"`
+	CreatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (BasisSnapshot) TableName() string {
+	return "basis_snapshots"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (bs *BasisSnapshot) BeforeCreate(tx *gorm.DB) error {
+	if bs.SpotPrice <= 0 || bs.PerpPrice <= 0 {
+		return gorm.ErrInvalidData
+	}
+	if bs.TakenAt.IsZero() {
+		bs.TakenAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// CalculateBasis restituisce il basis relativo del perpetual rispetto allo spot
+func CalculateBasis(spotPrice, perpPrice float64) float64 {
+	if spotPrice <= 0 {
+		return 0
+	}
+	return (perpPrice - spotPrice) / spotPrice
+}
@@ -0,0 +1,12 @@
+package models
+
+// InstrumentInfo contiene le regole di negoziazione di un simbolo derivati
+type InstrumentInfo struct {
+	Symbol       string  `json:"symbol"`
+	MinOrderQty  float64 `json:"min_order_qty"`  // Quantità minima di un ordine
+	MaxMarketQty float64 `json:"max_market_qty"` // Quantità massima di un ordine a mercato
+	QtyStep      float64 `json:"qty_step"`       // Passo della quantità
+	MinNotional  float64 `json:"min_notional"`   // Controvalore minimo di un ordine (USDT); 0 se non previsto
+	TickSize     float64 `json:"tick_size"`      // Passo del prezzo
+	MaxLeverage  float64 `json:"max_leverage"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OperatorChannel rappresenta il canale da cui è arrivata un'azione manuale
+type OperatorChannel string
+
+const (
+	OperatorChannelAPI OperatorChannel = "api" // REST API
+	OperatorChannelCLI OperatorChannel = "cli" // Sottocomandi di mkybot
+)
+
+// OperatorOutcome rappresenta l'esito di un'azione manuale
+type OperatorOutcome string
+
+const (
+	OperatorOutcomeSuccess OperatorOutcome = "success"
+	OperatorOutcomeFailed  OperatorOutcome = "failed"
+)
+
+// OperatorAudit rappresenta un'azione manuale di un operatore (pausa di un worker, modifica della configurazione, ...)
+// Affianca l'audit trail degli ordini: registra chi ha fatto cosa e quando, anche se l'azione non tocca alcun ordine
+type OperatorAudit struct {
+	ID            uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	Actor         string          `gorm:"type:varchar(100);not null;index:idx_operator_audit_actor;comment:Nome del token REST o utente di sistema della CLI" json:"actor"`
+	Channel       OperatorChannel `gorm:"type:varchar(10);not null" json:"channel"`
+	Action        string          `gorm:"type:varchar(100);not null;index:idx_operator_audit_action;comment:Route REST (es. POST /workers/{name}/pause) o sottocomando" json:"action"`
+	Target        string          `gorm:"type:varchar(200);comment:Oggetto dell'azione (es. path della richiesta, versione, strategia)" json:"target,omitempty"`
+	Details       string          `gorm:"type:text;comment:Parametri dell'azione in JSON" json:"details,omitempty"`
+	Outcome       OperatorOutcome `gorm:"type:varchar(10);not null" json:"outcome"`
+	Error         string          `gorm:"type:text" json:"error,omitempty"`
+	CorrelationID string          `gorm:"type:varchar(64);index:idx_operator_audit_correlation_id" json:"correlation_id,omitempty"` // Richiesta REST che ha eseguito l'azione
+	CreatedAt     time.Time       `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index:idx_operator_audit_created_at" json:"created_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (OperatorAudit) TableName() string {
+	return "operator_audit"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (oa *OperatorAudit) BeforeCreate(tx *gorm.DB) error {
+	if oa.Actor == "" || oa.Channel == "" || oa.Action == "" || oa.Outcome == "" {
+		return gorm.ErrInvalidData
+	}
+	return nil
+}
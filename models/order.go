@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -36,6 +37,8 @@ const (
 	OrderResultLoss    OrderResult = "Loss"
 	OrderResultPending OrderResult = "Pending"
 	OrderResultDone    OrderResult = "Done"
+
+	OrderResultRejected OrderResult = "Rejected" // Ordine rifiutato dall'exchange, registrato solo per analisi
 )
 
 // Order rappresenta un ordine di trading nel sistema
@@ -62,8 +65,24 @@ type Order struct {
 	Result        OrderResult        `gorm:"type:varchar(10);default:'Pending';index:idx_result;comment:Risultato finale dell'ordine" json:"result"`
 
 	// Metadati aggiuntivi per analisi
-	PnL           float64 `gorm:"type:REAL;default:0.00000000;index:idx_pnl;comment:Profit and Loss calcolato" json:"pnl"`
-	PnLPercentage float64 `gorm:"type:REAL;default:0.0000;index:idx_pnl_percentage;comment:PnL in percentuale" json:"pnl_percentage"`
+	PnL           float64 `gorm:"column:pnl;type:REAL;default:0.00000000;index:idx_pnl;comment:Profit and Loss calcolato" json:"pnl"`
+	PnLPercentage float64 `gorm:"column:pnl_percentage;type:REAL;default:0.0000;index:idx_pnl_percentage;comment:PnL in percentuale" json:"pnl_percentage"`
+
+	// Pyramiding: gli incrementi di una posizione sono collegati all'ordine di ingresso iniziale
+	ParentOrderID string `gorm:"type:varchar(50);index:idx_parent_order_id;comment:Ordine di ingresso iniziale della posizione incrementata" json:"parent_order_id,omitempty"`
+	ScaleLevel    int    `gorm:"not null;default:0;comment:0 = ingresso iniziale, 1..N = incrementi della posizione" json:"scale_level"`
+
+	// Millisecondi dalla chiusura della candela del segnale alla conferma dell'ordine da parte dell'exchange; nil se non misurati
+	SignalLatencyMs *int64 `gorm:"column:signal_latency_ms;comment:Millisecondi dal segnale alla conferma dell'ordine" json:"signal_latency_ms,omitempty"`
+
+	// Motivo del rifiuto da parte dell'exchange; vuoto per gli ordini accettati
+	RejectReason RejectReason `gorm:"type:varchar(30);index:idx_reject_reason;comment:Motivo del rifiuto da parte dell'exchange" json:"reject_reason,omitempty"`
+
+	// Versione della configurazione di strategia attiva alla creazione (hash di ConfigVersion)
+	ConfigVersion string `gorm:"type:varchar(64);index:idx_config_version;comment:Hash della configurazione di strategia" json:"config_version,omitempty"`
+
+	// Versione per optimistic locking: incrementata ad ogni aggiornamento
+	Version uint `gorm:"not null;default:1;comment:Versione per optimistic locking" json:"version"`
 
 	// Timestamps
 	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index:idx_created_at" json:"created_at"`
@@ -87,8 +106,12 @@ func (o *Order) BeforeCreate(tx *gorm.DB) error {
 		return gorm.ErrInvalidData
 	}
 
+	if o.Version == 0 {
+		o.Version = 1
+	}
+
 	// Validazione result
-	if o.Result != OrderResultProfit && o.Result != OrderResultLoss && o.Result != OrderResultPending {
+	if o.Result != OrderResultProfit && o.Result != OrderResultLoss && o.Result != OrderResultPending && o.Result != OrderResultRejected {
 		o.Result = OrderResultPending
 	}
 
@@ -172,16 +195,18 @@ func (o *Order) IsLosing() bool {
 	return o.Result == OrderResultLoss
 }
 
-// CalculatePnL calcola il PnL basato sui prezzi (da implementare con logica specifica)
+// CalculatePnL calcola il PnL basato sui prezzi, con aritmetica decimale per non perdere precisione
+// sulle monete con prezzo molto basso
 func (o *Order) CalculatePnL(currentPrice float64) {
-	if o.Side == OrderSideTypeBuy {
-		o.PnL = (currentPrice - o.OrderPrice) * o.Quantity
-	} else {
-		o.PnL = (o.OrderPrice - currentPrice) * o.Quantity
+	entry, quantity := Decimal(o.OrderPrice), Decimal(o.Quantity)
+	pnl := Decimal(currentPrice).Sub(entry).Mul(quantity)
+	if o.Side != OrderSideTypeBuy {
+		pnl = pnl.Neg()
 	}
+	o.PnL = pnl.InexactFloat64()
 
-	if o.OrderPrice != 0 {
-		o.PnLPercentage = (o.PnL / (o.OrderPrice * o.Quantity)) * 100
+	if cost := entry.Mul(quantity); !cost.IsZero() {
+		o.PnLPercentage = pnl.Div(cost).Mul(decimal.NewFromInt(100)).InexactFloat64()
 	}
 }
 
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
@@ -8,18 +10,40 @@ import (
 
 // OrderAudit rappresenta un record di audit per tracciare le modifiche agli ordini
 type OrderAudit struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	OrderID   string    `gorm:"type:varchar(50);not null;index:idx_audit_order_id" json:"order_id"`
-	FieldName string    `gorm:"type:varchar(50);not null;index:idx_field_name" json:"field_name"`
-	OldValue  *string   `gorm:"type:text" json:"old_value"`
-	NewValue  *string   `gorm:"type:text" json:"new_value"`
-	ChangedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index:idx_changed_at" json:"changed_at"`
-	ChangedBy string    `gorm:"type:varchar(100);default:'system'" json:"changed_by"`
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrderID       string    `gorm:"type:varchar(50);not null;index:idx_audit_order_id" json:"order_id"`
+	FieldName     string    `gorm:"type:varchar(50);not null;index:idx_field_name" json:"field_name"`
+	OldValue      *string   `gorm:"type:text" json:"old_value"`
+	NewValue      *string   `gorm:"type:text" json:"new_value"`
+	ChangedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index:idx_changed_at" json:"changed_at"`
+	ChangedBy     string    `gorm:"type:varchar(100);default:'system'" json:"changed_by"`
+	CorrelationID string    `gorm:"type:varchar(64);index:idx_audit_correlation_id" json:"correlation_id,omitempty"` // Ciclo del worker o richiesta REST che ha generato la modifica
 
 	// Relazione con Order (opzionale per query) - senza foreign key per evitare dipendenza circolare
 	Order *Order `gorm:"-" json:"order,omitempty"`
 }
 
+// AuditDiff rappresenta una modifica dell'audit trail con valori tipizzati
+// Before/After contengono numeri, stringhe o oggetti a seconda del campo modificato
+type AuditDiff struct {
+	ID            uint        `json:"id"`
+	OrderID       string      `json:"order_id"`
+	Field         string      `json:"field"`
+	Before        interface{} `json:"before"`
+	After         interface{} `json:"after"`
+	BeforeLabel   string      `json:"before_label,omitempty"` // Descrizione leggibile (es. nome dello stato)
+	AfterLabel    string      `json:"after_label,omitempty"`
+	ChangedAt     time.Time   `json:"changed_at"`
+	ChangedBy     string      `json:"changed_by"`
+	CorrelationID string      `json:"correlation_id,omitempty"` // Collega la modifica ai log del ciclo o della richiesta
+}
+
+// PnLAuditValue rappresenta il valore di un record di audit pnl_update
+type PnLAuditValue struct {
+	PnL           float64 `json:"pnl"`
+	PnLPercentage float64 `json:"pnl_percentage"`
+}
+
 // TableName specifica il nome della tabella per GORM
 func (OrderAudit) TableName() string {
 	return "order_audit"
@@ -81,3 +105,43 @@ func (oa *OrderAudit) SetNewValue(value string) {
 func (oa *OrderAudit) String() string {
 	return oa.OrderID + " - " + oa.FieldName
 }
+
+// ToDiff converte il record di audit in una diff con valori tipizzati
+func (oa *OrderAudit) ToDiff() *AuditDiff {
+	return &AuditDiff{
+		ID:            oa.ID,
+		OrderID:       oa.OrderID,
+		Field:         oa.FieldName,
+		Before:        parseAuditValue(oa.FieldName, oa.OldValue),
+		After:         parseAuditValue(oa.FieldName, oa.NewValue),
+		ChangedAt:     oa.ChangedAt,
+		ChangedBy:     oa.ChangedBy,
+		CorrelationID: oa.CorrelationID,
+	}
+}
+
+// parseAuditValue converte il valore salvato come stringa nel tipo del campo
+// Se il valore non è interpretabile viene restituita la stringa originale
+func parseAuditValue(fieldName string, value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	switch fieldName {
+	case "order_price", "take_profit_price", "stop_loss_price", "quantity", "pnl", "pnl_percentage":
+		if parsed, err := strconv.ParseFloat(*value, 64); err == nil {
+			return parsed
+		}
+	case "order_status_id":
+		if parsed, err := strconv.ParseUint(*value, 10, 64); err == nil {
+			return uint(parsed)
+		}
+	case "pnl_update":
+		var pnl PnLAuditValue
+		if _, err := fmt.Sscanf(*value, "PnL: %f, PnL%%: %f", &pnl.PnL, &pnl.PnLPercentage); err == nil {
+			return pnl
+		}
+	}
+
+	return *value
+}
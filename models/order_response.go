@@ -37,6 +37,9 @@ const (
 	OrderStatusRejected        OrderStatus = "Rejected"
 	OrderStatusUntriggered     OrderStatus = "Untriggered"
 	OrderStatusTriggered       OrderStatus = "Triggered"
+
+	OrderStatusPartiallyFilledCanceled OrderStatus = "PartiallyFilledCanceled"
+	OrderStatusDeactivated             OrderStatus = "Deactivated"
 )
 
 // TimeInForce rappresenta la durata dell'ordine
@@ -46,6 +49,8 @@ const (
 	TimeInForceGTC TimeInForce = "GTC" // Good Till Cancelled
 	TimeInForceIOC TimeInForce = "IOC" // Immediate Or Cancel
 	TimeInForceFOK TimeInForce = "FOK" // Fill Or Kill
+
+	TimeInForcePostOnly TimeInForce = "PostOnly" // Annullato se eseguirebbe subito: l'ordine resta maker
 )
 
 // TriggerDirection rappresenta la direzione del trigger per ordini condizionali
@@ -64,6 +69,25 @@ const (
 	TriggerTypeMark  TriggerType = "MarkPrice"
 )
 
+// RejectReason classifica il motivo per cui l'exchange ha rifiutato un ordine
+// Il codice e il messaggio originali restano in ErrorCode ed ErrorMessage; la classificazione
+// permette di aggregare i rifiuti per causa
+type RejectReason string
+
+const (
+	RejectReasonInsufficientBalance RejectReason = "insufficient_balance" // Saldo o margine insufficiente
+	RejectReasonPriceOutOfBounds    RejectReason = "price_out_of_bounds"  // Prezzo fuori dai limiti ammessi dall'exchange
+	RejectReasonQtyPrecision        RejectReason = "qty_precision"        // Quantità fuori dal passo o dai limiti del simbolo
+	RejectReasonRiskLimit           RejectReason = "risk_limit"           // Posizione oltre il risk limit
+	RejectReasonOther               RejectReason = "other"                // Rifiuto non classificato
+)
+
+// Retryable indica se un nuovo tentativo può avere esito diverso: i rifiuti classificati dipendono
+// dall'ordine o dall'account e si ripeterebbero identici, quelli non classificati (timeout, rate limit) no
+func (r RejectReason) Retryable() bool {
+	return r == "" || r == RejectReasonOther
+}
+
 // OrderRequest rappresenta una richiesta di ordine per Bybit
 type OrderRequest struct {
 	Category         string           `json:"category"`                   // "linear" per derivatives perpetual
@@ -80,26 +104,31 @@ type OrderRequest struct {
 	OrderLinkId      string           `json:"orderLinkId,omitempty"`      // ID cliente per tracking
 	TriggerBy        TriggerType      `json:"triggerBy,omitempty"`        // Tipo trigger (LastPrice, IndexPrice, MarkPrice)
 	ReduceOnly       bool             `json:"reduceOnly,omitempty"`       // Reduce Only
+	MarketUnit       string           `json:"marketUnit,omitempty"`       // Unità della quantità per gli ordini spot a mercato (baseCoin o quoteCoin)
 }
 
 // OrderResponse rappresenta la risposta di un ordine piazzato
 type OrderResponse struct {
-	OrderID      string      `json:"orderId"`
-	OrderLinkID  string      `json:"orderLinkId"`
-	Symbol       string      `json:"symbol"`
-	Side         OrderSide   `json:"side"`
-	OrderType    OrderType   `json:"orderType"`
-	Price        float64     `json:"price"`
-	AveragePrice float64     `json:"avgPrice"`
-	Quantity     float64     `json:"qty"`
-	Status       OrderStatus `json:"orderStatus"`
-	TriggerPrice float64     `json:"triggerPrice,omitempty"`
-	StopLoss     float64     `json:"stopLoss,omitempty"`
-	TakeProfit   float64     `json:"takeProfit,omitempty"`
-	CreatedTime  time.Time   `json:"createdTime"`
-	UpdatedTime  time.Time   `json:"updatedTime"`
-	ErrorCode    string      `json:"retCode,omitempty"`
-	ErrorMessage string      `json:"retMsg,omitempty"`
+	OrderID        string      `json:"orderId"`
+	OrderLinkID    string      `json:"orderLinkId"`
+	Symbol         string      `json:"symbol"`
+	Side           OrderSide   `json:"side"`
+	OrderType      OrderType   `json:"orderType"`
+	Price          float64     `json:"price"`
+	AveragePrice   float64     `json:"avgPrice"`
+	Quantity       float64     `json:"qty"`
+	FilledQuantity float64     `json:"cumExecQty,omitempty"` // Quantità già eseguita (ordini parzialmente fillati)
+	Status         OrderStatus `json:"orderStatus"`
+	TriggerPrice   float64     `json:"triggerPrice,omitempty"`
+	StopLoss       float64     `json:"stopLoss,omitempty"`
+	TakeProfit     float64     `json:"takeProfit,omitempty"`
+	CreatedTime    time.Time   `json:"createdTime"`
+	UpdatedTime    time.Time   `json:"updatedTime"`
+	StopOrderType  string      `json:"stopOrderType,omitempty"` // TakeProfit, StopLoss, ... per gli ordini collegati alla posizione
+	ErrorCode      string      `json:"retCode,omitempty"`
+	ErrorMessage   string      `json:"retMsg,omitempty"`
+
+	RejectReason RejectReason `json:"rejectReason,omitempty"` // Motivo del rifiuto; vuoto se l'ordine è stato accettato
 }
 
 // IsSuccess verifica se l'ordine è stato piazzato con successo
@@ -114,6 +143,11 @@ func (or *OrderResponse) IsActive() bool {
 		or.Status == OrderStatusUntriggered
 }
 
+// IsConditional verifica se l'ordine è condizionale, cioè in attesa del prezzo di attivazione
+func (or *OrderResponse) IsConditional() bool {
+	return or.TriggerPrice > 0 || or.Status == OrderStatusUntriggered
+}
+
 // IsFilled verifica se l'ordine è stato completamente riempito
 func (or *OrderResponse) IsFilled() bool {
 	return or.Status == OrderStatusFilled
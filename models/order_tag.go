@@ -0,0 +1,44 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OrderTag rappresenta un tag e/o una nota associata ad un ordine per il journaling
+type OrderTag struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrderID   string    `gorm:"type:varchar(50);not null;index:idx_tag_order_id" json:"order_id"`
+	Tag       string    `gorm:"type:varchar(50);index:idx_tag_name;comment:Etichetta breve (es. news spike)" json:"tag,omitempty"`
+	Note      string    `gorm:"type:text;comment:Nota libera" json:"note,omitempty"`
+	CreatedBy string    `gorm:"type:varchar(100);default:'system'" json:"created_by"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (OrderTag) TableName() string {
+	return "order_tags"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (ot *OrderTag) BeforeCreate(tx *gorm.DB) error {
+	ot.Tag = NormalizeTag(ot.Tag)
+	ot.Note = strings.TrimSpace(ot.Note)
+
+	if ot.OrderID == "" || (ot.Tag == "" && ot.Note == "") {
+		return gorm.ErrInvalidData
+	}
+
+	if ot.CreatedBy == "" {
+		ot.CreatedBy = "system"
+	}
+
+	return nil
+}
+
+// NormalizeTag normalizza un tag (minuscolo, senza spazi iniziali/finali) per renderlo confrontabile
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
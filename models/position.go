@@ -1,8 +1,11 @@
 package models
 
 import (
+	"encoding/json"
 	"strconv"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 // PositionSide rappresenta il lato della posizione
@@ -77,6 +80,118 @@ type Position struct {
 	StpType           string         `json:"stpType"`           // STP type
 	CreatedTime       string         `json:"createdTime"`       // Timestamp creazione
 	UpdatedAt         time.Time      `json:"updatedAt"`         // Timestamp aggiornamento per uso interno
+
+	// Parsed contiene i campi già convertiti nei tipi nativi; è calcolato una sola volta
+	// alla decodifica della risposta (o da Parse per le posizioni costruite dai processor)
+	Parsed ParsedPosition `json:"-"`
+}
+
+// ParsedPosition contiene i campi di Position restituiti come stringhe dall'exchange, convertiti in tipi nativi
+// I valori numerici vuoti o non validi valgono zero, i timestamp vuoti o non validi sono il tempo zero
+type ParsedPosition struct {
+	Side            PositionSide
+	Status          PositionStatus
+	Size            float64
+	EntryPrice      float64
+	MarkPrice       float64
+	UnrealisedPnl   float64
+	RealisedPnl     float64
+	CumRealisedPnl  float64
+	Leverage        float64
+	PositionBalance float64
+	PositionIM      float64
+	PositionMM      float64
+	Notional        float64
+	TakeProfit      float64
+	StopLoss        float64
+	TrailingStop    float64
+	TpTriggerBy     TriggerType
+	SlTriggerBy     TriggerType
+	CreatedTime     time.Time
+	UpdatedTime     time.Time
+}
+
+// IsActive indica se la posizione ha una dimensione positiva
+func (p ParsedPosition) IsActive() bool {
+	return p.Size > 0
+}
+
+// IsLong verifica se la posizione è long
+func (p ParsedPosition) IsLong() bool {
+	return p.Side == PositionSideBuy
+}
+
+// IsShort verifica se la posizione è short
+func (p ParsedPosition) IsShort() bool {
+	return p.Side == PositionSideSell
+}
+
+// HasStopLoss verifica se la posizione ha uno stop loss impostato
+func (p ParsedPosition) HasStopLoss() bool {
+	return p.StopLoss > 0
+}
+
+// HasTakeProfit verifica se la posizione ha un take profit impostato
+func (p ParsedPosition) HasTakeProfit() bool {
+	return p.TakeProfit > 0
+}
+
+// Price restituisce il mark price, o il prezzo di ingresso se il mark price non è disponibile
+func (p ParsedPosition) Price() float64 {
+	if p.MarkPrice > 0 {
+		return p.MarkPrice
+	}
+	return p.EntryPrice
+}
+
+// Parse converte i campi stringa della posizione e li salva in Parsed
+func (p *Position) Parse() {
+	p.Parsed = ParsedPosition{
+		Side:            p.Side,
+		Status:          p.PositionStatus,
+		Size:            parseFloat(p.Size),
+		EntryPrice:      parseFloat(p.EntryPrice),
+		MarkPrice:       parseFloat(p.MarkPrice),
+		UnrealisedPnl:   parseFloat(p.UnrealisedPnl),
+		RealisedPnl:     parseFloat(p.RealisedPnl),
+		CumRealisedPnl:  parseFloat(p.CumRealisedPnl),
+		Leverage:        parseFloat(p.Leverage),
+		PositionBalance: parseFloat(p.PositionBalance),
+		PositionIM:      parseFloat(p.PositionIM),
+		PositionMM:      parseFloat(p.PositionMM),
+		Notional:        parseFloat(p.Notional),
+		TakeProfit:      parseFloat(p.TakeProfit),
+		StopLoss:        parseFloat(p.StopLoss),
+		TrailingStop:    parseFloat(p.TrailingStop),
+		TpTriggerBy:     TriggerType(p.TpTriggerBy),
+		SlTriggerBy:     TriggerType(p.SlTriggerBy),
+		CreatedTime:     parseMillis(p.CreatedTime),
+		UpdatedTime:     parseMillis(p.UpdatedTime),
+	}
+}
+
+// UnmarshalJSON decodifica la posizione e ne converte subito i campi in Parsed
+func (p *Position) UnmarshalJSON(data []byte) error {
+	type rawPosition Position
+	if err := json.Unmarshal(data, (*rawPosition)(p)); err != nil {
+		return err
+	}
+	p.Parse()
+	return nil
+}
+
+// parseFloat converte un valore numerico restituito come stringa; vuoto o non valido vale zero
+func parseFloat(value string) float64 {
+	return ParseDecimal(value).InexactFloat64()
+}
+
+// parseMillis converte un timestamp in millisecondi restituito come stringa; vuoto o non valido è il tempo zero
+func parseMillis(value string) time.Time {
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || millis <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(millis)
 }
 
 // PositionListResponse rappresenta la risposta dell'API per la lista delle posizioni
@@ -114,38 +229,37 @@ func (p *Position) IsActive() bool {
 	return p.GetSizeFloat() > 0
 }
 
+// SizeDecimal restituisce la dimensione come decimale; vuota o non valida vale zero
+func (p *Position) SizeDecimal() decimal.Decimal {
+	return ParseDecimal(p.Size)
+}
+
+// EntryPriceDecimal restituisce il prezzo di entrata come decimale
+func (p *Position) EntryPriceDecimal() decimal.Decimal {
+	return ParseDecimal(p.EntryPrice)
+}
+
+// MarkPriceDecimal restituisce il prezzo di mark come decimale
+func (p *Position) MarkPriceDecimal() decimal.Decimal {
+	return ParseDecimal(p.MarkPrice)
+}
+
+// UnrealisedPnlDecimal restituisce il PnL non realizzato come decimale
+func (p *Position) UnrealisedPnlDecimal() decimal.Decimal {
+	return ParseDecimal(p.UnrealisedPnl)
+}
+
 // GetSizeFloat restituisce la dimensione come float64
 func (p *Position) GetSizeFloat() float64 {
-	if p.Size == "" {
-		return 0
-	}
-	value, err := strconv.ParseFloat(p.Size, 64)
-	if err != nil {
-		return 0
-	}
-	return value
+	return p.SizeDecimal().InexactFloat64()
 }
 
 // GetEntryPriceFloat restituisce il prezzo di entrata come float64
 func (p *Position) GetEntryPriceFloat() float64 {
-	if p.EntryPrice == "" {
-		return 0
-	}
-	value, err := strconv.ParseFloat(p.EntryPrice, 64)
-	if err != nil {
-		return 0
-	}
-	return value
+	return p.EntryPriceDecimal().InexactFloat64()
 }
 
 // GetUnrealisedPnlFloat restituisce il PnL non realizzato come float64
 func (p *Position) GetUnrealisedPnlFloat() float64 {
-	if p.UnrealisedPnl == "" {
-		return 0
-	}
-	value, err := strconv.ParseFloat(p.UnrealisedPnl, 64)
-	if err != nil {
-		return 0
-	}
-	return value
+	return p.UnrealisedPnlDecimal().InexactFloat64()
 }
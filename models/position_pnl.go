@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PositionPnL è il PnL non realizzato di una posizione aperta, ricalcolato a ogni aggiornamento del mark price
+// È sia l'aggiornamento pubblicato in tempo reale sia lo snapshot periodico salvato nel database
+type PositionPnL struct {
+	ID               uint         `gorm:"primaryKey;autoIncrement" json:"id,omitempty"`
+	Symbol           string       `gorm:"type:varchar(20);not null;index:idx_position_pnl_symbol_taken,priority:1" json:"symbol"`
+	Side             PositionSide `gorm:"type:varchar(4);not null" json:"side"`
+	Size             float64      `gorm:"type:REAL;not null" json:"size"`
+	EntryPrice       float64      `gorm:"type:REAL;not null" json:"entry_price"`
+	MarkPrice        float64      `gorm:"type:REAL;not null" json:"mark_price"`
+	Leverage         float64      `gorm:"type:REAL" json:"leverage"`
+	UnrealisedPnl    float64      `gorm:"type:REAL;not null" json:"unrealised_pnl"`
+	UnrealisedPnlPct float64      `gorm:"type:REAL;not null;comment:PnL / valore di ingresso" json:"unrealised_pnl_pct"`
+	ROE              float64      `gorm:"column:roe;type:REAL;comment:PnL / margine iniziale" json:"roe"`
+	TakenAt          time.Time    `gorm:"type:timestamp;not null;index:idx_position_pnl_symbol_taken,priority:2" json:"taken_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (PositionPnL) TableName() string {
+	return "position_pnl_snapshots"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (p *PositionPnL) BeforeCreate(tx *gorm.DB) error {
+	if p.Symbol == "" || p.Size <= 0 || p.EntryPrice <= 0 || p.MarkPrice <= 0 {
+		return gorm.ErrInvalidData
+	}
+	if p.TakenAt.IsZero() {
+		p.TakenAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// NewPositionPnL calcola il PnL non realizzato della posizione al mark price indicato
+// La percentuale è riferita al valore di ingresso, il ROE al margine iniziale (percentuale per leva)
+func NewPositionPnL(position ParsedPosition, symbol string, markPrice float64, at time.Time) *PositionPnL {
+	pnl := &PositionPnL{
+		Symbol:        symbol,
+		Side:          position.Side,
+		Size:          position.Size,
+		EntryPrice:    position.EntryPrice,
+		MarkPrice:     markPrice,
+		Leverage:      position.Leverage,
+		UnrealisedPnl: CalculateUnrealisedPnl(position.Side, position.Size, position.EntryPrice, markPrice),
+		TakenAt:       at.UTC(),
+	}
+	if notional := position.Size * position.EntryPrice; notional > 0 {
+		pnl.UnrealisedPnlPct = pnl.UnrealisedPnl / notional
+	}
+	if position.Leverage > 0 {
+		pnl.ROE = pnl.UnrealisedPnlPct * position.Leverage
+	}
+	return pnl
+}
+
+// CalculateUnrealisedPnl restituisce il PnL non realizzato di una posizione lineare: positivo se il mark price
+// è sopra l'ingresso per un long o sotto l'ingresso per uno short
+func CalculateUnrealisedPnl(side PositionSide, size, entryPrice, markPrice float64) float64 {
+	pnl := (markPrice - entryPrice) * size
+	if side == PositionSideSell {
+		return -pnl
+	}
+	return pnl
+}
+
+// IsBreakEven indica se la posizione è almeno in pareggio al netto della soglia minPct (es. 0.002 per coprire le commissioni)
+func (p *PositionPnL) IsBreakEven(minPct float64) bool {
+	return p.UnrealisedPnlPct >= minPct
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JobStatus rappresenta lo stato di un job differito
+type JobStatus string
+
+const (
+	JobStatusPending JobStatus = "pending" // In attesa dell'istante di esecuzione
+	JobStatusRunning JobStatus = "running" // Preso in carico dal worker della coda
+	JobStatusDone    JobStatus = "done"    // Eseguito con successo
+	JobStatusFailed  JobStatus = "failed"  // Tentativi esauriti o tipo di job sconosciuto
+)
+
+// ScheduledJob è un'azione differita salvata nel database, così da sopravvivere ai riavvii
+// (es. "cancella l'ordine se non è stato eseguito entro 5 minuti")
+type ScheduledJob struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Type        string     `gorm:"type:varchar(50);not null;index:idx_scheduled_job_type" json:"type"`
+	Payload     string     `gorm:"type:text;not null;comment:Parametri del job in JSON" json:"payload"`
+	Status      JobStatus  `gorm:"type:varchar(20);not null;index:idx_scheduled_job_due,priority:1" json:"status"`
+	RunAt       time.Time  `gorm:"type:timestamp;not null;index:idx_scheduled_job_due,priority:2" json:"run_at"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int        `gorm:"not null;default:3" json:"max_attempts"`
+	LastError   string     `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt   time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	CompletedAt *time.Time `gorm:"type:timestamp" json:"completed_at,omitempty"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (ScheduledJob) TableName() string {
+	return "scheduled_jobs"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (j *ScheduledJob) BeforeCreate(tx *gorm.DB) error {
+	if j.Type == "" || j.RunAt.IsZero() {
+		return gorm.ErrInvalidData
+	}
+	if j.Status == "" {
+		j.Status = JobStatusPending
+	}
+	if j.Payload == "" {
+		j.Payload = "{}"
+	}
+	if j.MaxAttempts <= 0 {
+		j.MaxAttempts = 3
+	}
+	return nil
+}
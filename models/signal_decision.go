@@ -0,0 +1,200 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SignalOutcome rappresenta l'esito di un segnale di una strategia
+type SignalOutcome string
+
+const (
+	SignalOutcomeEntered  SignalOutcome = "entered"  // Condizioni soddisfatte e ordine piazzato
+	SignalOutcomeRejected SignalOutcome = "rejected" // Almeno una condizione non soddisfatta
+	SignalOutcomeArmed    SignalOutcome = "armed"    // Rottura confermata, ingresso in attesa del retest
+	SignalOutcomeFailed   SignalOutcome = "failed"   // Condizioni soddisfatte ma ordine non piazzato
+)
+
+// SignalCheck è una condizione valutata su un segnale, con il valore osservato e la soglia confrontata
+// Una condizione non applicata (es. dati mancanti con filtro che non blocca) ha Applied false e Passed true
+type SignalCheck struct {
+	Name      string   `json:"name"`
+	Passed    bool     `json:"passed"`
+	Applied   bool     `json:"applied"`
+	Value     *float64 `json:"value,omitempty"`
+	Threshold *float64 `json:"threshold,omitempty"`
+	Detail    string   `json:"detail,omitempty"`
+}
+
+// SignalExplanation è la spiegazione strutturata di una decisione: quali condizioni sono state valutate,
+// con quali valori e con quale esito. Le strategie la compilano durante la valutazione del segnale
+// I metodi su una SignalExplanation nil non fanno nulla
+type SignalExplanation struct {
+	Strategy      string        `json:"strategy"`
+	Symbol        string        `json:"symbol"`
+	Side          OrderSide     `json:"side,omitempty"`
+	Trigger       string        `json:"trigger"` // Origine del segnale (es. wall_break, support_break, retest, liquidation)
+	Outcome       SignalOutcome `json:"outcome,omitempty"`
+	Checks        []SignalCheck `json:"checks"`
+	OrderID       string        `json:"order_id,omitempty"`
+	CorrelationID string        `json:"correlation_id,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// NewSignalExplanation crea la spiegazione di un segnale della strategia sul simbolo indicato
+func NewSignalExplanation(strategy, symbol string, side OrderSide, trigger string) *SignalExplanation {
+	return &SignalExplanation{
+		Strategy:  strategy,
+		Symbol:    symbol,
+		Side:      side,
+		Trigger:   trigger,
+		Checks:    []SignalCheck{},
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// Check registra una condizione numerica con il valore osservato e la soglia
+func (e *SignalExplanation) Check(name string, passed bool, value, threshold float64, detail string) {
+	if e == nil {
+		return
+	}
+	e.Checks = append(e.Checks, SignalCheck{Name: name, Passed: passed, Applied: true, Value: &value, Threshold: &threshold, Detail: detail})
+}
+
+// Verdict registra una condizione senza valori numerici, descritta da detail
+func (e *SignalExplanation) Verdict(name string, passed bool, detail string) {
+	if e == nil {
+		return
+	}
+	e.Checks = append(e.Checks, SignalCheck{Name: name, Passed: passed, Applied: true, Detail: detail})
+}
+
+// Skipped registra una condizione non applicata, che quindi non blocca il segnale
+func (e *SignalExplanation) Skipped(name, reason string) {
+	if e == nil {
+		return
+	}
+	e.Checks = append(e.Checks, SignalCheck{Name: name, Passed: true, Detail: reason})
+}
+
+// Passed indica se tutte le condizioni registrate sono soddisfatte
+func (e *SignalExplanation) Passed() bool {
+	if e == nil {
+		return false
+	}
+	for _, check := range e.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Conditions elenca le condizioni in una riga: ✓ soddisfatta, ✗ non soddisfatta, – non applicata,
+// con valore/soglia se presenti
+func (e *SignalExplanation) Conditions() string {
+	if e == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(e.Checks))
+	for _, check := range e.Checks {
+		mark := "✓"
+		switch {
+		case !check.Passed:
+			mark = "✗"
+		case !check.Applied:
+			mark = "–"
+		}
+		part := mark + " " + check.Name
+		if check.Value != nil && check.Threshold != nil {
+			part += fmt.Sprintf(" %.6g/%.6g", *check.Value, *check.Threshold)
+		}
+		if check.Detail != "" {
+			part += " (" + check.Detail + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// String riassume la decisione in una riga per i log
+func (e *SignalExplanation) String() string {
+	if e == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %s %s %s [%s]: %s", e.Strategy, e.Symbol, e.Side, e.Trigger, e.Outcome, e.Conditions())
+}
+
+// SignalDecision rappresenta la spiegazione di un segnale salvata nel database, collegata all'ordine che ha generato
+type SignalDecision struct {
+	ID            uint          `gorm:"primaryKey;autoIncrement" json:"id"`
+	Strategy      string        `gorm:"type:varchar(50);not null;index:idx_signal_decision_strategy" json:"strategy"`
+	Symbol        string        `gorm:"type:varchar(20);not null;index:idx_signal_decision_symbol" json:"symbol"`
+	Side          OrderSide     `gorm:"type:varchar(4)" json:"side,omitempty"`
+	Trigger       string        `gorm:"type:varchar(30);not null;comment:Origine del segnale" json:"trigger"`
+	Outcome       SignalOutcome `gorm:"type:varchar(10);not null;index:idx_signal_decision_outcome" json:"outcome"`
+	Checks        string        `gorm:"type:text;not null;comment:Condizioni valutate in JSON" json:"-"`
+	OrderID       *string       `gorm:"type:varchar(50);index:idx_signal_decision_order_id" json:"order_id,omitempty"`
+	CorrelationID string        `gorm:"type:varchar(64)" json:"correlation_id,omitempty"` // Ciclo del worker che ha valutato il segnale
+	CreatedAt     time.Time     `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index:idx_signal_decision_created_at" json:"created_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (SignalDecision) TableName() string {
+	return "signal_decisions"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (sd *SignalDecision) BeforeCreate(tx *gorm.DB) error {
+	if sd.Strategy == "" || sd.Symbol == "" || sd.Trigger == "" || sd.Outcome == "" || sd.Checks == "" {
+		return gorm.ErrInvalidData
+	}
+	return nil
+}
+
+// NewSignalDecision converte la spiegazione nel record da salvare
+func NewSignalDecision(explanation *SignalExplanation) (*SignalDecision, error) {
+	checks, err := json.Marshal(explanation.Checks)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella serializzazione delle condizioni: %w", err)
+	}
+	decision := &SignalDecision{
+		Strategy:      explanation.Strategy,
+		Symbol:        explanation.Symbol,
+		Side:          explanation.Side,
+		Trigger:       explanation.Trigger,
+		Outcome:       explanation.Outcome,
+		Checks:        string(checks),
+		CorrelationID: explanation.CorrelationID,
+		CreatedAt:     explanation.CreatedAt,
+	}
+	if explanation.OrderID != "" {
+		orderID := explanation.OrderID
+		decision.OrderID = &orderID
+	}
+	return decision, nil
+}
+
+// ToExplanation converte il record nella spiegazione con le condizioni tipizzate
+// Se le condizioni salvate non sono interpretabili la lista resta vuota
+func (sd *SignalDecision) ToExplanation() *SignalExplanation {
+	explanation := &SignalExplanation{
+		Strategy:      sd.Strategy,
+		Symbol:        sd.Symbol,
+		Side:          sd.Side,
+		Trigger:       sd.Trigger,
+		Outcome:       sd.Outcome,
+		Checks:        []SignalCheck{},
+		CorrelationID: sd.CorrelationID,
+		CreatedAt:     sd.CreatedAt,
+	}
+	if sd.OrderID != nil {
+		explanation.OrderID = *sd.OrderID
+	}
+	_ = json.Unmarshal([]byte(sd.Checks), &explanation.Checks)
+	return explanation
+}
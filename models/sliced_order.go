@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// SliceMethod rappresenta il modo in cui un ordine grande viene frazionato
+type SliceMethod string
+
+const (
+	SliceMethodTWAP    SliceMethod = "twap"    // Frazioni di pari quantità a intervalli regolari
+	SliceMethodIceberg SliceMethod = "iceberg" // Frazioni dimensionate sulla liquidità visibile al miglior prezzo
+)
+
+// SlicedOrderStatus rappresenta lo stato di un ordine frazionato
+type SlicedOrderStatus string
+
+const (
+	SlicedOrderStatusActive    SlicedOrderStatus = "active"    // Frazioni ancora da inviare
+	SlicedOrderStatusCompleted SlicedOrderStatus = "completed" // Quantità totale eseguita
+	SlicedOrderStatusCancelled SlicedOrderStatus = "cancelled" // Frazioni residue annullate (posizione chiusa o errore)
+)
+
+// SlicedOrder è l'ordine logico di un ingresso frazionato in più ordini figli
+// L'ingresso nella tabella orders è il primo figlio: la sua quantità e il prezzo medio crescono a ogni frazione
+// eseguita, così la posizione resta un solo ordine per statistiche, stop e incrementi
+type SlicedOrder struct {
+	ID              uint              `gorm:"primaryKey;autoIncrement" json:"id"`
+	ParentOrderID   string            `gorm:"type:varchar(50);not null;uniqueIndex:idx_sliced_order_parent;comment:Ingresso che rappresenta l'ordine logico" json:"parent_order_id"`
+	Symbol          string            `gorm:"type:varchar(20);not null" json:"symbol"`
+	Side            OrderSideType     `gorm:"type:varchar(4);not null" json:"side"`
+	Method          SliceMethod       `gorm:"type:varchar(10);not null" json:"method"`
+	Status          SlicedOrderStatus `gorm:"type:varchar(10);not null;index:idx_sliced_order_status" json:"status"`
+	TotalQuantity   float64           `gorm:"type:REAL;not null;comment:Quantità dell'ordine logico" json:"total_quantity"`
+	FilledQuantity  float64           `gorm:"type:REAL;not null;comment:Quantità eseguita, compreso il primo figlio" json:"filled_quantity"`
+	SlicesPlaced    int               `gorm:"not null;default:0" json:"slices_placed"`
+	MaxSlices       int               `gorm:"not null" json:"max_slices"`
+	IntervalSeconds int               `gorm:"not null;comment:Attesa tra due frazioni" json:"interval_seconds"`
+	Note            string            `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt       time.Time         `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt       time.Time         `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	CompletedAt     *time.Time        `gorm:"type:timestamp" json:"completed_at,omitempty"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (SlicedOrder) TableName() string {
+	return "sliced_orders"
+}
+
+// RemainingQuantity restituisce la quantità ancora da inviare
+func (s *SlicedOrder) RemainingQuantity() float64 {
+	return max(s.TotalQuantity-s.FilledQuantity, 0)
+}
+
+// OrderSlice è un ordine figlio di un ordine frazionato, inviato dopo l'ingresso
+type OrderSlice struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	SlicedOrderID uint      `gorm:"not null;index:idx_order_slice_parent" json:"sliced_order_id"`
+	Sequence      int       `gorm:"not null;comment:Posizione del figlio nell'ordine logico (l'ingresso è 1)" json:"sequence"`
+	OrderID       string    `gorm:"type:varchar(50);not null" json:"order_id"`
+	OrderLinkID   string    `gorm:"type:varchar(50)" json:"order_link_id"`
+	Quantity      float64   `gorm:"type:REAL;not null" json:"quantity"`
+	Price         float64   `gorm:"type:REAL;not null;comment:Prezzo di esecuzione, o di riferimento se non disponibile" json:"price"`
+	CreatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (OrderSlice) TableName() string {
+	return "order_slices"
+}
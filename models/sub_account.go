@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SubAccountPermissions sono i permessi di una API key per gruppo (es. ContractTrade: Order, Position)
+type SubAccountPermissions map[string][]string
+
+// SubAccountRequest descrive il sub-account da creare sotto il master account
+type SubAccountRequest struct {
+	Username string // 6-16 caratteri, lettere e numeri
+	Note     string
+}
+
+// SubAccount rappresenta un sub-account del master account
+type SubAccount struct {
+	UID        int64  `json:"uid"`
+	Username   string `json:"username"`
+	MemberType int    `json:"member_type"` // 1 sub-account standard, 6 sub-account custodial
+	Status     int    `json:"status"`      // 1 attivo, 2 login bloccato, 4 congelato
+	Note       string `json:"note,omitempty"`
+}
+
+// SubAPIKeyRequest descrive la API key da creare per un sub-account
+type SubAPIKeyRequest struct {
+	SubUID      int64
+	Note        string
+	ReadOnly    bool
+	IPs         []string // IP autorizzati; vuoto per nessuna restrizione (la key scade dopo 90 giorni)
+	Permissions SubAccountPermissions
+}
+
+// SubAPIKey è una API key creata per un sub-account
+// Il secret è restituito dall'exchange solo alla creazione e non viene mai serializzato
+type SubAPIKey struct {
+	ID          string                `json:"id"`
+	APIKey      string                `json:"api_key"`
+	Secret      string                `json:"-"`
+	ReadOnly    bool                  `json:"read_only"`
+	Permissions SubAccountPermissions `json:"permissions"`
+}
+
+// StrategySubAccount associa una strategia al sub-account in cui opera e alla API key creata per essa
+// Il secret della key non viene salvato nel database ma nel file cifrato delle credenziali
+type StrategySubAccount struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Strategy   string    `gorm:"type:varchar(30);not null;uniqueIndex:idx_strategy_sub_account_strategy" json:"strategy"`
+	Exchange   string    `gorm:"type:varchar(20);not null" json:"exchange"`
+	UID        int64     `gorm:"column:uid;not null;comment:UID del sub-account" json:"uid"`
+	Username   string    `gorm:"type:varchar(20);not null" json:"username"`
+	APIKey     string    `gorm:"column:api_key;type:varchar(50);comment:Ultima API key creata per la strategia" json:"api_key"`
+	KeyCreated time.Time `gorm:"type:timestamp" json:"key_created"`
+	CreatedAt  time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (StrategySubAccount) TableName() string {
+	return "strategy_sub_accounts"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (s *StrategySubAccount) BeforeCreate(tx *gorm.DB) error {
+	if s.Strategy == "" || s.Exchange == "" || s.UID <= 0 || s.Username == "" {
+		return gorm.ErrInvalidData
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tipi di account Bybit usati nei trasferimenti interni
+const (
+	AccountTypeUnified  = "UNIFIED"  // Unified Trading Account (margine per derivati e spot)
+	AccountTypeFund     = "FUND"     // Funding account (depositi e prelievi)
+	AccountTypeContract = "CONTRACT" // Account derivati classico
+	AccountTypeSpot     = "SPOT"     // Account spot classico
+)
+
+// TransferRequest rappresenta un trasferimento di fondi tra account
+// FromMemberID e ToMemberID sono usati solo dai trasferimenti tra UID diversi (universal transfer)
+type TransferRequest struct {
+	Coin            string  `json:"coin"`
+	Amount          float64 `json:"amount"`
+	FromAccountType string  `json:"from_account_type"`
+	ToAccountType   string  `json:"to_account_type"`
+	FromMemberID    int64   `json:"from_member_id,omitempty"`
+	ToMemberID      int64   `json:"to_member_id,omitempty"`
+}
+
+// TransferResult rappresenta l'esito di un trasferimento
+type TransferResult struct {
+	TransferID string          `json:"transfer_id"`
+	Status     string          `json:"status"` // SUCCESS, PENDING o FAILED
+	Request    TransferRequest `json:"request"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// IsSuccess verifica se il trasferimento è stato completato
+func (tr *TransferResult) IsSuccess() bool {
+	return tr.Status == "SUCCESS"
+}
+
+// MemberBalance rappresenta il saldo di una valuta nell'account di un UID (master o sub-account)
+type MemberBalance struct {
+	MemberID        int64   `json:"member_id"`
+	AccountType     string  `json:"account_type"`
+	Coin            string  `json:"coin"`
+	WalletBalance   float64 `json:"wallet_balance"`
+	TransferBalance float64 `json:"transfer_balance"` // Parte del saldo trasferibile, non impegnata come margine
+}
+
+// RebalanceTransfer registra un trasferimento eseguito dal ribilanciamento del portafoglio tra master e sub-account
+// Gli account sono indicati con il nome dell'allocazione: "master" o il nome della strategia
+type RebalanceTransfer struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransferID   string    `gorm:"type:varchar(64);index:idx_rebalance_transfer_id" json:"transfer_id"`
+	Coin         string    `gorm:"type:varchar(10);not null" json:"coin"`
+	Amount       float64   `gorm:"type:REAL;not null" json:"amount"`
+	FromAccount  string    `gorm:"type:varchar(30);not null" json:"from_account"`
+	ToAccount    string    `gorm:"type:varchar(30);not null" json:"to_account"`
+	FromMemberID int64     `gorm:"not null" json:"from_member_id"`
+	ToMemberID   int64     `gorm:"not null" json:"to_member_id"`
+	Status       string    `gorm:"type:varchar(10);not null;comment:SUCCESS, PENDING o FAILED" json:"status"`
+	Error        string    `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index:idx_rebalance_transfer_created" json:"created_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (RebalanceTransfer) TableName() string {
+	return "rebalance_transfers"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (rt *RebalanceTransfer) BeforeCreate(tx *gorm.DB) error {
+	if rt.Amount <= 0 || rt.Coin == "" || rt.FromAccount == "" || rt.ToAccount == "" || rt.Status == "" {
+		return gorm.ErrInvalidData
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"strconv"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 // WalletBalance rappresenta il saldo del wallet per una specifica criptovaluta
@@ -14,6 +16,21 @@ type WalletBalance struct {
 	UpdatedAt           time.Time `json:"updated_at"`          // Timestamp di aggiornamento (aggiunto internamente)
 }
 
+// EquityDecimal restituisce l'equity come decimale
+func (wb *WalletBalance) EquityDecimal() (decimal.Decimal, error) {
+	return decimal.NewFromString(wb.Equity)
+}
+
+// WalletBalanceDecimal restituisce il wallet balance come decimale
+func (wb *WalletBalance) WalletBalanceDecimal() (decimal.Decimal, error) {
+	return decimal.NewFromString(wb.WalletBalance)
+}
+
+// AvailableToWithdrawDecimal restituisce l'importo disponibile per prelievo come decimale
+func (wb *WalletBalance) AvailableToWithdrawDecimal() (decimal.Decimal, error) {
+	return decimal.NewFromString(wb.AvailableToWithdraw)
+}
+
 // GetEquityFloat restituisce l'equity come float64
 func (wb *WalletBalance) GetEquityFloat() (float64, error) {
 	return strconv.ParseFloat(wb.Equity, 64)
@@ -31,11 +48,11 @@ func (wb *WalletBalance) GetAvailableToWithdrawFloat() (float64, error) {
 
 // IsActive verifica se il wallet ha un saldo attivo
 func (wb *WalletBalance) IsActive() bool {
-	equity, err := wb.GetEquityFloat()
+	equity, err := wb.EquityDecimal()
 	if err != nil {
 		return false
 	}
-	return equity > 0
+	return equity.IsPositive()
 }
 
 // AccountInfo rappresenta le informazioni dell'account
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkerBudget è il sub-account virtuale di un worker: la quota del saldo assegnata alla strategia
+// e il PnL realizzato da allora. Il budget disponibile è Capital + RealizedPnL
+type WorkerBudget struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Worker      string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_worker_budget_worker" json:"worker"`
+	Share       float64   `gorm:"type:REAL;not null;comment:Quota del saldo assegnata (0.5 = 50%)" json:"share"`
+	Capital     float64   `gorm:"type:REAL;not null;comment:Capitale assegnato in USDT all'allocazione" json:"capital"`
+	RealizedPnL float64   `gorm:"column:realized_pnl;type:REAL;not null;default:0;comment:PnL realizzato dall'allocazione" json:"realized_pnl"`
+	AllocatedAt time.Time `gorm:"type:timestamp;not null" json:"allocated_at"`
+	UpdatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName specifica il nome della tabella per GORM
+func (WorkerBudget) TableName() string {
+	return "worker_budgets"
+}
+
+// BeforeCreate hook per validazioni prima della creazione
+func (wb *WorkerBudget) BeforeCreate(tx *gorm.DB) error {
+	if wb.Worker == "" || wb.Share <= 0 || wb.Capital < 0 || wb.AllocatedAt.IsZero() {
+		return gorm.ErrInvalidData
+	}
+	return nil
+}
+
+// Available restituisce il budget del worker al netto del PnL realizzato; mai negativo
+func (wb *WorkerBudget) Available() float64 {
+	if available := wb.Capital + wb.RealizedPnL; available > 0 {
+		return available
+	}
+	return 0
+}
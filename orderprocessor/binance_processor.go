@@ -0,0 +1,788 @@
+package orderprocessor
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/outage"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// URL di base delle API Binance USDT-M futures
+	binanceFuturesBaseURL        = "https://fapi.binance.com"
+	binanceFuturesTestnetBaseURL = "https://testnet.binancefuture.com"
+
+	// Endpoint Binance USDT-M futures
+	binanceOrderEndpoint          = "/fapi/v1/order"
+	binanceAlgoOrderEndpoint      = "/fapi/v1/algoOrder"
+	binanceOpenAlgoOrdersEndpoint = "/fapi/v1/openAlgoOrders"
+	binanceLeverageEndpoint       = "/fapi/v1/leverage"
+	binancePositionRiskEndpoint   = "/fapi/v2/positionRisk"
+	binanceAccountEndpoint        = "/fapi/v3/account"
+	binanceExchangeInfoEndpoint   = "/fapi/v1/exchangeInfo"
+	binanceCommissionEndpoint     = "/fapi/v1/commissionRate"
+	binanceMultiAssetsEndpoint    = "/fapi/v1/multiAssetsMargin"
+
+	// binanceRecvWindow è la finestra di validità (in millisecondi) delle richieste firmate
+	binanceRecvWindow = "5000"
+
+	// binanceWorkingType è il prezzo usato per attivare stop loss e take profit
+	binanceWorkingType = "MARK_PRICE"
+)
+
+// Tipi di ordine Binance
+const (
+	binanceOrderTypeMarket     = "MARKET"
+	binanceOrderTypeStopMarket = "STOP_MARKET"
+	binanceOrderTypeTakeProfit = "TAKE_PROFIT_MARKET"
+)
+
+// BinanceOrderProcessor implementa OrderProcessor per Binance USDT-M futures
+// Stop loss e take profit sono ordini condizionali closePosition inviati al servizio Algo di Binance
+// (gli ordini STOP_MARKET/TAKE_PROFIT_MARKET non sono più accettati da /fapi/v1/order)
+type BinanceOrderProcessor struct {
+	baseURL    string
+	apiKey     string
+	apiSecret  string
+	httpClient *http.Client
+
+	filtersMu sync.Mutex
+	filters   map[string]binanceSymbolFilters
+}
+
+// BinanceAPIError rappresenta un errore restituito da Binance ({"code":-2019,"msg":"..."})
+type BinanceAPIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+}
+
+// Error implementa l'interfaccia error
+func (e *BinanceAPIError) Error() string {
+	return fmt.Sprintf("errore API Binance %d: %s", e.Code, e.Message)
+}
+
+// BinanceOrder rappresenta un ordine restituito da /fapi/v1/order
+type BinanceOrder struct {
+	OrderID       int64  `json:"orderId"`
+	ClientOrderID string `json:"clientOrderId"`
+	Symbol        string `json:"symbol"`
+	Status        string `json:"status"` // NEW, PARTIALLY_FILLED, FILLED, CANCELED, REJECTED, EXPIRED
+	Price         string `json:"price"`
+	AvgPrice      string `json:"avgPrice"`
+	OrigQty       string `json:"origQty"`
+	ExecutedQty   string `json:"executedQty"`
+	Type          string `json:"type"`
+	Side          string `json:"side"`
+	StopPrice     string `json:"stopPrice"`
+	Time          int64  `json:"time"`
+	UpdateTime    int64  `json:"updateTime"`
+}
+
+// BinanceAlgoOrder rappresenta un ordine condizionale del servizio Algo
+type BinanceAlgoOrder struct {
+	AlgoID        int64  `json:"algoId"`
+	ClientAlgoID  string `json:"clientAlgoId"`
+	OrderType     string `json:"orderType"`
+	Symbol        string `json:"symbol"`
+	Side          string `json:"side"`
+	PositionSide  string `json:"positionSide"`
+	AlgoStatus    string `json:"algoStatus"`
+	TriggerPrice  string `json:"triggerPrice"`
+	ClosePosition bool   `json:"closePosition"`
+	ReduceOnly    bool   `json:"reduceOnly"`
+}
+
+// BinancePosition rappresenta una posizione restituita da /fapi/v2/positionRisk
+type BinancePosition struct {
+	Symbol           string `json:"symbol"`
+	PositionSide     string `json:"positionSide"` // BOTH (one-way), LONG o SHORT (hedge)
+	PositionAmt      string `json:"positionAmt"`  // negativo per le posizioni short in one-way
+	EntryPrice       string `json:"entryPrice"`
+	MarkPrice        string `json:"markPrice"`
+	UnRealizedProfit string `json:"unRealizedProfit"`
+	LiquidationPrice string `json:"liquidationPrice"`
+	Leverage         string `json:"leverage"`
+	MarginType       string `json:"marginType"` // cross o isolated
+	IsolatedMargin   string `json:"isolatedMargin"`
+	UpdateTime       int64  `json:"updateTime"`
+}
+
+// BinanceAccount rappresenta la risposta di /fapi/v3/account
+type BinanceAccount struct {
+	TotalWalletBalance string `json:"totalWalletBalance"`
+	TotalMarginBalance string `json:"totalMarginBalance"`
+	AvailableBalance   string `json:"availableBalance"`
+	Assets             []struct {
+		Asset             string `json:"asset"`
+		WalletBalance     string `json:"walletBalance"`
+		MarginBalance     string `json:"marginBalance"`
+		AvailableBalance  string `json:"availableBalance"`
+		MaxWithdrawAmount string `json:"maxWithdrawAmount"`
+		UpdateTime        int64  `json:"updateTime"`
+	} `json:"assets"`
+}
+
+// BinanceCommissionRate rappresenta la risposta di /fapi/v1/commissionRate
+type BinanceCommissionRate struct {
+	Symbol              string `json:"symbol"`
+	MakerCommissionRate string `json:"makerCommissionRate"`
+	TakerCommissionRate string `json:"takerCommissionRate"`
+}
+
+// BinanceMultiAssetsMode rappresenta la risposta di /fapi/v1/multiAssetsMargin
+type BinanceMultiAssetsMode struct {
+	MultiAssetsMargin bool `json:"multiAssetsMargin"`
+}
+
+// BinanceLeverageResponse rappresenta la risposta di /fapi/v1/leverage
+type BinanceLeverageResponse struct {
+	Symbol           string `json:"symbol"`
+	Leverage         int    `json:"leverage"`
+	MaxNotionalValue string `json:"maxNotionalValue"`
+}
+
+// binanceExchangeInfo contiene i soli filtri dei simboli usati per arrotondare prezzi e quantità
+type binanceExchangeInfo struct {
+	Symbols []struct {
+		Symbol  string `json:"symbol"`
+		Filters []struct {
+			FilterType string `json:"filterType"`
+			TickSize   string `json:"tickSize"`
+			StepSize   string `json:"stepSize"`
+		} `json:"filters"`
+	} `json:"symbols"`
+}
+
+// binanceSymbolFilters contiene tick size del prezzo e step size della quantità di un simbolo
+type binanceSymbolFilters struct {
+	tickSize float64
+	stepSize float64
+}
+
+// NewBinanceOrderProcessor crea una nuova istanza di BinanceOrderProcessor
+// testnet usa testnet.binancefuture.com
+func NewBinanceOrderProcessor(apiKey, apiSecret string, testnet bool) *BinanceOrderProcessor {
+	baseURL := binanceFuturesBaseURL
+	if testnet {
+		baseURL = binanceFuturesTestnetBaseURL
+	}
+	return &BinanceOrderProcessor{
+		baseURL:   baseURL,
+		apiKey:    apiKey,
+		apiSecret: apiSecret,
+		httpClient: outage.NewClient("binance", &http.Client{
+			Timeout: 10 * time.Second,
+		}),
+		filters: make(map[string]binanceSymbolFilters),
+	}
+}
+
+// PlaceLongOrder apre una posizione long a mercato con stop loss e take profit condizionali
+func (bp *BinanceOrderProcessor) PlaceLongOrder(ctx context.Context, symbol string, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
+	return bp.placeOrder(ctx, symbol, models.OrderSideBuy, price, quantity, stopLoss, takeProfit)
+}
+
+// PlaceShortOrder apre una posizione short a mercato con stop loss e take profit condizionali
+func (bp *BinanceOrderProcessor) PlaceShortOrder(ctx context.Context, symbol string, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
+	return bp.placeOrder(ctx, symbol, models.OrderSideSell, price, quantity, stopLoss, takeProfit)
+}
+
+// placeOrder invia l'ordine a mercato e, se eseguito, gli ordini di protezione
+func (bp *BinanceOrderProcessor) placeOrder(ctx context.Context, symbol string, side models.OrderSide, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
+	filters, err := bp.symbolFilters(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := "long"
+	if side == models.OrderSideSell {
+		prefix = "short"
+	}
+	clientOrderID := orderLinkIDFromContext(ctx, GenerateOrderLinkID(prefix))
+	quantity = models.FloorToStep(quantity, filters.stepSize)
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("side", binanceSide(side))
+	params.Set("type", binanceOrderTypeMarket)
+	params.Set("quantity", models.FormatStep(quantity, filters.stepSize))
+	params.Set("newClientOrderId", clientOrderID)
+	params.Set("newOrderRespType", "RESULT")
+
+	orderResp := &models.OrderResponse{
+		OrderLinkID: clientOrderID,
+		Symbol:      symbol,
+		Side:        side,
+		OrderType:   models.OrderTypeMarket,
+		Price:       price,
+		Quantity:    quantity,
+		StopLoss:    stopLoss,
+		TakeProfit:  takeProfit,
+		CreatedTime: time.Now(),
+		UpdatedTime: time.Now(),
+	}
+
+	var order BinanceOrder
+	if err := bp.doRequest(ctx, http.MethodPost, binanceOrderEndpoint, params, true, &order); err != nil {
+		// Come per Bybit, i rifiuti dell'exchange diventano un ordine Rejected e non un errore
+		var apiErr *BinanceAPIError
+		if errors.As(err, &apiErr) {
+			orderResp.Status = models.OrderStatusRejected
+			orderResp.ErrorCode = strconv.Itoa(apiErr.Code)
+			orderResp.ErrorMessage = apiErr.Message
+			return orderResp, nil
+		}
+		return nil, fmt.Errorf("errore nell'invio dell'ordine: %w", err)
+	}
+
+	orderResp.OrderID = strconv.FormatInt(order.OrderID, 10)
+	orderResp.AveragePrice, _ = strconv.ParseFloat(order.AvgPrice, 64)
+	orderResp.Status = mapBinanceOrderStatus(order.Status)
+	if order.UpdateTime > 0 {
+		orderResp.UpdatedTime = time.UnixMilli(order.UpdateTime)
+	}
+	if orderResp.Status == models.OrderStatusRejected {
+		return orderResp, nil
+	}
+
+	// Stop loss e take profit chiudono l'intera posizione sul lato opposto
+	closeSide := models.OrderSideSell
+	if side == models.OrderSideSell {
+		closeSide = models.OrderSideBuy
+	}
+
+	var protectionErrors []string
+	if stopLoss > 0 {
+		if _, err := bp.placeConditionalOrder(ctx, symbol, binanceOrderTypeStopMarket, closeSide, "", models.FormatStep(stopLoss, filters.tickSize)); err != nil {
+			protectionErrors = append(protectionErrors, fmt.Sprintf("stop loss: %v", err))
+		}
+	}
+	if takeProfit > 0 {
+		if _, err := bp.placeConditionalOrder(ctx, symbol, binanceOrderTypeTakeProfit, closeSide, "", models.FormatStep(takeProfit, filters.tickSize)); err != nil {
+			protectionErrors = append(protectionErrors, fmt.Sprintf("take profit: %v", err))
+		}
+	}
+	if len(protectionErrors) > 0 {
+		// La posizione è aperta: non si restituisce errore ma si segnala la protezione mancante
+		orderResp.ErrorMessage = "ordini di protezione non piazzati: " + strings.Join(protectionErrors, "; ")
+		log.Printf("ATTENZIONE: %s (ordine %s)", orderResp.ErrorMessage, orderResp.OrderID)
+	}
+
+	return orderResp, nil
+}
+
+// placeConditionalOrder piazza un ordine condizionale closePosition (stop loss o take profit)
+// positionSide è vuoto in modalità one-way, LONG o SHORT in modalità hedge
+func (bp *BinanceOrderProcessor) placeConditionalOrder(ctx context.Context, symbol, orderType string, side models.OrderSide, positionSide, triggerPrice string) (*BinanceAlgoOrder, error) {
+	params := url.Values{}
+	params.Set("algoType", "CONDITIONAL")
+	params.Set("symbol", symbol)
+	params.Set("side", binanceSide(side))
+	params.Set("type", orderType)
+	params.Set("triggerPrice", triggerPrice)
+	params.Set("closePosition", "true")
+	params.Set("workingType", binanceWorkingType)
+	if positionSide != "" {
+		params.Set("positionSide", positionSide)
+	}
+
+	var algoOrder BinanceAlgoOrder
+	if err := bp.doRequest(ctx, http.MethodPost, binanceAlgoOrderEndpoint, params, true, &algoOrder); err != nil {
+		return nil, err
+	}
+	return &algoOrder, nil
+}
+
+// DeleteOrder cancella un ordine usando l'orderId Binance (numerico) o il clientOrderId
+func (bp *BinanceOrderProcessor) DeleteOrder(ctx context.Context, symbol, orderID string) (*models.OrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	setBinanceOrderID(params, orderID)
+
+	var order BinanceOrder
+	if err := bp.doRequest(ctx, http.MethodDelete, binanceOrderEndpoint, params, true, &order); err != nil {
+		var apiErr *BinanceAPIError
+		if errors.As(err, &apiErr) {
+			return &models.OrderResponse{
+				OrderID:      orderID,
+				Symbol:       symbol,
+				Status:       models.OrderStatusRejected,
+				UpdatedTime:  time.Now(),
+				ErrorCode:    strconv.Itoa(apiErr.Code),
+				ErrorMessage: apiErr.Message,
+			}, nil
+		}
+		return nil, fmt.Errorf("errore nella cancellazione dell'ordine: %w", err)
+	}
+
+	return convertBinanceOrder(&order), nil
+}
+
+// AmendOrder modifica prezzo e quantità di un ordine limit ancora aperto
+// Binance consente la modifica solo degli ordini LIMIT; il lato dell'ordine è obbligatorio
+func (bp *BinanceOrderProcessor) AmendOrder(ctx context.Context, symbol, orderID string, side models.OrderSide, price, quantity float64) (*models.OrderResponse, error) {
+	filters, err := bp.symbolFilters(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	setBinanceOrderID(params, orderID)
+	params.Set("side", binanceSide(side))
+	params.Set("quantity", models.FormatStep(models.FloorToStep(quantity, filters.stepSize), filters.stepSize))
+	params.Set("price", models.FormatStep(price, filters.tickSize))
+
+	var order BinanceOrder
+	if err := bp.doRequest(ctx, http.MethodPut, binanceOrderEndpoint, params, true, &order); err != nil {
+		return nil, fmt.Errorf("errore nella modifica dell'ordine: %w", err)
+	}
+
+	return convertBinanceOrder(&order), nil
+}
+
+// UpdateOrder aggiorna stop loss e/o take profit della posizione sul simbolo indicato
+// Gli ordini condizionali esistenti dello stesso tipo vengono cancellati e sostituiti
+func (bp *BinanceOrderProcessor) UpdateOrder(ctx context.Context, params UpdateOrderParams) (*models.OrderResponse, error) {
+	if params.StopLoss == nil && params.TakeProfit == nil {
+		return nil, fmt.Errorf("almeno uno tra StopLoss e TakeProfit deve essere specificato")
+	}
+
+	filters, err := bp.symbolFilters(ctx, params.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	positions, err := bp.GetPositions(ctx, params.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// PositionIdx segue la convenzione Bybit: 0 one-way, 1 lato long e 2 lato short in hedge mode
+	positionSide := ""
+	switch params.PositionIdx {
+	case 1:
+		positionSide = "LONG"
+	case 2:
+		positionSide = "SHORT"
+	}
+
+	var position *models.Position
+	for i := range positions {
+		if params.PositionIdx == 0 || positions[i].PositionIdx == params.PositionIdx {
+			position = &positions[i]
+			break
+		}
+	}
+	if position == nil {
+		return nil, fmt.Errorf("nessuna posizione aperta per %s", params.Symbol)
+	}
+
+	closeSide := models.OrderSideSell
+	if position.IsShort() {
+		closeSide = models.OrderSideBuy
+	}
+
+	openParams := url.Values{}
+	openParams.Set("symbol", params.Symbol)
+	var openOrders []BinanceAlgoOrder
+	if err := bp.doRequest(ctx, http.MethodGet, binanceOpenAlgoOrdersEndpoint, openParams, true, &openOrders); err != nil {
+		return nil, fmt.Errorf("errore nel recupero degli ordini condizionali: %w", err)
+	}
+
+	orderResp := &models.OrderResponse{
+		Symbol:      params.Symbol,
+		Side:        models.OrderSide(position.Side),
+		Status:      models.OrderStatusNew,
+		UpdatedTime: time.Now(),
+	}
+
+	if params.StopLoss != nil {
+		if err := bp.replaceConditionalOrder(ctx, openOrders, params.Symbol, binanceOrderTypeStopMarket, closeSide, positionSide, models.FormatStep(*params.StopLoss, filters.tickSize)); err != nil {
+			return nil, fmt.Errorf("errore nell'aggiornamento dello stop loss: %w", err)
+		}
+		orderResp.StopLoss = *params.StopLoss
+	}
+	if params.TakeProfit != nil {
+		if err := bp.replaceConditionalOrder(ctx, openOrders, params.Symbol, binanceOrderTypeTakeProfit, closeSide, positionSide, models.FormatStep(*params.TakeProfit, filters.tickSize)); err != nil {
+			return nil, fmt.Errorf("errore nell'aggiornamento del take profit: %w", err)
+		}
+		orderResp.TakeProfit = *params.TakeProfit
+	}
+
+	return orderResp, nil
+}
+
+// replaceConditionalOrder cancella gli ordini condizionali dello stesso tipo e ne piazza uno nuovo
+func (bp *BinanceOrderProcessor) replaceConditionalOrder(ctx context.Context, openOrders []BinanceAlgoOrder, symbol, orderType string, side models.OrderSide, positionSide, triggerPrice string) error {
+	for _, order := range openOrders {
+		if order.Symbol != symbol || order.OrderType != orderType {
+			continue
+		}
+		if positionSide != "" && order.PositionSide != positionSide {
+			continue
+		}
+
+		params := url.Values{}
+		params.Set("algoId", strconv.FormatInt(order.AlgoID, 10))
+		if err := bp.doRequest(ctx, http.MethodDelete, binanceAlgoOrderEndpoint, params, true, nil); err != nil {
+			return err
+		}
+	}
+
+	_, err := bp.placeConditionalOrder(ctx, symbol, orderType, side, positionSide, triggerPrice)
+	return err
+}
+
+// GetOrderStatus recupera lo stato di un ordine tramite orderId Binance o clientOrderId
+func (bp *BinanceOrderProcessor) GetOrderStatus(ctx context.Context, symbol, orderID string) (*models.OrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	setBinanceOrderID(params, orderID)
+
+	var order BinanceOrder
+	if err := bp.doRequest(ctx, http.MethodGet, binanceOrderEndpoint, params, true, &order); err != nil {
+		return nil, fmt.Errorf("errore nel recupero dello stato dell'ordine: %w", err)
+	}
+
+	return convertBinanceOrder(&order), nil
+}
+
+// GetPositions recupera le posizioni aperte, filtrate per simbolo se indicato
+func (bp *BinanceOrderProcessor) GetPositions(ctx context.Context, symbol string) ([]models.Position, error) {
+	params := url.Values{}
+	if symbol != "" {
+		params.Set("symbol", symbol)
+	}
+
+	var binancePositions []BinancePosition
+	if err := bp.doRequest(ctx, http.MethodGet, binancePositionRiskEndpoint, params, true, &binancePositions); err != nil {
+		return nil, fmt.Errorf("errore nel recupero delle posizioni: %w", err)
+	}
+
+	positions := make([]models.Position, 0, len(binancePositions))
+	for _, p := range binancePositions {
+		amount, _ := strconv.ParseFloat(p.PositionAmt, 64)
+		if amount == 0 {
+			continue
+		}
+
+		position := models.Position{
+			Symbol:         p.Symbol,
+			Side:           models.PositionSideBuy,
+			Size:           strconv.FormatFloat(math.Abs(amount), 'f', -1, 64),
+			EntryPrice:     p.EntryPrice,
+			MarkPrice:      p.MarkPrice,
+			UnrealisedPnl:  p.UnRealizedProfit,
+			Leverage:       p.Leverage,
+			IsIsolated:     p.MarginType == "isolated",
+			PositionStatus: models.PositionStatusNormal,
+			UpdatedTime:    strconv.FormatInt(p.UpdateTime, 10),
+		}
+		if amount < 0 || p.PositionSide == "SHORT" {
+			position.Side = models.PositionSideSell
+		}
+		switch p.PositionSide {
+		case "LONG":
+			position.PositionIdx = 1
+		case "SHORT":
+			position.PositionIdx = 2
+		}
+		if p.MarginType == "isolated" {
+			position.PositionBalance = p.IsolatedMargin
+		}
+		position.Parse()
+
+		positions = append(positions, position)
+	}
+
+	return positions, nil
+}
+
+// SetLeverage imposta la leva per un simbolo
+func (bp *BinanceOrderProcessor) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if leverage < 1 {
+		return fmt.Errorf("leva non valida: %d", leverage)
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("leverage", strconv.Itoa(leverage))
+
+	var leverageResp BinanceLeverageResponse
+	if err := bp.doRequest(ctx, http.MethodPost, binanceLeverageEndpoint, params, true, &leverageResp); err != nil {
+		return fmt.Errorf("errore nell'impostazione della leva: %w", err)
+	}
+
+	log.Printf("Leva Binance per %s impostata a %dx (nozionale massimo %s)", symbol, leverageResp.Leverage, leverageResp.MaxNotionalValue)
+	return nil
+}
+
+// GetWalletBalance recupera i saldi del conto futures nel formato del wallet Bybit
+// accountType viene ignorato: Binance USDT-M ha un solo conto futures
+func (bp *BinanceOrderProcessor) GetWalletBalance(ctx context.Context, accountType, coin string) (*models.WalletBalanceResponse, error) {
+	var account BinanceAccount
+	if err := bp.doRequest(ctx, http.MethodGet, binanceAccountEndpoint, nil, true, &account); err != nil {
+		return nil, fmt.Errorf("errore nel recupero del saldo: %w", err)
+	}
+
+	now := time.Now()
+	info := models.AccountInfo{
+		AccountType:           "FUTURES",
+		TotalEquity:           account.TotalMarginBalance,
+		TotalWalletBalance:    account.TotalWalletBalance,
+		TotalMarginBalance:    account.TotalMarginBalance,
+		TotalAvailableBalance: account.AvailableBalance,
+		UpdatedAt:             now,
+	}
+	for _, asset := range account.Assets {
+		if coin != "" && !strings.EqualFold(asset.Asset, coin) {
+			continue
+		}
+		info.Coins = append(info.Coins, models.WalletBalance{
+			Coin:                asset.Asset,
+			Equity:              asset.MarginBalance,
+			WalletBalance:       asset.WalletBalance,
+			AvailableToWithdraw: asset.MaxWithdrawAmount,
+			UpdatedAt:           now,
+		})
+	}
+
+	walletResp := &models.WalletBalanceResponse{RetMsg: "OK", Time: now.UnixMilli()}
+	walletResp.Result.List = []models.AccountInfo{info}
+	return walletResp, nil
+}
+
+// GetUSDTBalance recupera il saldo USDT del conto futures
+func (bp *BinanceOrderProcessor) GetUSDTBalance(ctx context.Context) (float64, error) {
+	return bp.GetCoinBalance(ctx, "USDT")
+}
+
+// GetCoinBalance recupera il saldo di un asset del conto futures
+func (bp *BinanceOrderProcessor) GetCoinBalance(ctx context.Context, coin string) (float64, error) {
+	walletResp, err := bp.GetWalletBalance(ctx, "", coin)
+	if err != nil {
+		return 0, fmt.Errorf("errore nel recupero saldo %s: %w", coin, err)
+	}
+
+	coinBalance, found := walletResp.GetCoinBalance(coin)
+	if !found {
+		return 0, fmt.Errorf("saldo %s non trovato", coin)
+	}
+
+	balance, err := coinBalance.GetEquityFloat()
+	if err != nil {
+		return 0, fmt.Errorf("errore nella conversione del saldo %s: %w", coin, err)
+	}
+	return balance, nil
+}
+
+// symbolFilters restituisce tick size e step size del simbolo, caricandoli una volta da exchangeInfo
+func (bp *BinanceOrderProcessor) symbolFilters(ctx context.Context, symbol string) (binanceSymbolFilters, error) {
+	bp.filtersMu.Lock()
+	defer bp.filtersMu.Unlock()
+
+	if filters, ok := bp.filters[symbol]; ok {
+		return filters, nil
+	}
+
+	var info binanceExchangeInfo
+	if err := bp.doRequest(ctx, http.MethodGet, binanceExchangeInfoEndpoint, nil, false, &info); err != nil {
+		return binanceSymbolFilters{}, fmt.Errorf("errore nel recupero delle regole del simbolo: %w", err)
+	}
+
+	for _, s := range info.Symbols {
+		var filters binanceSymbolFilters
+		for _, f := range s.Filters {
+			switch f.FilterType {
+			case "PRICE_FILTER":
+				filters.tickSize, _ = strconv.ParseFloat(f.TickSize, 64)
+			case "LOT_SIZE":
+				filters.stepSize, _ = strconv.ParseFloat(f.StepSize, 64)
+			}
+		}
+		bp.filters[s.Symbol] = filters
+	}
+
+	filters, ok := bp.filters[symbol]
+	if !ok {
+		return binanceSymbolFilters{}, fmt.Errorf("simbolo %s non disponibile su Binance futures", symbol)
+	}
+	return filters, nil
+}
+
+// GetAccountInfo recupera la configurazione dell'account futures
+// Su Binance il margine isolato si imposta per simbolo: la modalità è incrociata solo con il multi-asset attivo
+func (bp *BinanceOrderProcessor) GetAccountInfo(ctx context.Context) (*models.AccountSettings, error) {
+	var mode BinanceMultiAssetsMode
+	if err := bp.doRequest(ctx, http.MethodGet, binanceMultiAssetsEndpoint, nil, true, &mode); err != nil {
+		return nil, fmt.Errorf("errore nel recupero della modalità multi-asset: %w", err)
+	}
+
+	settings := &models.AccountSettings{Exchange: "binance", Unified: mode.MultiAssetsMargin, FetchedAt: time.Now()}
+	if mode.MultiAssetsMargin {
+		settings.MarginMode = models.MarginModeRegular
+	}
+	return settings, nil
+}
+
+// GetFeeRate recupera le commissioni maker e taker dell'account sul simbolo indicato
+func (bp *BinanceOrderProcessor) GetFeeRate(ctx context.Context, symbol string) (*models.FeeRate, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+
+	var commission BinanceCommissionRate
+	if err := bp.doRequest(ctx, http.MethodGet, binanceCommissionEndpoint, params, true, &commission); err != nil {
+		return nil, fmt.Errorf("errore nel recupero delle commissioni: %w", err)
+	}
+
+	taker, err := strconv.ParseFloat(commission.TakerCommissionRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("commissione taker non valida per %s: %q", symbol, commission.TakerCommissionRate)
+	}
+	maker, err := strconv.ParseFloat(commission.MakerCommissionRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("commissione maker non valida per %s: %q", symbol, commission.MakerCommissionRate)
+	}
+	return &models.FeeRate{Exchange: "binance", Symbol: symbol, MakerRate: maker, TakerRate: taker, FetchedAt: time.Now()}, nil
+}
+
+// doRequest esegue una richiesta alle API futures, firmandola se signed è true
+// I parametri viaggiano sempre in query string, come consentito da Binance anche per POST/PUT/DELETE
+func (bp *BinanceOrderProcessor) doRequest(ctx context.Context, method, endpoint string, params url.Values, signed bool, out interface{}) error {
+	if params == nil {
+		params = url.Values{}
+	}
+
+	query := params.Encode()
+	if signed {
+		params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
+		params.Set("recvWindow", outage.RecvWindow("binance", binanceRecvWindow))
+		query = params.Encode()
+		query += "&signature=" + hmacSignature(bp.apiSecret, query)
+	}
+
+	target := bp.baseURL + endpoint
+	if query != "" {
+		target += "?" + query
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return fmt.Errorf("errore nella creazione della richiesta HTTP: %w", err)
+	}
+	if signed {
+		req.Header.Set("X-MBX-APIKEY", bp.apiKey)
+	}
+
+	resp, err := bp.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("errore nell'esecuzione della richiesta: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("errore nella lettura della risposta: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &BinanceAPIError{}
+		if err := json.Unmarshal(body, apiErr); err != nil || apiErr.Message == "" {
+			return fmt.Errorf("errore HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+		return apiErr
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("errore nella decodifica della risposta: %w", err)
+		}
+	}
+	return nil
+}
+
+// convertBinanceOrder converte un ordine Binance nel formato interno
+func convertBinanceOrder(order *BinanceOrder) *models.OrderResponse {
+	orderResp := &models.OrderResponse{
+		OrderID:     strconv.FormatInt(order.OrderID, 10),
+		OrderLinkID: order.ClientOrderID,
+		Symbol:      order.Symbol,
+		Side:        orderSideFromBinance(order.Side),
+		OrderType:   models.OrderTypeLimit,
+		Status:      mapBinanceOrderStatus(order.Status),
+		CreatedTime: time.UnixMilli(order.Time),
+		UpdatedTime: time.UnixMilli(order.UpdateTime),
+	}
+	if order.Type == binanceOrderTypeMarket {
+		orderResp.OrderType = models.OrderTypeMarket
+	}
+
+	orderResp.Price, _ = strconv.ParseFloat(order.Price, 64)
+	orderResp.AveragePrice, _ = strconv.ParseFloat(order.AvgPrice, 64)
+	orderResp.Quantity, _ = strconv.ParseFloat(order.OrigQty, 64)
+	orderResp.TriggerPrice, _ = strconv.ParseFloat(order.StopPrice, 64)
+
+	// Un ordine cancellato dopo un'esecuzione parziale ha executedQty > 0
+	if orderResp.Status == models.OrderStatusCancelled {
+		if executed, _ := strconv.ParseFloat(order.ExecutedQty, 64); executed > 0 {
+			orderResp.Status = models.OrderStatusPartiallyFilledCanceled
+		}
+	}
+
+	return orderResp
+}
+
+// mapBinanceOrderStatus converte lo stato Binance nello stato interno
+func mapBinanceOrderStatus(status string) models.OrderStatus {
+	switch status {
+	case "NEW":
+		return models.OrderStatusNew
+	case "PARTIALLY_FILLED":
+		return models.OrderStatusPartiallyFilled
+	case "FILLED":
+		return models.OrderStatusFilled
+	case "CANCELED", "EXPIRED", "EXPIRED_IN_MATCH":
+		return models.OrderStatusCancelled
+	case "REJECTED":
+		return models.OrderStatusRejected
+	default:
+		return models.OrderStatus(status)
+	}
+}
+
+// setBinanceOrderID imposta orderId se l'ID è numerico, altrimenti origClientOrderId
+func setBinanceOrderID(params url.Values, orderID string) {
+	if _, err := strconv.ParseInt(orderID, 10, 64); err == nil {
+		params.Set("orderId", orderID)
+	} else {
+		params.Set("origClientOrderId", orderID)
+	}
+}
+
+// binanceSide converte il lato dell'ordine nel formato Binance (BUY/SELL)
+func binanceSide(side models.OrderSide) string {
+	if side == models.OrderSideSell {
+		return "SELL"
+	}
+	return "BUY"
+}
+
+// orderSideFromBinance converte il lato Binance nel formato interno
+func orderSideFromBinance(side string) models.OrderSide {
+	if side == "SELL" {
+		return models.OrderSideSell
+	}
+	return models.OrderSideBuy
+}
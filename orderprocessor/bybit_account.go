@@ -0,0 +1,229 @@
+package orderprocessor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/outage"
+)
+
+const (
+	// bybitAccountInfoEndpoint è l'endpoint della configurazione dell'account (UTA e modalità di margine)
+	bybitAccountInfoEndpoint = "/v5/account/info"
+
+	// bybitFeeRateEndpoint è l'endpoint delle commissioni effettive dell'account per simbolo
+	bybitFeeRateEndpoint = "/v5/account/fee-rate"
+
+	// bybitCollateralInfoEndpoint è l'endpoint dei tassi di prestito e degli importi presi in prestito dell'account unificato
+	bybitCollateralInfoEndpoint = "/v5/account/collateral-info"
+
+	// bybitUnifiedStatusUTA è il primo stato di /v5/account/info che indica un account unificato (UTA 1.0)
+	bybitUnifiedStatusUTA = 3
+)
+
+// BybitAccountInfoResponse rappresenta la risposta di /v5/account/info
+type BybitAccountInfoResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		UnifiedMarginStatus int    `json:"unifiedMarginStatus"` // 1 classico, 3-4 UTA 1.0, 5-6 UTA 2.0
+		MarginMode          string `json:"marginMode"`          // REGULAR_MARGIN, ISOLATED_MARGIN, PORTFOLIO_MARGIN
+		UpdatedTime         string `json:"updatedTime"`
+	} `json:"result"`
+}
+
+// BybitFeeRateResponse rappresenta la risposta di /v5/account/fee-rate
+type BybitFeeRateResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		List []struct {
+			Symbol       string `json:"symbol"`
+			TakerFeeRate string `json:"takerFeeRate"`
+			MakerFeeRate string `json:"makerFeeRate"`
+		} `json:"list"`
+	} `json:"result"`
+}
+
+// BybitCollateralInfoResponse rappresenta la risposta di /v5/account/collateral-info
+type BybitCollateralInfoResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		List []struct {
+			Currency         string `json:"currency"`
+			HourlyBorrowRate string `json:"hourlyBorrowRate"`
+			BorrowAmount     string `json:"borrowAmount"`
+			Borrowable       bool   `json:"borrowable"`
+		} `json:"list"`
+	} `json:"result"`
+}
+
+// GetAccountInfo implementa AccountReader leggendo la configurazione dell'account unificato
+func (bp *BybitOrderProcessor) GetAccountInfo(ctx context.Context) (*models.AccountSettings, error) {
+	var infoResp BybitAccountInfoResponse
+	if err := bp.signedGet(ctx, bybitAccountInfoEndpoint, url.Values{}, &infoResp); err != nil {
+		return nil, err
+	}
+	if infoResp.RetCode != 0 {
+		return nil, fmt.Errorf("errore API Bybit: %s (codice: %d)", infoResp.RetMsg, infoResp.RetCode)
+	}
+
+	return &models.AccountSettings{
+		Exchange:      "bybit",
+		Unified:       infoResp.Result.UnifiedMarginStatus >= bybitUnifiedStatusUTA,
+		UnifiedStatus: infoResp.Result.UnifiedMarginStatus,
+		MarginMode:    models.MarginMode(infoResp.Result.MarginMode),
+		FetchedAt:     time.Now(),
+	}, nil
+}
+
+// GetFeeRate implementa AccountReader leggendo le commissioni dell'account sul perpetual indicato
+func (bp *BybitOrderProcessor) GetFeeRate(ctx context.Context, symbol string) (*models.FeeRate, error) {
+	params := url.Values{}
+	params.Set("category", derivativesCategory)
+	params.Set("symbol", symbol)
+
+	var feeResp BybitFeeRateResponse
+	if err := bp.signedGet(ctx, bybitFeeRateEndpoint, params, &feeResp); err != nil {
+		return nil, err
+	}
+	if feeResp.RetCode != 0 {
+		return nil, fmt.Errorf("errore API Bybit: %s (codice: %d)", feeResp.RetMsg, feeResp.RetCode)
+	}
+
+	for _, item := range feeResp.Result.List {
+		if !strings.EqualFold(item.Symbol, symbol) {
+			continue
+		}
+		taker, err := strconv.ParseFloat(item.TakerFeeRate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("commissione taker non valida per %s: %q", symbol, item.TakerFeeRate)
+		}
+		maker, err := strconv.ParseFloat(item.MakerFeeRate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("commissione maker non valida per %s: %q", symbol, item.MakerFeeRate)
+		}
+		return &models.FeeRate{Exchange: "bybit", Symbol: symbol, MakerRate: maker, TakerRate: taker, FetchedAt: time.Now()}, nil
+	}
+	return nil, fmt.Errorf("nessuna commissione restituita per %s", symbol)
+}
+
+// GetBorrowRate implementa BorrowRateReader leggendo tasso orario e importo preso in prestito di una coin
+func (bp *BybitOrderProcessor) GetBorrowRate(ctx context.Context, coin string) (*models.BorrowRate, error) {
+	params := url.Values{}
+	params.Set("currency", coin)
+
+	var collateralResp BybitCollateralInfoResponse
+	if err := bp.signedGet(ctx, bybitCollateralInfoEndpoint, params, &collateralResp); err != nil {
+		return nil, err
+	}
+	if collateralResp.RetCode != 0 {
+		return nil, fmt.Errorf("errore API Bybit: %s (codice: %d)", collateralResp.RetMsg, collateralResp.RetCode)
+	}
+
+	for _, item := range collateralResp.Result.List {
+		if !strings.EqualFold(item.Currency, coin) {
+			continue
+		}
+		if !item.Borrowable {
+			return nil, fmt.Errorf("%s non può essere presa in prestito", coin)
+		}
+		rate, err := strconv.ParseFloat(item.HourlyBorrowRate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("tasso di prestito non valido per %s: %q", coin, item.HourlyBorrowRate)
+		}
+		// borrowAmount è vuoto quando l'account non ha prestiti aperti
+		borrowed, _ := strconv.ParseFloat(item.BorrowAmount, 64)
+		return &models.BorrowRate{Exchange: "bybit", Coin: coin, HourlyRate: rate, BorrowAmount: borrowed, FetchedAt: time.Now()}, nil
+	}
+	return nil, fmt.Errorf("nessun tasso di prestito restituito per %s", coin)
+}
+
+// signedGet esegue una richiesta GET firmata e decodifica la risposta in dest
+func (bp *BybitOrderProcessor) signedGet(ctx context.Context, endpoint string, params url.Values, dest any) error {
+	queryString := params.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", bybitAPIBaseURL+endpoint+"?"+queryString, nil)
+	if err != nil {
+		return fmt.Errorf("errore nella creazione della richiesta HTTP: %w", err)
+	}
+
+	// Per richieste GET, il payload per la firma è costituito dai parametri query
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, queryString)
+	if err != nil {
+		return fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
+
+	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", recv_window)
+	req.Header.Set("X-BAPI-SIGN", signature)
+
+	resp, err := bp.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("errore nell'esecuzione della richiesta: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("errore nella lettura della risposta: %w", err)
+	}
+	if err := json.Unmarshal(body, dest); err != nil {
+		return fmt.Errorf("errore nella decodifica della risposta: %w", err)
+	}
+	return nil
+}
+
+// signedPost esegue una richiesta POST firmata con il payload JSON indicato e decodifica la risposta in dest
+func (bp *BybitOrderProcessor) signedPost(ctx context.Context, endpoint string, payload any, dest any) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("errore nella serializzazione della richiesta: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", bybitAPIBaseURL+endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("errore nella creazione della richiesta HTTP: %w", err)
+	}
+
+	// Per richieste POST, il payload per la firma è il body JSON
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	if err != nil {
+		return fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", recv_window)
+	req.Header.Set("X-BAPI-SIGN", signature)
+
+	resp, err := bp.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("errore nell'esecuzione della richiesta: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("errore nella lettura della risposta: %w", err)
+	}
+	if err := json.Unmarshal(body, dest); err != nil {
+		return fmt.Errorf("errore nella decodifica della risposta: %w", err)
+	}
+	return nil
+}
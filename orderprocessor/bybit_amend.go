@@ -0,0 +1,84 @@
+package orderprocessor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/outage"
+)
+
+// bybitAmendOrderEndpoint è l'endpoint per modificare un ordine aperto
+const bybitAmendOrderEndpoint = "/v5/order/amend"
+
+// BybitAmendOrderRequest rappresenta la richiesta di modifica del prezzo di un ordine
+type BybitAmendOrderRequest struct {
+	Category    string `json:"category"`
+	Symbol      string `json:"symbol"`
+	OrderID     string `json:"orderId,omitempty"`
+	OrderLinkID string `json:"orderLinkId,omitempty"`
+	Price       string `json:"price"`
+}
+
+// AmendOrderPrice implementa OrderAmender spostando il prezzo di un ordine limite aperto
+// L'ordine mantiene ID, quantità eseguita e priorità di tempo sul nuovo livello
+func (bp *BybitOrderProcessor) AmendOrderPrice(ctx context.Context, symbol, orderID string, price float64) error {
+	amendReq := BybitAmendOrderRequest{
+		Category: derivativesCategory,
+		Symbol:   symbol,
+		Price:    models.FormatStep(price, bp.priceTick(ctx, symbol)),
+	}
+	if isUUIDFormat(orderID) {
+		amendReq.OrderID = orderID
+	} else {
+		amendReq.OrderLinkID = orderID
+	}
+
+	jsonData, err := json.Marshal(amendReq)
+	if err != nil {
+		return fmt.Errorf("errore nella serializzazione della modifica: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", bybitAPIBaseURL+bybitAmendOrderEndpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("errore nella creazione della richiesta HTTP: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	recvWindow := outage.RecvWindow("bybit", bybitRecvWindow)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recvWindow, string(jsonData))
+	if err != nil {
+		return fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", recvWindow)
+	req.Header.Set("X-BAPI-SIGN", signature)
+
+	resp, err := bp.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("errore nell'esecuzione della richiesta di modifica: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("errore nella lettura della risposta: %w", err)
+	}
+
+	// La risposta ha la stessa forma di quella della cancellazione
+	var amendResp BybitCancelOrderResponse
+	if err := json.Unmarshal(body, &amendResp); err != nil {
+		return fmt.Errorf("errore nella decodifica della risposta: %w", err)
+	}
+	if amendResp.RetCode != 0 {
+		return fmt.Errorf("errore API Bybit: %s (codice: %d)", amendResp.RetMsg, amendResp.RetCode)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package orderprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/outage"
+)
+
+// bybitInstrumentsInfoEndpoint è l'endpoint pubblico delle regole di negoziazione dei simboli
+const bybitInstrumentsInfoEndpoint = "/v5/market/instruments-info"
+
+// BybitInstrumentsInfoResponse rappresenta la risposta di /v5/market/instruments-info
+type BybitInstrumentsInfoResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		List []struct {
+			Symbol         string `json:"symbol"`
+			LeverageFilter struct {
+				MaxLeverage string `json:"maxLeverage"`
+			} `json:"leverageFilter"`
+			PriceFilter struct {
+				TickSize string `json:"tickSize"`
+			} `json:"priceFilter"`
+			LotSizeFilter struct {
+				MinOrderQty      string `json:"minOrderQty"`
+				MaxMktOrderQty   string `json:"maxMktOrderQty"`
+				QtyStep          string `json:"qtyStep"`
+				MinNotionalValue string `json:"minNotionalValue"`
+			} `json:"lotSizeFilter"`
+		} `json:"list"`
+	} `json:"result"`
+}
+
+// GetInstrumentInfo implementa MarginReader
+// Le regole cambiano raramente: vengono lette una volta per simbolo e poi servite dalla memoria
+func (bp *BybitOrderProcessor) GetInstrumentInfo(ctx context.Context, symbol string) (*models.InstrumentInfo, error) {
+	bp.instrumentsMu.Lock()
+	defer bp.instrumentsMu.Unlock()
+
+	if info, ok := bp.instruments[symbol]; ok {
+		return info, nil
+	}
+
+	params := url.Values{}
+	params.Set("category", derivativesCategory)
+	params.Set("symbol", symbol)
+	req, err := http.NewRequestWithContext(ctx, "GET", bybitAPIBaseURL+bybitInstrumentsInfoEndpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella creazione della richiesta HTTP: %w", err)
+	}
+
+	resp, err := bp.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("errore nell'esecuzione della richiesta: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella lettura della risposta: %w", err)
+	}
+
+	var infoResp BybitInstrumentsInfoResponse
+	if err := json.Unmarshal(body, &infoResp); err != nil {
+		return nil, fmt.Errorf("errore nella decodifica della risposta: %w", err)
+	}
+	if infoResp.RetCode != 0 {
+		return nil, fmt.Errorf("errore API Bybit: %s (codice: %d)", infoResp.RetMsg, infoResp.RetCode)
+	}
+	if len(infoResp.Result.List) == 0 {
+		return nil, fmt.Errorf("simbolo %s non disponibile sui perpetual Bybit", symbol)
+	}
+
+	item := infoResp.Result.List[0]
+	info := &models.InstrumentInfo{Symbol: item.Symbol}
+	info.MinOrderQty, _ = strconv.ParseFloat(item.LotSizeFilter.MinOrderQty, 64)
+	info.MaxMarketQty, _ = strconv.ParseFloat(item.LotSizeFilter.MaxMktOrderQty, 64)
+	info.QtyStep, _ = strconv.ParseFloat(item.LotSizeFilter.QtyStep, 64)
+	info.MinNotional, _ = strconv.ParseFloat(item.LotSizeFilter.MinNotionalValue, 64)
+	info.TickSize, _ = strconv.ParseFloat(item.PriceFilter.TickSize, 64)
+	info.MaxLeverage, _ = strconv.ParseFloat(item.LeverageFilter.MaxLeverage, 64)
+	if info.QtyStep <= 0 {
+		return nil, fmt.Errorf("passo della quantità non valido per %s: %q", symbol, item.LotSizeFilter.QtyStep)
+	}
+
+	if bp.instruments == nil {
+		bp.instruments = make(map[string]*models.InstrumentInfo)
+	}
+	bp.instruments[symbol] = info
+	return info, nil
+}
+
+// GetAvailableMargin implementa MarginReader con il saldo disponibile dell'account unificato,
+// già al netto del margine impegnato da posizioni e ordini aperti
+func (bp *BybitOrderProcessor) GetAvailableMargin(ctx context.Context) (float64, error) {
+	walletResp, err := bp.GetWalletBalance(ctx, "UNIFIED", "")
+	if err != nil {
+		return 0, err
+	}
+	account := walletResp.GetFirstAccount()
+	if account == nil {
+		return 0, fmt.Errorf("account unificato non trovato")
+	}
+	available, err := account.GetTotalAvailableBalanceFloat()
+	if err != nil {
+		return 0, fmt.Errorf("saldo disponibile non valido %q: %w", account.TotalAvailableBalance, err)
+	}
+	return available, nil
+}
+
+// GetLeverage implementa MarginReader
+// Bybit restituisce la posizione del simbolo, con la leva impostata, anche quando la size è zero
+func (bp *BybitOrderProcessor) GetLeverage(ctx context.Context, symbol string) (float64, error) {
+	params := url.Values{}
+	params.Set("category", derivativesCategory)
+	params.Set("symbol", symbol)
+	queryString := params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", bybitAPIBaseURL+bybitGetPositionsEndpoint+"?"+queryString, nil)
+	if err != nil {
+		return 0, fmt.Errorf("errore nella creazione della richiesta HTTP: %w", err)
+	}
+
+	// Per richieste GET, il payload per la firma è costituito dai parametri query
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, queryString)
+	if err != nil {
+		return 0, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
+
+	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", recv_window)
+	req.Header.Set("X-BAPI-SIGN", signature)
+
+	resp, err := bp.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("errore nell'esecuzione della richiesta: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("errore nella lettura della risposta: %w", err)
+	}
+
+	var positionsResp models.PositionListResponse
+	if err := json.Unmarshal(body, &positionsResp); err != nil {
+		return 0, fmt.Errorf("errore nella decodifica della risposta: %w", err)
+	}
+	if positionsResp.RetCode != 0 {
+		return 0, fmt.Errorf("errore API Bybit: %s (codice: %d)", positionsResp.RetMsg, positionsResp.RetCode)
+	}
+	if len(positionsResp.Result.List) == 0 {
+		return 0, fmt.Errorf("nessuna posizione restituita per %s", symbol)
+	}
+
+	leverage := positionsResp.Result.List[0].Parsed.Leverage
+	if leverage <= 0 {
+		return 0, fmt.Errorf("leva non valida per %s: %q", symbol, positionsResp.Result.List[0].Leverage)
+	}
+	return leverage, nil
+}
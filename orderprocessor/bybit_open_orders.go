@@ -0,0 +1,94 @@
+package orderprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/outage"
+)
+
+// bybitOpenOrdersPageSize è il numero massimo di ordini per pagina di /v5/order/realtime
+const bybitOpenOrdersPageSize = 50
+
+// GetOpenOrders implementa OpenOrderReader
+// Senza simbolo legge gli ordini di tutti i perpetual regolati in USDT, pagina per pagina
+func (bp *BybitOrderProcessor) GetOpenOrders(ctx context.Context, symbol string) ([]models.OrderResponse, error) {
+	var orders []models.OrderResponse
+	cursor := ""
+	for {
+		params := url.Values{}
+		params.Set("category", derivativesCategory)
+		if symbol != "" {
+			params.Set("symbol", symbol)
+		} else {
+			params.Set("settleCoin", "USDT")
+		}
+		params.Set("openOnly", "0")
+		params.Set("limit", strconv.Itoa(bybitOpenOrdersPageSize))
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+
+		page, err := bp.getOrdersPage(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Result.List {
+			orders = append(orders, *item.toOrderResponse())
+		}
+
+		cursor = page.Result.NextPageCursor
+		if cursor == "" || len(page.Result.List) < bybitOpenOrdersPageSize {
+			return orders, nil
+		}
+	}
+}
+
+// getOrdersPage legge una pagina di /v5/order/realtime con i parametri indicati
+func (bp *BybitOrderProcessor) getOrdersPage(ctx context.Context, params url.Values) (*BybitOrderStatusResponse, error) {
+	queryString := params.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", bybitAPIBaseURL+bybitGetOrderStatusEndpoint+"?"+queryString, nil)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella creazione della richiesta HTTP: %w", err)
+	}
+
+	// Per richieste GET, il payload per la firma è costituito dai parametri query
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, queryString)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
+
+	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", recv_window)
+	req.Header.Set("X-BAPI-SIGN", signature)
+
+	resp, err := bp.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("errore nell'esecuzione della richiesta: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella lettura della risposta: %w", err)
+	}
+
+	var ordersResp BybitOrderStatusResponse
+	if err := json.Unmarshal(body, &ordersResp); err != nil {
+		return nil, fmt.Errorf("errore nella decodifica della risposta: %w", err)
+	}
+	if ordersResp.RetCode != 0 {
+		return nil, fmt.Errorf("errore API Bybit: %s (codice: %d)", ordersResp.RetMsg, ordersResp.RetCode)
+	}
+	return &ordersResp, nil
+}
@@ -3,17 +3,18 @@ package orderprocessor
 import (
 	"bytes"
 	"context"
+	"cross-exchange-arbitrage/correlation"
 	"cross-exchange-arbitrage/models"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
+	"cross-exchange-arbitrage/outage"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"math"
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -41,8 +42,14 @@ const (
 	// Endpoint per ottenere il saldo del wallet
 	bybitGetWalletBalanceEndpoint = "/v5/account/wallet-balance"
 
+	// Endpoint per ottenere permessi e scadenza della API key
+	bybitQueryAPIKeyEndpoint = "/v5/user/query-api"
+
 	// Categoria per mercati derivati perpetual
 	derivativesCategory = "linear"
+
+	// Categoria Bybit per il mercato spot
+	spotCategory = "spot"
 )
 
 // RICORDA:
@@ -54,18 +61,28 @@ Quindi se nel caso dovresti usare questo processore per un altro simbolo dovrai
 // BybitOrderProcessor implementa OrderProcessor per Bybit
 type BybitOrderProcessor struct {
 	apiKey     string
-	apiSecret  string
+	signer     Signer
 	httpClient *http.Client
+
+	// Regole di negoziazione dei simboli già lette, usate dal preflight degli ordini
+	instrumentsMu sync.Mutex
+	instruments   map[string]*models.InstrumentInfo
 }
 
 // NewBybitOrderProcessor crea una nuova istanza di BybitOrderProcessor
 func NewBybitOrderProcessor(apiKey, apiSecret string) *BybitOrderProcessor {
+	return NewBybitOrderProcessorWithSigner(apiKey, NewHMACSigner(apiSecret))
+}
+
+// NewBybitOrderProcessorWithSigner crea una nuova istanza che firma le richieste con signer (HMAC o RSA)
+func NewBybitOrderProcessorWithSigner(apiKey string, signer Signer) *BybitOrderProcessor {
 	return &BybitOrderProcessor{
-		apiKey:    apiKey,
-		apiSecret: apiSecret,
-		httpClient: &http.Client{
+		apiKey: apiKey,
+		signer: signer,
+		// Le chiamate riportano l'identificativo del ciclo o della richiesta che le ha originate
+		httpClient: outage.NewClient("bybit", correlation.NewClient(correlation.BybitHeader, &http.Client{
 			Timeout: 30 * time.Second,
-		},
+		})),
 	}
 }
 
@@ -133,27 +150,72 @@ type BybitOrderStatusResponse struct {
 	RetCode int    `json:"retCode"`
 	RetMsg  string `json:"retMsg"`
 	Result  struct {
-		List []struct {
-			OrderID     string `json:"orderId"`
-			OrderLinkID string `json:"orderLinkId"`
-			Symbol      string `json:"symbol"`
-			OrderStatus string `json:"orderStatus"` // New, PartiallyFilled, Untriggered, Rejected, PartiallyFilledCanceled, Filled, Deactivated, Triggered, Cancelled
-			Side        string `json:"side"`
-			OrderType   string `json:"orderType"`
-			Price       string `json:"price"`
-			Qty         string `json:"qty"`
-			CreatedTime string `json:"createdTime"`
-			UpdatedTime string `json:"updatedTime"`
-		} `json:"list"`
+		List           []BybitOrderItem `json:"list"`
+		NextPageCursor string           `json:"nextPageCursor"`
 	} `json:"result"`
 	Time int64 `json:"time"`
 }
 
+// BybitOrderItem rappresenta un ordine restituito da /v5/order/realtime
+type BybitOrderItem struct {
+	OrderID     string `json:"orderId"`
+	OrderLinkID string `json:"orderLinkId"`
+	Symbol      string `json:"symbol"`
+	OrderStatus string `json:"orderStatus"` // New, PartiallyFilled, Untriggered, Rejected, PartiallyFilledCanceled, Filled, Deactivated, Triggered, Cancelled
+	Side        string `json:"side"`
+	OrderType   string `json:"orderType"`
+	Price       string `json:"price"`
+	Qty         string `json:"qty"`
+	CumExecQty  string `json:"cumExecQty"`
+	AvgPrice    string `json:"avgPrice"`
+	CreatedTime string `json:"createdTime"`
+	UpdatedTime string `json:"updatedTime"`
+	// StopOrderType distingue gli ordini condizionali collegati alla posizione (TakeProfit, StopLoss, ...)
+	StopOrderType string `json:"stopOrderType"`
+}
+
+// toOrderResponse converte l'ordine nel formato interno
+func (order BybitOrderItem) toOrderResponse() *models.OrderResponse {
+	orderResp := &models.OrderResponse{
+		OrderID:       order.OrderID,
+		OrderLinkID:   order.OrderLinkID,
+		Symbol:        order.Symbol,
+		Side:          models.OrderSide(order.Side),
+		OrderType:     models.OrderType(order.OrderType),
+		Status:        models.OrderStatus(order.OrderStatus),
+		StopOrderType: order.StopOrderType,
+	}
+
+	// Converte i valori string in float64
+	if order.Price != "" {
+		orderResp.Price, _ = strconv.ParseFloat(order.Price, 64)
+	}
+	if order.Qty != "" {
+		orderResp.Quantity, _ = strconv.ParseFloat(order.Qty, 64)
+	}
+	if order.CumExecQty != "" {
+		orderResp.FilledQuantity, _ = strconv.ParseFloat(order.CumExecQty, 64)
+	}
+	if order.AvgPrice != "" {
+		orderResp.AveragePrice, _ = strconv.ParseFloat(order.AvgPrice, 64)
+	}
+
+	// Converte i timestamp
+	if createdTimeInt, err := strconv.ParseInt(order.CreatedTime, 10, 64); err == nil {
+		orderResp.CreatedTime = time.Unix(createdTimeInt/1000, 0)
+	}
+	if updatedTimeInt, err := strconv.ParseInt(order.UpdatedTime, 10, 64); err == nil {
+		orderResp.UpdatedTime = time.Unix(updatedTimeInt/1000, 0)
+	}
+
+	return orderResp
+}
+
 // PlaceLongOrder implementa l'interfaccia OrderProcessor per ordini long
 // Usa ordini Market per esecuzione immediata
 func (bp *BybitOrderProcessor) PlaceLongOrder(ctx context.Context, symbol string, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
-	// Genera un ID univoco per l'ordine
-	orderLinkID := fmt.Sprintf("long_%s_%d", symbol, time.Now().Unix())
+	// Genera un ID univoco per l'ordine, se il chiamante non ne ha indicato uno
+	orderLinkID := orderLinkIDFromContext(ctx, fmt.Sprintf("long_%s_%d", symbol, time.Now().Unix()))
 
 	//{
 	//   "symbol": "BTCUSDT",
@@ -165,6 +227,7 @@ func (bp *BybitOrderProcessor) PlaceLongOrder(ctx context.Context, symbol string
 	//}
 
 	// Crea la richiesta di ordine Market per LONG (esecuzione immediata)
+	tick := bp.priceTick(ctx, symbol)
 	orderReq := models.OrderRequest{
 		Category:    derivativesCategory,
 		Symbol:      symbol,
@@ -174,8 +237,8 @@ func (bp *BybitOrderProcessor) PlaceLongOrder(ctx context.Context, symbol string
 		TimeInForce: models.TimeInForceIOC,
 		OrderLinkId: orderLinkID,
 		ReduceOnly:  false,
-		StopLoss:    strconv.FormatFloat(stopLoss, 'f', 2, 64),
-		TakeProfit:  strconv.FormatFloat(takeProfit, 'f', 2, 64),
+		StopLoss:    formatOptionalPrice(stopLoss, tick),
+		TakeProfit:  formatOptionalPrice(takeProfit, tick),
 	}
 
 	return bp.placeOrder(ctx, &orderReq, takeProfit, stopLoss)
@@ -184,10 +247,11 @@ func (bp *BybitOrderProcessor) PlaceLongOrder(ctx context.Context, symbol string
 // PlaceShortOrder implementa l'interfaccia OrderProcessor per ordini short
 // Usa ordini Stop per vendere quando il prezzo raggiunge il livello specificato
 func (bp *BybitOrderProcessor) PlaceShortOrder(ctx context.Context, symbol string, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
-	// Genera un ID univoco per l'ordine
-	orderLinkID := fmt.Sprintf("short_%s_%d", symbol, time.Now().Unix())
+	// Genera un ID univoco per l'ordine, se il chiamante non ne ha indicato uno
+	orderLinkID := orderLinkIDFromContext(ctx, fmt.Sprintf("short_%s_%d", symbol, time.Now().Unix()))
 
 	// Crea la richiesta di ordine Market per SHORT (esecuzione immediata)
+	tick := bp.priceTick(ctx, symbol)
 	orderReq := models.OrderRequest{
 		Category:    derivativesCategory,
 		Symbol:      symbol,
@@ -197,13 +261,107 @@ func (bp *BybitOrderProcessor) PlaceShortOrder(ctx context.Context, symbol strin
 		TimeInForce: models.TimeInForceIOC,
 		OrderLinkId: orderLinkID,
 		ReduceOnly:  false,
-		StopLoss:    strconv.FormatFloat(stopLoss, 'f', 2, 64),
-		TakeProfit:  strconv.FormatFloat(takeProfit, 'f', 2, 64),
+		StopLoss:    formatOptionalPrice(stopLoss, tick),
+		TakeProfit:  formatOptionalPrice(takeProfit, tick),
 	}
 
 	return bp.placeOrder(ctx, &orderReq, takeProfit, stopLoss)
 }
 
+// PlaceMarketOrder implementa MarketOrderProcessor con un ordine a mercato sul perpetual
+// A differenza di PlaceLongOrder e PlaceShortOrder la quantità non viene arrotondata a unità intere
+func (bp *BybitOrderProcessor) PlaceMarketOrder(ctx context.Context, symbol string, side models.OrderSide, quantity float64, reduceOnly bool) (*models.OrderResponse, error) {
+	orderReq := models.OrderRequest{
+		Category:    derivativesCategory,
+		Symbol:      symbol,
+		Side:        side,
+		OrderType:   models.OrderTypeMarket,
+		Qty:         models.FormatStep(quantity, 0),
+		TimeInForce: models.TimeInForceIOC,
+		OrderLinkId: GenerateOrderLinkID("market"),
+		ReduceOnly:  reduceOnly,
+	}
+
+	return bp.placeOrder(ctx, &orderReq, 0, 0)
+}
+
+// PlaceLimitOrder implementa LimitOrderProcessor con un ordine limite GTC, o PostOnly, sul perpetual
+func (bp *BybitOrderProcessor) PlaceLimitOrder(ctx context.Context, symbol string, side models.OrderSide, price, quantity, stopLoss, takeProfit float64, postOnly bool) (*models.OrderResponse, error) {
+	timeInForce := models.TimeInForceGTC
+	if postOnly {
+		timeInForce = models.TimeInForcePostOnly
+	}
+	tick := bp.priceTick(ctx, symbol)
+
+	orderReq := models.OrderRequest{
+		Category:    derivativesCategory,
+		Symbol:      symbol,
+		Side:        side,
+		OrderType:   models.OrderTypeLimit,
+		Qty:         models.FormatStep(quantity, 0),
+		Price:       models.FormatStep(price, tick),
+		TimeInForce: timeInForce,
+		OrderLinkId: orderLinkIDFromContext(ctx, GenerateOrderLinkID("limit")),
+		StopLoss:    formatOptionalPrice(stopLoss, tick),
+		TakeProfit:  formatOptionalPrice(takeProfit, tick),
+	}
+
+	orderResp, err := bp.placeOrder(ctx, &orderReq, takeProfit, stopLoss)
+	if err != nil {
+		return nil, err
+	}
+	// Gli ordini limite non sono condizionali
+	if orderResp.Status == models.OrderStatusUntriggered {
+		orderResp.Status = models.OrderStatusNew
+	}
+	return orderResp, nil
+}
+
+// PlaceSpotMarketOrder implementa SpotOrderProcessor con un ordine a mercato sul mercato spot
+// La quantità è espressa nella base coin anche per gli acquisti (marketUnit=baseCoin)
+func (bp *BybitOrderProcessor) PlaceSpotMarketOrder(ctx context.Context, symbol string, side models.OrderSide, quantity float64) (*models.OrderResponse, error) {
+	orderReq := models.OrderRequest{
+		Category:    spotCategory,
+		Symbol:      symbol,
+		Side:        side,
+		OrderType:   models.OrderTypeMarket,
+		Qty:         models.FormatStep(quantity, 0),
+		MarketUnit:  "baseCoin",
+		TimeInForce: models.TimeInForceIOC,
+		OrderLinkId: GenerateOrderLinkID("spot"),
+	}
+
+	orderResp, err := bp.placeOrder(ctx, &orderReq, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	// Gli ordini spot a mercato non sono condizionali
+	if orderResp.Status == models.OrderStatusUntriggered {
+		orderResp.Status = models.OrderStatusNew
+	}
+	return orderResp, nil
+}
+
+// formatOptionalPrice formatta stop loss e take profit al passo del prezzo; un valore non positivo significa "non impostato"
+func formatOptionalPrice(price, tick float64) string {
+	if price <= 0 {
+		return ""
+	}
+	return models.FormatStep(price, tick)
+}
+
+// priceTick restituisce il passo del prezzo del simbolo, con cui formattare i prezzi inviati a Bybit
+// Se non è disponibile restituisce 0: i prezzi vengono inviati con le cifre strettamente necessarie,
+// mai troncati a un numero fisso di decimali che sulle monete con prezzo basso sposterebbe stop e target
+func (bp *BybitOrderProcessor) priceTick(ctx context.Context, symbol string) float64 {
+	info, err := bp.GetInstrumentInfo(ctx, symbol)
+	if err != nil {
+		log.Printf("⚠️  Passo del prezzo di %s non disponibile: %v", symbol, err)
+		return 0
+	}
+	return info.TickSize
+}
+
 // placeOrder invia l'ordine a Bybit usando le API autenticate
 func (bp *BybitOrderProcessor) placeOrder(ctx context.Context, orderReq *models.OrderRequest, takeProfit, stopLoss float64) (*models.OrderResponse, error) {
 
@@ -222,10 +380,13 @@ func (bp *BybitOrderProcessor) placeOrder(ctx context.Context, orderReq *models.
 
 	// Aggiungi headers necessari per l'autenticazione Bybit
 	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	recv_window := "5000"
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
 
-	// Calcola la firma HMAC
-	signature := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	// Calcola la firma (HMAC o RSA a seconda della API key)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
@@ -264,6 +425,7 @@ func (bp *BybitOrderProcessor) placeOrder(ctx context.Context, orderReq *models.
 		UpdatedTime:  time.Unix(apiResp.Time/1000, 0),
 		ErrorCode:    strconv.Itoa(apiResp.RetCode),
 		ErrorMessage: apiResp.RetMsg,
+		RejectReason: bybitRejectReason(apiResp.RetCode, apiResp.RetMsg),
 	}
 
 	// Converte i valori string in float64
@@ -324,8 +486,11 @@ func (bp *BybitOrderProcessor) DeleteOrder(ctx context.Context, symbol, orderID
 
 	// Aggiungi headers per l'autenticazione
 	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	recv_window := "5000"
-	signature := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
@@ -406,13 +571,9 @@ func (bp *BybitOrderProcessor) setTradingStop(ctx context.Context, symbol string
 	}
 
 	// Aggiungi solo i valori > 0 per evitare conflitti
-	if takeProfit > 0 {
-		tradingStopReq.TakeProfit = strconv.FormatFloat(takeProfit, 'f', 2, 64)
-	}
-
-	if stopLoss > 0 {
-		tradingStopReq.StopLoss = strconv.FormatFloat(stopLoss, 'f', 2, 64)
-	}
+	tick := bp.priceTick(ctx, symbol)
+	tradingStopReq.TakeProfit = formatOptionalPrice(takeProfit, tick)
+	tradingStopReq.StopLoss = formatOptionalPrice(stopLoss, tick)
 
 	// Se non c'è nulla da aggiornare, esci
 	if tradingStopReq.TakeProfit == "" && tradingStopReq.StopLoss == "" {
@@ -437,8 +598,11 @@ func (bp *BybitOrderProcessor) setTradingStop(ctx context.Context, symbol string
 
 	// Aggiungi headers per l'autenticazione
 	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	recv_window := "5000"
-	signature := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	if err != nil {
+		return fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
@@ -495,14 +659,16 @@ func (bp *BybitOrderProcessor) UpdateOrder(ctx context.Context, params UpdateOrd
 		SlTriggerBy: "LastPrice", // Usa sempre LastPrice come default
 	}
 
-	// Converte StopLoss in stringa se specificato
+	// Converte StopLoss e TakeProfit in stringa se specificati, al passo del prezzo (0 li rimuove)
+	var tick float64
+	if params.StopLoss != nil || params.TakeProfit != nil {
+		tick = bp.priceTick(ctx, params.Symbol)
+	}
 	if params.StopLoss != nil {
-		updateReq.StopLoss = strconv.FormatFloat(*params.StopLoss, 'f', 2, 64)
+		updateReq.StopLoss = models.FormatStep(*params.StopLoss, tick)
 	}
-
-	// Converte TakeProfit in stringa se specificato
 	if params.TakeProfit != nil {
-		updateReq.TakeProfit = strconv.FormatFloat(*params.TakeProfit, 'f', 2, 64)
+		updateReq.TakeProfit = models.FormatStep(*params.TakeProfit, tick)
 	}
 
 	// Serializza la richiesta in JSON
@@ -520,8 +686,11 @@ func (bp *BybitOrderProcessor) UpdateOrder(ctx context.Context, params UpdateOrd
 
 	// Aggiungi headers per l'autenticazione
 	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	recv_window := "5000"
-	signature := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
@@ -555,6 +724,7 @@ func (bp *BybitOrderProcessor) UpdateOrder(ctx context.Context, params UpdateOrd
 		UpdatedTime:  time.Unix(updateResp.Time/1000, 0),
 		ErrorCode:    strconv.Itoa(updateResp.RetCode),
 		ErrorMessage: updateResp.RetMsg,
+		RejectReason: bybitRejectReason(updateResp.RetCode, updateResp.RetMsg),
 	}
 
 	// Aggiorna i valori modificati
@@ -604,11 +774,14 @@ func (bp *BybitOrderProcessor) GetOrderStatus(ctx context.Context, symbol, order
 
 	// Aggiungi headers per l'autenticazione
 	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	recv_window := "5000"
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
 
 	// Per richieste GET, il payload per la firma è costituito dai parametri query
 	queryString := params.Encode()
-	signature := bp.generateSignature(timestamp, bp.apiKey, recv_window, queryString)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, queryString)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
 
 	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
 	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
@@ -648,32 +821,9 @@ func (bp *BybitOrderProcessor) GetOrderStatus(ctx context.Context, symbol, order
 	order := statusResp.Result.List[0]
 
 	// Converte la risposta nel formato interno
-	orderResp := &models.OrderResponse{
-		OrderID:      order.OrderID,
-		OrderLinkID:  order.OrderLinkID,
-		Symbol:       order.Symbol,
-		Side:         models.OrderSide(order.Side),
-		OrderType:    models.OrderType(order.OrderType),
-		Status:       models.OrderStatus(order.OrderStatus),
-		ErrorCode:    strconv.Itoa(statusResp.RetCode),
-		ErrorMessage: statusResp.RetMsg,
-	}
-
-	// Converte i valori string in float64
-	if order.Price != "" {
-		orderResp.Price, _ = strconv.ParseFloat(order.Price, 64)
-	}
-	if order.Qty != "" {
-		orderResp.Quantity, _ = strconv.ParseFloat(order.Qty, 64)
-	}
-
-	// Converte i timestamp
-	if createdTimeInt, err := strconv.ParseInt(order.CreatedTime, 10, 64); err == nil {
-		orderResp.CreatedTime = time.Unix(createdTimeInt/1000, 0)
-	}
-	if updatedTimeInt, err := strconv.ParseInt(order.UpdatedTime, 10, 64); err == nil {
-		orderResp.UpdatedTime = time.Unix(updatedTimeInt/1000, 0)
-	}
+	orderResp := order.toOrderResponse()
+	orderResp.ErrorCode = strconv.Itoa(statusResp.RetCode)
+	orderResp.ErrorMessage = statusResp.RetMsg
 
 	return orderResp, nil
 }
@@ -705,11 +855,14 @@ func (bp *BybitOrderProcessor) GetPositions(ctx context.Context, symbol string)
 
 	// Aggiungi headers per l'autenticazione
 	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	recv_window := "5000"
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
 
 	// Per richieste GET, il payload per la firma è costituito dai parametri query
 	queryString := params.Encode()
-	signature := bp.generateSignature(timestamp, bp.apiKey, recv_window, queryString)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, queryString)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
 
 	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
 	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
@@ -743,7 +896,7 @@ func (bp *BybitOrderProcessor) GetPositions(ctx context.Context, symbol string)
 	// Filtra solo le posizioni attive (con size > 0)
 	var activePositions []models.Position
 	for _, position := range positionsResp.Result.List {
-		if position.IsActive() {
+		if position.Parsed.IsActive() {
 			// Aggiungi timestamp di aggiornamento per uso interno
 			position.UpdatedAt = time.Unix(positionsResp.Time/1000, 0)
 			activePositions = append(activePositions, position)
@@ -776,16 +929,13 @@ func isUUIDFormat(id string) bool {
 	return len(id) == 36 && id[8] == '-' && id[13] == '-' && id[18] == '-' && id[23] == '-'
 }
 
-// generateSignature genera la firma HMAC SHA256 richiesta da Bybit
-func (bp *BybitOrderProcessor) generateSignature(timestamp, apiKey, recvWindow, body string) string {
+// generateSignature genera la firma richiesta da Bybit (HMAC SHA256 o RSA SHA256)
+func (bp *BybitOrderProcessor) generateSignature(timestamp, apiKey, recvWindow, body string) (string, error) {
 	// Costruisce il payload per la firma
 	payload := timestamp + apiKey + recvWindow + body
 
-	// Calcola HMAC SHA256
-	h := hmac.New(sha256.New, []byte(bp.apiSecret))
-	h.Write([]byte(payload))
-
-	return hex.EncodeToString(h.Sum(nil))
+	// Calcola la firma con il signer configurato
+	return bp.signer.Sign(payload)
 }
 
 // GetWalletBalance recupera il saldo del wallet per un account specifico
@@ -812,11 +962,14 @@ func (bp *BybitOrderProcessor) GetWalletBalance(ctx context.Context, accountType
 
 	// Aggiungi headers per l'autenticazione
 	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	recv_window := "5000"
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
 
 	// Per richieste GET, il payload per la firma è costituito dai parametri query
 	queryString := params.Encode()
-	signature := bp.generateSignature(timestamp, bp.apiKey, recv_window, queryString)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, queryString)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
 
 	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
 	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
@@ -850,6 +1003,54 @@ func (bp *BybitOrderProcessor) GetWalletBalance(ctx context.Context, accountType
 	return &walletResp, nil
 }
 
+// GetAPIKeyInfo recupera permessi, modalità e scadenza della API key configurata
+func (bp *BybitOrderProcessor) GetAPIKeyInfo(ctx context.Context) (*models.APIKeyInfo, error) {
+	// Crea la richiesta HTTP GET (nessun parametro query)
+	req, err := http.NewRequestWithContext(ctx, "GET", bybitAPIBaseURL+bybitQueryAPIKeyEndpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella creazione della richiesta HTTP: %w", err)
+	}
+
+	// Aggiungi headers per l'autenticazione; senza parametri il payload firmato non ha query string
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, "")
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
+
+	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", recv_window)
+	req.Header.Set("X-BAPI-SIGN", signature)
+
+	// Esegui la richiesta
+	resp, err := bp.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("errore nell'esecuzione della richiesta: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Leggi la risposta
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella lettura della risposta: %w", err)
+	}
+
+	// Decodifica la risposta
+	var keyResp models.APIKeyInfoResponse
+	if err := json.Unmarshal(body, &keyResp); err != nil {
+		return nil, fmt.Errorf("errore nella decodifica della risposta: %w", err)
+	}
+
+	// Verifica che la richiesta sia andata a buon fine
+	if !keyResp.IsSuccess() {
+		return nil, fmt.Errorf("errore API Bybit: %s (codice: %d)", keyResp.RetMsg, keyResp.RetCode)
+	}
+
+	return &keyResp.Result, nil
+}
+
 // GetUSDTBalance recupera il saldo USDT dal wallet (metodo di convenienza)
 func (bp *BybitOrderProcessor) GetUSDTBalance(ctx context.Context) (float64, error) {
 	// Usa accountType "UNIFIED" per ottenere il saldo unificato
@@ -0,0 +1,64 @@
+package orderprocessor
+
+import (
+	"strings"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// bybitRejectCodes associa i retCode di Bybit v5 al motivo del rifiuto
+// Codici come 10001 (parametro non valido) coprono più cause e sono classificati dal messaggio
+var bybitRejectCodes = map[int]models.RejectReason{
+	110004: models.RejectReasonInsufficientBalance, // Wallet balance insufficient
+	110007: models.RejectReasonInsufficientBalance, // Available balance insufficient
+	110012: models.RejectReasonInsufficientBalance, // Insufficient available balance
+	110044: models.RejectReasonInsufficientBalance, // Available margin is insufficient
+	110045: models.RejectReasonInsufficientBalance, // Wallet balance is insufficient
+	170131: models.RejectReasonInsufficientBalance, // Insufficient balance (spot)
+
+	110003: models.RejectReasonPriceOutOfBounds, // Order price exceeds the allowable range
+	110022: models.RejectReasonPriceOutOfBounds, // Quantity or price exceeds the limit
+	30208:  models.RejectReasonPriceOutOfBounds, // Price higher than the maximum buying price
+	30209:  models.RejectReasonPriceOutOfBounds, // Price lower than the minimum selling price
+	170193: models.RejectReasonPriceOutOfBounds, // Buy order price cannot be higher than the limit (spot)
+	170194: models.RejectReasonPriceOutOfBounds, // Sell order price cannot be lower than the limit (spot)
+
+	110094: models.RejectReasonQtyPrecision, // Order does not meet minimum order value
+	170136: models.RejectReasonQtyPrecision, // Order quantity exceeded lower limit (spot)
+	170137: models.RejectReasonQtyPrecision, // Order volume has too many decimals (spot)
+
+	110013: models.RejectReasonRiskLimit, // Cannot set leverage due to risk limit level
+	110066: models.RejectReasonRiskLimit, // Risk limit adjustment not allowed
+	110090: models.RejectReasonRiskLimit, // Position will exceed the max position limit
+}
+
+// bybitRejectKeywords classifica dal messaggio i rifiuti con codici generici, nell'ordine indicato
+var bybitRejectKeywords = []struct {
+	keyword string
+	reason  models.RejectReason
+}{
+	{"insufficient", models.RejectReasonInsufficientBalance},
+	{"not enough", models.RejectReasonInsufficientBalance},
+	{"risk limit", models.RejectReasonRiskLimit},
+	{"price", models.RejectReasonPriceOutOfBounds},
+	{"qty", models.RejectReasonQtyPrecision},
+	{"quantity", models.RejectReasonQtyPrecision},
+	{"decimal", models.RejectReasonQtyPrecision},
+}
+
+// bybitRejectReason ricava il motivo del rifiuto da retCode e retMsg; vuoto se l'ordine è stato accettato
+func bybitRejectReason(retCode int, retMsg string) models.RejectReason {
+	if retCode == 0 {
+		return ""
+	}
+	if reason, ok := bybitRejectCodes[retCode]; ok {
+		return reason
+	}
+	message := strings.ToLower(retMsg)
+	for _, k := range bybitRejectKeywords {
+		if strings.Contains(message, k.keyword) {
+			return k.reason
+		}
+	}
+	return models.RejectReasonOther
+}
@@ -0,0 +1,173 @@
+package orderprocessor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"cross-exchange-arbitrage/models"
+)
+
+const (
+	// Endpoint di creazione dei sub-account (richiede la key del master con permesso di gestione dei sub-account)
+	bybitCreateSubMemberEndpoint = "/v5/user/create-sub-member"
+
+	// Endpoint di creazione delle API key dei sub-account
+	bybitCreateSubAPIEndpoint = "/v5/user/create-sub-api"
+
+	// Endpoint dell'elenco dei sub-account del master
+	bybitQuerySubMembersEndpoint = "/v5/user/query-sub-members"
+
+	// bybitSubMemberTypeNormal è il tipo di sub-account standard, con login e trading
+	bybitSubMemberTypeNormal = 1
+)
+
+// bybitCreateSubMemberRequest è il payload di /v5/user/create-sub-member
+type bybitCreateSubMemberRequest struct {
+	Username   string `json:"username"`
+	MemberType int    `json:"memberType"`
+	Switch     int    `json:"switch"` // 0 quick login disattivato
+	IsUta      bool   `json:"isUta"`
+	Note       string `json:"note,omitempty"`
+}
+
+// bybitSubMember rappresenta un sub-account nelle risposte di Bybit
+type bybitSubMember struct {
+	UID        string `json:"uid"`
+	Username   string `json:"username"`
+	MemberType int    `json:"memberType"`
+	Status     int    `json:"status"`
+	Remark     string `json:"remark"`
+}
+
+// BybitCreateSubMemberResponse rappresenta la risposta di /v5/user/create-sub-member
+type BybitCreateSubMemberResponse struct {
+	RetCode int            `json:"retCode"`
+	RetMsg  string         `json:"retMsg"`
+	Result  bybitSubMember `json:"result"`
+}
+
+// BybitQuerySubMembersResponse rappresenta la risposta di /v5/user/query-sub-members
+type BybitQuerySubMembersResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		SubMembers []bybitSubMember `json:"subMembers"`
+	} `json:"result"`
+}
+
+// bybitCreateSubAPIRequest è il payload di /v5/user/create-sub-api
+type bybitCreateSubAPIRequest struct {
+	SubUID      int64                        `json:"subuid"`
+	Note        string                       `json:"note,omitempty"`
+	ReadOnly    int                          `json:"readOnly"`
+	IPs         string                       `json:"ips,omitempty"`
+	Permissions models.SubAccountPermissions `json:"permissions"`
+}
+
+// BybitCreateSubAPIResponse rappresenta la risposta di /v5/user/create-sub-api
+type BybitCreateSubAPIResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		ID          string                       `json:"id"`
+		Note        string                       `json:"note"`
+		APIKey      string                       `json:"apiKey"`
+		ReadOnly    int                          `json:"readOnly"`
+		Secret      string                       `json:"secret"`
+		Permissions models.SubAccountPermissions `json:"permissions"`
+	} `json:"result"`
+}
+
+// CreateSubAccount implementa SubAccountManager creando un sub-account standard con account unificato
+func (bp *BybitOrderProcessor) CreateSubAccount(ctx context.Context, req models.SubAccountRequest) (*models.SubAccount, error) {
+	payload := bybitCreateSubMemberRequest{
+		Username:   req.Username,
+		MemberType: bybitSubMemberTypeNormal,
+		IsUta:      true,
+		Note:       req.Note,
+	}
+
+	var memberResp BybitCreateSubMemberResponse
+	if err := bp.signedPost(ctx, bybitCreateSubMemberEndpoint, payload, &memberResp); err != nil {
+		return nil, err
+	}
+	if memberResp.RetCode != 0 {
+		return nil, fmt.Errorf("errore API Bybit: %s (codice: %d)", memberResp.RetMsg, memberResp.RetCode)
+	}
+	return bybitSubAccount(memberResp.Result)
+}
+
+// CreateSubAPIKey implementa SubAccountManager creando una API key HMAC per il sub-account
+func (bp *BybitOrderProcessor) CreateSubAPIKey(ctx context.Context, req models.SubAPIKeyRequest) (*models.SubAPIKey, error) {
+	if req.SubUID <= 0 {
+		return nil, fmt.Errorf("UID del sub-account obbligatorio")
+	}
+	if len(req.Permissions) == 0 {
+		return nil, fmt.Errorf("permessi della API key obbligatori")
+	}
+
+	payload := bybitCreateSubAPIRequest{
+		SubUID:      req.SubUID,
+		Note:        req.Note,
+		IPs:         strings.Join(req.IPs, ","),
+		Permissions: req.Permissions,
+	}
+	if req.ReadOnly {
+		payload.ReadOnly = 1
+	}
+
+	var keyResp BybitCreateSubAPIResponse
+	if err := bp.signedPost(ctx, bybitCreateSubAPIEndpoint, payload, &keyResp); err != nil {
+		return nil, err
+	}
+	if keyResp.RetCode != 0 {
+		return nil, fmt.Errorf("errore API Bybit: %s (codice: %d)", keyResp.RetMsg, keyResp.RetCode)
+	}
+
+	return &models.SubAPIKey{
+		ID:          keyResp.Result.ID,
+		APIKey:      keyResp.Result.APIKey,
+		Secret:      keyResp.Result.Secret,
+		ReadOnly:    keyResp.Result.ReadOnly == 1,
+		Permissions: keyResp.Result.Permissions,
+	}, nil
+}
+
+// ListSubAccounts implementa SubAccountManager elencando i sub-account del master
+func (bp *BybitOrderProcessor) ListSubAccounts(ctx context.Context) ([]models.SubAccount, error) {
+	var membersResp BybitQuerySubMembersResponse
+	if err := bp.signedGet(ctx, bybitQuerySubMembersEndpoint, url.Values{}, &membersResp); err != nil {
+		return nil, err
+	}
+	if membersResp.RetCode != 0 {
+		return nil, fmt.Errorf("errore API Bybit: %s (codice: %d)", membersResp.RetMsg, membersResp.RetCode)
+	}
+
+	accounts := make([]models.SubAccount, 0, len(membersResp.Result.SubMembers))
+	for _, member := range membersResp.Result.SubMembers {
+		account, err := bybitSubAccount(member)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *account)
+	}
+	return accounts, nil
+}
+
+// bybitSubAccount converte un sub-account di Bybit, che riporta l'UID come stringa
+func bybitSubAccount(member bybitSubMember) (*models.SubAccount, error) {
+	uid, err := strconv.ParseInt(member.UID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("UID del sub-account non valido: %q", member.UID)
+	}
+	return &models.SubAccount{
+		UID:        uid,
+		Username:   member.Username,
+		MemberType: member.MemberType,
+		Status:     member.Status,
+		Note:       member.Remark,
+	}, nil
+}
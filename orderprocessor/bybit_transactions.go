@@ -0,0 +1,164 @@
+package orderprocessor
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// Endpoint per il transaction log dell'Unified Trading Account
+	bybitTransactionLogEndpoint = "/v5/account/transaction-log"
+
+	// Ampiezza massima dell'intervallo di una richiesta al transaction log
+	bybitTransactionLogWindow = 7 * 24 * time.Hour
+
+	// Numero massimo di movimenti per pagina
+	bybitTransactionLogLimit = 50
+)
+
+// bybitCashFlowTypes associa i tipi del transaction log che muovono fondi da e verso l'account di trading
+// Depositi e prelievi passano dal funding account: per l'Unified Trading Account sono trasferimenti
+var bybitCashFlowTypes = map[string]models.CashFlowType{
+	"TRANSFER_IN":  models.CashFlowDeposit,
+	"TRANSFER_OUT": models.CashFlowWithdrawal,
+}
+
+// BybitTransactionLogResponse rappresenta una pagina del transaction log
+type BybitTransactionLogResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		NextPageCursor string `json:"nextPageCursor"`
+		List           []struct {
+			ID              string `json:"id"`
+			Type            string `json:"type"`
+			Currency        string `json:"currency"`
+			Change          string `json:"change"`
+			TransactionTime string `json:"transactionTime"`
+		} `json:"list"`
+	} `json:"result"`
+}
+
+// GetCashFlows recupera i trasferimenti in entrata e in uscita dall'Unified Trading Account tra since e until
+// Il transaction log accetta intervalli di al massimo 7 giorni: il periodo viene letto a finestre
+func (bp *BybitOrderProcessor) GetCashFlows(ctx context.Context, coin string, since, until time.Time) ([]models.CashFlow, error) {
+	var flows []models.CashFlow
+	for start := since; start.Before(until); start = start.Add(bybitTransactionLogWindow) {
+		end := start.Add(bybitTransactionLogWindow)
+		if end.After(until) {
+			end = until
+		}
+		for logType, flowType := range bybitCashFlowTypes {
+			page, err := bp.getTransactionLog(ctx, coin, logType, flowType, start, end)
+			if err != nil {
+				return nil, err
+			}
+			flows = append(flows, page...)
+		}
+	}
+
+	sort.Slice(flows, func(i, j int) bool {
+		return flows[i].OccurredAt.Before(flows[j].OccurredAt)
+	})
+	return flows, nil
+}
+
+// getTransactionLog legge tutte le pagine di un tipo di movimento in una finestra di al massimo 7 giorni
+func (bp *BybitOrderProcessor) getTransactionLog(ctx context.Context, coin, logType string, flowType models.CashFlowType, start, end time.Time) ([]models.CashFlow, error) {
+	var flows []models.CashFlow
+	cursor := ""
+	for {
+		params := url.Values{}
+		params.Set("accountType", models.AccountTypeUnified)
+		params.Set("currency", coin)
+		params.Set("type", logType)
+		params.Set("startTime", strconv.FormatInt(start.UnixMilli(), 10))
+		params.Set("endTime", strconv.FormatInt(end.UnixMilli(), 10))
+		params.Set("limit", strconv.Itoa(bybitTransactionLogLimit))
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+
+		logResp, err := bp.fetchTransactionLog(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range logResp.Result.List {
+			change, err := strconv.ParseFloat(entry.Change, 64)
+			if err != nil {
+				return nil, fmt.Errorf("importo non valido nel movimento %s: %w", entry.ID, err)
+			}
+			millis, err := strconv.ParseInt(entry.TransactionTime, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("orario non valido nel movimento %s: %w", entry.ID, err)
+			}
+			if change == 0 {
+				continue
+			}
+			flows = append(flows, models.CashFlow{
+				Exchange:      "bybit",
+				TransactionID: entry.ID,
+				Coin:          entry.Currency,
+				Type:          flowType,
+				Amount:        change,
+				OccurredAt:    time.UnixMilli(millis).UTC(),
+			})
+		}
+
+		cursor = logResp.Result.NextPageCursor
+		if cursor == "" || len(logResp.Result.List) == 0 {
+			return flows, nil
+		}
+	}
+}
+
+// fetchTransactionLog esegue una richiesta firmata al transaction log
+func (bp *BybitOrderProcessor) fetchTransactionLog(ctx context.Context, params url.Values) (*BybitTransactionLogResponse, error) {
+	queryString := params.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", bybitAPIBaseURL+bybitTransactionLogEndpoint+"?"+queryString, nil)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella creazione della richiesta HTTP: %w", err)
+	}
+
+	// Per richieste GET, il payload per la firma è costituito dai parametri query
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	recv_window := bybitRecvWindow
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, queryString)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
+
+	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", recv_window)
+	req.Header.Set("X-BAPI-SIGN", signature)
+
+	resp, err := bp.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("errore nell'esecuzione della richiesta: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella lettura della risposta: %w", err)
+	}
+
+	var logResp BybitTransactionLogResponse
+	if err := json.Unmarshal(body, &logResp); err != nil {
+		return nil, fmt.Errorf("errore nella decodifica della risposta: %w", err)
+	}
+	if logResp.RetCode != 0 {
+		return nil, fmt.Errorf("errore API Bybit: %s (codice: %d)", logResp.RetMsg, logResp.RetCode)
+	}
+	return &logResp, nil
+}
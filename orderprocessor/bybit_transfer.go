@@ -0,0 +1,239 @@
+package orderprocessor
+
+import (
+	"bytes"
+	"context"
+	"cross-exchange-arbitrage/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Endpoint per i trasferimenti tra account dello stesso UID
+	bybitInterTransferEndpoint = "/v5/asset/transfer/inter-transfer"
+
+	// Endpoint per i trasferimenti tra UID diversi (master e sub account)
+	bybitUniversalTransferEndpoint = "/v5/asset/transfer/universal-transfer"
+
+	// Endpoint per il saldo trasferibile di una valuta in un account
+	bybitAccountCoinBalanceEndpoint = "/v5/asset/transfer/query-account-coin-balance"
+)
+
+// BybitTransferResponse rappresenta la risposta degli endpoint di trasferimento
+type BybitTransferResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		TransferID string `json:"transferId"`
+		Status     string `json:"status"`
+	} `json:"result"`
+	Time int64 `json:"time"`
+}
+
+// BybitAccountCoinBalanceResponse rappresenta il saldo di una valuta in un account
+type BybitAccountCoinBalanceResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		AccountType string `json:"accountType"`
+		Balance     struct {
+			Coin            string `json:"coin"`
+			WalletBalance   string `json:"walletBalance"`
+			TransferBalance string `json:"transferBalance"`
+		} `json:"balance"`
+	} `json:"result"`
+}
+
+// bybitTransferRequest è il payload comune ai trasferimenti interni e universal
+type bybitTransferRequest struct {
+	TransferID      string `json:"transferId"`
+	Coin            string `json:"coin"`
+	Amount          string `json:"amount"`
+	FromMemberID    int64  `json:"fromMemberId,omitempty"`
+	ToMemberID      int64  `json:"toMemberId,omitempty"`
+	FromAccountType string `json:"fromAccountType"`
+	ToAccountType   string `json:"toAccountType"`
+}
+
+// InternalTransfer trasferisce fondi tra due account dello stesso UID (es. FUND -> UNIFIED)
+// La API key deve avere il permesso Wallet/AccountTransfer
+func (bp *BybitOrderProcessor) InternalTransfer(ctx context.Context, transfer models.TransferRequest) (*models.TransferResult, error) {
+	return bp.transfer(ctx, bybitInterTransferEndpoint, transfer)
+}
+
+// UniversalTransfer trasferisce fondi tra UID diversi (master e sub account)
+// Deve essere eseguito con la API key del master account
+func (bp *BybitOrderProcessor) UniversalTransfer(ctx context.Context, transfer models.TransferRequest) (*models.TransferResult, error) {
+	if transfer.FromMemberID == 0 || transfer.ToMemberID == 0 {
+		return nil, fmt.Errorf("il trasferimento universal richiede gli UID di origine e destinazione")
+	}
+	return bp.transfer(ctx, bybitUniversalTransferEndpoint, transfer)
+}
+
+// transfer invia la richiesta di trasferimento all'endpoint indicato
+func (bp *BybitOrderProcessor) transfer(ctx context.Context, endpoint string, transfer models.TransferRequest) (*models.TransferResult, error) {
+	if transfer.Amount <= 0 {
+		return nil, fmt.Errorf("importo del trasferimento non valido: %f", transfer.Amount)
+	}
+	if transfer.FromAccountType == "" || transfer.ToAccountType == "" {
+		return nil, fmt.Errorf("account di origine e destinazione obbligatori")
+	}
+
+	payload := bybitTransferRequest{
+		TransferID:      uuid.New().String(),
+		Coin:            transfer.Coin,
+		Amount:          strconv.FormatFloat(transfer.Amount, 'f', -1, 64),
+		FromMemberID:    transfer.FromMemberID,
+		ToMemberID:      transfer.ToMemberID,
+		FromAccountType: transfer.FromAccountType,
+		ToAccountType:   transfer.ToAccountType,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella serializzazione del trasferimento: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", bybitAPIBaseURL+endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("errore nella creazione della richiesta HTTP: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	recv_window := bybitRecvWindow
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", recv_window)
+	req.Header.Set("X-BAPI-SIGN", signature)
+
+	resp, err := bp.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("errore nell'esecuzione della richiesta: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella lettura della risposta: %w", err)
+	}
+
+	var transferResp BybitTransferResponse
+	if err := json.Unmarshal(body, &transferResp); err != nil {
+		return nil, fmt.Errorf("errore nella decodifica della risposta: %w", err)
+	}
+	if transferResp.RetCode != 0 {
+		return nil, fmt.Errorf("errore API Bybit: %s (codice: %d)", transferResp.RetMsg, transferResp.RetCode)
+	}
+
+	transferID := transferResp.Result.TransferID
+	if transferID == "" {
+		transferID = payload.TransferID
+	}
+	return &models.TransferResult{
+		TransferID: transferID,
+		Status:     transferResp.Result.Status,
+		Request:    transfer,
+		CreatedAt:  time.Unix(transferResp.Time/1000, 0),
+	}, nil
+}
+
+// GetTransferableBalance recupera l'importo trasferibile di una valuta in un account (es. FUND)
+func (bp *BybitOrderProcessor) GetTransferableBalance(ctx context.Context, accountType, coin string) (float64, error) {
+	params := url.Values{}
+	params.Set("accountType", accountType)
+	params.Set("coin", coin)
+	queryString := params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", bybitAPIBaseURL+bybitAccountCoinBalanceEndpoint+"?"+queryString, nil)
+	if err != nil {
+		return 0, fmt.Errorf("errore nella creazione della richiesta HTTP: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	recv_window := bybitRecvWindow
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, queryString)
+	if err != nil {
+		return 0, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
+
+	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", recv_window)
+	req.Header.Set("X-BAPI-SIGN", signature)
+
+	resp, err := bp.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("errore nell'esecuzione della richiesta: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("errore nella lettura della risposta: %w", err)
+	}
+
+	var balanceResp BybitAccountCoinBalanceResponse
+	if err := json.Unmarshal(body, &balanceResp); err != nil {
+		return 0, fmt.Errorf("errore nella decodifica della risposta: %w", err)
+	}
+	if balanceResp.RetCode != 0 {
+		return 0, fmt.Errorf("errore API Bybit: %s (codice: %d)", balanceResp.RetMsg, balanceResp.RetCode)
+	}
+
+	if balanceResp.Result.Balance.TransferBalance == "" {
+		return 0, nil
+	}
+	balance, err := strconv.ParseFloat(balanceResp.Result.Balance.TransferBalance, 64)
+	if err != nil {
+		return 0, fmt.Errorf("errore nella conversione del saldo trasferibile: %w", err)
+	}
+	return balance, nil
+}
+
+// GetMemberBalance recupera saldo e importo trasferibile di una valuta nell'account di un UID
+// Con la key del master è possibile leggere anche i sub-account indicandone l'UID
+func (bp *BybitOrderProcessor) GetMemberBalance(ctx context.Context, memberID int64, accountType, coin string) (*models.MemberBalance, error) {
+	params := url.Values{}
+	params.Set("memberId", strconv.FormatInt(memberID, 10))
+	params.Set("accountType", accountType)
+	params.Set("coin", coin)
+
+	var balanceResp BybitAccountCoinBalanceResponse
+	if err := bp.signedGet(ctx, bybitAccountCoinBalanceEndpoint, params, &balanceResp); err != nil {
+		return nil, err
+	}
+	if balanceResp.RetCode != 0 {
+		return nil, fmt.Errorf("errore API Bybit: %s (codice: %d)", balanceResp.RetMsg, balanceResp.RetCode)
+	}
+
+	balance := &models.MemberBalance{MemberID: memberID, AccountType: accountType, Coin: coin}
+	// I saldi vuoti indicano un account senza la valuta
+	if value := balanceResp.Result.Balance.WalletBalance; value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("saldo non valido per l'UID %d: %q", memberID, value)
+		}
+		balance.WalletBalance = parsed
+	}
+	if value := balanceResp.Result.Balance.TransferBalance; value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("saldo trasferibile non valido per l'UID %d: %q", memberID, value)
+		}
+		balance.TransferBalance = parsed
+	}
+	return balance, nil
+}
@@ -0,0 +1,87 @@
+package orderprocessor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/cache"
+	"cross-exchange-arbitrage/models"
+)
+
+// PositionReader legge le posizioni aperte di un account
+type PositionReader interface {
+	// GetPositions recupera le posizioni attive per un simbolo (tutte se symbol è vuoto)
+	GetPositions(ctx context.Context, symbol string) ([]models.Position, error)
+}
+
+// CachedAccountReader serve saldi e posizioni dalla cache per ridurre le chiamate duplicate all'exchange
+// da parte di REST API, worker di reportistica e dashboard; con Redis la cache è condivisa tra istanze
+// Non va usato per le decisioni di trading: posizioni e saldi possono essere vecchi fino al TTL
+type CachedAccountReader struct {
+	reader       AccountReader
+	positions    PositionReader // nil se l'account non espone le posizioni
+	store        cache.Store
+	venue        string // Prefisso delle chiavi (es. bybit), per distinguere gli account
+	balanceTTL   time.Duration
+	positionsTTL time.Duration
+}
+
+// NewCachedAccountReader crea un reader che legge da reader e positions solo alla scadenza della cache
+func NewCachedAccountReader(reader AccountReader, positions PositionReader, store cache.Store, venue string, balanceTTL, positionsTTL time.Duration) *CachedAccountReader {
+	return &CachedAccountReader{
+		reader:       reader,
+		positions:    positions,
+		store:        store,
+		venue:        venue,
+		balanceTTL:   balanceTTL,
+		positionsTTL: positionsTTL,
+	}
+}
+
+// GetWalletBalance recupera il saldo del wallet dalla cache o dall'exchange
+func (c *CachedAccountReader) GetWalletBalance(ctx context.Context, accountType, coin string) (*models.WalletBalanceResponse, error) {
+	return cache.GetOrLoad(ctx, c.store, c.key("wallet", accountType, coin), c.balanceTTL, func(ctx context.Context) (*models.WalletBalanceResponse, error) {
+		return c.reader.GetWalletBalance(ctx, accountType, coin)
+	})
+}
+
+// GetUSDTBalance recupera il saldo USDT dalla cache o dall'exchange
+func (c *CachedAccountReader) GetUSDTBalance(ctx context.Context) (float64, error) {
+	return cache.GetOrLoad(ctx, c.store, c.key("balance", "USDT"), c.balanceTTL, c.reader.GetUSDTBalance)
+}
+
+// GetCoinBalance recupera il saldo di una criptovaluta dalla cache o dall'exchange
+func (c *CachedAccountReader) GetCoinBalance(ctx context.Context, coin string) (float64, error) {
+	return cache.GetOrLoad(ctx, c.store, c.key("balance", coin), c.balanceTTL, func(ctx context.Context) (float64, error) {
+		return c.reader.GetCoinBalance(ctx, coin)
+	})
+}
+
+// GetAccountInfo recupera la configurazione dell'account dalla cache o dall'exchange
+func (c *CachedAccountReader) GetAccountInfo(ctx context.Context) (*models.AccountSettings, error) {
+	return cache.GetOrLoad(ctx, c.store, c.key("account"), c.balanceTTL, c.reader.GetAccountInfo)
+}
+
+// GetFeeRate recupera le commissioni di un simbolo dalla cache o dall'exchange
+func (c *CachedAccountReader) GetFeeRate(ctx context.Context, symbol string) (*models.FeeRate, error) {
+	return cache.GetOrLoad(ctx, c.store, c.key("fees", symbol), c.balanceTTL, func(ctx context.Context) (*models.FeeRate, error) {
+		return c.reader.GetFeeRate(ctx, symbol)
+	})
+}
+
+// GetPositions recupera le posizioni aperte dalla cache o dall'exchange
+func (c *CachedAccountReader) GetPositions(ctx context.Context, symbol string) ([]models.Position, error) {
+	if c.positions == nil {
+		return nil, fmt.Errorf("posizioni non disponibili per %s", c.venue)
+	}
+	return cache.GetOrLoad(ctx, c.store, c.key("positions", symbol), c.positionsTTL, func(ctx context.Context) ([]models.Position, error) {
+		return c.positions.GetPositions(ctx, symbol)
+	})
+}
+
+// key compone la chiave di cache dell'account
+func (c *CachedAccountReader) key(parts ...string) string {
+	return "account:" + c.venue + ":" + strings.Join(parts, ":")
+}
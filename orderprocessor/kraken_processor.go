@@ -0,0 +1,634 @@
+package orderprocessor
+
+import (
+	"context"
+	"cross-exchange-arbitrage/kraken"
+	"cross-exchange-arbitrage/models"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Endpoint Kraken Futures (path senza il prefisso /derivatives, come nella firma)
+	krakenSendOrderEndpoint      = "/api/v3/sendorder"
+	krakenCancelOrderEndpoint    = "/api/v3/cancelorder"
+	krakenEditOrderEndpoint      = "/api/v3/editorder"
+	krakenOpenOrdersEndpoint     = "/api/v3/openorders"
+	krakenOrderStatusEndpoint    = "/api/v3/orders/status"
+	krakenOpenPositionsEndpoint  = "/api/v3/openpositions"
+	krakenAccountsEndpoint       = "/api/v3/accounts"
+	krakenFlexAccount            = "flex"
+	krakenTriggerSignalLastPrice = "last"
+)
+
+// Tipi di ordine Kraken Futures
+const (
+	krakenOrderTypeMarket     = "mkt"
+	krakenOrderTypeStop       = "stp"
+	krakenOrderTypeTakeProfit = "take_profit"
+
+	// Nelle liste degli ordini aperti gli stop compaiono come "stop"
+	krakenOpenOrderTypeStop = "stop"
+)
+
+// KrakenOrderProcessor implementa OrderProcessor per Kraken Futures
+// Kraken non ha stop loss e take profit a livello di posizione: vengono piazzati come
+// ordini trigger reduce-only separati, e UpdateOrder li modifica con editorder
+type KrakenOrderProcessor struct {
+	client *kraken.Client
+}
+
+// KrakenOrderEvent rappresenta un evento restituito da sendorder (esecuzione, piazzamento...)
+type KrakenOrderEvent struct {
+	Type         string        `json:"type"` // PLACE, EXECUTION, REJECT...
+	Price        kraken.Number `json:"price"`
+	Amount       kraken.Number `json:"amount"`
+	ExecutionID  string        `json:"executionId"`
+	Reason       string        `json:"reason"`
+	OrderTrigger *struct {
+		UID string `json:"uid"`
+	} `json:"orderTrigger"`
+}
+
+// KrakenSendOrderResponse rappresenta la risposta di sendorder
+type KrakenSendOrderResponse struct {
+	kraken.Response
+	SendStatus struct {
+		OrderID      string             `json:"order_id"`
+		CliOrdID     string             `json:"cliOrdId"`
+		Status       string             `json:"status"` // placed, insufficientAvailableFunds, invalidSize...
+		ReceivedTime time.Time          `json:"receivedTime"`
+		OrderEvents  []KrakenOrderEvent `json:"orderEvents"`
+	} `json:"sendStatus"`
+}
+
+// KrakenCancelOrderResponse rappresenta la risposta di cancelorder
+type KrakenCancelOrderResponse struct {
+	kraken.Response
+	CancelStatus struct {
+		OrderID      string    `json:"order_id"`
+		CliOrdID     string    `json:"cliOrdId"`
+		Status       string    `json:"status"` // cancelled, filled, notFound
+		ReceivedTime time.Time `json:"receivedTime"`
+	} `json:"cancelStatus"`
+}
+
+// KrakenEditOrderResponse rappresenta la risposta di editorder
+type KrakenEditOrderResponse struct {
+	kraken.Response
+	EditStatus struct {
+		OrderID string `json:"orderId"`
+		Status  string `json:"status"` // edited, notFound...
+	} `json:"editStatus"`
+}
+
+// KrakenOpenOrder rappresenta un ordine aperto
+type KrakenOpenOrder struct {
+	OrderID      string        `json:"order_id"`
+	CliOrdID     string        `json:"cliOrdId"`
+	Symbol       string        `json:"symbol"`
+	Side         string        `json:"side"`
+	OrderType    string        `json:"orderType"` // lmt, stop, take_profit
+	LimitPrice   kraken.Number `json:"limitPrice"`
+	StopPrice    kraken.Number `json:"stopPrice"`
+	UnfilledSize kraken.Number `json:"unfilledSize"`
+	ReduceOnly   bool          `json:"reduceOnly"`
+}
+
+// KrakenOpenOrdersResponse rappresenta la risposta di openorders
+type KrakenOpenOrdersResponse struct {
+	kraken.Response
+	OpenOrders []KrakenOpenOrder `json:"openOrders"`
+}
+
+// KrakenOrderStatus rappresenta lo stato di un ordine restituito da orders/status
+type KrakenOrderStatus struct {
+	Order struct {
+		Type                string        `json:"type"` // ORDER o TRIGGER_ORDER
+		OrderID             string        `json:"orderId"`
+		CliOrdID            string        `json:"cliOrdId"`
+		Symbol              string        `json:"symbol"`
+		Side                string        `json:"side"`
+		Quantity            kraken.Number `json:"quantity"`
+		Filled              kraken.Number `json:"filled"`
+		LimitPrice          kraken.Number `json:"limitPrice"`
+		Timestamp           time.Time     `json:"timestamp"`
+		LastUpdateTimestamp time.Time     `json:"lastUpdateTimestamp"`
+		PriceTriggerOptions *struct {
+			TriggerPrice kraken.Number `json:"triggerPrice"`
+		} `json:"priceTriggerOptions"`
+	} `json:"order"`
+	Status       string `json:"status"` // ENTERED_BOOK, FULLY_EXECUTED, REJECTED, CANCELLED, TRIGGER_PLACED, TRIGGER_ACTIVATED
+	UpdateReason string `json:"updateReason"`
+	Error        string `json:"error"`
+}
+
+// KrakenOrderStatusResponse rappresenta la risposta di orders/status
+type KrakenOrderStatusResponse struct {
+	kraken.Response
+	Orders []KrakenOrderStatus `json:"orders"`
+}
+
+// KrakenPosition rappresenta una posizione aperta
+type KrakenPosition struct {
+	Side              string        `json:"side"` // long o short
+	Symbol            string        `json:"symbol"`
+	Price             kraken.Number `json:"price"`
+	FillTime          time.Time     `json:"fillTime"`
+	Size              kraken.Number `json:"size"`
+	UnrealizedFunding kraken.Number `json:"unrealizedFunding"`
+}
+
+// KrakenOpenPositionsResponse rappresenta la risposta di openpositions
+type KrakenOpenPositionsResponse struct {
+	kraken.Response
+	OpenPositions []KrakenPosition `json:"openPositions"`
+}
+
+// KrakenCurrencyBalance rappresenta il saldo di una valuta nell'account flex
+type KrakenCurrencyBalance struct {
+	Quantity   kraken.Number `json:"quantity"`
+	Value      kraken.Number `json:"value"`
+	Collateral kraken.Number `json:"collateral"`
+	Available  kraken.Number `json:"available"`
+}
+
+// KrakenAccount rappresenta un account Kraken Futures (solo i campi dell'account flex)
+type KrakenAccount struct {
+	Type            string                           `json:"type"`
+	Currencies      map[string]KrakenCurrencyBalance `json:"currencies"`
+	BalanceValue    kraken.Number                    `json:"balanceValue"`
+	PortfolioValue  kraken.Number                    `json:"portfolioValue"`
+	MarginEquity    kraken.Number                    `json:"marginEquity"`
+	AvailableMargin kraken.Number                    `json:"availableMargin"`
+}
+
+// KrakenAccountsResponse rappresenta la risposta di accounts
+type KrakenAccountsResponse struct {
+	kraken.Response
+	Accounts map[string]KrakenAccount `json:"accounts"`
+}
+
+// NewKrakenOrderProcessor crea una nuova istanza di KrakenOrderProcessor
+// apiSecret è il secret in base64 fornito da Kraken; demo usa demo-futures.kraken.com
+func NewKrakenOrderProcessor(apiKey, apiSecret string, demo bool) *KrakenOrderProcessor {
+	return &KrakenOrderProcessor{
+		client: kraken.NewClient(apiKey, apiSecret, demo),
+	}
+}
+
+// PlaceLongOrder apre una posizione long a mercato e piazza stop loss e take profit reduce-only
+func (kp *KrakenOrderProcessor) PlaceLongOrder(ctx context.Context, symbol string, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
+	return kp.placeOrder(ctx, symbol, models.OrderSideBuy, price, quantity, stopLoss, takeProfit)
+}
+
+// PlaceShortOrder apre una posizione short a mercato e piazza stop loss e take profit reduce-only
+func (kp *KrakenOrderProcessor) PlaceShortOrder(ctx context.Context, symbol string, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
+	return kp.placeOrder(ctx, symbol, models.OrderSideSell, price, quantity, stopLoss, takeProfit)
+}
+
+// placeOrder invia l'ordine di ingresso e, se accettato, gli ordini di protezione
+func (kp *KrakenOrderProcessor) placeOrder(ctx context.Context, symbol string, side models.OrderSide, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
+	prefix := "long"
+	if side == models.OrderSideSell {
+		prefix = "short"
+	}
+	cliOrdID := orderLinkIDFromContext(ctx, GenerateOrderLinkID(prefix))
+
+	params := url.Values{}
+	params.Set("orderType", krakenOrderTypeMarket)
+	params.Set("symbol", kraken.Symbol(symbol))
+	params.Set("side", krakenSide(side))
+	params.Set("size", formatKrakenFloat(quantity))
+	params.Set("cliOrdId", cliOrdID)
+
+	var sendResp KrakenSendOrderResponse
+	if err := kp.client.PrivatePost(ctx, krakenSendOrderEndpoint, params, &sendResp); err != nil {
+		return nil, fmt.Errorf("errore nell'invio dell'ordine: %w", err)
+	}
+
+	orderResp := &models.OrderResponse{
+		OrderID:     sendResp.SendStatus.OrderID,
+		OrderLinkID: cliOrdID,
+		Symbol:      symbol,
+		Side:        side,
+		OrderType:   models.OrderTypeMarket,
+		Price:       price,
+		Quantity:    quantity,
+		StopLoss:    stopLoss,
+		TakeProfit:  takeProfit,
+		CreatedTime: sendResp.SendStatus.ReceivedTime,
+		UpdatedTime: sendResp.SendStatus.ReceivedTime,
+	}
+
+	// Kraken risponde con result=success anche per ordini rifiutati: l'esito è in sendStatus.status
+	if sendResp.SendStatus.Status != "placed" {
+		orderResp.Status = models.OrderStatusRejected
+		orderResp.ErrorCode = sendResp.SendStatus.Status
+		orderResp.ErrorMessage = fmt.Sprintf("ordine rifiutato da Kraken: %s", sendResp.SendStatus.Status)
+		return orderResp, nil
+	}
+
+	filled, avgPrice := summarizeExecutions(sendResp.SendStatus.OrderEvents)
+	orderResp.AveragePrice = avgPrice
+	switch {
+	case filled >= quantity && filled > 0:
+		orderResp.Status = models.OrderStatusFilled
+	case filled > 0:
+		orderResp.Status = models.OrderStatusPartiallyFilled
+	default:
+		orderResp.Status = models.OrderStatusNew
+	}
+
+	// Stop loss e take profit chiudono la posizione: lato opposto e reduce-only
+	closeSide := models.OrderSideSell
+	if side == models.OrderSideSell {
+		closeSide = models.OrderSideBuy
+	}
+
+	var protectionErrors []string
+	if stopLoss > 0 {
+		if _, err := kp.placeTriggerOrder(ctx, symbol, krakenOrderTypeStop, closeSide, quantity, stopLoss); err != nil {
+			protectionErrors = append(protectionErrors, fmt.Sprintf("stop loss: %v", err))
+		}
+	}
+	if takeProfit > 0 {
+		if _, err := kp.placeTriggerOrder(ctx, symbol, krakenOrderTypeTakeProfit, closeSide, quantity, takeProfit); err != nil {
+			protectionErrors = append(protectionErrors, fmt.Sprintf("take profit: %v", err))
+		}
+	}
+	if len(protectionErrors) > 0 {
+		// La posizione è aperta: non si restituisce errore ma si segnala la protezione mancante
+		orderResp.ErrorMessage = "ordini di protezione non piazzati: " + strings.Join(protectionErrors, "; ")
+		log.Printf("ATTENZIONE: %s (ordine %s)", orderResp.ErrorMessage, orderResp.OrderID)
+	}
+
+	return orderResp, nil
+}
+
+// placeTriggerOrder piazza un ordine trigger reduce-only (stop o take profit) e ne restituisce l'ID
+func (kp *KrakenOrderProcessor) placeTriggerOrder(ctx context.Context, symbol, orderType string, side models.OrderSide, quantity, triggerPrice float64) (string, error) {
+	params := url.Values{}
+	params.Set("orderType", orderType)
+	params.Set("symbol", kraken.Symbol(symbol))
+	params.Set("side", krakenSide(side))
+	params.Set("size", formatKrakenFloat(quantity))
+	params.Set("stopPrice", formatKrakenFloat(triggerPrice))
+	params.Set("triggerSignal", krakenTriggerSignalLastPrice)
+	params.Set("reduceOnly", "true")
+
+	var sendResp KrakenSendOrderResponse
+	if err := kp.client.PrivatePost(ctx, krakenSendOrderEndpoint, params, &sendResp); err != nil {
+		return "", err
+	}
+	if sendResp.SendStatus.Status != "placed" {
+		return "", fmt.Errorf("ordine rifiutato da Kraken: %s", sendResp.SendStatus.Status)
+	}
+	return sendResp.SendStatus.OrderID, nil
+}
+
+// DeleteOrder cancella un ordine usando l'order_id Kraken (UUID) o il cliOrdId
+func (kp *KrakenOrderProcessor) DeleteOrder(ctx context.Context, symbol, orderID string) (*models.OrderResponse, error) {
+	params := url.Values{}
+	if _, err := uuid.Parse(orderID); err == nil {
+		params.Set("order_id", orderID)
+	} else {
+		params.Set("cliOrdId", orderID)
+	}
+
+	var cancelResp KrakenCancelOrderResponse
+	if err := kp.client.PrivatePost(ctx, krakenCancelOrderEndpoint, params, &cancelResp); err != nil {
+		return nil, fmt.Errorf("errore nella cancellazione dell'ordine: %w", err)
+	}
+
+	orderResp := &models.OrderResponse{
+		OrderID:      cancelResp.CancelStatus.OrderID,
+		OrderLinkID:  cancelResp.CancelStatus.CliOrdID,
+		Symbol:       symbol,
+		CreatedTime:  cancelResp.CancelStatus.ReceivedTime,
+		UpdatedTime:  cancelResp.CancelStatus.ReceivedTime,
+		ErrorCode:    cancelResp.CancelStatus.Status,
+		ErrorMessage: cancelResp.CancelStatus.Status,
+	}
+	if orderResp.OrderID == "" {
+		orderResp.OrderID = orderID
+	}
+
+	if cancelResp.CancelStatus.Status == "cancelled" {
+		orderResp.Status = models.OrderStatusCancelled
+	} else {
+		orderResp.Status = models.OrderStatusRejected
+	}
+
+	return orderResp, nil
+}
+
+// UpdateOrder aggiorna stop loss e/o take profit della posizione sul simbolo indicato
+// Gli ordini trigger esistenti vengono modificati; se mancano vengono creati sulla size della posizione
+func (kp *KrakenOrderProcessor) UpdateOrder(ctx context.Context, params UpdateOrderParams) (*models.OrderResponse, error) {
+	if params.StopLoss == nil && params.TakeProfit == nil {
+		return nil, fmt.Errorf("almeno uno tra StopLoss e TakeProfit deve essere specificato")
+	}
+
+	positions, err := kp.GetPositions(ctx, params.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if len(positions) == 0 {
+		return nil, fmt.Errorf("nessuna posizione aperta per %s", params.Symbol)
+	}
+	position := positions[0]
+
+	var openResp KrakenOpenOrdersResponse
+	if err := kp.client.PrivateGet(ctx, krakenOpenOrdersEndpoint, nil, &openResp); err != nil {
+		return nil, fmt.Errorf("errore nel recupero degli ordini aperti: %w", err)
+	}
+
+	closeSide := models.OrderSideSell
+	if position.IsShort() {
+		closeSide = models.OrderSideBuy
+	}
+
+	orderResp := &models.OrderResponse{
+		Symbol:      params.Symbol,
+		Side:        models.OrderSide(position.Side),
+		Status:      models.OrderStatusNew,
+		UpdatedTime: time.Now(),
+	}
+
+	if params.StopLoss != nil {
+		if err := kp.upsertTriggerOrder(ctx, openResp.OpenOrders, params.Symbol, krakenOrderTypeStop, closeSide, position.GetSizeFloat(), *params.StopLoss); err != nil {
+			return nil, fmt.Errorf("errore nell'aggiornamento dello stop loss: %w", err)
+		}
+		orderResp.StopLoss = *params.StopLoss
+	}
+	if params.TakeProfit != nil {
+		if err := kp.upsertTriggerOrder(ctx, openResp.OpenOrders, params.Symbol, krakenOrderTypeTakeProfit, closeSide, position.GetSizeFloat(), *params.TakeProfit); err != nil {
+			return nil, fmt.Errorf("errore nell'aggiornamento del take profit: %w", err)
+		}
+		orderResp.TakeProfit = *params.TakeProfit
+	}
+
+	return orderResp, nil
+}
+
+// upsertTriggerOrder modifica il prezzo di trigger dell'ordine reduce-only esistente o ne crea uno nuovo
+func (kp *KrakenOrderProcessor) upsertTriggerOrder(ctx context.Context, openOrders []KrakenOpenOrder, symbol, orderType string, side models.OrderSide, size, triggerPrice float64) error {
+	listedType := orderType
+	if orderType == krakenOrderTypeStop {
+		listedType = krakenOpenOrderTypeStop
+	}
+
+	for _, order := range openOrders {
+		if !order.ReduceOnly || order.OrderType != listedType || !kraken.SameSymbol(order.Symbol, symbol) {
+			continue
+		}
+
+		params := url.Values{}
+		params.Set("orderId", order.OrderID)
+		params.Set("stopPrice", formatKrakenFloat(triggerPrice))
+
+		var editResp KrakenEditOrderResponse
+		if err := kp.client.PrivatePost(ctx, krakenEditOrderEndpoint, params, &editResp); err != nil {
+			return err
+		}
+		if editResp.EditStatus.Status != "edited" {
+			return fmt.Errorf("modifica rifiutata da Kraken: %s", editResp.EditStatus.Status)
+		}
+		return nil
+	}
+
+	_, err := kp.placeTriggerOrder(ctx, symbol, orderType, side, size, triggerPrice)
+	return err
+}
+
+// GetOrderStatus recupera lo stato di un ordine tramite order_id Kraken (UUID) o cliOrdId
+func (kp *KrakenOrderProcessor) GetOrderStatus(ctx context.Context, symbol, orderID string) (*models.OrderResponse, error) {
+	params := url.Values{}
+	if _, err := uuid.Parse(orderID); err == nil {
+		params.Set("orderIds", orderID)
+	} else {
+		params.Set("cliOrdIds", orderID)
+	}
+
+	var statusResp KrakenOrderStatusResponse
+	if err := kp.client.PrivatePost(ctx, krakenOrderStatusEndpoint, params, &statusResp); err != nil {
+		return nil, fmt.Errorf("errore nel recupero dello stato dell'ordine: %w", err)
+	}
+	if len(statusResp.Orders) == 0 {
+		return nil, fmt.Errorf("ordine non trovato: %s", orderID)
+	}
+
+	status := statusResp.Orders[0]
+	order := status.Order
+
+	orderResp := &models.OrderResponse{
+		OrderID:      order.OrderID,
+		OrderLinkID:  order.CliOrdID,
+		Symbol:       symbol,
+		Side:         orderSideFromKraken(order.Side),
+		OrderType:    models.OrderTypeLimit,
+		Price:        order.LimitPrice.Float64(),
+		Quantity:     order.Quantity.Float64(),
+		Status:       mapKrakenOrderStatus(status.Status, order.Filled.Float64()),
+		CreatedTime:  order.Timestamp,
+		UpdatedTime:  order.LastUpdateTimestamp,
+		ErrorCode:    status.Error,
+		ErrorMessage: status.UpdateReason,
+	}
+	if order.Type == "TRIGGER_ORDER" {
+		orderResp.OrderType = models.OrderTypeStop
+		if order.PriceTriggerOptions != nil {
+			orderResp.TriggerPrice = order.PriceTriggerOptions.TriggerPrice.Float64()
+		}
+	}
+
+	return orderResp, nil
+}
+
+// mapKrakenOrderStatus converte lo stato Kraken nello stato interno
+func mapKrakenOrderStatus(status string, filled float64) models.OrderStatus {
+	switch status {
+	case "ENTERED_BOOK":
+		if filled > 0 {
+			return models.OrderStatusPartiallyFilled
+		}
+		return models.OrderStatusNew
+	case "FULLY_EXECUTED":
+		return models.OrderStatusFilled
+	case "CANCELLED":
+		if filled > 0 {
+			return models.OrderStatusPartiallyFilledCanceled
+		}
+		return models.OrderStatusCancelled
+	case "REJECTED":
+		return models.OrderStatusRejected
+	case "TRIGGER_PLACED":
+		return models.OrderStatusUntriggered
+	case "TRIGGER_ACTIVATED":
+		return models.OrderStatusTriggered
+	default:
+		return models.OrderStatus(status)
+	}
+}
+
+// GetPositions recupera le posizioni aperte, filtrate per simbolo se indicato
+func (kp *KrakenOrderProcessor) GetPositions(ctx context.Context, symbol string) ([]models.Position, error) {
+	var posResp KrakenOpenPositionsResponse
+	if err := kp.client.PrivateGet(ctx, krakenOpenPositionsEndpoint, nil, &posResp); err != nil {
+		return nil, fmt.Errorf("errore nel recupero delle posizioni: %w", err)
+	}
+
+	positions := make([]models.Position, 0, len(posResp.OpenPositions))
+	for _, p := range posResp.OpenPositions {
+		if symbol != "" && !kraken.SameSymbol(p.Symbol, symbol) {
+			continue
+		}
+
+		side := models.PositionSideBuy
+		if p.Side == "short" {
+			side = models.PositionSideSell
+		}
+
+		position := models.Position{
+			Symbol:         p.Symbol,
+			Side:           side,
+			Size:           p.Size.String(),
+			EntryPrice:     p.Price.String(),
+			PositionStatus: models.PositionStatusNormal,
+			UpdatedTime:    strconv.FormatInt(p.FillTime.UnixMilli(), 10),
+		}
+		position.Parse()
+		positions = append(positions, position)
+	}
+
+	return positions, nil
+}
+
+// GetWalletBalance recupera i saldi dell'account Kraken nel formato del wallet Bybit
+// accountType "UNIFIED" (o vuoto) corrisponde all'account multi-collateral "flex"
+func (kp *KrakenOrderProcessor) GetWalletBalance(ctx context.Context, accountType, coin string) (*models.WalletBalanceResponse, error) {
+	var accountsResp KrakenAccountsResponse
+	if err := kp.client.PrivateGet(ctx, krakenAccountsEndpoint, nil, &accountsResp); err != nil {
+		return nil, fmt.Errorf("errore nel recupero del saldo: %w", err)
+	}
+
+	name := accountType
+	if name == "" || strings.EqualFold(name, "UNIFIED") {
+		name = krakenFlexAccount
+	}
+	account, ok := accountsResp.Accounts[name]
+	if !ok {
+		return nil, fmt.Errorf("account Kraken %q non trovato", name)
+	}
+
+	now := time.Now()
+	info := models.AccountInfo{
+		AccountType:           name,
+		TotalEquity:           account.PortfolioValue.String(),
+		TotalWalletBalance:    account.BalanceValue.String(),
+		TotalMarginBalance:    account.MarginEquity.String(),
+		TotalAvailableBalance: account.AvailableMargin.String(),
+		UpdatedAt:             now,
+	}
+	for currency, balance := range account.Currencies {
+		currency = strings.ToUpper(currency)
+		if coin != "" && !strings.EqualFold(currency, coin) {
+			continue
+		}
+		info.Coins = append(info.Coins, models.WalletBalance{
+			Coin:                currency,
+			Equity:              balance.Quantity.String(),
+			WalletBalance:       balance.Quantity.String(),
+			AvailableToWithdraw: balance.Available.String(),
+			UpdatedAt:           now,
+		})
+	}
+
+	walletResp := &models.WalletBalanceResponse{RetMsg: "OK", Time: now.UnixMilli()}
+	walletResp.Result.List = []models.AccountInfo{info}
+	return walletResp, nil
+}
+
+// GetUSDTBalance recupera il saldo USDT dell'account flex
+func (kp *KrakenOrderProcessor) GetUSDTBalance(ctx context.Context) (float64, error) {
+	return kp.GetCoinBalance(ctx, "USDT")
+}
+
+// GetCoinBalance recupera il saldo di una valuta dell'account flex
+func (kp *KrakenOrderProcessor) GetCoinBalance(ctx context.Context, coin string) (float64, error) {
+	walletResp, err := kp.GetWalletBalance(ctx, krakenFlexAccount, coin)
+	if err != nil {
+		return 0, fmt.Errorf("errore nel recupero saldo %s: %w", coin, err)
+	}
+
+	coinBalance, found := walletResp.GetCoinBalance(coin)
+	if !found {
+		return 0, fmt.Errorf("saldo %s non trovato", coin)
+	}
+
+	balance, err := coinBalance.GetEquityFloat()
+	if err != nil {
+		return 0, fmt.Errorf("errore nella conversione del saldo %s: %w", coin, err)
+	}
+	return balance, nil
+}
+
+// GetAccountInfo restituisce la configurazione dell'account flex, multi-collateral con margine incrociato
+func (kp *KrakenOrderProcessor) GetAccountInfo(ctx context.Context) (*models.AccountSettings, error) {
+	return &models.AccountSettings{
+		Exchange:   "kraken",
+		Unified:    true,
+		MarginMode: models.MarginModeRegular,
+		FetchedAt:  time.Now(),
+	}, nil
+}
+
+// GetFeeRate non è supportato: Kraken Futures applica le commissioni per scaglioni di volume,
+// senza un endpoint che restituisca quelle effettive di un simbolo
+func (kp *KrakenOrderProcessor) GetFeeRate(ctx context.Context, symbol string) (*models.FeeRate, error) {
+	return nil, fmt.Errorf("lettura delle commissioni non supportata su Kraken")
+}
+
+// summarizeExecutions restituisce quantità eseguita e prezzo medio dagli eventi di sendorder
+func summarizeExecutions(events []KrakenOrderEvent) (float64, float64) {
+	var filled, notional float64
+	for _, event := range events {
+		if event.Type != "EXECUTION" {
+			continue
+		}
+		filled += event.Amount.Float64()
+		notional += event.Amount.Float64() * event.Price.Float64()
+	}
+	if filled == 0 {
+		return 0, 0
+	}
+	return filled, notional / filled
+}
+
+// krakenSide converte il lato dell'ordine nel formato Kraken (buy/sell)
+func krakenSide(side models.OrderSide) string {
+	if side == models.OrderSideSell {
+		return "sell"
+	}
+	return "buy"
+}
+
+// orderSideFromKraken converte il lato Kraken nel formato interno
+func orderSideFromKraken(side string) models.OrderSide {
+	if side == "sell" {
+		return models.OrderSideSell
+	}
+	return models.OrderSideBuy
+}
+
+// formatKrakenFloat formatta un valore numerico senza notazione esponenziale né zeri superflui
+func formatKrakenFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,153 @@
+package orderprocessor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// ErrObserverMode indica che l'ordine non è stato inviato perché il bot è in modalità observer
+var ErrObserverMode = errors.New("modalità observer: ordine non inviato")
+
+// ObserverOrderProcessor sostituisce un OrderProcessor in modalità observer: le letture (saldi, posizioni,
+// stato degli ordini) arrivano all'exchange, mentre ogni ordine, cancellazione o modifica viene solo registrato
+// nel log e restituisce ErrObserverMode. Le strategie girano sui dati reali senza mai operare sull'account
+type ObserverOrderProcessor struct {
+	venue  string
+	reader OrderProcessor // Processor usato per le letture; nil senza credenziali
+}
+
+// NewObserverOrderProcessor crea un processor che non invia ordini a venue; reader esegue le letture e può essere
+// costruito con una key di sola lettura
+func NewObserverOrderProcessor(venue string, reader OrderProcessor) *ObserverOrderProcessor {
+	return &ObserverOrderProcessor{venue: venue, reader: reader}
+}
+
+// PlaceLongOrder implementa OrderProcessor registrando l'ordine senza inviarlo
+func (op *ObserverOrderProcessor) PlaceLongOrder(ctx context.Context, symbol string, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
+	return nil, op.skip("ordine LONG %s: prezzo %.6f, quantità %g, SL %.6f, TP %.6f, orderLinkId %s",
+		symbol, price, quantity, stopLoss, takeProfit, orderLinkIDFromContext(ctx, "-"))
+}
+
+// PlaceShortOrder implementa OrderProcessor registrando l'ordine senza inviarlo
+func (op *ObserverOrderProcessor) PlaceShortOrder(ctx context.Context, symbol string, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
+	return nil, op.skip("ordine SHORT %s: prezzo %.6f, quantità %g, SL %.6f, TP %.6f, orderLinkId %s",
+		symbol, price, quantity, stopLoss, takeProfit, orderLinkIDFromContext(ctx, "-"))
+}
+
+// PlaceMarketOrder implementa MarketOrderProcessor registrando l'ordine senza inviarlo
+func (op *ObserverOrderProcessor) PlaceMarketOrder(ctx context.Context, symbol string, side models.OrderSide, quantity float64, reduceOnly bool) (*models.OrderResponse, error) {
+	return nil, op.skip("ordine a mercato %s %s: quantità %g, reduceOnly %t", side, symbol, quantity, reduceOnly)
+}
+
+// PlaceLimitOrder implementa LimitOrderProcessor registrando l'ordine senza inviarlo
+func (op *ObserverOrderProcessor) PlaceLimitOrder(ctx context.Context, symbol string, side models.OrderSide, price, quantity, stopLoss, takeProfit float64, postOnly bool) (*models.OrderResponse, error) {
+	return nil, op.skip("ordine limite %s %s: prezzo %.6f, quantità %g, SL %.6f, TP %.6f, postOnly %t, orderLinkId %s",
+		side, symbol, price, quantity, stopLoss, takeProfit, postOnly, orderLinkIDFromContext(ctx, "-"))
+}
+
+// PlaceSpotMarketOrder implementa SpotOrderProcessor registrando l'ordine senza inviarlo
+func (op *ObserverOrderProcessor) PlaceSpotMarketOrder(ctx context.Context, symbol string, side models.OrderSide, quantity float64) (*models.OrderResponse, error) {
+	return nil, op.skip("ordine spot a mercato %s %s: quantità %g", side, symbol, quantity)
+}
+
+// DeleteOrder implementa OrderProcessor registrando la cancellazione senza inviarla
+func (op *ObserverOrderProcessor) DeleteOrder(ctx context.Context, symbol, orderID string) (*models.OrderResponse, error) {
+	return nil, op.skip("cancellazione ordine %s %s", symbol, orderID)
+}
+
+// AmendOrderPrice implementa OrderAmender registrando la modifica senza inviarla
+func (op *ObserverOrderProcessor) AmendOrderPrice(ctx context.Context, symbol, orderID string, price float64) error {
+	return op.skip("modifica ordine %s %s: prezzo %.6f", symbol, orderID, price)
+}
+
+// UpdateOrder implementa OrderProcessor registrando la modifica senza inviarla
+func (op *ObserverOrderProcessor) UpdateOrder(ctx context.Context, params UpdateOrderParams) (*models.OrderResponse, error) {
+	stopLoss, takeProfit := "-", "-"
+	if params.StopLoss != nil {
+		stopLoss = fmt.Sprintf("%.6f", *params.StopLoss)
+	}
+	if params.TakeProfit != nil {
+		takeProfit = fmt.Sprintf("%.6f", *params.TakeProfit)
+	}
+	return nil, op.skip("modifica posizione %s: SL %s, TP %s", params.Symbol, stopLoss, takeProfit)
+}
+
+// GetOrderStatus implementa OrderProcessor leggendo lo stato dall'exchange
+func (op *ObserverOrderProcessor) GetOrderStatus(ctx context.Context, symbol, orderID string) (*models.OrderResponse, error) {
+	if op.reader == nil {
+		return nil, op.noReader()
+	}
+	return op.reader.GetOrderStatus(ctx, symbol, orderID)
+}
+
+// GetOpenOrders implementa OpenOrderReader leggendo gli ordini aperti dall'exchange, se il processor lo supporta
+func (op *ObserverOrderProcessor) GetOpenOrders(ctx context.Context, symbol string) ([]models.OrderResponse, error) {
+	reader, ok := op.reader.(OpenOrderReader)
+	if !ok {
+		return nil, fmt.Errorf("lettura degli ordini aperti non supportata su %s", op.venue)
+	}
+	return reader.GetOpenOrders(ctx, symbol)
+}
+
+// GetPositions implementa OrderProcessor leggendo le posizioni dall'exchange
+func (op *ObserverOrderProcessor) GetPositions(ctx context.Context, symbol string) ([]models.Position, error) {
+	if op.reader == nil {
+		return nil, op.noReader()
+	}
+	return op.reader.GetPositions(ctx, symbol)
+}
+
+// GetWalletBalance implementa AccountReader leggendo il saldo dall'exchange
+func (op *ObserverOrderProcessor) GetWalletBalance(ctx context.Context, accountType, coin string) (*models.WalletBalanceResponse, error) {
+	if op.reader == nil {
+		return nil, op.noReader()
+	}
+	return op.reader.GetWalletBalance(ctx, accountType, coin)
+}
+
+// GetUSDTBalance implementa AccountReader leggendo il saldo dall'exchange
+func (op *ObserverOrderProcessor) GetUSDTBalance(ctx context.Context) (float64, error) {
+	if op.reader == nil {
+		return 0, op.noReader()
+	}
+	return op.reader.GetUSDTBalance(ctx)
+}
+
+// GetCoinBalance implementa AccountReader leggendo il saldo dall'exchange
+func (op *ObserverOrderProcessor) GetCoinBalance(ctx context.Context, coin string) (float64, error) {
+	if op.reader == nil {
+		return 0, op.noReader()
+	}
+	return op.reader.GetCoinBalance(ctx, coin)
+}
+
+// GetAccountInfo implementa AccountReader leggendo la configurazione dell'account dall'exchange
+func (op *ObserverOrderProcessor) GetAccountInfo(ctx context.Context) (*models.AccountSettings, error) {
+	if op.reader == nil {
+		return nil, op.noReader()
+	}
+	return op.reader.GetAccountInfo(ctx)
+}
+
+// GetFeeRate implementa AccountReader leggendo le commissioni dall'exchange
+func (op *ObserverOrderProcessor) GetFeeRate(ctx context.Context, symbol string) (*models.FeeRate, error) {
+	if op.reader == nil {
+		return nil, op.noReader()
+	}
+	return op.reader.GetFeeRate(ctx, symbol)
+}
+
+// skip registra l'operazione non inviata e restituisce ErrObserverMode
+func (op *ObserverOrderProcessor) skip(format string, args ...any) error {
+	log.Printf("👁️  [observer %s] non inviato: %s", op.venue, fmt.Sprintf(format, args...))
+	return ErrObserverMode
+}
+
+// noReader restituisce l'errore delle letture senza credenziali
+func (op *ObserverOrderProcessor) noReader() error {
+	return fmt.Errorf("modalità observer su %s senza credenziali: letture dell'account non disponibili", op.venue)
+}
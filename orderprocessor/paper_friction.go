@@ -0,0 +1,74 @@
+package orderprocessor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/simrand"
+)
+
+// Distribuzioni della latenza simulata dal paper trading
+const (
+	PaperLatencyFixed   = "fixed"   // Sempre la latenza media
+	PaperLatencyUniform = "uniform" // Uniforme tra media - jitter e media + jitter
+	PaperLatencyNormal  = "normal"  // Normale con deviazione standard pari al jitter, troncata a zero
+)
+
+// PaperLatency descrive il ritardo simulato tra l'invio di un ordine e il suo arrivo sull'exchange
+type PaperLatency struct {
+	Distribution string        // fixed, uniform o normal
+	Mean         time.Duration // Latenza media (0 = nessuna latenza)
+	Jitter       time.Duration // Ampiezza della variazione attorno alla media
+}
+
+// Validate verifica distribuzione e durate della latenza
+func (l PaperLatency) Validate() error {
+	switch l.Distribution {
+	case "", PaperLatencyFixed, PaperLatencyUniform, PaperLatencyNormal:
+	default:
+		return fmt.Errorf("distribuzione della latenza %q non supportata (fixed, uniform o normal)", l.Distribution)
+	}
+	if l.Mean < 0 || l.Jitter < 0 {
+		return fmt.Errorf("latenza e jitter non possono essere negativi")
+	}
+	return nil
+}
+
+// Sample estrae una latenza dalla distribuzione usando src (nil = generatore globale)
+func (l PaperLatency) Sample(src *simrand.Source) time.Duration {
+	latency := l.Mean
+	switch l.Distribution {
+	case PaperLatencyUniform:
+		latency += time.Duration((src.Float64()*2 - 1) * float64(l.Jitter))
+	case PaperLatencyNormal:
+		latency += time.Duration(src.NormFloat64() * float64(l.Jitter))
+	}
+	return max(latency, 0)
+}
+
+// wait attende la latenza come farebbe l'ordine in viaggio verso l'exchange
+func (l PaperLatency) wait(ctx context.Context, latency time.Duration) error {
+	if latency <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(latency):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// slipPrice peggiora il prezzo di esecuzione di slippageBps punti base nella direzione dell'ordine:
+// gli acquisti pagano di più, le vendite incassano di meno
+func slipPrice(side models.OrderSide, price, slippageBps float64) float64 {
+	if slippageBps <= 0 {
+		return price
+	}
+	if side == models.OrderSideSell {
+		return price * (1 - slippageBps/10000)
+	}
+	return price * (1 + slippageBps/10000)
+}
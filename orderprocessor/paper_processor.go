@@ -0,0 +1,576 @@
+package orderprocessor
+
+import (
+	"context"
+	"cross-exchange-arbitrage/backtest"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/simrand"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Candele da 1 minuto richieste ad ogni avanzamento della simulazione
+	paperCandleLookback = 60
+	// Account e valuta simulati dal paper trading
+	paperAccountType = "UNIFIED"
+	paperCoin        = "USDT"
+)
+
+// PaperMarketData fornisce le candele su cui simulare le esecuzioni (soddisfatta da exchange.Exchange)
+type PaperMarketData interface {
+	FetchLastCandles(ctx context.Context, symbol string, market models.Market, timeframe models.Timeframe, limit int) (*models.CandleResponse, error)
+}
+
+// PaperConfig contiene i parametri della simulazione
+type PaperConfig struct {
+	InitialBalance float64                 // Saldo USDT iniziale
+	Liquidity      backtest.LiquidityModel // Limite di esecuzione per candela
+	FillPriority   backtest.FillPriority   // Priorità tra SL e TP nella stessa candela
+	Latency        PaperLatency            // Ritardo simulato prima che l'ordine arrivi all'exchange
+	SlippageBps    float64                 // Peggioramento del prezzo di ogni esecuzione in punti base
+	Rand           *simrand.Source         // Sorgente delle estrazioni casuali (nil = non riproducibile)
+
+	// Interval è la durata delle candele simulate (0 = 1 minuto); un backtest che chiama ProcessCandle
+	// su candele più ampie la indica insieme a LowerTimeframe, richiesto dalla priorità lower_timeframe
+	Interval       time.Duration
+	LowerTimeframe backtest.LowerTimeframeLoader
+}
+
+// PaperOrderProcessor implementa OrderProcessor simulando le esecuzioni sulle candele da 1 minuto
+// Gli ordini vengono eseguiti candela dopo candela entro il limite di liquidità, attraversando
+// lo stato PartiallyFilled come sul mercato reale; le posizioni escono su stop loss e take profit
+type PaperOrderProcessor struct {
+	mu        sync.Mutex
+	market    PaperMarketData
+	liquidity backtest.LiquidityModel
+	exits     *backtest.FillModel
+	latency   PaperLatency
+	rand      *simrand.Source
+	slippage  float64 // Slippage in punti base applicato a fill e uscite
+	balance   float64
+	orders    map[string]*paperOrder
+	links     map[string]string // orderLinkID -> orderID
+	positions map[string]*paperPosition
+	cursors   map[string]time.Time // Ultima candela elaborata per simbolo
+	marks     map[string]float64   // Ultimo prezzo di chiusura per simbolo
+	sequence  int
+}
+
+// paperOrder è un ordine simulato con la quantità già eseguita
+type paperOrder struct {
+	response models.OrderResponse
+	notional float64
+}
+
+// paperPosition è una posizione simulata
+type paperPosition struct {
+	side       models.OrderSide
+	size       float64
+	entryPrice float64
+	stopLoss   float64
+	takeProfit float64
+	realised   float64
+	updated    time.Time
+}
+
+// NewPaperOrderProcessor crea un processor simulato che legge le candele da market
+func NewPaperOrderProcessor(market PaperMarketData, cfg PaperConfig) (*PaperOrderProcessor, error) {
+	if market == nil {
+		return nil, fmt.Errorf("il paper trading richiede una sorgente di candele")
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	exits, err := backtest.NewFillModel(cfg.FillPriority, interval, cfg.LowerTimeframe)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Latency.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.SlippageBps < 0 {
+		return nil, fmt.Errorf("slippage non valido: %f bps", cfg.SlippageBps)
+	}
+	return &PaperOrderProcessor{
+		market:    market,
+		liquidity: cfg.Liquidity,
+		exits:     exits,
+		latency:   cfg.Latency,
+		rand:      cfg.Rand,
+		slippage:  cfg.SlippageBps,
+		balance:   cfg.InitialBalance,
+		orders:    make(map[string]*paperOrder),
+		links:     make(map[string]string),
+		positions: make(map[string]*paperPosition),
+		cursors:   make(map[string]time.Time),
+		marks:     make(map[string]float64),
+	}, nil
+}
+
+// PlaceLongOrder piazza un ordine di acquisto a mercato simulato
+func (pp *PaperOrderProcessor) PlaceLongOrder(ctx context.Context, symbol string, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
+	return pp.placeOrder(ctx, symbol, models.OrderSideBuy, price, quantity, stopLoss, takeProfit)
+}
+
+// PlaceShortOrder piazza un ordine di vendita a mercato simulato
+func (pp *PaperOrderProcessor) PlaceShortOrder(ctx context.Context, symbol string, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
+	return pp.placeOrder(ctx, symbol, models.OrderSideSell, price, quantity, stopLoss, takeProfit)
+}
+
+// placeOrder registra l'ordine e lo esegue subito sulla liquidità dell'ultima candela chiusa
+// La parte eccedente resta aperta e viene eseguita sulle candele successive
+// Prima dell'esecuzione attende la latenza simulata, come un ordine in viaggio verso l'exchange
+func (pp *PaperOrderProcessor) placeOrder(ctx context.Context, symbol string, side models.OrderSide, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("quantità non valida: %f", quantity)
+	}
+	latency := pp.latency.Sample(pp.rand)
+	if err := pp.latency.wait(ctx, latency); err != nil {
+		return nil, err
+	}
+	candles, err := pp.closedCandles(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	pp.processLocked(ctx, symbol, candles)
+
+	now := time.Now()
+	orderLinkID := orderLinkIDFromContext(ctx, fmt.Sprintf("paper_%s_%s_%d", strings.ToLower(string(side)), symbol, now.Unix()))
+	// Come sull'exchange, un ID cliente già usato non apre un secondo ordine
+	if orderID, ok := pp.links[orderLinkID]; ok {
+		return nil, fmt.Errorf("ID cliente %s già usato dall'ordine %s", orderLinkID, orderID)
+	}
+
+	pp.sequence++
+	order := &paperOrder{response: models.OrderResponse{
+		OrderID:     fmt.Sprintf("paper-%d", pp.sequence),
+		OrderLinkID: orderLinkID,
+		Symbol:      symbol,
+		Side:        side,
+		OrderType:   models.OrderTypeMarket,
+		Price:       price,
+		Quantity:    quantity,
+		Status:      models.OrderStatusNew,
+		StopLoss:    stopLoss,
+		TakeProfit:  takeProfit,
+		CreatedTime: now,
+		UpdatedTime: now,
+	}}
+	pp.orders[order.response.OrderID] = order
+	pp.links[order.response.OrderLinkID] = order.response.OrderID
+
+	if len(candles) > 0 {
+		pp.fillLocked(order, candles[len(candles)-1])
+	}
+	log.Printf("📝 Ordine simulato %s %s %s %.4f: latenza %v, slippage %.1f bps, eseguiti %.4f a %.6f",
+		order.response.OrderID, side, symbol, quantity, latency.Round(time.Millisecond), pp.slippage,
+		order.response.FilledQuantity, order.response.AveragePrice)
+
+	response := order.response
+	return &response, nil
+}
+
+// DeleteOrder cancella la parte non eseguita di un ordine simulato
+func (pp *PaperOrderProcessor) DeleteOrder(ctx context.Context, symbol, orderID string) (*models.OrderResponse, error) {
+	if err := pp.Advance(ctx, symbol); err != nil {
+		return nil, err
+	}
+
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	order, ok := pp.findLocked(orderID)
+	if !ok {
+		return nil, fmt.Errorf("ordine non trovato: %s", orderID)
+	}
+	switch order.response.Status {
+	case models.OrderStatusNew:
+		order.response.Status = models.OrderStatusCancelled
+	case models.OrderStatusPartiallyFilled:
+		order.response.Status = models.OrderStatusPartiallyFilledCanceled
+	default:
+		return nil, fmt.Errorf("ordine %s non cancellabile nello stato %s", orderID, order.response.Status)
+	}
+	order.response.UpdatedTime = time.Now()
+
+	response := order.response
+	return &response, nil
+}
+
+// UpdateOrder aggiorna stop loss e/o take profit della posizione simulata
+func (pp *PaperOrderProcessor) UpdateOrder(ctx context.Context, params UpdateOrderParams) (*models.OrderResponse, error) {
+	if params.StopLoss == nil && params.TakeProfit == nil {
+		return nil, fmt.Errorf("almeno uno tra StopLoss e TakeProfit deve essere specificato")
+	}
+
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	position, ok := pp.positions[params.Symbol]
+	if !ok {
+		return nil, fmt.Errorf("nessuna posizione aperta su %s", params.Symbol)
+	}
+	if params.StopLoss != nil {
+		position.stopLoss = *params.StopLoss
+	}
+	if params.TakeProfit != nil {
+		position.takeProfit = *params.TakeProfit
+	}
+	position.updated = time.Now()
+
+	return &models.OrderResponse{
+		Symbol:      params.Symbol,
+		Side:        position.side,
+		Quantity:    position.size,
+		StopLoss:    position.stopLoss,
+		TakeProfit:  position.takeProfit,
+		UpdatedTime: position.updated,
+	}, nil
+}
+
+// GetOrderStatus restituisce lo stato dell'ordine simulato dopo aver elaborato le nuove candele
+func (pp *PaperOrderProcessor) GetOrderStatus(ctx context.Context, symbol, orderID string) (*models.OrderResponse, error) {
+	if err := pp.Advance(ctx, symbol); err != nil {
+		return nil, err
+	}
+
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	order, ok := pp.findLocked(orderID)
+	if !ok {
+		return nil, fmt.Errorf("ordine non trovato: %s", orderID)
+	}
+	response := order.response
+	return &response, nil
+}
+
+// GetPositions restituisce le posizioni simulate aperte; se symbol è vuoto le restituisce tutte
+func (pp *PaperOrderProcessor) GetPositions(ctx context.Context, symbol string) ([]models.Position, error) {
+	symbols := []string{symbol}
+	if symbol == "" {
+		symbols = pp.activeSymbols()
+	}
+	for _, s := range symbols {
+		if err := pp.Advance(ctx, s); err != nil {
+			return nil, err
+		}
+	}
+
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	positions := make([]models.Position, 0, len(pp.positions))
+	for s, position := range pp.positions {
+		if symbol != "" && s != symbol {
+			continue
+		}
+		side := models.PositionSideBuy
+		if position.side == models.OrderSideSell {
+			side = models.PositionSideSell
+		}
+		mark := pp.marks[s]
+		paper := models.Position{
+			Symbol:         s,
+			Side:           side,
+			Size:           formatPaperFloat(position.size),
+			EntryPrice:     formatPaperFloat(position.entryPrice),
+			MarkPrice:      formatPaperFloat(mark),
+			UnrealisedPnl:  formatPaperFloat(position.pnl(mark)),
+			RealisedPnl:    formatPaperFloat(position.realised),
+			Leverage:       "1",
+			PositionStatus: models.PositionStatusNormal,
+			StopLoss:       formatPaperFloat(position.stopLoss),
+			TakeProfit:     formatPaperFloat(position.takeProfit),
+			UpdatedTime:    strconv.FormatInt(position.updated.UnixMilli(), 10),
+		}
+		paper.Parse()
+		positions = append(positions, paper)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Symbol < positions[j].Symbol })
+	return positions, nil
+}
+
+// GetWalletBalance restituisce il saldo simulato: saldo iniziale più PnL realizzato e non realizzato
+func (pp *PaperOrderProcessor) GetWalletBalance(ctx context.Context, accountType, coin string) (*models.WalletBalanceResponse, error) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	var unrealised float64
+	for symbol, position := range pp.positions {
+		unrealised += position.pnl(pp.marks[symbol])
+	}
+	equity := pp.balance + unrealised
+
+	now := time.Now()
+	info := models.AccountInfo{
+		AccountType:           paperAccountType,
+		TotalEquity:           formatPaperFloat(equity),
+		TotalWalletBalance:    formatPaperFloat(pp.balance),
+		TotalMarginBalance:    formatPaperFloat(equity),
+		TotalAvailableBalance: formatPaperFloat(equity),
+		UpdatedAt:             now,
+	}
+	if coin == "" || strings.EqualFold(coin, paperCoin) {
+		info.Coins = append(info.Coins, models.WalletBalance{
+			Coin:                paperCoin,
+			Equity:              formatPaperFloat(equity),
+			WalletBalance:       formatPaperFloat(pp.balance),
+			AvailableToWithdraw: formatPaperFloat(equity),
+			UpdatedAt:           now,
+		})
+	}
+
+	walletResp := &models.WalletBalanceResponse{RetMsg: "OK", Time: now.UnixMilli()}
+	walletResp.Result.List = []models.AccountInfo{info}
+	return walletResp, nil
+}
+
+// GetUSDTBalance restituisce il saldo USDT simulato
+func (pp *PaperOrderProcessor) GetUSDTBalance(ctx context.Context) (float64, error) {
+	return pp.GetCoinBalance(ctx, paperCoin)
+}
+
+// GetCoinBalance restituisce il saldo simulato di una valuta (solo USDT)
+func (pp *PaperOrderProcessor) GetCoinBalance(ctx context.Context, coin string) (float64, error) {
+	walletResp, err := pp.GetWalletBalance(ctx, paperAccountType, coin)
+	if err != nil {
+		return 0, err
+	}
+	coinBalance, found := walletResp.GetCoinBalance(coin)
+	if !found {
+		return 0, fmt.Errorf("saldo %s non trovato", coin)
+	}
+	return coinBalance.GetEquityFloat()
+}
+
+// GetAccountInfo restituisce la configurazione dell'account simulato, unificato con margine incrociato
+func (pp *PaperOrderProcessor) GetAccountInfo(ctx context.Context) (*models.AccountSettings, error) {
+	return &models.AccountSettings{
+		Exchange:   "paper",
+		Unified:    true,
+		MarginMode: models.MarginModeRegular,
+		FetchedAt:  time.Now(),
+	}, nil
+}
+
+// GetFeeRate non è supportato: la simulazione non applica commissioni
+func (pp *PaperOrderProcessor) GetFeeRate(ctx context.Context, symbol string) (*models.FeeRate, error) {
+	return nil, fmt.Errorf("commissioni non simulate dal paper trading")
+}
+
+// Advance elabora le candele chiuse dall'ultimo avanzamento: uscite delle posizioni e fill degli ordini aperti
+func (pp *PaperOrderProcessor) Advance(ctx context.Context, symbol string) error {
+	candles, err := pp.closedCandles(ctx, symbol)
+	if err != nil {
+		return err
+	}
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	pp.processLocked(ctx, symbol, candles)
+	return nil
+}
+
+// ProcessCandle applica una candela alla simulazione; usato dai backtest che scorrono lo storico
+func (pp *PaperOrderProcessor) ProcessCandle(ctx context.Context, symbol string, candle models.Candle) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	pp.processCandleLocked(ctx, symbol, candle)
+}
+
+// closedCandles scarica le ultime candele da 1 minuto scartando quella ancora aperta
+func (pp *PaperOrderProcessor) closedCandles(ctx context.Context, symbol string) ([]models.Candle, error) {
+	resp, err := pp.market.FetchLastCandles(ctx, symbol, models.DerivativesMarket, models.Timeframe1m, paperCandleLookback)
+	if err != nil {
+		return nil, fmt.Errorf("errore nel recupero delle candele di %s: %w", symbol, err)
+	}
+	return resp.Closed(), nil
+}
+
+// processLocked elabora le candele successive al cursore del simbolo
+// Alla prima lettura il cursore viene solo posizionato: lo storico precede gli ordini simulati
+func (pp *PaperOrderProcessor) processLocked(ctx context.Context, symbol string, candles []models.Candle) {
+	if len(candles) == 0 {
+		return
+	}
+	if _, ok := pp.cursors[symbol]; !ok {
+		last := candles[len(candles)-1]
+		pp.cursors[symbol] = last.Timestamp
+		pp.marks[symbol] = last.Close
+		return
+	}
+	for _, candle := range candles {
+		pp.processCandleLocked(ctx, symbol, candle)
+	}
+}
+
+// processCandleLocked verifica le uscite della posizione e poi esegue gli ordini aperti sulla candela
+func (pp *PaperOrderProcessor) processCandleLocked(ctx context.Context, symbol string, candle models.Candle) {
+	if cursor, ok := pp.cursors[symbol]; ok && !candle.Timestamp.After(cursor) {
+		return
+	}
+	pp.cursors[symbol] = candle.Timestamp
+	pp.marks[symbol] = candle.Close
+
+	if position, ok := pp.positions[symbol]; ok {
+		bracket := backtest.Bracket{Symbol: symbol, Side: position.side, StopLoss: position.stopLoss, TakeProfit: position.takeProfit}
+		exit, err := pp.exits.Exit(ctx, bracket, candle)
+		if err != nil {
+			log.Printf("⚠️  Paper trading: uscita %s non valutata: %v", symbol, err)
+		}
+		if exit != nil {
+			pp.balance += position.pnl(slipPrice(position.closingSide(), exit.Price, pp.slippage))
+			delete(pp.positions, symbol)
+		}
+	}
+
+	for _, order := range pp.openOrdersLocked(symbol) {
+		pp.fillLocked(order, candle)
+	}
+}
+
+// fillLocked esegue la parte rimanente dell'ordine entro la liquidità della candela
+func (pp *PaperOrderProcessor) fillLocked(order *paperOrder, candle models.Candle) {
+	remaining := order.response.Quantity - order.response.FilledQuantity
+	price, filled := pp.liquidity.Fill(order.response.Symbol, order.response.Side, remaining, 0, candle)
+	if filled <= 0 {
+		return
+	}
+	price = slipPrice(order.response.Side, price, pp.slippage)
+
+	order.response.FilledQuantity += filled
+	order.notional += filled * price
+	order.response.AveragePrice = order.notional / order.response.FilledQuantity
+	order.response.Status = backtest.FillStatus(order.response.FilledQuantity, order.response.Quantity)
+	order.response.UpdatedTime = candle.Timestamp.Add(time.Minute)
+
+	pp.applyFillLocked(order.response.Symbol, order.response.Side, filled, price, order.response.StopLoss, order.response.TakeProfit, order.response.UpdatedTime)
+}
+
+// applyFillLocked aggiorna la posizione con un fill: aumenta, riduce o inverte la posizione
+func (pp *PaperOrderProcessor) applyFillLocked(symbol string, side models.OrderSide, quantity, price, stopLoss, takeProfit float64, at time.Time) {
+	position, ok := pp.positions[symbol]
+	if !ok {
+		pp.positions[symbol] = &paperPosition{side: side, size: quantity, entryPrice: price, stopLoss: stopLoss, takeProfit: takeProfit, updated: at}
+		return
+	}
+
+	if position.side == side {
+		position.entryPrice = (position.entryPrice*position.size + price*quantity) / (position.size + quantity)
+		position.size += quantity
+		if stopLoss > 0 {
+			position.stopLoss = stopLoss
+		}
+		if takeProfit > 0 {
+			position.takeProfit = takeProfit
+		}
+		position.updated = at
+		return
+	}
+
+	closed := quantity
+	if closed > position.size {
+		closed = position.size
+	}
+	realised := position.direction() * (price - position.entryPrice) * closed
+	pp.balance += realised
+	position.realised += realised
+	position.size -= closed
+	position.updated = at
+
+	if position.size <= 0 {
+		delete(pp.positions, symbol)
+		if remaining := quantity - closed; remaining > 0 {
+			pp.positions[symbol] = &paperPosition{side: side, size: remaining, entryPrice: price, stopLoss: stopLoss, takeProfit: takeProfit, updated: at}
+		}
+	}
+}
+
+// openOrdersLocked restituisce gli ordini ancora eseguibili di un simbolo in ordine di creazione
+func (pp *PaperOrderProcessor) openOrdersLocked(symbol string) []*paperOrder {
+	var open []*paperOrder
+	for _, order := range pp.orders {
+		if order.response.Symbol != symbol {
+			continue
+		}
+		if order.response.Status == models.OrderStatusNew || order.response.Status == models.OrderStatusPartiallyFilled {
+			open = append(open, order)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].response.CreatedTime.Before(open[j].response.CreatedTime) })
+	return open
+}
+
+// findLocked cerca un ordine per orderID o orderLinkID
+func (pp *PaperOrderProcessor) findLocked(orderID string) (*paperOrder, bool) {
+	if order, ok := pp.orders[orderID]; ok {
+		return order, true
+	}
+	if id, ok := pp.links[orderID]; ok {
+		order, ok := pp.orders[id]
+		return order, ok
+	}
+	return nil, false
+}
+
+// activeSymbols restituisce i simboli con posizioni o ordini aperti
+func (pp *PaperOrderProcessor) activeSymbols() []string {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	seen := make(map[string]bool)
+	for symbol := range pp.positions {
+		seen[symbol] = true
+	}
+	for _, order := range pp.orders {
+		if order.response.Status == models.OrderStatusNew || order.response.Status == models.OrderStatusPartiallyFilled {
+			seen[order.response.Symbol] = true
+		}
+	}
+	symbols := make([]string, 0, len(seen))
+	for symbol := range seen {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// direction restituisce +1 per le posizioni long e -1 per le short
+func (p *paperPosition) direction() float64 {
+	if p.side == models.OrderSideSell {
+		return -1
+	}
+	return 1
+}
+
+// closingSide restituisce il lato dell'ordine che chiude la posizione
+func (p *paperPosition) closingSide() models.OrderSide {
+	if p.side == models.OrderSideSell {
+		return models.OrderSideBuy
+	}
+	return models.OrderSideSell
+}
+
+// pnl calcola il PnL della posizione al prezzo indicato
+func (p *paperPosition) pnl(price float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+	return p.direction() * (price - p.entryPrice) * p.size
+}
+
+// formatPaperFloat formatta un valore nel formato stringa usato dalle risposte Bybit
+func formatPaperFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
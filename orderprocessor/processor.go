@@ -3,6 +3,7 @@ package orderprocessor
 import (
 	"context"
 	"cross-exchange-arbitrage/models"
+	"time"
 )
 
 // UpdateOrderParams rappresenta i parametri per aggiornare un ordine
@@ -39,6 +40,13 @@ type OrderProcessor interface {
 	// Se symbol è vuoto, restituisce tutte le posizioni attive
 	GetPositions(ctx context.Context, symbol string) ([]models.Position, error)
 
+	// AccountReader espone le letture del wallet usate anche per il dimensionamento degli ordini
+	AccountReader
+}
+
+// AccountReader definisce le sole letture dell'account, eseguibili con una API key di sola lettura
+// Analytics e reportistica devono dipendere solo da questa interfaccia
+type AccountReader interface {
 	// GetWalletBalance recupera il saldo del wallet per un account specifico
 	// Se coin è vuoto, restituisce tutti i saldi; altrimenti filtra per la criptovaluta specificata
 	GetWalletBalance(ctx context.Context, accountType, coin string) (*models.WalletBalanceResponse, error)
@@ -48,4 +56,97 @@ type OrderProcessor interface {
 
 	// GetCoinBalance recupera il saldo per una specifica criptovaluta (metodo di convenienza)
 	GetCoinBalance(ctx context.Context, coin string) (float64, error)
+
+	// GetAccountInfo recupera la configurazione dell'account (account unificato, modalità di margine)
+	GetAccountInfo(ctx context.Context) (*models.AccountSettings, error)
+
+	// GetFeeRate recupera le commissioni maker e taker effettive dell'account sul perpetual indicato
+	GetFeeRate(ctx context.Context, symbol string) (*models.FeeRate, error)
+}
+
+// SpotOrderProcessor è implementato dai processor che possono operare sul mercato spot
+// Usato dalle strategie che combinano una gamba spot con una perpetual (es. funding arbitrage)
+type SpotOrderProcessor interface {
+	// PlaceSpotMarketOrder piazza un ordine spot a mercato; quantity è espressa nella base coin
+	PlaceSpotMarketOrder(ctx context.Context, symbol string, side models.OrderSide, quantity float64) (*models.OrderResponse, error)
+}
+
+// BorrowRateReader è implementato dai processor di account a margine unificato che espongono i tassi di prestito
+// Usato dalle strategie che possono finanziare la gamba spot con il margine dell'account (es. funding arbitrage)
+type BorrowRateReader interface {
+	// GetBorrowRate recupera il tasso di prestito orario di una coin e l'importo preso in prestito dall'account
+	GetBorrowRate(ctx context.Context, coin string) (*models.BorrowRate, error)
+}
+
+// SubAccountManager è implementato dai processor il cui account master può creare sub-account e relative API key
+// Usato per isolare ogni strategia in un proprio sub-account; la key del master deve poter gestire i sub-account
+type SubAccountManager interface {
+	// CreateSubAccount crea un sub-account sotto il master account
+	CreateSubAccount(ctx context.Context, req models.SubAccountRequest) (*models.SubAccount, error)
+
+	// CreateSubAPIKey crea una API key per un sub-account; il secret è disponibile solo nella risposta
+	CreateSubAPIKey(ctx context.Context, req models.SubAPIKeyRequest) (*models.SubAPIKey, error)
+
+	// ListSubAccounts elenca i sub-account del master account
+	ListSubAccounts(ctx context.Context) ([]models.SubAccount, error)
+}
+
+// MarketOrderProcessor è implementato dai processor che piazzano ordini a mercato sui derivati con la quantità
+// esatta, senza arrotondarla come PlaceLongOrder e PlaceShortOrder. Il chiamante arrotonda la quantità al passo
+// del simbolo; con reduceOnly l'ordine può solo ridurre la posizione aperta
+type MarketOrderProcessor interface {
+	// PlaceMarketOrder piazza un ordine a mercato IOC sul perpetual; quantity è espressa nella base coin
+	PlaceMarketOrder(ctx context.Context, symbol string, side models.OrderSide, quantity float64, reduceOnly bool) (*models.OrderResponse, error)
+}
+
+// LimitOrderProcessor è implementato dai processor che piazzano ordini limite sui derivati
+// Usato dagli algoritmi di esecuzione che attendono al miglior prezzo prima di attraversare lo spread
+type LimitOrderProcessor interface {
+	// PlaceLimitOrder piazza un ordine limite sul perpetual; con postOnly l'exchange lo annulla se eseguirebbe
+	// subito contro il book. quantity è espressa nella base coin, già arrotondata al passo del simbolo
+	PlaceLimitOrder(ctx context.Context, symbol string, side models.OrderSide, price, quantity, stopLoss, takeProfit float64, postOnly bool) (*models.OrderResponse, error)
+}
+
+// OrderAmender è implementato dai processor che modificano il prezzo di un ordine limite aperto
+// Usato dagli algoritmi di esecuzione che seguono il miglior prezzo senza inviare nuovi ordini
+type OrderAmender interface {
+	// AmendOrderPrice sposta l'ordine (orderID o orderLinkID) al nuovo prezzo
+	AmendOrderPrice(ctx context.Context, symbol, orderID string, price float64) error
+}
+
+// CashFlowReader è implementato dagli account che espongono depositi e prelievi (transaction log)
+// Usato dalla reportistica per separare i movimenti esterni dal PnL di trading
+type CashFlowReader interface {
+	// GetCashFlows recupera i movimenti esterni di una valuta avvenuti tra since e until, in ordine cronologico
+	GetCashFlows(ctx context.Context, coin string, since, until time.Time) ([]models.CashFlow, error)
+}
+
+// AccountTransferer è implementato dai processor che possono spostare fondi tra i propri account
+// Usato dal bilanciamento dei margini per rifornire l'account di trading dal funding account
+type AccountTransferer interface {
+	// InternalTransfer trasferisce fondi tra due account dello stesso utente
+	InternalTransfer(ctx context.Context, transfer models.TransferRequest) (*models.TransferResult, error)
+
+	// GetTransferableBalance recupera l'importo trasferibile di una valuta in un account
+	GetTransferableBalance(ctx context.Context, accountType, coin string) (float64, error)
+}
+
+// MemberTransferer è implementato dai processor del master account che spostano fondi tra master e sub-account
+// Usato dal ribilanciamento del portafoglio; la key del master deve avere il permesso Wallet/SubMemberTransfer
+type MemberTransferer interface {
+	// UniversalTransfer trasferisce fondi tra UID diversi (master e sub-account)
+	UniversalTransfer(ctx context.Context, transfer models.TransferRequest) (*models.TransferResult, error)
+
+	// GetMemberBalance recupera saldo e importo trasferibile di una valuta nell'account di un UID
+	GetMemberBalance(ctx context.Context, memberID int64, accountType, coin string) (*models.MemberBalance, error)
+
+	// GetAPIKeyInfo recupera le informazioni della key, tra cui l'UID del master account
+	GetAPIKeyInfo(ctx context.Context) (*models.APIKeyInfo, error)
+}
+
+// OpenOrderReader è implementato dai processor che elencano gli ordini aperti dell'account
+// Usato dal controllo delle attività dell'account per trovare ordini che il bot non ha creato
+type OpenOrderReader interface {
+	// GetOpenOrders recupera gli ordini aperti sui derivati; se symbol è vuoto restituisce quelli di tutti i simboli
+	GetOpenOrders(ctx context.Context, symbol string) ([]models.OrderResponse, error)
 }
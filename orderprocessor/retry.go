@@ -0,0 +1,130 @@
+package orderprocessor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// orderLinkIDKey è la chiave di contesto dell'ID cliente da assegnare all'ordine
+type orderLinkIDKey struct{}
+
+// WithOrderLinkID indica ai processor l'ID cliente (orderLinkId, cliOrdId, clientOrderId) da usare
+// per l'ordine piazzato con il contesto restituito. Riusare lo stesso ID nei nuovi tentativi rende il
+// piazzamento idempotente: l'exchange rifiuta un secondo ordine con lo stesso ID invece di aprirne un altro
+func WithOrderLinkID(ctx context.Context, orderLinkID string) context.Context {
+	return context.WithValue(ctx, orderLinkIDKey{}, orderLinkID)
+}
+
+// orderLinkIDFromContext restituisce l'ID cliente indicato con WithOrderLinkID, altrimenti fallback
+func orderLinkIDFromContext(ctx context.Context, fallback string) string {
+	if id, ok := ctx.Value(orderLinkIDKey{}).(string); ok && id != "" {
+		return id
+	}
+	return fallback
+}
+
+// RetryPolicy configura i tentativi di piazzamento di un ordine
+type RetryPolicy struct {
+	Attempts int           // Tentativi totali, compreso il primo
+	Backoff  time.Duration // Attesa prima del secondo tentativo, raddoppiata a ogni tentativo successivo
+
+	// Allow viene chiamata prima di ogni nuovo tentativo; un errore interrompe i tentativi
+	// (es. exchange in modalità degradata). nil non blocca nulla
+	Allow func() error
+}
+
+// RejectedError è l'errore di un ordine rifiutato dall'exchange
+// Conserva l'ID cliente e il motivo del rifiuto, così il chiamante può registrare l'ordine rifiutato
+type RejectedError struct {
+	OrderLinkID string
+	Code        string
+	Message     string
+	Reason      models.RejectReason
+}
+
+// Error implementa l'interfaccia error
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("ordine rifiutato - %s (codice: %s)", e.Message, e.Code)
+}
+
+// newRejectedError crea l'errore di rifiuto dalla risposta dell'exchange
+func newRejectedError(orderLinkID string, resp *models.OrderResponse) *RejectedError {
+	reason := resp.RejectReason
+	if reason == "" {
+		reason = models.RejectReasonOther
+	}
+	return &RejectedError{OrderLinkID: orderLinkID, Code: resp.ErrorCode, Message: resp.ErrorMessage, Reason: reason}
+}
+
+// PlaceFunc piazza un ordine con il contesto indicato, che contiene l'ID cliente da usare
+type PlaceFunc func(ctx context.Context) (*models.OrderResponse, error)
+
+// PlaceWithRetry piazza un ordine riprovando in caso di errore o rifiuto, sempre con lo stesso ID cliente
+// (prefix seguito da un suffisso casuale). Un rifiuto con motivo non ritentabile (saldo, precisione, risk limit)
+// viene restituito subito: si ripeterebbe identico a ogni tentativo. Prima di ogni nuovo tentativo cerca l'ordine per ID cliente:
+// se un tentativo precedente è andato in timeout ma l'exchange lo ha accettato, restituisce quell'ordine
+// invece di piazzarne un secondo. Restituisce l'errore dell'ultimo tentativo se nessuno va a buon fine
+func PlaceWithRetry(ctx context.Context, processor OrderProcessor, symbol, prefix string, policy RetryPolicy, place PlaceFunc) (*models.OrderResponse, error) {
+	orderLinkID := GenerateOrderLinkID(prefix)
+	ctx = WithOrderLinkID(ctx, orderLinkID)
+
+	attempts := max(policy.Attempts, 1)
+	backoff := policy.Backoff
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if policy.Allow != nil {
+				if err := policy.Allow(); err != nil {
+					return nil, fmt.Errorf("%w (nuovi tentativi sospesi: %v)", lastErr, err)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("%w (nuovi tentativi annullati: %v)", lastErr, ctx.Err())
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+
+			if existing, ok := findPlacedOrder(ctx, processor, symbol, orderLinkID); ok {
+				log.Printf("♻️  Ordine %s già accettato dall'exchange (%s): nessun nuovo tentativo", orderLinkID, existing.OrderID)
+				return existing, nil
+			}
+		}
+
+		resp, err := place(ctx)
+		switch {
+		case errors.Is(err, ErrObserverMode):
+			// In modalità observer nessun tentativo arriva all'exchange: ritentare non cambia l'esito
+			return nil, err
+		case err != nil:
+			lastErr = err
+		case !resp.IsSuccess():
+			rejected := newRejectedError(orderLinkID, resp)
+			if !rejected.Reason.Retryable() {
+				log.Printf("⛔ Ordine %s rifiutato (%s): nessun nuovo tentativo", orderLinkID, rejected.Reason)
+				return nil, rejected
+			}
+			lastErr = rejected
+		default:
+			return resp, nil
+		}
+		log.Printf("⚠️  Tentativo %d/%d di piazzamento %s fallito: %v", attempt, attempts, orderLinkID, lastErr)
+	}
+	return nil, lastErr
+}
+
+// findPlacedOrder cerca sull'exchange un ordine accettato con l'ID cliente indicato
+// Un errore di lettura equivale a ordine non trovato: il nuovo tentativo resta idempotente
+// perché l'exchange rifiuterebbe comunque un ID cliente già usato
+func findPlacedOrder(ctx context.Context, processor OrderProcessor, symbol, orderLinkID string) (*models.OrderResponse, bool) {
+	resp, err := processor.GetOrderStatus(ctx, symbol, orderLinkID)
+	if err != nil || resp == nil || resp.OrderID == "" || resp.Status == models.OrderStatusRejected {
+		return nil, false
+	}
+	return resp, true
+}
@@ -0,0 +1,56 @@
+package orderprocessor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// lookupFailingProcessor non trova mai l'ordine per ID cliente, così ogni nuovo tentativo piazza di nuovo
+type lookupFailingProcessor struct {
+	OrderProcessor
+}
+
+func (lookupFailingProcessor) GetOrderStatus(ctx context.Context, symbol, orderID string) (*models.OrderResponse, error) {
+	return nil, errors.New("order not found")
+}
+
+func TestPlaceWithRetryRejections(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		reason    models.RejectReason
+		wantCalls int
+	}{
+		{"insufficient_balance", "110007", models.RejectReasonInsufficientBalance, 1},
+		{"qty_precision", "170137", models.RejectReasonQtyPrecision, 1},
+		{"risk_limit", "110090", models.RejectReasonRiskLimit, 1},
+		{"unclassified", "10006", models.RejectReasonOther, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			place := func(ctx context.Context) (*models.OrderResponse, error) {
+				calls++
+				return &models.OrderResponse{ErrorCode: tt.code, ErrorMessage: tt.name, RejectReason: tt.reason}, nil
+			}
+			policy := RetryPolicy{Attempts: 3, Backoff: time.Millisecond}
+
+			_, err := PlaceWithRetry(context.Background(), lookupFailingProcessor{}, "DOGEUSDT", "long_DOGEUSDT", policy, place)
+			var rejected *RejectedError
+			if !errors.As(err, &rejected) {
+				t.Fatalf("error = %v, want *RejectedError", err)
+			}
+			if rejected.Reason != tt.reason {
+				t.Errorf("reason = %s, want %s", rejected.Reason, tt.reason)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("attempts = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
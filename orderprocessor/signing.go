@@ -0,0 +1,226 @@
+package orderprocessor
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// bybitRecvWindow è la finestra di validità (in millisecondi) inviata con ogni richiesta firmata
+	bybitRecvWindow = "5000"
+
+	// AuthTypeHMAC firma le richieste con HMAC SHA256 usando il secret della API key
+	AuthTypeHMAC = "hmac"
+
+	// AuthTypeRSA firma le richieste con RSA SHA256 usando la chiave privata associata alla API key
+	AuthTypeRSA = "rsa"
+)
+
+// Signer calcola la firma X-BAPI-SIGN di una richiesta Bybit
+type Signer interface {
+	// Sign firma il payload (timestamp + apiKey + recvWindow + query/body)
+	Sign(payload string) (string, error)
+
+	// AuthType restituisce il tipo di autenticazione (hmac o rsa)
+	AuthType() string
+}
+
+// hmacSigner firma con HMAC SHA256, firma in esadecimale
+type hmacSigner struct {
+	secret string
+}
+
+// NewHMACSigner crea un Signer per le API key di sistema con secret
+func NewHMACSigner(secret string) Signer {
+	return &hmacSigner{secret: secret}
+}
+
+// Sign implementa Signer
+func (s *hmacSigner) Sign(payload string) (string, error) {
+	return hmacSignature(s.secret, payload), nil
+}
+
+// AuthType implementa Signer
+func (s *hmacSigner) AuthType() string {
+	return AuthTypeHMAC
+}
+
+// rsaSigner firma con RSA SHA256 (PKCS#1 v1.5), firma in base64
+type rsaSigner struct {
+	key *rsa.PrivateKey
+}
+
+// NewRSASigner crea un Signer per le API key RSA a partire dalla chiave privata in formato PEM
+// Sono accettate chiavi PKCS#1 ("RSA PRIVATE KEY") e PKCS#8 ("PRIVATE KEY")
+func NewRSASigner(pemData []byte) (Signer, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("chiave privata RSA non valida: blocco PEM non trovato")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return &rsaSigner{key: key}, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("chiave privata RSA non valida: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("chiave privata non RSA (%T)", parsed)
+	}
+
+	return &rsaSigner{key: key}, nil
+}
+
+// Sign implementa Signer
+func (s *rsaSigner) Sign(payload string) (string, error) {
+	digest := sha256.Sum256([]byte(payload))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("errore nella firma RSA: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// AuthType implementa Signer
+func (s *rsaSigner) AuthType() string {
+	return AuthTypeRSA
+}
+
+// NewSigner crea il Signer indicato da authType
+// Per hmac serve il secret della API key, per rsa il percorso del file PEM con la chiave privata
+func NewSigner(authType, secret, rsaKeyPath string) (Signer, error) {
+	switch strings.ToLower(authType) {
+	case "", AuthTypeHMAC:
+		if secret == "" {
+			return nil, fmt.Errorf("secret mancante per l'autenticazione HMAC")
+		}
+		return NewHMACSigner(secret), nil
+	case AuthTypeRSA:
+		if rsaKeyPath == "" {
+			return nil, fmt.Errorf("percorso della chiave privata mancante per l'autenticazione RSA")
+		}
+		pemData, err := os.ReadFile(rsaKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("impossibile leggere la chiave privata RSA: %w", err)
+		}
+		return NewRSASigner(pemData)
+	default:
+		return nil, fmt.Errorf("tipo di autenticazione %q non supportato (usa %s o %s)", authType, AuthTypeHMAC, AuthTypeRSA)
+	}
+}
+
+// SignedRequest contiene tutti gli elementi di autenticazione di una richiesta Bybit
+// Serve a diagnosticare gli errori di firma (retCode 10004) senza inviare la richiesta
+type SignedRequest struct {
+	Method     string
+	Path       string
+	Query      string // Query string così come viene inviata (solo GET)
+	Body       string // Body così come viene inviato (solo POST)
+	Timestamp  string
+	RecvWindow string
+	Payload    string // Stringa firmata: timestamp + apiKey + recvWindow + query/body
+	AuthType   string
+	Signature  string
+	Warnings   []string // Differenze rispetto a come il bot costruirebbe la stessa richiesta
+}
+
+// Headers restituisce gli header di autenticazione che accompagnano la richiesta
+func (sr *SignedRequest) Headers(apiKey string) map[string]string {
+	headers := map[string]string{
+		"X-BAPI-API-KEY":     apiKey,
+		"X-BAPI-TIMESTAMP":   sr.Timestamp,
+		"X-BAPI-RECV-WINDOW": sr.RecvWindow,
+		"X-BAPI-SIGN":        sr.Signature,
+	}
+	if sr.Method == "POST" {
+		headers["Content-Type"] = "application/json"
+	}
+	return headers
+}
+
+// SignBybitRequest calcola la firma di una richiesta come farebbero i processori Bybit
+// target è il path dell'endpoint, con l'eventuale query string (es. /v5/order/realtime?category=linear)
+// Se recvWindow è vuoto viene usato quello del bot
+func SignBybitRequest(apiKey string, signer Signer, method, target, body, recvWindow string, at time.Time) (*SignedRequest, error) {
+	if apiKey == "" || signer == nil {
+		return nil, fmt.Errorf("API key e credenziali di firma sono obbligatorie per firmare la richiesta")
+	}
+	if recvWindow == "" {
+		recvWindow = bybitRecvWindow
+	}
+
+	method = strings.ToUpper(method)
+	path, query, _ := strings.Cut(target, "?")
+
+	sr := &SignedRequest{
+		Method:     method,
+		Path:       path,
+		Timestamp:  strconv.FormatInt(at.UnixMilli(), 10),
+		RecvWindow: recvWindow,
+		AuthType:   signer.AuthType(),
+	}
+
+	switch method {
+	case "GET":
+		// Per le GET la firma copre la query string, il body non viene inviato
+		sr.Query = query
+		if body != "" {
+			sr.Warnings = append(sr.Warnings, "GET requests carry no body: the body was ignored")
+		}
+		if params, err := url.ParseQuery(query); err != nil {
+			return nil, fmt.Errorf("query string non valida: %w", err)
+		} else if encoded := params.Encode(); encoded != query {
+			sr.Warnings = append(sr.Warnings, fmt.Sprintf("the bot signs sorted, URL-encoded parameters: %s", encoded))
+		}
+		sr.Payload = sr.Timestamp + apiKey + recvWindow + query
+	case "POST":
+		// Per le POST la firma copre il body JSON esattamente come inviato
+		sr.Body = body
+		if query != "" {
+			sr.Warnings = append(sr.Warnings, "POST requests sign only the body: the query string is not part of the signature")
+		}
+		if body != "" {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, []byte(body)); err != nil {
+				sr.Warnings = append(sr.Warnings, fmt.Sprintf("the body is not valid JSON: %v", err))
+			} else if compact.String() != body {
+				sr.Warnings = append(sr.Warnings, fmt.Sprintf("the bot sends compact JSON (json.Marshal): %s", compact.String()))
+			}
+		}
+		sr.Payload = sr.Timestamp + apiKey + recvWindow + body
+	default:
+		return nil, fmt.Errorf("metodo %s non supportato (usa GET o POST)", method)
+	}
+
+	signature, err := signer.Sign(sr.Payload)
+	if err != nil {
+		return nil, err
+	}
+	sr.Signature = signature
+	return sr, nil
+}
+
+// hmacSignature calcola la firma HMAC SHA256 in esadecimale richiesta da Bybit
+func hmacSignature(secret, payload string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(payload))
+	return hex.EncodeToString(h.Sum(nil))
+}
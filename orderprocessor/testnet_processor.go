@@ -3,10 +3,9 @@ package orderprocessor
 import (
 	"bytes"
 	"context"
+	"cross-exchange-arbitrage/correlation"
 	"cross-exchange-arbitrage/models"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
+	"cross-exchange-arbitrage/outage"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -24,25 +23,30 @@ const (
 // BybitTestnetOrderProcessor implementa OrderProcessor per Bybit Testnet
 type BybitTestnetOrderProcessor struct {
 	apiKey     string
-	apiSecret  string
+	signer     Signer
 	httpClient *http.Client
 }
 
 // NewBybitTestnetOrderProcessor crea una nuova istanza per testnet
 func NewBybitTestnetOrderProcessor(apiKey, apiSecret string) *BybitTestnetOrderProcessor {
+	return NewBybitTestnetOrderProcessorWithSigner(apiKey, NewHMACSigner(apiSecret))
+}
+
+// NewBybitTestnetOrderProcessorWithSigner crea una nuova istanza che firma le richieste con signer (HMAC o RSA)
+func NewBybitTestnetOrderProcessorWithSigner(apiKey string, signer Signer) *BybitTestnetOrderProcessor {
 	return &BybitTestnetOrderProcessor{
-		apiKey:    apiKey,
-		apiSecret: apiSecret,
-		httpClient: &http.Client{
+		apiKey: apiKey,
+		signer: signer,
+		httpClient: outage.NewClient("bybit", correlation.NewClient(correlation.BybitHeader, &http.Client{
 			Timeout: 30 * time.Second,
-		},
+		})),
 	}
 }
 
 // PlaceLongOrder implementa l'interfaccia OrderProcessor per ordini long su testnet
 // Crea un ordine Stop-Limit: si attiva al trigger price e poi esegue un ordine limit al prezzo specificato
 func (bp *BybitTestnetOrderProcessor) PlaceLongOrder(ctx context.Context, symbol string, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
-	orderLinkID := fmt.Sprintf("testnet_long_%s_%d", symbol, time.Now().Unix())
+	orderLinkID := orderLinkIDFromContext(ctx, fmt.Sprintf("testnet_long_%s_%d", symbol, time.Now().Unix()))
 
 	// Per ordini LONG Stop-Limit:
 	// - TriggerPrice: prezzo a cui si attiva l'ordine
@@ -70,7 +74,7 @@ func (bp *BybitTestnetOrderProcessor) PlaceLongOrder(ctx context.Context, symbol
 // PlaceShortOrder implementa l'interfaccia OrderProcessor per ordini short su testnet
 // Crea un ordine Stop-Limit: si attiva al trigger price e poi esegue un ordine limit al prezzo specificato
 func (bp *BybitTestnetOrderProcessor) PlaceShortOrder(ctx context.Context, symbol string, price, quantity, stopLoss, takeProfit float64) (*models.OrderResponse, error) {
-	orderLinkID := fmt.Sprintf("testnet_short_%s_%d", symbol, time.Now().Unix())
+	orderLinkID := orderLinkIDFromContext(ctx, fmt.Sprintf("testnet_short_%s_%d", symbol, time.Now().Unix()))
 
 	// Per ordini SHORT Stop-Limit:
 	// - TriggerPrice: prezzo a cui si attiva l'ordine
@@ -111,8 +115,11 @@ func (bp *BybitTestnetOrderProcessor) placeOrder(ctx context.Context, orderReq *
 
 	// Headers per autenticazione
 	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	recv_window := "5000"
-	signature := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
@@ -155,6 +162,7 @@ func (bp *BybitTestnetOrderProcessor) placeOrder(ctx context.Context, orderReq *
 		UpdatedTime:  time.Unix(apiResp.Time/1000, 0),
 		ErrorCode:    strconv.Itoa(apiResp.RetCode),
 		ErrorMessage: apiResp.RetMsg,
+		RejectReason: bybitRejectReason(apiResp.RetCode, apiResp.RetMsg),
 	}
 
 	// Converte i valori
@@ -218,8 +226,11 @@ func (bp *BybitTestnetOrderProcessor) DeleteOrder(ctx context.Context, symbol, o
 
 	// Headers per autenticazione
 	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	recv_window := "5000"
-	signature := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
@@ -326,8 +337,11 @@ func (bp *BybitTestnetOrderProcessor) UpdateOrder(ctx context.Context, params Up
 
 	// Headers per autenticazione
 	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	recv_window := "5000"
-	signature := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, string(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
@@ -369,6 +383,7 @@ func (bp *BybitTestnetOrderProcessor) UpdateOrder(ctx context.Context, params Up
 		UpdatedTime:  time.Unix(updateResp.Time/1000, 0),
 		ErrorCode:    strconv.Itoa(updateResp.RetCode),
 		ErrorMessage: updateResp.RetMsg,
+		RejectReason: bybitRejectReason(updateResp.RetCode, updateResp.RetMsg),
 	}
 
 	// Aggiorna i valori modificati
@@ -417,11 +432,14 @@ func (bp *BybitTestnetOrderProcessor) GetOrderStatus(ctx context.Context, symbol
 
 	// Headers per autenticazione
 	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	recv_window := "5000"
+	recv_window := outage.RecvWindow("bybit", bybitRecvWindow)
 
 	// Per richieste GET, il payload per la firma è costituito dai parametri query
 	queryString := params.Encode()
-	signature := bp.generateSignature(timestamp, bp.apiKey, recv_window, queryString)
+	signature, err := bp.generateSignature(timestamp, bp.apiKey, recv_window, queryString)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella firma della richiesta: %w", err)
+	}
 
 	req.Header.Set("X-BAPI-API-KEY", bp.apiKey)
 	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
@@ -539,10 +557,8 @@ func isTestnetUUIDFormat(id string) bool {
 	return len(id) == 36 && id[8] == '-' && id[13] == '-' && id[18] == '-' && id[23] == '-'
 }
 
-// generateSignature genera la firma HMAC SHA256
-func (bp *BybitTestnetOrderProcessor) generateSignature(timestamp, apiKey, recvWindow, body string) string {
+// generateSignature genera la firma con il signer configurato
+func (bp *BybitTestnetOrderProcessor) generateSignature(timestamp, apiKey, recvWindow, body string) (string, error) {
 	payload := timestamp + apiKey + recvWindow + body
-	h := hmac.New(sha256.New, []byte(bp.apiSecret))
-	h.Write([]byte(payload))
-	return hex.EncodeToString(h.Sum(nil))
+	return bp.signer.Sign(payload)
 }
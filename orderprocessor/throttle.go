@@ -0,0 +1,166 @@
+package orderprocessor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrOrderThrottled indica che l'ordine supererebbe il limite di ordini per simbolo o per account
+var ErrOrderThrottled = errors.New("limite di ordini superato")
+
+// Modalità di gestione degli ordini oltre il limite
+const (
+	ThrottleReject = "reject" // L'ordine viene scartato subito
+	ThrottleQueue  = "queue"  // L'ordine attende che si liberi un posto, fino all'attesa massima
+)
+
+// ThrottleLimits sono i limiti di ordini per finestra; 0 disabilita il limite
+type ThrottleLimits struct {
+	SymbolPerMinute  int // Ordini al minuto per simbolo e account
+	SymbolPerHour    int // Ordini all'ora per simbolo e account
+	AccountPerMinute int // Ordini al minuto per account, su tutti i simboli
+	AccountPerHour   int // Ordini all'ora per account, su tutti i simboli
+}
+
+// throttleRule è un limite applicato a una chiave (account o account e simbolo) su una finestra
+type throttleRule struct {
+	key    string
+	limit  int
+	window time.Duration
+}
+
+// OrderThrottle limita il numero di ordini inviati per simbolo e per account su finestre mobili di un minuto
+// e di un'ora, così un errore di una strategia che ripete gli ordini non porta al ban dell'account
+// Il conteggio è condiviso da tutti i worker dell'istanza. Un OrderThrottle nil ammette tutti gli ordini
+type OrderThrottle struct {
+	limits  ThrottleLimits
+	mode    string
+	maxWait time.Duration // Attesa massima in modalità queue
+
+	mu     sync.Mutex
+	orders map[string][]time.Time // Invii dell'ultima ora per chiave, in ordine cronologico
+}
+
+// NewOrderThrottle crea il limitatore; mode è reject o queue
+func NewOrderThrottle(limits ThrottleLimits, mode string, maxWait time.Duration) (*OrderThrottle, error) {
+	if mode != ThrottleReject && mode != ThrottleQueue {
+		return nil, fmt.Errorf("modalità %q non supportata (reject o queue)", mode)
+	}
+	return &OrderThrottle{
+		limits:  limits,
+		mode:    mode,
+		maxWait: maxWait,
+		orders:  make(map[string][]time.Time),
+	}, nil
+}
+
+// Acquire registra un ordine su symbol dell'account se rientra nei limiti
+// In modalità queue attende che si liberi un posto finché l'attesa resta entro il massimo; altrimenti,
+// e sempre in modalità reject, restituisce ErrOrderThrottled e l'ordine non va inviato
+func (t *OrderThrottle) Acquire(ctx context.Context, account, symbol string) error {
+	if t == nil {
+		return nil
+	}
+
+	rules := t.rules(account, symbol)
+	deadline := time.Now().Add(t.maxWait)
+	for {
+		wait, rule := t.tryAcquire(rules, time.Now())
+		if wait == 0 {
+			return nil
+		}
+
+		err := fmt.Errorf("%w: %d ordini in %v su %s", ErrOrderThrottled, rule.limit, rule.window, rule.key)
+		if t.mode == ThrottleReject || time.Now().Add(wait).After(deadline) {
+			log.Printf("🚦 Ordine %s %s scartato: %v", account, symbol, err)
+			return err
+		}
+
+		log.Printf("🚦 Ordine %s %s in coda per %v: limite di %d ordini in %v", account, symbol, wait.Round(time.Second), rule.limit, rule.window)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (attesa annullata: %v)", err, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// tryAcquire registra l'ordine se tutte le regole lo consentono; altrimenti restituisce l'attesa
+// necessaria e la regola superata
+func (t *OrderThrottle) tryAcquire(rules []throttleRule, now time.Time) (time.Duration, throttleRule) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, rule := range rules {
+		recent := t.prune(rule.key, now)
+		var count int
+		for _, sent := range recent {
+			if now.Sub(sent) < rule.window {
+				count++
+			}
+		}
+		if count >= rule.limit {
+			// Il posto si libera quando esce dalla finestra il più vecchio degli ultimi limit ordini
+			oldest := recent[len(recent)-rule.limit]
+			return oldest.Add(rule.window).Sub(now), rule
+		}
+	}
+
+	for _, key := range uniqueKeys(rules) {
+		t.orders[key] = append(t.orders[key], now)
+	}
+	return 0, throttleRule{}
+}
+
+// prune rimuove gli invii più vecchi di un'ora, la finestra più lunga, e restituisce quelli rimasti
+func (t *OrderThrottle) prune(key string, now time.Time) []time.Time {
+	sent := t.orders[key]
+	i := 0
+	for i < len(sent) && now.Sub(sent[i]) >= time.Hour {
+		i++
+	}
+	sent = sent[i:]
+	if len(sent) == 0 {
+		delete(t.orders, key)
+	} else {
+		t.orders[key] = sent
+	}
+	return sent
+}
+
+// rules restituisce i limiti attivi per l'ordine
+func (t *OrderThrottle) rules(account, symbol string) []throttleRule {
+	symbolKey := account + ":" + symbol
+	candidates := []throttleRule{
+		{key: symbolKey, limit: t.limits.SymbolPerMinute, window: time.Minute},
+		{key: symbolKey, limit: t.limits.SymbolPerHour, window: time.Hour},
+		{key: account, limit: t.limits.AccountPerMinute, window: time.Minute},
+		{key: account, limit: t.limits.AccountPerHour, window: time.Hour},
+	}
+
+	var rules []throttleRule
+	for _, rule := range candidates {
+		if rule.limit > 0 {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// uniqueKeys restituisce le chiavi delle regole senza ripetizioni
+// Senza regole l'ordine non viene registrato: nessun limite lo conterebbe
+func uniqueKeys(rules []throttleRule) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		if !seen[rule.key] {
+			seen[rule.key] = true
+			keys = append(keys, rule.key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,107 @@
+package outage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/simrand"
+)
+
+// maxChaosTimeout limita l'attesa di un timeout simulato se la richiesta non ha scadenza
+const maxChaosTimeout = time.Minute
+
+// Chaos configura i guasti iniettati nelle chiamate HTTP verso gli exchange, per provare la modalità degradata
+// Le richieste colpite non partono: l'exchange non riceve nulla, quindi non ci sono effetti sull'account
+type Chaos struct {
+	TimeoutRate float64         // Quota delle richieste che vanno in timeout (0.1 = 10%)
+	ErrorRate   float64         // Quota delle richieste a cui viene risposto 503 Service Unavailable
+	Latency     time.Duration   // Ritardo aggiunto a ogni richiesta colpita
+	Venues      []string        // Venue colpite (bybit, kraken, binance); vuoto per tutte
+	Paths       []string        // Prefissi dei path colpiti (es. /v5/order); vuoto per tutti
+	Rand        *simrand.Source // Sorgente delle estrazioni dei guasti (nil = non riproducibile)
+}
+
+// Validate verifica che le quote siano comprese tra 0 e 1
+func (c *Chaos) Validate() error {
+	if c.TimeoutRate < 0 || c.ErrorRate < 0 || c.TimeoutRate+c.ErrorRate > 1 {
+		return fmt.Errorf("chaos rates must be between 0 and 1 and sum to at most 1 (timeout %.2f, error %.2f)", c.TimeoutRate, c.ErrorRate)
+	}
+	if c.Latency < 0 {
+		return fmt.Errorf("chaos latency must not be negative")
+	}
+	return nil
+}
+
+// timeoutError è l'errore di un timeout simulato; implementa net.Error come i timeout reali
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "chaos: simulated timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// matches verifica se la richiesta verso la venue è tra quelle colpite
+func (c *Chaos) matches(venue string, req *http.Request) bool {
+	if len(c.Venues) > 0 && !containsFold(c.Venues, venue) {
+		return false
+	}
+	if len(c.Paths) == 0 {
+		return true
+	}
+	for _, prefix := range c.Paths {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// inject applica la latenza e, con le probabilità configurate, restituisce un guasto al posto della risposta
+// ok è false se la richiesta deve essere inviata normalmente
+func (c *Chaos) inject(req *http.Request) (resp *http.Response, err error, ok bool) {
+	if c.Latency > 0 {
+		select {
+		case <-time.After(c.Latency):
+		case <-req.Context().Done():
+			return nil, req.Context().Err(), true
+		}
+	}
+
+	roll := c.Rand.Float64()
+	switch {
+	case roll < c.TimeoutRate:
+		// Come un exchange che non risponde: l'attesa finisce alla scadenza della richiesta (timeout del client)
+		ctx, cancel := context.WithTimeout(req.Context(), maxChaosTimeout)
+		defer cancel()
+		<-ctx.Done()
+		return nil, timeoutError{}, true
+	case roll < c.TimeoutRate+c.ErrorRate:
+		body := "<html><body><h1>503 Service Temporarily Unavailable</h1></body></html>"
+		return &http.Response{
+			Status:        "503 Service Unavailable",
+			StatusCode:    http.StatusServiceUnavailable,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": []string{"text/html"}},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// containsFold verifica se values contiene value ignorando maiuscole e minuscole
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package outage
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/errorreport"
+	"cross-exchange-arbitrage/i18n"
+)
+
+// ErrDegraded indica un nuovo ingresso bloccato perché un exchange non è raggiungibile
+var ErrDegraded = errors.New("exchange in degraded mode")
+
+// Azioni della modalità degradata
+const (
+	ActionFreeze = "freeze" // Nessun nuovo ingresso finché l'exchange non torna raggiungibile, più l'alert
+	ActionAlert  = "alert"  // Solo l'alert: le strategie continuano a provare
+)
+
+// Policy definisce cosa fa il bot quando un exchange non risponde
+// In entrambe le azioni le posizioni aperte restano protette dagli stop già registrati sull'exchange
+// e non vengono chiuse dal bot: chiuderle richiederebbe proprio l'exchange irraggiungibile
+type Policy struct {
+	Action     string        // freeze o alert
+	Failures   int           // Errori consecutivi (errori di rete, timeout, risposte 5xx) dopo cui la venue è degradata
+	Recovery   int           // Richieste riuscite consecutive dopo cui la venue torna operativa
+	RecvWindow time.Duration // recvWindow delle richieste firmate in modalità degradata; 0 lascia quello normale
+}
+
+// Validate verifica la coerenza della policy
+func (p Policy) Validate() error {
+	if p.Action != ActionFreeze && p.Action != ActionAlert {
+		return fmt.Errorf("invalid degraded mode action %q: expected freeze or alert", p.Action)
+	}
+	if p.Failures < 1 || p.Recovery < 1 {
+		return fmt.Errorf("degraded mode failures and recovery must be at least 1")
+	}
+	if p.RecvWindow < 0 {
+		return fmt.Errorf("degraded mode recv window must not be negative")
+	}
+	return nil
+}
+
+// venueHealth è lo stato di raggiungibilità di una venue
+type venueHealth struct {
+	degraded  bool
+	failures  int // Errori consecutivi
+	successes int // Richieste riuscite consecutive in modalità degradata
+	lastError string
+	since     time.Time // Inizio della modalità degradata
+}
+
+// Monitor tiene lo stato di raggiungibilità di ogni venue dall'esito delle chiamate HTTP
+// e applica la Policy ai cambi di stato. Un Monitor nil considera tutte le venue raggiungibili
+type Monitor struct {
+	mu       sync.Mutex
+	policy   Policy
+	venues   map[string]*venueHealth
+	reporter *errorreport.Reporter // Invio a Sentry dell'ingresso in modalità degradata; nil se disabilitato
+}
+
+// NewMonitor crea il monitor validando la policy
+func NewMonitor(policy Policy, reporter *errorreport.Reporter) (*Monitor, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &Monitor{policy: policy, venues: make(map[string]*venueHealth), reporter: reporter}, nil
+}
+
+// Record registra l'esito di una chiamata alla venue; err nil indica una risposta valida
+func (m *Monitor) Record(venue string, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	venue = strings.ToLower(venue)
+	health, ok := m.venues[venue]
+	if !ok {
+		health = &venueHealth{}
+		m.venues[venue] = health
+	}
+
+	if err != nil {
+		health.failures++
+		health.successes = 0
+		health.lastError = err.Error()
+		if !health.degraded && health.failures >= m.policy.Failures {
+			health.degraded = true
+			health.since = time.Now()
+			m.alertDegraded(venue, health)
+		}
+		return
+	}
+
+	health.failures = 0
+	if !health.degraded {
+		return
+	}
+	health.successes++
+	if health.successes >= m.policy.Recovery {
+		health.degraded = false
+		health.successes = 0
+		log.Println(i18n.T("alert.exchange_recovered", venue, time.Since(health.since).Round(time.Second)))
+	}
+}
+
+// alertDegraded segnala l'ingresso della venue in modalità degradata
+func (m *Monitor) alertDegraded(venue string, health *venueHealth) {
+	message := i18n.T("alert.exchange_degraded", venue, health.failures, health.lastError, m.policy.Action)
+	log.Println(message)
+	m.reporter.CaptureError(errors.New(message), errorreport.Context{
+		Component:   "outage",
+		Fingerprint: []string{"outage", venue},
+	})
+}
+
+// Degraded restituisce in ordine alfabetico le venue in modalità degradata
+func (m *Monitor) Degraded() []string {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var venues []string
+	for venue, health := range m.venues {
+		if health.degraded {
+			venues = append(venues, venue)
+		}
+	}
+	sort.Strings(venues)
+	return venues
+}
+
+// IsDegraded verifica se la venue è in modalità degradata
+func (m *Monitor) IsDegraded(venue string) bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	health, ok := m.venues[strings.ToLower(venue)]
+	return ok && health.degraded
+}
+
+// CheckEntry restituisce ErrDegraded se la policy è freeze e almeno una venue è in modalità degradata
+func (m *Monitor) CheckEntry() error {
+	if m == nil || m.policy.Action != ActionFreeze {
+		return nil
+	}
+	if venues := m.Degraded(); len(venues) > 0 {
+		return fmt.Errorf("%w: %s not reachable", ErrDegraded, strings.Join(venues, ", "))
+	}
+	return nil
+}
+
+// RecvWindow restituisce la recvWindow (in millisecondi) da firmare per la venue: quella della policy
+// se la venue è in modalità degradata e la policy la allarga, altrimenti normal
+func (m *Monitor) RecvWindow(venue, normal string) string {
+	if m == nil || m.policy.RecvWindow <= 0 || !m.IsDegraded(venue) {
+		return normal
+	}
+	return strconv.FormatInt(m.policy.RecvWindow.Milliseconds(), 10)
+}
@@ -0,0 +1,229 @@
+package outage_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"cross-exchange-arbitrage/outage"
+	"cross-exchange-arbitrage/risk"
+)
+
+const normalRecvWindow = "5000"
+
+// roundTripFunc è l'exchange simulato dietro il Transport: risponde sempre 200
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func exchangeUp(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"retCode":0,"retMsg":"OK"}`)),
+		Request:    req,
+	}, nil
+}
+
+// newClient crea il client della venue con il Transport degli exchange e un timeout breve per i timeout simulati
+func newClient(venue string) *http.Client {
+	return outage.NewClient(venue, &http.Client{
+		Timeout:   50 * time.Millisecond,
+		Transport: roundTripFunc(exchangeUp),
+	})
+}
+
+// install attiva monitor e chaos per il test; a fine test i guasti vengono rimossi
+func install(t *testing.T, monitor *outage.Monitor, chaos *outage.Chaos) {
+	t.Helper()
+	if err := outage.Install(monitor, chaos); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	t.Cleanup(func() { _ = outage.Install(nil, nil) })
+}
+
+// get invia una richiesta alla venue e restituisce lo status, 0 se la richiesta è fallita
+func get(t *testing.T, client *http.Client, path string) int {
+	t.Helper()
+	resp, err := client.Get("https://api.bybit.test" + path)
+	if err != nil {
+		return 0
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestChaosDrivesDegradedMode(t *testing.T) {
+	tests := []struct {
+		name       string
+		chaos      outage.Chaos
+		wantStatus int
+	}{
+		{"server_errors", outage.Chaos{ErrorRate: 1}, http.StatusServiceUnavailable},
+		{"timeouts", outage.Chaos{TimeoutRate: 1}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := outage.Policy{Action: outage.ActionFreeze, Failures: 3, Recovery: 2, RecvWindow: 20 * time.Second}
+			monitor, err := outage.NewMonitor(policy, nil)
+			if err != nil {
+				t.Fatalf("new monitor: %v", err)
+			}
+			manager := risk.NewManager(risk.Limits{})
+			manager.AddEntryGate(monitor.CheckEntry)
+			client := newClient("bybit")
+
+			chaos := tt.chaos
+			install(t, monitor, &chaos)
+			for i := 1; i <= 4; i++ {
+				if status := get(t, client, "/v5/market/time"); status != tt.wantStatus {
+					t.Fatalf("down #%d: status = %d, want %d", i, status, tt.wantStatus)
+				}
+				degraded := i >= policy.Failures
+				if monitor.IsDegraded("bybit") != degraded {
+					t.Fatalf("down #%d: degraded = %t, want %t", i, !degraded, degraded)
+				}
+				if blocked := manager.CheckEntry() != nil; blocked != degraded {
+					t.Errorf("down #%d: entries blocked = %t, want %t", i, blocked, degraded)
+				}
+				wantWindow := normalRecvWindow
+				if degraded {
+					wantWindow = "20000"
+				}
+				if got := monitor.RecvWindow("bybit", normalRecvWindow); got != wantWindow {
+					t.Errorf("down #%d: recv window = %s, want %s", i, got, wantWindow)
+				}
+			}
+			if err := manager.CheckEntry(); !errors.Is(err, outage.ErrDegraded) {
+				t.Errorf("entry error = %v, want ErrDegraded", err)
+			}
+			if got := monitor.Degraded(); len(got) != 1 || got[0] != "bybit" {
+				t.Errorf("degraded venues = %v, want [bybit]", got)
+			}
+
+			install(t, monitor, nil)
+			for i := 1; i <= policy.Recovery; i++ {
+				if status := get(t, client, "/v5/market/time"); status != http.StatusOK {
+					t.Fatalf("up #%d: status = %d, want 200", i, status)
+				}
+				degraded := i < policy.Recovery
+				if monitor.IsDegraded("bybit") != degraded {
+					t.Fatalf("up #%d: degraded = %t, want %t", i, !degraded, degraded)
+				}
+			}
+			if err := manager.CheckEntry(); err != nil {
+				t.Errorf("entries still blocked after recovery: %v", err)
+			}
+			if got := monitor.RecvWindow("bybit", normalRecvWindow); got != normalRecvWindow {
+				t.Errorf("recv window after recovery = %s, want %s", got, normalRecvWindow)
+			}
+		})
+	}
+}
+
+func TestFailureStreakResetBySuccess(t *testing.T) {
+	monitor, err := outage.NewMonitor(outage.Policy{Action: outage.ActionFreeze, Failures: 3, Recovery: 1}, nil)
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+	client := newClient("bybit")
+
+	// Due errori, una risposta valida, due errori: mai tre errori consecutivi
+	for _, step := range []*outage.Chaos{{ErrorRate: 1}, {ErrorRate: 1}, nil, {TimeoutRate: 1}, {ErrorRate: 1}} {
+		install(t, monitor, step)
+		get(t, client, "/v5/market/time")
+		if monitor.IsDegraded("bybit") {
+			t.Fatal("venue degraded without consecutive failures")
+		}
+	}
+}
+
+func TestAlertPolicyDoesNotBlockEntries(t *testing.T) {
+	monitor, err := outage.NewMonitor(outage.Policy{Action: outage.ActionAlert, Failures: 2, Recovery: 1}, nil)
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+	client := newClient("bybit")
+
+	install(t, monitor, &outage.Chaos{ErrorRate: 1})
+	for i := 0; i < 2; i++ {
+		get(t, client, "/v5/order/create")
+	}
+	if !monitor.IsDegraded("bybit") {
+		t.Fatal("venue not degraded after 2 server errors")
+	}
+	if err := monitor.CheckEntry(); err != nil {
+		t.Errorf("alert policy blocked entries: %v", err)
+	}
+	if got := monitor.RecvWindow("bybit", normalRecvWindow); got != normalRecvWindow {
+		t.Errorf("recv window = %s, want %s without a degraded recv window", got, normalRecvWindow)
+	}
+}
+
+func TestChaosTargetsVenuesAndPaths(t *testing.T) {
+	monitor, err := outage.NewMonitor(outage.Policy{Action: outage.ActionFreeze, Failures: 1, Recovery: 1}, nil)
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+	install(t, monitor, &outage.Chaos{ErrorRate: 1, Venues: []string{"bybit"}, Paths: []string{"/v5/order"}})
+
+	if status := get(t, newClient("kraken"), "/v5/order/create"); status != http.StatusOK {
+		t.Errorf("kraken status = %d, want 200", status)
+	}
+	if status := get(t, newClient("bybit"), "/v5/market/time"); status != http.StatusOK {
+		t.Errorf("bybit market status = %d, want 200", status)
+	}
+	if got := monitor.Degraded(); len(got) != 0 {
+		t.Fatalf("degraded venues = %v before any injected failure", got)
+	}
+
+	if status := get(t, newClient("bybit"), "/v5/order/create"); status != http.StatusServiceUnavailable {
+		t.Errorf("bybit order status = %d, want 503", status)
+	}
+	if !monitor.IsDegraded("bybit") || monitor.IsDegraded("kraken") {
+		t.Errorf("degraded venues = %v, want [bybit]", monitor.Degraded())
+	}
+}
+
+func TestCanceledRequestsAreNotFailures(t *testing.T) {
+	monitor, err := outage.NewMonitor(outage.Policy{Action: outage.ActionFreeze, Failures: 1, Recovery: 1}, nil)
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+	install(t, monitor, &outage.Chaos{Latency: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.bybit.test/v5/market/time", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := newClient("bybit").Do(req); err == nil {
+		t.Fatal("canceled request succeeded")
+	}
+	if monitor.IsDegraded("bybit") {
+		t.Error("canceled request counted as a venue failure")
+	}
+}
+
+func TestChaosValidate(t *testing.T) {
+	for _, chaos := range []outage.Chaos{
+		{TimeoutRate: -0.1},
+		{TimeoutRate: 0.6, ErrorRate: 0.5},
+		{Latency: -time.Second},
+	} {
+		if err := outage.Install(nil, &chaos); err == nil {
+			t.Errorf("Install accepted invalid chaos %+v", chaos)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package outage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// installed è la configurazione attiva, letta a ogni richiesta dai Transport degli exchange
+type installed struct {
+	monitor *Monitor
+	chaos   *Chaos // nil se l'iniezione dei guasti è disabilitata
+}
+
+var current atomic.Pointer[installed]
+
+// Install attiva il monitor e, se chaos non è nil, l'iniezione dei guasti sui client creati con NewClient
+// Può essere chiamato anche dopo la creazione dei client: la configurazione è letta a ogni richiesta
+func Install(monitor *Monitor, chaos *Chaos) error {
+	if chaos != nil {
+		if err := chaos.Validate(); err != nil {
+			return err
+		}
+	}
+	current.Store(&installed{monitor: monitor, chaos: chaos})
+	return nil
+}
+
+// Default restituisce il monitor installato; nil se Install non è stato chiamato
+func Default() *Monitor {
+	if cfg := current.Load(); cfg != nil {
+		return cfg.monitor
+	}
+	return nil
+}
+
+// RecvWindow restituisce la recvWindow da firmare per la venue secondo il monitor installato
+func RecvWindow(venue, normal string) string {
+	return Default().RecvWindow(venue, normal)
+}
+
+// Transport inietta i guasti configurati e registra l'esito di ogni richiesta nel monitor della venue
+type Transport struct {
+	Venue string
+	Base  http.RoundTripper
+}
+
+// NewClient aggiunge al client il Transport della venue
+func NewClient(venue string, client *http.Client) *http.Client {
+	client.Transport = &Transport{Venue: venue, Base: client.Transport}
+	return client
+}
+
+// RoundTrip esegue la richiesta, o il guasto simulato, e ne registra l'esito
+// Le richieste annullate dal chiamante (es. arresto del bot) non contano come errori della venue
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cfg := current.Load()
+	if cfg == nil {
+		return t.base().RoundTrip(req)
+	}
+
+	var resp *http.Response
+	var err error
+	injected := false
+	if cfg.chaos != nil && cfg.chaos.matches(t.Venue, req) {
+		resp, err, injected = cfg.chaos.inject(req)
+	}
+	if !injected {
+		resp, err = t.base().RoundTrip(req)
+	}
+
+	switch {
+	case err != nil && errors.Is(err, context.Canceled):
+	case err != nil:
+		cfg.monitor.Record(t.Venue, err)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		cfg.monitor.Record(t.Venue, fmt.Errorf("HTTP %d", resp.StatusCode))
+	default:
+		cfg.monitor.Record(t.Venue, nil)
+	}
+	return resp, err
+}
+
+// base restituisce il RoundTripper sottostante
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
@@ -0,0 +1,94 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// HedgeParams configura la copertura del portafoglio con uno short su un perpetual indice (es. BTCUSDT)
+type HedgeParams struct {
+	Symbol    string  // Perpetual usato come copertura
+	Ratio     float64 // Nozionale dello short rispetto all'esposizione long sulle altcoin (0.5 = 50%)
+	Tolerance float64 // Scostamento dalla copertura obiettivo entro cui non si ribilancia (0.2 = 20%)
+	QtyStep   float64 // Passo della quantità del perpetual (es. 0.001 BTC)
+}
+
+// Exposure è l'esposizione long aggregata sulle altcoin
+type Exposure struct {
+	Notional float64  // Nozionale complessivo dei long in USDT
+	Symbols  []string // Simboli con un long aperto, in ordine alfabetico
+}
+
+// HedgeOverlay calcola la copertura del portafoglio: durante i segnali di risk-off apre uno short sul
+// perpetual indice proporzionale ai long aperti sulle altcoin, fuori dal risk-off lo chiude.
+// Le altcoin seguono l'indice con beta elevato, quindi lo short ne riduce il drawdown senza chiudere le posizioni
+type HedgeOverlay struct {
+	params HedgeParams
+	base   string // Base coin del simbolo di copertura (es. BTC), esclusa dall'esposizione
+}
+
+// NewHedgeOverlay crea la copertura validando i parametri
+func NewHedgeOverlay(params HedgeParams) (*HedgeOverlay, error) {
+	params.Symbol = strings.ToUpper(strings.TrimSpace(params.Symbol))
+	if params.Symbol == "" {
+		return nil, fmt.Errorf("il simbolo di copertura è obbligatorio")
+	}
+	if params.Ratio <= 0 || params.Ratio > 1 {
+		return nil, fmt.Errorf("il rapporto di copertura deve essere compreso tra 0 (escluso) e 1 (%.2f)", params.Ratio)
+	}
+	if params.Tolerance < 0 || params.Tolerance >= 1 {
+		return nil, fmt.Errorf("la tolleranza della copertura deve essere compresa tra 0 e 1 (%.2f)", params.Tolerance)
+	}
+	if params.QtyStep <= 0 {
+		return nil, fmt.Errorf("il passo della quantità di copertura deve essere positivo (%g)", params.QtyStep)
+	}
+	base := strings.TrimSuffix(strings.TrimSuffix(params.Symbol, "USDT"), "USD")
+	return &HedgeOverlay{params: params, base: base}, nil
+}
+
+// Symbol restituisce il perpetual usato come copertura
+func (h *HedgeOverlay) Symbol() string {
+	return h.params.Symbol
+}
+
+// Exposure somma il nozionale dei long aperti sulle altcoin; le posizioni sulla coin di copertura sono escluse
+func (h *HedgeOverlay) Exposure(positions []models.Position) Exposure {
+	var exposure Exposure
+	for _, position := range positions {
+		symbol := strings.ToUpper(position.Symbol)
+		if !position.Parsed.IsLong() || !position.Parsed.IsActive() || strings.HasPrefix(symbol, h.base) {
+			continue
+		}
+		exposure.Notional += position.Parsed.Size * position.Parsed.Price()
+		exposure.Symbols = append(exposure.Symbols, symbol)
+	}
+	sort.Strings(exposure.Symbols)
+	return exposure
+}
+
+// Target restituisce la quantità short obiettivo sul simbolo di copertura, arrotondata per difetto al passo;
+// 0 fuori dal risk-off
+func (h *HedgeOverlay) Target(exposure Exposure, hedgePrice float64, riskOff bool) float64 {
+	if !riskOff || hedgePrice <= 0 {
+		return 0
+	}
+	return models.FloorToStep(exposure.Notional*h.params.Ratio/hedgePrice, h.params.QtyStep)
+}
+
+// Adjustment restituisce la variazione dello short per passare da current a target, arrotondata al passo:
+// positiva per aumentarlo, negativa per ridurlo. ok è false se lo short è già entro la tolleranza dell'obiettivo.
+// Con obiettivo 0 lo short viene chiuso per intero
+func (h *HedgeOverlay) Adjustment(current, target float64) (delta float64, ok bool) {
+	if target == 0 {
+		return -current, current > 0
+	}
+	delta = models.RoundToStep(target-current, h.params.QtyStep)
+	if delta == 0 || math.Abs(delta) <= h.params.Tolerance*target {
+		return 0, false
+	}
+	return delta, true
+}
@@ -0,0 +1,123 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cross-exchange-arbitrage/exchange"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/strategy"
+)
+
+// Segnali di risk-off disponibili
+const (
+	SignalFearGreed = "fear_greed" // Fear & Greed Index pari o sotto la soglia
+	SignalBTCTrend  = "btc_trend"  // Simbolo di copertura sotto la sua EMA sulle candele orarie
+)
+
+// RiskOffParams configura i segnali di risk-off
+type RiskOffParams struct {
+	Signals      []string      // Segnali attivi: ne basta uno per il risk-off
+	Symbol       string        // Simbolo su cui valutare il trend (es. BTCUSDT)
+	FearGreedMax float64       // Valore del Fear & Greed Index pari o sotto cui si è in risk-off
+	FearGreedAge time.Duration // Età massima della rilevazione del Fear & Greed Index
+	TrendPeriod  int           // Periodo dell'EMA del trend sulle candele orarie
+}
+
+// RiskOffDetector valuta i segnali di risk-off. Un segnale senza dati recenti non scatta:
+// la copertura si apre solo su un segnale effettivo
+type RiskOffDetector struct {
+	params  RiskOffParams
+	points  repositories.DataPointRepository
+	candles exchange.Exchange
+	trend   *strategy.TrendFilter // nil se il segnale btc_trend non è attivo
+}
+
+// NewRiskOffDetector crea il detector validando i segnali configurati
+func NewRiskOffDetector(params RiskOffParams, points repositories.DataPointRepository, candles exchange.Exchange) (*RiskOffDetector, error) {
+	if len(params.Signals) == 0 {
+		return nil, fmt.Errorf("serve almeno un segnale di risk-off")
+	}
+
+	detector := &RiskOffDetector{params: params, points: points, candles: candles}
+	for _, signal := range params.Signals {
+		switch signal {
+		case SignalFearGreed:
+			if params.FearGreedAge <= 0 {
+				return nil, fmt.Errorf("l'età massima del Fear & Greed Index deve essere positiva")
+			}
+		case SignalBTCTrend:
+			trend, err := strategy.NewTrendFilter(strategy.TrendFilterParams{Period: params.TrendPeriod})
+			if err != nil {
+				return nil, err
+			}
+			detector.trend = trend
+		default:
+			return nil, fmt.Errorf("segnale di risk-off %q non valido (%s o %s)", signal, SignalFearGreed, SignalBTCTrend)
+		}
+	}
+	return detector, nil
+}
+
+// RiskOff restituisce true se almeno un segnale è attivo, con i motivi dei segnali scattati
+// Gli errori di lettura dei dati sono restituiti solo se nessun segnale ha potuto confermare il risk-off
+func (d *RiskOffDetector) RiskOff(ctx context.Context) (bool, []string, error) {
+	var reasons []string
+	var lastErr error
+	for _, signal := range d.params.Signals {
+		var reason string
+		var err error
+		switch signal {
+		case SignalFearGreed:
+			reason, err = d.fearGreed(ctx)
+		case SignalBTCTrend:
+			reason, err = d.btcTrend(ctx)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	if len(reasons) > 0 {
+		return true, reasons, nil
+	}
+	return false, nil, lastErr
+}
+
+// fearGreed restituisce il motivo del risk-off se l'ultima rilevazione recente è pari o sotto la soglia
+func (d *RiskOffDetector) fearGreed(ctx context.Context) (string, error) {
+	point, err := d.points.GetLatest(ctx, models.DataSourceFearGreed)
+	if err != nil {
+		return "", fmt.Errorf("Fear & Greed non disponibile: %w", err)
+	}
+	if time.Since(point.ObservedAt) > d.params.FearGreedAge {
+		return "", fmt.Errorf("Fear & Greed: ultimo valore del %s", point.ObservedAt.Format("2006-01-02"))
+	}
+	if point.Value > d.params.FearGreedMax {
+		return "", nil
+	}
+	return fmt.Sprintf("Fear & Greed %.0f (%s)", point.Value, point.Label), nil
+}
+
+// btcTrend restituisce il motivo del risk-off se il simbolo è in trend ribassista sulle candele orarie chiuse
+func (d *RiskOffDetector) btcTrend(ctx context.Context) (string, error) {
+	// Una candela in più perché l'ultima restituita è quella in corso
+	resp, err := d.candles.FetchLastCandles(ctx, d.params.Symbol, models.DerivativesMarket, models.Timeframe1h, d.trend.MinCandles()+1)
+	if err != nil {
+		return "", fmt.Errorf("errore candele %s: %w", d.params.Symbol, err)
+	}
+	candles := resp.Closed()
+	if len(candles) < d.trend.MinCandles() {
+		return "", fmt.Errorf("%d candele chiuse di %s, ne servono %d per l'EMA%d", len(candles), d.params.Symbol, d.trend.MinCandles(), d.params.TrendPeriod)
+	}
+	// Il risk-off scatta quando il filtro ammetterebbe uno short: prezzo sotto l'EMA
+	if err := d.trend.Check(models.OrderSideSell, candles); err != nil {
+		return "", nil
+	}
+	return fmt.Sprintf("%s sotto l'EMA%d oraria", d.params.Symbol, d.params.TrendPeriod), nil
+}
@@ -0,0 +1,115 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+var (
+	// ErrAPIKeyReadOnly indica che la API key non può piazzare ordini
+	ErrAPIKeyReadOnly = errors.New("API key di sola lettura")
+
+	// ErrMissingPermission indica che alla API key manca un permesso richiesto
+	ErrMissingPermission = errors.New("permesso mancante sulla API key")
+
+	// ErrAPIKeyExpired indica che la API key è scaduta
+	ErrAPIKeyExpired = errors.New("API key scaduta")
+
+	// ErrAPIKeyNotReadOnly indica che una key destinata alle sole letture può anche operare
+	ErrAPIKeyNotReadOnly = errors.New("API key non di sola lettura")
+)
+
+// APIKeyInspector recupera le informazioni della API key configurata
+type APIKeyInspector interface {
+	GetAPIKeyInfo(ctx context.Context) (*models.APIKeyInfo, error)
+}
+
+// Permission identifica un permesso Bybit all'interno del suo gruppo
+type Permission struct {
+	Group string // Es. "ContractTrade"
+	Name  string // Es. "Order"
+}
+
+// TradingPermissions sono i permessi necessari al bot per operare sui perpetual lineari
+var TradingPermissions = []Permission{
+	{Group: "ContractTrade", Name: "Order"},    // Piazzamento e cancellazione ordini
+	{Group: "ContractTrade", Name: "Position"}, // Stop loss e take profit della posizione
+}
+
+// APIKeyReport riassume l'esito del controllo della API key
+type APIKeyReport struct {
+	Info      *models.APIKeyInfo
+	ExpiresAt *time.Time // nil se la key non scade
+	DaysLeft  int
+	Warnings  []string
+}
+
+// CheckAPIKey verifica che la API key abbia i permessi richiesti, non sia di sola lettura e non sia scaduta
+// Se la scadenza è entro warnDays giorni viene aggiunto un avviso al report
+func CheckAPIKey(ctx context.Context, inspector APIKeyInspector, required []Permission, warnDays int, now time.Time) (*APIKeyReport, error) {
+	info, err := inspector.GetAPIKeyInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("impossibile verificare la API key: %w", err)
+	}
+
+	report := &APIKeyReport{Info: info}
+
+	if info.IsReadOnly() {
+		return report, fmt.Errorf("%w: abilitare la modalità lettura/scrittura su Bybit", ErrAPIKeyReadOnly)
+	}
+
+	var missing []string
+	for _, permission := range required {
+		if !info.HasPermission(permission.Group, permission.Name) {
+			missing = append(missing, permission.Group+"."+permission.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return report, fmt.Errorf("%w: %s", ErrMissingPermission, strings.Join(missing, ", "))
+	}
+
+	return report, checkExpiry(report, warnDays, now)
+}
+
+// CheckReadOnlyAPIKey verifica che la key usata da analytics e reportistica sia di sola lettura,
+// così che la compromissione dell'host di reportistica non permetta di operare sull'account
+func CheckReadOnlyAPIKey(ctx context.Context, inspector APIKeyInspector, warnDays int, now time.Time) (*APIKeyReport, error) {
+	info, err := inspector.GetAPIKeyInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("impossibile verificare la API key di sola lettura: %w", err)
+	}
+
+	report := &APIKeyReport{Info: info}
+
+	if !info.IsReadOnly() {
+		return report, fmt.Errorf("%w: impostare la modalità sola lettura su Bybit", ErrAPIKeyNotReadOnly)
+	}
+
+	return report, checkExpiry(report, warnDays, now)
+}
+
+// checkExpiry completa il report con la scadenza della key
+// Restituisce un errore se la key è scaduta e aggiunge un avviso se scade entro warnDays giorni
+func checkExpiry(report *APIKeyReport, warnDays int, now time.Time) error {
+	expiresAt, ok := report.Info.ExpiresAt()
+	if !ok {
+		return nil
+	}
+
+	report.ExpiresAt = &expiresAt
+	report.DaysLeft = int(expiresAt.Sub(now).Hours() / 24)
+	if !expiresAt.After(now) {
+		return fmt.Errorf("%w il %s: rinnovarla su Bybit", ErrAPIKeyExpired, expiresAt.Format("2006-01-02"))
+	}
+	if warnDays > 0 && report.DaysLeft < warnDays {
+		report.Warnings = append(report.Warnings,
+			fmt.Sprintf("la API key scade il %s (tra %d giorni)", expiresAt.Format("2006-01-02"), report.DaysLeft))
+	}
+
+	return nil
+}
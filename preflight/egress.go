@@ -0,0 +1,105 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrEgressIPMismatch indica che l'IP pubblico del bot non è tra quelli autorizzati
+var ErrEgressIPMismatch = errors.New("IP pubblico non autorizzato")
+
+// DefaultIPCheckURL è il servizio usato per rilevare l'IP pubblico se non configurato
+const DefaultIPCheckURL = "https://api.ipify.org"
+
+// IPDetector rileva l'IP pubblico con cui il bot esce verso internet
+type IPDetector interface {
+	PublicIP(ctx context.Context) (net.IP, error)
+}
+
+// HTTPIPDetector rileva l'IP pubblico interrogando un servizio che restituisce l'IP in chiaro
+type HTTPIPDetector struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewHTTPIPDetector crea un IPDetector che interroga url (DefaultIPCheckURL se vuoto)
+func NewHTTPIPDetector(url string) *HTTPIPDetector {
+	if url == "" {
+		url = DefaultIPCheckURL
+	}
+	return &HTTPIPDetector{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// PublicIP implementa IPDetector
+func (d *HTTPIPDetector) PublicIP(ctx context.Context) (net.IP, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", d.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("errore nella creazione della richiesta HTTP: %w", err)
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("errore nel rilevamento dell'IP pubblico: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("errore nel rilevamento dell'IP pubblico: status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return nil, fmt.Errorf("errore nella lettura della risposta: %w", err)
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return nil, fmt.Errorf("risposta non valida dal servizio IP: %q", strings.TrimSpace(string(body)))
+	}
+	return ip, nil
+}
+
+// CheckEgressIP rileva l'IP pubblico e verifica che rientri tra quelli autorizzati
+// Restituisce l'IP rilevato, anche in caso di mancata corrispondenza
+func CheckEgressIP(ctx context.Context, detector IPDetector, allowed []string) (net.IP, error) {
+	ip, err := detector.PublicIP(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ip, MatchEgressIP(ip, allowed)
+}
+
+// MatchEgressIP verifica che ip rientri tra quelli autorizzati
+// allowed accetta IP singoli e reti CIDR; "*" autorizza qualsiasi IP
+func MatchEgressIP(ip net.IP, allowed []string) error {
+	for _, entry := range allowed {
+		if ipMatches(ip, strings.TrimSpace(entry)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s non è tra %s", ErrEgressIPMismatch, ip, strings.Join(allowed, ", "))
+}
+
+// ipMatches verifica se ip corrisponde a un IP o a una rete CIDR
+func ipMatches(ip net.IP, entry string) bool {
+	if entry == "*" {
+		return true
+	}
+	if strings.Contains(entry, "/") {
+		_, network, err := net.ParseCIDR(entry)
+		return err == nil && network.Contains(ip)
+	}
+	allowed := net.ParseIP(entry)
+	return allowed != nil && allowed.Equal(ip)
+}
@@ -0,0 +1,134 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// ErrOrderRejected indica che l'ordine verrebbe rifiutato dall'exchange
+var ErrOrderRejected = errors.New("ordine destinato al rifiuto")
+
+// Motivi di fallimento del controllo di un ordine
+const (
+	ReasonQtyTooSmall        = "qty_too_small"       // Quantità, dopo l'arrotondamento al passo, sotto il minimo del simbolo
+	ReasonQtyTooLarge        = "qty_too_large"       // Quantità oltre il massimo di un ordine a mercato
+	ReasonNotionalTooSmall   = "notional_too_small"  // Controvalore sotto il minimo del simbolo
+	ReasonInsufficientMargin = "insufficient_margin" // Margine iniziale e commissioni oltre il saldo disponibile
+)
+
+// MarginReader recupera le regole del simbolo e il margine disponibile dell'account
+type MarginReader interface {
+	GetInstrumentInfo(ctx context.Context, symbol string) (*models.InstrumentInfo, error)
+	GetAvailableMargin(ctx context.Context) (float64, error)
+	GetLeverage(ctx context.Context, symbol string) (float64, error)
+}
+
+// OrderRequest è l'ordine da controllare prima dell'invio
+type OrderRequest struct {
+	Symbol   string
+	Side     models.OrderSide
+	Price    float64 // Prezzo di ingresso previsto
+	Quantity float64
+	TakerFee float64 // Commissione taker (es. 0.00055); l'ordine entra a mercato
+}
+
+// Failure è un singolo motivo per cui l'ordine verrebbe rifiutato
+type Failure struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// OrderRejectedError riporta tutti i motivi di rifiuto previsti per un ordine
+type OrderRejectedError struct {
+	Symbol   string
+	Failures []Failure
+}
+
+func (e *OrderRejectedError) Error() string {
+	messages := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		messages[i] = failure.Reason + ": " + failure.Message
+	}
+	return fmt.Sprintf("%v (%s): %s", ErrOrderRejected, e.Symbol, strings.Join(messages, "; "))
+}
+
+func (e *OrderRejectedError) Is(target error) bool {
+	return target == ErrOrderRejected
+}
+
+// OrderReport riassume il calcolo del controllo di un ordine
+type OrderReport struct {
+	Quantity  float64 // Quantità arrotondata al passo del simbolo, quella effettivamente inviabile
+	Notional  float64
+	Leverage  float64
+	Cost      float64 // Margine iniziale più commissioni stimate di apertura e chiusura
+	Available float64 // Margine disponibile sull'account
+}
+
+// CheckOrder prevede se l'exchange rifiuterebbe l'ordine per quantità fuori dai limiti o margine insufficiente
+// Restituisce un *OrderRejectedError con tutti i motivi trovati, così l'ordine non viene mai inviato
+// Il costo segue la formula di Bybit: margine iniziale (controvalore / leva) più la commissione di apertura
+// e quella di chiusura calcolata al prezzo di fallimento della posizione
+func CheckOrder(ctx context.Context, reader MarginReader, order OrderRequest) (*OrderReport, error) {
+	info, err := reader.GetInstrumentInfo(ctx, order.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("impossibile recuperare le regole di %s: %w", order.Symbol, err)
+	}
+	leverage, err := reader.GetLeverage(ctx, order.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("impossibile recuperare la leva di %s: %w", order.Symbol, err)
+	}
+	available, err := reader.GetAvailableMargin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("impossibile recuperare il margine disponibile: %w", err)
+	}
+
+	report := &OrderReport{
+		Quantity:  models.FloorToStep(order.Quantity, info.QtyStep),
+		Leverage:  leverage,
+		Available: available,
+	}
+	report.Notional = report.Quantity * order.Price
+
+	// Prezzo a cui la posizione perderebbe tutto il margine iniziale
+	bankruptcy := order.Price * (1 - 1/leverage)
+	if order.Side == models.OrderSideSell {
+		bankruptcy = order.Price * (1 + 1/leverage)
+	}
+	report.Cost = report.Notional/leverage + report.Notional*order.TakerFee + report.Quantity*bankruptcy*order.TakerFee
+
+	var failures []Failure
+	if report.Quantity < info.MinOrderQty || report.Quantity <= 0 {
+		failures = append(failures, Failure{
+			Reason:  ReasonQtyTooSmall,
+			Message: fmt.Sprintf("quantità %g (da %g) sotto il minimo %g", report.Quantity, order.Quantity, info.MinOrderQty),
+		})
+	}
+	if info.MaxMarketQty > 0 && report.Quantity > info.MaxMarketQty {
+		failures = append(failures, Failure{
+			Reason:  ReasonQtyTooLarge,
+			Message: fmt.Sprintf("quantità %g oltre il massimo %g di un ordine a mercato", report.Quantity, info.MaxMarketQty),
+		})
+	}
+	if info.MinNotional > 0 && report.Notional < info.MinNotional {
+		failures = append(failures, Failure{
+			Reason:  ReasonNotionalTooSmall,
+			Message: fmt.Sprintf("controvalore %.2f USDT sotto il minimo %.2f", report.Notional, info.MinNotional),
+		})
+	}
+	if report.Cost > available {
+		failures = append(failures, Failure{
+			Reason:  ReasonInsufficientMargin,
+			Message: fmt.Sprintf("costo %.2f USDT (leva %gx) oltre il margine disponibile %.2f", report.Cost, leverage, available),
+		})
+	}
+
+	if len(failures) > 0 {
+		return report, &OrderRejectedError{Symbol: order.Symbol, Failures: failures}
+	}
+	return report, nil
+}
@@ -0,0 +1,149 @@
+package reporting
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// BenchmarkComparison confronta la curva di equity della strategia con il buy-and-hold del simbolo
+type BenchmarkComparison struct {
+	Symbol              string        `json:"symbol"`
+	StartDate           time.Time     `json:"start_date"`
+	EndDate             time.Time     `json:"end_date"`
+	StrategyReturn      float64       `json:"strategy_return"`       // Rendimento totale della strategia in percentuale
+	BuyHoldReturn       float64       `json:"buy_hold_return"`       // Rendimento totale del buy-and-hold in percentuale
+	ExcessReturn        float64       `json:"excess_return"`         // StrategyReturn - BuyHoldReturn
+	StrategyMaxDrawdown float64       `json:"strategy_max_drawdown"` // Massimo drawdown della strategia in percentuale
+	BuyHoldMaxDrawdown  float64       `json:"buy_hold_max_drawdown"` // Massimo drawdown del buy-and-hold in percentuale
+	Beta                float64       `json:"beta"`                  // Sensibilità della strategia ai movimenti del simbolo
+	Alpha               float64       `json:"alpha"`                 // Alpha di Jensen annualizzato in percentuale
+	Correlation         float64       `json:"correlation"`           // Correlazione dei rendimenti per periodo
+	TrackingError       float64       `json:"tracking_error"`        // Deviazione standard annualizzata dei rendimenti attivi
+	InformationRatio    float64       `json:"information_ratio"`     // Rendimento attivo annualizzato / tracking error
+	BuyHoldCurve        []EquityPoint `json:"buy_hold_curve"`        // Curva buy-and-hold scalata sull'equity iniziale
+}
+
+// CalculateBenchmark confronta la curva di equity con il buy-and-hold del simbolo sullo stesso periodo
+// Le candele vengono allineate ai timestamp della curva usando l'ultima chiusura disponibile
+func CalculateBenchmark(symbol string, points []EquityPoint, candles []models.Candle) (*BenchmarkComparison, error) {
+	if len(points) < 2 {
+		return nil, fmt.Errorf("at least 2 equity points are required, got %d", len(points))
+	}
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("no candles available for %s", symbol)
+	}
+
+	sorted := make([]models.Candle, len(candles))
+	copy(sorted, candles)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	// Allinea i prezzi ai punti della curva di equity
+	prices := make([]float64, len(points))
+	for i, point := range points {
+		price, ok := closeAt(sorted, point.Timestamp)
+		if !ok {
+			return nil, fmt.Errorf("no price available for %s at %s", symbol, point.Timestamp.Format(time.RFC3339))
+		}
+		prices[i] = price
+	}
+
+	startEquity := points[0].Equity
+	if startEquity <= 0 || prices[0] <= 0 {
+		return nil, fmt.Errorf("starting equity and price must be positive")
+	}
+
+	// La strategia è confrontata al netto di depositi e prelievi
+	points = tradingCurve(points)
+
+	// Curva buy-and-hold con lo stesso capitale iniziale
+	buyHold := make([]EquityPoint, len(points))
+	for i, point := range points {
+		buyHold[i] = EquityPoint{
+			Timestamp: point.Timestamp,
+			Equity:    startEquity * prices[i] / prices[0],
+		}
+	}
+
+	comparison := &BenchmarkComparison{
+		Symbol:         symbol,
+		StartDate:      points[0].Timestamp,
+		EndDate:        points[len(points)-1].Timestamp,
+		StrategyReturn: (points[len(points)-1].Equity/startEquity - 1) * 100,
+		BuyHoldReturn:  (prices[len(prices)-1]/prices[0] - 1) * 100,
+		BuyHoldCurve:   buyHold,
+	}
+	comparison.ExcessReturn = comparison.StrategyReturn - comparison.BuyHoldReturn
+	comparison.StrategyMaxDrawdown, _ = maxDrawdown(points)
+	comparison.BuyHoldMaxDrawdown, _ = maxDrawdown(buyHold)
+
+	// Statistiche sui rendimenti per periodo
+	strategyReturns := periodReturns(points)
+	benchmarkReturns := periodReturns(buyHold)
+	if len(strategyReturns) != len(benchmarkReturns) || len(strategyReturns) < 2 {
+		return comparison, nil
+	}
+
+	elapsed := comparison.EndDate.Sub(comparison.StartDate)
+	periodsPerYear := (daysPerYear * 24 * float64(time.Hour)) / float64(elapsed/time.Duration(len(points)-1))
+
+	benchmarkVariance := variance(benchmarkReturns)
+	if benchmarkVariance > 0 {
+		comparison.Beta = covariance(strategyReturns, benchmarkReturns) / benchmarkVariance
+	}
+
+	strategyStd := stdDev(strategyReturns)
+	benchmarkStd := stdDev(benchmarkReturns)
+	if strategyStd > 0 && benchmarkStd > 0 {
+		comparison.Correlation = covariance(strategyReturns, benchmarkReturns) / (strategyStd * benchmarkStd)
+	}
+
+	comparison.Alpha = (mean(strategyReturns) - comparison.Beta*mean(benchmarkReturns)) * periodsPerYear * 100
+
+	active := make([]float64, len(strategyReturns))
+	for i := range strategyReturns {
+		active[i] = strategyReturns[i] - benchmarkReturns[i]
+	}
+	if activeStd := stdDev(active); activeStd > 0 {
+		comparison.TrackingError = activeStd * math.Sqrt(periodsPerYear) * 100
+		comparison.InformationRatio = mean(active) / activeStd * math.Sqrt(periodsPerYear)
+	}
+
+	return comparison, nil
+}
+
+// closeAt restituisce la chiusura dell'ultima candela aperta prima (o esattamente a) t
+func closeAt(candles []models.Candle, t time.Time) (float64, bool) {
+	idx := sort.Search(len(candles), func(i int) bool {
+		return candles[i].Timestamp.After(t)
+	})
+	if idx == 0 {
+		return 0, false
+	}
+	return candles[idx-1].Close, true
+}
+
+// variance calcola la varianza campionaria
+func variance(values []float64) float64 {
+	std := stdDev(values)
+	return std * std
+}
+
+// covariance calcola la covarianza campionaria tra due serie della stessa lunghezza
+func covariance(a, b []float64) float64 {
+	if len(a) < 2 || len(a) != len(b) {
+		return 0
+	}
+	meanA := mean(a)
+	meanB := mean(b)
+	sum := 0.0
+	for i := range a {
+		sum += (a[i] - meanA) * (b[i] - meanB)
+	}
+	return sum / float64(len(a)-1)
+}
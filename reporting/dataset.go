@@ -0,0 +1,124 @@
+package reporting
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/scoring"
+)
+
+// DatasetRow è un esempio etichettato per l'addestramento del modello di scoring
+// Le feature sono calcolate con scoring.BreakoutFeatures sulla candela del segnale, come nel worker live
+type DatasetRow struct {
+	OrderID       string
+	Symbol        string
+	Side          models.OrderSide
+	SignalTime    time.Time // Apertura della candela del segnale
+	Features      scoring.Features
+	Label         int // 1 = Profit, 0 = Loss
+	PnL           float64
+	PnLPercentage float64
+}
+
+// datasetFeatureNames restituisce i nomi delle feature comuni a tutte le righe
+// Righe con feature diverse indicano un dataset incoerente e vengono rifiutate
+func datasetFeatureNames(rows []DatasetRow) ([]string, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	names := rows[0].Features.Names
+	for _, row := range rows[1:] {
+		if len(row.Features.Names) != len(names) {
+			return nil, fmt.Errorf("order %s has %d features, expected %d", row.OrderID, len(row.Features.Names), len(names))
+		}
+		for i, name := range row.Features.Names {
+			if name != names[i] {
+				return nil, fmt.Errorf("order %s has feature %s at position %d, expected %s", row.OrderID, name, i, names[i])
+			}
+		}
+	}
+	return names, nil
+}
+
+// WriteDatasetCSV scrive il dataset in formato CSV: identificativi, una colonna per feature, etichetta ed esito
+func WriteDatasetCSV(w io.Writer, rows []DatasetRow) error {
+	names, err := datasetFeatureNames(rows)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	header := append([]string{"order_id", "symbol", "side", "signal_time"}, names...)
+	header = append(header, "label", "pnl", "pnl_percentage")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := []string{row.OrderID, row.Symbol, string(row.Side), row.SignalTime.UTC().Format(time.RFC3339)}
+		for _, value := range row.Features.Values {
+			record = append(record, formatFloat(value))
+		}
+		record = append(record, strconv.Itoa(row.Label), formatFloat(row.PnL), formatFloat(row.PnLPercentage))
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteDatasetParquet scrive il dataset in formato Parquet con le stesse colonne dell'export CSV
+func WriteDatasetParquet(w io.Writer, rows []DatasetRow) error {
+	names, err := datasetFeatureNames(rows)
+	if err != nil {
+		return err
+	}
+
+	orderIDs := make([]string, len(rows))
+	symbols := make([]string, len(rows))
+	sides := make([]string, len(rows))
+	signalTimes := make([]int64, len(rows))
+	labels := make([]int64, len(rows))
+	pnls := make([]float64, len(rows))
+	pnlPercentages := make([]float64, len(rows))
+	features := make([][]float64, len(names))
+	for i := range features {
+		features[i] = make([]float64, len(rows))
+	}
+
+	for i, row := range rows {
+		orderIDs[i] = row.OrderID
+		symbols[i] = row.Symbol
+		sides[i] = string(row.Side)
+		signalTimes[i] = row.SignalTime.UnixMilli()
+		labels[i] = int64(row.Label)
+		pnls[i] = row.PnL
+		pnlPercentages[i] = row.PnLPercentage
+		for j, value := range row.Features.Values {
+			features[j][i] = value
+		}
+	}
+
+	columns := []*parquetColumn{
+		stringColumn("order_id", orderIDs),
+		stringColumn("symbol", symbols),
+		stringColumn("side", sides),
+		timestampColumn("signal_time", signalTimes),
+	}
+	for i, name := range names {
+		columns = append(columns, doubleColumn(name, features[i]))
+	}
+	columns = append(columns,
+		int64Column("label", labels),
+		doubleColumn("pnl", pnls),
+		doubleColumn("pnl_percentage", pnlPercentages),
+	)
+
+	return writeParquet(w, len(rows), columns)
+}
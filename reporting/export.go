@@ -0,0 +1,81 @@
+package reporting
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// orderCSVHeader è l'intestazione dell'export CSV degli ordini
+var orderCSVHeader = []string{
+	"order_id", "symbol", "side", "order_price", "quantity", "take_profit_price", "stop_loss_price",
+	"status", "result", "pnl", "pnl_percentage", "created_at", "updated_at", "tags", "notes",
+}
+
+// WriteOrdersCSV scrive gli ordini in formato CSV includendo tag e note di journaling
+// tagsByOrder contiene i tag raggruppati per OrderID (può essere nil)
+func WriteOrdersCSV(w io.Writer, orders []*models.Order, tagsByOrder map[string][]*models.OrderTag) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(orderCSVHeader); err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		status := ""
+		if order.OrderStatus != nil {
+			status = order.OrderStatus.StatusName
+		}
+
+		var tags, notes []string
+		for _, tag := range tagsByOrder[order.OrderID] {
+			if tag.Tag != "" {
+				tags = append(tags, tag.Tag)
+			}
+			if tag.Note != "" {
+				notes = append(notes, tag.Note)
+			}
+		}
+
+		record := []string{
+			order.OrderID,
+			order.Symbol,
+			string(order.Side),
+			formatFloat(order.OrderPrice),
+			formatFloat(order.Quantity),
+			formatOptionalFloat(order.TakeProfitPrice),
+			formatOptionalFloat(order.StopLossPrice),
+			status,
+			string(order.Result),
+			formatFloat(order.PnL),
+			formatFloat(order.PnLPercentage),
+			order.CreatedAt.UTC().Format(time.RFC3339),
+			order.UpdatedAt.UTC().Format(time.RFC3339),
+			strings.Join(tags, ";"),
+			strings.Join(notes, " | "),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatFloat formatta un float senza perdita di precisione
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// formatOptionalFloat formatta un float opzionale (stringa vuota se nil)
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return formatFloat(*value)
+}
@@ -0,0 +1,307 @@
+package reporting
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Writer Parquet minimale per gli export dei dataset: un solo row group, colonne REQUIRED,
+// encoding PLAIN senza compressione. I metadati sono serializzati con il protocollo Thrift compact
+
+// parquetMagic delimita l'inizio e la fine di un file Parquet
+const parquetMagic = "PAR1"
+
+// Tipi fisici e logici Parquet usati dagli export
+const (
+	parquetTypeInt64     = 2
+	parquetTypeDouble    = 5
+	parquetTypeByteArray = 6
+
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMillis = 9
+
+	parquetRepetitionRequired = 0
+	parquetEncodingPlain      = 0
+	parquetEncodingRLE        = 3
+	parquetCodecUncompressed  = 0
+	parquetPageTypeData       = 0
+)
+
+// Tipi del protocollo Thrift compact
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// parquetColumn è una colonna da scrivere; va valorizzato solo lo slice corrispondente al tipo
+type parquetColumn struct {
+	name       string
+	timestamp  bool // Colonna int64 con millisecondi Unix
+	doubles    []float64
+	ints       []int64
+	strings    []string
+	physical   int32
+	numValues  int
+	pageOffset int64
+	chunkSize  int64
+}
+
+// doubleColumn crea una colonna di float64
+func doubleColumn(name string, values []float64) *parquetColumn {
+	return &parquetColumn{name: name, doubles: values, physical: parquetTypeDouble, numValues: len(values)}
+}
+
+// int64Column crea una colonna di interi
+func int64Column(name string, values []int64) *parquetColumn {
+	return &parquetColumn{name: name, ints: values, physical: parquetTypeInt64, numValues: len(values)}
+}
+
+// timestampColumn crea una colonna di timestamp in millisecondi Unix
+func timestampColumn(name string, values []int64) *parquetColumn {
+	column := int64Column(name, values)
+	column.timestamp = true
+	return column
+}
+
+// stringColumn crea una colonna di stringhe UTF-8
+func stringColumn(name string, values []string) *parquetColumn {
+	return &parquetColumn{name: name, strings: values, physical: parquetTypeByteArray, numValues: len(values)}
+}
+
+// plainValues codifica i valori della colonna con l'encoding PLAIN
+func (c *parquetColumn) plainValues() []byte {
+	var buf bytes.Buffer
+	var scratch [8]byte
+	switch c.physical {
+	case parquetTypeDouble:
+		for _, value := range c.doubles {
+			binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(value))
+			buf.Write(scratch[:])
+		}
+	case parquetTypeInt64:
+		for _, value := range c.ints {
+			binary.LittleEndian.PutUint64(scratch[:], uint64(value))
+			buf.Write(scratch[:])
+		}
+	case parquetTypeByteArray:
+		for _, value := range c.strings {
+			binary.LittleEndian.PutUint32(scratch[:4], uint32(len(value)))
+			buf.Write(scratch[:4])
+			buf.WriteString(value)
+		}
+	}
+	return buf.Bytes()
+}
+
+// writeParquet scrive le colonne come file Parquet; tutte le colonne devono avere numRows valori
+func writeParquet(w io.Writer, numRows int, columns []*parquetColumn) error {
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	for _, column := range columns {
+		if column.numValues != numRows {
+			return fmt.Errorf("column %s has %d values, expected %d", column.name, column.numValues, numRows)
+		}
+
+		data := column.plainValues()
+		header := &compactWriter{}
+		header.begin()
+		header.i32(1, parquetPageTypeData)
+		header.i32(2, int32(len(data)))
+		header.i32(3, int32(len(data)))
+		header.structBegin(5)
+		header.i32(1, int32(numRows))
+		header.i32(2, parquetEncodingPlain)
+		header.i32(3, parquetEncodingRLE)
+		header.i32(4, parquetEncodingRLE)
+		header.structEnd()
+		header.end()
+
+		column.pageOffset = int64(file.Len())
+		file.Write(header.buf.Bytes())
+		file.Write(data)
+		column.chunkSize = int64(file.Len()) - column.pageOffset
+	}
+
+	footer := fileMetadata(numRows, columns)
+	file.Write(footer)
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	file.Write(length[:])
+	file.WriteString(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// fileMetadata serializza il FileMetaData con schema e row group
+func fileMetadata(numRows int, columns []*parquetColumn) []byte {
+	meta := &compactWriter{}
+	meta.begin()
+	meta.i32(1, 1) // version
+
+	// Schema: radice seguita dalle colonne foglia
+	meta.listBegin(2, thriftStruct, len(columns)+1)
+	meta.elemBegin()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(columns)))
+	meta.elemEnd()
+	for _, column := range columns {
+		meta.elemBegin()
+		meta.i32(1, column.physical)
+		meta.i32(3, parquetRepetitionRequired)
+		meta.binary(4, column.name)
+		switch {
+		case column.physical == parquetTypeByteArray:
+			meta.i32(6, parquetConvertedUTF8)
+		case column.timestamp:
+			meta.i32(6, parquetConvertedTimestampMillis)
+		}
+		meta.elemEnd()
+	}
+
+	meta.i64(3, int64(numRows))
+
+	// Un solo row group con un column chunk per colonna
+	var totalSize int64
+	for _, column := range columns {
+		totalSize += column.chunkSize
+	}
+	meta.listBegin(4, thriftStruct, 1)
+	meta.elemBegin()
+	meta.listBegin(1, thriftStruct, len(columns))
+	for _, column := range columns {
+		meta.elemBegin()
+		meta.i64(2, column.pageOffset)
+		meta.structBegin(3)
+		meta.i32(1, column.physical)
+		meta.listBegin(2, thriftI32, 2)
+		meta.listI32(parquetEncodingPlain)
+		meta.listI32(parquetEncodingRLE)
+		meta.listBegin(3, thriftBinary, 1)
+		meta.listBinary(column.name)
+		meta.i32(4, parquetCodecUncompressed)
+		meta.i64(5, int64(numRows))
+		meta.i64(6, column.chunkSize)
+		meta.i64(7, column.chunkSize)
+		meta.i64(9, column.pageOffset)
+		meta.structEnd()
+		meta.elemEnd()
+	}
+	meta.i64(2, totalSize)
+	meta.i64(3, int64(numRows))
+	meta.elemEnd()
+
+	meta.binary(6, "mkybot")
+	meta.end()
+	return meta.buf.Bytes()
+}
+
+// compactWriter serializza strutture con il protocollo Thrift compact
+// lastField tiene l'ultimo field id di ogni struttura aperta, per la codifica a delta degli header
+type compactWriter struct {
+	buf       bytes.Buffer
+	lastField []int16
+}
+
+// begin apre la struttura di primo livello
+func (c *compactWriter) begin() {
+	c.lastField = append(c.lastField, 0)
+}
+
+// end chiude la struttura di primo livello
+func (c *compactWriter) end() {
+	c.buf.WriteByte(0)
+	c.lastField = c.lastField[:len(c.lastField)-1]
+}
+
+// fieldHeader scrive l'header di un campo: delta dal campo precedente se possibile, altrimenti id esplicito
+func (c *compactWriter) fieldHeader(id int16, fieldType byte) {
+	last := &c.lastField[len(c.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		c.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		c.buf.WriteByte(fieldType)
+		c.varint(uint64((int64(id) << 1) ^ (int64(id) >> 63)))
+	}
+	*last = id
+}
+
+// i32 scrive un campo intero a 32 bit
+func (c *compactWriter) i32(id int16, value int32) {
+	c.fieldHeader(id, thriftI32)
+	c.zigzag(int64(value))
+}
+
+// i64 scrive un campo intero a 64 bit
+func (c *compactWriter) i64(id int16, value int64) {
+	c.fieldHeader(id, thriftI64)
+	c.zigzag(value)
+}
+
+// binary scrive un campo stringa
+func (c *compactWriter) binary(id int16, value string) {
+	c.fieldHeader(id, thriftBinary)
+	c.listBinary(value)
+}
+
+// structBegin apre un campo struttura
+func (c *compactWriter) structBegin(id int16) {
+	c.fieldHeader(id, thriftStruct)
+	c.lastField = append(c.lastField, 0)
+}
+
+// structEnd chiude un campo struttura
+func (c *compactWriter) structEnd() {
+	c.end()
+}
+
+// listBegin apre un campo lista di size elementi del tipo indicato
+func (c *compactWriter) listBegin(id int16, elemType byte, size int) {
+	c.fieldHeader(id, thriftList)
+	if size < 15 {
+		c.buf.WriteByte(byte(size)<<4 | elemType)
+		return
+	}
+	c.buf.WriteByte(0xF0 | elemType)
+	c.varint(uint64(size))
+}
+
+// elemBegin apre una struttura elemento di lista
+func (c *compactWriter) elemBegin() {
+	c.lastField = append(c.lastField, 0)
+}
+
+// elemEnd chiude una struttura elemento di lista
+func (c *compactWriter) elemEnd() {
+	c.end()
+}
+
+// listI32 scrive un elemento intero di lista
+func (c *compactWriter) listI32(value int32) {
+	c.zigzag(int64(value))
+}
+
+// listBinary scrive un elemento stringa di lista
+func (c *compactWriter) listBinary(value string) {
+	c.varint(uint64(len(value)))
+	c.buf.WriteString(value)
+}
+
+// zigzag scrive un intero con segno in codifica zigzag
+func (c *compactWriter) zigzag(value int64) {
+	c.varint(uint64((value << 1) ^ (value >> 63)))
+}
+
+// varint scrive un intero senza segno in codifica varint
+func (c *compactWriter) varint(value uint64) {
+	var scratch [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(scratch[:], value)
+	c.buf.Write(scratch[:n])
+}
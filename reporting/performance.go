@@ -0,0 +1,259 @@
+package reporting
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+const (
+	// Numero di giorni in un anno usato per l'annualizzazione (i mercati crypto sono sempre aperti)
+	daysPerYear = 365.0
+)
+
+// EquityPoint rappresenta un punto della curva di equity
+type EquityPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Equity    float64   `json:"equity"`
+	Flow      float64   `json:"flow,omitempty"` // Depositi meno prelievi dal punto precedente, già inclusi in Equity
+}
+
+// PerformanceMetrics contiene le metriche di performance calcolate da una curva di equity
+type PerformanceMetrics struct {
+	StartDate           time.Time     `json:"start_date"`
+	EndDate             time.Time     `json:"end_date"`
+	StartEquity         float64       `json:"start_equity"`
+	EndEquity           float64       `json:"end_equity"`
+	NetCashFlow         float64       `json:"net_cash_flow"`         // Depositi meno prelievi nel periodo
+	TradingPnL          float64       `json:"trading_pnl"`           // Variazione dell'equity al netto di depositi e prelievi
+	TotalReturn         float64       `json:"total_return"`          // Rendimento totale time-weighted in percentuale
+	CAGR                float64       `json:"cagr"`                  // Compound Annual Growth Rate in percentuale
+	MaxDrawdown         float64       `json:"max_drawdown"`          // Massimo drawdown in percentuale (valore positivo)
+	MaxDrawdownDuration time.Duration `json:"max_drawdown_duration"` // Durata del drawdown più lungo
+	Sharpe              float64       `json:"sharpe"`                // Sharpe ratio annualizzato
+	Sortino             float64       `json:"sortino"`               // Sortino ratio annualizzato
+	Calmar              float64       `json:"calmar"`                // CAGR / MaxDrawdown
+	PeriodsPerYear      float64       `json:"periods_per_year"`      // Frequenza della serie usata per l'annualizzazione
+	Points              int           `json:"points"`
+}
+
+// EquityPointsFromSnapshots converte gli snapshot di bilancio in una curva di equity ordinata
+func EquityPointsFromSnapshots(snapshots []*models.BalanceSnapshot) []EquityPoint {
+	points := make([]EquityPoint, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		points = append(points, EquityPoint{
+			Timestamp: snapshot.TakenAt,
+			Equity:    snapshot.Equity,
+		})
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Timestamp.Before(points[j].Timestamp)
+	})
+
+	return points
+}
+
+// ApplyCashFlows assegna depositi e prelievi al primo punto della curva successivo al movimento
+// I movimenti precedenti al primo punto o successivi all'ultimo sono già fuori dal periodo misurato
+func ApplyCashFlows(points []EquityPoint, flows []*models.CashFlow) []EquityPoint {
+	for _, flow := range flows {
+		i := sort.Search(len(points), func(i int) bool {
+			return !points[i].Timestamp.Before(flow.OccurredAt)
+		})
+		if i == 0 || i == len(points) {
+			continue
+		}
+		points[i].Flow += flow.Amount
+	}
+	return points
+}
+
+// tradingCurve restituisce la curva di equity senza depositi e prelievi (rendimenti time-weighted)
+// Parte dall'equity iniziale e cresce solo con i rendimenti per periodo, così un deposito non è un guadagno
+// e un prelievo non è un drawdown; senza movimenti coincide con la curva originale
+func tradingCurve(points []EquityPoint) []EquityPoint {
+	curve := make([]EquityPoint, len(points))
+	curve[0] = EquityPoint{Timestamp: points[0].Timestamp, Equity: points[0].Equity}
+	for i := 1; i < len(points); i++ {
+		value := curve[i-1].Equity
+		if points[i-1].Equity > 0 {
+			value *= (points[i].Equity - points[i].Flow) / points[i-1].Equity
+		}
+		curve[i] = EquityPoint{Timestamp: points[i].Timestamp, Equity: value}
+	}
+	return curve
+}
+
+// CalculatePerformanceMetrics calcola le metriche di performance da una curva di equity
+// riskFreeRate è il tasso privo di rischio annuo (es. 0.04 per 4%)
+// La frequenza di annualizzazione viene dedotta dall'intervallo medio tra i punti.
+// Rendimenti, drawdown e ratio escludono i depositi e prelievi registrati in Flow
+func CalculatePerformanceMetrics(points []EquityPoint, riskFreeRate float64) (*PerformanceMetrics, error) {
+	if len(points) < 2 {
+		return nil, fmt.Errorf("at least 2 equity points are required, got %d", len(points))
+	}
+
+	if points[0].Equity <= 0 {
+		return nil, fmt.Errorf("starting equity must be positive, got %.8f", points[0].Equity)
+	}
+
+	elapsed := points[len(points)-1].Timestamp.Sub(points[0].Timestamp)
+	if elapsed <= 0 {
+		return nil, fmt.Errorf("equity points must span a positive time range")
+	}
+
+	metrics := &PerformanceMetrics{
+		StartDate:   points[0].Timestamp,
+		EndDate:     points[len(points)-1].Timestamp,
+		StartEquity: points[0].Equity,
+		EndEquity:   points[len(points)-1].Equity,
+		Points:      len(points),
+	}
+	for _, point := range points[1:] {
+		metrics.NetCashFlow += point.Flow
+	}
+	metrics.TradingPnL = metrics.EndEquity - metrics.StartEquity - metrics.NetCashFlow
+
+	// Da qui le metriche usano la curva senza depositi e prelievi
+	points = tradingCurve(points)
+	first := points[0]
+	last := points[len(points)-1]
+	metrics.TotalReturn = (last.Equity/first.Equity - 1) * 100
+
+	// Frequenza della serie: numero medio di periodi in un anno
+	avgPeriod := elapsed / time.Duration(len(points)-1)
+	metrics.PeriodsPerYear = (daysPerYear * 24 * float64(time.Hour)) / float64(avgPeriod)
+
+	// CAGR
+	years := elapsed.Hours() / 24 / daysPerYear
+	if last.Equity > 0 {
+		metrics.CAGR = (math.Pow(last.Equity/first.Equity, 1/years) - 1) * 100
+	} else {
+		metrics.CAGR = -100
+	}
+
+	// Drawdown
+	metrics.MaxDrawdown, metrics.MaxDrawdownDuration = maxDrawdown(points)
+
+	// Rendimenti per periodo
+	returns := periodReturns(points)
+	periodRiskFree := riskFreeRate / metrics.PeriodsPerYear
+
+	metrics.Sharpe = sharpeRatio(returns, periodRiskFree, metrics.PeriodsPerYear)
+	metrics.Sortino = sortinoRatio(returns, periodRiskFree, metrics.PeriodsPerYear)
+
+	if metrics.MaxDrawdown > 0 {
+		metrics.Calmar = metrics.CAGR / metrics.MaxDrawdown
+	}
+
+	return metrics, nil
+}
+
+// maxDrawdown calcola il massimo drawdown percentuale e la durata del drawdown più lungo
+func maxDrawdown(points []EquityPoint) (float64, time.Duration) {
+	peak := points[0].Equity
+	peakTime := points[0].Timestamp
+	maxDD := 0.0
+	var maxDuration time.Duration
+
+	for _, point := range points {
+		if point.Equity >= peak {
+			peak = point.Equity
+			peakTime = point.Timestamp
+			continue
+		}
+
+		if drawdown := (peak - point.Equity) / peak * 100; drawdown > maxDD {
+			maxDD = drawdown
+		}
+		if duration := point.Timestamp.Sub(peakTime); duration > maxDuration {
+			maxDuration = duration
+		}
+	}
+
+	return maxDD, maxDuration
+}
+
+// periodReturns calcola i rendimenti semplici tra punti consecutivi
+func periodReturns(points []EquityPoint) []float64 {
+	returns := make([]float64, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		if points[i-1].Equity <= 0 {
+			continue
+		}
+		returns = append(returns, points[i].Equity/points[i-1].Equity-1)
+	}
+	return returns
+}
+
+// sharpeRatio calcola lo Sharpe ratio annualizzato
+func sharpeRatio(returns []float64, periodRiskFree, periodsPerYear float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+
+	excess := make([]float64, len(returns))
+	for i, r := range returns {
+		excess[i] = r - periodRiskFree
+	}
+
+	std := stdDev(excess)
+	if std == 0 {
+		return 0
+	}
+
+	return mean(excess) / std * math.Sqrt(periodsPerYear)
+}
+
+// sortinoRatio calcola il Sortino ratio annualizzato usando la downside deviation
+func sortinoRatio(returns []float64, periodRiskFree, periodsPerYear float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+
+	sumExcess := 0.0
+	downsideSquares := 0.0
+	for _, r := range returns {
+		excess := r - periodRiskFree
+		sumExcess += excess
+		if excess < 0 {
+			downsideSquares += excess * excess
+		}
+	}
+
+	downsideDev := math.Sqrt(downsideSquares / float64(len(returns)))
+	if downsideDev == 0 {
+		return 0
+	}
+
+	return (sumExcess / float64(len(returns))) / downsideDev * math.Sqrt(periodsPerYear)
+}
+
+// mean calcola la media aritmetica
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// stdDev calcola la deviazione standard campionaria
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
@@ -0,0 +1,243 @@
+package reporting
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// Formati dell'export fiscale
+const (
+	TaxFormatGeneric  = "generic"  // Tutte le colonne dei lotti, per fogli di calcolo e commercialisti
+	TaxFormatForm8949 = "form8949" // Colonne del modulo IRS 8949 (USA), importabile dai principali software fiscali
+)
+
+// longTermHolding è il periodo di detenzione oltre cui una plusvalenza è a lungo termine nel modulo 8949
+const longTermHolding = 365 * 24 * time.Hour
+
+// quantityEpsilon assorbe gli errori di arrotondamento nel consumo dei lotti
+const quantityEpsilon = 1e-9
+
+// TaxLot è una plusvalenza o minusvalenza realizzata: la chiusura di (parte di) un lotto di apertura
+// Per una posizione short il lotto si apre con la vendita (Proceeds) e si chiude con l'acquisto (CostBasis)
+type TaxLot struct {
+	Exchange   string    `json:"exchange"`
+	Symbol     string    `json:"symbol"`
+	Direction  string    `json:"direction"` // long o short
+	Quantity   float64   `json:"quantity"`
+	OpenedAt   time.Time `json:"opened_at"`
+	ClosedAt   time.Time `json:"closed_at"`
+	OpenPrice  float64   `json:"open_price"`
+	ClosePrice float64   `json:"close_price"`
+	Proceeds   float64   `json:"proceeds"`   // Incasso della vendita al netto della sua commissione
+	CostBasis  float64   `json:"cost_basis"` // Costo dell'acquisto più la sua commissione
+	Fees       float64   `json:"fees"`       // Commissioni di apertura e chiusura attribuite al lotto
+	Gain       float64   `json:"gain"`       // Proceeds - CostBasis
+}
+
+// LongTerm indica se il lotto è stato detenuto per più di un anno
+func (l TaxLot) LongTerm() bool {
+	return l.ClosedAt.Sub(l.OpenedAt) > longTermHolding
+}
+
+// openLot è la parte ancora aperta di un'esecuzione
+type openLot struct {
+	quantity   float64
+	price      float64
+	feePerUnit float64
+	openedAt   time.Time
+}
+
+// MatchTaxLotsFIFO abbina aperture e chiusure delle esecuzioni in ordine FIFO, per exchange e simbolo
+// Un acquisto chiude prima i lotti short aperti e apre un lotto long con la quantità residua (e viceversa);
+// le commissioni sono ripartite in proporzione alla quantità. Le esecuzioni senza quantità o prezzo
+// (es. funding) sono ignorate; i lotti ancora aperti non sono realizzati e non compaiono nel risultato
+func MatchTaxLotsFIFO(executions []*models.Execution) []TaxLot {
+	sorted := make([]*models.Execution, 0, len(executions))
+	for _, execution := range executions {
+		if execution.Qty > 0 && execution.Price > 0 {
+			sorted = append(sorted, execution)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ExecTime.Before(sorted[j].ExecTime)
+	})
+
+	type bookKey struct{ exchange, symbol string }
+	longs := make(map[bookKey][]openLot)
+	shorts := make(map[bookKey][]openLot)
+
+	var lots []TaxLot
+	for _, execution := range sorted {
+		key := bookKey{exchange: execution.Exchange, symbol: execution.Symbol}
+		buy := strings.EqualFold(execution.Side, string(models.OrderSideBuy))
+		remaining := execution.Qty
+		feePerUnit := execution.Fee / execution.Qty
+
+		// Un acquisto chiude gli short, una vendita chiude i long
+		opposite, same, direction := longs, shorts, "long"
+		if buy {
+			opposite, same, direction = shorts, longs, "short"
+		}
+
+		queue := opposite[key]
+		for len(queue) > 0 && remaining > quantityEpsilon {
+			lot := &queue[0]
+			quantity := math.Min(lot.quantity, remaining)
+			lots = append(lots, closeLot(execution, direction, lot, quantity, feePerUnit))
+
+			lot.quantity -= quantity
+			remaining -= quantity
+			if lot.quantity <= quantityEpsilon {
+				queue = queue[1:]
+			}
+		}
+		opposite[key] = queue
+
+		if remaining > quantityEpsilon {
+			same[key] = append(same[key], openLot{
+				quantity:   remaining,
+				price:      execution.Price,
+				feePerUnit: feePerUnit,
+				openedAt:   execution.ExecTime,
+			})
+		}
+	}
+
+	sort.SliceStable(lots, func(i, j int) bool {
+		return lots[i].ClosedAt.Before(lots[j].ClosedAt)
+	})
+	return lots
+}
+
+// closeLot crea il lotto realizzato chiudendo quantity del lotto aperto con l'esecuzione
+func closeLot(execution *models.Execution, direction string, lot *openLot, quantity, closeFeePerUnit float64) TaxLot {
+	openFee := lot.feePerUnit * quantity
+	closeFee := closeFeePerUnit * quantity
+
+	taxLot := TaxLot{
+		Exchange:   execution.Exchange,
+		Symbol:     execution.Symbol,
+		Direction:  direction,
+		Quantity:   quantity,
+		OpenedAt:   lot.openedAt,
+		ClosedAt:   execution.ExecTime,
+		OpenPrice:  lot.price,
+		ClosePrice: execution.Price,
+		Fees:       openFee + closeFee,
+	}
+	if direction == "long" {
+		taxLot.CostBasis = lot.price*quantity + openFee
+		taxLot.Proceeds = execution.Price*quantity - closeFee
+	} else {
+		taxLot.Proceeds = lot.price*quantity - openFee
+		taxLot.CostBasis = execution.Price*quantity + closeFee
+	}
+	taxLot.Gain = taxLot.Proceeds - taxLot.CostBasis
+	return taxLot
+}
+
+// TaxLotsForYear filtra i lotti realizzati (chiusi) nell'anno indicato, nel fuso orario loc
+func TaxLotsForYear(lots []TaxLot, year int, loc *time.Location) []TaxLot {
+	var filtered []TaxLot
+	for _, lot := range lots {
+		if lot.ClosedAt.In(loc).Year() == year {
+			filtered = append(filtered, lot)
+		}
+	}
+	return filtered
+}
+
+// WriteTaxLotsCSV scrive i lotti realizzati nel formato indicato; date nel fuso orario loc
+func WriteTaxLotsCSV(w io.Writer, lots []TaxLot, format string, loc *time.Location) error {
+	switch format {
+	case TaxFormatGeneric:
+		return writeGenericTaxCSV(w, lots, loc)
+	case TaxFormatForm8949:
+		return writeForm8949CSV(w, lots, loc)
+	default:
+		return fmt.Errorf("unsupported tax format %q (use %s or %s)", format, TaxFormatGeneric, TaxFormatForm8949)
+	}
+}
+
+// writeGenericTaxCSV scrive tutte le colonne dei lotti
+func writeGenericTaxCSV(w io.Writer, lots []TaxLot, loc *time.Location) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"exchange", "symbol", "direction", "quantity", "opened_at", "closed_at",
+		"open_price", "close_price", "proceeds", "cost_basis", "fees", "gain", "term",
+	}); err != nil {
+		return err
+	}
+
+	for _, lot := range lots {
+		if err := writer.Write([]string{
+			lot.Exchange,
+			lot.Symbol,
+			lot.Direction,
+			formatFloat(lot.Quantity),
+			lot.OpenedAt.In(loc).Format(time.RFC3339),
+			lot.ClosedAt.In(loc).Format(time.RFC3339),
+			formatFloat(lot.OpenPrice),
+			formatFloat(lot.ClosePrice),
+			formatMoney(lot.Proceeds),
+			formatMoney(lot.CostBasis),
+			formatMoney(lot.Fees),
+			formatMoney(lot.Gain),
+			taxTerm(lot),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeForm8949CSV scrive le colonne del modulo 8949: descrizione, date di acquisto e vendita, incasso, costo e risultato
+// Per gli short la data di acquisto è quella di apertura, come nei report dei broker
+func writeForm8949CSV(w io.Writer, lots []TaxLot, loc *time.Location) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"Description", "Date Acquired", "Date Sold", "Proceeds", "Cost Basis", "Gain or Loss", "Term",
+	}); err != nil {
+		return err
+	}
+
+	for _, lot := range lots {
+		description := fmt.Sprintf("%s %s %s (%s)", formatFloat(lot.Quantity), lot.Symbol, lot.Direction, lot.Exchange)
+		if err := writer.Write([]string{
+			description,
+			lot.OpenedAt.In(loc).Format("01/02/2006"),
+			lot.ClosedAt.In(loc).Format("01/02/2006"),
+			formatMoney(lot.Proceeds),
+			formatMoney(lot.CostBasis),
+			formatMoney(lot.Gain),
+			taxTerm(lot),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// taxTerm classifica il lotto a breve o lungo termine
+func taxTerm(lot TaxLot) string {
+	if lot.LongTerm() {
+		return "Long"
+	}
+	return "Short"
+}
+
+// formatMoney formatta un importo con 2 decimali, come richiesto dai software fiscali
+func formatMoney(value float64) string {
+	return fmt.Sprintf("%.2f", value)
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// accountSettingsRepository implementa AccountSettingsRepository
+type accountSettingsRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountSettingsRepository crea una nuova istanza di AccountSettingsRepository
+func NewAccountSettingsRepository(db *gorm.DB) AccountSettingsRepository {
+	return &accountSettingsRepository{db: db}
+}
+
+// SaveSettings crea o sostituisce la configurazione dell'account di una venue
+func (r *accountSettingsRepository) SaveSettings(ctx context.Context, settings *models.AccountSettings) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "exchange"}},
+		DoUpdates: clause.AssignmentColumns([]string{"unified", "unified_status", "margin_mode", "fetched_at"}),
+	}).Create(settings).Error
+}
+
+// ListSettings recupera la configurazione degli account di tutte le venue in ordine di venue
+func (r *accountSettingsRepository) ListSettings(ctx context.Context) ([]*models.AccountSettings, error) {
+	var settings []*models.AccountSettings
+	if err := r.db.WithContext(ctx).Order("exchange ASC").Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// SaveFeeRate crea o sostituisce le commissioni di un simbolo su una venue
+func (r *accountSettingsRepository) SaveFeeRate(ctx context.Context, rate *models.FeeRate) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "exchange"}, {Name: "symbol"}},
+		DoUpdates: clause.AssignmentColumns([]string{"maker_rate", "taker_rate", "fetched_at"}),
+	}).Create(rate).Error
+}
+
+// ListFeeRates recupera le commissioni salvate in ordine di venue e simbolo
+func (r *accountSettingsRepository) ListFeeRates(ctx context.Context) ([]*models.FeeRate, error) {
+	var rates []*models.FeeRate
+	if err := r.db.WithContext(ctx).Order("exchange ASC, symbol ASC").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// balanceSnapshotRepository implementa BalanceSnapshotRepository
+type balanceSnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewBalanceSnapshotRepository crea una nuova istanza di BalanceSnapshotRepository
+func NewBalanceSnapshotRepository(db *gorm.DB) BalanceSnapshotRepository {
+	return &balanceSnapshotRepository{db: db}
+}
+
+// Create crea un nuovo snapshot
+func (r *balanceSnapshotRepository) Create(ctx context.Context, snapshot *models.BalanceSnapshot) error {
+	return r.db.WithContext(ctx).Create(snapshot).Error
+}
+
+// GetByDateRange recupera gli snapshot di una sorgente in un range di date, in ordine cronologico
+func (r *balanceSnapshotRepository) GetByDateRange(ctx context.Context, source models.SnapshotSource, runID string, startDate, endDate time.Time) ([]*models.BalanceSnapshot, error) {
+	var snapshots []*models.BalanceSnapshot
+	query := r.db.WithContext(ctx).
+		Where("source = ? AND taken_at >= ? AND taken_at <= ?", source, startDate, endDate)
+
+	if runID != "" {
+		query = query.Where("run_id = ?", runID)
+	}
+
+	err := query.Order("taken_at ASC").Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// GetLatest recupera l'ultimo snapshot di una sorgente
+func (r *balanceSnapshotRepository) GetLatest(ctx context.Context, source models.SnapshotSource, runID string) (*models.BalanceSnapshot, error) {
+	var snapshot models.BalanceSnapshot
+	query := r.db.WithContext(ctx).Where("source = ?", source)
+
+	if runID != "" {
+		query = query.Where("run_id = ?", runID)
+	}
+
+	err := query.Order("taken_at DESC").First(&snapshot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// candleRepository implementa CandleRepository
+type candleRepository struct {
+	db *gorm.DB
+}
+
+// NewCandleRepository crea una nuova istanza di CandleRepository
+func NewCandleRepository(db *gorm.DB) CandleRepository {
+	return &candleRepository{db: db}
+}
+
+// CreateBatch inserisce più candele ignorando quelle già presenti
+// Le candele chiuse non cambiano, quindi le finestre sovrapposte possono essere reinserite senza aggiornamenti
+func (r *candleRepository) CreateBatch(ctx context.Context, candles []*models.CandleRecord) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "symbol"}, {Name: "market"}, {Name: "timeframe"}, {Name: "open_time"}},
+		DoNothing: true,
+	}).CreateInBatches(candles, defaultBatchSize).Error
+}
+
+// GetLastBefore recupera le ultime limit candele aperte entro end, in ordine cronologico
+func (r *candleRepository) GetLastBefore(ctx context.Context, symbol string, market models.Market, timeframe models.Timeframe, end time.Time, limit int) ([]*models.CandleRecord, error) {
+	var candles []*models.CandleRecord
+	err := r.db.WithContext(ctx).
+		Where("symbol = ? AND market = ? AND timeframe = ? AND open_time <= ?", symbol, market, timeframe, end).
+		Order("open_time DESC").
+		Limit(limit).
+		Find(&candles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Dalla più vecchia alla più recente
+	for i, j := 0, len(candles)-1; i < j; i, j = i+1, j-1 {
+		candles[i], candles[j] = candles[j], candles[i]
+	}
+	return candles, nil
+}
+
+// GetRange recupera le candele aperte in [start, end), in ordine cronologico
+func (r *candleRepository) GetRange(ctx context.Context, symbol string, market models.Market, timeframe models.Timeframe, start, end time.Time) ([]*models.CandleRecord, error) {
+	var candles []*models.CandleRecord
+	err := r.db.WithContext(ctx).
+		Where("symbol = ? AND market = ? AND timeframe = ? AND open_time >= ? AND open_time < ?", symbol, market, timeframe, start.UTC(), end.UTC()).
+		Order("open_time ASC").
+		Find(&candles).Error
+	if err != nil {
+		return nil, err
+	}
+	return candles, nil
+}
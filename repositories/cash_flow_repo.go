@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// cashFlowRepository implementa CashFlowRepository
+type cashFlowRepository struct {
+	db *gorm.DB
+}
+
+// NewCashFlowRepository crea una nuova istanza di CashFlowRepository
+func NewCashFlowRepository(db *gorm.DB) CashFlowRepository {
+	return &cashFlowRepository{db: db}
+}
+
+// CreateBatch inserisce più movimenti ignorando quelli già registrati per lo stesso exchange e ID
+// Permette di rileggere finestre sovrapposte del transaction log senza duplicati
+func (r *cashFlowRepository) CreateBatch(ctx context.Context, flows []*models.CashFlow) error {
+	if len(flows) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "exchange"}, {Name: "transaction_id"}},
+		DoNothing: true,
+	}).CreateInBatches(flows, defaultBatchSize).Error
+}
+
+// GetLatest recupera l'ultimo movimento registrato di un exchange
+func (r *cashFlowRepository) GetLatest(ctx context.Context, exchange string) (*models.CashFlow, error) {
+	var flow models.CashFlow
+	err := r.db.WithContext(ctx).
+		Where("exchange = ?", exchange).
+		Order("occurred_at DESC").
+		First(&flow).Error
+	if err != nil {
+		return nil, err
+	}
+	return &flow, nil
+}
+
+// GetByDateRange recupera i movimenti di tutti gli exchange in un range di date, in ordine cronologico
+func (r *cashFlowRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.CashFlow, error) {
+	var flows []*models.CashFlow
+	err := r.db.WithContext(ctx).
+		Where("occurred_at >= ? AND occurred_at <= ?", startDate, endDate).
+		Order("occurred_at ASC").
+		Find(&flows).Error
+	if err != nil {
+		return nil, err
+	}
+	return flows, nil
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// configVersionRepository implementa ConfigVersionRepository
+type configVersionRepository struct {
+	db *gorm.DB
+}
+
+// NewConfigVersionRepository crea una nuova istanza di ConfigVersionRepository
+func NewConfigVersionRepository(db *gorm.DB) ConfigVersionRepository {
+	return &configVersionRepository{db: db}
+}
+
+// Record salva una versione; se l'hash è già presente aggiorna solo l'istante dell'ultima applicazione
+func (r *configVersionRepository) Record(ctx context.Context, version *models.ConfigVersion) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_applied_at"}),
+	}).Create(version).Error
+}
+
+// GetByHashPrefix recupera le versioni il cui hash inizia con prefix (al più limit)
+func (r *configVersionRepository) GetByHashPrefix(ctx context.Context, prefix string, limit int) ([]*models.ConfigVersion, error) {
+	var versions []*models.ConfigVersion
+	err := r.db.WithContext(ctx).
+		Where("hash LIKE ? ESCAPE '\\'", escapeLike(prefix)+"%").
+		Order("last_applied_at DESC").
+		Limit(limit).
+		Find(&versions).Error
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// List recupera le versioni dalla più recentemente applicata
+func (r *configVersionRepository) List(ctx context.Context, limit int) ([]*models.ConfigVersion, error) {
+	var versions []*models.ConfigVersion
+	query := r.db.WithContext(ctx).Order("last_applied_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
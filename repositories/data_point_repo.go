@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dataPointRepository implementa DataPointRepository
+type dataPointRepository struct {
+	db *gorm.DB
+}
+
+// NewDataPointRepository crea una nuova istanza di DataPointRepository
+func NewDataPointRepository(db *gorm.DB) DataPointRepository {
+	return &dataPointRepository{db: db}
+}
+
+// CreateBatch inserisce più rilevazioni ignorando quelle già presenti per la stessa sorgente e istante
+// Permette di reimportare finestre sovrapposte senza duplicati
+func (r *dataPointRepository) CreateBatch(ctx context.Context, points []*models.DataPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source"}, {Name: "observed_at"}},
+		DoNothing: true,
+	}).CreateInBatches(points, defaultBatchSize).Error
+}
+
+// GetLatest recupera l'ultima rilevazione di una sorgente
+func (r *dataPointRepository) GetLatest(ctx context.Context, source string) (*models.DataPoint, error) {
+	var point models.DataPoint
+	err := r.db.WithContext(ctx).
+		Where("source = ?", source).
+		Order("observed_at DESC").
+		First(&point).Error
+	if err != nil {
+		return nil, err
+	}
+	return &point, nil
+}
+
+// GetByDateRange recupera le rilevazioni di una sorgente in un range di date, in ordine cronologico
+func (r *dataPointRepository) GetByDateRange(ctx context.Context, source string, startDate, endDate time.Time) ([]*models.DataPoint, error) {
+	var points []*models.DataPoint
+	err := r.db.WithContext(ctx).
+		Where("source = ? AND observed_at >= ? AND observed_at <= ?", source, startDate, endDate).
+		Order("observed_at ASC").
+		Find(&points).Error
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
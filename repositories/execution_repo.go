@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// executionRepository implementa ExecutionRepository
+type executionRepository struct {
+	db *gorm.DB
+}
+
+// NewExecutionRepository crea una nuova istanza di ExecutionRepository
+func NewExecutionRepository(db *gorm.DB) ExecutionRepository {
+	return &executionRepository{db: db}
+}
+
+// Create crea una nuova esecuzione
+func (r *executionRepository) Create(ctx context.Context, execution *models.Execution) error {
+	return r.db.WithContext(ctx).Create(execution).Error
+}
+
+// CreateBatch inserisce più esecuzioni in un'unica transazione, ignorando gli ExecID già presenti
+// Permette ai job di sincronizzazione di reimportare finestre sovrapposte senza duplicati
+func (r *executionRepository) CreateBatch(ctx context.Context, executions []*models.Execution, batchSize int) error {
+	if len(executions) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "exec_id"}},
+			DoNothing: true,
+		}).CreateInBatches(executions, batchSize).Error
+	})
+}
+
+// UpdateBatch aggiorna più esecuzioni (identificate da ExecID) in un'unica transazione
+func (r *executionRepository) UpdateBatch(ctx context.Context, executions []*models.Execution) error {
+	if len(executions) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, execution := range executions {
+			if execution.ExecID == "" {
+				return fmt.Errorf("cannot update execution without exec ID")
+			}
+
+			// Select("*") aggiorna anche i campi a zero (es. IsMaker false, Fee 0)
+			result := tx.Model(&models.Execution{}).
+				Where("exec_id = ?", execution.ExecID).
+				Select("*").Omit("id").
+				Updates(execution)
+			if result.Error != nil {
+				return fmt.Errorf("failed to update execution %s: %w", execution.ExecID, result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("execution %s not found: %w", execution.ExecID, gorm.ErrRecordNotFound)
+			}
+		}
+		return nil
+	})
+}
+
+// GetByExecID recupera un'esecuzione per ExecID
+func (r *executionRepository) GetByExecID(ctx context.Context, execID string) (*models.Execution, error) {
+	var execution models.Execution
+	err := r.db.WithContext(ctx).Where("exec_id = ?", execID).First(&execution).Error
+	if err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// GetByOrderID recupera le esecuzioni di un ordine
+func (r *executionRepository) GetByOrderID(ctx context.Context, orderID string) ([]*models.Execution, error) {
+	var executions []*models.Execution
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).
+		Order("exec_time ASC").Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
+// GetBySymbolAndDateRange recupera le esecuzioni di un simbolo in un range di date
+func (r *executionRepository) GetBySymbolAndDateRange(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*models.Execution, error) {
+	var executions []*models.Execution
+	err := r.db.WithContext(ctx).
+		Where("symbol = ? AND exec_time >= ? AND exec_time <= ?", symbol, startDate, endDate).
+		Order("exec_time ASC").Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
+// GetByDateRange recupera le esecuzioni di tutti i simboli in un range di date, in ordine cronologico
+func (r *executionRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.Execution, error) {
+	var executions []*models.Execution
+	err := r.db.WithContext(ctx).
+		Where("exec_time >= ? AND exec_time <= ?", startDate, endDate).
+		Order("exec_time ASC").Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fundingArbRepository implementa FundingArbRepository
+type fundingArbRepository struct {
+	db *gorm.DB
+}
+
+// NewFundingArbRepository crea una nuova istanza di FundingArbRepository
+func NewFundingArbRepository(db *gorm.DB) FundingArbRepository {
+	return &fundingArbRepository{db: db}
+}
+
+// activeFundingArbStatuses sono gli stati con almeno una gamba aperta
+var activeFundingArbStatuses = []models.FundingArbStatus{
+	models.FundingArbStatusOpening,
+	models.FundingArbStatusOpen,
+	models.FundingArbStatusClosing,
+	models.FundingArbStatusUnhedged,
+}
+
+// Create crea una nuova posizione
+func (r *fundingArbRepository) Create(ctx context.Context, position *models.FundingArbPosition) error {
+	return r.db.WithContext(ctx).Create(position).Error
+}
+
+// Update aggiorna una posizione esistente
+func (r *fundingArbRepository) Update(ctx context.Context, position *models.FundingArbPosition) error {
+	return r.db.WithContext(ctx).Save(position).Error
+}
+
+// GetByID recupera una posizione per ID
+func (r *fundingArbRepository) GetByID(ctx context.Context, id uint) (*models.FundingArbPosition, error) {
+	var position models.FundingArbPosition
+	err := r.db.WithContext(ctx).First(&position, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &position, nil
+}
+
+// GetActive recupera le posizioni con almeno una gamba aperta per un simbolo, dalla più vecchia
+func (r *fundingArbRepository) GetActive(ctx context.Context, symbol string) ([]*models.FundingArbPosition, error) {
+	var positions []*models.FundingArbPosition
+	err := r.db.WithContext(ctx).
+		Where("symbol = ? AND status IN ?", symbol, activeFundingArbStatuses).
+		Order("opened_at ASC").
+		Find(&positions).Error
+	if err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// GetAllActive recupera le posizioni con almeno una gamba aperta di tutti i simboli, dalla più vecchia
+func (r *fundingArbRepository) GetAllActive(ctx context.Context) ([]*models.FundingArbPosition, error) {
+	var positions []*models.FundingArbPosition
+	err := r.db.WithContext(ctx).
+		Where("status IN ?", activeFundingArbStatuses).
+		Order("opened_at ASC").
+		Find(&positions).Error
+	if err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// GetBySymbol recupera le posizioni di un simbolo, dalla più recente
+func (r *fundingArbRepository) GetBySymbol(ctx context.Context, symbol string, limit, offset int) ([]*models.FundingArbPosition, error) {
+	var positions []*models.FundingArbPosition
+	err := r.db.WithContext(ctx).
+		Where("symbol = ?", symbol).
+		Order("opened_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&positions).Error
+	if err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// basisSnapshotRepository implementa BasisSnapshotRepository
+type basisSnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewBasisSnapshotRepository crea una nuova istanza di BasisSnapshotRepository
+func NewBasisSnapshotRepository(db *gorm.DB) BasisSnapshotRepository {
+	return &basisSnapshotRepository{db: db}
+}
+
+// Create crea una nuova rilevazione
+func (r *basisSnapshotRepository) Create(ctx context.Context, snapshot *models.BasisSnapshot) error {
+	return r.db.WithContext(ctx).Create(snapshot).Error
+}
+
+// GetByDateRange recupera le rilevazioni di un simbolo in un range di date, in ordine cronologico
+func (r *basisSnapshotRepository) GetByDateRange(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*models.BasisSnapshot, error) {
+	var snapshots []*models.BasisSnapshot
+	err := r.db.WithContext(ctx).
+		Where("symbol = ? AND taken_at >= ? AND taken_at <= ?", symbol, startDate, endDate).
+		Order("taken_at ASC").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// GetSeries recupera le rilevazioni di una coppia di venue in un range di date, in ordine cronologico
+func (r *basisSnapshotRepository) GetSeries(ctx context.Context, symbol, spotExchange, perpExchange string, startDate, endDate time.Time) ([]*models.BasisSnapshot, error) {
+	var snapshots []*models.BasisSnapshot
+	err := r.db.WithContext(ctx).
+		Where("symbol = ? AND spot_exchange = ? AND perp_exchange = ?", symbol, spotExchange, perpExchange).
+		Where("taken_at >= ? AND taken_at <= ?", startDate, endDate).
+		Order("taken_at ASC").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// GetLatest recupera l'ultima rilevazione di un simbolo
+func (r *basisSnapshotRepository) GetLatest(ctx context.Context, symbol string) (*models.BasisSnapshot, error) {
+	var snapshot models.BasisSnapshot
+	err := r.db.WithContext(ctx).
+		Where("symbol = ?", symbol).
+		Order("taken_at DESC").
+		First(&snapshot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
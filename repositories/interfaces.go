@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"cross-exchange-arbitrage/models"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -51,6 +52,9 @@ type OrderRepository interface {
 	// GetBySymbol recupera ordini per simbolo
 	GetBySymbol(ctx context.Context, symbol string, limit, offset int) ([]*models.Order, error)
 
+	// Search recupera una pagina di ordini filtrati, con paginazione keyset (cursore) e ordinamento configurabile
+	Search(ctx context.Context, filter OrderSearchFilter, page OrderPageQuery) (*OrderPage, error)
+
 	// GetByStatus recupera ordini per stato
 	GetByStatus(ctx context.Context, statusName string, limit, offset int) ([]*models.Order, error)
 
@@ -69,11 +73,23 @@ type OrderRepository interface {
 	// GetByDateRange recupera ordini in un range di date
 	GetByDateRange(ctx context.Context, startDate, endDate string, limit, offset int) ([]*models.Order, error)
 
-	// Update aggiorna un ordine esistente
+	// GetLatestEntry recupera l'ultimo ingresso iniziale (non un incremento) ancora aperto per simbolo
+	GetLatestEntry(ctx context.Context, symbol string) (*models.Order, error)
+
+	// GetByParentOrderID recupera in ordine di livello gli incrementi collegati a un ingresso
+	GetByParentOrderID(ctx context.Context, parentOrderID string) ([]*models.Order, error)
+
+	// CreateBatch crea più ordini in un'unica transazione, a blocchi di batchSize
+	CreateBatch(ctx context.Context, orders []*models.Order, batchSize int) error
+
+	// Update aggiorna un ordine esistente, con controllo di versione (ErrVersionConflict)
 	Update(ctx context.Context, order *models.Order) error
 
-	// UpdateStatus aggiorna solo lo stato di un ordine
-	UpdateStatus(ctx context.Context, orderID string, statusID uint) error
+	// UpdateBatch aggiorna più ordini esistenti in un'unica transazione
+	UpdateBatch(ctx context.Context, orders []*models.Order) error
+
+	// UpdateStatus aggiorna solo lo stato di un ordine, se la versione coincide con quella letta (ErrVersionConflict)
+	UpdateStatus(ctx context.Context, orderID string, version, statusID uint) error
 
 	// UpdateResult aggiorna solo il risultato di un ordine
 	UpdateResult(ctx context.Context, orderID string, result models.OrderResult) error
@@ -105,6 +121,9 @@ type OrderRepository interface {
 	// GetTradingStats recupera statistiche di trading
 	GetTradingStats(ctx context.Context, symbol string) (*TradingStats, error)
 
+	// GetRejectStats conta gli ordini rifiutati raggruppati per motivo
+	GetRejectStats(ctx context.Context, symbol string) ([]*RejectStats, error)
+
 	// GetPnLStats recupera statistiche PnL
 	GetPnLStats(ctx context.Context, symbol string) (*PnLStats, error)
 }
@@ -117,6 +136,9 @@ type OrderAuditRepository interface {
 	// GetByOrderID recupera tutti i record di audit per un ordine
 	GetByOrderID(ctx context.Context, orderID string, limit, offset int) ([]*models.OrderAudit, error)
 
+	// GetByCorrelationID recupera in ordine cronologico i record di audit di un ciclo o di una richiesta
+	GetByCorrelationID(ctx context.Context, correlationID string, limit, offset int) ([]*models.OrderAudit, error)
+
 	// GetByFieldName recupera record di audit per campo
 	GetByFieldName(ctx context.Context, fieldName string, limit, offset int) ([]*models.OrderAudit, error)
 
@@ -136,6 +158,241 @@ type OrderAuditRepository interface {
 	DeleteOldRecords(ctx context.Context, beforeDate string) error
 }
 
+// OperatorAuditRepository definisce le operazioni sull'audit delle azioni manuali degli operatori
+type OperatorAuditRepository interface {
+	// Create registra un'azione manuale
+	Create(ctx context.Context, audit *models.OperatorAudit) error
+
+	// Search recupera le azioni che soddisfano i filtri, dalla più recente
+	Search(ctx context.Context, filter OperatorAuditFilter, limit, offset int) ([]*models.OperatorAudit, error)
+}
+
+// SignalDecisionRepository definisce le operazioni sulle spiegazioni dei segnali delle strategie
+type SignalDecisionRepository interface {
+	// Create salva la spiegazione di un segnale
+	Create(ctx context.Context, decision *models.SignalDecision) error
+
+	// GetByOrderID recupera la decisione che ha generato l'ordine indicato
+	GetByOrderID(ctx context.Context, orderID string) (*models.SignalDecision, error)
+
+	// Search recupera le decisioni che soddisfano i filtri, dalla più recente
+	Search(ctx context.Context, filter SignalDecisionFilter, limit, offset int) ([]*models.SignalDecision, error)
+
+	// DeleteBefore elimina le decisioni precedenti a before e restituisce quante ne ha eliminate
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// BalanceSnapshotRepository definisce l'interfaccia per le operazioni sugli snapshot di equity
+type BalanceSnapshotRepository interface {
+	// Create crea un nuovo snapshot
+	Create(ctx context.Context, snapshot *models.BalanceSnapshot) error
+
+	// GetByDateRange recupera gli snapshot di una sorgente in un range di date, in ordine cronologico
+	// Se runID è vuoto non viene applicato alcun filtro sul backtest
+	GetByDateRange(ctx context.Context, source models.SnapshotSource, runID string, startDate, endDate time.Time) ([]*models.BalanceSnapshot, error)
+
+	// GetLatest recupera l'ultimo snapshot di una sorgente
+	GetLatest(ctx context.Context, source models.SnapshotSource, runID string) (*models.BalanceSnapshot, error)
+}
+
+// OrderTagRepository definisce l'interfaccia per le operazioni sui tag e le note degli ordini
+type OrderTagRepository interface {
+	// Create crea un nuovo tag
+	Create(ctx context.Context, tag *models.OrderTag) error
+
+	// GetByID recupera un tag per ID
+	GetByID(ctx context.Context, id uint) (*models.OrderTag, error)
+
+	// GetByOrderID recupera tutti i tag di un ordine
+	GetByOrderID(ctx context.Context, orderID string) ([]*models.OrderTag, error)
+
+	// GetByOrderIDs recupera i tag di più ordini raggruppati per OrderID
+	GetByOrderIDs(ctx context.Context, orderIDs []string) (map[string][]*models.OrderTag, error)
+
+	// GetByTag recupera i record con un determinato tag
+	GetByTag(ctx context.Context, tag string, limit, offset int) ([]*models.OrderTag, error)
+
+	// Delete elimina un tag
+	Delete(ctx context.Context, id uint) error
+
+	// GetTagStats recupera le statistiche di trading raggruppate per tag
+	GetTagStats(ctx context.Context, symbol string) ([]*TagStats, error)
+}
+
+// ExecutionRepository definisce l'interfaccia per le operazioni sulle esecuzioni (trade)
+type ExecutionRepository interface {
+	// Create crea una nuova esecuzione
+	Create(ctx context.Context, execution *models.Execution) error
+
+	// CreateBatch inserisce più esecuzioni in un'unica transazione, ignorando gli ExecID già presenti
+	CreateBatch(ctx context.Context, executions []*models.Execution, batchSize int) error
+
+	// UpdateBatch aggiorna più esecuzioni (identificate da ExecID) in un'unica transazione
+	UpdateBatch(ctx context.Context, executions []*models.Execution) error
+
+	// GetByExecID recupera un'esecuzione per ExecID
+	GetByExecID(ctx context.Context, execID string) (*models.Execution, error)
+
+	// GetByOrderID recupera le esecuzioni di un ordine
+	GetByOrderID(ctx context.Context, orderID string) ([]*models.Execution, error)
+
+	// GetBySymbolAndDateRange recupera le esecuzioni di un simbolo in un range di date
+	GetBySymbolAndDateRange(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*models.Execution, error)
+
+	// GetByDateRange recupera le esecuzioni di tutti i simboli in un range di date
+	GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.Execution, error)
+}
+
+// OrderArchiveRepository definisce l'interfaccia per l'archivio degli ordini chiusi
+type OrderArchiveRepository interface {
+	// ArchiveClosedOrders sposta in archivio gli ordini chiusi prima di closedBefore, restituisce il numero di ordini spostati
+	ArchiveClosedOrders(ctx context.Context, closedBefore time.Time, batchSize int) (int64, error)
+
+	// GetByOrderID recupera un ordine archiviato per OrderID
+	GetByOrderID(ctx context.Context, orderID string) (*models.ArchivedOrder, error)
+
+	// GetBySymbol recupera ordini archiviati per simbolo
+	GetBySymbol(ctx context.Context, symbol string, limit, offset int) ([]*models.ArchivedOrder, error)
+
+	// Count conta gli ordini archiviati
+	Count(ctx context.Context) (int64, error)
+}
+
+// FundingArbRepository definisce l'interfaccia per le posizioni di funding arbitrage
+type FundingArbRepository interface {
+	// Create crea una nuova posizione
+	Create(ctx context.Context, position *models.FundingArbPosition) error
+
+	// Update aggiorna una posizione esistente
+	Update(ctx context.Context, position *models.FundingArbPosition) error
+
+	// GetByID recupera una posizione per ID
+	GetByID(ctx context.Context, id uint) (*models.FundingArbPosition, error)
+
+	// GetActive recupera le posizioni con almeno una gamba aperta per un simbolo
+	GetActive(ctx context.Context, symbol string) ([]*models.FundingArbPosition, error)
+
+	// GetAllActive recupera le posizioni con almeno una gamba aperta di tutti i simboli
+	GetAllActive(ctx context.Context) ([]*models.FundingArbPosition, error)
+
+	// GetBySymbol recupera le posizioni di un simbolo con paginazione
+	GetBySymbol(ctx context.Context, symbol string, limit, offset int) ([]*models.FundingArbPosition, error)
+}
+
+// BasisSnapshotRepository definisce l'interfaccia per le rilevazioni di basis e funding
+type BasisSnapshotRepository interface {
+	// Create crea una nuova rilevazione
+	Create(ctx context.Context, snapshot *models.BasisSnapshot) error
+
+	// GetByDateRange recupera le rilevazioni di un simbolo in un range di date, in ordine cronologico
+	GetByDateRange(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*models.BasisSnapshot, error)
+
+	// GetSeries recupera le rilevazioni di una coppia di venue in un range di date, in ordine cronologico
+	GetSeries(ctx context.Context, symbol, spotExchange, perpExchange string, startDate, endDate time.Time) ([]*models.BasisSnapshot, error)
+
+	// GetLatest recupera l'ultima rilevazione di un simbolo
+	GetLatest(ctx context.Context, symbol string) (*models.BasisSnapshot, error)
+}
+
+// PositionPnLRepository definisce le operazioni sugli snapshot periodici del PnL non realizzato delle posizioni
+type PositionPnLRepository interface {
+	// Create salva uno snapshot
+	Create(ctx context.Context, snapshot *models.PositionPnL) error
+
+	// GetSeries recupera gli snapshot di un simbolo in un range di date, in ordine cronologico
+	GetSeries(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*models.PositionPnL, error)
+
+	// DeleteBefore elimina gli snapshot precedenti a before e restituisce quanti ne ha eliminati
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// DataPointRepository definisce l'interfaccia per le rilevazioni delle sorgenti dati esterne
+type DataPointRepository interface {
+	// CreateBatch inserisce più rilevazioni ignorando quelle già presenti
+	CreateBatch(ctx context.Context, points []*models.DataPoint) error
+
+	// GetLatest recupera l'ultima rilevazione di una sorgente
+	GetLatest(ctx context.Context, source string) (*models.DataPoint, error)
+
+	// GetByDateRange recupera le rilevazioni di una sorgente in un range di date, in ordine cronologico
+	GetByDateRange(ctx context.Context, source string, startDate, endDate time.Time) ([]*models.DataPoint, error)
+}
+
+// CandleRepository definisce l'interfaccia per la cache locale delle candele chiuse
+type CandleRepository interface {
+	// CreateBatch inserisce più candele ignorando quelle già presenti
+	CreateBatch(ctx context.Context, candles []*models.CandleRecord) error
+
+	// GetLastBefore recupera le ultime limit candele aperte entro end, in ordine cronologico
+	GetLastBefore(ctx context.Context, symbol string, market models.Market, timeframe models.Timeframe, end time.Time, limit int) ([]*models.CandleRecord, error)
+
+	// GetRange recupera le candele aperte in [start, end), in ordine cronologico
+	GetRange(ctx context.Context, symbol string, market models.Market, timeframe models.Timeframe, start, end time.Time) ([]*models.CandleRecord, error)
+}
+
+// ConfigVersionRepository definisce l'interfaccia per le versioni della configurazione di strategia
+type ConfigVersionRepository interface {
+	// Record salva una versione o, se già presente, ne aggiorna l'ultima applicazione
+	Record(ctx context.Context, version *models.ConfigVersion) error
+
+	// GetByHashPrefix recupera le versioni il cui hash inizia con prefix
+	GetByHashPrefix(ctx context.Context, prefix string, limit int) ([]*models.ConfigVersion, error)
+
+	// List recupera le versioni dalla più recentemente applicata
+	List(ctx context.Context, limit int) ([]*models.ConfigVersion, error)
+}
+
+// ScheduledJobRepository definisce l'interfaccia per la coda persistente dei job differiti
+type ScheduledJobRepository interface {
+	// Enqueue salva un nuovo job in attesa
+	Enqueue(ctx context.Context, job *models.ScheduledJob) error
+
+	// ClaimDue prende in carico i job scaduti marcandoli running
+	ClaimDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledJob, error)
+
+	// Complete marca un job come eseguito
+	Complete(ctx context.Context, id uint, at time.Time) error
+
+	// Retry rimette in attesa un job fallito fino a retryAt
+	Retry(ctx context.Context, id uint, retryAt time.Time, lastError string) error
+
+	// Fail marca un job come fallito definitivamente
+	Fail(ctx context.Context, id uint, at time.Time, lastError string) error
+
+	// ReleaseRunning rimette in attesa i job interrotti da un riavvio
+	ReleaseRunning(ctx context.Context) (int64, error)
+
+	// ListByStatus recupera i job in uno stato, in ordine di esecuzione
+	ListByStatus(ctx context.Context, status models.JobStatus, limit int) ([]*models.ScheduledJob, error)
+
+	// DeleteFinishedBefore elimina i job conclusi prima di before
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// LockRepository definisce l'interfaccia per i lease che coordinano più istanze del bot
+type LockRepository interface {
+	// TryAcquire acquisisce o rinnova il lease di key per owner; false se è detenuto da un'altra istanza
+	TryAcquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+
+	// Release rilascia il lease se detenuto da owner
+	Release(ctx context.Context, key, owner string) error
+
+	// Get recupera il lease corrente di key
+	Get(ctx context.Context, key string) (*models.DistributedLock, error)
+}
+
+// TagStats rappresenta le statistiche di trading per tag
+type TagStats struct {
+	Tag              string  `json:"tag"`
+	TotalOrders      int64   `json:"total_orders"`
+	ProfitableOrders int64   `json:"profitable_orders"`
+	LosingOrders     int64   `json:"losing_orders"`
+	TotalPnL         float64 `json:"total_pnl"`
+	AvgPnL           float64 `json:"avg_pnl"`
+	AvgPnLPercentage float64 `json:"avg_pnl_percentage"`
+	WinRate          float64 `json:"win_rate"`
+}
+
 // TradingStats rappresenta le statistiche di trading
 type TradingStats struct {
 	Symbol           string  `json:"symbol"`
@@ -150,6 +407,12 @@ type TradingStats struct {
 	WinRate          float64 `json:"win_rate"`
 }
 
+// RejectStats rappresenta il numero di ordini rifiutati per un motivo
+type RejectStats struct {
+	Reason models.RejectReason `json:"reason"`
+	Count  int64               `json:"count"`
+}
+
 // PnLStats rappresenta le statistiche PnL
 type PnLStats struct {
 	Symbol             string  `json:"symbol"`
@@ -163,6 +426,99 @@ type PnLStats struct {
 	MinPnLPercentage   float64 `json:"min_pnl_percentage"`
 }
 
+// WorkerBudgetRepository definisce l'interfaccia per i budget virtuali dei worker
+type WorkerBudgetRepository interface {
+	// Save crea il budget o lo sostituisce (nuova allocazione)
+	Save(ctx context.Context, budget *models.WorkerBudget) error
+
+	// GetByWorker recupera il budget di un worker
+	GetByWorker(ctx context.Context, worker string) (*models.WorkerBudget, error)
+
+	// List recupera tutti i budget
+	List(ctx context.Context) ([]*models.WorkerBudget, error)
+
+	// AddRealizedPnL somma pnl al PnL realizzato del worker
+	AddRealizedPnL(ctx context.Context, worker string, pnl float64) error
+}
+
+// CashFlowRepository definisce l'interfaccia per i depositi e prelievi rilevati sugli exchange
+type CashFlowRepository interface {
+	// CreateBatch inserisce più movimenti ignorando quelli già registrati
+	CreateBatch(ctx context.Context, flows []*models.CashFlow) error
+
+	// GetLatest recupera l'ultimo movimento registrato di un exchange
+	GetLatest(ctx context.Context, exchange string) (*models.CashFlow, error)
+
+	// GetByDateRange recupera i movimenti in un range di date
+	GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.CashFlow, error)
+}
+
+// AccountSettingsRepository definisce l'interfaccia per la configurazione degli account e le commissioni delle venue
+type AccountSettingsRepository interface {
+	// SaveSettings crea o sostituisce la configurazione dell'account di una venue
+	SaveSettings(ctx context.Context, settings *models.AccountSettings) error
+
+	// ListSettings recupera la configurazione degli account di tutte le venue
+	ListSettings(ctx context.Context) ([]*models.AccountSettings, error)
+
+	// SaveFeeRate crea o sostituisce le commissioni di un simbolo su una venue
+	SaveFeeRate(ctx context.Context, rate *models.FeeRate) error
+
+	// ListFeeRates recupera tutte le commissioni salvate
+	ListFeeRates(ctx context.Context) ([]*models.FeeRate, error)
+}
+
+// SubAccountRepository definisce l'interfaccia per i sub-account assegnati alle strategie
+type SubAccountRepository interface {
+	// Save crea l'associazione della strategia o aggiorna quella esistente
+	Save(ctx context.Context, account *models.StrategySubAccount) error
+
+	// GetByStrategy recupera il sub-account associato a una strategia
+	GetByStrategy(ctx context.Context, strategy string) (*models.StrategySubAccount, error)
+
+	// List recupera tutte le associazioni
+	List(ctx context.Context) ([]*models.StrategySubAccount, error)
+}
+
+// RebalanceTransferRepository definisce l'interfaccia per i trasferimenti del ribilanciamento del portafoglio
+type RebalanceTransferRepository interface {
+	// Create registra un trasferimento
+	Create(ctx context.Context, transfer *models.RebalanceTransfer) error
+
+	// ListRecent recupera gli ultimi trasferimenti, dal più recente
+	ListRecent(ctx context.Context, limit int) ([]*models.RebalanceTransfer, error)
+}
+
+// QueryRepository esegue le query in sola lettura della console su tabelle e colonne in whitelist
+type QueryRepository interface {
+	// Tables restituisce tabelle e colonne interrogabili
+	Tables() map[string][]string
+
+	// Run valida ed esegue una query della console; gli errori di validazione avvolgono ErrInvalidQuery
+	Run(ctx context.Context, query ConsoleQuery) (*QueryResult, error)
+}
+
+// SlicedOrderRepository definisce l'interfaccia per gli ingressi frazionati in più ordini figli
+type SlicedOrderRepository interface {
+	// Create crea un nuovo ordine frazionato
+	Create(ctx context.Context, order *models.SlicedOrder) error
+
+	// Update aggiorna un ordine frazionato esistente
+	Update(ctx context.Context, order *models.SlicedOrder) error
+
+	// GetByID recupera un ordine frazionato per ID
+	GetByID(ctx context.Context, id uint) (*models.SlicedOrder, error)
+
+	// GetByParentOrderID recupera l'ordine frazionato di un ingresso
+	GetByParentOrderID(ctx context.Context, parentOrderID string) (*models.SlicedOrder, error)
+
+	// AddSlice salva un ordine figlio e aggiorna l'ordine frazionato nella stessa transazione
+	AddSlice(ctx context.Context, order *models.SlicedOrder, slice *models.OrderSlice) error
+
+	// GetSlices recupera gli ordini figli di un ordine frazionato, in ordine di invio
+	GetSlices(ctx context.Context, slicedOrderID uint) ([]*models.OrderSlice, error)
+}
+
 // RepositoryManager gestisce tutti i repository
 type RepositoryManager interface {
 	// OrderStatus restituisce il repository per gli stati ordine
@@ -174,6 +530,69 @@ type RepositoryManager interface {
 	// OrderAudit restituisce il repository per l'audit trail
 	OrderAudit() OrderAuditRepository
 
+	// OperatorAudit restituisce il repository per l'audit delle azioni manuali
+	OperatorAudit() OperatorAuditRepository
+
+	// SignalDecision restituisce il repository per le spiegazioni dei segnali
+	SignalDecision() SignalDecisionRepository
+
+	// BalanceSnapshot restituisce il repository per gli snapshot di equity
+	BalanceSnapshot() BalanceSnapshotRepository
+
+	// OrderTag restituisce il repository per i tag degli ordini
+	OrderTag() OrderTagRepository
+
+	// Execution restituisce il repository per le esecuzioni
+	Execution() ExecutionRepository
+
+	// OrderArchive restituisce il repository per l'archivio degli ordini
+	OrderArchive() OrderArchiveRepository
+
+	// FundingArb restituisce il repository per le posizioni di funding arbitrage
+	FundingArb() FundingArbRepository
+
+	// BasisSnapshot restituisce il repository per le rilevazioni di basis
+	BasisSnapshot() BasisSnapshotRepository
+
+	// PositionPnL restituisce il repository per gli snapshot del PnL delle posizioni
+	PositionPnL() PositionPnLRepository
+
+	// DataPoint restituisce il repository per le rilevazioni delle sorgenti dati esterne
+	DataPoint() DataPointRepository
+
+	// Candle restituisce il repository per la cache delle candele
+	Candle() CandleRepository
+
+	// ConfigVersion restituisce il repository per le versioni della configurazione
+	ConfigVersion() ConfigVersionRepository
+
+	// ScheduledJob restituisce il repository per la coda dei job differiti
+	ScheduledJob() ScheduledJobRepository
+
+	// Lock restituisce il repository per i lease tra istanze
+	Lock() LockRepository
+
+	// WorkerBudget restituisce il repository per i budget virtuali dei worker
+	WorkerBudget() WorkerBudgetRepository
+
+	// CashFlow restituisce il repository per depositi e prelievi
+	CashFlow() CashFlowRepository
+
+	// SlicedOrder restituisce il repository per gli ingressi frazionati
+	SlicedOrder() SlicedOrderRepository
+
+	// AccountSettings restituisce il repository per la configurazione degli account e le commissioni
+	AccountSettings() AccountSettingsRepository
+
+	// SubAccount restituisce il repository per i sub-account delle strategie
+	SubAccount() SubAccountRepository
+
+	// RebalanceTransfer restituisce il repository per i trasferimenti del ribilanciamento
+	RebalanceTransfer() RebalanceTransferRepository
+
+	// Query restituisce il repository per la console delle query
+	Query() QueryRepository
+
 	// BeginTransaction inizia una transazione
 	BeginTransaction(ctx context.Context) (*gorm.DB, error)
 
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lockRepository implementa LockRepository
+type lockRepository struct {
+	db *gorm.DB
+}
+
+// NewLockRepository crea una nuova istanza di LockRepository
+func NewLockRepository(db *gorm.DB) LockRepository {
+	return &lockRepository{db: db}
+}
+
+// TryAcquire acquisisce il lease di key per owner, o lo rinnova se owner lo detiene già
+// Con un unico upsert condizionato: il lease di un'altra istanza viene sovrascritto solo se scaduto
+func (r *lockRepository) TryAcquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	result := r.db.WithContext(ctx).Exec(`
+		INSERT INTO distributed_locks (key, owner, expires_at, acquired_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			owner = excluded.owner,
+			expires_at = excluded.expires_at,
+			acquired_at = CASE WHEN distributed_locks.owner = excluded.owner THEN distributed_locks.acquired_at ELSE excluded.acquired_at END,
+			updated_at = excluded.updated_at
+		WHERE distributed_locks.owner = excluded.owner OR distributed_locks.expires_at < ?`,
+		key, owner, now.Add(ttl), now, now, now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Release rilascia il lease se detenuto da owner
+func (r *lockRepository) Release(ctx context.Context, key, owner string) error {
+	return r.db.WithContext(ctx).
+		Where("key = ? AND owner = ?", key, owner).
+		Delete(&models.DistributedLock{}).Error
+}
+
+// Get recupera il lease corrente di key
+func (r *lockRepository) Get(ctx context.Context, key string) (*models.DistributedLock, error) {
+	var lock models.DistributedLock
+	if err := r.db.WithContext(ctx).Where("key = ?", key).First(&lock).Error; err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
@@ -13,15 +13,57 @@ type repositoryManager struct {
 	orderStatusRepo OrderStatusRepository
 	orderRepo       OrderRepository
 	orderAuditRepo  OrderAuditRepository
+	operatorRepo    OperatorAuditRepository
+	signalRepo      SignalDecisionRepository
+	snapshotRepo    BalanceSnapshotRepository
+	orderTagRepo    OrderTagRepository
+	executionRepo   ExecutionRepository
+	archiveRepo     OrderArchiveRepository
+	fundingArbRepo  FundingArbRepository
+	basisRepo       BasisSnapshotRepository
+	pnlRepo         PositionPnLRepository
+	dataPointRepo   DataPointRepository
+	candleRepo      CandleRepository
+	configRepo      ConfigVersionRepository
+	jobRepo         ScheduledJobRepository
+	lockRepo        LockRepository
+	budgetRepo      WorkerBudgetRepository
+	cashFlowRepo    CashFlowRepository
+	slicedRepo      SlicedOrderRepository
+	accountRepo     AccountSettingsRepository
+	subAccountRepo  SubAccountRepository
+	rebalanceRepo   RebalanceTransferRepository
+	queryRepo       QueryRepository
 }
 
 // NewRepositoryManager crea una nuova istanza di RepositoryManager
 func NewRepositoryManager(db *gorm.DB) RepositoryManager {
 	return &repositoryManager{
 		db:              db,
-		orderStatusRepo: NewOrderStatusRepository(db),
+		orderStatusRepo: NewCachedOrderStatusRepository(NewOrderStatusRepository(db)),
 		orderRepo:       NewOrderRepository(db),
 		orderAuditRepo:  NewOrderAuditRepository(db),
+		operatorRepo:    NewOperatorAuditRepository(db),
+		signalRepo:      NewSignalDecisionRepository(db),
+		snapshotRepo:    NewBalanceSnapshotRepository(db),
+		orderTagRepo:    NewOrderTagRepository(db),
+		executionRepo:   NewExecutionRepository(db),
+		archiveRepo:     NewOrderArchiveRepository(db),
+		fundingArbRepo:  NewFundingArbRepository(db),
+		basisRepo:       NewBasisSnapshotRepository(db),
+		pnlRepo:         NewPositionPnLRepository(db),
+		dataPointRepo:   NewDataPointRepository(db),
+		candleRepo:      NewCandleRepository(db),
+		configRepo:      NewConfigVersionRepository(db),
+		jobRepo:         NewScheduledJobRepository(db),
+		lockRepo:        NewLockRepository(db),
+		budgetRepo:      NewWorkerBudgetRepository(db),
+		cashFlowRepo:    NewCashFlowRepository(db),
+		slicedRepo:      NewSlicedOrderRepository(db),
+		accountRepo:     NewAccountSettingsRepository(db),
+		subAccountRepo:  NewSubAccountRepository(db),
+		rebalanceRepo:   NewRebalanceTransferRepository(db),
+		queryRepo:       NewQueryRepository(db),
 	}
 }
 
@@ -40,6 +82,111 @@ func (rm *repositoryManager) OrderAudit() OrderAuditRepository {
 	return rm.orderAuditRepo
 }
 
+// OperatorAudit restituisce il repository per l'audit delle azioni manuali
+func (rm *repositoryManager) OperatorAudit() OperatorAuditRepository {
+	return rm.operatorRepo
+}
+
+// SignalDecision restituisce il repository per le spiegazioni dei segnali
+func (rm *repositoryManager) SignalDecision() SignalDecisionRepository {
+	return rm.signalRepo
+}
+
+// BalanceSnapshot restituisce il repository per gli snapshot di equity
+func (rm *repositoryManager) BalanceSnapshot() BalanceSnapshotRepository {
+	return rm.snapshotRepo
+}
+
+// OrderTag restituisce il repository per i tag degli ordini
+func (rm *repositoryManager) OrderTag() OrderTagRepository {
+	return rm.orderTagRepo
+}
+
+// Execution restituisce il repository per le esecuzioni
+func (rm *repositoryManager) Execution() ExecutionRepository {
+	return rm.executionRepo
+}
+
+// OrderArchive restituisce il repository per l'archivio degli ordini
+func (rm *repositoryManager) OrderArchive() OrderArchiveRepository {
+	return rm.archiveRepo
+}
+
+// FundingArb restituisce il repository per le posizioni di funding arbitrage
+func (rm *repositoryManager) FundingArb() FundingArbRepository {
+	return rm.fundingArbRepo
+}
+
+// BasisSnapshot restituisce il repository per le rilevazioni di basis
+func (rm *repositoryManager) BasisSnapshot() BasisSnapshotRepository {
+	return rm.basisRepo
+}
+
+// PositionPnL restituisce il repository per gli snapshot del PnL delle posizioni
+func (rm *repositoryManager) PositionPnL() PositionPnLRepository {
+	return rm.pnlRepo
+}
+
+// DataPoint restituisce il repository per le rilevazioni delle sorgenti dati esterne
+func (rm *repositoryManager) DataPoint() DataPointRepository {
+	return rm.dataPointRepo
+}
+
+// Candle restituisce il repository per la cache delle candele
+func (rm *repositoryManager) Candle() CandleRepository {
+	return rm.candleRepo
+}
+
+// ConfigVersion restituisce il repository per le versioni della configurazione
+func (rm *repositoryManager) ConfigVersion() ConfigVersionRepository {
+	return rm.configRepo
+}
+
+// ScheduledJob restituisce il repository per la coda dei job differiti
+func (rm *repositoryManager) ScheduledJob() ScheduledJobRepository {
+	return rm.jobRepo
+}
+
+// Lock restituisce il repository per i lease tra istanze
+func (rm *repositoryManager) Lock() LockRepository {
+	return rm.lockRepo
+}
+
+// WorkerBudget restituisce il repository per i budget virtuali dei worker
+func (rm *repositoryManager) WorkerBudget() WorkerBudgetRepository {
+	return rm.budgetRepo
+}
+
+// CashFlow restituisce il repository per depositi e prelievi
+func (rm *repositoryManager) CashFlow() CashFlowRepository {
+	return rm.cashFlowRepo
+}
+
+// SlicedOrder restituisce il repository per gli ingressi frazionati
+func (rm *repositoryManager) SlicedOrder() SlicedOrderRepository {
+	return rm.slicedRepo
+}
+
+// AccountSettings restituisce il repository per la configurazione degli account e le commissioni
+func (rm *repositoryManager) AccountSettings() AccountSettingsRepository {
+	return rm.accountRepo
+}
+
+// SubAccount restituisce il repository per i sub-account delle strategie
+func (rm *repositoryManager) SubAccount() SubAccountRepository {
+	return rm.subAccountRepo
+}
+
+// RebalanceTransfer restituisce il repository per i trasferimenti del ribilanciamento
+func (rm *repositoryManager) RebalanceTransfer() RebalanceTransferRepository {
+	return rm.rebalanceRepo
+}
+
+// Query restituisce il repository per la console delle query
+func (rm *repositoryManager) Query() QueryRepository {
+	return rm.queryRepo
+}
+
 // BeginTransaction inizia una transazione
 func (rm *repositoryManager) BeginTransaction(ctx context.Context) (*gorm.DB, error) {
 	if rm.db == nil {
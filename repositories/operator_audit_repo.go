@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OperatorAuditFilter contiene i filtri della ricerca nell'audit delle azioni manuali; i campi vuoti sono ignorati
+type OperatorAuditFilter struct {
+	Actor   string
+	Action  string // Corrispondenza parziale (es. "workers" trova tutte le azioni sui worker)
+	Channel models.OperatorChannel
+	From    time.Time // Inclusivo
+	To      time.Time // Esclusivo
+}
+
+// operatorAuditRepository implementa OperatorAuditRepository
+type operatorAuditRepository struct {
+	db *gorm.DB
+}
+
+// NewOperatorAuditRepository crea una nuova istanza di OperatorAuditRepository
+func NewOperatorAuditRepository(db *gorm.DB) OperatorAuditRepository {
+	return &operatorAuditRepository{db: db}
+}
+
+// Create registra un'azione manuale
+func (r *operatorAuditRepository) Create(ctx context.Context, audit *models.OperatorAudit) error {
+	return r.db.WithContext(ctx).Create(audit).Error
+}
+
+// Search recupera le azioni che soddisfano i filtri, dalla più recente
+func (r *operatorAuditRepository) Search(ctx context.Context, filter OperatorAuditFilter, limit, offset int) ([]*models.OperatorAudit, error) {
+	query := r.db.WithContext(ctx)
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action LIKE ?", "%"+filter.Action+"%")
+	}
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", sqliteTimestamp(filter.From))
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", sqliteTimestamp(filter.To))
+	}
+
+	var audits []*models.OperatorAudit
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&audits).Error; err != nil {
+		return nil, err
+	}
+	return audits, nil
+}
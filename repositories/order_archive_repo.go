@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// archivedOrderColumns elenca le colonne copiate da orders a orders_archive
+// Va aggiornato quando si aggiungono colonne a models.Order
+const archivedOrderColumns = "id, order_id, symbol, side, order_price, quantity, take_profit_price, stop_loss_price, " +
+	"order_status_id, result, pnl, pnl_percentage, parent_order_id, scale_level, signal_latency_ms, reject_reason, config_version, version, created_at, updated_at"
+
+// historicalOrders restituisce gli ordini attivi e quelli archiviati come un'unica tabella con alias orders
+// Le letture storiche (statistiche, ricerca, dataset) la usano al posto di orders per non perdere gli ordini archiviati;
+// l'ID originale è conservato in archivio, quindi resta univoco anche nell'unione
+func historicalOrders(db *gorm.DB) *gorm.DB {
+	hot := db.Model(&models.Order{}).Select(archivedOrderColumns)
+	archived := db.Model(&models.ArchivedOrder{}).Select(archivedOrderColumns)
+	return db.Table("(? UNION ALL ?) AS orders", hot, archived)
+}
+
+// archivableResults sono i risultati definitivi di un ordine: Done resta nella tabella attiva perché indica
+// un ingresso eseguito con la posizione ancora aperta, letto da pyramiding e slicing
+var archivableResults = []models.OrderResult{models.OrderResultProfit, models.OrderResultLoss, models.OrderResultRejected}
+
+// orderArchiveRepository implementa OrderArchiveRepository
+type orderArchiveRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderArchiveRepository crea una nuova istanza di OrderArchiveRepository
+func NewOrderArchiveRepository(db *gorm.DB) OrderArchiveRepository {
+	return &orderArchiveRepository{db: db}
+}
+
+// ArchiveClosedOrders sposta in archivio gli ordini chiusi (risultato Profit, Loss o Rejected)
+// aggiornati prima di closedBefore; lavora a blocchi di batchSize, una transazione per blocco
+func (r *orderArchiveRepository) ArchiveClosedOrders(ctx context.Context, closedBefore time.Time, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	var archived int64
+	for {
+		moved, err := r.archiveBatch(ctx, closedBefore.UTC(), batchSize)
+		if err != nil {
+			return archived, err
+		}
+		archived += moved
+
+		if moved < int64(batchSize) {
+			return archived, nil
+		}
+	}
+}
+
+// archiveBatch sposta un singolo blocco di ordini in archivio
+func (r *orderArchiveRepository) archiveBatch(ctx context.Context, closedBefore time.Time, batchSize int) (int64, error) {
+	var moved int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Model(&models.Order{}).
+			Where("result IN ? AND updated_at < ?", archivableResults, closedBefore).
+			Order("id ASC").Limit(batchSize).
+			Pluck("id", &ids).Error; err != nil {
+			return fmt.Errorf("failed to select closed orders: %w", err)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		insertSQL := fmt.Sprintf("INSERT INTO orders_archive (%s, archived_at) SELECT %s, ? FROM orders WHERE id IN ?",
+			archivedOrderColumns, archivedOrderColumns)
+		if err := tx.Exec(insertSQL, time.Now().UTC(), ids).Error; err != nil {
+			return fmt.Errorf("failed to copy orders to archive: %w", err)
+		}
+
+		result := tx.Where("id IN ?", ids).Delete(&models.Order{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete archived orders: %w", result.Error)
+		}
+
+		moved = result.RowsAffected
+		return nil
+	})
+
+	return moved, err
+}
+
+// GetByOrderID recupera un ordine archiviato per OrderID
+func (r *orderArchiveRepository) GetByOrderID(ctx context.Context, orderID string) (*models.ArchivedOrder, error) {
+	var order models.ArchivedOrder
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetBySymbol recupera ordini archiviati per simbolo
+func (r *orderArchiveRepository) GetBySymbol(ctx context.Context, symbol string, limit, offset int) ([]*models.ArchivedOrder, error) {
+	var orders []*models.ArchivedOrder
+	query := r.db.WithContext(ctx).Where("symbol = ?", symbol)
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	err := query.Order("created_at DESC").Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// Count conta gli ordini archiviati
+func (r *orderArchiveRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ArchivedOrder{}).Count(&count).Error
+	return count, err
+}
@@ -41,6 +41,25 @@ func (r *orderAuditRepository) GetByOrderID(ctx context.Context, orderID string,
 	return audits, nil
 }
 
+// GetByCorrelationID recupera in ordine cronologico i record di audit di un ciclo o di una richiesta
+func (r *orderAuditRepository) GetByCorrelationID(ctx context.Context, correlationID string, limit, offset int) ([]*models.OrderAudit, error) {
+	var audits []*models.OrderAudit
+	query := r.db.WithContext(ctx).Where("correlation_id = ?", correlationID)
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	err := query.Order("changed_at ASC, id ASC").Find(&audits).Error
+	if err != nil {
+		return nil, err
+	}
+	return audits, nil
+}
+
 // GetByFieldName recupera record di audit per campo
 func (r *orderAuditRepository) GetByFieldName(ctx context.Context, fieldName string, limit, offset int) ([]*models.OrderAudit, error) {
 	var audits []*models.OrderAudit
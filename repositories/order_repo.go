@@ -3,10 +3,49 @@ package repositories
 import (
 	"context"
 	"cross-exchange-arbitrage/models"
+	"errors"
+	"fmt"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// defaultBatchSize è la dimensione di default dei blocchi per gli inserimenti multipli
+const defaultBatchSize = 100
+
+// ErrVersionConflict indica che l'ordine è stato modificato da un altro processo dopo essere stato letto
+var ErrVersionConflict = errors.New("order was modified concurrently")
+
+// nextVersion è l'espressione per incrementare la versione negli aggiornamenti parziali
+var nextVersion = gorm.Expr("version + 1")
+
+// UpdateOrderWithVersion salva tutti i campi dell'ordine solo se la versione sul database
+// coincide con quella letta (optimistic locking) e incrementa la versione.
+// Accetta una transazione in modo da poter essere usata insieme ad altre scritture (es. audit)
+func UpdateOrderWithVersion(tx *gorm.DB, order *models.Order) error {
+	if order.ID == 0 {
+		return fmt.Errorf("cannot update order %s without ID", order.OrderID)
+	}
+
+	expected := order.Version
+	order.Version = expected + 1
+
+	result := tx.Model(order).
+		Where("version = ?", expected).
+		Select("*").Omit(clause.Associations, "id", "created_at").
+		Updates(order)
+	if result.Error != nil {
+		order.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		order.Version = expected
+		return fmt.Errorf("order %s (version %d): %w", order.OrderID, expected, ErrVersionConflict)
+	}
+
+	return nil
+}
+
 // orderRepository implementa OrderRepository
 type orderRepository struct {
 	db *gorm.DB
@@ -22,6 +61,20 @@ func (r *orderRepository) Create(ctx context.Context, order *models.Order) error
 	return r.db.WithContext(ctx).Create(order).Error
 }
 
+// CreateBatch crea più ordini in un'unica transazione, a blocchi di batchSize
+func (r *orderRepository) CreateBatch(ctx context.Context, orders []*models.Order, batchSize int) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(orders, batchSize).Error
+	})
+}
+
 // GetByID recupera un ordine per ID
 func (r *orderRepository) GetByID(ctx context.Context, id uint) (*models.Order, error) {
 	var order models.Order
@@ -61,6 +114,72 @@ func (r *orderRepository) GetAll(ctx context.Context, limit, offset int) ([]*mod
 	return orders, nil
 }
 
+// Search recupera una pagina di ordini che soddisfano tutti i filtri indicati, con paginazione keyset
+// (cursore) e ordinamento configurabile: a differenza di limit/offset il costo non cresce con la profondità.
+// La ricerca comprende gli ordini archiviati
+func (r *orderRepository) Search(ctx context.Context, filter OrderSearchFilter, pageQuery OrderPageQuery) (*OrderPage, error) {
+	sortBy, err := ParseOrderSortField(string(pageQuery.SortBy))
+	if err != nil {
+		return nil, err
+	}
+	direction, err := ParseSortDirection(string(pageQuery.Direction))
+	if err != nil {
+		return nil, err
+	}
+	limit := pageQuery.Limit
+	if limit <= 0 {
+		limit = defaultBatchSize
+	}
+
+	query := applyOrderSearchFilter(historicalOrders(r.db.WithContext(ctx)).Preload("OrderStatus"), filter)
+
+	// sortBy proviene dalla whitelist, quindi è sicuro interpolarlo nella query
+	comparison := "<"
+	if direction == SortAsc {
+		comparison = ">"
+	}
+	if pageQuery.Cursor != "" {
+		value, lastID, err := decodeOrderCursor(pageQuery.Cursor, sortBy)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(
+			fmt.Sprintf("((%[1]s %[2]s ?) OR (%[1]s = ? AND id %[2]s ?))", sortBy, comparison),
+			value, value, lastID,
+		)
+	}
+
+	// Un elemento in più per sapere se esiste una pagina successiva
+	var orders []*models.Order
+	err = query.
+		Order(fmt.Sprintf("%s %s, id %s", sortBy, direction, direction)).
+		Limit(limit + 1).
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	page := &OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		last := page.Orders[limit-1]
+
+		rawTime := ""
+		if orderSortFields[sortBy] {
+			// Il driver converte le colonne timestamp in time.Time: CAST restituisce il testo salvato
+			err := historicalOrders(r.db.WithContext(ctx)).
+				Select(fmt.Sprintf("CAST(%s AS TEXT)", sortBy)).
+				Where("id = ?", last.ID).
+				Scan(&rawTime).Error
+			if err != nil {
+				return nil, err
+			}
+		}
+		page.NextCursor = newOrderCursor(last, sortBy, rawTime)
+	}
+	return page, nil
+}
+
 // GetBySymbol recupera ordini per simbolo
 func (r *orderRepository) GetBySymbol(ctx context.Context, symbol string, limit, offset int) ([]*models.Order, error) {
 	var orders []*models.Order
@@ -183,6 +302,33 @@ func (r *orderRepository) GetBySymbolAndResult(ctx context.Context, symbol strin
 	return orders, nil
 }
 
+// GetLatestEntry recupera l'ultimo ingresso iniziale (non un incremento) ancora aperto per simbolo
+// Un ingresso è aperto finché il risultato è Pending o Done
+func (r *orderRepository) GetLatestEntry(ctx context.Context, symbol string) (*models.Order, error) {
+	var order models.Order
+	err := r.db.WithContext(ctx).Preload("OrderStatus").
+		Where("symbol = ? AND scale_level = 0 AND result IN ?", symbol, []models.OrderResult{models.OrderResultPending, models.OrderResultDone}).
+		Order("created_at DESC").
+		First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetByParentOrderID recupera in ordine di livello gli incrementi collegati a un ingresso
+func (r *orderRepository) GetByParentOrderID(ctx context.Context, parentOrderID string) ([]*models.Order, error) {
+	var orders []*models.Order
+	err := r.db.WithContext(ctx).Preload("OrderStatus").
+		Where("parent_order_id = ?", parentOrderID).
+		Order("scale_level ASC").
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
 // GetByDateRange recupera ordini in un range di date
 func (r *orderRepository) GetByDateRange(ctx context.Context, startDate, endDate string, limit, offset int) ([]*models.Order, error) {
 	var orders []*models.Order
@@ -204,22 +350,55 @@ func (r *orderRepository) GetByDateRange(ctx context.Context, startDate, endDate
 }
 
 // Update aggiorna un ordine esistente
+// Restituisce ErrVersionConflict se l'ordine è stato modificato dopo la lettura
 func (r *orderRepository) Update(ctx context.Context, order *models.Order) error {
-	return r.db.WithContext(ctx).Save(order).Error
+	return UpdateOrderWithVersion(r.db.WithContext(ctx), order)
 }
 
-// UpdateStatus aggiorna solo lo stato di un ordine
-func (r *orderRepository) UpdateStatus(ctx context.Context, orderID string, statusID uint) error {
-	return r.db.WithContext(ctx).Model(&models.Order{}).
-		Where("order_id = ?", orderID).
-		Update("order_status_id", statusID).Error
+// UpdateBatch aggiorna più ordini esistenti in un'unica transazione
+// SQLite non supporta UPDATE multi-riga con valori diversi: la transazione unica evita un commit per ordine
+func (r *orderRepository) UpdateBatch(ctx context.Context, orders []*models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, order := range orders {
+			if err := UpdateOrderWithVersion(tx, order); err != nil {
+				return fmt.Errorf("failed to update order %s: %w", order.OrderID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateStatus aggiorna solo lo stato di un ordine se la versione sul database coincide con quella letta,
+// così una transizione validata sullo stato letto non sovrascrive una transizione concorrente.
+// Restituisce ErrVersionConflict se l'ordine è stato modificato dopo la lettura
+func (r *orderRepository) UpdateStatus(ctx context.Context, orderID string, version, statusID uint) error {
+	result := r.db.WithContext(ctx).Model(&models.Order{}).
+		Where("order_id = ? AND version = ?", orderID, version).
+		Updates(map[string]interface{}{
+			"order_status_id": statusID,
+			"version":         nextVersion,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("order %s (version %d): %w", orderID, version, ErrVersionConflict)
+	}
+	return nil
 }
 
 // UpdateResult aggiorna solo il risultato di un ordine
 func (r *orderRepository) UpdateResult(ctx context.Context, orderID string, result models.OrderResult) error {
 	return r.db.WithContext(ctx).Model(&models.Order{}).
 		Where("order_id = ?", orderID).
-		Update("result", result).Error
+		Updates(map[string]interface{}{
+			"result":  result,
+			"version": nextVersion,
+		}).Error
 }
 
 // UpdatePnL aggiorna PnL e PnL percentage di un ordine
@@ -229,12 +408,13 @@ func (r *orderRepository) UpdatePnL(ctx context.Context, orderID string, pnl, pn
 		Updates(map[string]interface{}{
 			"pnl":            pnl,
 			"pnl_percentage": pnlPercentage,
+			"version":        nextVersion,
 		}).Error
 }
 
 // UpdatePrices aggiorna prezzi take profit e stop loss
 func (r *orderRepository) UpdatePrices(ctx context.Context, orderID string, takeProfit, stopLoss *float64) error {
-	updates := map[string]interface{}{}
+	updates := map[string]interface{}{"version": nextVersion}
 	if takeProfit != nil {
 		updates["take_profit_price"] = *takeProfit
 	}
@@ -292,11 +472,12 @@ func (r *orderRepository) CountByResult(ctx context.Context, result models.Order
 	return count, err
 }
 
-// GetTradingStats recupera statistiche di trading
+// GetTradingStats recupera statistiche di trading, compresi gli ordini archiviati
 func (r *orderRepository) GetTradingStats(ctx context.Context, symbol string) (*TradingStats, error) {
 	var stats TradingStats
 
-	query := r.db.WithContext(ctx).Model(&models.Order{})
+	// Gli ordini rifiutati non sono mai stati eseguiti e non entrano nelle statistiche di trading
+	query := historicalOrders(r.db.WithContext(ctx)).Where("result <> ?", models.OrderResultRejected)
 	if symbol != "" {
 		query = query.Where("symbol = ?", symbol)
 	}
@@ -327,11 +508,31 @@ func (r *orderRepository) GetTradingStats(ctx context.Context, symbol string) (*
 	return &stats, nil
 }
 
-// GetPnLStats recupera statistiche PnL
+// GetRejectStats conta gli ordini rifiutati raggruppati per motivo, dal più frequente
+// Comprende gli ordini già spostati in archivio, che conservano il motivo del rifiuto
+func (r *orderRepository) GetRejectStats(ctx context.Context, symbol string) ([]*RejectStats, error) {
+	var stats []*RejectStats
+
+	hot := r.db.Model(&models.Order{}).Select("reject_reason AS reason").Where("result = ?", models.OrderResultRejected)
+	archived := r.db.Model(&models.ArchivedOrder{}).Select("reject_reason AS reason").Where("result = ?", models.OrderResultRejected)
+	if symbol != "" {
+		hot = hot.Where("symbol = ?", symbol)
+		archived = archived.Where("symbol = ?", symbol)
+	}
+
+	err := r.db.WithContext(ctx).Table("(? UNION ALL ?) AS rejected", hot, archived).
+		Select("reason, COUNT(*) AS count").Group("reason").Order("count DESC").Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetPnLStats recupera statistiche PnL, compresi gli ordini archiviati
 func (r *orderRepository) GetPnLStats(ctx context.Context, symbol string) (*PnLStats, error) {
 	var stats PnLStats
 
-	query := r.db.WithContext(ctx).Model(&models.Order{})
+	query := historicalOrders(r.db.WithContext(ctx)).Where("result <> ?", models.OrderResultRejected)
 	if symbol != "" {
 		query = query.Where("symbol = ?", symbol)
 	}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"cross-exchange-arbitrage/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OrderSearchFilter contiene i filtri per la ricerca degli ordini; i campi vuoti/nil vengono ignorati
+type OrderSearchFilter struct {
+	Symbol     string               // Simbolo esatto (es. DOGEUSDT)
+	Side       models.OrderSideType // Buy o Sell
+	Result     models.OrderResult   // Profit, Loss, Pending
+	StatusName string               // Nome dello stato (es. Filled)
+	From       *time.Time           // Creati a partire da (incluso)
+	To         *time.Time           // Creati prima di (escluso)
+	MinPnL     *float64             // PnL minimo (incluso)
+	MaxPnL     *float64             // PnL massimo (incluso)
+	Tag        string               // Tag associato all'ordine
+	ConfigHash string               // Versione della configurazione (hash o suo prefisso)
+	Text       string               // Ricerca libera su OrderID (prefisso) e note dei tag
+}
+
+// sqliteTimestamp formatta un istante come CURRENT_TIMESTAMP di SQLite, per confronti testuali coerenti
+func sqliteTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// applyOrderSearchFilter aggiunge alla query le condizioni del filtro
+// Le condizioni sono coperte dagli indici compositi creati in database.createIndexes
+func applyOrderSearchFilter(query *gorm.DB, filter OrderSearchFilter) *gorm.DB {
+	if filter.Symbol != "" {
+		query = query.Where("orders.symbol = ?", filter.Symbol)
+	}
+	if filter.Side != "" {
+		query = query.Where("orders.side = ?", filter.Side)
+	}
+	if filter.Result != "" {
+		query = query.Where("orders.result = ?", filter.Result)
+	}
+	if filter.StatusName != "" {
+		query = query.Where("orders.order_status_id IN (SELECT id FROM order_statuses WHERE status_name = ?)", filter.StatusName)
+	}
+	if filter.From != nil {
+		query = query.Where("orders.created_at >= ?", sqliteTimestamp(*filter.From))
+	}
+	if filter.To != nil {
+		query = query.Where("orders.created_at < ?", sqliteTimestamp(*filter.To))
+	}
+	if filter.MinPnL != nil {
+		query = query.Where("orders.pnl >= ?", *filter.MinPnL)
+	}
+	if filter.MaxPnL != nil {
+		query = query.Where("orders.pnl <= ?", *filter.MaxPnL)
+	}
+	if filter.ConfigHash != "" {
+		query = query.Where("orders.config_version LIKE ? ESCAPE '\\'", escapeLike(filter.ConfigHash)+"%")
+	}
+	if tag := models.NormalizeTag(filter.Tag); tag != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM order_tags WHERE order_tags.order_id = orders.order_id AND order_tags.tag = ?)", tag)
+	}
+	if text := strings.TrimSpace(filter.Text); text != "" {
+		pattern := escapeLike(text)
+		query = query.Where(
+			"(orders.order_id LIKE ? ESCAPE '\\' OR EXISTS (SELECT 1 FROM order_tags WHERE order_tags.order_id = orders.order_id AND order_tags.note LIKE ? ESCAPE '\\'))",
+			pattern+"%", "%"+pattern+"%",
+		)
+	}
+	return query
+}
+
+// escapeLike esegue l'escape dei caratteri speciali di LIKE
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
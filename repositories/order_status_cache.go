@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"errors"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// cachedOrderStatusRepository implementa OrderStatusRepository con una cache in memoria
+// Gli stati ordine cambiano raramente: vengono caricati tutti al primo accesso
+// e la cache viene invalidata ad ogni scrittura
+type cachedOrderStatusRepository struct {
+	repo   OrderStatusRepository
+	mutex  sync.RWMutex
+	loaded bool
+	byID   map[uint]*models.OrderStatusEntity
+	byName map[string]*models.OrderStatusEntity
+	all    []*models.OrderStatusEntity
+}
+
+// NewCachedOrderStatusRepository crea un OrderStatusRepository con cache davanti al repository dato
+func NewCachedOrderStatusRepository(repo OrderStatusRepository) OrderStatusRepository {
+	return &cachedOrderStatusRepository{repo: repo}
+}
+
+// Create crea un nuovo stato ordine e invalida la cache
+func (r *cachedOrderStatusRepository) Create(ctx context.Context, orderStatus *models.OrderStatusEntity) error {
+	defer r.invalidate()
+	return r.repo.Create(ctx, orderStatus)
+}
+
+// GetByID recupera uno stato ordine per ID dalla cache
+func (r *cachedOrderStatusRepository) GetByID(ctx context.Context, id uint) (*models.OrderStatusEntity, error) {
+	if err := r.load(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	status, found := r.byID[id]
+	r.mutex.RUnlock()
+	if found {
+		return copyOrderStatus(status), nil
+	}
+
+	// Stato inserito fuori dal repository: ricarica la cache al prossimo accesso
+	return r.missByID(ctx, id)
+}
+
+// GetByStatusName recupera uno stato ordine per nome dalla cache
+func (r *cachedOrderStatusRepository) GetByStatusName(ctx context.Context, statusName string) (*models.OrderStatusEntity, error) {
+	if err := r.load(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	status, found := r.byName[statusName]
+	r.mutex.RUnlock()
+	if found {
+		return copyOrderStatus(status), nil
+	}
+
+	status, err := r.repo.GetByStatusName(ctx, statusName)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate()
+	return status, nil
+}
+
+// GetAll recupera tutti gli stati ordine dalla cache
+func (r *cachedOrderStatusRepository) GetAll(ctx context.Context) ([]*models.OrderStatusEntity, error) {
+	if err := r.load(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	statuses := make([]*models.OrderStatusEntity, 0, len(r.all))
+	for _, status := range r.all {
+		statuses = append(statuses, copyOrderStatus(status))
+	}
+	return statuses, nil
+}
+
+// GetActive recupera solo gli stati attivi dalla cache
+func (r *cachedOrderStatusRepository) GetActive(ctx context.Context) ([]*models.OrderStatusEntity, error) {
+	if err := r.load(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	statuses := make([]*models.OrderStatusEntity, 0, len(r.all))
+	for _, status := range r.all {
+		if status.IsActive {
+			statuses = append(statuses, copyOrderStatus(status))
+		}
+	}
+	return statuses, nil
+}
+
+// Update aggiorna uno stato ordine esistente e invalida la cache
+func (r *cachedOrderStatusRepository) Update(ctx context.Context, orderStatus *models.OrderStatusEntity) error {
+	defer r.invalidate()
+	return r.repo.Update(ctx, orderStatus)
+}
+
+// Delete elimina uno stato ordine e invalida la cache
+func (r *cachedOrderStatusRepository) Delete(ctx context.Context, id uint) error {
+	defer r.invalidate()
+	return r.repo.Delete(ctx, id)
+}
+
+// Exists verifica se uno stato ordine esiste
+func (r *cachedOrderStatusRepository) Exists(ctx context.Context, id uint) (bool, error) {
+	_, err := r.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// load carica tutti gli stati in cache se non ancora presenti
+func (r *cachedOrderStatusRepository) load(ctx context.Context) error {
+	r.mutex.RLock()
+	loaded := r.loaded
+	r.mutex.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	statuses, err := r.repo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.byID = make(map[uint]*models.OrderStatusEntity, len(statuses))
+	r.byName = make(map[string]*models.OrderStatusEntity, len(statuses))
+	for _, status := range statuses {
+		r.byID[status.ID] = status
+		r.byName[status.StatusName] = status
+	}
+	r.all = statuses
+	r.loaded = true
+	return nil
+}
+
+// missByID gestisce un ID non presente in cache interrogando il database
+func (r *cachedOrderStatusRepository) missByID(ctx context.Context, id uint) (*models.OrderStatusEntity, error) {
+	status, err := r.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate()
+	return status, nil
+}
+
+// invalidate svuota la cache, che verrà ricaricata al prossimo accesso
+func (r *cachedOrderStatusRepository) invalidate() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.loaded = false
+	r.byID = nil
+	r.byName = nil
+	r.all = nil
+}
+
+// copyOrderStatus restituisce una copia dello stato per evitare modifiche alla cache da parte dei chiamanti
+func copyOrderStatus(status *models.OrderStatusEntity) *models.OrderStatusEntity {
+	copied := *status
+	return &copied
+}
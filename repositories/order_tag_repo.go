@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+
+	"gorm.io/gorm"
+)
+
+// orderTagRepository implementa OrderTagRepository
+type orderTagRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderTagRepository crea una nuova istanza di OrderTagRepository
+func NewOrderTagRepository(db *gorm.DB) OrderTagRepository {
+	return &orderTagRepository{db: db}
+}
+
+// Create crea un nuovo tag
+func (r *orderTagRepository) Create(ctx context.Context, tag *models.OrderTag) error {
+	return r.db.WithContext(ctx).Create(tag).Error
+}
+
+// GetByID recupera un tag per ID
+func (r *orderTagRepository) GetByID(ctx context.Context, id uint) (*models.OrderTag, error) {
+	var tag models.OrderTag
+	err := r.db.WithContext(ctx).First(&tag, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetByOrderID recupera tutti i tag di un ordine
+func (r *orderTagRepository) GetByOrderID(ctx context.Context, orderID string) ([]*models.OrderTag, error) {
+	var tags []*models.OrderTag
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).
+		Order("created_at ASC").Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetByOrderIDs recupera i tag di più ordini raggruppati per OrderID
+func (r *orderTagRepository) GetByOrderIDs(ctx context.Context, orderIDs []string) (map[string][]*models.OrderTag, error) {
+	result := make(map[string][]*models.OrderTag)
+	if len(orderIDs) == 0 {
+		return result, nil
+	}
+
+	var tags []*models.OrderTag
+	err := r.db.WithContext(ctx).Where("order_id IN ?", orderIDs).
+		Order("created_at ASC").Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range tags {
+		result[tag.OrderID] = append(result[tag.OrderID], tag)
+	}
+	return result, nil
+}
+
+// GetByTag recupera i record con un determinato tag
+func (r *orderTagRepository) GetByTag(ctx context.Context, tag string, limit, offset int) ([]*models.OrderTag, error) {
+	var tags []*models.OrderTag
+	query := r.db.WithContext(ctx).Where("tag = ?", models.NormalizeTag(tag))
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	err := query.Order("created_at DESC").Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Delete elimina un tag
+func (r *orderTagRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.OrderTag{}, id).Error
+}
+
+// GetTagStats recupera le statistiche di trading raggruppate per tag, compresi gli ordini archiviati
+func (r *orderTagRepository) GetTagStats(ctx context.Context, symbol string) ([]*TagStats, error) {
+	var stats []*TagStats
+
+	query := r.db.WithContext(ctx).Table("order_tags").
+		Joins("JOIN (?) AS orders ON orders.order_id = order_tags.order_id", historicalOrders(r.db)).
+		Where("order_tags.tag <> ''")
+	if symbol != "" {
+		query = query.Where("orders.symbol = ?", symbol)
+	}
+
+	err := query.Select(`
+		order_tags.tag as tag,
+		COUNT(DISTINCT orders.id) as total_orders,
+		SUM(CASE WHEN orders.result = ? THEN 1 ELSE 0 END) as profitable_orders,
+		SUM(CASE WHEN orders.result = ? THEN 1 ELSE 0 END) as losing_orders,
+		SUM(orders.pnl) as total_pnl,
+		AVG(orders.pnl) as avg_pnl,
+		AVG(orders.pnl_percentage) as avg_pnl_percentage
+	`, models.OrderResultProfit, models.OrderResultLoss).
+		Group("order_tags.tag").
+		Order("total_pnl DESC").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Calcola win rate
+	for _, s := range stats {
+		if s.TotalOrders > 0 {
+			s.WinRate = float64(s.ProfitableOrders) / float64(s.TotalOrders) * 100
+		}
+	}
+
+	return stats, nil
+}
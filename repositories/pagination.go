@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"cross-exchange-arbitrage/models"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidCursor indica un cursore di paginazione malformato o non coerente con l'ordinamento richiesto
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// OrderSortField rappresenta un campo su cui è consentito ordinare gli ordini
+type OrderSortField string
+
+const (
+	OrderSortCreatedAt  OrderSortField = "created_at"
+	OrderSortUpdatedAt  OrderSortField = "updated_at"
+	OrderSortPnL        OrderSortField = "pnl"
+	OrderSortOrderPrice OrderSortField = "order_price"
+)
+
+// orderSortFields è la whitelist dei campi ordinabili (il valore indica se il campo è temporale)
+var orderSortFields = map[OrderSortField]bool{
+	OrderSortCreatedAt:  true,
+	OrderSortUpdatedAt:  true,
+	OrderSortPnL:        false,
+	OrderSortOrderPrice: false,
+}
+
+// SortDirection rappresenta la direzione di ordinamento
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+// ParseOrderSortField valida il nome di un campo di ordinamento (vuoto = created_at)
+func ParseOrderSortField(value string) (OrderSortField, error) {
+	if value == "" {
+		return OrderSortCreatedAt, nil
+	}
+	field := OrderSortField(strings.ToLower(value))
+	if _, ok := orderSortFields[field]; !ok {
+		return "", fmt.Errorf("unsupported sort field: %s", value)
+	}
+	return field, nil
+}
+
+// ParseSortDirection valida la direzione di ordinamento (vuoto = desc)
+func ParseSortDirection(value string) (SortDirection, error) {
+	switch SortDirection(strings.ToLower(value)) {
+	case "", SortDesc:
+		return SortDesc, nil
+	case SortAsc:
+		return SortAsc, nil
+	default:
+		return "", fmt.Errorf("unsupported sort direction: %s", value)
+	}
+}
+
+// OrderPageQuery contiene i parametri per la paginazione keyset degli ordini
+type OrderPageQuery struct {
+	SortBy    OrderSortField // Campo di ordinamento (default created_at)
+	Direction SortDirection  // Direzione (default desc)
+	Limit     int            // Numero massimo di ordini per pagina
+	Cursor    string         // Cursore restituito dalla pagina precedente (vuoto = prima pagina)
+}
+
+// OrderPage rappresenta una pagina di ordini
+type OrderPage struct {
+	Orders     []*models.Order `json:"orders"`
+	NextCursor string          `json:"next_cursor,omitempty"` // Vuoto se non ci sono altre pagine
+}
+
+// orderCursor è la posizione dell'ultimo ordine restituito: valore del campo di ordinamento + ID
+// L'ID rende l'ordinamento totale anche quando più ordini hanno lo stesso valore.
+// I timestamp sono conservati come testo grezzo di SQLite (CURRENT_TIMESTAMP e GORM usano formati
+// diversi): confrontare lo stesso testo garantisce coerenza con l'ORDER BY
+type orderCursor struct {
+	SortBy OrderSortField `json:"s"`
+	Text   *string        `json:"t,omitempty"`
+	Number *float64       `json:"n,omitempty"`
+	ID     uint           `json:"id"`
+}
+
+// newOrderCursor crea il cursore che punta dopo l'ordine indicato
+// rawTime è il valore testuale del campo temporale di ordinamento (ignorato per i campi numerici)
+func newOrderCursor(order *models.Order, sortBy OrderSortField, rawTime string) string {
+	cursor := orderCursor{SortBy: sortBy, ID: order.ID}
+
+	switch sortBy {
+	case OrderSortCreatedAt, OrderSortUpdatedAt:
+		cursor.Text = &rawTime
+	case OrderSortPnL:
+		cursor.Number = &order.PnL
+	case OrderSortOrderPrice:
+		cursor.Number = &order.OrderPrice
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeOrderCursor decodifica un cursore verificando che sia coerente con il campo di ordinamento
+// Restituisce il valore da usare nella condizione keyset e l'ID dell'ultimo ordine
+func decodeOrderCursor(encoded string, sortBy OrderSortField) (interface{}, uint, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+
+	var cursor orderCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	if cursor.SortBy != sortBy {
+		return nil, 0, fmt.Errorf("%w: cursor was created for sort field %s", ErrInvalidCursor, cursor.SortBy)
+	}
+
+	if orderSortFields[sortBy] {
+		if cursor.Text == nil {
+			return nil, 0, ErrInvalidCursor
+		}
+		return *cursor.Text, cursor.ID, nil
+	}
+	if cursor.Number == nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	return *cursor.Number, cursor.ID, nil
+}
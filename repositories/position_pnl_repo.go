@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// positionPnLRepository implementa PositionPnLRepository
+type positionPnLRepository struct {
+	db *gorm.DB
+}
+
+// NewPositionPnLRepository crea una nuova istanza di PositionPnLRepository
+func NewPositionPnLRepository(db *gorm.DB) PositionPnLRepository {
+	return &positionPnLRepository{db: db}
+}
+
+// Create salva uno snapshot del PnL di una posizione
+func (r *positionPnLRepository) Create(ctx context.Context, snapshot *models.PositionPnL) error {
+	return r.db.WithContext(ctx).Create(snapshot).Error
+}
+
+// GetSeries recupera gli snapshot di un simbolo in un range di date, in ordine cronologico
+func (r *positionPnLRepository) GetSeries(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*models.PositionPnL, error) {
+	var snapshots []*models.PositionPnL
+	err := r.db.WithContext(ctx).
+		Where("symbol = ? AND taken_at >= ? AND taken_at <= ?", symbol, startDate.UTC(), endDate.UTC()).
+		Order("taken_at ASC, id ASC").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// DeleteBefore elimina gli snapshot precedenti a before e restituisce quanti ne ha eliminati
+func (r *positionPnLRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("taken_at < ?", before.UTC()).
+		Delete(&models.PositionPnL{})
+	return result.RowsAffected, result.Error
+}
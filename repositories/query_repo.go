@@ -0,0 +1,307 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidQuery indica una query della console non consentita o malformata
+var ErrInvalidQuery = errors.New("invalid query")
+
+const (
+	// defaultQueryLimit è il numero di righe restituite quando la query non indica un limite
+	defaultQueryLimit = 100
+
+	// maxQueryLimit è il numero massimo di righe restituite da una query della console
+	maxQueryLimit = 1000
+)
+
+// queryTables è la whitelist di tabelle e colonne interrogabili dalla console
+// Le query vengono costruite solo con questi nomi: i valori dei filtri sono sempre passati come parametri
+var queryTables = map[string][]string{
+	"orders": {
+		"id", "order_id", "symbol", "side", "order_price", "quantity", "take_profit_price", "stop_loss_price",
+		"order_status_id", "result", "pnl", "pnl_percentage", "parent_order_id", "scale_level",
+		"signal_latency_ms", "reject_reason", "config_version", "created_at", "updated_at",
+	},
+	"executions": {
+		"id", "symbol", "side", "order_id", "exec_id", "price", "qty", "exec_type", "exec_time",
+		"is_maker", "fee", "fee_currency", "trade_time", "exchange",
+	},
+	"balance_snapshots": {
+		"id", "source", "run_id", "coin", "equity", "wallet_balance", "taken_at", "created_at",
+	},
+	"funding_arb_positions": {
+		"id", "symbol", "spot_exchange", "perp_exchange", "status", "quantity", "spot_entry_price", "perp_entry_price",
+		"entry_basis", "entry_funding_apr", "spot_exit_price", "perp_exit_price", "exit_basis",
+		"funding_collected", "borrow_cost", "pnl", "opened_at", "closed_at",
+	},
+}
+
+// querySources sostituisce nella FROM le tabelle interrogate attraverso un'unione
+// orders comprende gli ordini spostati in orders_archive, che altrimenti mancherebbero sui periodi lunghi
+var querySources = map[string]string{
+	"orders": fmt.Sprintf("(SELECT %[1]s FROM orders UNION ALL SELECT %[1]s FROM orders_archive) AS orders", archivedOrderColumns),
+}
+
+// queryOperators associa gli operatori dei filtri al loro SQL; quelli senza segnaposto non hanno valore
+var queryOperators = map[string]string{
+	"=":        "= ?",
+	"!=":       "<> ?",
+	"<":        "< ?",
+	"<=":       "<= ?",
+	">":        "> ?",
+	">=":       ">= ?",
+	"like":     "LIKE ?",
+	"in":       "IN ?",
+	"is_null":  "IS NULL",
+	"not_null": "IS NOT NULL",
+}
+
+// queryFunctions sono le funzioni di aggregazione consentite
+var queryFunctions = map[string]bool{"count": true, "sum": true, "avg": true, "min": true, "max": true}
+
+// queryAliasPattern valida gli alias delle aggregazioni, interpolati nella query
+var queryAliasPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,39}$`)
+
+// ConsoleQuery è una SELECT costruita dalla console delle query a partire da nomi in whitelist
+type ConsoleQuery struct {
+	Table      string           `json:"table"`
+	Columns    []string         `json:"columns,omitempty"`    // Vuoto con aggregazioni = solo aggregazioni; vuoto senza = tutte
+	Aggregates []QueryAggregate `json:"aggregates,omitempty"` // Funzioni di aggregazione
+	Filters    []QueryFilter    `json:"filters,omitempty"`    // Condizioni in AND
+	GroupBy    []string         `json:"group_by,omitempty"`
+	OrderBy    []QueryOrder     `json:"order_by,omitempty"`
+	Limit      int              `json:"limit,omitempty"` // Default 100, massimo 1000
+}
+
+// QueryAggregate è una funzione di aggregazione su una colonna (colonna vuota solo per count)
+type QueryAggregate struct {
+	Func   string `json:"func"` // count, sum, avg, min o max
+	Column string `json:"column,omitempty"`
+	As     string `json:"as,omitempty"` // Nome della colonna risultante (default func_column)
+}
+
+// QueryFilter è una condizione su una colonna
+type QueryFilter struct {
+	Column string `json:"column"`
+	Op     string `json:"op"` // =, !=, <, <=, >, >=, like, in, is_null, not_null
+	Value  any    `json:"value,omitempty"`
+}
+
+// QueryOrder è un ordinamento su una colonna o sull'alias di un'aggregazione
+type QueryOrder struct {
+	Column string `json:"column"`
+	Desc   bool   `json:"desc,omitempty"`
+}
+
+// QueryResult è il risultato di una query della console
+type QueryResult struct {
+	Columns   []string `json:"columns"`
+	Rows      [][]any  `json:"rows"`
+	Truncated bool     `json:"truncated"` // true se esistono altre righe oltre il limite
+}
+
+// queryRepository implementa QueryRepository
+type queryRepository struct {
+	db *gorm.DB
+}
+
+// NewQueryRepository crea una nuova istanza di QueryRepository
+func NewQueryRepository(db *gorm.DB) QueryRepository {
+	return &queryRepository{db: db}
+}
+
+// Tables restituisce tabelle e colonne interrogabili
+func (r *queryRepository) Tables() map[string][]string {
+	tables := make(map[string][]string, len(queryTables))
+	for table, columns := range queryTables {
+		tables[table] = append([]string(nil), columns...)
+	}
+	return tables
+}
+
+// Run valida la query contro la whitelist, la esegue e restituisce le righe
+func (r *queryRepository) Run(ctx context.Context, query ConsoleQuery) (*QueryResult, error) {
+	statement, args, limit, err := buildConsoleQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.WithContext(ctx).Raw(statement, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &QueryResult{Columns: columns, Rows: [][]any{}}
+	for rows.Next() {
+		if len(result.Rows) == limit {
+			result.Truncated = true
+			break
+		}
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		// Il driver restituisce il testo come []byte, che JSON codificherebbe in base64
+		for i, value := range values {
+			if raw, ok := value.([]byte); ok {
+				values[i] = string(raw)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return result, rows.Err()
+}
+
+// buildConsoleQuery costruisce la SELECT; ogni nome interpolato proviene dalla whitelist o supera queryAliasPattern
+// Restituisce anche il limite effettivo: la query chiede una riga in più per sapere se il risultato è troncato
+func buildConsoleQuery(query ConsoleQuery) (string, []any, int, error) {
+	table := strings.ToLower(query.Table)
+	allowed, ok := queryTables[table]
+	if !ok {
+		return "", nil, 0, fmt.Errorf("%w: unknown table %q", ErrInvalidQuery, query.Table)
+	}
+	column := func(name string) (string, error) {
+		name = strings.ToLower(name)
+		for _, candidate := range allowed {
+			if candidate == name {
+				return name, nil
+			}
+		}
+		return "", fmt.Errorf("%w: unknown column %q in %s", ErrInvalidQuery, name, table)
+	}
+
+	var selected []string
+	for _, name := range query.Columns {
+		col, err := column(name)
+		if err != nil {
+			return "", nil, 0, err
+		}
+		selected = append(selected, col)
+	}
+
+	aliases := make(map[string]bool)
+	for _, aggregate := range query.Aggregates {
+		fn := strings.ToLower(aggregate.Func)
+		if !queryFunctions[fn] {
+			return "", nil, 0, fmt.Errorf("%w: unsupported function %q", ErrInvalidQuery, aggregate.Func)
+		}
+		target := "*"
+		if aggregate.Column != "" {
+			col, err := column(aggregate.Column)
+			if err != nil {
+				return "", nil, 0, err
+			}
+			target = col
+		} else if fn != "count" {
+			return "", nil, 0, fmt.Errorf("%w: %s needs a column", ErrInvalidQuery, fn)
+		}
+		alias := strings.ToLower(aggregate.As)
+		if alias == "" {
+			alias = strings.TrimSuffix(fn+"_"+strings.Trim(target, "*"), "_")
+		}
+		if !queryAliasPattern.MatchString(alias) {
+			return "", nil, 0, fmt.Errorf("%w: invalid alias %q", ErrInvalidQuery, aggregate.As)
+		}
+		aliases[alias] = true
+		selected = append(selected, fmt.Sprintf("%s(%s) AS %s", strings.ToUpper(fn), target, alias))
+	}
+	if len(selected) == 0 {
+		selected = append(selected, allowed...)
+	}
+
+	var where []string
+	var args []any
+	for _, filter := range query.Filters {
+		col, err := column(filter.Column)
+		if err != nil {
+			return "", nil, 0, err
+		}
+		op := strings.ToLower(filter.Op)
+		clause, ok := queryOperators[op]
+		switch {
+		case !ok:
+			return "", nil, 0, fmt.Errorf("%w: unsupported operator %q", ErrInvalidQuery, filter.Op)
+		case !strings.Contains(clause, "?"):
+			where = append(where, col+" "+clause)
+		case filter.Value == nil:
+			return "", nil, 0, fmt.Errorf("%w: operator %s on %s needs a value", ErrInvalidQuery, op, col)
+		default:
+			values, isList := filter.Value.([]any)
+			if op == "in" && (!isList || len(values) == 0) {
+				return "", nil, 0, fmt.Errorf("%w: operator in on %s needs a non-empty list", ErrInvalidQuery, col)
+			}
+			if op != "in" && isList {
+				return "", nil, 0, fmt.Errorf("%w: operator %s on %s needs a single value", ErrInvalidQuery, op, col)
+			}
+			where = append(where, col+" "+clause)
+			args = append(args, filter.Value)
+		}
+	}
+
+	var groupBy []string
+	for _, name := range query.GroupBy {
+		col, err := column(name)
+		if err != nil {
+			return "", nil, 0, err
+		}
+		groupBy = append(groupBy, col)
+	}
+
+	var orderBy []string
+	for _, order := range query.OrderBy {
+		name := strings.ToLower(order.Column)
+		if !aliases[name] {
+			col, err := column(name)
+			if err != nil {
+				return "", nil, 0, err
+			}
+			name = col
+		}
+		direction := "ASC"
+		if order.Desc {
+			direction = "DESC"
+		}
+		orderBy = append(orderBy, name+" "+direction)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultQueryLimit
+	}
+	if limit > maxQueryLimit {
+		return "", nil, 0, fmt.Errorf("%w: limit must not exceed %d", ErrInvalidQuery, maxQueryLimit)
+	}
+
+	source := table
+	if union, ok := querySources[table]; ok {
+		source = union
+	}
+	statement := "SELECT " + strings.Join(selected, ", ") + " FROM " + source
+	if len(where) > 0 {
+		statement += " WHERE " + strings.Join(where, " AND ")
+	}
+	if len(groupBy) > 0 {
+		statement += " GROUP BY " + strings.Join(groupBy, ", ")
+	}
+	if len(orderBy) > 0 {
+		statement += " ORDER BY " + strings.Join(orderBy, ", ")
+	}
+	statement += fmt.Sprintf(" LIMIT %d", limit+1)
+	return statement, args, limit, nil
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+
+	"gorm.io/gorm"
+)
+
+// rebalanceTransferRepository implementa RebalanceTransferRepository
+type rebalanceTransferRepository struct {
+	db *gorm.DB
+}
+
+// NewRebalanceTransferRepository crea una nuova istanza di RebalanceTransferRepository
+func NewRebalanceTransferRepository(db *gorm.DB) RebalanceTransferRepository {
+	return &rebalanceTransferRepository{db: db}
+}
+
+// Create registra un trasferimento del ribilanciamento
+func (r *rebalanceTransferRepository) Create(ctx context.Context, transfer *models.RebalanceTransfer) error {
+	return r.db.WithContext(ctx).Create(transfer).Error
+}
+
+// ListRecent recupera gli ultimi trasferimenti, dal più recente
+func (r *rebalanceTransferRepository) ListRecent(ctx context.Context, limit int) ([]*models.RebalanceTransfer, error) {
+	var transfers []*models.RebalanceTransfer
+	if err := r.db.WithContext(ctx).Order("created_at DESC, id DESC").Limit(limit).Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
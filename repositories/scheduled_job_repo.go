@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// scheduledJobRepository implementa ScheduledJobRepository
+type scheduledJobRepository struct {
+	db *gorm.DB
+}
+
+// NewScheduledJobRepository crea una nuova istanza di ScheduledJobRepository
+func NewScheduledJobRepository(db *gorm.DB) ScheduledJobRepository {
+	return &scheduledJobRepository{db: db}
+}
+
+// Enqueue salva un nuovo job in attesa
+func (r *scheduledJobRepository) Enqueue(ctx context.Context, job *models.ScheduledJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+// ClaimDue prende in carico al più limit job scaduti, marcandoli running nella stessa transazione
+// Il passaggio a running è condizionato allo stato pending, così un job non viene eseguito due volte
+func (r *scheduledJobRepository) ClaimDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledJob, error) {
+	var claimed []*models.ScheduledJob
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var due []*models.ScheduledJob
+		if err := tx.Where("status = ? AND run_at <= ?", models.JobStatusPending, now.UTC()).
+			Order("run_at ASC, id ASC").
+			Limit(limit).
+			Find(&due).Error; err != nil {
+			return err
+		}
+
+		for _, job := range due {
+			result := tx.Model(&models.ScheduledJob{}).
+				Where("id = ? AND status = ?", job.ID, models.JobStatusPending).
+				Updates(map[string]interface{}{
+					"status":     models.JobStatusRunning,
+					"attempts":   gorm.Expr("attempts + 1"),
+					"updated_at": now.UTC(),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			job.Status = models.JobStatusRunning
+			job.Attempts++
+			claimed = append(claimed, job)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// Complete marca un job come eseguito
+func (r *scheduledJobRepository) Complete(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.ScheduledJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.JobStatusDone,
+			"last_error":   "",
+			"completed_at": at.UTC(),
+			"updated_at":   at.UTC(),
+		}).Error
+}
+
+// Retry rimette in attesa un job fallito fino a retryAt
+func (r *scheduledJobRepository) Retry(ctx context.Context, id uint, retryAt time.Time, lastError string) error {
+	return r.db.WithContext(ctx).Model(&models.ScheduledJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     models.JobStatusPending,
+			"run_at":     retryAt.UTC(),
+			"last_error": lastError,
+			"updated_at": time.Now().UTC(),
+		}).Error
+}
+
+// Fail marca un job come fallito definitivamente
+func (r *scheduledJobRepository) Fail(ctx context.Context, id uint, at time.Time, lastError string) error {
+	return r.db.WithContext(ctx).Model(&models.ScheduledJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.JobStatusFailed,
+			"last_error":   lastError,
+			"completed_at": at.UTC(),
+			"updated_at":   at.UTC(),
+		}).Error
+}
+
+// ReleaseRunning rimette in attesa i job rimasti running, interrotti da un arresto del processo
+func (r *scheduledJobRepository) ReleaseRunning(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.ScheduledJob{}).
+		Where("status = ?", models.JobStatusRunning).
+		Updates(map[string]interface{}{
+			"status":     models.JobStatusPending,
+			"updated_at": time.Now().UTC(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ListByStatus recupera i job in uno stato, in ordine di esecuzione
+func (r *scheduledJobRepository) ListByStatus(ctx context.Context, status models.JobStatus, limit int) ([]*models.ScheduledJob, error) {
+	var jobs []*models.ScheduledJob
+	query := r.db.WithContext(ctx).Where("status = ?", status).Order("run_at ASC, id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// DeleteFinishedBefore elimina i job conclusi (done o failed) prima di before
+func (r *scheduledJobRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status IN ? AND completed_at < ?", []models.JobStatus{models.JobStatusDone, models.JobStatusFailed}, before.UTC()).
+		Delete(&models.ScheduledJob{})
+	return result.RowsAffected, result.Error
+}
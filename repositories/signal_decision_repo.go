@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SignalDecisionFilter contiene i filtri della ricerca nelle decisioni delle strategie; i campi vuoti sono ignorati
+type SignalDecisionFilter struct {
+	Strategy string
+	Symbol   string
+	Outcome  models.SignalOutcome
+	From     time.Time // Inclusivo
+	To       time.Time // Esclusivo
+}
+
+// signalDecisionRepository implementa SignalDecisionRepository
+type signalDecisionRepository struct {
+	db *gorm.DB
+}
+
+// NewSignalDecisionRepository crea una nuova istanza di SignalDecisionRepository
+func NewSignalDecisionRepository(db *gorm.DB) SignalDecisionRepository {
+	return &signalDecisionRepository{db: db}
+}
+
+// Create salva la spiegazione di un segnale
+func (r *signalDecisionRepository) Create(ctx context.Context, decision *models.SignalDecision) error {
+	return r.db.WithContext(ctx).Create(decision).Error
+}
+
+// GetByOrderID recupera la decisione che ha generato l'ordine indicato
+func (r *signalDecisionRepository) GetByOrderID(ctx context.Context, orderID string) (*models.SignalDecision, error) {
+	var decision models.SignalDecision
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("id DESC").First(&decision).Error; err != nil {
+		return nil, err
+	}
+	return &decision, nil
+}
+
+// Search recupera le decisioni che soddisfano i filtri, dalla più recente
+func (r *signalDecisionRepository) Search(ctx context.Context, filter SignalDecisionFilter, limit, offset int) ([]*models.SignalDecision, error) {
+	query := r.db.WithContext(ctx)
+	if filter.Strategy != "" {
+		query = query.Where("strategy = ?", filter.Strategy)
+	}
+	if filter.Symbol != "" {
+		query = query.Where("symbol = ?", filter.Symbol)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", sqliteTimestamp(filter.From))
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", sqliteTimestamp(filter.To))
+	}
+
+	var decisions []*models.SignalDecision
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&decisions).Error; err != nil {
+		return nil, err
+	}
+	return decisions, nil
+}
+
+// DeleteBefore elimina le decisioni precedenti a before e restituisce quante ne ha eliminate
+func (r *signalDecisionRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("created_at < ?", sqliteTimestamp(before)).
+		Delete(&models.SignalDecision{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+
+	"gorm.io/gorm"
+)
+
+// slicedOrderRepository implementa SlicedOrderRepository
+type slicedOrderRepository struct {
+	db *gorm.DB
+}
+
+// NewSlicedOrderRepository crea una nuova istanza di SlicedOrderRepository
+func NewSlicedOrderRepository(db *gorm.DB) SlicedOrderRepository {
+	return &slicedOrderRepository{db: db}
+}
+
+// Create crea un nuovo ordine frazionato
+func (r *slicedOrderRepository) Create(ctx context.Context, order *models.SlicedOrder) error {
+	return r.db.WithContext(ctx).Create(order).Error
+}
+
+// Update aggiorna un ordine frazionato esistente
+func (r *slicedOrderRepository) Update(ctx context.Context, order *models.SlicedOrder) error {
+	return r.db.WithContext(ctx).Save(order).Error
+}
+
+// GetByID recupera un ordine frazionato per ID
+func (r *slicedOrderRepository) GetByID(ctx context.Context, id uint) (*models.SlicedOrder, error) {
+	var order models.SlicedOrder
+	err := r.db.WithContext(ctx).First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetByParentOrderID recupera l'ordine frazionato di un ingresso
+func (r *slicedOrderRepository) GetByParentOrderID(ctx context.Context, parentOrderID string) (*models.SlicedOrder, error) {
+	var order models.SlicedOrder
+	err := r.db.WithContext(ctx).Where("parent_order_id = ?", parentOrderID).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// AddSlice salva un ordine figlio e aggiorna l'ordine frazionato nella stessa transazione
+func (r *slicedOrderRepository) AddSlice(ctx context.Context, order *models.SlicedOrder, slice *models.OrderSlice) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		slice.SlicedOrderID = order.ID
+		if err := tx.Create(slice).Error; err != nil {
+			return err
+		}
+		return tx.Save(order).Error
+	})
+}
+
+// GetSlices recupera gli ordini figli di un ordine frazionato, in ordine di invio
+func (r *slicedOrderRepository) GetSlices(ctx context.Context, slicedOrderID uint) ([]*models.OrderSlice, error) {
+	var slices []*models.OrderSlice
+	err := r.db.WithContext(ctx).
+		Where("sliced_order_id = ?", slicedOrderID).
+		Order("sequence ASC").
+		Find(&slices).Error
+	if err != nil {
+		return nil, err
+	}
+	return slices, nil
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// subAccountRepository implementa SubAccountRepository
+type subAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewSubAccountRepository crea una nuova istanza di SubAccountRepository
+func NewSubAccountRepository(db *gorm.DB) SubAccountRepository {
+	return &subAccountRepository{db: db}
+}
+
+// Save crea l'associazione della strategia o aggiorna quella esistente (es. nuova API key)
+func (r *subAccountRepository) Save(ctx context.Context, account *models.StrategySubAccount) error {
+	account.UpdatedAt = time.Now().UTC()
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "strategy"}},
+		DoUpdates: clause.AssignmentColumns([]string{"exchange", "uid", "username", "api_key", "key_created", "updated_at"}),
+	}).Create(account).Error
+}
+
+// GetByStrategy recupera il sub-account associato a una strategia
+func (r *subAccountRepository) GetByStrategy(ctx context.Context, strategy string) (*models.StrategySubAccount, error) {
+	var account models.StrategySubAccount
+	err := r.db.WithContext(ctx).Where("strategy = ?", strategy).First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// List recupera tutte le associazioni in ordine di strategia
+func (r *subAccountRepository) List(ctx context.Context) ([]*models.StrategySubAccount, error) {
+	var accounts []*models.StrategySubAccount
+	if err := r.db.WithContext(ctx).Order("strategy ASC").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+
+	"gorm.io/gorm"
+)
+
+// workerBudgetRepository implementa WorkerBudgetRepository
+type workerBudgetRepository struct {
+	db *gorm.DB
+}
+
+// NewWorkerBudgetRepository crea una nuova istanza di WorkerBudgetRepository
+func NewWorkerBudgetRepository(db *gorm.DB) WorkerBudgetRepository {
+	return &workerBudgetRepository{db: db}
+}
+
+// Save crea il budget o lo sostituisce (nuova allocazione)
+func (r *workerBudgetRepository) Save(ctx context.Context, budget *models.WorkerBudget) error {
+	return r.db.WithContext(ctx).Save(budget).Error
+}
+
+// GetByWorker recupera il budget di un worker
+func (r *workerBudgetRepository) GetByWorker(ctx context.Context, worker string) (*models.WorkerBudget, error) {
+	var budget models.WorkerBudget
+	err := r.db.WithContext(ctx).Where("worker = ?", worker).First(&budget).Error
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// List recupera tutti i budget in ordine di worker
+func (r *workerBudgetRepository) List(ctx context.Context) ([]*models.WorkerBudget, error) {
+	var budgets []*models.WorkerBudget
+	if err := r.db.WithContext(ctx).Order("worker ASC").Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+// AddRealizedPnL somma pnl al PnL realizzato del worker con un solo UPDATE, sicuro tra istanze concorrenti
+// Restituisce gorm.ErrRecordNotFound se il worker non ha un budget
+func (r *workerBudgetRepository) AddRealizedPnL(ctx context.Context, worker string, pnl float64) error {
+	result := r.db.WithContext(ctx).Model(&models.WorkerBudget{}).
+		Where("worker = ?", worker).
+		Updates(map[string]interface{}{
+			"realized_pnl": gorm.Expr("realized_pnl + ?", pnl),
+			"updated_at":   gorm.Expr("CURRENT_TIMESTAMP"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
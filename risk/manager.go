@@ -0,0 +1,145 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrSymbolNotAllowed indica un simbolo escluso dal trading dalle liste globali
+var ErrSymbolNotAllowed = errors.New("simbolo non negoziabile")
+
+// SymbolLists sono le liste globali dei simboli ammessi ed esclusi dal trading
+type SymbolLists struct {
+	Allow []string `json:"allow"` // Se non vuota, solo questi simboli sono negoziabili
+	Deny  []string `json:"deny"`  // Simboli mai negoziabili, anche se presenti in Allow
+}
+
+// Limits sono i limiti di rischio iniziali del Manager
+type Limits struct {
+	Symbols          SymbolLists
+	MaxOpenPositions int // Numero massimo di simboli con una posizione aperta; 0 senza limite
+}
+
+// Manager applica i limiti di rischio globali, condivisi da tutte le strategie
+// Le liste dei simboli possono essere modificate a runtime (REST API) e valgono dal ciclo successivo;
+// un Manager nil non blocca nulla, così i chiamanti non devono controllare se è attivo
+type Manager struct {
+	mu    sync.RWMutex
+	allow map[string]bool
+	deny  map[string]bool
+	gates []func() error // Condizioni globali che bloccano i nuovi ingressi (es. exchange non raggiungibile)
+
+	// Posizioni aperte: lette dalle sorgenti registrate, più i simboli prenotati dagli ingressi in corso
+	positionsMu      sync.Mutex
+	maxOpenPositions int
+	sources          []namedSource
+	reserved         map[string]bool
+}
+
+// NewManager crea il risk manager con i limiti indicati
+func NewManager(limits Limits) *Manager {
+	m := &Manager{
+		maxOpenPositions: limits.MaxOpenPositions,
+		reserved:         make(map[string]bool),
+	}
+	m.setSymbolLists(limits.Symbols)
+	return m
+}
+
+// NormalizeSymbol riporta un simbolo al formato del bot (maiuscolo, senza spazi)
+func NormalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// CheckSymbol restituisce ErrSymbolNotAllowed se il simbolo è nella deny list
+// o se la allow list non è vuota e non lo contiene
+func (m *Manager) CheckSymbol(symbol string) error {
+	if m == nil {
+		return nil
+	}
+	symbol = NormalizeSymbol(symbol)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.deny[symbol] {
+		return fmt.Errorf("%w: %s è nella deny list", ErrSymbolNotAllowed, symbol)
+	}
+	if len(m.allow) > 0 && !m.allow[symbol] {
+		return fmt.Errorf("%w: %s non è nella allow list", ErrSymbolNotAllowed, symbol)
+	}
+	return nil
+}
+
+// AddEntryGate registra una condizione globale dei nuovi ingressi: se gate restituisce un errore,
+// CheckEntry e ReservePosition bloccano l'ingresso su tutti i simboli. Va chiamato prima dell'avvio dei worker
+func (m *Manager) AddEntryGate(gate func() error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gates = append(m.gates, gate)
+}
+
+// CheckEntry restituisce il primo errore delle condizioni globali registrate con AddEntryGate
+func (m *Manager) CheckEntry() error {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, gate := range m.gates {
+		if err := gate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SymbolLists restituisce le liste correnti in ordine alfabetico
+func (m *Manager) SymbolLists() SymbolLists {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return SymbolLists{Allow: sortedKeys(m.allow), Deny: sortedKeys(m.deny)}
+}
+
+// SetSymbolLists sostituisce entrambe le liste; le modifiche non sopravvivono al riavvio
+func (m *Manager) SetSymbolLists(lists SymbolLists) {
+	m.setSymbolLists(lists)
+	current := m.SymbolLists()
+	log.Printf("🛡️  Liste simboli aggiornate: allow %v, deny %v", current.Allow, current.Deny)
+}
+
+// setSymbolLists normalizza i simboli e sostituisce le liste
+func (m *Manager) setSymbolLists(lists SymbolLists) {
+	allow, deny := symbolSet(lists.Allow), symbolSet(lists.Deny)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.allow, m.deny = allow, deny
+}
+
+// symbolSet converte un elenco di simboli in un insieme, scartando i valori vuoti
+func symbolSet(symbols []string) map[string]bool {
+	set := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		if symbol = NormalizeSymbol(symbol); symbol != "" {
+			set[symbol] = true
+		}
+	}
+	return set
+}
+
+// sortedKeys restituisce le chiavi dell'insieme in ordine alfabetico, mai nil (serializzate come [])
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,109 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrPositionLimit indica un nuovo ingresso bloccato dal limite di posizioni aperte
+var ErrPositionLimit = errors.New("limite posizioni aperte")
+
+// PositionSource elenca i simboli con una posizione aperta (es. posizioni su un exchange o nel database)
+type PositionSource interface {
+	OpenSymbols(ctx context.Context) ([]string, error)
+}
+
+// PositionSourceFunc adatta una funzione all'interfaccia PositionSource
+type PositionSourceFunc func(ctx context.Context) ([]string, error)
+
+// OpenSymbols implementa PositionSource
+func (f PositionSourceFunc) OpenSymbols(ctx context.Context) ([]string, error) {
+	return f(ctx)
+}
+
+// namedSource è una sorgente di posizioni con il nome usato negli errori
+type namedSource struct {
+	name   string
+	source PositionSource
+}
+
+// AddPositionSource registra una sorgente di posizioni aperte; va chiamato prima dell'avvio dei worker
+func (m *Manager) AddPositionSource(name string, source PositionSource) {
+	m.positionsMu.Lock()
+	defer m.positionsMu.Unlock()
+
+	m.sources = append(m.sources, namedSource{name: name, source: source})
+}
+
+// ReservePosition autorizza una nuova posizione sul simbolo e lo prenota finché non viene chiamato release
+// Blocca l'ingresso se una condizione globale lo vieta, se il simbolo è escluso dalle liste, se ha già una posizione aperta o prenotata,
+// o se le posizioni aperte hanno raggiunto il massimo. Le prenotazioni sono serializzate, così due strategie
+// non possono superare il limite entrando nello stesso momento; release va chiamato dopo il piazzamento degli ordini,
+// quando la posizione è visibile alle sorgenti. Se una sorgente non risponde l'ingresso viene bloccato
+func (m *Manager) ReservePosition(ctx context.Context, symbol string) (release func(), err error) {
+	if m == nil {
+		return func() {}, nil
+	}
+	symbol = NormalizeSymbol(symbol)
+	if err := m.CheckEntry(); err != nil {
+		return nil, err
+	}
+	if err := m.CheckSymbol(symbol); err != nil {
+		return nil, err
+	}
+
+	m.positionsMu.Lock()
+	defer m.positionsMu.Unlock()
+
+	open, err := m.openSymbolsLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if open[symbol] {
+		return nil, fmt.Errorf("%w: %s ha già una posizione aperta", ErrPositionLimit, symbol)
+	}
+	if m.maxOpenPositions > 0 && len(open) >= m.maxOpenPositions {
+		return nil, fmt.Errorf("%w: %d posizioni aperte %v (massimo %d)", ErrPositionLimit, len(open), sortedKeys(open), m.maxOpenPositions)
+	}
+
+	m.reserved[symbol] = true
+	return func() {
+		m.positionsMu.Lock()
+		defer m.positionsMu.Unlock()
+		delete(m.reserved, symbol)
+	}, nil
+}
+
+// OpenSymbols restituisce in ordine alfabetico i simboli con una posizione aperta o prenotata
+func (m *Manager) OpenSymbols(ctx context.Context) ([]string, error) {
+	m.positionsMu.Lock()
+	defer m.positionsMu.Unlock()
+
+	open, err := m.openSymbolsLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(open), nil
+}
+
+// openSymbolsLocked unisce i simboli delle sorgenti e quelli prenotati; va chiamato con positionsMu acquisito
+// Lo stesso simbolo su più sorgenti (es. gamba perpetual sull'exchange e posizione nel database) conta una volta
+func (m *Manager) openSymbolsLocked(ctx context.Context) (map[string]bool, error) {
+	open := make(map[string]bool, len(m.reserved))
+	for symbol := range m.reserved {
+		open[symbol] = true
+	}
+	for _, named := range m.sources {
+		symbols, err := named.source.OpenSymbols(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("posizioni aperte %s non disponibili: %w", named.name, err)
+		}
+		for _, symbol := range symbols {
+			if symbol = NormalizeSymbol(symbol); symbol != "" {
+				open[symbol] = true
+			}
+		}
+	}
+	return open, nil
+}
@@ -0,0 +1,147 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"cross-exchange-arbitrage/exchange"
+)
+
+// ErrPriceDeviation indica un prezzo di esecuzione troppo lontano dalla fonte di riferimento (es. un tick anomalo)
+var ErrPriceDeviation = errors.New("prezzo di esecuzione lontano dal riferimento")
+
+// PriceDeviationError descrive lo scostamento che ha bloccato l'ordine; errors.Is(err, ErrPriceDeviation) la riconosce
+type PriceDeviationError struct {
+	Symbol         string
+	Price          float64 // Prezzo usato per l'ordine
+	Reference      string  // Fonte di riferimento (es. "index bybit")
+	ReferencePrice float64
+	Deviation      float64 // Scostamento relativo dal riferimento (0.01 = 1%)
+	MaxDeviation   float64
+}
+
+// Error descrive i due prezzi e lo scostamento
+func (e *PriceDeviationError) Error() string {
+	return fmt.Sprintf("%v: %s a %.6f, %s a %.6f (scostamento %.2f%%, massimo %.2f%%)",
+		ErrPriceDeviation, e.Symbol, e.Price, e.Reference, e.ReferencePrice, e.Deviation*100, e.MaxDeviation*100)
+}
+
+// Unwrap permette di riconoscere l'errore con errors.Is(err, ErrPriceDeviation)
+func (e *PriceDeviationError) Unwrap() error {
+	return ErrPriceDeviation
+}
+
+// ReferenceFeed fornisce il secondo prezzo con cui validare quello di esecuzione
+type ReferenceFeed interface {
+	// Name identifica la fonte nei log (es. "index bybit")
+	Name() string
+
+	// ReferencePrice restituisce il prezzo di riferimento corrente del simbolo
+	ReferencePrice(ctx context.Context, symbol string) (float64, error)
+}
+
+// indexFeed usa l'index price del perpetual, calcolato dall'exchange su più mercati spot
+type indexFeed struct {
+	venue    string
+	provider exchange.FundingRateProvider
+}
+
+// NewIndexFeed crea una fonte di riferimento basata sull'index price della venue indicata
+func NewIndexFeed(venue string, provider exchange.FundingRateProvider) ReferenceFeed {
+	return &indexFeed{venue: venue, provider: provider}
+}
+
+// Name implementa ReferenceFeed
+func (f *indexFeed) Name() string {
+	return "index " + f.venue
+}
+
+// ReferencePrice implementa ReferenceFeed
+func (f *indexFeed) ReferencePrice(ctx context.Context, symbol string) (float64, error) {
+	funding, err := f.provider.GetFundingRate(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	return funding.IndexPrice, nil
+}
+
+// venueFeed usa il prezzo medio tra bid e ask di un'altra venue
+type venueFeed struct {
+	venue    string
+	exchange exchange.Exchange
+}
+
+// NewVenueFeed crea una fonte di riferimento basata sul prezzo in tempo reale della venue indicata
+func NewVenueFeed(venue string, exch exchange.Exchange) ReferenceFeed {
+	return &venueFeed{venue: venue, exchange: exch}
+}
+
+// Name implementa ReferenceFeed
+func (f *venueFeed) Name() string {
+	return f.venue
+}
+
+// ReferencePrice implementa ReferenceFeed
+func (f *venueFeed) ReferencePrice(ctx context.Context, symbol string) (float64, error) {
+	price, err := f.exchange.GetRealTimePrice(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	return price.Price, nil
+}
+
+// PriceChecker confronta il prezzo di esecuzione con una seconda fonte prima di piazzare un ordine
+// Un PriceChecker nil non blocca nulla, così i chiamanti non devono controllare se è attivo
+type PriceChecker struct {
+	feed         ReferenceFeed
+	maxDeviation float64
+}
+
+// NewPriceChecker crea il controllo con lo scostamento massimo ammesso (0.02 = 2%)
+func NewPriceChecker(feed ReferenceFeed, maxDeviation float64) (*PriceChecker, error) {
+	if feed == nil {
+		return nil, fmt.Errorf("fonte di riferimento mancante")
+	}
+	if maxDeviation <= 0 || maxDeviation >= 1 {
+		return nil, fmt.Errorf("scostamento massimo %.4f non valido: deve essere tra 0 e 1 esclusi", maxDeviation)
+	}
+	return &PriceChecker{feed: feed, maxDeviation: maxDeviation}, nil
+}
+
+// Reference restituisce il nome della fonte di riferimento
+func (c *PriceChecker) Reference() string {
+	if c == nil {
+		return ""
+	}
+	return c.feed.Name()
+}
+
+// Check restituisce uno *PriceDeviationError se price si scosta dal riferimento più del massimo ammesso
+// Se il riferimento non è disponibile l'ordine viene bloccato: senza conferma il prezzo non è verificabile
+func (c *PriceChecker) Check(ctx context.Context, symbol string, price float64) error {
+	if c == nil {
+		return nil
+	}
+
+	reference, err := c.feed.ReferencePrice(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("prezzo di riferimento %s non disponibile per %s: %w", c.feed.Name(), symbol, err)
+	}
+	if reference <= 0 || price <= 0 {
+		return &PriceDeviationError{
+			Symbol: symbol, Price: price, Reference: c.feed.Name(), ReferencePrice: reference,
+			Deviation: math.Inf(1), MaxDeviation: c.maxDeviation,
+		}
+	}
+
+	deviation := math.Abs(price/reference - 1)
+	if deviation > c.maxDeviation {
+		return &PriceDeviationError{
+			Symbol: symbol, Price: price, Reference: c.feed.Name(), ReferencePrice: reference,
+			Deviation: deviation, MaxDeviation: c.maxDeviation,
+		}
+	}
+	return nil
+}
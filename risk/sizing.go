@@ -0,0 +1,128 @@
+package risk
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// Modalità di dimensionamento degli ordini
+const (
+	SizingCompound = "compound" // Dal capitale corrente: profitti e perdite cambiano la size dei trade successivi
+	SizingFixed    = "fixed"    // Da un capitale base costante, riancorato solo dalle regole di reset
+)
+
+// Regole di reset del capitale base in modalità fixed
+const (
+	ResetNever   = "never"
+	ResetDaily   = "daily"
+	ResetWeekly  = "weekly"
+	ResetMonthly = "monthly"
+)
+
+// SizingParams configura il dimensionamento degli ordini
+type SizingParams struct {
+	Mode        string  // compound o fixed
+	BaseCapital float64 // Capitale base in fixed; 0 lo ancora al capitale al primo ordine
+	Reset       string  // Riancoraggio periodico del capitale base ancorato: never, daily, weekly, monthly (UTC)
+	ResetChange float64 // Riancora quando il capitale si discosta dal base oltre questa frazione (0.2 = 20%); 0 disattiva
+}
+
+// Sizer restituisce il capitale da cui dimensionare gli ordini di ogni worker
+// In fixed i trade hanno la stessa size finché il capitale base non viene riancorato, così le size
+// del live restano confrontabili con quelle di un backtest; il capitale base non sopravvive al riavvio.
+// Un Sizer nil usa il capitale corrente (compound)
+type Sizer struct {
+	params SizingParams
+	now    func() time.Time
+
+	mu    sync.Mutex
+	bases map[string]sizingBase // Capitale base ancorato per worker
+}
+
+// sizingBase è il capitale base ancorato di un worker
+type sizingBase struct {
+	capital    float64
+	anchoredAt time.Time
+}
+
+// NewSizer valida i parametri e crea il Sizer
+func NewSizer(params SizingParams) (*Sizer, error) {
+	switch params.Mode {
+	case SizingCompound, SizingFixed:
+	default:
+		return nil, fmt.Errorf("modalità di dimensionamento %q non valida (compound o fixed)", params.Mode)
+	}
+	switch params.Reset {
+	case ResetNever, ResetDaily, ResetWeekly, ResetMonthly:
+	default:
+		return nil, fmt.Errorf("regola di reset %q non valida (never, daily, weekly o monthly)", params.Reset)
+	}
+	if params.BaseCapital < 0 {
+		return nil, fmt.Errorf("capitale base negativo: %.2f", params.BaseCapital)
+	}
+	if params.ResetChange < 0 {
+		return nil, fmt.Errorf("soglia di reset negativa: %.4f", params.ResetChange)
+	}
+	if params.BaseCapital > 0 && (params.Reset != ResetNever || params.ResetChange > 0) {
+		return nil, fmt.Errorf("le regole di reset valgono solo per il capitale base ancorato (capitale base 0)")
+	}
+
+	return &Sizer{params: params, now: time.Now, bases: make(map[string]sizingBase)}, nil
+}
+
+// Capital restituisce il capitale da cui dimensionare l'ordine del worker, dato il capitale disponibile
+// (saldo o budget del worker). Non supera mai il disponibile: in fixed, dopo una perdita, la size cala solo
+// quando il capitale base non è più coperto
+func (s *Sizer) Capital(worker string, available float64) float64 {
+	if s == nil || s.params.Mode == SizingCompound {
+		return available
+	}
+	if s.params.BaseCapital > 0 {
+		return math.Min(s.params.BaseCapital, available)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	base, ok := s.bases[worker]
+	if reason := s.resetReason(base, ok, available, now); reason != "" {
+		base = sizingBase{capital: available, anchoredAt: now}
+		s.bases[worker] = base
+		log.Printf("📏 Capitale base %s: %.2f USDT (%s)", worker, available, reason)
+	}
+	return math.Min(base.capital, available)
+}
+
+// resetReason restituisce il motivo per riancorare il capitale base, vuoto se resta valido
+func (s *Sizer) resetReason(base sizingBase, ok bool, available float64, now time.Time) string {
+	if !ok || base.capital <= 0 {
+		return "primo ordine"
+	}
+	if s.params.ResetChange > 0 && math.Abs(available/base.capital-1) >= s.params.ResetChange {
+		return fmt.Sprintf("capitale cambiato del %+.1f%%", (available/base.capital-1)*100)
+	}
+	if periodStart(s.params.Reset, now).After(base.anchoredAt) {
+		return "reset " + s.params.Reset
+	}
+	return ""
+}
+
+// periodStart restituisce l'inizio (UTC) del periodo di reset che contiene t; zero con ResetNever
+func periodStart(reset string, t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch reset {
+	case ResetDaily:
+		return day
+	case ResetWeekly:
+		// Le settimane iniziano di lunedì
+		return day.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	case ResetMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Time{}
+	}
+}
@@ -0,0 +1,97 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// ErrSpreadTooWide indica che lo spread denaro-lettera supera la soglia ammessa per un ordine a mercato
+var ErrSpreadTooWide = errors.New("spread troppo ampio per un ordine a mercato")
+
+// Modalità di gestione di un ordine a mercato con spread oltre la soglia
+const (
+	SpreadBlock = "block" // L'ordine viene scartato subito
+	SpreadDefer = "defer" // L'ordine attende che lo spread rientri, fino all'attesa massima
+)
+
+// QuoteFunc restituisce miglior denaro e lettera di un simbolo (es. GetRealTimePrice dell'exchange, servito dalla cache)
+type QuoteFunc func(ctx context.Context, symbol string) (*models.RealTimePriceData, error)
+
+// SpreadBps restituisce lo spread in punti base sul prezzo medio tra denaro e lettera
+func SpreadBps(price *models.RealTimePriceData) float64 {
+	mid := (price.BidPrice + price.AskPrice) / 2
+	return (price.AskPrice - price.BidPrice) / mid * 10000
+}
+
+// SpreadGuard blocca, o rinvia, gli ordini a mercato di apertura quando lo spread supera maxBps
+// Su un book sottile attraversare lo spread può costare più del take profit
+// Uno SpreadGuard nil ammette tutti gli ordini, così i chiamanti non devono controllare se è attivo
+type SpreadGuard struct {
+	maxBps  float64
+	mode    string
+	maxWait time.Duration // Attesa massima in modalità defer
+	poll    time.Duration // Intervallo di rilettura dello spread in modalità defer
+}
+
+// NewSpreadGuard crea il controllo con la soglia in punti base; mode è block o defer
+func NewSpreadGuard(maxBps float64, mode string, maxWait time.Duration) (*SpreadGuard, error) {
+	if maxBps <= 0 {
+		return nil, fmt.Errorf("soglia di spread %.2f bps non valida: deve essere positiva", maxBps)
+	}
+	if mode != SpreadBlock && mode != SpreadDefer {
+		return nil, fmt.Errorf("modalità %q non supportata (block o defer)", mode)
+	}
+	return &SpreadGuard{maxBps: maxBps, mode: mode, maxWait: maxWait, poll: time.Second}, nil
+}
+
+// Check legge lo spread di symbol con quote e restituisce un errore ErrSpreadTooWide se supera la soglia
+// In modalità defer rilegge lo spread finché rientra o scade l'attesa massima
+// Se il prezzo non è disponibile l'ordine viene bloccato: lo spread non è verificabile
+func (g *SpreadGuard) Check(ctx context.Context, symbol string, quote QuoteFunc) error {
+	if g == nil {
+		return nil
+	}
+
+	deadline := time.Now().Add(g.maxWait)
+	for {
+		spread, err := g.spread(ctx, symbol, quote)
+		if err != nil {
+			return err
+		}
+		if spread <= g.maxBps {
+			return nil
+		}
+
+		err = fmt.Errorf("%w: %s %.1f bps (massimo %.1f bps)", ErrSpreadTooWide, symbol, spread, g.maxBps)
+		if g.mode == SpreadBlock || time.Now().Add(g.poll).After(deadline) {
+			log.Printf("↔️  Ordine %s scartato: %v", symbol, err)
+			return err
+		}
+		log.Printf("↔️  Ordine %s rinviato: %v", symbol, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (attesa annullata: %v)", err, ctx.Err())
+		case <-time.After(g.poll):
+		}
+	}
+}
+
+// spread legge denaro e lettera di symbol e restituisce lo spread in punti base
+func (g *SpreadGuard) spread(ctx context.Context, symbol string, quote QuoteFunc) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	price, err := quote(ctx, symbol)
+	if err != nil {
+		return 0, fmt.Errorf("spread di %s non verificabile: %w", symbol, err)
+	}
+	if price.BidPrice <= 0 || price.AskPrice <= 0 {
+		return 0, fmt.Errorf("spread di %s non verificabile: miglior denaro o lettera mancante", symbol)
+	}
+	return SpreadBps(price), nil
+}
@@ -0,0 +1,71 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// ErrStaleData indica dati di mercato troppo vecchi per operare
+// (es. WebSocket fermo senza errori o endpoint REST che restituisce dati in cache)
+var ErrStaleData = errors.New("dati di mercato non aggiornati")
+
+// StaleDataError descrive il dato scartato; errors.Is(err, ErrStaleData) la riconosce
+type StaleDataError struct {
+	Source    string        // Origine del dato (es. "prezzo bybit DOGEUSDT")
+	Timestamp time.Time     // Timestamp del dato; zero se non disponibile
+	Age       time.Duration // Età del dato al momento del controllo
+	MaxAge    time.Duration // Età massima ammessa
+}
+
+// Error descrive il dato scartato e la sua età
+func (e *StaleDataError) Error() string {
+	if e.Timestamp.IsZero() {
+		return fmt.Sprintf("%v: %s senza timestamp", ErrStaleData, e.Source)
+	}
+	return fmt.Sprintf("%v: %s, età %v (massimo %v)", ErrStaleData, e.Source, e.Age.Round(time.Second), e.MaxAge)
+}
+
+// Unwrap permette di riconoscere l'errore con errors.Is(err, ErrStaleData)
+func (e *StaleDataError) Unwrap() error {
+	return ErrStaleData
+}
+
+// CheckFreshness restituisce uno *StaleDataError se timestamp è più vecchio di maxAge
+// Un timestamp nel futuro (orologio dell'exchange avanti rispetto al nostro) è considerato aggiornato;
+// maxAge 0 disattiva il controllo
+func CheckFreshness(source string, timestamp time.Time, maxAge time.Duration) error {
+	if maxAge <= 0 {
+		return nil
+	}
+	if timestamp.IsZero() {
+		return &StaleDataError{Source: source, MaxAge: maxAge}
+	}
+	if age := time.Since(timestamp); age > maxAge {
+		return &StaleDataError{Source: source, Timestamp: timestamp, Age: age, MaxAge: maxAge}
+	}
+	return nil
+}
+
+// CheckPrice controlla che il prezzo in tempo reale sia stato aggiornato da non più di maxAge
+func CheckPrice(price *models.RealTimePriceData, maxAge time.Duration) error {
+	return CheckFreshness(fmt.Sprintf("prezzo %s %s", price.Exchange, price.Symbol), price.Timestamp, maxAge)
+}
+
+// CheckCandles controlla che la candela più recente sia ancora aperta o si sia chiusa da non più di maxAge
+// Le candele possono essere in ordine cronologico o inverso; un elenco vuoto è considerato non aggiornato
+func CheckCandles(symbol string, candles []models.Candle, timeframe models.Timeframe, maxAge time.Duration) error {
+	source := fmt.Sprintf("candele %s timeframe %s", symbol, timeframe)
+	if len(candles) == 0 {
+		return CheckFreshness(source, time.Time{}, maxAge)
+	}
+
+	latest := candles[0].Timestamp
+	if last := candles[len(candles)-1].Timestamp; last.After(latest) {
+		latest = last
+	}
+	// Il dato di una candela è aggiornato fino alla sua chiusura
+	return CheckFreshness(source, latest.Add(timeframe.Duration()), maxAge)
+}
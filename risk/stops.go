@@ -0,0 +1,159 @@
+package risk
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"cross-exchange-arbitrage/models"
+
+	"github.com/markcheno/go-talib"
+)
+
+// Modalità di posizionamento dello stop loss
+const (
+	StopFixed      = "fixed"      // Percentuale fissa dal prezzo di ingresso, decisa dalla strategia
+	StopSwing      = "swing"      // Sotto l'ultimo minimo swing (long) o sopra l'ultimo massimo swing (short)
+	StopChandelier = "chandelier" // Massimo più alto meno k×ATR (long) o minimo più basso più k×ATR (short)
+)
+
+// swingStrength è il numero di candele per lato che un minimo (o massimo) swing deve superare
+const swingStrength = 2
+
+// StopParams configura il posizionamento dello stop loss
+type StopParams struct {
+	Mode          string  // fixed, swing o chandelier
+	SwingLookback int     // Candele chiuse in cui cercare l'ultimo swing
+	ATRPeriod     int     // Periodo dell'ATR e della finestra del massimo/minimo nel chandelier
+	ATRMultiplier float64 // Multiplo dell'ATR (k) nel chandelier
+}
+
+// StopPlacer calcola lo stop loss di un ingresso dalle candele chiuse
+// Un StopPlacer nil, o in modalità fixed, lascia lo stop alla percentuale della strategia
+type StopPlacer struct {
+	params StopParams
+}
+
+// NewStopPlacer valida i parametri e crea lo StopPlacer
+func NewStopPlacer(params StopParams) (*StopPlacer, error) {
+	switch params.Mode {
+	case StopFixed, StopSwing, StopChandelier:
+	default:
+		return nil, fmt.Errorf("modalità di stop %q non valida (fixed, swing o chandelier)", params.Mode)
+	}
+	if params.Mode == StopSwing && params.SwingLookback <= 2*swingStrength {
+		return nil, fmt.Errorf("le candele dello stop swing devono essere più di %d (%d)", 2*swingStrength, params.SwingLookback)
+	}
+	if params.Mode == StopChandelier && (params.ATRPeriod <= 0 || params.ATRMultiplier <= 0) {
+		return nil, fmt.Errorf("periodo (%d) e multiplo (%.2f) dell'ATR del chandelier devono essere positivi",
+			params.ATRPeriod, params.ATRMultiplier)
+	}
+	return &StopPlacer{params: params}, nil
+}
+
+// MinCandles restituisce le candele chiuse da cui lo stop è calcolabile (0 in modalità fixed o con StopPlacer nil)
+func (p *StopPlacer) MinCandles() int {
+	if p == nil {
+		return 0
+	}
+	switch p.params.Mode {
+	case StopSwing:
+		return p.params.SwingLookback
+	case StopChandelier:
+		return p.params.ATRPeriod + 1
+	default:
+		return 0
+	}
+}
+
+// StopLoss calcola lo stop loss di un ingresso a entry nella direzione indicata, dalle candele chiuse
+// (dalla più vecchia alla più recente). ok è false in modalità fixed o se lo stop non è calcolabile
+// o cade dal lato sbagliato del prezzo di ingresso: il chiamante usa allora il suo stop percentuale
+func (p *StopPlacer) StopLoss(side models.OrderSide, closedCandles []models.Candle, entry float64) (float64, bool) {
+	if p == nil || p.params.Mode == StopFixed {
+		return 0, false
+	}
+
+	var stop float64
+	var err error
+	long := side == models.OrderSideBuy
+	if p.params.Mode == StopSwing {
+		stop, err = p.swingStop(long, closedCandles, entry)
+	} else {
+		stop, err = p.chandelierStop(long, closedCandles)
+	}
+	if err == nil && ((long && stop >= entry) || (!long && stop <= entry)) {
+		err = fmt.Errorf("stop %.6f dal lato sbagliato dell'ingresso %.6f", stop, entry)
+	}
+	if err != nil {
+		log.Printf("⚠️  Stop %s non applicato, uso lo stop percentuale: %v", p.params.Mode, err)
+		return 0, false
+	}
+
+	log.Printf("🛑 Stop %s %s: %.6f (ingresso %.6f, distanza %.2f%%)", p.params.Mode, side, stop, entry, math.Abs(entry-stop)/entry*100)
+	return stop, true
+}
+
+// swingStop restituisce l'ultimo minimo swing sotto l'ingresso (long) o l'ultimo massimo swing sopra (short)
+// Un minimo swing è una candela il cui minimo è inferiore a quello delle swingStrength candele su entrambi i lati
+func (p *StopPlacer) swingStop(long bool, candles []models.Candle, entry float64) (float64, error) {
+	if len(candles) > p.params.SwingLookback {
+		candles = candles[len(candles)-p.params.SwingLookback:]
+	}
+
+	for i := len(candles) - 1 - swingStrength; i >= swingStrength; i-- {
+		pivot := true
+		for j := i - swingStrength; j <= i+swingStrength && pivot; j++ {
+			if j == i {
+				continue
+			}
+			if long {
+				pivot = candles[i].Low < candles[j].Low
+			} else {
+				pivot = candles[i].High > candles[j].High
+			}
+		}
+		if !pivot {
+			continue
+		}
+		if long && candles[i].Low < entry {
+			return candles[i].Low, nil
+		}
+		if !long && candles[i].High > entry {
+			return candles[i].High, nil
+		}
+	}
+	return 0, fmt.Errorf("nessuno swing nelle ultime %d candele", len(candles))
+}
+
+// chandelierStop restituisce il massimo più alto meno k×ATR (long) o il minimo più basso più k×ATR (short)
+// sulle ultime ATRPeriod candele
+func (p *StopPlacer) chandelierStop(long bool, candles []models.Candle) (float64, error) {
+	period := p.params.ATRPeriod
+	if len(candles) <= period {
+		return 0, fmt.Errorf("%d candele disponibili, ne servono %d per l'ATR%d", len(candles), period+1, period)
+	}
+
+	highs := make([]float64, len(candles))
+	lows := make([]float64, len(candles))
+	closes := make([]float64, len(candles))
+	for i, candle := range candles {
+		highs[i], lows[i], closes[i] = candle.High, candle.Low, candle.Close
+	}
+	atr := talib.Atr(highs, lows, closes, period)
+	distance := p.params.ATRMultiplier * atr[len(atr)-1]
+
+	window := candles[len(candles)-period:]
+	if long {
+		highest := window[0].High
+		for _, candle := range window {
+			highest = math.Max(highest, candle.High)
+		}
+		return highest - distance, nil
+	}
+	lowest := window[0].Low
+	for _, candle := range window {
+		lowest = math.Min(lowest, candle.Low)
+	}
+	return lowest + distance, nil
+}
@@ -0,0 +1,115 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/taprocess"
+
+	"github.com/markcheno/go-talib"
+)
+
+const (
+	// FeatureCandles è il numero di candele chiuse, fino a quella del segnale inclusa, da cui si calcolano le feature
+	// Il worker live e l'export dei dataset usano la stessa storia, così gli indicatori coincidono
+	FeatureCandles = 500
+
+	// breakoutWindow è il numero di candele precedenti il segnale su cui si calcolano muro e supporto
+	breakoutWindow = 72
+
+	// MinFeatureCandles è il numero minimo di candele chiuse, fino a quella del segnale inclusa, per calcolare le feature
+	MinFeatureCandles = breakoutWindow + 1
+
+	// volumeLookback è il numero di candele dello stesso colore per il volume medio
+	volumeLookback = 10
+)
+
+// BreakoutFeatures calcola il vettore di feature di un breakout del sistema DOGE
+// candles contiene le candele chiuse in ordine cronologico, l'ultima è quella del segnale;
+// va passata la stessa storia sia in live che negli export (le ultime FeatureCandles candele), con le stesse
+// sorgenti di prezzo degli indicatori (TA_PRICE_SOURCES): rsi14 è calcolato sulla sorgente dell'RSI
+func BreakoutFeatures(symbol string, side models.OrderSide, candles []models.Candle, sources taprocess.IndicatorSources) (Features, error) {
+	features := Features{Symbol: symbol, Side: side}
+	if len(candles) < MinFeatureCandles {
+		return features, fmt.Errorf("candele insufficienti per le feature: servono almeno %d, presenti %d", MinFeatureCandles, len(candles))
+	}
+	if len(candles) > FeatureCandles {
+		candles = candles[len(candles)-FeatureCandles:]
+	}
+
+	last := candles[len(candles)-1]
+	previous := candles[:len(candles)-1]
+
+	// Muro e supporto sono massimo e minimo delle candele precedenti il segnale
+	wall, support := 0.0, math.MaxFloat64
+	for _, candle := range previous[len(previous)-breakoutWindow:] {
+		wall = math.Max(wall, candle.High)
+		support = math.Min(support, candle.Low)
+	}
+
+	closes := make([]float64, len(candles))
+	highs := make([]float64, len(candles))
+	lows := make([]float64, len(candles))
+	for i, candle := range candles {
+		closes[i] = candle.Close
+		highs[i] = candle.High
+		lows[i] = candle.Low
+	}
+
+	// Distanza della chiusura dal livello rotto, positiva nella direzione del breakout
+	breakout := (last.Close - wall) / wall
+	if side == models.OrderSideSell {
+		breakout = (support - last.Close) / support
+	}
+	avgVolume := sameColorAverageVolume(previous, side)
+
+	features.Add("close", last.Close)
+	features.Add("wall", wall)
+	features.Add("support", support)
+	features.Add("breakout_pct", breakout)
+	features.Add("range_pct", (wall-support)/last.Close)
+	features.Add("body_pct", (last.Close-last.Open)/last.Open)
+	features.Add("volume", last.Volume)
+	features.Add("avg_volume", avgVolume)
+	features.Add("volume_ratio", safeRatio(last.Volume, avgVolume))
+	features.Add("rsi14", lastValue(talib.Rsi(sources.RSI.Series(candles), 14)))
+	features.Add("atr14_pct", safeRatio(lastValue(talib.Atr(highs, lows, closes, 14)), last.Close))
+
+	return features, nil
+}
+
+// sameColorAverageVolume restituisce il volume medio delle ultime volumeLookback candele
+// dello stesso colore del breakout (verdi per i long, rosse per gli short)
+func sameColorAverageVolume(candles []models.Candle, side models.OrderSide) float64 {
+	var total float64
+	count := 0
+	for i := len(candles) - 1; i >= 0 && count < volumeLookback; i-- {
+		green := candles[i].Close > candles[i].Open
+		red := candles[i].Close < candles[i].Open
+		if (side == models.OrderSideBuy && green) || (side == models.OrderSideSell && red) {
+			total += candles[i].Volume
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// lastValue restituisce l'ultimo valore di una serie di indicatori (NaN se vuota)
+func lastValue(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	return values[len(values)-1]
+}
+
+// safeRatio restituisce a/b, o 0 se b è nullo
+func safeRatio(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
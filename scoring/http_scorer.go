@@ -0,0 +1,94 @@
+package scoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// HTTPScorer delega la valutazione a un servizio esterno che espone il modello via HTTP
+// Richiesta: POST {"symbol", "side", "features": {nome: valore}}
+// Risposta: {"score": 0.72, "size_multiplier": 0.8, "veto": false} (size_multiplier e veto opzionali)
+type HTTPScorer struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+// httpScoreRequest rappresenta il corpo della richiesta di valutazione
+type httpScoreRequest struct {
+	Symbol   string             `json:"symbol"`
+	Side     models.OrderSide   `json:"side"`
+	Features map[string]float64 `json:"features"`
+}
+
+// httpScoreResponse rappresenta la risposta del servizio di valutazione
+type httpScoreResponse struct {
+	Score          *float64 `json:"score"`
+	SizeMultiplier float64  `json:"size_multiplier"`
+	Veto           bool     `json:"veto"`
+}
+
+// NewHTTPScorer crea uno scorer che interroga l'URL indicato; token può essere vuoto
+func NewHTTPScorer(url, token string, timeout time.Duration) *HTTPScorer {
+	return &HTTPScorer{
+		url:        url,
+		token:      token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Score implementa SignalScorer
+func (s *HTTPScorer) Score(ctx context.Context, features Features) (*Score, error) {
+	jsonData, err := json.Marshal(httpScoreRequest{
+		Symbol:   features.Symbol,
+		Side:     features.Side,
+		Features: features.Map(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("errore serializzazione feature: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("errore creazione richiesta di scoring: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("errore richiesta di scoring: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("errore lettura risposta di scoring: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("scoring: status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result httpScoreResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("errore decodifica risposta di scoring: %w", err)
+	}
+	if result.Score == nil {
+		return nil, fmt.Errorf("scoring: risposta senza score")
+	}
+
+	return &Score{
+		Value:          *result.Score,
+		SizeMultiplier: result.SizeMultiplier,
+		Veto:           result.Veto,
+	}, nil
+}
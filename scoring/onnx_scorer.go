@@ -0,0 +1,79 @@
+//go:build onnx
+
+package scoring
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// ONNXScorer valuta i segnali in-process con un modello ONNX tramite ONNX Runtime
+// Il modello riceve un tensore float32 [1, n] e restituisce il punteggio come ultimo valore dell'output
+// (per i classificatori binari con output [1, 2] è la probabilità della classe positiva)
+type ONNXScorer struct {
+	mu       sync.Mutex // Una sessione non va eseguita in parallelo con gli stessi tensori
+	session  *ort.DynamicAdvancedSession
+	features []string
+}
+
+// NewONNXScorer carica il modello e inizializza ONNX Runtime
+func NewONNXScorer(cfg Config) (SignalScorer, error) {
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("modello ONNX non configurato")
+	}
+
+	if !ort.IsInitialized() {
+		if cfg.Library != "" {
+			ort.SetSharedLibraryPath(cfg.Library)
+		}
+		if err := ort.InitializeEnvironment(); err != nil {
+			return nil, fmt.Errorf("errore inizializzazione ONNX Runtime: %w", err)
+		}
+	}
+
+	session, err := ort.NewDynamicAdvancedSession(cfg.Model, []string{cfg.Input}, []string{cfg.Output}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("errore caricamento modello %s: %w", cfg.Model, err)
+	}
+
+	return &ONNXScorer{session: session, features: cfg.Features}, nil
+}
+
+// Score implementa SignalScorer
+func (s *ONNXScorer) Score(ctx context.Context, features Features) (*Score, error) {
+	vector := features.Vector(s.features)
+	input, err := ort.NewTensor(ort.NewShape(1, int64(len(vector))), vector)
+	if err != nil {
+		return nil, fmt.Errorf("errore creazione tensore di input: %w", err)
+	}
+	defer input.Destroy()
+
+	// L'output con valore nil viene allocato da ONNX Runtime con la forma prodotta dal modello
+	outputs := []ort.Value{nil}
+	s.mu.Lock()
+	err = s.session.Run([]ort.Value{input}, outputs)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("errore esecuzione modello: %w", err)
+	}
+	defer outputs[0].Destroy()
+
+	tensor, ok := outputs[0].(*ort.Tensor[float32])
+	if !ok {
+		return nil, fmt.Errorf("output del modello non float32")
+	}
+	data := tensor.GetData()
+	if len(data) == 0 {
+		return nil, fmt.Errorf("output del modello vuoto")
+	}
+
+	return &Score{Value: float64(data[len(data)-1])}, nil
+}
+
+// Close rilascia la sessione del modello
+func (s *ONNXScorer) Close() error {
+	return s.session.Destroy()
+}
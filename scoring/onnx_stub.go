@@ -0,0 +1,10 @@
+//go:build !onnx
+
+package scoring
+
+import "fmt"
+
+// NewONNXScorer non è disponibile senza il tag di build onnx, che richiede cgo e ONNX Runtime
+func NewONNXScorer(cfg Config) (SignalScorer, error) {
+	return nil, fmt.Errorf("supporto ONNX non compilato: ricompila con -tags onnx")
+}
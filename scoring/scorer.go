@@ -0,0 +1,129 @@
+package scoring
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// Features è il vettore di feature di un segnale (indicatori, rapporti di volume, livelli)
+// Names e Values sono allineati: l'ordine è quello con cui il modello è stato addestrato
+type Features struct {
+	Symbol string
+	Side   models.OrderSide
+	Names  []string
+	Values []float64
+}
+
+// Add aggiunge una feature al vettore
+func (f *Features) Add(name string, value float64) {
+	f.Names = append(f.Names, name)
+	f.Values = append(f.Values, value)
+}
+
+// Map restituisce le feature indicizzate per nome
+func (f Features) Map() map[string]float64 {
+	values := make(map[string]float64, len(f.Names))
+	for i, name := range f.Names {
+		values[name] = f.Values[i]
+	}
+	return values
+}
+
+// Vector restituisce le feature nell'ordine di names; con names vuoto usa l'ordine del vettore
+// Le feature mancanti e i valori non finiti valgono 0
+func (f Features) Vector(names []string) []float32 {
+	if len(names) == 0 {
+		names = f.Names
+	}
+	values := f.Map()
+	vector := make([]float32, len(names))
+	for i, name := range names {
+		if value := values[name]; !math.IsNaN(value) && !math.IsInf(value, 0) {
+			vector[i] = float32(value)
+		}
+	}
+	return vector
+}
+
+// Score è la valutazione di un segnale da parte del modello
+type Score struct {
+	Value          float64 // Punteggio del modello, tipicamente la probabilità di successo (0-1)
+	SizeMultiplier float64 // Moltiplicatore della quantità suggerito dal modello (0 = calcolato dalla Policy)
+	Veto           bool    // Il modello rifiuta esplicitamente il trade
+}
+
+// SignalScorer valuta un segnale con un modello addestrato esternamente
+type SignalScorer interface {
+	Score(ctx context.Context, features Features) (*Score, error)
+}
+
+// Policy converte il punteggio del modello in una decisione di ingresso e dimensionamento
+type Policy struct {
+	MinScore float64 // Sotto questo punteggio il trade viene scartato
+	MinSize  float64 // Moltiplicatore della quantità al punteggio minimo
+	MaxSize  float64 // Moltiplicatore della quantità al punteggio massimo (1)
+}
+
+// Decision è l'esito dell'applicazione della Policy a un punteggio
+type Decision struct {
+	Allow          bool
+	SizeMultiplier float64
+	Score          float64
+	Reason         string
+}
+
+// Decide applica la Policy al punteggio del modello
+// Senza un moltiplicatore suggerito la quantità cresce linearmente da MinSize a MaxSize tra MinScore e 1
+func (p Policy) Decide(score *Score) Decision {
+	if score.Veto {
+		return Decision{Score: score.Value, Reason: "veto del modello"}
+	}
+	if score.Value < p.MinScore {
+		return Decision{Score: score.Value, Reason: fmt.Sprintf("punteggio %.3f sotto la soglia %.3f", score.Value, p.MinScore)}
+	}
+
+	size := score.SizeMultiplier
+	if size <= 0 {
+		size = p.MaxSize
+		if p.MinScore < 1 {
+			size = p.MinSize + (p.MaxSize-p.MinSize)*(score.Value-p.MinScore)/(1-p.MinScore)
+		}
+	}
+	size = math.Max(p.MinSize, math.Min(p.MaxSize, size))
+
+	return Decision{
+		Allow:          true,
+		SizeMultiplier: size,
+		Score:          score.Value,
+		Reason:         fmt.Sprintf("punteggio %.3f, quantità al %.0f%%", score.Value, size*100),
+	}
+}
+
+// Config contiene i parametri per creare uno SignalScorer
+type Config struct {
+	Type     string        // http o onnx
+	URL      string        // Endpoint dello scorer HTTP
+	Token    string        // Bearer token opzionale dello scorer HTTP
+	Timeout  time.Duration // Timeout delle richieste HTTP
+	Model    string        // File del modello ONNX
+	Library  string        // Libreria condivisa di ONNX Runtime (vuoto = percorso di default)
+	Input    string        // Nome del tensore di input del modello ONNX
+	Output   string        // Nome del tensore di output del modello ONNX
+	Features []string      // Ordine delle feature atteso dal modello ONNX (vuoto = ordine del vettore)
+}
+
+// New crea lo SignalScorer del tipo configurato
+func New(cfg Config) (SignalScorer, error) {
+	switch cfg.Type {
+	case "http":
+		return NewHTTPScorer(cfg.URL, cfg.Token, cfg.Timeout), nil
+	case "onnx":
+		return NewONNXScorer(cfg)
+	default:
+		return nil, fmt.Errorf("tipo di scorer %q non supportato (http o onnx)", cfg.Type)
+	}
+}
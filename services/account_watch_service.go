@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/repositories"
+
+	"gorm.io/gorm"
+)
+
+// positionStopOrderTypes sono gli ordini condizionali che Bybit crea per stop loss e take profit di una posizione:
+// appartengono alla posizione e vengono valutati con essa
+var positionStopOrderTypes = map[string]bool{
+	"TakeProfit":        true,
+	"StopLoss":          true,
+	"PartialTakeProfit": true,
+	"PartialStopLoss":   true,
+	"TrailingStop":      true,
+	"tpslOrder":         true,
+}
+
+// AccountWatchParams configura il controllo delle attività dell'account
+type AccountWatchParams struct {
+	Grace         time.Duration // Età minima di ordini e posizioni sconosciuti prima di segnalarli (il bot salva dopo l'invio)
+	CancelUnknown bool          // Cancella gli ordini aperti sconosciuti
+	IgnoreSymbols []string      // Simboli gestiti fuori dal bot, o da strategie senza ordini nel database (es. la copertura)
+}
+
+// UnknownOrder è un ordine aperto sull'account che il bot non ha creato
+type UnknownOrder struct {
+	Order     models.OrderResponse
+	New       bool  // true alla prima segnalazione dell'ordine
+	Canceled  bool  // true se l'ordine è stato cancellato
+	CancelErr error // Errore della cancellazione, se tentata
+}
+
+// UnknownPosition è una posizione aperta sull'account che nessuna strategia del bot ha aperto
+type UnknownPosition struct {
+	Position models.Position
+	New      bool // true alla prima segnalazione della posizione
+}
+
+// AccountWatchReport è l'esito di un controllo delle attività dell'account
+type AccountWatchReport struct {
+	Orders    []UnknownOrder
+	Positions []UnknownPosition
+	CheckedAt time.Time
+}
+
+// AccountWatchService confronta ordini e posizioni aperti sull'exchange con quelli registrati dal bot
+// Un ordine o una posizione sconosciuti indicano un'operazione manuale o una API key compromessa
+type AccountWatchService struct {
+	processor   orderprocessor.OrderProcessor
+	orders      orderprocessor.OpenOrderReader
+	repoManager repositories.RepositoryManager
+	params      AccountWatchParams
+	ignore      map[string]bool
+
+	mu        sync.Mutex
+	reported  map[string]bool      // Ordini e posizioni già segnalati, per segnalarli una sola volta
+	firstSeen map[string]time.Time // Prima volta in cui una posizione sconosciuta è stata vista
+}
+
+// NewAccountWatchService crea il servizio; il processor deve saper elencare gli ordini aperti
+func NewAccountWatchService(processor orderprocessor.OrderProcessor, repoManager repositories.RepositoryManager, params AccountWatchParams) (*AccountWatchService, error) {
+	orders, ok := processor.(orderprocessor.OpenOrderReader)
+	if !ok {
+		return nil, fmt.Errorf("order processor cannot list open orders")
+	}
+
+	ignore := make(map[string]bool, len(params.IgnoreSymbols))
+	for _, symbol := range params.IgnoreSymbols {
+		ignore[strings.ToUpper(symbol)] = true
+	}
+	return &AccountWatchService{
+		processor:   processor,
+		orders:      orders,
+		repoManager: repoManager,
+		params:      params,
+		ignore:      ignore,
+		reported:    make(map[string]bool),
+		firstSeen:   make(map[string]time.Time),
+	}, nil
+}
+
+// Check cerca ordini e posizioni aperti che il bot non ha creato e, se configurato, cancella gli ordini
+func (s *AccountWatchService) Check(ctx context.Context) (*AccountWatchReport, error) {
+	now := time.Now()
+
+	openOrders, err := s.orders.GetOpenOrders(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read open orders: %w", err)
+	}
+	positions, err := s.processor.GetPositions(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read positions: %w", err)
+	}
+	fundingSymbols, err := s.fundingArbSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := &AccountWatchReport{CheckedAt: now}
+	seen := make(map[string]bool)
+
+	for _, order := range openOrders {
+		if positionStopOrderTypes[order.StopOrderType] || s.ignore[order.Symbol] || now.Sub(order.CreatedTime) < s.params.Grace {
+			continue
+		}
+		known, err := s.knownOrder(ctx, order.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		if known {
+			continue
+		}
+
+		key := "order:" + order.OrderID
+		seen[key] = true
+		unknown := UnknownOrder{Order: order, New: !s.reported[key]}
+		s.reported[key] = true
+		if s.params.CancelUnknown {
+			if _, err := s.processor.DeleteOrder(ctx, order.Symbol, order.OrderID); err != nil {
+				unknown.CancelErr = err
+			} else {
+				unknown.Canceled = true
+			}
+		}
+		report.Orders = append(report.Orders, unknown)
+	}
+
+	for _, position := range positions {
+		if !position.Parsed.IsActive() || s.ignore[position.Symbol] || fundingSymbols[position.Symbol] {
+			continue
+		}
+		known, err := s.knownPosition(ctx, position.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		if known {
+			continue
+		}
+
+		key := "position:" + position.Symbol + ":" + string(position.Side)
+		seen[key] = true
+		first, ok := s.firstSeen[key]
+		if !ok {
+			s.firstSeen[key] = now
+			first = now
+		}
+		if now.Sub(first) < s.params.Grace {
+			continue
+		}
+		report.Positions = append(report.Positions, UnknownPosition{Position: position, New: !s.reported[key]})
+		s.reported[key] = true
+	}
+
+	// Un ordine o una posizione spariti possono essere segnalati di nuovo se ricompaiono
+	for key := range s.reported {
+		if !seen[key] {
+			delete(s.reported, key)
+		}
+	}
+	for key := range s.firstSeen {
+		if !seen[key] {
+			delete(s.firstSeen, key)
+		}
+	}
+	return report, nil
+}
+
+// knownOrder verifica se l'ordine è registrato nel database del bot
+func (s *AccountWatchService) knownOrder(ctx context.Context, orderID string) (bool, error) {
+	_, err := s.repoManager.Order().GetByOrderID(ctx, orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up order %s: %w", orderID, err)
+	}
+	return true, nil
+}
+
+// knownPosition verifica se il bot ha un ingresso aperto sul simbolo
+func (s *AccountWatchService) knownPosition(ctx context.Context, symbol string) (bool, error) {
+	_, err := s.repoManager.Order().GetLatestEntry(ctx, symbol)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up open entry for %s: %w", symbol, err)
+	}
+	return true, nil
+}
+
+// fundingArbSymbols restituisce i simboli con una posizione di funding arbitrage aperta
+func (s *AccountWatchService) fundingArbSymbols(ctx context.Context) (map[string]bool, error) {
+	positions, err := s.repoManager.FundingArb().GetAllActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read funding arbitrage positions: %w", err)
+	}
+	symbols := make(map[string]bool, len(positions))
+	for _, position := range positions {
+		symbols[strings.ToUpper(position.Symbol)] = true
+	}
+	return symbols, nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/taprocess"
+)
+
+// archiveTestOrders è il numero di ordini chiusi creati prima dell'archiviazione
+const archiveTestOrders = 3
+
+// seedClosedOrders salva la cache delle candele e gli ordini chiusi e taggati, chiusi prima di closedAt
+func seedClosedOrders(t *testing.T, ctx context.Context, repoManager repositories.RepositoryManager, start, closedAt time.Time) {
+	t.Helper()
+
+	records := make([]*models.CandleRecord, 0, 300)
+	for i := 0; i < cap(records); i++ {
+		price := 0.1 + 0.01*math.Sin(float64(i)/7)
+		records = append(records, models.NewCandleRecord("DOGEUSDT", models.DerivativesMarket, models.Timeframe1m, models.Candle{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Open:      price,
+			High:      price * 1.002,
+			Low:       price * 0.998,
+			Close:     price * 1.001,
+			Volume:    1000 + float64(i%10)*50,
+		}))
+	}
+	if err := repoManager.Candle().CreateBatch(ctx, records); err != nil {
+		t.Fatalf("candles: %v", err)
+	}
+
+	status, err := repoManager.OrderStatus().GetByStatusName(ctx, "Filled")
+	if err != nil {
+		t.Fatalf("status Filled: %v", err)
+	}
+	for i := 0; i < archiveTestOrders; i++ {
+		takeProfit, stopLoss := 0.11, 0.09
+		order := &models.Order{
+			OrderID:         fmt.Sprintf("archived-%d", i),
+			Symbol:          "DOGEUSDT",
+			Side:            models.OrderSideTypeBuy,
+			OrderPrice:      0.1,
+			Quantity:        100,
+			TakeProfitPrice: &takeProfit,
+			StopLossPrice:   &stopLoss,
+			OrderStatusID:   status.ID,
+			Result:          models.OrderResultProfit,
+			PnL:             1,
+			CreatedAt:       start.Add(time.Duration(200+i*10) * time.Minute),
+			UpdatedAt:       closedAt,
+		}
+		if err := repoManager.Order().Create(ctx, order); err != nil {
+			t.Fatalf("order %s: %v", order.OrderID, err)
+		}
+		if err := repoManager.OrderTag().Create(ctx, &models.OrderTag{OrderID: order.OrderID, Tag: "breakout"}); err != nil {
+			t.Fatalf("tag %s: %v", order.OrderID, err)
+		}
+	}
+}
+
+func TestHistoricalReportsSurviveArchiving(t *testing.T) {
+	ctx := context.Background()
+	repoManager := openTestRepositories(t)
+
+	start := time.Now().UTC().AddDate(0, 0, -120).Truncate(time.Minute)
+	seedClosedOrders(t, ctx, repoManager, start, start.Add(24*time.Hour))
+
+	reports := NewReportService(repoManager, nil, 0)
+	datasets := NewDatasetService(repoManager, taprocess.NewTalibProcessor().Sources)
+	from, to := start, start.Add(48*time.Hour)
+
+	tagsBefore, err := reports.GetTagPerformance(ctx, "DOGEUSDT")
+	if err != nil {
+		t.Fatalf("tag stats before archiving: %v", err)
+	}
+	rowsBefore, _, err := datasets.BuildTrainingSet(ctx, "DOGEUSDT", from, to)
+	if err != nil {
+		t.Fatalf("dataset before archiving: %v", err)
+	}
+	if len(rowsBefore) != archiveTestOrders {
+		t.Fatalf("dataset rows before archiving = %d, want %d", len(rowsBefore), archiveTestOrders)
+	}
+
+	moved, err := repoManager.OrderArchive().ArchiveClosedOrders(ctx, time.Now().UTC().AddDate(0, 0, -90), 100)
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if moved != archiveTestOrders {
+		t.Fatalf("archived orders = %d, want %d", moved, archiveTestOrders)
+	}
+
+	tagsAfter, err := reports.GetTagPerformance(ctx, "DOGEUSDT")
+	if err != nil {
+		t.Fatalf("tag stats after archiving: %v", err)
+	}
+	if len(tagsAfter) != 1 || len(tagsBefore) != 1 {
+		t.Fatalf("tag stats: before %d tags, after %d tags, want 1", len(tagsBefore), len(tagsAfter))
+	}
+	if *tagsAfter[0] != *tagsBefore[0] {
+		t.Errorf("tag stats after archiving = %+v, want %+v", *tagsAfter[0], *tagsBefore[0])
+	}
+
+	rowsAfter, _, err := datasets.BuildTrainingSet(ctx, "DOGEUSDT", from, to)
+	if err != nil {
+		t.Fatalf("dataset after archiving: %v", err)
+	}
+	if len(rowsAfter) != len(rowsBefore) {
+		t.Fatalf("dataset rows after archiving = %d, want %d", len(rowsAfter), len(rowsBefore))
+	}
+	for i := range rowsAfter {
+		if rowsAfter[i].OrderID != rowsBefore[i].OrderID {
+			t.Errorf("row %d order = %s, want %s", i, rowsAfter[i].OrderID, rowsBefore[i].OrderID)
+		}
+	}
+}
+
+func TestArchivingKeepsOpenPositionEntries(t *testing.T) {
+	ctx := context.Background()
+	repoManager := openTestRepositories(t)
+
+	status, err := repoManager.OrderStatus().GetByStatusName(ctx, "Filled")
+	if err != nil {
+		t.Fatalf("status Filled: %v", err)
+	}
+	longAgo := time.Now().UTC().AddDate(0, 0, -120)
+	entry := &models.Order{
+		OrderID:       "open-entry",
+		Symbol:        "DOGEUSDT",
+		Side:          models.OrderSideTypeBuy,
+		OrderPrice:    0.1,
+		Quantity:      100,
+		OrderStatusID: status.ID,
+		Result:        models.OrderResultDone,
+		CreatedAt:     longAgo,
+		UpdatedAt:     longAgo,
+	}
+	if err := repoManager.Order().Create(ctx, entry); err != nil {
+		t.Fatalf("order: %v", err)
+	}
+
+	moved, err := repoManager.OrderArchive().ArchiveClosedOrders(ctx, time.Now().UTC().AddDate(0, 0, -90), 100)
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if moved != 0 {
+		t.Errorf("archived orders = %d, want 0", moved)
+	}
+
+	latest, err := repoManager.Order().GetLatestEntry(ctx, "DOGEUSDT")
+	if err != nil {
+		t.Fatalf("latest entry after archiving: %v", err)
+	}
+	if latest.OrderID != entry.OrderID {
+		t.Errorf("latest entry = %s, want %s", latest.OrderID, entry.OrderID)
+	}
+}
+
+func TestQueryConsoleReadsArchivedOrders(t *testing.T) {
+	ctx := context.Background()
+	repoManager := openTestRepositories(t)
+
+	start := time.Now().UTC().AddDate(0, 0, -120).Truncate(time.Minute)
+	seedClosedOrders(t, ctx, repoManager, start, start.Add(24*time.Hour))
+	if _, err := repoManager.OrderArchive().ArchiveClosedOrders(ctx, time.Now().UTC().AddDate(0, 0, -90), 100); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	result, err := repoManager.Query().Run(ctx, repositories.ConsoleQuery{
+		Table:      "orders",
+		Aggregates: []repositories.QueryAggregate{{Func: "count", As: "orders"}},
+		Filters:    []repositories.QueryFilter{{Column: "symbol", Op: "=", Value: "DOGEUSDT"}},
+	})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(result.Rows) != 1 || fmt.Sprint(result.Rows[0][0]) != fmt.Sprint(archiveTestOrders) {
+		t.Errorf("archived orders counted by the console = %v, want %d", result.Rows, archiveTestOrders)
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// VenueBalance rappresenta il margine disponibile di una venue rispetto alla sua soglia
+type VenueBalance struct {
+	Venue     string
+	Available float64                // Margine disponibile dopo l'eventuale rifornimento
+	Threshold float64                // Margine minimo richiesto
+	Deficit   float64                // Importo mancante per tornare alla soglia (0 se sopra)
+	TopUp     *models.TransferResult // Rifornimento automatico dal funding account, se eseguito
+	Err       error                  // Errore di lettura del saldo o di trasferimento
+}
+
+// RebalanceSuggestion rappresenta uno spostamento di fondi tra venue da eseguire manualmente
+type RebalanceSuggestion struct {
+	From   string
+	To     string
+	Amount float64
+}
+
+// BalanceSyncReport è l'esito di un controllo dei margini
+type BalanceSyncReport struct {
+	Coin        string
+	Venues      []VenueBalance
+	Suggestions []RebalanceSuggestion
+	CheckedAt   time.Time
+}
+
+// NeedsRebalance verifica se almeno una venue è sotto soglia
+func (r *BalanceSyncReport) NeedsRebalance() bool {
+	for _, venue := range r.Venues {
+		if venue.Deficit > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// BalanceSyncService mantiene il margine disponibile di ogni venue sopra una soglia
+// Le venue che supportano i trasferimenti interni vengono rifornite dal funding account;
+// gli spostamenti tra exchange diversi richiedono un prelievo e vengono solo suggeriti
+type BalanceSyncService struct {
+	readers     map[string]orderprocessor.AccountReader
+	thresholds  map[string]float64
+	coin        string
+	autoTopUp   bool
+	topUpBuffer float64
+}
+
+// NewBalanceSyncService crea una nuova istanza di BalanceSyncService
+// thresholds indica il margine minimo per venue; topUpBuffer è la frazione oltre la soglia
+// a cui riportare il margine (es. 0.2 = soglia + 20%) per evitare rifornimenti continui
+func NewBalanceSyncService(readers map[string]orderprocessor.AccountReader, thresholds map[string]float64, coin string, autoTopUp bool, topUpBuffer float64) *BalanceSyncService {
+	return &BalanceSyncService{
+		readers:     readers,
+		thresholds:  thresholds,
+		coin:        coin,
+		autoTopUp:   autoTopUp,
+		topUpBuffer: topUpBuffer,
+	}
+}
+
+// Sync controlla il margine di ogni venue con una soglia configurata ed esegue i rifornimenti automatici
+func (s *BalanceSyncService) Sync(ctx context.Context) (*BalanceSyncReport, error) {
+	if len(s.thresholds) == 0 {
+		return nil, fmt.Errorf("no balance thresholds configured")
+	}
+
+	venues := make([]string, 0, len(s.thresholds))
+	for venue := range s.thresholds {
+		venues = append(venues, venue)
+	}
+	sort.Strings(venues)
+
+	report := &BalanceSyncReport{Coin: s.coin, CheckedAt: time.Now().UTC()}
+	for _, venue := range venues {
+		report.Venues = append(report.Venues, s.syncVenue(ctx, venue, s.thresholds[venue]))
+	}
+	report.Suggestions = s.suggest(report.Venues)
+
+	return report, nil
+}
+
+// syncVenue legge il margine di una venue e la rifornisce se possibile
+func (s *BalanceSyncService) syncVenue(ctx context.Context, venue string, threshold float64) VenueBalance {
+	balance := VenueBalance{Venue: venue, Threshold: threshold}
+
+	reader, ok := s.readers[venue]
+	if !ok {
+		balance.Err = fmt.Errorf("venue %s has no configured credentials", venue)
+		return balance
+	}
+
+	available, err := s.availableMargin(ctx, reader)
+	if err != nil {
+		balance.Err = fmt.Errorf("failed to read %s margin: %w", venue, err)
+		return balance
+	}
+	balance.Available = available
+
+	if available >= threshold {
+		return balance
+	}
+
+	if transferer, ok := reader.(orderprocessor.AccountTransferer); ok && s.autoTopUp {
+		result, err := s.topUp(ctx, transferer, threshold*(1+s.topUpBuffer)-available)
+		if err != nil {
+			balance.Err = fmt.Errorf("failed to top up %s: %w", venue, err)
+		} else if result != nil {
+			balance.TopUp = result
+			balance.Available += result.Request.Amount
+		}
+	}
+
+	balance.Deficit = math.Max(0, threshold-balance.Available)
+	return balance
+}
+
+// availableMargin restituisce il margine disponibile dell'account di trading
+func (s *BalanceSyncService) availableMargin(ctx context.Context, reader orderprocessor.AccountReader) (float64, error) {
+	walletResp, err := reader.GetWalletBalance(ctx, models.AccountTypeUnified, s.coin)
+	if err != nil {
+		return 0, err
+	}
+	account := walletResp.GetFirstAccount()
+	if account == nil {
+		return 0, fmt.Errorf("no account returned")
+	}
+	return account.GetTotalAvailableBalanceFloat()
+}
+
+// topUp trasferisce fino ad amount dal funding account all'account di trading
+// Restituisce nil senza errore se il funding account è vuoto
+func (s *BalanceSyncService) topUp(ctx context.Context, transferer orderprocessor.AccountTransferer, amount float64) (*models.TransferResult, error) {
+	fundBalance, err := transferer.GetTransferableBalance(ctx, models.AccountTypeFund, s.coin)
+	if err != nil {
+		return nil, err
+	}
+
+	amount = math.Floor(math.Min(amount, fundBalance)*100) / 100
+	if amount <= 0 {
+		return nil, nil
+	}
+
+	result, err := transferer.InternalTransfer(ctx, models.TransferRequest{
+		Coin:            s.coin,
+		Amount:          amount,
+		FromAccountType: models.AccountTypeFund,
+		ToAccountType:   models.AccountTypeUnified,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !result.IsSuccess() && result.Status != "PENDING" {
+		return nil, fmt.Errorf("transfer %s ended with status %s", result.TransferID, result.Status)
+	}
+	return result, nil
+}
+
+// suggest calcola gli spostamenti tra venue che coprono i deficit usando le eccedenze
+// Una venue cede solo il margine oltre soglia + buffer
+func (s *BalanceSyncService) suggest(balances []VenueBalance) []RebalanceSuggestion {
+	surplus := make(map[string]float64)
+	var donors []string
+	for _, balance := range balances {
+		if balance.Err != nil {
+			continue
+		}
+		if excess := balance.Available - balance.Threshold*(1+s.topUpBuffer); excess > 0 {
+			surplus[balance.Venue] = excess
+			donors = append(donors, balance.Venue)
+		}
+	}
+
+	var suggestions []RebalanceSuggestion
+	for _, balance := range balances {
+		needed := balance.Deficit
+		for _, donor := range donors {
+			if needed <= 0 {
+				break
+			}
+			amount := math.Min(needed, surplus[donor])
+			if amount <= 0 {
+				continue
+			}
+			suggestions = append(suggestions, RebalanceSuggestion{From: donor, To: balance.Venue, Amount: amount})
+			surplus[donor] -= amount
+			needed -= amount
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+
+	"gorm.io/gorm"
+)
+
+// BudgetService gestisce i sub-account virtuali dei worker: ogni worker con una quota opera solo
+// sulla sua parte del saldo, aggiornata dal PnL realizzato, così le strategie non si sottraggono capitale
+// Un BudgetService nil lascia ai worker l'intero saldo, così i chiamanti non devono controllare se è attivo
+type BudgetService struct {
+	repoManager repositories.RepositoryManager
+	shares      map[string]float64 // Quota del saldo per worker (0.5 = 50%)
+}
+
+// NewBudgetService crea il servizio con le quote configurate per worker
+func NewBudgetService(repoManager repositories.RepositoryManager, shares map[string]float64) *BudgetService {
+	return &BudgetService{repoManager: repoManager, shares: shares}
+}
+
+// HasBudget indica se al worker è assegnata una quota del saldo
+func (s *BudgetService) HasBudget(worker string) bool {
+	if s == nil {
+		return false
+	}
+	_, ok := s.shares[worker]
+	return ok
+}
+
+// Available restituisce il budget del worker, mai superiore al saldo reale dell'account
+// Alla prima chiamata, o se la quota configurata è cambiata, il worker riceve una nuova allocazione:
+// share × balance, con PnL realizzato azzerato. Senza quota il worker usa l'intero saldo
+func (s *BudgetService) Available(ctx context.Context, worker string, balance float64) (float64, error) {
+	if s == nil {
+		return balance, nil
+	}
+	share, ok := s.shares[worker]
+	if !ok {
+		return balance, nil
+	}
+
+	budget, err := s.repoManager.WorkerBudget().GetByWorker(ctx, worker)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("failed to get budget of %s: %w", worker, err)
+	}
+	if budget == nil || budget.Share != share {
+		budget, err = s.allocate(ctx, budget, worker, share, balance)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return math.Min(budget.Available(), balance), nil
+}
+
+// allocate assegna al worker la sua quota del saldo corrente, sostituendo l'eventuale allocazione precedente
+func (s *BudgetService) allocate(ctx context.Context, previous *models.WorkerBudget, worker string, share, balance float64) (*models.WorkerBudget, error) {
+	budget := &models.WorkerBudget{
+		Worker:      worker,
+		Share:       share,
+		Capital:     share * balance,
+		AllocatedAt: time.Now().UTC(),
+	}
+	if previous != nil {
+		budget.ID = previous.ID
+		log.Printf("💼 Budget %s: quota cambiata da %.0f%% a %.0f%%, PnL realizzato %.2f USDT azzerato",
+			worker, previous.Share*100, share*100, previous.RealizedPnL)
+	}
+	if err := s.repoManager.WorkerBudget().Save(ctx, budget); err != nil {
+		return nil, fmt.Errorf("failed to allocate budget of %s: %w", worker, err)
+	}
+	log.Printf("💼 Budget %s: allocati %.2f USDT (%.0f%% di %.2f)", worker, budget.Capital, share*100, balance)
+	return budget, nil
+}
+
+// RecordPnL aggiunge il PnL realizzato di una posizione chiusa al budget del worker
+// Ignorato per i worker senza quota o non ancora allocati
+func (s *BudgetService) RecordPnL(ctx context.Context, worker string, pnl float64) error {
+	if pnl == 0 || !s.HasBudget(worker) {
+		return nil
+	}
+
+	err := s.repoManager.WorkerBudget().AddRealizedPnL(ctx, worker, pnl)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record PnL of %s: %w", worker, err)
+	}
+	log.Printf("💼 Budget %s: PnL realizzato %+.2f USDT", worker, pnl)
+	return nil
+}
+
+// List restituisce i budget allocati
+func (s *BudgetService) List(ctx context.Context) ([]*models.WorkerBudget, error) {
+	budgets, err := s.repoManager.WorkerBudget().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worker budgets: %w", err)
+	}
+	return budgets, nil
+}
+
+// SetBudgets attribuisce il PnL degli ordini chiusi al budget del worker che li ha creati
+func (s *OrderService) SetBudgets(budgets *BudgetService) {
+	s.budgets = budgets
+}
+
+// creditBudget aggiunge al budget il PnL di un ordine il cui risultato diventa Profit o Loss
+// Il worker è l'autore del record "created" dell'audit trail
+func (s *OrderService) creditBudget(ctx context.Context, before, after *models.Order) {
+	if s.budgets == nil || after.Result == before.Result ||
+		(after.Result != models.OrderResultProfit && after.Result != models.OrderResultLoss) {
+		return
+	}
+
+	created, err := s.repoManager.OrderAudit().GetByOrderIDAndField(ctx, after.OrderID, "created")
+	if err != nil || len(created) == 0 {
+		log.Printf("⚠️  Budget: autore dell'ordine %s non trovato, PnL non attribuito: %v", after.OrderID, err)
+		return
+	}
+	if err := s.budgets.RecordPnL(ctx, created[0].ChangedBy, after.PnL); err != nil {
+		log.Printf("⚠️  Budget: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ConfigVersionService gestisce le versioni della configurazione di strategia e rischio
+type ConfigVersionService struct {
+	repoManager repositories.RepositoryManager
+}
+
+// NewConfigVersionService crea una nuova istanza di ConfigVersionService
+func NewConfigVersionService(repoManager repositories.RepositoryManager) *ConfigVersionService {
+	return &ConfigVersionService{repoManager: repoManager}
+}
+
+// Record salva lo snapshot come versione applicata ora e restituisce la versione registrata
+func (s *ConfigVersionService) Record(ctx context.Context, snapshot *config.Snapshot) (*models.ConfigVersion, error) {
+	env, err := json.Marshal(snapshot.Env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config environment: %w", err)
+	}
+
+	version := &models.ConfigVersion{
+		Hash:          snapshot.Hash,
+		Settings:      string(snapshot.Settings),
+		Env:           string(env),
+		LastAppliedAt: time.Now().UTC(),
+	}
+	if err := s.repoManager.ConfigVersion().Record(ctx, version); err != nil {
+		return nil, fmt.Errorf("failed to record config version: %w", err)
+	}
+	return version, nil
+}
+
+// List restituisce le ultime versioni applicate
+func (s *ConfigVersionService) List(ctx context.Context, limit int) ([]*models.ConfigVersion, error) {
+	versions, err := s.repoManager.ConfigVersion().List(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list config versions: %w", err)
+	}
+	return versions, nil
+}
+
+// Get recupera una versione dall'hash completo o da un suo prefisso non ambiguo
+func (s *ConfigVersionService) Get(ctx context.Context, hash string) (*models.ConfigVersion, error) {
+	if len(hash) < 4 {
+		return nil, fmt.Errorf("%w: config version must have at least 4 characters", ErrInvalidInput)
+	}
+
+	versions, err := s.repoManager.ConfigVersion().GetByHashPrefix(ctx, hash, 2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config version: %w", err)
+	}
+	switch len(versions) {
+	case 0:
+		return nil, fmt.Errorf("config version %s not found: %w", hash, gorm.ErrRecordNotFound)
+	case 1:
+		return versions[0], nil
+	default:
+		return nil, fmt.Errorf("%w: config version %s is ambiguous", ErrInvalidInput, hash)
+	}
+}
+
+// Rollback riscrive nel file .env le variabili di strategia e rischio della versione indicata
+// La nuova configurazione viene applicata al successivo avvio del bot
+func (s *ConfigVersionService) Rollback(ctx context.Context, hash, envFile string) (*models.ConfigVersion, error) {
+	version, err := s.Get(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var env map[string]string
+	if err := json.Unmarshal([]byte(version.Env), &env); err != nil {
+		return nil, fmt.Errorf("failed to decode config version %s: %w", version.ShortHash(), err)
+	}
+
+	label := fmt.Sprintf("%s (rollback %s)", version.ShortHash(), time.Now().UTC().Format(time.RFC3339))
+	if err := config.RewriteStrategyEnv(envFile, env, label); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/reporting"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/scoring"
+	"cross-exchange-arbitrage/taprocess"
+	"fmt"
+	"time"
+)
+
+// maxSignalCandleLag è il ritardo massimo tra la candela del segnale attesa e l'ultima presente in cache:
+// oltre questa soglia la cache ha un buco e le feature non corrisponderebbero a quelle calcolate in live
+const maxSignalCandleLag = 2 * time.Minute
+
+// DatasetService costruisce i dataset di addestramento del modello di scoring
+// incrociando gli ordini chiusi con la cache delle candele
+type DatasetService struct {
+	repoManager repositories.RepositoryManager
+	sources     taprocess.IndicatorSources // Sorgenti di prezzo degli indicatori, le stesse del worker live
+}
+
+// NewDatasetService crea una nuova istanza di DatasetService
+func NewDatasetService(repoManager repositories.RepositoryManager, sources taprocess.IndicatorSources) *DatasetService {
+	return &DatasetService{repoManager: repoManager, sources: sources}
+}
+
+// BuildTrainingSet costruisce un esempio etichettato per ogni ordine chiuso del simbolo creato nel periodo
+// Le feature sono calcolate come nel worker live, sulla candela chiusa che precede la creazione dell'ordine;
+// gli ordini senza storia sufficiente in cache vengono saltati e conteggiati in skipped
+func (s *DatasetService) BuildTrainingSet(ctx context.Context, symbol string, from, to time.Time) ([]reporting.DatasetRow, int, error) {
+	if symbol == "" {
+		return nil, 0, fmt.Errorf("%w: symbol is required", ErrInvalidInput)
+	}
+	if !from.Before(to) {
+		return nil, 0, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+
+	filter := repositories.OrderSearchFilter{Symbol: symbol, From: &from, To: &to}
+	pageQuery := repositories.OrderPageQuery{
+		SortBy:    repositories.OrderSortCreatedAt,
+		Direction: repositories.SortAsc,
+	}
+
+	var rows []reporting.DatasetRow
+	skipped := 0
+	for {
+		page, err := s.repoManager.Order().Search(ctx, filter, pageQuery)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to load orders: %w", err)
+		}
+
+		for _, order := range page.Orders {
+			if order.Result != models.OrderResultProfit && order.Result != models.OrderResultLoss {
+				continue
+			}
+			row, ok, err := s.buildRow(ctx, order)
+			if err != nil {
+				return nil, 0, err
+			}
+			if !ok {
+				skipped++
+				continue
+			}
+			rows = append(rows, *row)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		pageQuery.Cursor = page.NextCursor
+	}
+	return rows, skipped, nil
+}
+
+// buildRow calcola le feature di un ordine chiuso; restituisce false se la cache non copre il segnale
+func (s *DatasetService) buildRow(ctx context.Context, order *models.Order) (*reporting.DatasetRow, bool, error) {
+	// L'ordine viene aperto nel minuto successivo alla chiusura della candela del segnale
+	signalTime := order.CreatedAt.UTC().Truncate(time.Minute).Add(-time.Minute)
+
+	records, err := s.repoManager.Candle().GetLastBefore(ctx, order.Symbol, models.DerivativesMarket, models.Timeframe1m, signalTime, scoring.FeatureCandles)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load candles for order %s: %w", order.OrderID, err)
+	}
+	if len(records) == 0 || signalTime.Sub(records[len(records)-1].OpenTime) > maxSignalCandleLag {
+		return nil, false, nil
+	}
+
+	// Il lato decide feature ed etichetta: se non è verificabile l'esempio è scartato invece di finire nel lato sbagliato
+	if !sideMatchesBrackets(order) {
+		return nil, false, nil
+	}
+
+	candles := make([]models.Candle, len(records))
+	for i, record := range records {
+		candles[i] = record.ToCandle()
+	}
+
+	side := models.OrderSideBuy
+	if order.Side == models.OrderSideTypeSell {
+		side = models.OrderSideSell
+	}
+	features, err := scoring.BreakoutFeatures(order.Symbol, side, candles, s.sources)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	label := 0
+	if order.Result == models.OrderResultProfit {
+		label = 1
+	}
+	return &reporting.DatasetRow{
+		OrderID:       order.OrderID,
+		Symbol:        order.Symbol,
+		Side:          side,
+		SignalTime:    records[len(records)-1].OpenTime,
+		Features:      features,
+		Label:         label,
+		PnL:           order.PnL,
+		PnLPercentage: order.PnLPercentage,
+	}, true, nil
+}
+
+// sideMatchesBrackets indica se il lato salvato è coerente con take profit e stop loss dell'ordine
+// (long: TP sopra e SL sotto il prezzo; short: il contrario). Gli ingressi salvati prima della correzione
+// del lato risultano tutti Buy: senza entrambi i livelli, o con livelli dal lato opposto, il lato non è affidabile
+func sideMatchesBrackets(order *models.Order) bool {
+	if order.TakeProfitPrice == nil || order.StopLossPrice == nil {
+		return false
+	}
+	takeProfit, stopLoss := *order.TakeProfitPrice, *order.StopLossPrice
+	switch order.Side {
+	case models.OrderSideTypeBuy:
+		return takeProfit > order.OrderPrice && stopLoss < order.OrderPrice
+	case models.OrderSideTypeSell:
+		return takeProfit < order.OrderPrice && stopLoss > order.OrderPrice
+	default:
+		return false
+	}
+}
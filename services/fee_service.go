@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/repositories"
+)
+
+// AccountOverview è la configurazione degli account e le commissioni effettive di tutte le venue
+type AccountOverview struct {
+	Accounts []*models.AccountSettings `json:"accounts"`
+	FeeRates []*models.FeeRate         `json:"fee_rates"`
+}
+
+// FeeService conserva configurazione degli account e commissioni effettive lette dalle venue all'avvio,
+// così i moduli che stimano i costi usano le commissioni reali dell'account invece di quelle configurate
+// Le letture sono salvate nel database: se una venue non risponde all'avvio restano valide le ultime salvate
+// Un FeeService nil non conosce alcuna commissione e i chiamanti usano quelle configurate
+type FeeService struct {
+	repoManager repositories.RepositoryManager
+
+	mu       sync.RWMutex
+	accounts map[string]*models.AccountSettings // venue -> configurazione
+	rates    map[string]*models.FeeRate         // venue:simbolo -> commissioni
+}
+
+// NewFeeService crea il servizio caricando configurazioni e commissioni salvate in precedenza
+func NewFeeService(ctx context.Context, repoManager repositories.RepositoryManager) (*FeeService, error) {
+	s := &FeeService{
+		repoManager: repoManager,
+		accounts:    make(map[string]*models.AccountSettings),
+		rates:       make(map[string]*models.FeeRate),
+	}
+
+	settings, err := repoManager.AccountSettings().ListSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account settings: %w", err)
+	}
+	for _, account := range settings {
+		s.accounts[account.Exchange] = account
+	}
+	rates, err := repoManager.AccountSettings().ListFeeRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fee rates: %w", err)
+	}
+	for _, rate := range rates {
+		s.rates[feeKey(rate.Exchange, rate.Symbol)] = rate
+	}
+	return s, nil
+}
+
+// Refresh legge dalla venue la configurazione dell'account e le commissioni dei simboli indicati e le salva
+// Una lettura fallita viene registrata e lascia in uso il valore salvato in precedenza
+func (s *FeeService) Refresh(ctx context.Context, venue string, reader orderprocessor.AccountReader, symbols []string) {
+	if s == nil || reader == nil {
+		return
+	}
+
+	if account, err := reader.GetAccountInfo(ctx); err != nil {
+		log.Printf("⚠️  Configurazione dell'account %s non disponibile: %v", venue, err)
+	} else {
+		account.Exchange = venue
+		if err := s.repoManager.AccountSettings().SaveSettings(ctx, account); err != nil {
+			log.Printf("⚠️  Configurazione dell'account %s non salvata: %v", venue, err)
+		}
+		s.mu.Lock()
+		s.accounts[venue] = account
+		s.mu.Unlock()
+		log.Printf("🏦 Account %s: unificato %t, margine %s", venue, account.Unified, account.MarginMode)
+	}
+
+	for _, symbol := range symbols {
+		rate, err := reader.GetFeeRate(ctx, symbol)
+		if err != nil {
+			log.Printf("⚠️  Commissioni %s su %s non disponibili: %v", symbol, venue, err)
+			continue
+		}
+		rate.Exchange = venue
+		rate.Symbol = strings.ToUpper(symbol)
+		if err := s.repoManager.AccountSettings().SaveFeeRate(ctx, rate); err != nil {
+			log.Printf("⚠️  Commissioni %s su %s non salvate: %v", symbol, venue, err)
+		}
+		s.mu.Lock()
+		s.rates[feeKey(venue, rate.Symbol)] = rate
+		s.mu.Unlock()
+		log.Printf("💸 Commissioni %s su %s: maker %.4f%%, taker %.4f%%", rate.Symbol, venue, rate.MakerRate*100, rate.TakerRate*100)
+	}
+}
+
+// TakerFee restituisce la commissione taker effettiva di un simbolo su una venue; false se non è nota
+func (s *FeeService) TakerFee(venue, symbol string) (float64, bool) {
+	rate, ok := s.rate(venue, symbol)
+	if !ok {
+		return 0, false
+	}
+	return rate.TakerRate, true
+}
+
+// MakerFee restituisce la commissione maker effettiva di un simbolo su una venue; false se non è nota
+func (s *FeeService) MakerFee(venue, symbol string) (float64, bool) {
+	rate, ok := s.rate(venue, symbol)
+	if !ok {
+		return 0, false
+	}
+	return rate.MakerRate, true
+}
+
+// TakerFeeOr restituisce la commissione taker effettiva, o fallback se non è nota
+func (s *FeeService) TakerFeeOr(venue, symbol string, fallback float64) float64 {
+	if fee, ok := s.TakerFee(venue, symbol); ok {
+		return fee
+	}
+	return fallback
+}
+
+// IsUnified indica se l'account della venue è unificato, e può quindi finanziare gli acquisti spot con il margine
+func (s *FeeService) IsUnified(venue string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	account, ok := s.accounts[strings.ToLower(venue)]
+	return ok && account.Unified
+}
+
+// Overview restituisce configurazioni e commissioni note, in ordine di venue e simbolo
+func (s *FeeService) Overview() AccountOverview {
+	overview := AccountOverview{Accounts: []*models.AccountSettings{}, FeeRates: []*models.FeeRate{}}
+	if s == nil {
+		return overview
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, account := range s.accounts {
+		copied := *account
+		overview.Accounts = append(overview.Accounts, &copied)
+	}
+	for _, rate := range s.rates {
+		copied := *rate
+		overview.FeeRates = append(overview.FeeRates, &copied)
+	}
+	sort.Slice(overview.Accounts, func(i, j int) bool { return overview.Accounts[i].Exchange < overview.Accounts[j].Exchange })
+	sort.Slice(overview.FeeRates, func(i, j int) bool {
+		return feeKey(overview.FeeRates[i].Exchange, overview.FeeRates[i].Symbol) < feeKey(overview.FeeRates[j].Exchange, overview.FeeRates[j].Symbol)
+	})
+	return overview
+}
+
+// rate restituisce le commissioni note di un simbolo su una venue
+func (s *FeeService) rate(venue, symbol string) (*models.FeeRate, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rate, ok := s.rates[feeKey(venue, symbol)]
+	return rate, ok
+}
+
+// feeKey è la chiave delle commissioni di un simbolo su una venue
+func feeKey(venue, symbol string) string {
+	return strings.ToLower(venue) + ":" + strings.ToUpper(symbol)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"cross-exchange-arbitrage/correlation"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrPermanentJobFailure indica un errore per cui ripetere il job è inutile (es. payload non valido)
+var ErrPermanentJobFailure = errors.New("permanent job failure")
+
+// jobBatchSize è il numero massimo di job eseguiti per ciclo
+const jobBatchSize = 50
+
+// JobHandler esegue un job differito; payload è il JSON salvato alla schedulazione
+type JobHandler func(ctx context.Context, payload []byte) error
+
+// JobQueue è una coda persistente di azioni differite: i job sono salvati nel database
+// e vengono eseguiti dal worker della coda quando scade il loro istante di esecuzione
+type JobQueue struct {
+	repoManager repositories.RepositoryManager
+	retryDelay  time.Duration
+	mu          sync.RWMutex
+	handlers    map[string]JobHandler
+}
+
+// NewJobQueue crea una nuova coda; retryDelay è l'attesa base prima di ripetere un job fallito
+func NewJobQueue(repoManager repositories.RepositoryManager, retryDelay time.Duration) *JobQueue {
+	return &JobQueue{
+		repoManager: repoManager,
+		retryDelay:  retryDelay,
+		handlers:    make(map[string]JobHandler),
+	}
+}
+
+// Register associa l'handler al tipo di job
+func (q *JobQueue) Register(jobType string, handler JobHandler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// Schedule salva un job da eseguire dopo delay
+func (q *JobQueue) Schedule(ctx context.Context, jobType string, payload interface{}, delay time.Duration) (*models.ScheduledJob, error) {
+	if jobType == "" {
+		return nil, fmt.Errorf("%w: job type is required", ErrInvalidInput)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	job := &models.ScheduledJob{
+		Type:    jobType,
+		Payload: string(data),
+		RunAt:   time.Now().Add(delay).UTC(),
+	}
+	if err := q.repoManager.ScheduledJob().Enqueue(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return job, nil
+}
+
+// Recover rimette in attesa i job rimasti in esecuzione all'arresto precedente
+func (q *JobQueue) Recover(ctx context.Context) (int64, error) {
+	released, err := q.repoManager.ScheduledJob().ReleaseRunning(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to release running jobs: %w", err)
+	}
+	return released, nil
+}
+
+// RunDue esegue i job scaduti e restituisce quanti ne sono stati presi in carico
+// Un job fallito viene ripetuto con attesa crescente fino a MaxAttempts, poi marcato failed
+func (q *JobQueue) RunDue(ctx context.Context) (int, error) {
+	jobs, err := q.repoManager.ScheduledJob().ClaimDue(ctx, time.Now(), jobBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim due jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		if err := q.run(ctx, job); err != nil {
+			return len(jobs), err
+		}
+	}
+	return len(jobs), nil
+}
+
+// Pending restituisce i job in attesa in ordine di esecuzione
+func (q *JobQueue) Pending(ctx context.Context, limit int) ([]*models.ScheduledJob, error) {
+	jobs, err := q.repoManager.ScheduledJob().ListByStatus(ctx, models.JobStatusPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// run esegue un job e ne registra l'esito; l'errore restituito riguarda solo il salvataggio dell'esito
+func (q *JobQueue) run(ctx context.Context, job *models.ScheduledJob) error {
+	repo := q.repoManager.ScheduledJob()
+
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Type]
+	q.mu.RUnlock()
+	if !ok {
+		correlation.Logf(ctx, "❌ Job %d: nessun handler registrato per il tipo %s", job.ID, job.Type)
+		return repo.Fail(ctx, job.ID, time.Now(), fmt.Sprintf("no handler registered for job type %s", job.Type))
+	}
+
+	runErr := handler(ctx, []byte(job.Payload))
+	if runErr == nil {
+		correlation.Logf(ctx, "✅ Job %d (%s) eseguito", job.ID, job.Type)
+		return repo.Complete(ctx, job.ID, time.Now())
+	}
+
+	if errors.Is(runErr, ErrPermanentJobFailure) || job.Attempts >= job.MaxAttempts {
+		correlation.Logf(ctx, "❌ Job %d (%s) fallito definitivamente dopo %d tentativi: %v", job.ID, job.Type, job.Attempts, runErr)
+		return repo.Fail(ctx, job.ID, time.Now(), runErr.Error())
+	}
+
+	retryAt := time.Now().Add(q.retryDelay * time.Duration(job.Attempts))
+	correlation.Logf(ctx, "⚠️  Job %d (%s) fallito (tentativo %d/%d), nuovo tentativo alle %s: %v",
+		job.ID, job.Type, job.Attempts, job.MaxAttempts, retryAt.Format("15:04:05"), runErr)
+	return repo.Retry(ctx, job.ID, retryAt, runErr.Error())
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/strategy"
+)
+
+// KeyLevelsResult contiene i livelli chiave di un simbolo calcolati dalla cache delle candele
+type KeyLevelsResult struct {
+	Symbol string           `json:"symbol"`
+	At     time.Time        `json:"at"`
+	Levels []strategy.Level `json:"levels"`
+}
+
+// LevelsService calcola i livelli chiave (massimi e minimi del giorno e della settimana precedenti, aperture di
+// mezzanotte e della sessione) dalle candele 1m in cache, per le strategie e per i grafici delle REST API
+type LevelsService struct {
+	repoManager repositories.RepositoryManager
+	levels      *strategy.KeyLevels
+}
+
+// NewLevelsService crea una nuova istanza di LevelsService con l'orario di apertura della sessione (dopo le 00:00 UTC)
+func NewLevelsService(repoManager repositories.RepositoryManager, sessionOpen time.Duration) (*LevelsService, error) {
+	levels, err := strategy.NewKeyLevels(sessionOpen)
+	if err != nil {
+		return nil, err
+	}
+	return &LevelsService{repoManager: repoManager, levels: levels}, nil
+}
+
+// GetLevels calcola i livelli chiave del simbolo all'istante at
+func (s *LevelsService) GetLevels(ctx context.Context, symbol string, at time.Time) (*KeyLevelsResult, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return nil, fmt.Errorf("%w: symbol is required", ErrInvalidInput)
+	}
+
+	at = at.UTC()
+	records, err := s.repoManager.Candle().GetRange(ctx, symbol, models.DerivativesMarket, models.Timeframe1m, s.levels.Start(at), at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached candles: %w", err)
+	}
+	candles := make([]models.Candle, len(records))
+	for i, record := range records {
+		candles[i] = record.ToCandle()
+	}
+
+	return &KeyLevelsResult{
+		Symbol: symbol,
+		At:     at,
+		Levels: s.levels.Compute(candles, at),
+	}, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os/user"
+
+	"cross-exchange-arbitrage/correlation"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+)
+
+// OperatorAction descrive un'azione manuale da registrare
+type OperatorAction struct {
+	Actor   string
+	Channel models.OperatorChannel
+	Action  string
+	Target  string
+	Details any   // Parametri dell'azione, salvati in JSON; nil se assenti
+	Err     error // Errore dell'azione; nil se riuscita
+}
+
+// OperatorAuditService registra le azioni manuali degli operatori arrivate da REST API e CLI
+type OperatorAuditService struct {
+	repoManager repositories.RepositoryManager
+}
+
+// NewOperatorAuditService crea una nuova istanza di OperatorAuditService
+func NewOperatorAuditService(repoManager repositories.RepositoryManager) *OperatorAuditService {
+	return &OperatorAuditService{repoManager: repoManager}
+}
+
+// Record registra un'azione; un errore di scrittura viene solo loggato, perché l'azione è già stata eseguita
+func (s *OperatorAuditService) Record(ctx context.Context, action OperatorAction) {
+	audit := &models.OperatorAudit{
+		Actor:         action.Actor,
+		Channel:       action.Channel,
+		Action:        action.Action,
+		Target:        action.Target,
+		Outcome:       models.OperatorOutcomeSuccess,
+		CorrelationID: correlation.ID(ctx),
+	}
+	if action.Err != nil {
+		audit.Outcome = models.OperatorOutcomeFailed
+		audit.Error = action.Err.Error()
+	}
+	switch details := action.Details.(type) {
+	case nil:
+	case []byte:
+		audit.Details = string(details)
+	default:
+		if data, err := json.Marshal(details); err == nil {
+			audit.Details = string(data)
+		}
+	}
+
+	if err := s.repoManager.OperatorAudit().Create(ctx, audit); err != nil {
+		log.Printf("⚠️  Azione di %s (%s %s) non registrata nell'audit degli operatori: %v", audit.Actor, audit.Channel, audit.Action, err)
+	}
+}
+
+// Search recupera le azioni registrate che soddisfano i filtri, dalla più recente
+func (s *OperatorAuditService) Search(ctx context.Context, filter repositories.OperatorAuditFilter, limit, offset int) ([]*models.OperatorAudit, error) {
+	return s.repoManager.OperatorAudit().Search(ctx, filter, limit, offset)
+}
+
+// LocalOperator restituisce l'utente di sistema che esegue la CLI, autore delle azioni da riga di comando
+func LocalOperator() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	return "unknown"
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/events"
+	"cross-exchange-arbitrage/models"
+	"log"
+	"time"
+)
+
+// SetEvents abilita la pubblicazione degli eventi di trading (ordine piazzato, eseguito, posizione chiusa)
+func (s *OrderService) SetEvents(emitter *events.Emitter) {
+	s.events = emitter
+}
+
+// emitPlaced pubblica order.placed per un ordine appena salvato
+func (s *OrderService) emitPlaced(ctx context.Context, order *models.Order, statusName string) {
+	if s.events == nil {
+		return
+	}
+	event := events.NewEvent(events.TypeOrderPlaced, order.OrderID, database.ChangedByFromContext(ctx), order.Symbol)
+	event.Order = orderEventData(order, statusName)
+	s.events.Emit(event)
+}
+
+// emitChanges pubblica gli eventi corrispondenti al passaggio di un ordine da before ad after
+// order.filled quando lo stato diventa Filled o il worker rileva la posizione aperta (risultato Done);
+// position.closed quando il risultato diventa Profit o Loss
+func (s *OrderService) emitChanges(ctx context.Context, before, after *models.Order) {
+	if s.events == nil {
+		return
+	}
+	source := database.ChangedByFromContext(ctx)
+
+	statusName := s.orderStatusName(ctx, after.OrderStatusID)
+	filledNow := after.OrderStatusID != before.OrderStatusID && statusName == string(models.OrderStatusFilled)
+	if filledNow || (before.Result == models.OrderResultPending && after.Result == models.OrderResultDone) {
+		event := events.NewEvent(events.TypeOrderFilled, after.OrderID, source, after.Symbol)
+		event.Order = orderEventData(after, statusName)
+		s.events.Emit(event)
+	}
+
+	if after.Result != before.Result && (after.Result == models.OrderResultProfit || after.Result == models.OrderResultLoss) {
+		closedAt := time.Now().UTC()
+		pnlPercentage := after.PnLPercentage
+		event := events.NewEvent(events.TypePositionClosed, after.OrderID, source, after.Symbol)
+		event.Position = &events.Position{
+			PositionID:    after.OrderID,
+			Symbol:        after.Symbol,
+			Side:          string(after.Side),
+			Quantity:      after.Quantity,
+			EntryPrice:    after.OrderPrice,
+			PnL:           after.PnL,
+			PnLPercentage: &pnlPercentage,
+			Result:        string(after.Result),
+			OpenedAt:      after.CreatedAt,
+			ClosedAt:      &closedAt,
+		}
+		s.events.Emit(event)
+	}
+}
+
+// orderStatusName restituisce il nome dello stato (gli stati sono in cache nel repository)
+func (s *OrderService) orderStatusName(ctx context.Context, statusID uint) string {
+	status, err := s.repoManager.OrderStatus().GetByID(ctx, statusID)
+	if err != nil {
+		log.Printf("⚠️  Stato ordine %d non trovato per l'evento: %v", statusID, err)
+		return ""
+	}
+	return status.StatusName
+}
+
+// orderEventData converte un ordine nel formato stabile degli eventi
+func orderEventData(order *models.Order, statusName string) *events.Order {
+	return &events.Order{
+		OrderID:       order.OrderID,
+		Symbol:        order.Symbol,
+		Side:          string(order.Side),
+		Price:         order.OrderPrice,
+		Quantity:      order.Quantity,
+		StopLoss:      order.StopLossPrice,
+		TakeProfit:    order.TakeProfitPrice,
+		Status:        statusName,
+		ConfigVersion: order.ConfigVersion,
+	}
+}
@@ -2,16 +2,33 @@ package services
 
 import (
 	"context"
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/events"
 	"cross-exchange-arbitrage/models"
 	"cross-exchange-arbitrage/repositories"
+	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrInvalidInput indica parametri non validi forniti dal chiamante
+var ErrInvalidInput = errors.New("invalid input")
+
+const (
+	// maxUpdateRetries è il numero massimo di tentativi di un aggiornamento in caso di conflitto di versione
+	maxUpdateRetries = 3
+	// updateRetryBackoff è l'attesa base tra un tentativo e l'altro (moltiplicata per il tentativo)
+	updateRetryBackoff = 50 * time.Millisecond
+)
+
 // OrderService gestisce la logica business per gli ordini
 type OrderService struct {
-	repoManager repositories.RepositoryManager
+	repoManager   repositories.RepositoryManager
+	configVersion string          // Hash della configurazione di strategia attiva, assegnato ai nuovi ordini
+	events        *events.Emitter // Pubblicazione degli eventi di trading; nil se disabilitata
+	budgets       *BudgetService  // Budget virtuali dei worker aggiornati dal PnL realizzato; nil se disabilitati
 }
 
 // NewOrderService crea una nuova istanza di OrderService
@@ -21,8 +38,18 @@ func NewOrderService(repoManager repositories.RepositoryManager) *OrderService {
 	}
 }
 
+// SetConfigVersion imposta la versione della configurazione di strategia assegnata ai nuovi ordini
+func (s *OrderService) SetConfigVersion(hash string) {
+	s.configVersion = hash
+}
+
 // CreateOrder crea un nuovo ordine con validazioni business
+// Se l'ordine non indica una versione di configurazione riceve quella attiva
 func (s *OrderService) CreateOrder(ctx context.Context, order *models.Order) error {
+	if order.ConfigVersion == "" {
+		order.ConfigVersion = s.configVersion
+	}
+
 	// Validazioni business
 	if err := s.validateOrder(order); err != nil {
 		return fmt.Errorf("order validation failed: %w", err)
@@ -46,67 +73,110 @@ func (s *OrderService) CreateOrder(ctx context.Context, order *models.Order) err
 		return fmt.Errorf("order status %s is not active", status.StatusName)
 	}
 
-	// Crea l'ordine
+	// Crea l'ordine (il record di audit "created" viene scritto dal plugin di audit)
 	if err := s.repoManager.Order().Create(ctx, order); err != nil {
 		return fmt.Errorf("failed to create order: %w", err)
 	}
 
-	// Crea record di audit per la creazione
-	audit := &models.OrderAudit{
-		OrderID:   order.OrderID,
-		FieldName: "created",
-		OldValue:  nil,
-		NewValue:  func() *string { v := "Order created"; return &v }(),
-		ChangedBy: "system",
+	s.emitPlaced(ctx, order, status.StatusName)
+	return nil
+}
+
+// RecordRejectedOrder registra un ordine rifiutato dall'exchange per l'analisi dei rifiuti
+// L'ordine è identificato dal suo ID cliente, ha risultato Rejected e non genera eventi di trading
+func (s *OrderService) RecordRejectedOrder(ctx context.Context, order *models.Order) error {
+	if order.ConfigVersion == "" {
+		order.ConfigVersion = s.configVersion
 	}
-	if err := s.repoManager.OrderAudit().Create(ctx, audit); err != nil {
-		// Log dell'errore ma non fallisce la creazione dell'ordine
-		fmt.Printf("Warning: failed to create audit record: %v\n", err)
+	order.Result = models.OrderResultRejected
+	if order.RejectReason == "" {
+		order.RejectReason = models.RejectReasonOther
 	}
+	if err := s.validateOrder(order); err != nil {
+		return fmt.Errorf("order validation failed: %w", err)
+	}
+
+	status, err := s.repoManager.OrderStatus().GetByStatusName(ctx, string(models.OrderStatusRejected))
+	if err != nil {
+		return fmt.Errorf("failed to get rejected order status: %w", err)
+	}
+	order.OrderStatusID = status.ID
 
+	if err := s.repoManager.Order().Create(ctx, order); err != nil {
+		return fmt.Errorf("failed to record rejected order: %w", err)
+	}
 	return nil
 }
 
-// UpdateOrder aggiorna un ordine esistente con audit trail
-func (s *OrderService) UpdateOrder(ctx context.Context, order *models.Order) error {
+// UpdateOrder aggiorna un ordine esistente con audit trail e optimistic locking
+// mutate riceve l'ordine appena letto dal database e applica le modifiche; se un altro processo
+// (es. il job di riconciliazione) aggiorna l'ordine nel frattempo, l'ordine viene riletto e mutate
+// rieseguito fino a maxUpdateRetries volte
+func (s *OrderService) UpdateOrder(ctx context.Context, orderID string, mutate func(order *models.Order) error) error {
+	return retryOnVersionConflict(ctx, "update order", func() error {
+		return s.tryUpdateOrder(ctx, orderID, mutate)
+	})
+}
+
+// retryOnVersionConflict riesegue try finché non fallisce per un conflitto di versione, fino a maxUpdateRetries volte
+// Ogni tentativo deve rileggere l'ordine, così le validazioni sono ripetute sullo stato aggiornato
+func retryOnVersionConflict(ctx context.Context, action string, try func() error) error {
+	var err error
+	for attempt := 1; attempt <= maxUpdateRetries; attempt++ {
+		err = try()
+		if !errors.Is(err, repositories.ErrVersionConflict) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * updateRetryBackoff):
+		}
+	}
+
+	return fmt.Errorf("failed to %s after %d attempts: %w", action, maxUpdateRetries, err)
+}
+
+// tryUpdateOrder esegue un singolo tentativo di aggiornamento
+func (s *OrderService) tryUpdateOrder(ctx context.Context, orderID string, mutate func(order *models.Order) error) error {
 	// Recupera l'ordine esistente per confronto
-	existingOrder, err := s.repoManager.Order().GetByOrderID(ctx, order.OrderID)
+	existingOrder, err := s.repoManager.Order().GetByOrderID(ctx, orderID)
 	if err != nil {
 		return fmt.Errorf("failed to get existing order: %w", err)
 	}
 
+	order := *existingOrder
+	if err := mutate(&order); err != nil {
+		return err
+	}
+	order.ID = existingOrder.ID
+	order.OrderID = existingOrder.OrderID
+	order.Version = existingOrder.Version
+
 	// Validazioni business
-	if err := s.validateOrder(order); err != nil {
+	if err := s.validateOrder(&order); err != nil {
 		return fmt.Errorf("order validation failed: %w", err)
 	}
 
-	// Inizia transazione per aggiornamento e audit
-	tx, err := s.repoManager.BeginTransaction(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+	// Verifica che l'eventuale cambio di stato sia consentito
+	if err := s.checkStatusTransition(ctx, orderID, existingOrder.OrderStatusID, order.OrderStatusID, database.ChangedByFromContext(ctx)); err != nil {
+		return err
 	}
 
-	// Aggiorna l'ordine
-	if err := tx.Save(order).Error; err != nil {
-		s.repoManager.RollbackTransaction(tx)
+	// Aggiorna l'ordine solo se la versione non è cambiata; l'audit è scritto dal plugin
+	// nella stessa transazione dell'aggiornamento
+	if err := s.repoManager.Order().Update(ctx, &order); err != nil {
 		return fmt.Errorf("failed to update order: %w", err)
 	}
 
-	// Crea record di audit per le modifiche
-	if err := s.createAuditRecords(ctx, tx, existingOrder, order); err != nil {
-		s.repoManager.RollbackTransaction(tx)
-		return fmt.Errorf("failed to create audit records: %w", err)
-	}
-
-	// Commit transazione
-	if err := s.repoManager.CommitTransaction(tx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
+	s.emitChanges(ctx, existingOrder, &order)
+	s.creditBudget(ctx, existingOrder, &order)
 	return nil
 }
 
 // UpdateOrderStatus aggiorna solo lo stato di un ordine
+// Se un altro processo cambia l'ordine tra la lettura e la scrittura, la transizione viene riverificata sul nuovo stato
 func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID string, statusName string) error {
 	// Verifica che lo stato esista
 	status, err := s.repoManager.OrderStatus().GetByStatusName(ctx, statusName)
@@ -114,6 +184,13 @@ func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID string, st
 		return fmt.Errorf("invalid order status: %w", err)
 	}
 
+	return retryOnVersionConflict(ctx, "update order status", func() error {
+		return s.tryUpdateOrderStatus(ctx, orderID, status.ID)
+	})
+}
+
+// tryUpdateOrderStatus esegue un singolo tentativo di cambio di stato
+func (s *OrderService) tryUpdateOrderStatus(ctx context.Context, orderID string, statusID uint) error {
 	// Recupera l'ordine esistente
 	order, err := s.repoManager.Order().GetByOrderID(ctx, orderID)
 	if err != nil {
@@ -121,40 +198,25 @@ func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID string, st
 	}
 
 	// Verifica se lo stato è cambiato
-	if order.OrderStatusID == status.ID {
+	if order.OrderStatusID == statusID {
 		return nil // Nessun cambiamento necessario
 	}
 
-	// Inizia transazione
-	tx, err := s.repoManager.BeginTransaction(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+	// Verifica che la transizione sia consentita dal ciclo di vita dell'ordine
+	if err := s.checkStatusTransition(ctx, orderID, order.OrderStatusID, statusID, database.ChangedByFromContext(ctx)); err != nil {
+		return err
 	}
 
-	// Aggiorna lo stato
-	if err := tx.Model(&models.Order{}).Where("order_id = ?", orderID).Update("order_status_id", status.ID).Error; err != nil {
-		s.repoManager.RollbackTransaction(tx)
+	// Aggiorna lo stato solo se l'ordine è ancora alla versione letta (audit scritto dal plugin nella stessa transazione)
+	if err := s.repoManager.Order().UpdateStatus(ctx, orderID, order.Version, statusID); err != nil {
 		return fmt.Errorf("failed to update order status: %w", err)
 	}
 
-	// Crea record di audit
-	audit := &models.OrderAudit{
-		OrderID:   orderID,
-		FieldName: "order_status_id",
-		OldValue:  func() *string { v := fmt.Sprintf("%d", order.OrderStatusID); return &v }(),
-		NewValue:  func() *string { v := fmt.Sprintf("%d", status.ID); return &v }(),
-		ChangedBy: "system",
-	}
-	if err := tx.Create(audit).Error; err != nil {
-		s.repoManager.RollbackTransaction(tx)
-		return fmt.Errorf("failed to create audit record: %w", err)
-	}
-
-	// Commit transazione
-	if err := s.repoManager.CommitTransaction(tx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
+	updated := *order
+	updated.OrderStatusID = statusID
+	updated.Version = order.Version + 1
+	s.emitChanges(ctx, order, &updated)
+	s.creditBudget(ctx, order, &updated)
 	return nil
 }
 
@@ -171,36 +233,15 @@ func (s *OrderService) UpdateOrderResult(ctx context.Context, orderID string, re
 		return nil // Nessun cambiamento necessario
 	}
 
-	// Inizia transazione
-	tx, err := s.repoManager.BeginTransaction(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	// Aggiorna il risultato
-	if err := tx.Model(&models.Order{}).Where("order_id = ?", orderID).Update("result", result).Error; err != nil {
-		s.repoManager.RollbackTransaction(tx)
+	// Aggiorna il risultato (audit scritto dal plugin nella stessa transazione)
+	if err := s.repoManager.Order().UpdateResult(ctx, orderID, result); err != nil {
 		return fmt.Errorf("failed to update order result: %w", err)
 	}
 
-	// Crea record di audit
-	audit := &models.OrderAudit{
-		OrderID:   orderID,
-		FieldName: "result",
-		OldValue:  func() *string { v := string(order.Result); return &v }(),
-		NewValue:  func() *string { v := string(result); return &v }(),
-		ChangedBy: "system",
-	}
-	if err := tx.Create(audit).Error; err != nil {
-		s.repoManager.RollbackTransaction(tx)
-		return fmt.Errorf("failed to create audit record: %w", err)
-	}
-
-	// Commit transazione
-	if err := s.repoManager.CommitTransaction(tx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
+	updated := *order
+	updated.Result = result
+	s.emitChanges(ctx, order, &updated)
+	s.creditBudget(ctx, order, &updated)
 	return nil
 }
 
@@ -215,39 +256,11 @@ func (s *OrderService) UpdateOrderPnL(ctx context.Context, orderID string, curre
 	// Calcola nuovo PnL
 	order.CalculatePnL(currentPrice)
 
-	// Inizia transazione
-	tx, err := s.repoManager.BeginTransaction(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	// Aggiorna PnL
-	if err := tx.Model(&models.Order{}).Where("order_id = ?", orderID).Updates(map[string]interface{}{
-		"pnl":            order.PnL,
-		"pnl_percentage": order.PnLPercentage,
-	}).Error; err != nil {
-		s.repoManager.RollbackTransaction(tx)
+	// Aggiorna PnL (audit scritto dal plugin nella stessa transazione)
+	if err := s.repoManager.Order().UpdatePnL(ctx, orderID, order.PnL, order.PnLPercentage); err != nil {
 		return fmt.Errorf("failed to update order PnL: %w", err)
 	}
 
-	// Crea record di audit
-	audit := &models.OrderAudit{
-		OrderID:   orderID,
-		FieldName: "pnl_update",
-		OldValue:  func() *string { v := fmt.Sprintf("PnL: %.8f, PnL%%: %.4f", order.PnL, order.PnLPercentage); return &v }(),
-		NewValue:  func() *string { v := fmt.Sprintf("PnL: %.8f, PnL%%: %.4f", order.PnL, order.PnLPercentage); return &v }(),
-		ChangedBy: "system",
-	}
-	if err := tx.Create(audit).Error; err != nil {
-		s.repoManager.RollbackTransaction(tx)
-		return fmt.Errorf("failed to create audit record: %w", err)
-	}
-
-	// Commit transazione
-	if err := s.repoManager.CommitTransaction(tx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
@@ -268,6 +281,78 @@ func (s *OrderService) GetOrderWithAudit(ctx context.Context, orderID string) (*
 	return order, audits, nil
 }
 
+// GetOrderAuditDiffs recupera l'audit trail di un ordine come diff tipizzate, dal più recente
+// Le modifiche di stato vengono arricchite con il nome dello stato
+func (s *OrderService) GetOrderAuditDiffs(ctx context.Context, orderID string, limit, offset int) ([]*models.AuditDiff, error) {
+	// Verifica che l'ordine esista (anche archiviato) per distinguere "nessun audit" da "ordine inesistente"
+	if _, err := s.GetOrder(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	audits, err := s.repoManager.OrderAudit().GetByOrderID(ctx, orderID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit trail: %w", err)
+	}
+
+	statusNames := make(map[uint]string)
+	diffs := make([]*models.AuditDiff, 0, len(audits))
+	for _, audit := range audits {
+		diff := audit.ToDiff()
+		if audit.FieldName == "order_status_id" {
+			diff.BeforeLabel = s.statusLabel(ctx, diff.Before, statusNames)
+			diff.AfterLabel = s.statusLabel(ctx, diff.After, statusNames)
+		}
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+// GetAuditTrace restituisce le modifiche agli ordini fatte da un ciclo di worker o da una richiesta REST,
+// in ordine cronologico: con i log dello stesso identificativo ricostruisce un trade dall'inizio alla fine
+func (s *OrderService) GetAuditTrace(ctx context.Context, correlationID string, limit, offset int) ([]*models.AuditDiff, error) {
+	if correlationID == "" {
+		return nil, fmt.Errorf("%w: correlation id is required", ErrInvalidInput)
+	}
+
+	audits, err := s.repoManager.OrderAudit().GetByCorrelationID(ctx, correlationID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit trace: %w", err)
+	}
+
+	statusNames := make(map[uint]string)
+	diffs := make([]*models.AuditDiff, 0, len(audits))
+	for _, audit := range audits {
+		diff := audit.ToDiff()
+		if audit.FieldName == "order_status_id" {
+			diff.BeforeLabel = s.statusLabel(ctx, diff.Before, statusNames)
+			diff.AfterLabel = s.statusLabel(ctx, diff.After, statusNames)
+		}
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+// statusLabel restituisce il nome dello stato per un valore di audit, usando una cache locale
+func (s *OrderService) statusLabel(ctx context.Context, value interface{}, cache map[uint]string) string {
+	statusID, ok := value.(uint)
+	if !ok {
+		return ""
+	}
+
+	if name, found := cache[statusID]; found {
+		return name
+	}
+
+	name := ""
+	if status, err := s.repoManager.OrderStatus().GetByID(ctx, statusID); err == nil {
+		name = status.StatusName
+	}
+	cache[statusID] = name
+	return name
+}
+
 // GetTradingStatistics recupera statistiche di trading
 func (s *OrderService) GetTradingStatistics(ctx context.Context, symbol string) (*repositories.TradingStats, error) {
 	stats, err := s.repoManager.Order().GetTradingStats(ctx, symbol)
@@ -286,6 +371,117 @@ func (s *OrderService) GetOrdersByResult(ctx context.Context, result models.Orde
 	return orders, nil
 }
 
+// GetOrders recupera gli ordini con paginazione, filtrando opzionalmente per simbolo
+func (s *OrderService) GetOrders(ctx context.Context, symbol string, limit, offset int) ([]*models.Order, error) {
+	var orders []*models.Order
+	var err error
+	if symbol != "" {
+		orders, err = s.repoManager.Order().GetBySymbol(ctx, symbol, limit, offset)
+	} else {
+		orders, err = s.repoManager.Order().GetAll(ctx, limit, offset)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+	return orders, nil
+}
+
+// ListOrders recupera una pagina di ordini con paginazione a cursore e ordinamento configurabile
+func (s *OrderService) ListOrders(ctx context.Context, symbol string, page repositories.OrderPageQuery) (*repositories.OrderPage, error) {
+	return s.SearchOrders(ctx, repositories.OrderSearchFilter{Symbol: symbol}, page)
+}
+
+// SearchOrders ricerca gli ordini combinando i filtri (simbolo, lato, risultato, stato, date, PnL, tag, testo)
+func (s *OrderService) SearchOrders(ctx context.Context, filter repositories.OrderSearchFilter, page repositories.OrderPageQuery) (*repositories.OrderPage, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+	if filter.MinPnL != nil && filter.MaxPnL != nil && *filter.MinPnL > *filter.MaxPnL {
+		return nil, fmt.Errorf("%w: min_pnl must not exceed max_pnl", ErrInvalidInput)
+	}
+
+	result, err := s.repoManager.Order().Search(ctx, filter, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search orders: %w", err)
+	}
+	return result, nil
+}
+
+// GetOrder recupera un ordine per OrderID, cercandolo anche nell'archivio degli ordini chiusi
+func (s *OrderService) GetOrder(ctx context.Context, orderID string) (*models.Order, error) {
+	order, err := s.repoManager.Order().GetByOrderID(ctx, orderID)
+	if err == nil {
+		return order, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	archived, archiveErr := s.repoManager.OrderArchive().GetByOrderID(ctx, orderID)
+	if archiveErr != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	return archived.ToOrder(), nil
+}
+
+// AddOrderTag associa un tag e/o una nota ad un ordine esistente
+func (s *OrderService) AddOrderTag(ctx context.Context, orderID, tag, note, createdBy string) (*models.OrderTag, error) {
+	if models.NormalizeTag(tag) == "" && note == "" {
+		return nil, fmt.Errorf("%w: tag or note is required", ErrInvalidInput)
+	}
+
+	// Gli ordini archiviati possono ancora essere annotati
+	if _, err := s.GetOrder(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	orderTag := &models.OrderTag{
+		OrderID:   orderID,
+		Tag:       tag,
+		Note:      note,
+		CreatedBy: createdBy,
+	}
+	if err := s.repoManager.OrderTag().Create(ctx, orderTag); err != nil {
+		return nil, fmt.Errorf("failed to create order tag: %w", err)
+	}
+
+	return orderTag, nil
+}
+
+// GetOrderTags recupera i tag e le note di un ordine
+func (s *OrderService) GetOrderTags(ctx context.Context, orderID string) ([]*models.OrderTag, error) {
+	tags, err := s.repoManager.OrderTag().GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order tags: %w", err)
+	}
+	return tags, nil
+}
+
+// GetOrderTagsByOrderIDs recupera i tag di più ordini raggruppati per OrderID
+func (s *OrderService) GetOrderTagsByOrderIDs(ctx context.Context, orderIDs []string) (map[string][]*models.OrderTag, error) {
+	tags, err := s.repoManager.OrderTag().GetByOrderIDs(ctx, orderIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order tags: %w", err)
+	}
+	return tags, nil
+}
+
+// RemoveOrderTag elimina un tag verificando che appartenga all'ordine indicato
+func (s *OrderService) RemoveOrderTag(ctx context.Context, orderID string, tagID uint) error {
+	orderTag, err := s.repoManager.OrderTag().GetByID(ctx, tagID)
+	if err != nil {
+		return fmt.Errorf("failed to get order tag: %w", err)
+	}
+	if orderTag.OrderID != orderID {
+		return fmt.Errorf("tag %d does not belong to order %s: %w", tagID, orderID, gorm.ErrRecordNotFound)
+	}
+
+	if err := s.repoManager.OrderTag().Delete(ctx, tagID); err != nil {
+		return fmt.Errorf("failed to delete order tag: %w", err)
+	}
+	return nil
+}
+
 // validateOrder valida un ordine secondo le regole business
 func (s *OrderService) validateOrder(order *models.Order) error {
 	// Validazioni base
@@ -335,106 +531,3 @@ func (s *OrderService) validateOrder(order *models.Order) error {
 
 	return nil
 }
-
-// createAuditRecords crea record di audit per le modifiche
-func (s *OrderService) createAuditRecords(ctx context.Context, tx *gorm.DB, oldOrder, newOrder *models.Order) error {
-	// Audit per order_price
-	if oldOrder.OrderPrice != newOrder.OrderPrice {
-		audit := &models.OrderAudit{
-			OrderID:   newOrder.OrderID,
-			FieldName: "order_price",
-			OldValue:  func() *string { v := fmt.Sprintf("%.8f", oldOrder.OrderPrice); return &v }(),
-			NewValue:  func() *string { v := fmt.Sprintf("%.8f", newOrder.OrderPrice); return &v }(),
-			ChangedBy: "system",
-		}
-		if err := tx.Create(audit).Error; err != nil {
-			return err
-		}
-	}
-
-	// Audit per take_profit_price
-	if (oldOrder.TakeProfitPrice == nil && newOrder.TakeProfitPrice != nil) ||
-		(oldOrder.TakeProfitPrice != nil && newOrder.TakeProfitPrice == nil) ||
-		(oldOrder.TakeProfitPrice != nil && newOrder.TakeProfitPrice != nil && *oldOrder.TakeProfitPrice != *newOrder.TakeProfitPrice) {
-		audit := &models.OrderAudit{
-			OrderID:   newOrder.OrderID,
-			FieldName: "take_profit_price",
-			OldValue: func() *string {
-				if oldOrder.TakeProfitPrice == nil {
-					return nil
-				}
-				v := fmt.Sprintf("%.8f", *oldOrder.TakeProfitPrice)
-				return &v
-			}(),
-			NewValue: func() *string {
-				if newOrder.TakeProfitPrice == nil {
-					return nil
-				}
-				v := fmt.Sprintf("%.8f", *newOrder.TakeProfitPrice)
-				return &v
-			}(),
-			ChangedBy: "system",
-		}
-		if err := tx.Create(audit).Error; err != nil {
-			return err
-		}
-	}
-
-	// Audit per stop_loss_price
-	if (oldOrder.StopLossPrice == nil && newOrder.StopLossPrice != nil) ||
-		(oldOrder.StopLossPrice != nil && newOrder.StopLossPrice == nil) ||
-		(oldOrder.StopLossPrice != nil && newOrder.StopLossPrice != nil && *oldOrder.StopLossPrice != *newOrder.StopLossPrice) {
-		audit := &models.OrderAudit{
-			OrderID:   newOrder.OrderID,
-			FieldName: "stop_loss_price",
-			OldValue: func() *string {
-				if oldOrder.StopLossPrice == nil {
-					return nil
-				}
-				v := fmt.Sprintf("%.8f", *oldOrder.StopLossPrice)
-				return &v
-			}(),
-			NewValue: func() *string {
-				if newOrder.StopLossPrice == nil {
-					return nil
-				}
-				v := fmt.Sprintf("%.8f", *newOrder.StopLossPrice)
-				return &v
-			}(),
-			ChangedBy: "system",
-		}
-		if err := tx.Create(audit).Error; err != nil {
-			return err
-		}
-	}
-
-	// Audit per order_status_id
-	if oldOrder.OrderStatusID != newOrder.OrderStatusID {
-		audit := &models.OrderAudit{
-			OrderID:   newOrder.OrderID,
-			FieldName: "order_status_id",
-			OldValue:  func() *string { v := fmt.Sprintf("%d", oldOrder.OrderStatusID); return &v }(),
-			NewValue:  func() *string { v := fmt.Sprintf("%d", newOrder.OrderStatusID); return &v }(),
-			ChangedBy: "system",
-		}
-		if err := tx.Create(audit).Error; err != nil {
-			return err
-		}
-	}
-
-	// Audit per result
-	if oldOrder.Result != newOrder.Result {
-		audit := &models.OrderAudit{
-			OrderID:   newOrder.OrderID,
-			FieldName: "result",
-			OldValue:  func() *string { v := string(oldOrder.Result); return &v }(),
-			NewValue:  func() *string { v := string(newOrder.Result); return &v }(),
-			ChangedBy: "system",
-		}
-		if err := tx.Create(audit).Error; err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
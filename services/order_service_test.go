@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+
+	"gorm.io/gorm/logger"
+)
+
+// openTestRepositories apre un database su file temporaneo con tabelle e stati degli ordini
+func openTestRepositories(t *testing.T) repositories.RepositoryManager {
+	t.Helper()
+
+	db, err := database.Connect(&database.Config{
+		FilePath:      filepath.Join(t.TempDir(), "trading_bot.db"),
+		JournalMode:   "WAL",
+		BusyTimeoutMs: 5000,
+		MaxOpenConns:  4,
+	})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	db.Logger = logger.Discard
+	t.Cleanup(func() { _ = database.Close(db) })
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := database.InitializeOrderStatuses(db); err != nil {
+		t.Fatalf("order statuses: %v", err)
+	}
+	return repositories.NewRepositoryManager(db)
+}
+
+// Due transizioni concorrenti dallo stesso stato New verso due stati terminali: solo la prima può essere applicata,
+// la seconda deve essere riverificata sullo stato terminale e rifiutata invece di sovrascriverlo
+func TestConcurrentStatusTransitions(t *testing.T) {
+	ctx := context.Background()
+	repoManager := openTestRepositories(t)
+	service := NewOrderService(repoManager)
+
+	for round := 0; round < 20; round++ {
+		newStatus, err := repoManager.OrderStatus().GetByStatusName(ctx, string(models.OrderStatusNew))
+		if err != nil {
+			t.Fatalf("status New: %v", err)
+		}
+		order := &models.Order{
+			OrderID:       fmt.Sprintf("race-%d", round),
+			Symbol:        "DOGEUSDT",
+			Side:          models.OrderSideTypeBuy,
+			OrderPrice:    0.1,
+			Quantity:      100,
+			OrderStatusID: newStatus.ID,
+		}
+		if err := repoManager.Order().Create(ctx, order); err != nil {
+			t.Fatalf("order %s: %v", order.OrderID, err)
+		}
+
+		targets := []models.OrderStatus{models.OrderStatusFilled, models.OrderStatusCancelled}
+		errs := make([]error, len(targets))
+		var wg sync.WaitGroup
+		for i, target := range targets {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = service.UpdateOrderStatus(ctx, order.OrderID, string(target))
+			}()
+		}
+		wg.Wait()
+
+		applied := -1
+		for i, err := range errs {
+			switch {
+			case err == nil:
+				if applied >= 0 {
+					t.Fatalf("%s: both %s and %s applied", order.OrderID, targets[applied], targets[i])
+				}
+				applied = i
+			case !errors.Is(err, ErrInvalidStatusTransition):
+				t.Fatalf("%s -> %s: unexpected error %v", order.OrderID, targets[i], err)
+			}
+		}
+		if applied < 0 {
+			t.Fatalf("%s: no transition applied", order.OrderID)
+		}
+
+		stored, err := repoManager.Order().GetByOrderID(ctx, order.OrderID)
+		if err != nil {
+			t.Fatalf("reload %s: %v", order.OrderID, err)
+		}
+		if got := models.OrderStatus(stored.OrderStatus.StatusName); got != targets[applied] {
+			t.Errorf("%s: status = %s, want %s", order.OrderID, got, targets[applied])
+		}
+		if stored.Version != order.Version+1 {
+			t.Errorf("%s: version = %d, want %d", order.OrderID, stored.Version, order.Version+1)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/exchange"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/risk"
+
+	"gorm.io/gorm"
+)
+
+// priceTimeout è l'attesa massima della liquidità al miglior prezzo
+const priceTimeout = 5 * time.Second
+
+// OrderSlicerParams configura il frazionamento degli ingressi
+type OrderSlicerParams struct {
+	Method          models.SliceMethod
+	MaxBookFraction float64       // Frazione della liquidità al miglior prezzo oltre cui l'ingresso viene frazionato
+	Slices          int           // Numero di frazioni con twap, massimo con iceberg
+	Interval        time.Duration // Attesa tra due frazioni
+	QtyStep         float64       // Passo della quantità dei figli (1 se il processor arrotonda a unità intere)
+}
+
+// SlicePlan è la decisione di frazionare un ingresso
+type SlicePlan struct {
+	Total     float64 // Quantità dell'ordine logico
+	First     float64 // Quantità del primo figlio, inviato subito come ingresso
+	Liquidity float64 // Liquidità al miglior prezzo sul lato preso dall'ordine
+}
+
+// OrderSlicer fraziona gli ingressi troppo grandi per la liquidità al miglior prezzo in più ordini a mercato
+// inviati nel tempo (TWAP) o dimensionati sulla liquidità visibile (emulazione iceberg)
+// Il primo figlio è l'ingresso salvato tra gli ordini; i successivi ne aumentano quantità e prezzo medio,
+// così l'ordine logico resta uno solo. Lo stato del frazionamento è salvato nel database
+type OrderSlicer struct {
+	repoManager  repositories.RepositoryManager
+	orderService *OrderService
+	processor    orderprocessor.OrderProcessor
+	prices       exchange.Exchange
+	throttle     *orderprocessor.OrderThrottle
+	spread       *risk.SpreadGuard
+	params       OrderSlicerParams
+}
+
+// NewOrderSlicer crea il servizio di frazionamento sul processor della venue
+func NewOrderSlicer(repoManager repositories.RepositoryManager, orderService *OrderService, processor orderprocessor.OrderProcessor, prices exchange.Exchange, throttle *orderprocessor.OrderThrottle, spread *risk.SpreadGuard, params OrderSlicerParams) *OrderSlicer {
+	return &OrderSlicer{
+		repoManager:  repoManager,
+		orderService: orderService,
+		processor:    processor,
+		prices:       prices,
+		throttle:     throttle,
+		spread:       spread,
+		params:       params,
+	}
+}
+
+// Interval restituisce l'attesa tra due frazioni
+func (s *OrderSlicer) Interval() time.Duration {
+	return s.params.Interval
+}
+
+// Plan decide se l'ingresso va frazionato confrontando la quantità con la liquidità al miglior prezzo
+// Restituisce nil se l'ingresso può essere inviato intero
+func (s *OrderSlicer) Plan(ctx context.Context, symbol string, side models.OrderSide, quantity float64) (*SlicePlan, error) {
+	liquidity, _, err := s.topOfBook(ctx, symbol, side)
+	if err != nil {
+		return nil, err
+	}
+	if quantity <= liquidity*s.params.MaxBookFraction {
+		return nil, nil
+	}
+
+	first := s.nextQuantity(quantity, s.params.Slices, liquidity)
+	if first <= 0 || first >= quantity {
+		return nil, nil
+	}
+	return &SlicePlan{Total: quantity, First: first, Liquidity: liquidity}, nil
+}
+
+// Start registra l'ordine logico dopo l'invio del primo figlio, salvato come ingresso
+func (s *OrderSlicer) Start(ctx context.Context, entry *models.Order, plan *SlicePlan) (*models.SlicedOrder, error) {
+	order := &models.SlicedOrder{
+		ParentOrderID:   entry.OrderID,
+		Symbol:          entry.Symbol,
+		Side:            entry.Side,
+		Method:          s.params.Method,
+		Status:          models.SlicedOrderStatusActive,
+		TotalQuantity:   plan.Total,
+		FilledQuantity:  entry.Quantity,
+		SlicesPlaced:    1,
+		MaxSlices:       s.params.Slices,
+		IntervalSeconds: int(s.params.Interval / time.Second),
+	}
+	if err := s.repoManager.SlicedOrder().Create(ctx, order); err != nil {
+		return nil, fmt.Errorf("failed to save sliced order %s: %w", entry.OrderID, err)
+	}
+	log.Printf("🧩 Ingresso %s frazionato (%s): %.2f di %.2f inviati, liquidità al miglior prezzo %.2f",
+		entry.OrderID, order.Method, entry.Quantity, plan.Total, plan.Liquidity)
+	return order, nil
+}
+
+// Active verifica se l'ingresso ha ancora frazioni da inviare
+func (s *OrderSlicer) Active(ctx context.Context, parentOrderID string) (bool, error) {
+	order, err := s.repoManager.SlicedOrder().GetByParentOrderID(ctx, parentOrderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get sliced order %s: %w", parentOrderID, err)
+	}
+	return order.Status == models.SlicedOrderStatusActive, nil
+}
+
+// PlaceNext invia la frazione successiva dell'ordine logico e restituisce il suo stato aggiornato
+// Le frazioni residue vengono annullate se l'ingresso o la posizione sono stati chiusi nel frattempo,
+// o se un figlio viene rifiutato; un figlio scartato dal limite di ordini o dal controllo dello spread viene ritentato
+// alla frazione successiva
+func (s *OrderSlicer) PlaceNext(ctx context.Context, id uint) (*models.SlicedOrder, error) {
+	order, err := s.repoManager.SlicedOrder().GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sliced order %d: %w", id, err)
+	}
+	if order.Status != models.SlicedOrderStatusActive {
+		return order, nil
+	}
+
+	entry, err := s.repoManager.Order().GetByOrderID(ctx, order.ParentOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entry %s: %w", order.ParentOrderID, err)
+	}
+	if entry.Result != models.OrderResultPending && entry.Result != models.OrderResultDone {
+		return order, s.finish(ctx, order, models.SlicedOrderStatusCancelled, "ingresso chiuso prima dell'ultima frazione")
+	}
+	side := models.OrderSide(order.Side)
+	open, err := s.positionOpen(ctx, order.Symbol, side)
+	if err != nil {
+		return nil, err
+	}
+	if !open {
+		return order, s.finish(ctx, order, models.SlicedOrderStatusCancelled, "posizione chiusa prima dell'ultima frazione")
+	}
+
+	liquidity, price, err := s.topOfBook(ctx, order.Symbol, side)
+	if err != nil {
+		return nil, err
+	}
+	quantity := s.nextQuantity(order.RemainingQuantity(), order.MaxSlices-order.SlicesPlaced, liquidity)
+	if quantity <= 0 {
+		return order, s.finish(ctx, order, models.SlicedOrderStatusCompleted, "residuo sotto il passo della quantità")
+	}
+	if err := s.spread.Check(ctx, order.Symbol, s.prices.GetRealTimePrice); err != nil {
+		return order, nil
+	}
+	if err := s.throttle.Acquire(ctx, "bybit", order.Symbol); err != nil {
+		return order, nil
+	}
+
+	var stopLoss, takeProfit float64
+	if entry.StopLossPrice != nil {
+		stopLoss = *entry.StopLossPrice
+	}
+	if entry.TakeProfitPrice != nil {
+		takeProfit = *entry.TakeProfitPrice
+	}
+	sequence := order.SlicesPlaced + 1
+	orderLinkID := orderprocessor.GenerateOrderLinkID(fmt.Sprintf("slice%d_%d", order.ID, sequence))
+	placeCtx := orderprocessor.WithOrderLinkID(ctx, orderLinkID)
+	place := s.processor.PlaceLongOrder
+	if side == models.OrderSideSell {
+		place = s.processor.PlaceShortOrder
+	}
+	resp, err := place(placeCtx, order.Symbol, price, quantity, stopLoss, takeProfit)
+	if err == nil && !resp.IsSuccess() {
+		err = fmt.Errorf("rejected: %s (code %s)", resp.ErrorMessage, resp.ErrorCode)
+	}
+	if err != nil {
+		return order, s.finish(ctx, order, models.SlicedOrderStatusCancelled, fmt.Sprintf("frazione %d fallita: %v", sequence, err))
+	}
+
+	fill := price
+	if resp.AveragePrice > 0 {
+		fill = resp.AveragePrice
+	}
+	order.FilledQuantity += quantity
+	order.SlicesPlaced = sequence
+	if order.SlicesPlaced >= order.MaxSlices || s.floorToStep(order.RemainingQuantity()) <= 0 {
+		now := time.Now().UTC()
+		order.Status = models.SlicedOrderStatusCompleted
+		order.CompletedAt = &now
+	}
+	slice := &models.OrderSlice{
+		Sequence:    sequence,
+		OrderID:     resp.OrderID,
+		OrderLinkID: orderLinkID,
+		Quantity:    quantity,
+		Price:       fill,
+	}
+	if err := s.repoManager.SlicedOrder().AddSlice(ctx, order, slice); err != nil {
+		return nil, fmt.Errorf("slice %s placed but not saved: %w", resp.OrderID, err)
+	}
+
+	// L'ingresso rappresenta l'ordine logico: quantità totale eseguita e prezzo medio ponderato
+	err = s.orderService.UpdateOrder(ctx, entry.OrderID, func(entry *models.Order) error {
+		total := entry.Quantity + quantity
+		entry.OrderPrice = (entry.OrderPrice*entry.Quantity + fill*quantity) / total
+		entry.Quantity = total
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("slice %s placed but entry %s not updated: %w", resp.OrderID, entry.OrderID, err)
+	}
+
+	log.Printf("🧩 Frazione %d/%d di %s: %.2f a %.6f (ordine %s), eseguiti %.2f di %.2f",
+		sequence, order.MaxSlices, order.ParentOrderID, quantity, fill, resp.OrderID, order.FilledQuantity, order.TotalQuantity)
+	if order.Status == models.SlicedOrderStatusCompleted {
+		log.Printf("✅ Ingresso frazionato %s completato: %.2f in %d frazioni", order.ParentOrderID, order.FilledQuantity, order.SlicesPlaced)
+	}
+	return order, nil
+}
+
+// nextQuantity calcola la quantità del prossimo figlio dato il residuo e le frazioni rimaste
+// Con twap le frazioni sono uguali; con iceberg il figlio è limitato dalla liquidità visibile, ma non scende
+// sotto la quota uguale, così l'ordine si completa entro il numero massimo di frazioni
+func (s *OrderSlicer) nextQuantity(remaining float64, slicesLeft int, liquidity float64) float64 {
+	if slicesLeft <= 1 {
+		return s.floorToStep(remaining)
+	}
+	quantity := remaining / float64(slicesLeft)
+	if s.params.Method == models.SliceMethodIceberg {
+		quantity = max(quantity, liquidity*s.params.MaxBookFraction)
+	}
+	return s.floorToStep(min(quantity, remaining))
+}
+
+// floorToStep arrotonda la quantità per difetto al passo configurato
+func (s *OrderSlicer) floorToStep(quantity float64) float64 {
+	return models.FloorToStep(quantity, s.params.QtyStep)
+}
+
+// topOfBook restituisce la liquidità e il prezzo al miglior livello sul lato preso da un ordine a mercato
+func (s *OrderSlicer) topOfBook(ctx context.Context, symbol string, side models.OrderSide) (float64, float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, priceTimeout)
+	defer cancel()
+
+	data, err := s.prices.GetRealTimePrice(ctx, symbol)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read top of book for %s: %w", symbol, err)
+	}
+	if side == models.OrderSideSell {
+		return data.BidLiquidity, data.BidPrice, nil
+	}
+	return data.AskLiquidity, data.AskPrice, nil
+}
+
+// positionOpen verifica se sull'exchange c'è ancora la posizione dell'ordine logico
+func (s *OrderSlicer) positionOpen(ctx context.Context, symbol string, side models.OrderSide) (bool, error) {
+	positions, err := s.processor.GetPositions(ctx, symbol)
+	if err != nil {
+		return false, fmt.Errorf("failed to read positions for %s: %w", symbol, err)
+	}
+	for _, position := range positions {
+		if position.Parsed.IsActive() && string(position.Side) == string(side) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// finish chiude l'ordine logico senza inviare altre frazioni
+func (s *OrderSlicer) finish(ctx context.Context, order *models.SlicedOrder, status models.SlicedOrderStatus, note string) error {
+	now := time.Now().UTC()
+	order.Status = status
+	order.Note = note
+	order.CompletedAt = &now
+	if err := s.repoManager.SlicedOrder().Update(ctx, order); err != nil {
+		return fmt.Errorf("failed to update sliced order %d: %w", order.ID, err)
+	}
+	log.Printf("🧩 Ingresso frazionato %s %s: %s (eseguiti %.2f di %.2f)", order.ParentOrderID, status, note, order.FilledQuantity, order.TotalQuantity)
+	return nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"cross-exchange-arbitrage/correlation"
+	"cross-exchange-arbitrage/models"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ErrInvalidStatusTransition indica una transizione di stato non consentita dal ciclo di vita dell'ordine
+var ErrInvalidStatusTransition = errors.New("invalid order status transition")
+
+// statusTransitions definisce il ciclo di vita degli ordini: per ogni stato gli stati raggiungibili
+// Gli stati senza transizioni in uscita sono terminali
+var statusTransitions = map[models.OrderStatus][]models.OrderStatus{
+	models.OrderStatusUntriggered: {
+		models.OrderStatusTriggered,
+		models.OrderStatusDeactivated,
+		models.OrderStatusCancelled,
+		models.OrderStatusRejected,
+	},
+	models.OrderStatusTriggered: {
+		models.OrderStatusNew,
+		models.OrderStatusPartiallyFilled,
+		models.OrderStatusFilled,
+		models.OrderStatusCancelled,
+		models.OrderStatusRejected,
+	},
+	models.OrderStatusNew: {
+		models.OrderStatusPartiallyFilled,
+		models.OrderStatusFilled,
+		models.OrderStatusCancelled,
+		models.OrderStatusRejected,
+		models.OrderStatusDeactivated,
+	},
+	models.OrderStatusPartiallyFilled: {
+		models.OrderStatusFilled,
+		models.OrderStatusCancelled,
+		models.OrderStatusPartiallyFilledCanceled,
+	},
+	models.OrderStatusFilled:                  {},
+	models.OrderStatusCancelled:               {},
+	models.OrderStatusRejected:                {},
+	models.OrderStatusPartiallyFilledCanceled: {},
+	models.OrderStatusDeactivated:             {},
+}
+
+// ValidateStatusTransition verifica che un ordine possa passare dallo stato from allo stato to
+// Rimanere nello stesso stato è sempre consentito
+func ValidateStatusTransition(from, to models.OrderStatus) error {
+	if from == to {
+		return nil
+	}
+
+	allowed, known := statusTransitions[from]
+	if !known {
+		return fmt.Errorf("%w: unknown status %s", ErrInvalidStatusTransition, from)
+	}
+	if _, known := statusTransitions[to]; !known {
+		return fmt.Errorf("%w: unknown status %s", ErrInvalidStatusTransition, to)
+	}
+
+	for _, status := range allowed {
+		if status == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, from, to)
+}
+
+// IsTerminalStatus indica se lo stato non ammette ulteriori transizioni
+func IsTerminalStatus(status models.OrderStatus) bool {
+	allowed, known := statusTransitions[status]
+	return known && len(allowed) == 0
+}
+
+// checkStatusTransition valida il cambio di stato di un ordine a partire dagli ID
+// Le transizioni rifiutate vengono registrate nell'audit trail
+func (s *OrderService) checkStatusTransition(ctx context.Context, orderID string, fromID, toID uint, changedBy string) error {
+	if fromID == toID {
+		return nil
+	}
+
+	from, err := s.repoManager.OrderStatus().GetByID(ctx, fromID)
+	if err != nil {
+		return fmt.Errorf("invalid current order status: %w", err)
+	}
+	to, err := s.repoManager.OrderStatus().GetByID(ctx, toID)
+	if err != nil {
+		return fmt.Errorf("invalid order status: %w", err)
+	}
+
+	if err := ValidateStatusTransition(models.OrderStatus(from.StatusName), models.OrderStatus(to.StatusName)); err != nil {
+		s.recordTransitionViolation(ctx, orderID, from.StatusName, to.StatusName, changedBy)
+		return err
+	}
+	return nil
+}
+
+// recordTransitionViolation salva nell'audit trail un tentativo di transizione non consentita
+func (s *OrderService) recordTransitionViolation(ctx context.Context, orderID, from, to, changedBy string) {
+	audit := &models.OrderAudit{
+		OrderID:       orderID,
+		FieldName:     "status_transition_rejected",
+		ChangedBy:     changedBy,
+		CorrelationID: correlation.ID(ctx),
+	}
+	audit.SetOldValue(from)
+	audit.SetNewValue(to)
+
+	if err := s.repoManager.OrderAudit().Create(ctx, audit); err != nil {
+		log.Printf("Warning: failed to record rejected status transition for order %s: %v", orderID, err)
+	}
+}
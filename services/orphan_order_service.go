@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/repositories"
+)
+
+// Azioni sugli ordini orfani
+const (
+	OrphanActionCancel = "cancel" // Cancella l'ordine sull'exchange
+	OrphanActionAdopt  = "adopt"  // Registra l'ordine nel database, così il bot lo gestisce come i propri
+)
+
+// orphanAdoptedTag è il tag assegnato agli ordini orfani registrati nel database
+const orphanAdoptedTag = "adopted"
+
+// OrphanSweepParams configura la pulizia degli ordini orfani
+type OrphanSweepParams struct {
+	Action  string        // OrphanActionCancel o OrphanActionAdopt
+	Grace   time.Duration // Età minima di un ordine sconosciuto prima di considerarlo orfano
+	Symbols []string      // Simboli gestiti dal bot
+}
+
+// OrphanOrder è un ordine condizionale aperto sull'exchange che il database non conosce
+type OrphanOrder struct {
+	Order   models.OrderResponse
+	Handled bool  // true se l'ordine è stato cancellato o registrato
+	Err     error // Errore della cancellazione o della registrazione
+}
+
+// OrphanSweepReport è l'esito di una pulizia degli ordini orfani
+type OrphanSweepReport struct {
+	Action    string
+	Orders    []OrphanOrder
+	CheckedAt time.Time
+}
+
+// OrphanOrderService trova gli ordini condizionali lasciati aperti da un'esecuzione precedente interrotta
+// prima di salvarli: senza il bot a seguirli, il loro trigger scatterebbe senza controllo
+type OrphanOrderService struct {
+	processor    orderprocessor.OrderProcessor
+	orders       orderprocessor.OpenOrderReader
+	orderService *OrderService
+	repoManager  repositories.RepositoryManager
+	params       OrphanSweepParams
+}
+
+// NewOrphanOrderService crea il servizio; il processor deve saper elencare gli ordini aperti
+func NewOrphanOrderService(processor orderprocessor.OrderProcessor, orderService *OrderService, repoManager repositories.RepositoryManager, params OrphanSweepParams) (*OrphanOrderService, error) {
+	orders, ok := processor.(orderprocessor.OpenOrderReader)
+	if !ok {
+		return nil, fmt.Errorf("order processor cannot list open orders")
+	}
+	if params.Action != OrphanActionCancel && params.Action != OrphanActionAdopt {
+		return nil, fmt.Errorf("unknown orphan order action %q", params.Action)
+	}
+	return &OrphanOrderService{
+		processor:    processor,
+		orders:       orders,
+		orderService: orderService,
+		repoManager:  repoManager,
+		params:       params,
+	}, nil
+}
+
+// Sweep legge gli ordini aperti dei simboli gestiti e cancella o registra quelli condizionali sconosciuti
+// Stop loss e take profit collegati a una posizione sono esclusi: vengono chiusi insieme alla posizione
+func (s *OrphanOrderService) Sweep(ctx context.Context) (*OrphanSweepReport, error) {
+	now := time.Now()
+	report := &OrphanSweepReport{Action: s.params.Action, CheckedAt: now}
+
+	for _, symbol := range s.params.Symbols {
+		symbol = strings.ToUpper(symbol)
+		openOrders, err := s.orders.GetOpenOrders(ctx, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read open orders for %s: %w", symbol, err)
+		}
+
+		for _, order := range openOrders {
+			if !order.IsConditional() || positionStopOrderTypes[order.StopOrderType] || now.Sub(order.CreatedTime) < s.params.Grace {
+				continue
+			}
+			exists, err := s.repoManager.Order().Exists(ctx, order.OrderID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up order %s: %w", order.OrderID, err)
+			}
+			if exists {
+				continue
+			}
+
+			orphan := OrphanOrder{Order: order}
+			if s.params.Action == OrphanActionAdopt {
+				orphan.Err = s.adopt(ctx, order)
+			} else {
+				_, orphan.Err = s.processor.DeleteOrder(ctx, order.Symbol, order.OrderID)
+			}
+			orphan.Handled = orphan.Err == nil
+			report.Orders = append(report.Orders, orphan)
+		}
+	}
+	return report, nil
+}
+
+// adopt registra l'ordine nel database con lo stato restituito dall'exchange e il tag adopted
+// Il prezzo registrato è quello di attivazione, che per un ordine condizionale a mercato è l'unico noto
+func (s *OrphanOrderService) adopt(ctx context.Context, order models.OrderResponse) error {
+	status, err := s.repoManager.OrderStatus().GetByStatusName(ctx, string(order.Status))
+	if err != nil {
+		return fmt.Errorf("unknown order status %s: %w", order.Status, err)
+	}
+
+	price := order.TriggerPrice
+	if price <= 0 {
+		price = order.Price
+	}
+	record := &models.Order{
+		OrderID:       order.OrderID,
+		Symbol:        order.Symbol,
+		Side:          models.OrderSideType(order.Side),
+		OrderPrice:    price,
+		Quantity:      order.Quantity,
+		OrderStatusID: status.ID,
+		Result:        models.OrderResultPending,
+	}
+	if order.TakeProfit > 0 {
+		record.TakeProfitPrice = &order.TakeProfit
+	}
+	if order.StopLoss > 0 {
+		record.StopLossPrice = &order.StopLoss
+	}
+	if err := s.orderService.CreateOrder(ctx, record); err != nil {
+		return err
+	}
+
+	note := fmt.Sprintf("Conditional order found open at startup, created %s", order.CreatedTime.UTC().Format(time.RFC3339))
+	if _, err := s.orderService.AddOrderTag(ctx, order.OrderID, orphanAdoptedTag, note, "orphan-sweep"); err != nil {
+		return fmt.Errorf("order adopted but not tagged: %w", err)
+	}
+	return nil
+}
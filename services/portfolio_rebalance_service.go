@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/repositories"
+
+	"gorm.io/gorm"
+)
+
+// masterAllocation è il nome dell'allocazione del master account nei pesi del ribilanciamento
+const masterAllocation = "master"
+
+// AllocationBalance è il saldo di un'allocazione (master o sub-account di una strategia) rispetto al peso obiettivo
+type AllocationBalance struct {
+	Account      string  // master o nome della strategia
+	MemberID     int64   // UID dell'account
+	Balance      float64 // Saldo del wallet unificato
+	Transferable float64 // Parte del saldo non impegnata come margine
+	Weight       float64 // Peso obiettivo, normalizzato sulla somma dei pesi
+	Target       float64 // Saldo obiettivo
+	Drift        float64 // Quota attuale meno peso obiettivo (positivo se sovrallocato)
+}
+
+// PortfolioRebalanceReport è l'esito di un ribilanciamento
+type PortfolioRebalanceReport struct {
+	Coin        string
+	Total       float64
+	Allocations []AllocationBalance
+	MaxDrift    float64                     // Scostamento assoluto massimo dal peso obiettivo
+	Transfers   []*models.RebalanceTransfer // Trasferimenti tentati, compresi quelli falliti
+	CheckedAt   time.Time
+}
+
+// rebalancePlan è uno spostamento di fondi calcolato tra due allocazioni
+type rebalancePlan struct {
+	from, to *AllocationBalance
+	amount   float64
+}
+
+// PortfolioRebalanceService mantiene il capitale del master e dei sub-account delle strategie ai pesi obiettivo
+// I fondi vengono spostati con universal transfer usando la key del master; ogni trasferimento viene registrato
+type PortfolioRebalanceService struct {
+	transferer  orderprocessor.MemberTransferer
+	repoManager repositories.RepositoryManager
+	coin        string
+	weights     map[string]float64
+	drift       float64
+	minTransfer float64
+	masterUID   int64 // Letto dalla key del master al primo ribilanciamento
+}
+
+// NewPortfolioRebalanceService crea una nuova istanza di PortfolioRebalanceService
+func NewPortfolioRebalanceService(transferer orderprocessor.MemberTransferer, repoManager repositories.RepositoryManager, cfg config.PortfolioRebalanceConfig) *PortfolioRebalanceService {
+	return &PortfolioRebalanceService{
+		transferer:  transferer,
+		repoManager: repoManager,
+		coin:        cfg.Coin,
+		weights:     cfg.Weights,
+		drift:       cfg.Drift,
+		minTransfer: cfg.MinTransfer,
+	}
+}
+
+// Rebalance legge i saldi delle allocazioni e, se una si scosta dal peso obiettivo oltre la soglia,
+// sposta i fondi dalle allocazioni sovrallocate a quelle sottoallocate
+// Un'allocazione cede solo il saldo trasferibile, così il margine delle posizioni aperte non viene toccato
+func (s *PortfolioRebalanceService) Rebalance(ctx context.Context) (*PortfolioRebalanceReport, error) {
+	allocations, err := s.readAllocations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PortfolioRebalanceReport{Coin: s.coin, CheckedAt: time.Now().UTC()}
+	var totalWeight float64
+	for _, allocation := range allocations {
+		report.Total += allocation.Balance
+		totalWeight += allocation.Weight
+	}
+	for i := range allocations {
+		allocation := &allocations[i]
+		allocation.Weight /= totalWeight
+		allocation.Target = report.Total * allocation.Weight
+		if report.Total > 0 {
+			allocation.Drift = allocation.Balance/report.Total - allocation.Weight
+		}
+		report.MaxDrift = math.Max(report.MaxDrift, math.Abs(allocation.Drift))
+	}
+	report.Allocations = allocations
+
+	if report.Total <= 0 || report.MaxDrift <= s.drift {
+		return report, nil
+	}
+
+	for _, plan := range s.plan(report.Allocations) {
+		report.Transfers = append(report.Transfers, s.transfer(ctx, plan))
+	}
+	return report, nil
+}
+
+// readAllocations legge il saldo di ogni allocazione con un peso configurato, in ordine di nome
+// Un saldo non leggibile interrompe il ribilanciamento: con una vista parziale i pesi sarebbero sbagliati
+func (s *PortfolioRebalanceService) readAllocations(ctx context.Context) ([]AllocationBalance, error) {
+	accounts := make([]string, 0, len(s.weights))
+	for account := range s.weights {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	allocations := make([]AllocationBalance, 0, len(accounts))
+	for _, account := range accounts {
+		memberID, err := s.memberID(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		balance, err := s.transferer.GetMemberBalance(ctx, memberID, models.AccountTypeUnified, s.coin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s balance of %s: %w", s.coin, account, err)
+		}
+		allocations = append(allocations, AllocationBalance{
+			Account:      account,
+			MemberID:     memberID,
+			Balance:      balance.WalletBalance,
+			Transferable: balance.TransferBalance,
+			Weight:       s.weights[account],
+		})
+	}
+	return allocations, nil
+}
+
+// memberID restituisce l'UID di un'allocazione: quello della key per il master, quello registrato per le strategie
+func (s *PortfolioRebalanceService) memberID(ctx context.Context, account string) (int64, error) {
+	if account == masterAllocation {
+		if s.masterUID == 0 {
+			info, err := s.transferer.GetAPIKeyInfo(ctx)
+			if err != nil {
+				return 0, fmt.Errorf("failed to read master account UID: %w", err)
+			}
+			if info.UserID <= 0 {
+				return 0, fmt.Errorf("master account UID not returned by the exchange")
+			}
+			s.masterUID = info.UserID
+		}
+		return s.masterUID, nil
+	}
+
+	sub, err := s.repoManager.SubAccount().GetByStrategy(ctx, account)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("strategy %s has no registered sub-account (create it with \"mkybot subaccount create %s\")", account, account)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to load sub-account of %s: %w", account, err)
+	}
+	return sub.UID, nil
+}
+
+// plan abbina le eccedenze trasferibili delle allocazioni sovrallocate ai deficit di quelle sottoallocate,
+// a partire dagli scostamenti più grandi; gli importi sono arrotondati per difetto al centesimo
+func (s *PortfolioRebalanceService) plan(allocations []AllocationBalance) []rebalancePlan {
+	var donors, receivers []*AllocationBalance
+	surplus := make(map[string]float64)
+	deficit := make(map[string]float64)
+	for i := range allocations {
+		allocation := &allocations[i]
+		if excess := math.Min(allocation.Balance-allocation.Target, allocation.Transferable); excess > 0 {
+			surplus[allocation.Account] = excess
+			donors = append(donors, allocation)
+		} else if need := allocation.Target - allocation.Balance; need > 0 {
+			deficit[allocation.Account] = need
+			receivers = append(receivers, allocation)
+		}
+	}
+	sort.SliceStable(donors, func(i, j int) bool { return surplus[donors[i].Account] > surplus[donors[j].Account] })
+	sort.SliceStable(receivers, func(i, j int) bool { return deficit[receivers[i].Account] > deficit[receivers[j].Account] })
+
+	var plans []rebalancePlan
+	for _, receiver := range receivers {
+		for _, donor := range donors {
+			amount := math.Floor(math.Min(deficit[receiver.Account], surplus[donor.Account])*100) / 100
+			if amount <= 0 || amount < s.minTransfer {
+				continue
+			}
+			plans = append(plans, rebalancePlan{from: donor, to: receiver, amount: amount})
+			surplus[donor.Account] -= amount
+			deficit[receiver.Account] -= amount
+		}
+	}
+	return plans
+}
+
+// transfer esegue uno spostamento e lo registra, anche se fallito
+func (s *PortfolioRebalanceService) transfer(ctx context.Context, plan rebalancePlan) *models.RebalanceTransfer {
+	record := &models.RebalanceTransfer{
+		Coin:         s.coin,
+		Amount:       plan.amount,
+		FromAccount:  plan.from.Account,
+		ToAccount:    plan.to.Account,
+		FromMemberID: plan.from.MemberID,
+		ToMemberID:   plan.to.MemberID,
+	}
+
+	result, err := s.transferer.UniversalTransfer(ctx, models.TransferRequest{
+		Coin:            s.coin,
+		Amount:          plan.amount,
+		FromAccountType: models.AccountTypeUnified,
+		ToAccountType:   models.AccountTypeUnified,
+		FromMemberID:    plan.from.MemberID,
+		ToMemberID:      plan.to.MemberID,
+	})
+	switch {
+	case err != nil:
+		record.Status = "FAILED"
+		record.Error = err.Error()
+	default:
+		record.TransferID = result.TransferID
+		record.Status = result.Status
+	}
+
+	if err := s.repoManager.RebalanceTransfer().Create(ctx, record); err != nil {
+		record.Error = fmt.Sprintf("%s; failed to record transfer: %v", record.Error, err)
+	}
+	return record
+}
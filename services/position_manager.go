@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/repositories"
+
+	"gorm.io/gorm"
+)
+
+// ScaledPosition è una posizione aperta composta dall'ingresso iniziale e dai suoi incrementi (pyramiding)
+type ScaledPosition struct {
+	Entry *models.Order   // Ingresso iniziale (livello 0)
+	Adds  []*models.Order // Incrementi collegati all'ingresso, in ordine di livello
+}
+
+// Level restituisce il numero di incrementi già fatti
+func (p *ScaledPosition) Level() int {
+	return len(p.Adds)
+}
+
+// LastPrice restituisce il prezzo dell'ultimo ingresso, iniziale o incremento
+func (p *ScaledPosition) LastPrice() float64 {
+	if len(p.Adds) > 0 {
+		return p.Adds[len(p.Adds)-1].OrderPrice
+	}
+	return p.Entry.OrderPrice
+}
+
+// Quantity restituisce la quantità complessiva della posizione
+func (p *ScaledPosition) Quantity() float64 {
+	quantity := p.Entry.Quantity
+	for _, add := range p.Adds {
+		quantity += add.Quantity
+	}
+	return quantity
+}
+
+// AverageEntry restituisce il prezzo medio di ingresso ponderato per quantità
+func (p *ScaledPosition) AverageEntry() float64 {
+	cost := p.Entry.OrderPrice * p.Entry.Quantity
+	for _, add := range p.Adds {
+		cost += add.OrderPrice * add.Quantity
+	}
+	return cost / p.Quantity()
+}
+
+// AverageEntryAfter restituisce il prezzo medio di ingresso dopo un incremento di quantity a price
+func (p *ScaledPosition) AverageEntryAfter(price, quantity float64) float64 {
+	total := p.Quantity()
+	return (p.AverageEntry()*total + price*quantity) / (total + quantity)
+}
+
+// PositionManager gestisce le posizioni incrementate: ricostruisce ingresso e incrementi dal database,
+// salva ogni incremento come ordine collegato all'ingresso e allinea stop loss e take profit della posizione
+type PositionManager struct {
+	repoManager    repositories.RepositoryManager
+	orderService   *OrderService
+	orderProcessor orderprocessor.OrderProcessor
+}
+
+// NewPositionManager crea il gestore delle posizioni di una venue
+func NewPositionManager(repoManager repositories.RepositoryManager, orderService *OrderService, orderProcessor orderprocessor.OrderProcessor) *PositionManager {
+	return &PositionManager{repoManager: repoManager, orderService: orderService, orderProcessor: orderProcessor}
+}
+
+// OpenPosition restituisce la posizione aperta del simbolo con i suoi incrementi; nil se il database non ha un ingresso aperto
+func (m *PositionManager) OpenPosition(ctx context.Context, symbol string) (*ScaledPosition, error) {
+	entry, err := m.repoManager.Order().GetLatestEntry(ctx, symbol)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open entry for %s: %w", symbol, err)
+	}
+
+	orders, err := m.repoManager.Order().GetByParentOrderID(ctx, entry.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scale-ins of %s: %w", entry.OrderID, err)
+	}
+
+	// Gli incrementi cancellati o rifiutati non fanno parte della posizione
+	position := &ScaledPosition{Entry: entry}
+	for _, order := range orders {
+		if order.OrderStatus != nil {
+			switch models.OrderStatus(order.OrderStatus.StatusName) {
+			case models.OrderStatusCancelled, models.OrderStatusRejected, models.OrderStatusDeactivated:
+				continue
+			}
+		}
+		position.Adds = append(position.Adds, order)
+	}
+	return position, nil
+}
+
+// RecordAdd salva l'incremento come ordine collegato all'ingresso della posizione, al livello successivo
+func (m *PositionManager) RecordAdd(ctx context.Context, position *ScaledPosition, add *models.Order) error {
+	add.ParentOrderID = position.Entry.OrderID
+	add.ScaleLevel = position.Level() + 1
+	if err := m.orderService.CreateOrder(ctx, add); err != nil {
+		return fmt.Errorf("failed to save scale-in %s: %w", add.OrderID, err)
+	}
+	position.Adds = append(position.Adds, add)
+	return nil
+}
+
+// AdjustStops imposta stop loss e take profit dell'intera posizione, ricalcolati sul prezzo medio di ingresso
+func (m *PositionManager) AdjustStops(ctx context.Context, symbol string, stopLoss, takeProfit float64) error {
+	response, err := m.orderProcessor.UpdateOrder(ctx, orderprocessor.UpdateOrderParams{
+		Symbol:     symbol,
+		StopLoss:   &stopLoss,
+		TakeProfit: &takeProfit,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update stops of %s: %w", symbol, err)
+	}
+	if !response.IsSuccess() {
+		return fmt.Errorf("stop update for %s rejected: %s (code %s)", symbol, response.ErrorMessage, response.ErrorCode)
+	}
+	log.Printf("📐 Posizione %s: stop loss %.6f, take profit %.6f", symbol, stopLoss, takeProfit)
+	return nil
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/repositories"
+)
+
+// PositionPnLTracker ricalcola il PnL non realizzato delle posizioni aperte a ogni aggiornamento del mark price
+// Le posizioni vengono rilette dall'exchange a intervalli regolari, il mark price arriva dallo stream WebSocket:
+// ogni ricalcolo è pubblicato ai subscriber (dashboard, stop a break-even del worker DOGE) e periodicamente salvato nel database
+type PositionPnLTracker struct {
+	positions   orderprocessor.PositionReader
+	repoManager repositories.RepositoryManager
+	symbols     []string
+	refresh     time.Duration
+	snapshot    time.Duration
+
+	mu          sync.RWMutex
+	open        map[string][]models.ParsedPosition // Posizioni aperte per simbolo, all'ultima lettura
+	marks       map[string]float64                 // Ultimo mark price ricevuto per simbolo
+	latest      map[string]*models.PositionPnL     // Ultimo PnL per posizione (simbolo e lato)
+	subscribers []chan *models.PositionPnL
+}
+
+// NewPositionPnLTracker crea un tracker del PnL delle posizioni dei simboli indicati
+// refresh è la frequenza di lettura delle posizioni, snapshot quella di salvataggio del PnL
+func NewPositionPnLTracker(positions orderprocessor.PositionReader, repoManager repositories.RepositoryManager, symbols []string, refresh, snapshot time.Duration) *PositionPnLTracker {
+	normalized := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		normalized = append(normalized, strings.ToUpper(symbol))
+	}
+	return &PositionPnLTracker{
+		positions:   positions,
+		repoManager: repoManager,
+		symbols:     normalized,
+		refresh:     refresh,
+		snapshot:    snapshot,
+		open:        make(map[string][]models.ParsedPosition),
+		marks:       make(map[string]float64),
+		latest:      make(map[string]*models.PositionPnL),
+	}
+}
+
+// Symbols restituisce i simboli seguiti dal tracker
+func (t *PositionPnLTracker) Symbols() []string {
+	return t.symbols
+}
+
+// Start avvia la lettura periodica delle posizioni e il salvataggio degli snapshot fino alla cancellazione del contesto
+func (t *PositionPnLTracker) Start(ctx context.Context) {
+	go func() {
+		refresh := time.NewTicker(t.refresh)
+		defer refresh.Stop()
+		snapshot := time.NewTicker(t.snapshot)
+		defer snapshot.Stop()
+
+		t.Refresh(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				t.closeSubscribers()
+				return
+			case <-refresh.C:
+				t.Refresh(ctx)
+			case <-snapshot.C:
+				t.saveSnapshots(ctx)
+			}
+		}
+	}()
+}
+
+// Refresh rilegge le posizioni aperte dei simboli seguiti e ne ricalcola il PnL con l'ultimo mark price
+// In assenza di mark price dallo stream si usa quello restituito dall'exchange con la posizione
+func (t *PositionPnLTracker) Refresh(ctx context.Context) {
+	for _, symbol := range t.symbols {
+		positions, err := t.positions.GetPositions(ctx, symbol)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("⚠️  PnL posizioni %s: %v", symbol, err)
+			}
+			continue
+		}
+
+		open := make([]models.ParsedPosition, 0, len(positions))
+		for _, position := range positions {
+			if position.Parsed.IsActive() && position.Parsed.EntryPrice > 0 {
+				open = append(open, position.Parsed)
+			}
+		}
+
+		t.mu.Lock()
+		t.open[symbol] = open
+		mark, ok := t.marks[symbol]
+		t.mu.Unlock()
+
+		if !ok && len(open) > 0 {
+			mark = open[0].MarkPrice
+		}
+		t.recompute(symbol, mark, time.Now())
+	}
+}
+
+// UpdateMark registra un nuovo mark price e ricalcola il PnL delle posizioni aperte del simbolo
+func (t *PositionPnLTracker) UpdateMark(symbol string, price float64, at time.Time) {
+	if price <= 0 {
+		return
+	}
+	symbol = strings.ToUpper(symbol)
+
+	t.mu.Lock()
+	t.marks[symbol] = price
+	t.mu.Unlock()
+
+	t.recompute(symbol, price, at)
+}
+
+// recompute aggiorna il PnL delle posizioni aperte del simbolo e lo pubblica ai subscriber
+// Le posizioni non più aperte vengono rimosse dall'ultimo PnL
+func (t *PositionPnLTracker) recompute(symbol string, mark float64, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, pnl := range t.latest {
+		if pnl.Symbol == symbol {
+			delete(t.latest, key)
+		}
+	}
+	if mark <= 0 {
+		return
+	}
+
+	for _, position := range t.open[symbol] {
+		pnl := models.NewPositionPnL(position, symbol, mark, at)
+		t.latest[pnlKey(symbol, position.Side)] = pnl
+		for _, ch := range t.subscribers {
+			select {
+			case ch <- pnl:
+			default:
+				// Subscriber lento: l'aggiornamento viene scartato, il successivo lo sostituisce
+			}
+		}
+	}
+}
+
+// Latest restituisce l'ultimo PnL delle posizioni aperte di un simbolo (due in hedge mode)
+func (t *PositionPnLTracker) Latest(symbol string) []*models.PositionPnL {
+	symbol = strings.ToUpper(symbol)
+	var pnls []*models.PositionPnL
+	for _, pnl := range t.All() {
+		if pnl.Symbol == symbol {
+			pnls = append(pnls, pnl)
+		}
+	}
+	return pnls
+}
+
+// All restituisce l'ultimo PnL di tutte le posizioni aperte, ordinato per simbolo e lato
+func (t *PositionPnLTracker) All() []*models.PositionPnL {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	pnls := make([]*models.PositionPnL, 0, len(t.latest))
+	for _, pnl := range t.latest {
+		pnls = append(pnls, pnl)
+	}
+	sort.Slice(pnls, func(i, j int) bool {
+		if pnls[i].Symbol != pnls[j].Symbol {
+			return pnls[i].Symbol < pnls[j].Symbol
+		}
+		return pnls[i].Side < pnls[j].Side
+	})
+	return pnls
+}
+
+// Subscribe restituisce un channel che riceve ogni ricalcolo del PnL
+// Il channel viene chiuso da Unsubscribe o quando il tracker si ferma
+func (t *PositionPnLTracker) Subscribe() <-chan *models.PositionPnL {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ch := make(chan *models.PositionPnL, subscriberBufferSize)
+	t.subscribers = append(t.subscribers, ch)
+	return ch
+}
+
+// Unsubscribe rimuove un subscriber (es. un client dello stream disconnesso) e ne chiude il channel
+func (t *PositionPnLTracker) Unsubscribe(ch <-chan *models.PositionPnL) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, subscriber := range t.subscribers {
+		if subscriber == ch {
+			t.subscribers = append(t.subscribers[:i], t.subscribers[i+1:]...)
+			close(subscriber)
+			return
+		}
+	}
+}
+
+// saveSnapshots salva l'ultimo PnL di ogni posizione aperta, con l'istante del salvataggio
+func (t *PositionPnLTracker) saveSnapshots(ctx context.Context) {
+	now := time.Now().UTC()
+	for _, pnl := range t.All() {
+		snapshot := *pnl
+		snapshot.TakenAt = now
+		if err := t.repoManager.PositionPnL().Create(ctx, &snapshot); err != nil {
+			log.Printf("⚠️  Snapshot PnL %s %s non salvato: %v", pnl.Symbol, pnl.Side, err)
+		}
+	}
+}
+
+// closeSubscribers chiude i channel di tutti i subscriber
+func (t *PositionPnLTracker) closeSubscribers() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, ch := range t.subscribers {
+		close(ch)
+	}
+	t.subscribers = nil
+}
+
+// pnlKey identifica una posizione: in hedge mode lo stesso simbolo ha una posizione per lato
+func pnlKey(symbol string, side models.PositionSide) string {
+	return symbol + ":" + string(side)
+}
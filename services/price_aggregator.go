@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"cross-exchange-arbitrage/cache"
+	"cross-exchange-arbitrage/exchange"
+	"cross-exchange-arbitrage/models"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// subscriberBufferSize è la dimensione del buffer dei channel dei subscriber
+// Se un subscriber è lento gli aggiornamenti vengono scartati invece di bloccare l'aggregatore
+const subscriberBufferSize = 16
+
+// PriceAggregator unisce i prezzi in tempo reale di tutte le venue configurate
+// in una vista consolidata con il miglior bid/ask e la venue che lo quota
+type PriceAggregator struct {
+	exchanges map[string]exchange.Exchange
+	symbols   []string
+	interval  time.Duration
+	maxAge    time.Duration
+
+	mu          sync.RWMutex
+	latest      map[string]*models.AggregatedPrice
+	subscribers []chan *models.AggregatedPrice
+
+	cache    cache.Store // Cache condivisa dove pubblicare le viste consolidate; nil se disabilitata
+	cacheTTL time.Duration
+}
+
+// NewPriceAggregator crea una nuova istanza di PriceAggregator
+// interval è la frequenza di aggiornamento; le quotazioni più vecchie di maxAge sono escluse dal consolidato
+func NewPriceAggregator(exchanges map[string]exchange.Exchange, symbols []string, interval, maxAge time.Duration) *PriceAggregator {
+	return &PriceAggregator{
+		exchanges: exchanges,
+		symbols:   symbols,
+		interval:  interval,
+		maxAge:    maxAge,
+		latest:    make(map[string]*models.AggregatedPrice),
+	}
+}
+
+// Start avvia l'aggiornamento periodico dei simboli configurati fino alla cancellazione del contesto
+func (a *PriceAggregator) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+
+		for {
+			for _, symbol := range a.symbols {
+				if _, err := a.Refresh(ctx, symbol); err != nil && ctx.Err() == nil {
+					log.Printf("⚠️  Aggregatore prezzi %s: %v", symbol, err)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				a.closeSubscribers()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// SetCache pubblica ogni vista consolidata nella cache per ttl, così altri processi (es. dashboard,
+// istanze in standby) leggono i prezzi senza interrogare le venue; va chiamato prima di Start
+func (a *PriceAggregator) SetCache(store cache.Store, ttl time.Duration) {
+	a.cache = store
+	a.cacheTTL = ttl
+}
+
+// Subscribe restituisce un channel che riceve ogni nuova vista consolidata
+// Il channel viene chiuso quando l'aggregatore si ferma
+func (a *PriceAggregator) Subscribe() <-chan *models.AggregatedPrice {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ch := make(chan *models.AggregatedPrice, subscriberBufferSize)
+	a.subscribers = append(a.subscribers, ch)
+	return ch
+}
+
+// Latest restituisce l'ultima vista consolidata di un simbolo, se più recente di maxAge
+func (a *PriceAggregator) Latest(symbol string) (*models.AggregatedPrice, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	price, ok := a.latest[symbol]
+	if !ok || (a.maxAge > 0 && time.Since(price.Timestamp) > a.maxAge) {
+		return nil, false
+	}
+	return price, true
+}
+
+// Shared restituisce l'ultima vista consolidata locale o, se assente, quella pubblicata nella cache condivisa
+func (a *PriceAggregator) Shared(ctx context.Context, symbol string) (*models.AggregatedPrice, bool) {
+	if price, ok := a.Latest(symbol); ok {
+		return price, true
+	}
+	if a.cache == nil {
+		return nil, false
+	}
+
+	var price models.AggregatedPrice
+	found, err := a.cache.Get(ctx, priceCacheKey(symbol), &price)
+	if err != nil {
+		log.Printf("⚠️  Aggregatore prezzi %s: %v", symbol, err)
+		return nil, false
+	}
+	if !found || (a.maxAge > 0 && time.Since(price.Timestamp) > a.maxAge) {
+		return nil, false
+	}
+	return &price, true
+}
+
+// All restituisce l'ultima vista consolidata di tutti i simboli, ordinata per simbolo
+func (a *PriceAggregator) All() []*models.AggregatedPrice {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	prices := make([]*models.AggregatedPrice, 0, len(a.latest))
+	for _, price := range a.latest {
+		prices = append(prices, price)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Symbol < prices[j].Symbol })
+	return prices
+}
+
+// Refresh legge il prezzo di un simbolo da tutte le venue in parallelo, aggiorna la vista e notifica i subscriber
+func (a *PriceAggregator) Refresh(ctx context.Context, symbol string) (*models.AggregatedPrice, error) {
+	quotes := a.fetchQuotes(ctx, symbol)
+
+	price, err := AggregateQuotes(symbol, quotes, a.maxAge, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	a.latest[symbol] = price
+	for _, ch := range a.subscribers {
+		select {
+		case ch <- price:
+		default:
+			// Subscriber lento, salta questo aggiornamento
+		}
+	}
+	a.mu.Unlock()
+
+	if a.cache != nil {
+		if err := a.cache.Set(ctx, priceCacheKey(symbol), price, a.cacheTTL); err != nil {
+			log.Printf("⚠️  Aggregatore prezzi %s: %v", symbol, err)
+		}
+	}
+
+	return price, nil
+}
+
+// priceCacheKey restituisce la chiave di cache della vista consolidata di un simbolo
+func priceCacheKey(symbol string) string {
+	return "price:" + symbol
+}
+
+// fetchQuotes legge le quotazioni di tutte le venue; gli errori sono riportati nella quotazione
+// Il contesto non viene limitato con un timeout: Bybit lega la durata del WebSocket al contesto
+// della prima richiesta, e le chiamate REST hanno già il timeout del client HTTP
+func (a *PriceAggregator) fetchQuotes(ctx context.Context, symbol string) []models.VenueQuote {
+	var wg sync.WaitGroup
+	quotes := make([]models.VenueQuote, 0, len(a.exchanges))
+	var quotesMu sync.Mutex
+
+	for name, exch := range a.exchanges {
+		wg.Add(1)
+		go func(name string, exch exchange.Exchange) {
+			defer wg.Done()
+
+			quote := models.VenueQuote{Exchange: name}
+			data, err := exch.GetRealTimePrice(ctx, symbol)
+			if err != nil {
+				quote.Error = err.Error()
+			} else {
+				quote.BidPrice = data.BidPrice
+				quote.AskPrice = data.AskPrice
+				quote.BidLiquidity = data.BidLiquidity
+				quote.AskLiquidity = data.AskLiquidity
+				quote.Timestamp = data.Timestamp
+			}
+
+			quotesMu.Lock()
+			quotes = append(quotes, quote)
+			quotesMu.Unlock()
+		}(name, exch)
+	}
+	wg.Wait()
+
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].Exchange < quotes[j].Exchange })
+	return quotes
+}
+
+// AggregateQuotes calcola la vista consolidata dalle quotazioni delle singole venue
+// Le quotazioni con errore, prezzi non validi o più vecchie di maxAge (se > 0) sono escluse
+func AggregateQuotes(symbol string, quotes []models.VenueQuote, maxAge time.Duration, now time.Time) (*models.AggregatedPrice, error) {
+	price := &models.AggregatedPrice{Symbol: symbol, Quotes: quotes, Timestamp: now.UTC()}
+
+	for i := range price.Quotes {
+		quote := &price.Quotes[i]
+		if quote.Error != "" || quote.BidPrice <= 0 || quote.AskPrice <= 0 {
+			continue
+		}
+		if maxAge > 0 && now.Sub(quote.Timestamp) > maxAge {
+			quote.Stale = true
+			continue
+		}
+
+		if quote.BidPrice > price.BestBid {
+			price.BestBid = quote.BidPrice
+			price.BestBidExchange = quote.Exchange
+			price.BestBidLiquidity = quote.BidLiquidity
+		}
+		if price.BestAsk == 0 || quote.AskPrice < price.BestAsk {
+			price.BestAsk = quote.AskPrice
+			price.BestAskExchange = quote.Exchange
+			price.BestAskLiquidity = quote.AskLiquidity
+		}
+	}
+
+	if price.BestBidExchange == "" || price.BestAskExchange == "" {
+		return nil, fmt.Errorf("no valid quotes for %s", symbol)
+	}
+
+	price.Spread = price.BestAsk - price.BestBid
+	if mid := price.MidPrice(); mid > 0 {
+		price.SpreadPct = price.Spread / mid
+	}
+	return price, nil
+}
+
+// closeSubscribers chiude i channel dei subscriber alla fermata dell'aggregatore
+func (a *PriceAggregator) closeSubscribers() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, ch := range a.subscribers {
+		close(ch)
+	}
+	a.subscribers = nil
+}
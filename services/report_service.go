@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"cross-exchange-arbitrage/exchange"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/reporting"
+	"cross-exchange-arbitrage/repositories"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Periodo letto alla prima sincronizzazione dei movimenti, se non ce ne sono di più recenti nel database
+	cashFlowLookback = 30 * 24 * time.Hour
+
+	// Sovrapposizione tra sincronizzazioni, per i movimenti che compaiono nel transaction log in ritardo
+	cashFlowOverlap = time.Hour
+)
+
+// ReportService gestisce la reportistica di performance
+type ReportService struct {
+	repoManager  repositories.RepositoryManager
+	exchange     exchange.Exchange
+	riskFreeRate float64
+
+	// Fine dell'ultima lettura dei movimenti per exchange, per non rileggere ogni volta l'intero periodo
+	cashFlowMu     sync.Mutex
+	cashFlowSynced map[string]time.Time
+}
+
+// NewReportService crea una nuova istanza di ReportService
+// L'exchange viene usato per recuperare i prezzi del benchmark buy-and-hold
+// riskFreeRate è il tasso privo di rischio annuo usato per Sharpe e Sortino (es. 0.04)
+func NewReportService(repoManager repositories.RepositoryManager, exch exchange.Exchange, riskFreeRate float64) *ReportService {
+	return &ReportService{
+		repoManager:  repoManager,
+		exchange:     exch,
+		riskFreeRate: riskFreeRate,
+
+		cashFlowSynced: make(map[string]time.Time),
+	}
+}
+
+// SyncCashFlows registra depositi e prelievi USDT dell'exchange avvenuti dall'ultima sincronizzazione
+// Alla prima sincronizzazione riparte dall'ultimo movimento registrato, al massimo dagli ultimi 30 giorni;
+// i movimenti già presenti vengono ignorati
+func (s *ReportService) SyncCashFlows(ctx context.Context, exchangeName string, reader orderprocessor.CashFlowReader) error {
+	s.cashFlowMu.Lock()
+	defer s.cashFlowMu.Unlock()
+
+	now := time.Now().UTC()
+	since, ok := s.cashFlowSynced[exchangeName]
+	if ok {
+		since = since.Add(-cashFlowOverlap)
+	} else {
+		since = now.Add(-cashFlowLookback)
+		latest, err := s.repoManager.CashFlow().GetLatest(ctx, exchangeName)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to get latest cash flow: %w", err)
+		}
+		if latest != nil && latest.OccurredAt.After(since) {
+			since = latest.OccurredAt
+		}
+	}
+
+	flows, err := reader.GetCashFlows(ctx, "USDT", since, now)
+	if err != nil {
+		return fmt.Errorf("failed to fetch cash flows from %s: %w", exchangeName, err)
+	}
+	records := make([]*models.CashFlow, 0, len(flows))
+	for i := range flows {
+		records = append(records, &flows[i])
+		log.Printf("💸 Movimento esterno %s: %s %+.2f %s il %s", exchangeName, flows[i].Type, flows[i].Amount,
+			flows[i].Coin, flows[i].OccurredAt.Format(time.RFC3339))
+	}
+	if err := s.repoManager.CashFlow().CreateBatch(ctx, records); err != nil {
+		return fmt.Errorf("failed to record cash flows: %w", err)
+	}
+
+	s.cashFlowSynced[exchangeName] = now
+	return nil
+}
+
+// RecordSnapshot salva uno snapshot dell'equity corrente
+func (s *ReportService) RecordSnapshot(ctx context.Context, snapshot *models.BalanceSnapshot) error {
+	if err := s.repoManager.BalanceSnapshot().Create(ctx, snapshot); err != nil {
+		return fmt.Errorf("failed to record balance snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetPerformanceMetrics calcola le metriche di performance per una sorgente (live o backtest) in un periodo
+func (s *ReportService) GetPerformanceMetrics(ctx context.Context, source models.SnapshotSource, runID string, startDate, endDate time.Time) (*reporting.PerformanceMetrics, error) {
+	points, err := s.getEquityCurve(ctx, source, runID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) < 2 {
+		return nil, fmt.Errorf("%w: not enough balance snapshots for performance metrics: got %d", ErrInvalidInput, len(points))
+	}
+
+	metrics, err := reporting.CalculatePerformanceMetrics(points, s.riskFreeRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate performance metrics: %w", err)
+	}
+	return metrics, nil
+}
+
+// GetLivePerformanceMetrics calcola le metriche di performance del trading live in un periodo
+func (s *ReportService) GetLivePerformanceMetrics(ctx context.Context, startDate, endDate time.Time) (*reporting.PerformanceMetrics, error) {
+	return s.GetPerformanceMetrics(ctx, models.SnapshotSourceLive, "", startDate, endDate)
+}
+
+// GetBacktestPerformanceMetrics calcola le metriche di performance di un backtest (es. una sessione di paper trading)
+func (s *ReportService) GetBacktestPerformanceMetrics(ctx context.Context, runID string) (*reporting.PerformanceMetrics, error) {
+	if runID == "" {
+		return nil, fmt.Errorf("%w: backtest run ID is required", ErrInvalidInput)
+	}
+	return s.GetPerformanceMetrics(ctx, models.SnapshotSourceBacktest, runID, time.Time{}, time.Now().UTC())
+}
+
+// GetBenchmarkComparison confronta la curva di equity con il buy-and-hold del simbolo nello stesso periodo
+func (s *ReportService) GetBenchmarkComparison(ctx context.Context, symbol string, source models.SnapshotSource, runID string, startDate, endDate time.Time) (*reporting.BenchmarkComparison, error) {
+	if s.exchange == nil {
+		return nil, fmt.Errorf("exchange not configured for benchmark prices")
+	}
+
+	points, err := s.getEquityCurve(ctx, source, runID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) < 2 {
+		return nil, fmt.Errorf("%w: not enough balance snapshots for benchmark: got %d", ErrInvalidInput, len(points))
+	}
+
+	candles, err := s.fetchBenchmarkCandles(ctx, symbol, points[0].Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison, err := reporting.CalculateBenchmark(symbol, points, candles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate benchmark comparison: %w", err)
+	}
+	return comparison, nil
+}
+
+// fetchBenchmarkCandles recupera le candele necessarie a coprire il periodo dalla data di inizio ad oggi
+// Usa candele orarie per periodi brevi e giornaliere per periodi lunghi
+func (s *ReportService) fetchBenchmarkCandles(ctx context.Context, symbol string, since time.Time) ([]models.Candle, error) {
+	elapsed := time.Since(since)
+
+	timeframe := models.Timeframe1h
+	period := time.Hour
+	if elapsed > 30*24*time.Hour {
+		timeframe = models.Timeframe1d
+		period = 24 * time.Hour
+	}
+
+	// Una candela in più per coprire il primo snapshot
+	limit := int(elapsed/period) + 2
+
+	candleResponse, err := s.exchange.FetchLastCandles(ctx, symbol, models.DerivativesMarket, timeframe, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch benchmark candles: %w", err)
+	}
+	return candleResponse.Candles, nil
+}
+
+// GetTagPerformance recupera le statistiche di trading raggruppate per tag
+func (s *ReportService) GetTagPerformance(ctx context.Context, symbol string) ([]*repositories.TagStats, error) {
+	stats, err := s.repoManager.OrderTag().GetTagStats(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag statistics: %w", err)
+	}
+	return stats, nil
+}
+
+// GetRejectReasons conta gli ordini rifiutati dall'exchange raggruppati per motivo
+func (s *ReportService) GetRejectReasons(ctx context.Context, symbol string) ([]*repositories.RejectStats, error) {
+	stats, err := s.repoManager.Order().GetRejectStats(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reject statistics: %w", err)
+	}
+	return stats, nil
+}
+
+// GetBasisSeries recupera la serie storica del basis tra una venue spot e una perpetual
+func (s *ReportService) GetBasisSeries(ctx context.Context, symbol, spotExchange, perpExchange string, startDate, endDate time.Time) ([]*models.BasisSnapshot, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("%w: symbol is required", ErrInvalidInput)
+	}
+	if !startDate.Before(endDate) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+
+	snapshots, err := s.repoManager.BasisSnapshot().GetSeries(ctx, symbol, spotExchange, perpExchange, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get basis series: %w", err)
+	}
+	return snapshots, nil
+}
+
+// GetPositionPnLSeries recupera gli snapshot del PnL non realizzato delle posizioni di un simbolo
+func (s *ReportService) GetPositionPnLSeries(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*models.PositionPnL, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("%w: symbol is required", ErrInvalidInput)
+	}
+	if !startDate.Before(endDate) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+
+	snapshots, err := s.repoManager.PositionPnL().GetSeries(ctx, symbol, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get position pnl series: %w", err)
+	}
+	return snapshots, nil
+}
+
+// GetDataSeries recupera la serie storica di una sorgente dati esterna (es. fear_greed)
+func (s *ReportService) GetDataSeries(ctx context.Context, source string, startDate, endDate time.Time) ([]*models.DataPoint, error) {
+	if source == "" {
+		return nil, fmt.Errorf("%w: source is required", ErrInvalidInput)
+	}
+	if !startDate.Before(endDate) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+
+	points, err := s.repoManager.DataPoint().GetByDateRange(ctx, source, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data series: %w", err)
+	}
+	return points, nil
+}
+
+// getEquityCurve recupera la curva di equity dagli snapshot salvati
+// Per il trading live la curva include i depositi e prelievi USDT, esclusi dai rendimenti
+func (s *ReportService) getEquityCurve(ctx context.Context, source models.SnapshotSource, runID string, startDate, endDate time.Time) ([]reporting.EquityPoint, error) {
+	snapshots, err := s.repoManager.BalanceSnapshot().GetByDateRange(ctx, source, runID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance snapshots: %w", err)
+	}
+	points := reporting.EquityPointsFromSnapshots(snapshots)
+	if source != models.SnapshotSourceLive || len(points) == 0 {
+		return points, nil
+	}
+
+	flows, err := s.repoManager.CashFlow().GetByDateRange(ctx, points[0].Timestamp, points[len(points)-1].Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cash flows: %w", err)
+	}
+	usdtFlows := make([]*models.CashFlow, 0, len(flows))
+	for _, flow := range flows {
+		if flow.Coin == "USDT" {
+			usdtFlows = append(usdtFlows, flow)
+		}
+	}
+	return reporting.ApplyCashFlows(points, usdtFlows), nil
+}
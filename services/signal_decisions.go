@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+)
+
+// GetOrderSignal restituisce la spiegazione del segnale che ha aperto l'ordine
+func (s *OrderService) GetOrderSignal(ctx context.Context, orderID string) (*models.SignalExplanation, error) {
+	decision, err := s.repoManager.SignalDecision().GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signal of order %s: %w", orderID, err)
+	}
+	return decision.ToExplanation(), nil
+}
+
+// SearchSignals restituisce le decisioni delle strategie sui segnali che soddisfano i filtri, dalla più recente
+func (s *OrderService) SearchSignals(ctx context.Context, filter repositories.SignalDecisionFilter, limit, offset int) ([]*models.SignalExplanation, error) {
+	switch filter.Outcome {
+	case "", models.SignalOutcomeEntered, models.SignalOutcomeRejected, models.SignalOutcomeArmed, models.SignalOutcomeFailed:
+	default:
+		return nil, fmt.Errorf("%w: unknown outcome %q (use entered, rejected, armed or failed)", ErrInvalidInput, filter.Outcome)
+	}
+
+	decisions, err := s.repoManager.SignalDecision().Search(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search signals: %w", err)
+	}
+	explanations := make([]*models.SignalExplanation, len(decisions))
+	for i, decision := range decisions {
+		explanations[i] = decision.ToExplanation()
+	}
+	return explanations, nil
+}
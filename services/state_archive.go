@@ -0,0 +1,369 @@
+package services
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/repositories"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// StateArchiveVersion è la versione del formato dell'archivio di stato
+	StateArchiveVersion = 1
+
+	// Nomi dei file all'interno dell'archivio
+	stateManifestFile    = "manifest.json"
+	stateDatabaseFile    = "database.sqlite"
+	stateCredentialsFile = "credentials.enc"
+	stateEnvFile         = "env"
+)
+
+// ErrStateIntegrity indica un archivio di stato alterato, incompleto o non coerente con il suo manifest
+var ErrStateIntegrity = errors.New("state archive integrity check failed")
+
+// StateManifest descrive il contenuto di un archivio di stato e ne consente la verifica all'importazione
+type StateManifest struct {
+	Version    int                `json:"version"`
+	CreatedAt  time.Time          `json:"created_at"`
+	Host       string             `json:"host"`
+	ConfigHash string             `json:"config_hash,omitempty"` // Ultima versione della configurazione di strategia applicata
+	Files      []StateArchiveFile `json:"files"`
+	Tables     map[string]int64   `json:"tables"` // Righe per tabella del database esportato
+}
+
+// StateArchiveFile è un file dell'archivio con dimensione e checksum
+type StateArchiveFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// StateExportOptions indica i file da includere oltre al database
+type StateExportOptions struct {
+	CredentialsFile string // File di credenziali cifrato; incluso se esiste
+	EnvFile         string // File .env; vuoto = non incluso (può contenere credenziali in chiaro)
+}
+
+// StateImportOptions indica dove ripristinare i file dell'archivio
+type StateImportOptions struct {
+	DatabasePath    string
+	CredentialsFile string
+	EnvFile         string
+	Force           bool // Sovrascrive i file esistenti, conservandone una copia .bak
+}
+
+// StateArchiveService esporta e importa lo stato completo del bot per migrarlo tra host
+type StateArchiveService struct {
+	db          *gorm.DB
+	repoManager repositories.RepositoryManager
+}
+
+// NewStateArchiveService crea una nuova istanza di StateArchiveService
+func NewStateArchiveService(db *gorm.DB, repoManager repositories.RepositoryManager) *StateArchiveService {
+	return &StateArchiveService{db: db, repoManager: repoManager}
+}
+
+// Export scrive in w un archivio tar.gz con manifest, snapshot del database e file di configurazione
+// Lo snapshot è consistente anche con il bot in esecuzione
+func (s *StateArchiveService) Export(ctx context.Context, w io.Writer, options StateExportOptions) (*StateManifest, error) {
+	tmpDir, err := os.MkdirTemp("", "mkybot-export-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	snapshotPath := filepath.Join(tmpDir, stateDatabaseFile)
+	if err := database.SnapshotTo(s.db.WithContext(ctx), snapshotPath); err != nil {
+		return nil, err
+	}
+	tables, err := database.VerifyFile(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+
+	host, _ := os.Hostname()
+	manifest := &StateManifest{
+		Version:   StateArchiveVersion,
+		CreatedAt: time.Now().UTC(),
+		Host:      host,
+		Tables:    tables,
+	}
+	versions, err := s.repoManager.ConfigVersion().List(ctx, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config version: %w", err)
+	}
+	if len(versions) > 0 {
+		manifest.ConfigHash = versions[0].Hash
+	}
+
+	sources := map[string]string{stateDatabaseFile: snapshotPath}
+	if options.CredentialsFile != "" {
+		if _, err := os.Stat(options.CredentialsFile); err == nil {
+			sources[stateCredentialsFile] = options.CredentialsFile
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read credentials file: %w", err)
+		}
+	}
+	if options.EnvFile != "" {
+		sources[stateEnvFile] = options.EnvFile
+	}
+
+	names := slices.Sorted(maps.Keys(sources))
+	for _, name := range names {
+		file, err := checksumFile(name, sources[name])
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	if err := writeStateArchive(w, manifest, sources); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// ImportStateArchive verifica l'archivio letto da r e ne ripristina i file
+// Il bot deve essere fermo: il database di destinazione viene sostituito
+// Nessun file viene scritto se una verifica fallisce
+func ImportStateArchive(r io.Reader, options StateImportOptions) (*StateManifest, error) {
+	tmpDir, err := os.MkdirTemp("", "mkybot-import-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest, err := extractStateArchive(r, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyStateFiles(manifest, tmpDir); err != nil {
+		return nil, err
+	}
+
+	tables, err := database.VerifyFile(filepath.Join(tmpDir, stateDatabaseFile))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStateIntegrity, err)
+	}
+	for table, count := range manifest.Tables {
+		if tables[table] != count {
+			return nil, fmt.Errorf("%w: table %s has %d rows, manifest says %d", ErrStateIntegrity, table, tables[table], count)
+		}
+	}
+
+	destinations := map[string]string{
+		stateDatabaseFile:    options.DatabasePath,
+		stateCredentialsFile: options.CredentialsFile,
+		stateEnvFile:         options.EnvFile,
+	}
+	for _, file := range manifest.Files {
+		target := destinations[file.Name]
+		if target == "" {
+			return nil, fmt.Errorf("%w: no destination for %s", ErrInvalidInput, file.Name)
+		}
+		if _, err := os.Stat(target); err == nil && !options.Force {
+			return nil, fmt.Errorf("%w: %s already exists (use -force to replace it)", ErrInvalidInput, target)
+		}
+	}
+
+	backupSuffix := ".bak-" + time.Now().UTC().Format("20060102T150405")
+	for _, file := range manifest.Files {
+		target := destinations[file.Name]
+		// WAL e SHM del database sostituito appartengono al file precedente e vanno spostati con lui
+		related := []string{target}
+		if file.Name == stateDatabaseFile {
+			related = append(related, target+"-wal", target+"-shm")
+		}
+		for _, path := range related {
+			if err := os.Rename(path, path+backupSuffix); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to back up %s: %w", path, err)
+			}
+		}
+		if err := copyFile(filepath.Join(tmpDir, file.Name), target); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+// writeStateArchive scrive manifest e file nell'archivio tar.gz
+func writeStateArchive(w io.Writer, manifest *StateManifest, sources map[string]string) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	header := &tar.Header{Name: stateManifestFile, Mode: 0o600, Size: int64(len(data)), ModTime: manifest.CreatedAt}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	for _, file := range manifest.Files {
+		if err := appendStateFile(tw, file, sources[file.Name], manifest.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// appendStateFile aggiunge un file all'archivio con la dimensione registrata nel manifest
+func appendStateFile(tw *tar.Writer, file StateArchiveFile, path string, modTime time.Time) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer src.Close()
+
+	header := &tar.Header{Name: file.Name, Mode: 0o600, Size: file.Size, ModTime: modTime}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if _, err := io.CopyN(tw, src, file.Size); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", path, err)
+	}
+	return nil
+}
+
+// extractStateArchive estrae l'archivio in dir e restituisce il manifest
+// Sono accettati solo i file noti, senza percorsi, così l'archivio non può scrivere fuori da dir
+func extractStateArchive(r io.Reader, dir string) (*StateManifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a gzip archive: %v", ErrStateIntegrity, err)
+	}
+	defer gz.Close()
+
+	allowed := map[string]bool{stateManifestFile: true, stateDatabaseFile: true, stateCredentialsFile: true, stateEnvFile: true}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrStateIntegrity, err)
+		}
+		if !allowed[header.Name] || header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("%w: unexpected entry %q", ErrStateIntegrity, header.Name)
+		}
+		allowed[header.Name] = false
+
+		dst, err := os.OpenFile(filepath.Join(dir, header.Name), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", header.Name, err)
+		}
+		_, copyErr := io.Copy(dst, tr)
+		if err := dst.Close(); err != nil && copyErr == nil {
+			copyErr = err
+		}
+		if copyErr != nil {
+			return nil, fmt.Errorf("%w: failed to extract %s: %v", ErrStateIntegrity, header.Name, copyErr)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, stateManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("%w: manifest is missing", ErrStateIntegrity)
+	}
+	var manifest StateManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid manifest: %v", ErrStateIntegrity, err)
+	}
+	if manifest.Version != StateArchiveVersion {
+		return nil, fmt.Errorf("%w: unsupported archive version %d", ErrStateIntegrity, manifest.Version)
+	}
+	return &manifest, nil
+}
+
+// verifyStateFiles confronta i file estratti con dimensioni e checksum del manifest
+func verifyStateFiles(manifest *StateManifest, dir string) error {
+	listed := make(map[string]bool, len(manifest.Files))
+	for _, expected := range manifest.Files {
+		listed[expected.Name] = true
+		actual, err := checksumFile(expected.Name, filepath.Join(dir, expected.Name))
+		if err != nil {
+			return fmt.Errorf("%w: %s is missing", ErrStateIntegrity, expected.Name)
+		}
+		if actual.Size != expected.Size || actual.SHA256 != expected.SHA256 {
+			return fmt.Errorf("%w: checksum mismatch for %s", ErrStateIntegrity, expected.Name)
+		}
+	}
+	if !listed[stateDatabaseFile] {
+		return fmt.Errorf("%w: database is missing", ErrStateIntegrity)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read extracted archive: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != stateManifestFile && !listed[entry.Name()] {
+			return fmt.Errorf("%w: %s is not listed in the manifest", ErrStateIntegrity, entry.Name())
+		}
+	}
+	return nil
+}
+
+// checksumFile calcola dimensione e SHA-256 di un file
+func checksumFile(name, path string) (StateArchiveFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return StateArchiveFile{}, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return StateArchiveFile{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return StateArchiveFile{Name: name, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// copyFile copia src in dst con permessi ristretti, creando la directory se necessario
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", dst, err)
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/repositories"
+
+	"gorm.io/gorm"
+)
+
+// strategyPermissions sono i permessi della API key creata per il sub-account di ogni strategia in
+// config.SubAccountStrategies; ogni key riceve solo i permessi degli ordini che la strategia invia
+var strategyPermissions = map[string]models.SubAccountPermissions{
+	"funding-arbitrage": {
+		"ContractTrade": {"Order", "Position"},
+		"Spot":          {"SpotTrade"},
+	},
+}
+
+// subAccountUsernamePattern è il formato dei nomi dei sub-account Bybit: 6-16 lettere e numeri
+var subAccountUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9]{6,16}$`)
+
+// ProvisionOptions sono le opzioni di creazione del sub-account e della API key di una strategia
+type ProvisionOptions struct {
+	Username string   // Nome del sub-account; se vuoto viene derivato dalla strategia
+	IPs      []string // IP autorizzati per la key; vuoto per nessuna restrizione
+}
+
+// ProvisionResult è il risultato della creazione del sub-account e della key di una strategia
+type ProvisionResult struct {
+	Account *models.StrategySubAccount
+	Key     *models.SubAPIKey // Contiene il secret, restituito dall'exchange solo alla creazione
+	Created bool              // false se il sub-account esisteva già ed è stata creata solo una nuova key
+}
+
+// SubAccountService crea i sub-account in cui isolare le strategie e le relative API key
+// Le operazioni usano la key del master account, che deve poter gestire i sub-account
+type SubAccountService struct {
+	manager     orderprocessor.SubAccountManager
+	repoManager repositories.RepositoryManager
+	exchange    string
+}
+
+// NewSubAccountService crea il servizio per la venue indicata
+func NewSubAccountService(manager orderprocessor.SubAccountManager, repoManager repositories.RepositoryManager, exchange string) *SubAccountService {
+	return &SubAccountService{manager: manager, repoManager: repoManager, exchange: exchange}
+}
+
+// Provision crea il sub-account della strategia, se non esiste già, e una nuova API key con i permessi della strategia
+// Con un sub-account già registrato viene creata solo la key: è il modo di ruotare le credenziali
+func (s *SubAccountService) Provision(ctx context.Context, strategy string, options ProvisionOptions) (*ProvisionResult, error) {
+	permissions, ok := strategyPermissions[strategy]
+	if !ok {
+		return nil, fmt.Errorf("strategy %q cannot run in a sub-account (supported: %s)", strategy, strings.Join(config.SubAccountStrategies, ", "))
+	}
+
+	account, err := s.repoManager.SubAccount().GetByStrategy(ctx, strategy)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load sub-account of %s: %w", strategy, err)
+	}
+
+	created := false
+	if account == nil {
+		username := options.Username
+		if username == "" {
+			username = subAccountUsername(strategy, time.Now())
+		}
+		if !subAccountUsernamePattern.MatchString(username) {
+			return nil, fmt.Errorf("invalid sub-account username %q: use 6-16 letters and digits", username)
+		}
+
+		sub, err := s.manager.CreateSubAccount(ctx, models.SubAccountRequest{Username: username, Note: "mkybot " + strategy})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sub-account for %s: %w", strategy, err)
+		}
+		account = &models.StrategySubAccount{Strategy: strategy, Exchange: s.exchange, UID: sub.UID, Username: sub.Username}
+		created = true
+		log.Printf("👤 Sub-account %s (UID %d) creato per la strategia %s", sub.Username, sub.UID, strategy)
+	}
+
+	key, err := s.manager.CreateSubAPIKey(ctx, models.SubAPIKeyRequest{
+		SubUID:      account.UID,
+		Note:        "mkybot " + strategy,
+		IPs:         options.IPs,
+		Permissions: permissions,
+	})
+	if err != nil {
+		// Il sub-account appena creato viene registrato comunque: un nuovo tentativo crea solo la key
+		if created {
+			if saveErr := s.repoManager.SubAccount().Save(ctx, account); saveErr != nil {
+				log.Printf("⚠️  Sub-account %s non registrato: %v", account.Username, saveErr)
+			}
+		}
+		return nil, fmt.Errorf("failed to create API key for sub-account %d: %w", account.UID, err)
+	}
+
+	account.APIKey = key.APIKey
+	account.KeyCreated = time.Now().UTC()
+	if err := s.repoManager.SubAccount().Save(ctx, account); err != nil {
+		return nil, fmt.Errorf("failed to save sub-account of %s: %w", strategy, err)
+	}
+	log.Printf("🔑 API key %s creata per il sub-account %s (%s)", key.APIKey, account.Username, strategy)
+
+	return &ProvisionResult{Account: account, Key: key, Created: created}, nil
+}
+
+// List restituisce i sub-account registrati per le strategie
+func (s *SubAccountService) List(ctx context.Context) ([]*models.StrategySubAccount, error) {
+	accounts, err := s.repoManager.SubAccount().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sub-accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// subAccountUsername deriva il nome del sub-account dalla strategia: lettere della strategia più un suffisso numerico
+// Bybit richiede nomi univoci di 6-16 lettere e numeri, con almeno una lettera e un numero
+func subAccountUsername(strategy string, now time.Time) string {
+	var letters strings.Builder
+	for _, r := range strings.ToLower(strategy) {
+		if r >= 'a' && r <= 'z' && letters.Len() < 10 {
+			letters.WriteRune(r)
+		}
+	}
+	return fmt.Sprintf("%s%06d", letters.String(), now.Unix()%1000000)
+}
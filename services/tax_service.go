@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"cross-exchange-arbitrage/reporting"
+	"cross-exchange-arbitrage/repositories"
+	"fmt"
+	"time"
+)
+
+// TaxService costruisce il report fiscale delle plusvalenze realizzate a partire dalle esecuzioni importate
+type TaxService struct {
+	repoManager repositories.RepositoryManager
+}
+
+// NewTaxService crea una nuova istanza di TaxService
+func NewTaxService(repoManager repositories.RepositoryManager) *TaxService {
+	return &TaxService{repoManager: repoManager}
+}
+
+// BuildTaxReport restituisce i lotti chiusi nell'anno, abbinati in FIFO su tutta la storia delle esecuzioni
+// Le esecuzioni degli anni precedenti servono a ricostruire i lotti ancora aperti a inizio anno;
+// l'anno è calcolato nel fuso orario loc della giurisdizione fiscale
+func (s *TaxService) BuildTaxReport(ctx context.Context, year int, loc *time.Location) ([]reporting.TaxLot, error) {
+	if year < 2000 {
+		return nil, fmt.Errorf("%w: invalid tax year %d", ErrInvalidInput, year)
+	}
+
+	end := time.Date(year+1, time.January, 1, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+	executions, err := s.repoManager.Execution().GetByDateRange(ctx, time.Time{}, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load executions: %w", err)
+	}
+
+	return reporting.TaxLotsForYear(reporting.MatchTaxLotsFIFO(executions), year, loc), nil
+}
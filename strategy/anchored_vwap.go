@@ -0,0 +1,92 @@
+package strategy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// AnchoredVWAP calcola il VWAP dalle candele (dalla più vecchia alla più recente) aperte dall'ancora in poi,
+// con il prezzo tipico (massimo + minimo + chiusura) / 3. Restituisce anche il numero di candele usate
+func AnchoredVWAP(candles []models.Candle, anchor time.Time) (vwap float64, count int, err error) {
+	var value, volume float64
+	for _, candle := range candles {
+		if candle.Timestamp.Before(anchor) {
+			continue
+		}
+		value += (candle.High + candle.Low + candle.Close) / 3 * candle.Volume
+		volume += candle.Volume
+		count++
+	}
+	if volume <= 0 {
+		return 0, count, fmt.Errorf("nessun volume dall'ancora %s (%d candele)", anchor.Format(time.RFC3339), count)
+	}
+	return value / volume, count, nil
+}
+
+// AnchorTracker conserva l'ancora del VWAP di ogni simbolo, impostata dalla strategia (es. la candela di rottura del muro)
+// Lo stato è in memoria: dopo un riavvio il chiamante deve ricavare l'ancora da un'altra fonte (es. l'ordine di ingresso)
+type AnchorTracker struct {
+	mu      sync.Mutex
+	anchors map[string]time.Time
+}
+
+// NewAnchorTracker crea un tracker senza ancore
+func NewAnchorTracker() *AnchorTracker {
+	return &AnchorTracker{anchors: make(map[string]time.Time)}
+}
+
+// SetAnchor ancora il VWAP del simbolo all'apertura della candela indicata, sostituendo l'ancora precedente
+func (t *AnchorTracker) SetAnchor(symbol string, candle models.Candle) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.anchors[symbol] = candle.Timestamp
+}
+
+// Anchor restituisce l'ancora del simbolo, se impostata
+func (t *AnchorTracker) Anchor(symbol string) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	anchor, ok := t.anchors[symbol]
+	return anchor, ok
+}
+
+// AVWAPTrailParams configura lo stop che segue il VWAP ancorato
+type AVWAPTrailParams struct {
+	Buffer     float64 // Distanza dello stop oltre il VWAP (0.002 = 0.2% sotto per i long, sopra per gli short)
+	MinCandles int     // Candele dall'ancora prima di seguire il VWAP, che nelle prime candele è instabile
+}
+
+// AVWAPTrail usa il VWAP ancorato come riferimento di uscita: lo stop segue il VWAP a distanza Buffer,
+// solo stringendosi, così la posizione viene chiusa quando il prezzo torna sotto (long) o sopra (short) il VWAP
+type AVWAPTrail struct {
+	params AVWAPTrailParams
+}
+
+// NewAVWAPTrail crea lo stop sul VWAP ancorato validando i parametri
+func NewAVWAPTrail(params AVWAPTrailParams) (*AVWAPTrail, error) {
+	if params.Buffer < 0 || params.Buffer >= 1 {
+		return nil, fmt.Errorf("la distanza dello stop dal VWAP deve essere compresa tra 0 e 1 (%.4f)", params.Buffer)
+	}
+	if params.MinCandles < 1 {
+		return nil, fmt.Errorf("le candele minime dall'ancora devono essere almeno 1 (%d)", params.MinCandles)
+	}
+	return &AVWAPTrail{params: params}, nil
+}
+
+// StopLoss calcola lo stop della posizione nella direzione indicata dal VWAP ancorato
+// count è il numero di candele dall'ancora, current lo stop attuale (0 se assente) e price il mark price.
+// ok è false se le candele sono troppo poche, lo stop non stringerebbe quello attuale o cadrebbe oltre il prezzo
+func (t *AVWAPTrail) StopLoss(side models.OrderSide, vwap float64, count int, current, price float64) (float64, bool) {
+	if count < t.params.MinCandles || vwap <= 0 {
+		return 0, false
+	}
+	if side == models.OrderSideBuy {
+		stop := vwap * (1 - t.params.Buffer)
+		return stop, stop < price && (current <= 0 || stop > current)
+	}
+	stop := vwap * (1 + t.params.Buffer)
+	return stop, stop > price && (current <= 0 || stop < current)
+}
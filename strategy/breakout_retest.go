@@ -0,0 +1,141 @@
+package strategy
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// RetestParams configura l'ingresso su retest del livello rotto
+type RetestParams struct {
+	Candles   int     // Candele dopo la rottura entro cui il prezzo deve tornare sul livello e confermare
+	Tolerance float64 // Distanza dal livello che conta come ritorno (0.001 = 0.1%)
+}
+
+// Fasi della macchina a stati del retest
+type retestPhase int
+
+const (
+	retestWaitingPullback retestPhase = iota // Rottura registrata, in attesa del ritorno sul livello
+	retestWaitingConfirm                     // Prezzo tornato sul livello, in attesa della candela di conferma
+	retestConfirmed                          // Retest confermato: ingresso possibile finché il livello tiene
+)
+
+// retestState è lo stato del retest di un simbolo
+type retestState struct {
+	side     models.OrderSide
+	level    float64   // Livello rotto: il muro per i long, il supporto per gli short
+	lastSeen time.Time // Apertura dell'ultima candela elaborata
+	candles  int       // Candele elaborate dopo la rottura
+	phase    retestPhase
+}
+
+// RetestTracker trasforma le rotture di muro e supporto in ingressi su retest: dopo la rottura attende che il prezzo
+// torni sul livello rotto entro un numero di candele e che una candela chiuda di nuovo oltre il livello nella
+// direzione della rottura. Lo stato è per simbolo e in memoria: un riavvio scarta le rotture in attesa
+type RetestTracker struct {
+	params RetestParams
+
+	mu     sync.Mutex
+	states map[string]*retestState
+}
+
+// NewRetestTracker crea il tracker validando i parametri
+func NewRetestTracker(params RetestParams) (*RetestTracker, error) {
+	if params.Candles <= 0 {
+		return nil, fmt.Errorf("le candele del retest devono essere positive (%d)", params.Candles)
+	}
+	if params.Tolerance < 0 || params.Tolerance >= 1 {
+		return nil, fmt.Errorf("la tolleranza del retest deve essere compresa tra 0 e 1 (%.4f)", params.Tolerance)
+	}
+	return &RetestTracker{params: params, states: make(map[string]*retestState)}, nil
+}
+
+// Arm registra la rottura del livello sulla candela indicata, sostituendo l'eventuale rottura in attesa
+func (t *RetestTracker) Arm(symbol string, side models.OrderSide, level float64, candle models.Candle) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.states[symbol] = &retestState{
+		side:     side,
+		level:    level,
+		lastSeen: candle.Timestamp,
+	}
+	log.Printf("🎯 Rottura %s di %s a %.6f: ingresso in attesa del retest entro %d candele", side, symbol, level, t.params.Candles)
+}
+
+// Advance elabora le candele chiuse (dalla più vecchia alla più recente) non ancora viste e restituisce la
+// direzione dell'ingresso e il livello rotto quando il retest è confermato e il livello tiene ancora sull'ultima candela.
+// Dopo la conferma lo stato del simbolo viene azzerato; una rottura fallita o scaduta viene scartata
+func (t *RetestTracker) Advance(symbol string, closedCandles []models.Candle) (models.OrderSide, float64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.states[symbol]
+	if !ok {
+		return "", 0, false
+	}
+
+	for _, candle := range closedCandles {
+		if !candle.Timestamp.After(state.lastSeen) {
+			continue
+		}
+		state.lastSeen = candle.Timestamp
+		state.candles++
+
+		if reason := t.step(state, candle); reason != "" {
+			delete(t.states, symbol)
+			log.Printf("🎯 Retest %s di %s a %.6f scartato: %s", state.side, symbol, state.level, reason)
+			return "", 0, false
+		}
+	}
+
+	if state.phase != retestConfirmed {
+		return "", 0, false
+	}
+	delete(t.states, symbol)
+	log.Printf("🎯 Retest %s di %s a %.6f confermato", state.side, symbol, state.level)
+	return state.side, state.level, true
+}
+
+// step fa avanzare lo stato con una candela chiusa; restituisce il motivo se la rottura va scartata
+func (t *RetestTracker) step(state *retestState, candle models.Candle) string {
+	long := state.side == models.OrderSideBuy
+
+	// Una chiusura oltre il livello nella direzione opposta invalida la rottura in qualsiasi fase
+	failed := candle.Close < state.level*(1-t.params.Tolerance)
+	if !long {
+		failed = candle.Close > state.level*(1+t.params.Tolerance)
+	}
+	if failed {
+		return fmt.Sprintf("chiusura a %.6f dal lato opposto del livello", candle.Close)
+	}
+	if state.phase == retestConfirmed {
+		return ""
+	}
+	if state.candles > t.params.Candles {
+		return fmt.Sprintf("nessuna conferma entro %d candele", t.params.Candles)
+	}
+
+	// Ritorno sul livello: il minimo (long) o il massimo (short) arriva entro la tolleranza
+	touched := candle.Low <= state.level*(1+t.params.Tolerance)
+	if !long {
+		touched = candle.High >= state.level*(1-t.params.Tolerance)
+	}
+	if touched && state.phase == retestWaitingPullback {
+		state.phase = retestWaitingConfirm
+	}
+
+	// Conferma: candela nella direzione della rottura che chiude oltre il livello
+	confirmed := candle.Close > state.level && candle.Close > candle.Open
+	if !long {
+		confirmed = candle.Close < state.level && candle.Close < candle.Open
+	}
+	if state.phase == retestWaitingConfirm && confirmed {
+		state.phase = retestConfirmed
+	}
+	return ""
+}
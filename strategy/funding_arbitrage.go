@@ -0,0 +1,128 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+)
+
+// FundingSignal rappresenta l'azione suggerita dalla strategia di funding arbitrage
+type FundingSignal string
+
+const (
+	FundingSignalHold  FundingSignal = "hold"  // Nessuna azione
+	FundingSignalEnter FundingSignal = "enter" // Aprire long spot + short perpetual
+	FundingSignalExit  FundingSignal = "exit"  // Chiudere entrambe le gambe
+)
+
+// FundingArbitrageParams contiene le soglie di ingresso e uscita
+// I tassi di funding sono annualizzati, il basis è (perp - spot) / spot
+type FundingArbitrageParams struct {
+	EntryAPR      float64 // Funding annualizzato minimo per aprire la posizione (es. 0.15 = 15%)
+	ExitAPR       float64 // Sotto questo funding annualizzato la posizione viene chiusa
+	MinEntryBasis float64 // Basis minimo all'ingresso: un perp molto sotto lo spot rende l'ingresso costoso
+	MaxBasis      float64 // Basis assoluto oltre cui la posizione viene chiusa per rischio (0 = disabilitato)
+}
+
+// FundingDecision è l'esito della valutazione di un ciclo
+type FundingDecision struct {
+	Signal FundingSignal
+	Reason string
+}
+
+// FundingArbitrageStrategy decide quando aprire e chiudere una posizione coperta
+// long spot / short perpetual che incassa il funding pagato dai long del perpetual
+type FundingArbitrageStrategy struct {
+	params FundingArbitrageParams
+}
+
+// NewFundingArbitrageStrategy crea la strategia validando le soglie
+// La soglia di uscita deve essere inferiore a quella di ingresso per evitare aperture e chiusure continue
+func NewFundingArbitrageStrategy(params FundingArbitrageParams) (*FundingArbitrageStrategy, error) {
+	if params.EntryAPR <= 0 {
+		return nil, fmt.Errorf("la soglia di ingresso deve essere positiva (%.4f)", params.EntryAPR)
+	}
+	if params.ExitAPR >= params.EntryAPR {
+		return nil, fmt.Errorf("la soglia di uscita (%.4f) deve essere inferiore a quella di ingresso (%.4f)", params.ExitAPR, params.EntryAPR)
+	}
+	if params.MaxBasis < 0 {
+		return nil, fmt.Errorf("il basis massimo non può essere negativo (%.4f)", params.MaxBasis)
+	}
+	return &FundingArbitrageStrategy{params: params}, nil
+}
+
+// Params restituisce le soglie della strategia
+func (s *FundingArbitrageStrategy) Params() FundingArbitrageParams {
+	return s.params
+}
+
+// Evaluate valuta funding annualizzato e basis correnti
+// Con la gamba spot a margine fundingAPR è al netto del costo del prestito (vedi NetCarryAPR)
+// hasPosition indica se esiste già una posizione coperta aperta
+func (s *FundingArbitrageStrategy) Evaluate(fundingAPR, basis float64, hasPosition bool) FundingDecision {
+	if hasPosition {
+		if s.params.MaxBasis > 0 && math.Abs(basis) > s.params.MaxBasis {
+			return FundingDecision{
+				Signal: FundingSignalExit,
+				Reason: fmt.Sprintf("basis %.4f%% oltre il limite di %.4f%%", basis*100, s.params.MaxBasis*100),
+			}
+		}
+		if fundingAPR < s.params.ExitAPR {
+			return FundingDecision{
+				Signal: FundingSignalExit,
+				Reason: fmt.Sprintf("funding annualizzato %.2f%% sotto la soglia di uscita %.2f%%", fundingAPR*100, s.params.ExitAPR*100),
+			}
+		}
+		return FundingDecision{Signal: FundingSignalHold, Reason: "posizione aperta, funding sopra la soglia di uscita"}
+	}
+
+	if fundingAPR < s.params.EntryAPR {
+		return FundingDecision{
+			Signal: FundingSignalHold,
+			Reason: fmt.Sprintf("funding annualizzato %.2f%% sotto la soglia di ingresso %.2f%%", fundingAPR*100, s.params.EntryAPR*100),
+		}
+	}
+	if basis < s.params.MinEntryBasis {
+		return FundingDecision{
+			Signal: FundingSignalHold,
+			Reason: fmt.Sprintf("basis %.4f%% sotto il minimo di ingresso %.4f%%", basis*100, s.params.MinEntryBasis*100),
+		}
+	}
+	if s.params.MaxBasis > 0 && math.Abs(basis) > s.params.MaxBasis {
+		return FundingDecision{
+			Signal: FundingSignalHold,
+			Reason: fmt.Sprintf("basis %.4f%% oltre il limite di %.4f%%", basis*100, s.params.MaxBasis*100),
+		}
+	}
+
+	return FundingDecision{
+		Signal: FundingSignalEnter,
+		Reason: fmt.Sprintf("funding annualizzato %.2f%% sopra la soglia di ingresso %.2f%%", fundingAPR*100, s.params.EntryAPR*100),
+	}
+}
+
+// AccruedFunding stima il funding incassato dalla gamba short in un intervallo di tempo
+// rate è il tasso per intervallo di funding; un tasso negativo indica funding pagato
+func AccruedFunding(quantity, perpPrice, rate, elapsedHours, intervalHours float64) float64 {
+	if intervalHours <= 0 || elapsedHours <= 0 {
+		return 0
+	}
+	return quantity * perpPrice * rate * elapsedHours / intervalHours
+}
+
+// AccruedBorrowInterest stima gli interessi maturati sull'importo preso in prestito per la gamba spot a margine
+// hourlyRate è il tasso orario del prestito; l'importo è espresso nella coin presa in prestito
+func AccruedBorrowInterest(borrowed, hourlyRate, elapsedHours float64) float64 {
+	if borrowed <= 0 || hourlyRate <= 0 || elapsedHours <= 0 {
+		return 0
+	}
+	return borrowed * hourlyRate * elapsedHours
+}
+
+// NetCarryAPR restituisce il rendimento annualizzato della posizione al netto del costo del prestito
+// borrowedShare è la quota del nozionale spot finanziata con il prestito (0 se la gamba spot è pagata per intero)
+func NetCarryAPR(fundingAPR, borrowAPR, borrowedShare float64) float64 {
+	if borrowedShare <= 0 || borrowAPR <= 0 {
+		return fundingAPR
+	}
+	return fundingAPR - borrowAPR*math.Min(borrowedShare, 1)
+}
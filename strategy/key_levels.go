@@ -0,0 +1,102 @@
+package strategy
+
+import (
+	"fmt"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// Nomi dei livelli chiave
+const (
+	LevelPrevDayHigh  = "prev_day_high"
+	LevelPrevDayLow   = "prev_day_low"
+	LevelPrevWeekHigh = "prev_week_high"
+	LevelPrevWeekLow  = "prev_week_low"
+	LevelMidnightOpen = "midnight_open"
+	LevelSessionOpen  = "session_open"
+)
+
+// Level è un livello di prezzo con nome, usato come supporto/resistenza e disegnato sui grafici
+type Level struct {
+	Name  string    `json:"name"`
+	Price float64   `json:"price"`
+	Since time.Time `json:"since"` // Inizio del periodo da cui è calcolato il livello
+}
+
+// KeyLevels calcola i livelli del giorno e della settimana precedenti e le aperture di mezzanotte e della sessione
+// Giorni e settimane sono in UTC e le settimane iniziano il lunedì; la sessione si apre ogni giorno a SessionOpen
+// dopo la mezzanotte UTC (es. 13h30 per l'apertura di New York)
+type KeyLevels struct {
+	SessionOpen time.Duration
+}
+
+// NewKeyLevels crea il calcolatore con l'orario di apertura della sessione
+func NewKeyLevels(sessionOpen time.Duration) (*KeyLevels, error) {
+	if sessionOpen < 0 || sessionOpen >= 24*time.Hour {
+		return nil, fmt.Errorf("l'apertura della sessione deve essere compresa tra 00:00 e 23:59 UTC (%s)", sessionOpen)
+	}
+	return &KeyLevels{SessionOpen: sessionOpen}, nil
+}
+
+// Start restituisce l'inizio del periodo da cui servono le candele per calcolare i livelli all'istante now
+func (k *KeyLevels) Start(now time.Time) time.Time {
+	return weekStart(now.UTC()).AddDate(0, 0, -7)
+}
+
+// Compute calcola i livelli all'istante now dalle candele chiuse (dalla più vecchia alla più recente)
+// I livelli il cui periodo non ha candele vengono omessi
+func (k *KeyLevels) Compute(candles []models.Candle, now time.Time) []Level {
+	now = now.UTC()
+	midnight := now.Truncate(24 * time.Hour)
+	session := midnight.Add(k.SessionOpen)
+	if session.After(now) {
+		session = session.AddDate(0, 0, -1)
+	}
+	week := weekStart(now)
+
+	levels := make([]Level, 0, 6)
+	levels = appendRange(levels, candles, midnight.AddDate(0, 0, -1), midnight, LevelPrevDayHigh, LevelPrevDayLow)
+	levels = appendRange(levels, candles, week.AddDate(0, 0, -7), week, LevelPrevWeekHigh, LevelPrevWeekLow)
+	levels = appendOpen(levels, candles, midnight, LevelMidnightOpen)
+	levels = appendOpen(levels, candles, session, LevelSessionOpen)
+	return levels
+}
+
+// appendRange aggiunge massimo e minimo delle candele aperte in [start, end)
+func appendRange(levels []Level, candles []models.Candle, start, end time.Time, highName, lowName string) []Level {
+	var high, low float64
+	found := false
+	for _, candle := range candles {
+		if candle.Timestamp.Before(start) || !candle.Timestamp.Before(end) {
+			continue
+		}
+		if !found || candle.High > high {
+			high = candle.High
+		}
+		if !found || candle.Low < low {
+			low = candle.Low
+		}
+		found = true
+	}
+	if !found {
+		return levels
+	}
+	return append(levels, Level{Name: highName, Price: high, Since: start}, Level{Name: lowName, Price: low, Since: start})
+}
+
+// appendOpen aggiunge l'apertura della prima candela aperta da start in poi
+func appendOpen(levels []Level, candles []models.Candle, start time.Time, name string) []Level {
+	for _, candle := range candles {
+		if !candle.Timestamp.Before(start) {
+			return append(levels, Level{Name: name, Price: candle.Open, Since: start})
+		}
+	}
+	return levels
+}
+
+// weekStart restituisce la mezzanotte UTC del lunedì della settimana di t
+func weekStart(t time.Time) time.Time {
+	midnight := t.UTC().Truncate(24 * time.Hour)
+	return midnight.AddDate(0, 0, -(int(midnight.Weekday())+6)%7)
+}
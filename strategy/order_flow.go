@@ -0,0 +1,60 @@
+package strategy
+
+import (
+	"fmt"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// OrderFlowFilterParams configura la conferma dei breakout con il delta dei trade pubblici
+type OrderFlowFilterParams struct {
+	MinImbalance float64 // Delta minimo in rapporto al volume della finestra (0.1 = acquisti oltre le vendite del 10% del volume)
+	MinTrades    int     // Trade minimi nella finestra perché il delta sia significativo
+}
+
+// OrderFlowFilter conferma una rottura solo se gli aggressori spingono nella sua direzione:
+// delta positivo (acquisti a mercato oltre le vendite) per i long, negativo per gli short.
+// Lavora sui volumi per lato, così può essere usato con il delta di qualsiasi venue
+type OrderFlowFilter struct {
+	params OrderFlowFilterParams
+}
+
+// NewOrderFlowFilter crea il filtro validando i parametri
+func NewOrderFlowFilter(params OrderFlowFilterParams) (*OrderFlowFilter, error) {
+	if params.MinImbalance < 0 || params.MinImbalance >= 1 {
+		return nil, fmt.Errorf("lo sbilanciamento minimo dell'order flow deve essere compreso tra 0 e 1 (%.2f)", params.MinImbalance)
+	}
+	if params.MinTrades < 1 {
+		return nil, fmt.Errorf("i trade minimi dell'order flow devono essere almeno 1 (%d)", params.MinTrades)
+	}
+	return &OrderFlowFilter{params: params}, nil
+}
+
+// MinTrades restituisce il numero di trade sotto il quale il delta non è significativo
+func (f *OrderFlowFilter) MinTrades() int {
+	return f.params.MinTrades
+}
+
+// MinImbalance restituisce lo sbilanciamento minimo del delta, in rapporto al volume della finestra
+func (f *OrderFlowFilter) MinImbalance() float64 {
+	return f.params.MinImbalance
+}
+
+// Check verifica se il volume in acquisto e in vendita degli aggressori conferma un ingresso nella direzione indicata
+// Restituisce un errore con il motivo del blocco
+func (f *OrderFlowFilter) Check(side models.OrderSide, buyVolume, sellVolume float64) error {
+	total := buyVolume + sellVolume
+	if total <= 0 {
+		return fmt.Errorf("order flow: nessun volume nella finestra")
+	}
+	imbalance := (buyVolume - sellVolume) / total
+	if side == models.OrderSideBuy && imbalance < f.params.MinImbalance {
+		return fmt.Errorf("order flow: delta %.2f (acquisti %.2f, vendite %.2f) sotto il %.0f%% del volume",
+			buyVolume-sellVolume, buyVolume, sellVolume, f.params.MinImbalance*100)
+	}
+	if side == models.OrderSideSell && imbalance > -f.params.MinImbalance {
+		return fmt.Errorf("order flow: delta %.2f (acquisti %.2f, vendite %.2f) non sotto il -%.0f%% del volume",
+			buyVolume-sellVolume, buyVolume, sellVolume, f.params.MinImbalance*100)
+	}
+	return nil
+}
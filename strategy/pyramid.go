@@ -0,0 +1,53 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// PyramidParams configura gli incrementi di una posizione in profitto (pyramiding)
+type PyramidParams struct {
+	MaxAdds   int     // Numero massimo di incrementi oltre l'ingresso iniziale
+	Step      float64 // Movimento favorevole dall'ultimo ingresso che fa scattare l'incremento (0.01 = 1%)
+	SizeDecay float64 // Ogni incremento è questa frazione del precedente (0.5 = metà), a partire dall'ingresso iniziale
+}
+
+// Pyramid decide quando e di quanto incrementare una posizione vincente
+// Gli incrementi hanno size decrescente, così il prezzo medio si sposta poco e il rischio resta concentrato sull'ingresso iniziale
+type Pyramid struct {
+	params PyramidParams
+}
+
+// NewPyramid crea il pyramiding validando i parametri
+func NewPyramid(params PyramidParams) (*Pyramid, error) {
+	if params.MaxAdds <= 0 {
+		return nil, fmt.Errorf("il numero massimo di incrementi deve essere positivo (%d)", params.MaxAdds)
+	}
+	if params.Step <= 0 || params.Step >= 1 {
+		return nil, fmt.Errorf("il passo degli incrementi deve essere compreso tra 0 e 1 (%.4f)", params.Step)
+	}
+	if params.SizeDecay <= 0 || params.SizeDecay >= 1 {
+		return nil, fmt.Errorf("la riduzione della size degli incrementi deve essere compresa tra 0 e 1 (%.2f)", params.SizeDecay)
+	}
+	return &Pyramid{params: params}, nil
+}
+
+// NextAdd restituisce la quantità del prossimo incremento di una posizione nella direzione indicata
+// level è il numero di incrementi già fatti, lastEntry il prezzo dell'ultimo ingresso, entryQuantity la quantità
+// dell'ingresso iniziale. ok è false se gli incrementi sono esauriti o il prezzo non si è mosso abbastanza a favore
+func (p *Pyramid) NextAdd(side models.OrderSide, level int, lastEntry, price, entryQuantity float64) (quantity float64, ok bool) {
+	if level >= p.params.MaxAdds || lastEntry <= 0 {
+		return 0, false
+	}
+
+	move := price/lastEntry - 1
+	if side == models.OrderSideSell {
+		move = -move
+	}
+	if move < p.params.Step {
+		return 0, false
+	}
+	return entryQuantity * math.Pow(p.params.SizeDecay, float64(level+1)), true
+}
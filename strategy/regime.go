@@ -0,0 +1,251 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/taprocess"
+
+	"github.com/markcheno/go-talib"
+)
+
+// Regime è il regime di mercato di un simbolo
+type Regime string
+
+const (
+	RegimeTrending Regime = "trending" // Movimenti direzionali: le rotture tendono a proseguire
+	RegimeRanging  Regime = "ranging"  // Movimenti laterali: le rotture tendono a rientrare
+)
+
+// Metodi di misura del regime
+const (
+	RegimeMethodADX           = "adx"            // Average Directional Index: forza del trend indipendente dalla direzione
+	RegimeMethodVarianceRatio = "variance_ratio" // Rapporto di varianza di Lo-MacKinlay: > 1 trend, < 1 ritorno verso la media
+)
+
+// Nomi degli ingressi del worker DOGE attivabili per regime
+const (
+	EntryBreakout    = "breakout"    // Rottura di muro o supporto, anche in modalità retest
+	EntryLiquidation = "liquidation" // Ingresso contrarian dopo una cascata di liquidazioni
+)
+
+// RegimeEntries elenca gli ingressi che possono essere assegnati a un regime
+var RegimeEntries = []string{EntryBreakout, EntryLiquidation}
+
+// RegimeParams configura la misura del regime
+// Ogni metodo ha due soglie: sopra Trending il regime diventa trending, sotto Ranging diventa ranging;
+// tra le due resta quello precedente, così il regime non oscilla attorno a una soglia unica
+type RegimeParams struct {
+	Method      string
+	ADXPeriod   int     // Periodo dell'ADX (es. 14)
+	ADXTrending float64 // ADX oltre cui il mercato è in trend (es. 25)
+	ADXRanging  float64 // ADX sotto cui il mercato è laterale (es. 20)
+	VRLag       int     // Candele dei rendimenti aggregati del rapporto di varianza (es. 4)
+	VRWindow    int     // Rendimenti su cui calcolare il rapporto di varianza (es. 100)
+	VRTrending  float64 // Rapporto oltre cui il mercato è in trend (es. 1.1)
+	VRRanging   float64 // Rapporto sotto cui il mercato è laterale (es. 0.9)
+	// Source è il prezzo della candela che sostituisce la chiusura nella misura (vuoto = close):
+	// i rendimenti del rapporto di varianza e la serie di chiusura dell'ADX, che usa comunque massimi e minimi
+	Source taprocess.PriceSource
+}
+
+// RegimeDetector classifica il regime di un simbolo dalle candele chiuse
+type RegimeDetector struct {
+	params RegimeParams
+}
+
+// NewRegimeDetector crea il detector validando i parametri del metodo scelto
+func NewRegimeDetector(params RegimeParams) (*RegimeDetector, error) {
+	switch params.Method {
+	case RegimeMethodADX:
+		if params.ADXPeriod < 2 {
+			return nil, fmt.Errorf("il periodo dell'ADX deve essere almeno 2 (%d)", params.ADXPeriod)
+		}
+		if params.ADXRanging <= 0 || params.ADXRanging > params.ADXTrending || params.ADXTrending >= 100 {
+			return nil, fmt.Errorf("le soglie dell'ADX devono rispettare 0 < laterale <= trend < 100 (%.1f, %.1f)",
+				params.ADXRanging, params.ADXTrending)
+		}
+	case RegimeMethodVarianceRatio:
+		if params.VRLag < 2 {
+			return nil, fmt.Errorf("il lag del rapporto di varianza deve essere almeno 2 (%d)", params.VRLag)
+		}
+		if params.VRWindow < 2*params.VRLag {
+			return nil, fmt.Errorf("la finestra del rapporto di varianza deve essere almeno il doppio del lag (%d)", params.VRWindow)
+		}
+		if params.VRRanging <= 0 || params.VRRanging > params.VRTrending {
+			return nil, fmt.Errorf("le soglie del rapporto di varianza devono rispettare 0 < laterale <= trend (%.2f, %.2f)",
+				params.VRRanging, params.VRTrending)
+		}
+	default:
+		return nil, fmt.Errorf("metodo di misura del regime %q non supportato (adx, variance_ratio)", params.Method)
+	}
+	if params.Source == "" {
+		params.Source = taprocess.SourceClose
+	}
+	return &RegimeDetector{params: params}, nil
+}
+
+// MinCandles restituisce il numero minimo di candele chiuse necessarie per misurare il regime
+func (d *RegimeDetector) MinCandles() int {
+	if d.params.Method == RegimeMethodADX {
+		return 2*d.params.ADXPeriod + 1
+	}
+	return d.params.VRWindow + 1
+}
+
+// Measure calcola l'indicatore del regime sulle candele chiuse (dalla più vecchia alla più recente)
+func (d *RegimeDetector) Measure(candles []models.Candle) (float64, error) {
+	if len(candles) < d.MinCandles() {
+		return 0, fmt.Errorf("%d candele chiuse disponibili, ne servono %d", len(candles), d.MinCandles())
+	}
+	if d.params.Method == RegimeMethodADX {
+		high := make([]float64, len(candles))
+		low := make([]float64, len(candles))
+		for i, candle := range candles {
+			high[i], low[i] = candle.High, candle.Low
+		}
+		adx := talib.Adx(high, low, d.params.Source.Series(candles), d.params.ADXPeriod)
+		return adx[len(adx)-1], nil
+	}
+	return varianceRatio(candles[len(candles)-d.params.VRWindow-1:], d.params.VRLag, d.params.Source)
+}
+
+// Classify restituisce il regime corrispondente al valore dell'indicatore
+// Tra le due soglie resta previous; senza un regime precedente vale la soglia più vicina
+func (d *RegimeDetector) Classify(value float64, previous Regime) Regime {
+	trending, ranging := d.params.ADXTrending, d.params.ADXRanging
+	if d.params.Method == RegimeMethodVarianceRatio {
+		trending, ranging = d.params.VRTrending, d.params.VRRanging
+	}
+	switch {
+	case value >= trending:
+		return RegimeTrending
+	case value <= ranging:
+		return RegimeRanging
+	case previous != "":
+		return previous
+	case value >= (trending+ranging)/2:
+		return RegimeTrending
+	default:
+		return RegimeRanging
+	}
+}
+
+// Method restituisce il metodo di misura del regime
+func (d *RegimeDetector) Method() string {
+	return d.params.Method
+}
+
+// varianceRatio calcola il rapporto tra la varianza dei rendimenti logaritmici su lag candele
+// (sovrapposti) e lag volte la varianza dei rendimenti su una candela, sui prezzi della sorgente
+func varianceRatio(candles []models.Candle, lag int, source taprocess.PriceSource) (float64, error) {
+	prices := source.Series(candles)
+	returns := make([]float64, 0, len(candles)-1)
+	for i := 1; i < len(prices); i++ {
+		if prices[i-1] <= 0 || prices[i] <= 0 {
+			return 0, fmt.Errorf("prezzo %s non valido alla candela del %s", source, candles[i].Timestamp.Format(time.RFC3339))
+		}
+		returns = append(returns, math.Log(prices[i]/prices[i-1]))
+	}
+
+	aggregated := make([]float64, 0, len(returns)-lag+1)
+	for i := lag; i <= len(returns); i++ {
+		var sum float64
+		for _, r := range returns[i-lag : i] {
+			sum += r
+		}
+		aggregated = append(aggregated, sum)
+	}
+
+	single := variance(returns)
+	if single == 0 {
+		return 0, fmt.Errorf("prezzo costante su %d candele", len(candles))
+	}
+	return variance(aggregated) / (float64(lag) * single), nil
+}
+
+// variance restituisce la varianza campionaria dei valori
+func variance(values []float64) float64 {
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	var sum float64
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return sum / float64(len(values)-1)
+}
+
+// RegimeState è il regime corrente di un simbolo
+type RegimeState struct {
+	Symbol    string
+	Regime    Regime
+	Value     float64   // Ultimo valore dell'indicatore
+	Since     time.Time // Inizio del regime corrente
+	UpdatedAt time.Time // Ultima misura
+}
+
+// RegimeTracker conserva il regime corrente di ogni simbolo e gli ingressi attivi in ciascun regime
+// Un tracker nil, o un simbolo di cui non si conosce ancora il regime, ammette tutti gli ingressi
+type RegimeTracker struct {
+	mu      sync.RWMutex
+	states  map[string]RegimeState
+	entries map[Regime][]string
+}
+
+// NewRegimeTracker crea il tracker con gli ingressi attivi per regime, validandone i nomi
+func NewRegimeTracker(entries map[Regime][]string) (*RegimeTracker, error) {
+	for regime, names := range entries {
+		for _, name := range names {
+			if !slices.Contains(RegimeEntries, name) {
+				return nil, fmt.Errorf("ingresso %q del regime %s non valido (%s)", name, regime, strings.Join(RegimeEntries, ", "))
+			}
+		}
+	}
+	return &RegimeTracker{states: make(map[string]RegimeState), entries: entries}, nil
+}
+
+// Get restituisce il regime corrente del simbolo, se già misurato
+func (t *RegimeTracker) Get(symbol string) (RegimeState, bool) {
+	if t == nil {
+		return RegimeState{}, false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	state, ok := t.states[strings.ToUpper(symbol)]
+	return state, ok
+}
+
+// Update registra una misura del regime del simbolo e restituisce lo stato precedente;
+// changed è true se il regime è cambiato (o è stato misurato per la prima volta)
+func (t *RegimeTracker) Update(symbol string, regime Regime, value float64, at time.Time) (previous RegimeState, changed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	symbol = strings.ToUpper(symbol)
+	previous, ok := t.states[symbol]
+	state := RegimeState{Symbol: symbol, Regime: regime, Value: value, Since: previous.Since, UpdatedAt: at}
+	if !ok || previous.Regime != regime {
+		state.Since = at
+		changed = true
+	}
+	t.states[symbol] = state
+	return previous, changed
+}
+
+// Allows verifica se l'ingresso indicato è attivo nel regime corrente del simbolo
+func (t *RegimeTracker) Allows(symbol, entry string) bool {
+	state, ok := t.Get(symbol)
+	if !ok {
+		return true
+	}
+	return slices.Contains(t.entries[state.Regime], entry)
+}
@@ -0,0 +1,47 @@
+package strategy
+
+import (
+	"fmt"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// SentimentFilterParams contiene le soglie del filtro sul Fear & Greed Index (0-100)
+// Il filtro è contrarian: evita i long quando il mercato è euforico e gli short quando è in panico
+type SentimentFilterParams struct {
+	MaxLong  float64 // Nessun long con indice pari o superiore (es. 80 = avidità estrema)
+	MinShort float64 // Nessuno short con indice pari o inferiore (es. 20 = paura estrema)
+}
+
+// SentimentFilter decide se un ingresso è coerente con il sentiment di mercato
+type SentimentFilter struct {
+	params SentimentFilterParams
+}
+
+// NewSentimentFilter crea il filtro validando le soglie
+func NewSentimentFilter(params SentimentFilterParams) (*SentimentFilter, error) {
+	if params.MaxLong <= 0 || params.MaxLong > 100 || params.MinShort < 0 || params.MinShort >= 100 {
+		return nil, fmt.Errorf("le soglie del filtro di sentiment devono essere comprese tra 0 e 100 (long %.0f, short %.0f)",
+			params.MaxLong, params.MinShort)
+	}
+	return &SentimentFilter{params: params}, nil
+}
+
+// AllowLong verifica se aprire un long con il valore corrente dell'indice
+func (f *SentimentFilter) AllowLong(value float64) bool {
+	return value < f.params.MaxLong
+}
+
+// AllowShort verifica se aprire uno short con il valore corrente dell'indice
+func (f *SentimentFilter) AllowShort(value float64) bool {
+	return value > f.params.MinShort
+}
+
+// Threshold restituisce la soglia dell'indice per un ingresso nella direzione indicata:
+// il massimo (escluso) per i long, il minimo (escluso) per gli short
+func (f *SentimentFilter) Threshold(side models.OrderSide) float64 {
+	if side == models.OrderSideBuy {
+		return f.params.MaxLong
+	}
+	return f.params.MinShort
+}
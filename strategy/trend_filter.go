@@ -0,0 +1,81 @@
+package strategy
+
+import (
+	"fmt"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/taprocess"
+)
+
+// TrendFilterParams configura il filtro di trend sull'EMA
+type TrendFilterParams struct {
+	Period       int // Periodo dell'EMA (es. 223)
+	SlopeCandles int // Candele su cui misurare la pendenza dell'EMA; 0 disattiva il controllo della pendenza
+	// Source è il prezzo della candela da cui si calcola l'EMA (vuoto = close); il prezzo confrontato resta la chiusura
+	Source taprocess.PriceSource
+}
+
+// TrendFilter ammette solo gli ingressi nella direzione del trend: long con il prezzo sopra l'EMA,
+// short con il prezzo sotto. Con il controllo della pendenza l'EMA deve anche salire (long) o scendere (short).
+// L'EMA è calcolata dalla pipeline taprocess sulla sorgente configurata, come l'EMA223 degli indicatori
+type TrendFilter struct {
+	params     TrendFilterParams
+	indicators *taprocess.TalibProcessor // Pipeline con l'EMA lunga impostata al periodo del filtro
+}
+
+// NewTrendFilter crea il filtro validando i parametri
+func NewTrendFilter(params TrendFilterParams) (*TrendFilter, error) {
+	if params.Period < 2 {
+		return nil, fmt.Errorf("il periodo dell'EMA del filtro di trend deve essere almeno 2 (%d)", params.Period)
+	}
+	if params.SlopeCandles < 0 {
+		return nil, fmt.Errorf("le candele della pendenza del filtro di trend non possono essere negative (%d)", params.SlopeCandles)
+	}
+	indicators := taprocess.NewTalibProcessor()
+	indicators.EMA223Period = params.Period
+	indicators.Sources.EMA223 = params.Source
+	return &TrendFilter{params: params, indicators: indicators}, nil
+}
+
+// MinCandles restituisce il numero minimo di candele chiuse necessarie per valutare il filtro
+// La pipeline calcola anche gli altri indicatori, quindi servono almeno le candele del più lungo
+func (f *TrendFilter) MinCandles() int {
+	return max(f.params.Period+f.params.SlopeCandles, f.indicators.MinCandles())
+}
+
+// Check verifica se un ingresso nella direzione indicata è coerente con il trend delle candele
+// (dalla più vecchia alla più recente, solo candele chiuse). Restituisce un errore con il motivo del blocco;
+// con dati insufficienti l'ingresso viene bloccato, perché il trend non è determinabile
+func (f *TrendFilter) Check(side models.OrderSide, candles []models.Candle) error {
+	if len(candles) < f.MinCandles() {
+		return fmt.Errorf("filtro di trend: %d candele chiuse disponibili, ne servono %d", len(candles), f.MinCandles())
+	}
+
+	taCandles, err := f.indicators.ProcessCandlesWithIndicators(candles)
+	if err != nil {
+		return fmt.Errorf("filtro di trend: %w", err)
+	}
+	last := len(taCandles) - 1
+	price, current := taCandles[last].Close, *taCandles[last].EMA223
+
+	long := side == models.OrderSideBuy
+	if long && price <= current {
+		return fmt.Errorf("filtro di trend: prezzo %.6f non sopra l'EMA%d %.6f", price, f.params.Period, current)
+	}
+	if !long && price >= current {
+		return fmt.Errorf("filtro di trend: prezzo %.6f non sotto l'EMA%d %.6f", price, f.params.Period, current)
+	}
+
+	if f.params.SlopeCandles > 0 {
+		previous := *taCandles[last-f.params.SlopeCandles].EMA223
+		if long && current <= previous {
+			return fmt.Errorf("filtro di trend: EMA%d non crescente nelle ultime %d candele (%.6f -> %.6f)",
+				f.params.Period, f.params.SlopeCandles, previous, current)
+		}
+		if !long && current >= previous {
+			return fmt.Errorf("filtro di trend: EMA%d non decrescente nelle ultime %d candele (%.6f -> %.6f)",
+				f.params.Period, f.params.SlopeCandles, previous, current)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/taprocess"
+)
+
+// wickCandles restituisce candele che chiudono sul massimo con una lunga ombra inferiore
+// e un'ultima candela che chiude appena sotto le precedenti: sotto l'EMA delle chiusure, sopra quella di hl2
+func wickCandles(n int) []models.Candle {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := make([]models.Candle, n)
+	for i := range candles {
+		candles[i] = models.Candle{Timestamp: start.Add(time.Duration(i) * time.Minute), Open: 1, High: 1, Low: 0.9, Close: 1, Volume: 1000}
+	}
+	candles[n-1].Close = 0.99
+	return candles
+}
+
+func TestTrendFilterPriceSource(t *testing.T) {
+	tests := []struct {
+		source    taprocess.PriceSource
+		wantAllow bool
+	}{
+		{source: taprocess.SourceClose, wantAllow: false},
+		{source: taprocess.SourceHL2, wantAllow: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.source), func(t *testing.T) {
+			filter, err := NewTrendFilter(TrendFilterParams{Period: 50, Source: tt.source})
+			if err != nil {
+				t.Fatalf("filter: %v", err)
+			}
+			err = filter.Check(models.OrderSideBuy, wickCandles(filter.MinCandles()))
+			if allowed := err == nil; allowed != tt.wantAllow {
+				t.Errorf("long allowed = %v (%v), want %v", allowed, err, tt.wantAllow)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// VolumeProfileParams configura il calcolo del volume profile
+type VolumeProfileParams struct {
+	Bins      int     // Fasce di prezzo in cui dividere il range della sessione
+	ValueArea float64 // Quota del volume compresa nella value area (0.7 = 70%)
+}
+
+// VolumeProfile è la distribuzione del volume per fascia di prezzo in una sessione
+type VolumeProfile struct {
+	Start  time.Time // Apertura della sessione
+	Low    float64   // Minimo della sessione, limite inferiore della prima fascia
+	High   float64   // Massimo della sessione, limite superiore dell'ultima fascia
+	Volume []float64 // Volume per fascia, dalla più bassa alla più alta
+	POC    float64   // Point of control: centro della fascia con più volume
+	VAH    float64   // Value area high: limite superiore della value area
+	VAL    float64   // Value area low: limite inferiore della value area
+}
+
+// Levels restituisce i livelli della sessione usati come supporti e resistenze: VAL, POC e VAH
+func (p *VolumeProfile) Levels() []float64 {
+	return []float64{p.VAL, p.POC, p.VAH}
+}
+
+// VolumeProfiler calcola il volume profile delle sessioni dalle candele
+// Il volume di ogni candela è distribuito tra le fasce coperte dal suo range (minimo-massimo),
+// in proporzione alla sovrapposizione: con candele a 1 minuto l'approssimazione è vicina al profilo per tick
+type VolumeProfiler struct {
+	params VolumeProfileParams
+}
+
+// NewVolumeProfiler crea il calcolatore validando i parametri
+func NewVolumeProfiler(params VolumeProfileParams) (*VolumeProfiler, error) {
+	if params.Bins < 2 {
+		return nil, fmt.Errorf("le fasce del volume profile devono essere almeno 2 (%d)", params.Bins)
+	}
+	if params.ValueArea <= 0 || params.ValueArea >= 1 {
+		return nil, fmt.Errorf("la value area deve essere compresa tra 0 e 1 (%.2f)", params.ValueArea)
+	}
+	return &VolumeProfiler{params: params}, nil
+}
+
+// Sessions divide le candele (dalla più vecchia alla più recente) in sessioni di durata session, allineate
+// all'ora UTC, e restituisce il profilo di ogni sessione in ordine cronologico. Le sessioni senza volume sono omesse
+func (p *VolumeProfiler) Sessions(candles []models.Candle, session time.Duration) []*VolumeProfile {
+	var profiles []*VolumeProfile
+	for start := 0; start < len(candles); {
+		sessionStart := candles[start].Timestamp.UTC().Truncate(session)
+		end := start + 1
+		for end < len(candles) && candles[end].Timestamp.UTC().Truncate(session).Equal(sessionStart) {
+			end++
+		}
+		if profile, err := p.Build(candles[start:end]); err == nil {
+			profile.Start = sessionStart
+			profiles = append(profiles, profile)
+		}
+		start = end
+	}
+	return profiles
+}
+
+// Build calcola il volume profile delle candele indicate come un'unica sessione
+func (p *VolumeProfiler) Build(candles []models.Candle) (*VolumeProfile, error) {
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("nessuna candela per il volume profile")
+	}
+
+	profile := &VolumeProfile{
+		Start:  candles[0].Timestamp.UTC(),
+		Low:    candles[0].Low,
+		High:   candles[0].High,
+		Volume: make([]float64, p.params.Bins),
+	}
+	for _, candle := range candles {
+		profile.Low = math.Min(profile.Low, candle.Low)
+		profile.High = math.Max(profile.High, candle.High)
+	}
+	if profile.High <= profile.Low {
+		return nil, fmt.Errorf("range di prezzo nullo (%.6f)", profile.Low)
+	}
+
+	width := (profile.High - profile.Low) / float64(p.params.Bins)
+	var total float64
+	for _, candle := range candles {
+		if candle.Volume <= 0 {
+			continue
+		}
+		total += candle.Volume
+		p.distribute(profile, width, candle)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("nessun volume nelle %d candele", len(candles))
+	}
+
+	poc := 0
+	for i, volume := range profile.Volume {
+		if volume > profile.Volume[poc] {
+			poc = i
+		}
+	}
+	low, high := p.valueArea(profile.Volume, poc, total)
+
+	profile.POC = profile.Low + (float64(poc)+0.5)*width
+	profile.VAL = profile.Low + float64(low)*width
+	profile.VAH = profile.Low + float64(high+1)*width
+	return profile, nil
+}
+
+// distribute assegna il volume della candela alle fasce coperte dal suo range
+// Una candela senza range (minimo uguale al massimo) assegna tutto il volume alla fascia del suo prezzo
+func (p *VolumeProfiler) distribute(profile *VolumeProfile, width float64, candle models.Candle) {
+	bin := func(price float64) int {
+		return min(int((price-profile.Low)/width), p.params.Bins-1)
+	}
+
+	if candle.High <= candle.Low {
+		profile.Volume[bin(candle.Close)] += candle.Volume
+		return
+	}
+	for i := bin(candle.Low); i <= bin(candle.High); i++ {
+		binLow := profile.Low + float64(i)*width
+		overlap := math.Min(candle.High, binLow+width) - math.Max(candle.Low, binLow)
+		if overlap > 0 {
+			profile.Volume[i] += candle.Volume * overlap / (candle.High - candle.Low)
+		}
+	}
+}
+
+// valueArea estende la value area dal POC aggiungendo ogni volta la fascia adiacente con più volume,
+// finché non contiene la quota ValueArea del volume totale; restituisce la prima e l'ultima fascia
+func (p *VolumeProfiler) valueArea(volume []float64, poc int, total float64) (low, high int) {
+	low, high = poc, poc
+	covered := volume[poc]
+	for covered < p.params.ValueArea*total && (low > 0 || high < len(volume)-1) {
+		below, above := -1.0, -1.0
+		if low > 0 {
+			below = volume[low-1]
+		}
+		if high < len(volume)-1 {
+			above = volume[high+1]
+		}
+		if above >= below {
+			high++
+			covered += above
+		} else {
+			low--
+			covered += below
+		}
+	}
+	return low, high
+}
+
+// NearestLevels restituisce il livello dei profili più vicino sotto il prezzo (supporto) e sopra (resistenza)
+// Un valore 0 indica che non c'è alcun livello da quel lato
+func NearestLevels(profiles []*VolumeProfile, price float64) (support, resistance float64) {
+	for _, profile := range profiles {
+		for _, level := range profile.Levels() {
+			if level < price && level > support {
+				support = level
+			}
+			if level > price && (resistance == 0 || level < resistance) {
+				resistance = level
+			}
+		}
+	}
+	return support, resistance
+}
@@ -0,0 +1,64 @@
+package strategy
+
+import "fmt"
+
+// WarmUp raccoglie lo storico minimo di candele chiuse dichiarato dai componenti di una strategia
+// (indicatori, filtri, stop): finché le candele disponibili non coprono il componente più esigente la strategia
+// non opera, invece di lasciare a ogni componente il proprio controllo sui dati insufficienti
+type WarmUp struct {
+	requirements []warmUpRequirement
+}
+
+// warmUpRequirement è lo storico richiesto da un componente
+type warmUpRequirement struct {
+	component string
+	candles   int
+}
+
+// WarmUpError indica che le candele chiuse disponibili non bastano ancora al componente più esigente
+type WarmUpError struct {
+	Component string // Componente che richiede più candele
+	Required  int
+	Available int
+}
+
+// Error implementa l'interfaccia error
+func (e *WarmUpError) Error() string {
+	return fmt.Sprintf("warm-up in corso: %d candele chiuse su %d richieste da %s", e.Available, e.Required, e.Component)
+}
+
+// NewWarmUp crea un warm-up senza requisiti
+func NewWarmUp() *WarmUp {
+	return &WarmUp{}
+}
+
+// Require dichiara le candele chiuse richieste da un componente; i requisiti non positivi vengono ignorati
+func (w *WarmUp) Require(component string, candles int) *WarmUp {
+	if candles > 0 {
+		w.requirements = append(w.requirements, warmUpRequirement{component: component, candles: candles})
+	}
+	return w
+}
+
+// Candles restituisce le candele chiuse richieste dal componente più esigente e il suo nome
+// A parità di requisito prevale il componente dichiarato per primo
+func (w *WarmUp) Candles() (int, string) {
+	var required int
+	var component string
+	for _, requirement := range w.requirements {
+		if requirement.candles > required {
+			required, component = requirement.candles, requirement.component
+		}
+	}
+	return required, component
+}
+
+// Check verifica che le candele chiuse disponibili coprano tutti i requisiti
+// Restituisce un *WarmUpError finché il warm-up non è completo
+func (w *WarmUp) Check(available int) error {
+	required, component := w.Candles()
+	if available < required {
+		return &WarmUpError{Component: component, Required: required, Available: available}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package taprocess
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// PriceSource è il prezzo della candela da cui viene calcolato un indicatore
+type PriceSource string
+
+const (
+	SourceClose PriceSource = "close" // Prezzo di chiusura
+	SourceHL2   PriceSource = "hl2"   // (high + low) / 2
+	SourceHLC3  PriceSource = "hlc3"  // Typical price: (high + low + close) / 3
+	SourceOHLC4 PriceSource = "ohlc4" // (open + high + low + close) / 4
+)
+
+// Nomi degli indicatori della pipeline, usati come chiavi nella configurazione delle sorgenti
+const (
+	IndicatorRSI    = "rsi"
+	IndicatorEMA20  = "ema20"
+	IndicatorEMA60  = "ema60"
+	IndicatorEMA223 = "ema223"
+	IndicatorRegime = "regime" // Misura del regime di mercato (ADX o rapporto di varianza)
+)
+
+// ParsePriceSource converte il nome di una sorgente di prezzo; il nome vuoto equivale a close
+func ParsePriceSource(value string) (PriceSource, error) {
+	switch source := PriceSource(strings.ToLower(strings.TrimSpace(value))); source {
+	case "":
+		return SourceClose, nil
+	case SourceClose, SourceHL2, SourceHLC3, SourceOHLC4:
+		return source, nil
+	default:
+		return "", fmt.Errorf("sorgente di prezzo %q non supportata (close, hl2, hlc3 o ohlc4)", value)
+	}
+}
+
+// Price restituisce il prezzo della candela secondo la sorgente
+func (s PriceSource) Price(candle models.Candle) float64 {
+	switch s {
+	case SourceHL2:
+		return (candle.High + candle.Low) / 2
+	case SourceHLC3:
+		return (candle.High + candle.Low + candle.Close) / 3
+	case SourceOHLC4:
+		return (candle.Open + candle.High + candle.Low + candle.Close) / 4
+	default:
+		return candle.Close
+	}
+}
+
+// Series estrae dalle candele la serie di prezzi della sorgente
+func (s PriceSource) Series(candles []models.Candle) []float64 {
+	prices := make([]float64, len(candles))
+	for i, candle := range candles {
+		prices[i] = s.Price(candle)
+	}
+	return prices
+}
+
+// IndicatorSources contiene la sorgente di prezzo di ogni indicatore; i campi vuoti usano close
+// EMA223 vale anche per l'EMA del filtro di trend e RSI per la feature rsi14 dello scorer
+type IndicatorSources struct {
+	RSI    PriceSource
+	EMA20  PriceSource
+	EMA60  PriceSource
+	EMA223 PriceSource
+	Regime PriceSource
+}
+
+// ParseIndicatorSources converte la configurazione indicatore -> sorgente (es. rsi=hlc3, ema20=hl2)
+// Gli indicatori non indicati usano close
+func ParseIndicatorSources(values map[string]string) (IndicatorSources, error) {
+	sources := IndicatorSources{RSI: SourceClose, EMA20: SourceClose, EMA60: SourceClose, EMA223: SourceClose, Regime: SourceClose}
+	fields := map[string]*PriceSource{
+		IndicatorRSI:    &sources.RSI,
+		IndicatorEMA20:  &sources.EMA20,
+		IndicatorEMA60:  &sources.EMA60,
+		IndicatorEMA223: &sources.EMA223,
+		IndicatorRegime: &sources.Regime,
+	}
+	for indicator, value := range values {
+		field, ok := fields[strings.ToLower(indicator)]
+		if !ok {
+			names := make([]string, 0, len(fields))
+			for name := range fields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return IndicatorSources{}, fmt.Errorf("indicatore %q sconosciuto (%s)", indicator, strings.Join(names, ", "))
+		}
+		source, err := ParsePriceSource(value)
+		if err != nil {
+			return IndicatorSources{}, fmt.Errorf("indicatore %s: %w", indicator, err)
+		}
+		*field = source
+	}
+	return sources, nil
+}
@@ -15,6 +15,9 @@ type TalibProcessor struct {
 	EMA20Period  int
 	EMA60Period  int
 	EMA223Period int
+
+	// Sorgente di prezzo di ogni indicatore, usata da ProcessCandlesWithIndicators
+	Sources IndicatorSources
 }
 
 // NewTalibProcessor crea una nuova istanza di TalibProcessor con i periodi standard
@@ -24,17 +27,25 @@ func NewTalibProcessor() *TalibProcessor {
 		EMA20Period:  20,
 		EMA60Period:  60,
 		EMA223Period: 223,
+		Sources:      IndicatorSources{RSI: SourceClose, EMA20: SourceClose, EMA60: SourceClose, EMA223: SourceClose, Regime: SourceClose},
 	}
 }
 
+// MinCandles restituisce le candele necessarie per calcolare tutti gli indicatori: il periodo più lungo
+// tra le EMA e l'RSI, che richiede una chiusura in più del periodo
+func (tp *TalibProcessor) MinCandles() int {
+	return max(tp.EMA223Period, tp.EMA60Period, tp.EMA20Period, tp.RSIPeriod+1)
+}
+
 // ProcessIndicators implementa l'interfaccia TAProcessor
+// Riceve solo i prezzi di chiusura, quindi ignora Sources: per hl2, hlc3 e ohlc4 usare ProcessCandlesWithIndicators
 func (tp *TalibProcessor) ProcessIndicators(closingPrices []float64) ([]*models.TACandlestick, error) {
 	if len(closingPrices) == 0 {
 		return nil, fmt.Errorf("closingPrices slice è vuota")
 	}
 
 	// Verifica che abbiamo abbastanza dati per calcolare tutti gli indicatori
-	minRequiredData := tp.EMA223Period // EMA223 richiede il maggior numero di dati
+	minRequiredData := tp.MinCandles()
 	if len(closingPrices) < minRequiredData {
 		return nil, fmt.Errorf("dati insufficienti: richiesti almeno %d prezzi, ricevuti %d", minRequiredData, len(closingPrices))
 	}
@@ -93,23 +104,32 @@ func (tp *TalibProcessor) ProcessIndicators(closingPrices []float64) ([]*models.
 	return results, nil
 }
 
-// ProcessCandlesWithIndicators prende candele esistenti e calcola gli indicatori
+// ProcessCandlesWithIndicators prende candele esistenti e calcola gli indicatori,
+// ognuno sulla serie di prezzi della propria sorgente (close se non configurata)
 func (tp *TalibProcessor) ProcessCandlesWithIndicators(candles []models.Candle) ([]*models.TACandlestick, error) {
 	if len(candles) == 0 {
 		return nil, fmt.Errorf("candles slice è vuota")
 	}
-
-	// Estrai i prezzi di chiusura
-	closingPrices := make([]float64, len(candles))
-	for i, candle := range candles {
-		closingPrices[i] = candle.Close
+	// go-talib va in panic con serie più corte del periodo
+	if minRequiredData := tp.MinCandles(); len(candles) < minRequiredData {
+		return nil, fmt.Errorf("dati insufficienti: richieste almeno %d candele, ricevute %d", minRequiredData, len(candles))
 	}
 
-	// Calcola gli indicatori
-	ema223Values := talib.Ema(closingPrices, tp.EMA223Period)
-	ema20Values := talib.Ema(closingPrices, tp.EMA20Period)
-	ema60Values := talib.Ema(closingPrices, tp.EMA60Period)
-	rsi14Values := talib.Rsi(closingPrices, tp.RSIPeriod)
+	// Calcola gli indicatori, estraendo una sola volta la serie di ogni sorgente
+	series := make(map[PriceSource][]float64)
+	prices := func(source PriceSource) []float64 {
+		if source == "" {
+			source = SourceClose
+		}
+		if _, ok := series[source]; !ok {
+			series[source] = source.Series(candles)
+		}
+		return series[source]
+	}
+	ema223Values := talib.Ema(prices(tp.Sources.EMA223), tp.EMA223Period)
+	ema20Values := talib.Ema(prices(tp.Sources.EMA20), tp.EMA20Period)
+	ema60Values := talib.Ema(prices(tp.Sources.EMA60), tp.EMA60Period)
+	rsi14Values := talib.Rsi(prices(tp.Sources.RSI), tp.RSIPeriod)
 
 	// Crea la slice di risultati mantenendo i dati OHLCV originali
 	results := make([]*models.TACandlestick, len(candles))
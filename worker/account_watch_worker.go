@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/errorreport"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/services"
+)
+
+// AccountWatchWorker confronta periodicamente ordini e posizioni aperti su Bybit con quelli del bot
+// e segnala subito quelli sconosciuti, frutto di operazioni manuali o di una API key compromessa
+type AccountWatchWorker struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	service  *services.AccountWatchService
+	reporter *errorreport.Reporter // Segnalazione delle anomalie su Sentry; nil se disabilitata
+}
+
+// NewAccountWatchWorker crea il worker sul processor di trading Bybit
+// Il simbolo di copertura viene ignorato: lo short del portafoglio non è registrato tra gli ordini
+func NewAccountWatchWorker(deps *SystemDependencies) (*AccountWatchWorker, error) {
+	cfg := deps.Config.Watch
+	if deps.OrderProcessor == nil {
+		return nil, fmt.Errorf("credenziali Bybit non configurate")
+	}
+
+	ignore := append([]string{}, cfg.IgnoreSymbols...)
+	if deps.Config.Hedge.Enabled {
+		ignore = append(ignore, deps.Config.Hedge.Symbol)
+	}
+	service, err := services.NewAccountWatchService(deps.OrderProcessor, deps.RepoManager, services.AccountWatchParams{
+		Grace:         cfg.Grace,
+		CancelUnknown: cfg.CancelUnknown,
+		IgnoreSymbols: ignore,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &AccountWatchWorker{
+		ctx:      ctx,
+		cancel:   cancel,
+		service:  service,
+		reporter: deps.Errors,
+	}, nil
+}
+
+// ExecuteTradingCycle esegue un controllo dell'account
+func (w *AccountWatchWorker) ExecuteTradingCycle() {
+	ctx, cancel := context.WithTimeout(w.ctx, time.Minute)
+	defer cancel()
+
+	report, err := w.service.Check(ctx)
+	if err != nil {
+		log.Printf("❌ Errore controllo attività account: %v", err)
+		return
+	}
+
+	for _, unknown := range report.Orders {
+		order := unknown.Order
+		if unknown.New {
+			w.alert(i18n.T("alert.unknown_order", order.Symbol, order.Side, order.OrderType, order.OrderID, order.Quantity, order.Price),
+				order.Symbol, "order:"+order.OrderID)
+		}
+		if unknown.Canceled {
+			log.Printf("🛑 Ordine sconosciuto %s %s cancellato", order.Symbol, order.OrderID)
+		} else if unknown.CancelErr != nil {
+			log.Printf("❌ Cancellazione ordine sconosciuto %s %s fallita: %v", order.Symbol, order.OrderID, unknown.CancelErr)
+		}
+	}
+
+	for _, unknown := range report.Positions {
+		position := unknown.Position
+		if unknown.New {
+			w.alert(i18n.T("alert.unknown_position", position.Side, position.Symbol, position.Size),
+				position.Symbol, "position:"+position.Symbol)
+		}
+	}
+
+	if len(report.Orders) == 0 && len(report.Positions) == 0 {
+		log.Printf("Account: nessun ordine o posizione sconosciuti")
+	}
+}
+
+// alert registra l'anomalia nei log e la segnala su Sentry
+func (w *AccountWatchWorker) alert(message, symbol, key string) {
+	log.Println(message)
+	w.reporter.CaptureError(errors.New(message), errorreport.Context{
+		Component:   "account-watch",
+		Worker:      "account-watch",
+		Symbol:      symbol,
+		Fingerprint: []string{"account-watch", key},
+	})
+}
+
+// GetName implementa l'interfaccia CronWorker
+func (w *AccountWatchWorker) GetName() string {
+	return "Account Watch Worker"
+}
+
+// Stop ferma il worker
+func (w *AccountWatchWorker) Stop() {
+	log.Println("Stopping Account Watch Worker...")
+	w.cancel()
+}
@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/correlation"
+	"cross-exchange-arbitrage/features"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/strategy"
+)
+
+// trailAVWAP sposta lo stop loss della posizione aperta del simbolo sul VWAP ancorato alla candela di rottura
+// Se l'ancora non è in memoria (es. dopo un riavvio) usa la candela chiusa prima dell'ingresso aperto nel database.
+// Il VWAP è calcolato dalla cache delle candele, aggiornata con le ultime candele dell'exchange
+func (w *DogeTradingSystemWorker) trailAVWAP(symbol string) {
+	if w.avwapTrail == nil || !w.flags.Enabled(features.TrailingStop) {
+		return
+	}
+
+	positions, err := w.orderProcessor.GetPositions(w.ctx, symbol)
+	if err != nil {
+		log.Printf("⚠️  VWAP ancorato: errore nel recupero della posizione %s: %v", symbol, err)
+		return
+	}
+	if len(positions) == 0 || !positions[0].Parsed.IsActive() {
+		return
+	}
+	position := positions[0]
+	markPrice := position.Parsed.MarkPrice
+	if markPrice <= 0 {
+		log.Printf("⚠️  VWAP ancorato: mark price di %s non disponibile", symbol)
+		return
+	}
+
+	anchor, ok := w.anchors.Anchor(symbol)
+	if !ok {
+		entry, err := w.repoManager.Order().GetLatestEntry(w.ctx, symbol)
+		if err != nil {
+			log.Printf("⚠️  VWAP ancorato: ancora di %s non disponibile: %v", symbol, err)
+			return
+		}
+		anchor = entry.CreatedAt.UTC().Truncate(time.Minute).Add(-time.Minute)
+	}
+
+	if candleResponse := w.fetchLast1000Candles(); candleResponse != nil {
+		w.cacheClosedCandles(candleResponse.Closed())
+	}
+	records, err := w.repoManager.Candle().GetRange(w.ctx, symbol, models.DerivativesMarket, models.Timeframe1m, anchor, time.Now().UTC())
+	if err != nil {
+		log.Printf("⚠️  VWAP ancorato: errore lettura cache candele %s: %v", symbol, err)
+		return
+	}
+	candles := make([]models.Candle, len(records))
+	for i, record := range records {
+		candles[i] = record.ToCandle()
+	}
+	vwap, count, err := strategy.AnchoredVWAP(candles, anchor)
+	if err != nil {
+		log.Printf("⚠️  VWAP ancorato %s: %v", symbol, err)
+		return
+	}
+
+	side := models.OrderSideBuy
+	if position.Parsed.IsShort() {
+		side = models.OrderSideSell
+	}
+	current := position.Parsed.StopLoss
+	stopLoss, ok := w.avwapTrail.StopLoss(side, vwap, count, current, markPrice)
+	log.Printf("📏 VWAP ancorato %s dal %s: %.6f su %d candele (mark %.6f, stop %s)",
+		symbol, anchor.Format("2006-01-02 15:04"), vwap, count, markPrice, position.StopLoss)
+	if !ok {
+		return
+	}
+
+	resp, err := w.orderProcessor.UpdateOrder(w.ctx, orderprocessor.UpdateOrderParams{
+		Symbol:      symbol,
+		StopLoss:    &stopLoss,
+		PositionIdx: position.PositionIdx,
+	})
+	if err != nil {
+		correlation.Logf(w.ctx, "❌ VWAP ancorato: errore aggiornamento stop loss %s: %v", symbol, err)
+		return
+	}
+	if !resp.IsSuccess() {
+		correlation.Logf(w.ctx, "❌ VWAP ancorato: stop loss %s rifiutato - %s (codice: %s)", symbol, resp.ErrorMessage, resp.ErrorCode)
+		return
+	}
+	correlation.Logf(w.ctx, "🛡️  VWAP ancorato: stop loss %s %s spostato da %s a %.6f", symbol, side, position.StopLoss, stopLoss)
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"log"
+
+	"cross-exchange-arbitrage/arbitrage"
+	"cross-exchange-arbitrage/book"
+)
+
+// startArbitrageDetector avvia il rilevamento degli arbitraggi sulla vista consolidata dei prezzi
+// La profondità dell'order book di Bybit alimenta la stima dello slippage; le altre venue
+// usano la liquidità del miglior livello. Le opportunità nette positive vengono registrate nei log
+func startArbitrageDetector(ctx context.Context, deps *SystemDependencies) {
+	cfg := deps.Config.Arbitrage
+	if deps.PriceAggregator == nil {
+		log.Println("⚠️  Rilevamento arbitraggi disabilitato: richiede PRICE_AGGREGATOR_ENABLED=true")
+		return
+	}
+
+	depth := book.NewStore()
+	for _, symbol := range deps.Config.Prices.Symbols {
+		depth.Run(ctx, book.NewBybitLinearOrderBookStreamer(), symbol, cfg.BookDepth)
+	}
+
+	cost := arbitrage.NewCostModel(arbitrage.CostConfig{
+		TakerFees:         cfg.TakerFees,
+		DefaultTakerFee:   cfg.DefaultTakerFee,
+		Fees:              deps.Fees,
+		OrderLatency:      cfg.OrderLatency,
+		DriftBpsPerSecond: cfg.DriftBpsPerSecond,
+		MaxBookAge:        cfg.MaxBookAge,
+	}, depth, nil)
+	detector := arbitrage.NewDetector(arbitrage.DetectorConfig{
+		Quantity:  cfg.Quantity,
+		MinNetBps: cfg.MinNetBps,
+	}, cost)
+
+	opportunities := make(chan *arbitrage.Opportunity, 16)
+	go detector.Run(ctx, deps.PriceAggregator.Subscribe(), opportunities)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case opp := <-opportunities:
+				log.Printf("💰 Arbitraggio %s: compra %.4f su %s a %.6f, vendi su %s a %.6f - lordo %.4f, costi %.4f, netto %.4f (%.1f bps)",
+					opp.Symbol, opp.Quantity, opp.BuyExchange, opp.Buy.FillPrice, opp.SellExchange, opp.Sell.FillPrice,
+					opp.GrossProfit, opp.Costs, opp.NetProfit, opp.NetBps)
+			}
+		}
+	}()
+
+	log.Printf("✅ Rilevamento arbitraggi avviato (quantità %.4f, netto minimo %.1f bps)", cfg.Quantity, cfg.MinNetBps)
+}
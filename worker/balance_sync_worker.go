@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/services"
+)
+
+// BalanceSyncWorker controlla periodicamente il margine disponibile di ogni venue
+// e segnala nei log quando è necessario un ribilanciamento manuale
+type BalanceSyncWorker struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	service *services.BalanceSyncService
+}
+
+// NewBalanceSyncWorker crea il worker usando i processor delle venue configurate
+// I trasferimenti richiedono la key di trading, quindi non viene usata la key di sola lettura
+func NewBalanceSyncWorker(deps *SystemDependencies) *BalanceSyncWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := deps.Config.BalanceSync
+
+	readers := make(map[string]orderprocessor.AccountReader, len(deps.OrderProcessors))
+	for venue, processor := range deps.OrderProcessors {
+		readers[venue] = processor
+	}
+
+	return &BalanceSyncWorker{
+		ctx:     ctx,
+		cancel:  cancel,
+		service: services.NewBalanceSyncService(readers, cfg.Thresholds, cfg.Coin, cfg.AutoTopUp, cfg.TopUpBuffer),
+	}
+}
+
+// ExecuteTradingCycle esegue un controllo dei margini
+func (w *BalanceSyncWorker) ExecuteTradingCycle() {
+	ctx, cancel := context.WithTimeout(w.ctx, time.Minute)
+	defer cancel()
+
+	report, err := w.service.Sync(ctx)
+	if err != nil {
+		log.Printf("❌ Errore controllo margini: %v", err)
+		return
+	}
+
+	for _, venue := range report.Venues {
+		if venue.Err != nil {
+			log.Printf("❌ Margine %s: %v", venue.Venue, venue.Err)
+		}
+		if venue.TopUp != nil {
+			log.Printf("✅ %s rifornito con %.2f %s dal funding account (transfer %s, %s)",
+				venue.Venue, venue.TopUp.Request.Amount, report.Coin, venue.TopUp.TransferID, venue.TopUp.Status)
+		}
+		if venue.Deficit > 0 {
+			log.Println(i18n.T("alert.margin_low", venue.Venue, venue.Available, report.Coin, venue.Threshold, venue.Deficit))
+		} else if venue.Err == nil {
+			log.Printf("Margine %s: %.2f %s (soglia %.2f)", venue.Venue, venue.Available, report.Coin, venue.Threshold)
+		}
+	}
+
+	for _, suggestion := range report.Suggestions {
+		log.Println(i18n.T("alert.transfer_suggested", suggestion.Amount, report.Coin, suggestion.From, suggestion.To))
+	}
+	if report.NeedsRebalance() && len(report.Suggestions) == 0 {
+		log.Println(i18n.T("alert.deposit_needed"))
+	}
+}
+
+// GetName implementa l'interfaccia Worker
+func (w *BalanceSyncWorker) GetName() string {
+	return "Balance Sync Worker"
+}
+
+// Stop ferma il worker
+func (w *BalanceSyncWorker) Stop() {
+	log.Println("Stopping Balance Sync Worker...")
+	w.cancel()
+}
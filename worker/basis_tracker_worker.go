@@ -0,0 +1,160 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/exchange"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/repositories"
+)
+
+// basisZone indica la posizione del basis rispetto alle soglie di alert
+type basisZone int
+
+const (
+	basisZoneNormal basisZone = iota
+	basisZoneAbove
+	basisZoneBelow
+)
+
+// BasisTrackerWorker registra il basis tra spot e perpetual di Bybit per i simboli configurati
+// e segnala quando esce dalle soglie; la serie salvata è la sorgente dati delle strategie di basis trading
+type BasisTrackerWorker struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	cfg         config.BasisTrackerConfig
+	perpPrices  exchange.Exchange
+	spotPrices  exchange.SpotPriceProvider
+	funding     exchange.FundingRateProvider // nil se l'exchange non espone il funding
+	repoManager repositories.RepositoryManager
+	zones       map[string]basisZone // Ultima zona per simbolo, per segnalare solo i cambi
+}
+
+// NewBasisTrackerWorker crea una nuova istanza del worker di monitoraggio del basis
+func NewBasisTrackerWorker(deps *SystemDependencies) *BasisTrackerWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	worker := &BasisTrackerWorker{
+		ctx:         ctx,
+		cancel:      cancel,
+		cfg:         deps.Config.Basis,
+		perpPrices:  deps.Exchange,
+		repoManager: deps.RepoManager,
+		zones:       make(map[string]basisZone),
+	}
+	worker.spotPrices, _ = deps.Exchange.(exchange.SpotPriceProvider)
+	worker.funding, _ = deps.Exchange.(exchange.FundingRateProvider)
+
+	return worker
+}
+
+// ExecuteTradingCycle registra il basis di ogni simbolo configurato
+func (w *BasisTrackerWorker) ExecuteTradingCycle() {
+	if w.spotPrices == nil {
+		log.Println("❌ Basis tracker: l'exchange non fornisce prezzi spot")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(w.ctx, time.Minute)
+	defer cancel()
+
+	for _, symbol := range w.cfg.Symbols {
+		if err := w.trackSymbol(ctx, symbol); err != nil {
+			log.Printf("❌ Basis tracker %s: %v", symbol, err)
+		}
+	}
+}
+
+// trackSymbol salva la rilevazione del basis di un simbolo e verifica le soglie
+func (w *BasisTrackerWorker) trackSymbol(ctx context.Context, symbol string) error {
+	spot, err := w.spotPrices.GetSpotPrice(ctx, symbol)
+	if err != nil {
+		return err
+	}
+	// Il WebSocket di Bybit vive quanto il contesto della prima richiesta: si usa quello del worker
+	perp, err := w.perpPrices.GetRealTimePrice(w.ctx, symbol)
+	if err != nil {
+		return err
+	}
+
+	spotPrice, perpPrice := midPrice(spot), midPrice(perp)
+	snapshot := &models.BasisSnapshot{
+		Symbol:       symbol,
+		SpotExchange: spot.Exchange,
+		PerpExchange: perp.Exchange,
+		SpotPrice:    spotPrice,
+		PerpPrice:    perpPrice,
+		Basis:        models.CalculateBasis(spotPrice, perpPrice),
+		TakenAt:      time.Now().UTC(),
+	}
+
+	// Il funding accompagna il basis: insieme determinano il rendimento di una posizione cash-and-carry
+	if w.funding != nil {
+		if rate, err := w.funding.GetFundingRate(ctx, symbol); err != nil {
+			log.Printf("⚠️  Basis tracker %s: funding non disponibile: %v", symbol, err)
+		} else {
+			snapshot.FundingRate = rate.Rate
+			snapshot.IntervalHours = rate.IntervalHours
+			snapshot.FundingAPR = rate.AnnualizedRate()
+		}
+	}
+
+	if err := w.repoManager.BasisSnapshot().Create(ctx, snapshot); err != nil {
+		return err
+	}
+
+	w.checkThresholds(snapshot)
+	return nil
+}
+
+// checkThresholds segnala l'uscita del basis dalle soglie e il suo rientro
+// Gli alert vengono emessi solo al cambio di zona per non ripeterli ad ogni ciclo
+func (w *BasisTrackerWorker) checkThresholds(snapshot *models.BasisSnapshot) {
+	zone := basisZoneNormal
+	switch {
+	case snapshot.Basis > w.cfg.UpperThreshold:
+		zone = basisZoneAbove
+	case snapshot.Basis < w.cfg.LowerThreshold:
+		zone = basisZoneBelow
+	}
+
+	previous := w.zones[snapshot.Symbol]
+	w.zones[snapshot.Symbol] = zone
+	if zone == previous {
+		log.Printf("Basis %s: %.4f%% (spot %.6f, perp %.6f, funding APR %.2f%%)",
+			snapshot.Symbol, snapshot.Basis*100, snapshot.SpotPrice, snapshot.PerpPrice, snapshot.FundingAPR*100)
+		return
+	}
+
+	switch zone {
+	case basisZoneAbove:
+		log.Println(i18n.T("alert.basis_above", snapshot.Symbol, snapshot.Basis*100, w.cfg.UpperThreshold*100, snapshot.SpotPrice, snapshot.PerpPrice))
+	case basisZoneBelow:
+		log.Println(i18n.T("alert.basis_below", snapshot.Symbol, snapshot.Basis*100, w.cfg.LowerThreshold*100, snapshot.SpotPrice, snapshot.PerpPrice))
+	default:
+		log.Println(i18n.T("alert.basis_back", snapshot.Symbol, snapshot.Basis*100))
+	}
+}
+
+// midPrice restituisce il prezzo medio tra bid e ask, o l'ultimo prezzo se il book non è disponibile
+func midPrice(data *models.RealTimePriceData) float64 {
+	if data.BidPrice > 0 && data.AskPrice > 0 {
+		return (data.BidPrice + data.AskPrice) / 2
+	}
+	return data.Price
+}
+
+// GetName implementa l'interfaccia Worker
+func (w *BasisTrackerWorker) GetName() string {
+	return "Basis Tracker Worker"
+}
+
+// Stop ferma il worker
+func (w *BasisTrackerWorker) Stop() {
+	log.Println("Stopping Basis Tracker Worker...")
+	w.cancel()
+}
@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/calendar"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+)
+
+// activeBlackout restituisce la finestra di blackout in corso; blackout può essere nil (calendario disabilitato)
+func activeBlackout(ctx context.Context, blackout *calendar.Blackout) (*calendar.Window, bool) {
+	if blackout == nil {
+		return nil, false
+	}
+	window, ok := blackout.Active(ctx, time.Now())
+	if ok {
+		log.Printf("⏸️  Blackout per %s alle %s (dalle %s alle %s): nessun nuovo ingresso",
+			window.Event.Name, window.Event.Time.Format(time.RFC3339),
+			window.Start.Format("15:04"), window.End.Format("15:04"))
+	}
+	return window, ok
+}
+
+// tightenStops avvicina lo stop loss delle posizioni aperte di un simbolo a stopPct dal mark price
+// Lo stop viene solo stretto: se quello attuale è già più vicino al prezzo resta invariato
+func tightenStops(ctx context.Context, processor orderprocessor.OrderProcessor, symbol string, stopPct float64) {
+	positions, err := processor.GetPositions(ctx, symbol)
+	if err != nil {
+		log.Printf("❌ Blackout: errore lettura posizioni %s: %v", symbol, err)
+		return
+	}
+
+	for _, position := range positions {
+		if !position.Parsed.IsActive() {
+			continue
+		}
+		stopLoss, ok := tightenedStopLoss(position, stopPct)
+		if !ok {
+			continue
+		}
+
+		resp, err := processor.UpdateOrder(ctx, orderprocessor.UpdateOrderParams{
+			Symbol:      position.Symbol,
+			StopLoss:    &stopLoss,
+			PositionIdx: position.PositionIdx,
+		})
+		if err != nil {
+			log.Printf("❌ Blackout: errore aggiornamento stop loss %s: %v", position.Symbol, err)
+			continue
+		}
+		if resp.ErrorCode != "0" {
+			log.Printf("❌ Blackout: stop loss %s rifiutato: %s %s", position.Symbol, resp.ErrorCode, resp.ErrorMessage)
+			continue
+		}
+		log.Printf("🛡️  Blackout: stop loss %s %s spostato da %s a %.6f", position.Symbol, position.Side, position.StopLoss, stopLoss)
+	}
+}
+
+// tightenedStopLoss calcola lo stop loss stretto di una posizione
+// Restituisce false se il mark price non è disponibile o lo stop attuale è già più stretto
+func tightenedStopLoss(position models.Position, stopPct float64) (float64, bool) {
+	parsed := position.Parsed
+	markPrice := parsed.MarkPrice
+	if markPrice <= 0 {
+		return 0, false
+	}
+	current := parsed.StopLoss
+
+	if parsed.IsLong() {
+		stopLoss := markPrice * (1 - stopPct)
+		if parsed.HasStopLoss() && current >= stopLoss {
+			return 0, false
+		}
+		return stopLoss, true
+	}
+
+	stopLoss := markPrice * (1 + stopPct)
+	if parsed.HasStopLoss() && current <= stopLoss {
+		return 0, false
+	}
+	return stopLoss, true
+}
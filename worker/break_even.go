@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+)
+
+// breakEvenRetryDelay è l'attesa dopo un aggiornamento dello stop a break-even fallito
+const breakEvenRetryDelay = time.Minute
+
+// followBreakEven riceve il PnL delle posizioni dal tracker e sposta lo stop loss della posizione DOGE
+// sul prezzo di ingresso quando il PnL raggiunge breakEvenPct. Termina alla chiusura del channel.
+// Sull'istanza in standby gli aggiornamenti vengono ignorati: gli stop sono gestiti dal leader
+func (w *DogeTradingSystemWorker) followBreakEven(updates <-chan *models.PositionPnL) {
+	for pnl := range updates {
+		if pnl.Symbol != "DOGEUSDT" || !w.trading.Load() || !pnl.IsBreakEven(w.breakEvenPct) {
+			continue
+		}
+		key := fmt.Sprintf("%s:%s:%g", pnl.Symbol, pnl.Side, pnl.EntryPrice)
+		if w.breakEven[key] || time.Now().Before(w.breakEvenRetry) {
+			continue
+		}
+		if w.moveStopToEntry(pnl) {
+			w.breakEven[key] = true
+		} else {
+			w.breakEvenRetry = time.Now().Add(breakEvenRetryDelay)
+		}
+	}
+}
+
+// moveStopToEntry sposta lo stop loss della posizione sul prezzo di ingresso, se quello attuale manca o ne è più lontano
+// Restituisce false se la posizione non è stata letta o l'aggiornamento è fallito
+func (w *DogeTradingSystemWorker) moveStopToEntry(pnl *models.PositionPnL) bool {
+	ctx := w.base
+	positions, err := w.orderProcessor.GetPositions(ctx, pnl.Symbol)
+	if err != nil {
+		log.Printf("⚠️  Break-even: errore nel recupero della posizione %s: %v", pnl.Symbol, err)
+		return false
+	}
+
+	for _, position := range positions {
+		parsed := position.Parsed
+		if parsed.Side != pnl.Side || !parsed.IsActive() {
+			continue
+		}
+		stopLoss := parsed.EntryPrice
+		if parsed.HasStopLoss() && (parsed.IsLong() && parsed.StopLoss >= stopLoss || parsed.IsShort() && parsed.StopLoss <= stopLoss) {
+			return true
+		}
+
+		resp, err := w.orderProcessor.UpdateOrder(ctx, orderprocessor.UpdateOrderParams{
+			Symbol:      pnl.Symbol,
+			StopLoss:    &stopLoss,
+			PositionIdx: position.PositionIdx,
+		})
+		if err != nil {
+			log.Printf("❌ Break-even: errore aggiornamento stop loss %s: %v", pnl.Symbol, err)
+			return false
+		}
+		if !resp.IsSuccess() {
+			log.Printf("❌ Break-even: stop loss %s rifiutato - %s (codice: %s)", pnl.Symbol, resp.ErrorMessage, resp.ErrorCode)
+			return false
+		}
+		log.Printf("🛡️  Break-even: PnL %s %s al %.2f%%, stop loss spostato da %s all'ingresso %.6f",
+			pnl.Symbol, pnl.Side, pnl.UnrealisedPnlPct*100, position.StopLoss, stopLoss)
+		return true
+	}
+	// La posizione è stata chiusa dopo l'ultimo ricalcolo del PnL
+	return true
+}
@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"fmt"
+	"time"
+
+	"cross-exchange-arbitrage/models"
+)
+
+// candleCloseSchedule esegue il worker subito dopo la chiusura di ogni candela del timeframe, con un piccolo ritardo
+// perché l'exchange renda disponibile la candela chiusa. A differenza di una schedule cron fissa segue i confini
+// delle candele dell'exchange (in UTC): le giornaliere a mezzanotte, le settimanali il lunedì, le mensili il primo del mese
+type candleCloseSchedule struct {
+	timeframe models.Timeframe
+	delay     time.Duration
+}
+
+// newCandleCloseSchedule crea la schedule per il timeframe indicato
+func newCandleCloseSchedule(timeframe models.Timeframe, delay time.Duration) (candleCloseSchedule, error) {
+	if timeframe.Duration() <= 0 && timeframe != models.Timeframe1M {
+		return candleCloseSchedule{}, fmt.Errorf("timeframe %q non supportato dallo scheduler alla chiusura delle candele", timeframe)
+	}
+	if delay < 0 {
+		return candleCloseSchedule{}, fmt.Errorf("il ritardo dopo la chiusura della candela non può essere negativo (%v)", delay)
+	}
+	return candleCloseSchedule{timeframe: timeframe, delay: delay}, nil
+}
+
+// Next implementa cron.Schedule: la prima chiusura di candela, più il ritardo, successiva a t
+func (s candleCloseSchedule) Next(t time.Time) time.Time {
+	return s.closeAfter(t.UTC().Add(-s.delay)).Add(s.delay).In(t.Location())
+}
+
+// closeAfter restituisce la prima chiusura di candela strettamente successiva a t (in UTC)
+// Truncate lavora sul tempo trascorso dall'1 gennaio dell'anno 1, un lunedì a mezzanotte UTC: i timeframe fino al
+// giornaliero restano allineati alla mezzanotte e il settimanale al lunedì, come le candele dell'exchange
+func (s candleCloseSchedule) closeAfter(t time.Time) time.Time {
+	if s.timeframe == models.Timeframe1M {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	}
+	duration := s.timeframe.Duration()
+	return t.Truncate(duration).Add(duration)
+}
+
+// String descrive la schedule nello stato dei worker
+func (s candleCloseSchedule) String() string {
+	return fmt.Sprintf("candle close, timeframe %s, +%v", s.timeframe, s.delay)
+}
@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/datasource"
+	"cross-exchange-arbitrage/repositories"
+
+	"gorm.io/gorm"
+)
+
+// DataIngestionWorker importa le rilevazioni delle sorgenti dati esterne configurate
+// Ad ogni ciclo scarica solo i valori successivi all'ultimo salvato; al primo avvio recupera lo storico
+type DataIngestionWorker struct {
+	ctx          context.Context
+	cancel       context.CancelFunc
+	sources      []datasource.Source
+	backfillDays int
+	repoManager  repositories.RepositoryManager
+}
+
+// NewDataIngestionWorker crea il worker con le sorgenti indicate in configurazione
+// Le sorgenti sconosciute vengono segnalate e ignorate
+func NewDataIngestionWorker(deps *SystemDependencies) *DataIngestionWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	worker := &DataIngestionWorker{
+		ctx:          ctx,
+		cancel:       cancel,
+		backfillDays: deps.Config.DataSources.BackfillDays,
+		repoManager:  deps.RepoManager,
+	}
+	for _, name := range deps.Config.DataSources.Sources {
+		source, err := datasource.New(name)
+		if err != nil {
+			log.Printf("❌ Data ingestion: %v", err)
+			continue
+		}
+		worker.sources = append(worker.sources, source)
+	}
+
+	return worker
+}
+
+// ExecuteTradingCycle importa le nuove rilevazioni di ogni sorgente
+func (w *DataIngestionWorker) ExecuteTradingCycle() {
+	ctx, cancel := context.WithTimeout(w.ctx, 2*time.Minute)
+	defer cancel()
+
+	for _, source := range w.sources {
+		if err := w.ingest(ctx, source); err != nil {
+			log.Printf("❌ Data ingestion %s: %v", source.Name(), err)
+		}
+	}
+}
+
+// ingest scarica e salva le rilevazioni di una sorgente successive all'ultima salvata
+func (w *DataIngestionWorker) ingest(ctx context.Context, source datasource.Source) error {
+	since := time.Now().UTC().AddDate(0, 0, -w.backfillDays)
+	latest, err := w.repoManager.DataPoint().GetLatest(ctx, source.Name())
+	switch {
+	case err == nil:
+		since = latest.ObservedAt
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+
+	points, err := source.Fetch(ctx, since)
+	if err != nil {
+		return err
+	}
+	if err := w.repoManager.DataPoint().CreateBatch(ctx, points); err != nil {
+		return err
+	}
+
+	if len(points) > 0 {
+		log.Printf("Data ingestion %s: %d nuove rilevazioni", source.Name(), len(points))
+	}
+	return nil
+}
+
+// GetName implementa l'interfaccia Worker
+func (w *DataIngestionWorker) GetName() string {
+	return "Data Ingestion Worker"
+}
+
+// Stop ferma il worker
+func (w *DataIngestionWorker) Stop() {
+	log.Println("Stopping Data Ingestion Worker...")
+	w.cancel()
+}
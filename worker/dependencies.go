@@ -0,0 +1,743 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"cross-exchange-arbitrage/backtest"
+	"cross-exchange-arbitrage/book"
+	"cross-exchange-arbitrage/cache"
+	"cross-exchange-arbitrage/calendar"
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/dataquality"
+	"cross-exchange-arbitrage/errorreport"
+	"cross-exchange-arbitrage/events"
+	"cross-exchange-arbitrage/exchange"
+	"cross-exchange-arbitrage/execution"
+	"cross-exchange-arbitrage/features"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/outage"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/risk"
+	"cross-exchange-arbitrage/scoring"
+	"cross-exchange-arbitrage/services"
+	"cross-exchange-arbitrage/simrand"
+	"cross-exchange-arbitrage/strategy"
+
+	"gorm.io/gorm"
+)
+
+// SystemDependencies contiene le dipendenze condivise tra i worker e le REST API
+// Vengono create una sola volta all'avvio in modo che database, cache e servizi siano unici
+type SystemDependencies struct {
+	Config         *config.Config
+	DB             *gorm.DB
+	RepoManager    repositories.RepositoryManager
+	OrderService   *services.OrderService
+	ReportService  *services.ReportService
+	Exchange       exchange.Exchange
+	OrderProcessor orderprocessor.OrderProcessor // nil se le credenziali non sono configurate
+	AccountReader  orderprocessor.AccountReader  // Letture per analytics/reportistica; nil senza credenziali
+	CashFlows      orderprocessor.CashFlowReader // Depositi e prelievi dell'account; nil in paper trading o senza credenziali
+
+	// Exchanges e OrderProcessors indicizzano tutte le venue configurate per nome (bybit, kraken, binance)
+	// Usati dal motore di arbitraggio e dalle strategie che operano su più exchange
+	Exchanges       map[string]exchange.Exchange
+	OrderProcessors map[string]orderprocessor.OrderProcessor
+
+	// SubAccounts contiene gli order processor Bybit dei sub-account dedicati, indicizzati per strategia
+	// Vuoto se nessuna strategia ha un sub-account configurato (vedi StrategyProcessor)
+	SubAccounts map[string]orderprocessor.OrderProcessor
+
+	// PriceAggregator consolida il miglior bid/ask tra le venue; nil se disabilitato
+	PriceAggregator *services.PriceAggregator
+
+	// Blackout indica le finestre attorno agli eventi macro in cui non aprire posizioni; nil se disabilitato
+	Blackout *calendar.Blackout
+
+	// Scorer valuta i segnali con un modello addestrato esternamente; nil se disabilitato
+	Scorer scoring.SignalScorer
+
+	// ConfigVersion è la versione della configurazione di strategia registrata all'avvio
+	ConfigVersion *models.ConfigVersion
+
+	// Jobs è la coda persistente delle azioni differite (es. cancellazione degli ordini non eseguiti)
+	Jobs *services.JobQueue
+
+	// Cache di prezzi, saldi e posizioni condivisa tra REST API e worker; nil se disabilitata
+	Cache cache.Store
+
+	// Locker assegna i lease del lock distribuito e dell'elezione del leader; nil se il lock è disabilitato
+	Locker Locker
+
+	// Events pubblica gli eventi di trading su NATS o Kafka; nil se disabilitato
+	Events *events.Emitter
+
+	// Errors invia a Sentry i panic dei worker e gli errori delle REST API; nil se disabilitato
+	Errors *errorreport.Reporter
+
+	// Risk applica i limiti di rischio globali (es. simboli ammessi) prima di ogni nuova posizione
+	Risk *risk.Manager
+
+	// Budgets assegna a ogni worker una quota virtuale del saldo, aggiornata dal PnL realizzato
+	Budgets *services.BudgetService
+
+	// Sizer sceglie il capitale da cui dimensionare gli ordini: corrente (compound) o base (fixed)
+	Sizer *risk.Sizer
+
+	// Levels calcola i livelli chiave (giorno e settimana precedenti, aperture) dalla cache delle candele
+	Levels *services.LevelsService
+
+	// OrderFlow calcola il delta tra acquisti e vendite a mercato dai trade pubblici di Bybit; nil se disabilitato
+	OrderFlow *book.OrderFlow
+
+	// Liquidations raggruppa in cluster le liquidazioni recenti ricevute da Bybit; nil se disabilitato
+	Liquidations *book.LiquidationFeed
+
+	// PositionPnL ricalcola il PnL non realizzato delle posizioni aperte sul mark price dello stream di Bybit; nil se disabilitato
+	PositionPnL *services.PositionPnLTracker
+
+	// Regimes conserva il regime di mercato di ogni simbolo e gli ingressi attivi in ciascun regime; nil se disabilitato
+	Regimes *strategy.RegimeTracker
+
+	// Latency controlla la latenza tra segnale e conferma degli ordini; nil se lo SLO è disattivato
+	Latency *LatencySLO
+
+	// Quality misura la qualità delle candele recuperate da Bybit e segnala quando peggiora; nil se disabilitato
+	Quality *dataquality.CandleMonitor
+
+	// Outage tiene lo stato di raggiungibilità degli exchange e applica la policy della modalità degradata
+	Outage *outage.Monitor
+
+	// Features decide quali funzionalità rischiose sono attive su questa istanza, modificabili a runtime
+	Features *features.Flags
+
+	// Throttle limita gli ordini per simbolo e per account condiviso da tutte le strategie; nil se disabilitato
+	Throttle *orderprocessor.OrderThrottle
+
+	// Slicer fraziona gli ingressi troppo grandi per la liquidità al miglior prezzo; nil se disabilitato
+	Slicer *services.OrderSlicer
+
+	// Spread blocca o rinvia gli ordini a mercato di apertura quando lo spread è troppo ampio; nil se disabilitato
+	Spread *risk.SpreadGuard
+
+	// Fees conserva configurazione degli account e commissioni effettive delle venue lette all'avvio
+	Fees *services.FeeService
+
+	// Executor traduce gli intent delle strategie (quantità e urgenza) in ordini Bybit; nil senza OrderProcessor
+	Executor *execution.Executor
+
+	// Simulation è la sorgente casuale con il seme della run, da cui ogni componente deriva la propria sequenza
+	Simulation *simrand.Source
+}
+
+// NewSystemDependencies inizializza database, repository e servizi condivisi
+func NewSystemDependencies(cfg *config.Config) (*SystemDependencies, error) {
+	log.Println("Inizializzando database...")
+	db, err := database.InitializeDatabaseWithData(database.DefaultConfig())
+	if err != nil {
+		return nil, fmt.Errorf("impossibile inizializzare database: %w", err)
+	}
+
+	repoManager := repositories.NewRepositoryManager(db)
+
+	reporter, err := newErrorReporter(cfg.Errors)
+	if err != nil {
+		return nil, err
+	}
+	// Latenza del paper trading, guasti simulati e jitter dei worker estraggono da sequenze separate dello stesso seme
+	simulation := simrand.New(cfg.Simulation.Seed)
+	if cfg.Paper.Enabled || cfg.Chaos.Enabled || cfg.Workers.Jitter > 0 {
+		log.Printf("🎲 Seme della simulazione: %d (SIMULATION_SEED=%d per ripetere la run)", simulation.Seed(), simulation.Seed())
+	}
+	monitor, err := newOutageMonitor(cfg, reporter, simulation.Derive("chaos"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Carica in cache gli stati ordine, usati ad ogni mappatura degli stati Bybit
+	if _, err := repoManager.OrderStatus().GetAll(context.Background()); err != nil {
+		return nil, fmt.Errorf("impossibile caricare gli stati ordine: %w", err)
+	}
+	bybitExchange := exchange.NewBybitExchange(false) // false = usa produzione, true = usa testnet
+	var quality *dataquality.CandleMonitor
+	if cfg.Quality.Enabled {
+		quality, err = dataquality.NewCandleMonitor(dataquality.Thresholds{
+			Window:        cfg.Quality.Window,
+			MaxGaps:       cfg.Quality.MaxGaps,
+			MaxZeroVolume: cfg.Quality.MaxZeroVolume,
+			MaxErrorRate:  cfg.Quality.MaxErrorRate,
+		}, reporter)
+		if err != nil {
+			return nil, fmt.Errorf("impossibile configurare la qualità delle candele: %w", err)
+		}
+		bybitExchange.SetCandleObserver(quality)
+	}
+
+	// Crea il processor per gli ordini con la key di trading
+	var orderProcessor orderprocessor.OrderProcessor
+	if cfg.Bybit.HasCredentials() {
+		processor, err := newBybitProcessor(cfg.Bybit.BybitCredentials)
+		if err != nil {
+			return nil, fmt.Errorf("impossibile configurare la key di trading: %w", err)
+		}
+		orderProcessor = processor
+	} else {
+		log.Println("ATTENZIONE: Credenziali API Bybit non configurate, ordini non funzioneranno")
+	}
+
+	// In paper trading gli ordini sono simulati sulle candele reali al posto della key di trading
+	if cfg.Paper.Enabled {
+		paper, err := orderprocessor.NewPaperOrderProcessor(bybitExchange, orderprocessor.PaperConfig{
+			InitialBalance: cfg.Paper.InitialBalance,
+			Liquidity:      backtest.LiquidityModel{MaxVolumeFraction: cfg.Paper.MaxVolumeFraction},
+			FillPriority:   backtest.FillPriority(cfg.Paper.FillPriority),
+			Latency: orderprocessor.PaperLatency{
+				Distribution: cfg.Paper.LatencyDistribution,
+				Mean:         cfg.Paper.Latency,
+				Jitter:       cfg.Paper.LatencyJitter,
+			},
+			SlippageBps: cfg.Paper.SlippageBps,
+			Rand:        simulation.Derive("paper"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("impossibile configurare il paper trading: %w", err)
+		}
+		orderProcessor = paper
+		log.Printf("📝 Paper trading attivo: saldo iniziale %.2f USDT, fill limitati al %.0f%% del volume per candela",
+			cfg.Paper.InitialBalance, cfg.Paper.MaxVolumeFraction*100)
+		if cfg.Paper.Latency > 0 || cfg.Paper.SlippageBps > 0 {
+			log.Printf("📝 Paper trading: latenza %s %v ± %v, slippage %.1f bps", cfg.Paper.LatencyDistribution,
+				cfg.Paper.Latency, cfg.Paper.LatencyJitter, cfg.Paper.SlippageBps)
+		}
+	}
+
+	// Analytics e reportistica leggono l'account con la key di sola lettura, se configurata (in paper trading il saldo simulato)
+	var accountReader orderprocessor.AccountReader
+	if cfg.Paper.Enabled {
+		accountReader = orderProcessor
+	} else if cfg.Bybit.ReadOnly.HasCredentials() {
+		reader, err := newBybitProcessor(cfg.Bybit.ReadOnly)
+		if err != nil {
+			return nil, fmt.Errorf("impossibile configurare la key di sola lettura: %w", err)
+		}
+		accountReader = reader
+	} else if orderProcessor != nil {
+		log.Println("ATTENZIONE: key di sola lettura non configurata, la reportistica usa la key di trading")
+		accountReader = orderProcessor
+	}
+
+	// Depositi e prelievi sono letti dalla stessa key, fuori dalla cache
+	cashFlows, _ := accountReader.(orderprocessor.CashFlowReader)
+
+	// Con la cache, saldi e posizioni letti da reportistica e REST API arrivano all'exchange solo alla scadenza del TTL
+	// I worker di trading usano l'order processor, che legge sempre dati aggiornati
+	store, err := newCacheStore(cfg.Cache)
+	if err != nil {
+		return nil, err
+	}
+	locker, err := newLocker(cfg, repoManager)
+	if err != nil {
+		return nil, err
+	}
+	if store != nil && accountReader != nil {
+		positions, _ := accountReader.(orderprocessor.PositionReader)
+		venue := "bybit"
+		if cfg.Paper.Enabled {
+			venue = "paper"
+		}
+		accountReader = orderprocessor.NewCachedAccountReader(accountReader, positions, store, venue, cfg.Cache.BalanceTTL, cfg.Cache.PositionsTTL)
+	}
+
+	// Registra le venue disponibili: i dati di mercato sono pubblici, gli ordini richiedono le credenziali
+	exchanges := map[string]exchange.Exchange{
+		"bybit":  bybitExchange,
+		"kraken": exchange.NewKrakenExchange(cfg.Kraken.APIKey, cfg.Kraken.SecretKey, cfg.Kraken.Demo),
+	}
+	orderProcessors := make(map[string]orderprocessor.OrderProcessor)
+	if orderProcessor != nil {
+		orderProcessors["bybit"] = orderProcessor
+	}
+	if cfg.Kraken.HasCredentials() {
+		orderProcessors["kraken"] = orderprocessor.NewKrakenOrderProcessor(cfg.Kraken.APIKey, cfg.Kraken.SecretKey, cfg.Kraken.Demo)
+	}
+	if cfg.Binance.HasCredentials() {
+		orderProcessors["binance"] = orderprocessor.NewBinanceOrderProcessor(cfg.Binance.APIKey, cfg.Binance.SecretKey, cfg.Binance.Testnet)
+	}
+
+	// Le strategie con un sub-account dedicato inviano gli ordini Bybit con la key del sub-account
+	// In paper trading gli ordini restano simulati sull'unico account virtuale
+	subAccounts := make(map[string]orderprocessor.OrderProcessor)
+	if !cfg.Paper.Enabled {
+		for strategy, creds := range cfg.Bybit.SubAccounts {
+			processor, err := newBybitProcessor(creds)
+			if err != nil {
+				return nil, fmt.Errorf("impossibile configurare la key del sub-account %s: %w", strategy, err)
+			}
+			var subProcessor orderprocessor.OrderProcessor = processor
+			if cfg.Observer.Enabled {
+				subProcessor = orderprocessor.NewObserverOrderProcessor("bybit", processor)
+			}
+			subAccounts[strategy] = subProcessor
+			log.Printf("👤 La strategia %s opera nel proprio sub-account Bybit", strategy)
+		}
+	}
+
+	// In modalità observer nessun processor invia ordini: le letture restano sulle key configurate,
+	// anche solo su quella di sola lettura se la key di trading non è ancora stata creata
+	if cfg.Observer.Enabled {
+		reader := orderProcessor
+		if reader == nil && cfg.Bybit.ReadOnly.HasCredentials() {
+			readOnly, err := newBybitProcessor(cfg.Bybit.ReadOnly)
+			if err != nil {
+				return nil, fmt.Errorf("impossibile configurare la key di sola lettura: %w", err)
+			}
+			reader = readOnly
+		}
+		for venue, processor := range orderProcessors {
+			orderProcessors[venue] = orderprocessor.NewObserverOrderProcessor(venue, processor)
+		}
+		orderProcessor = orderprocessor.NewObserverOrderProcessor("bybit", reader)
+		orderProcessors["bybit"] = orderProcessor
+		log.Println("👁️  Modalità observer attiva: dati, strategie e reportistica girano, nessun ordine viene inviato")
+	}
+
+	var priceAggregator *services.PriceAggregator
+	if cfg.Prices.Enabled {
+		priceAggregator = services.NewPriceAggregator(exchanges, cfg.Prices.Symbols, cfg.Prices.Interval, cfg.Prices.MaxAge)
+		if store != nil {
+			priceAggregator.SetCache(store, cfg.Cache.PriceTTL)
+		}
+	}
+
+	var blackout *calendar.Blackout
+	if cfg.Calendar.Enabled {
+		var provider calendar.Provider
+		if cfg.Calendar.Provider == "http" {
+			provider = calendar.NewHTTPProvider(cfg.Calendar.URL)
+		} else {
+			provider = calendar.NewFileProvider(cfg.Calendar.File)
+		}
+		blackout = calendar.NewBlackout(provider, calendar.BlackoutConfig{
+			Before:    cfg.Calendar.Before,
+			After:     cfg.Calendar.After,
+			Keywords:  cfg.Calendar.Keywords,
+			Countries: cfg.Calendar.Countries,
+			MinImpact: cfg.Calendar.MinImpact,
+			Refresh:   cfg.Calendar.Refresh,
+		})
+	}
+
+	var scorer scoring.SignalScorer
+	if cfg.Scorer.Enabled {
+		scorer, err = scoring.New(scoring.Config{
+			Type:     cfg.Scorer.Type,
+			URL:      cfg.Scorer.URL,
+			Token:    cfg.Scorer.Token,
+			Timeout:  cfg.Scorer.Timeout,
+			Model:    cfg.Scorer.ONNXModel,
+			Library:  cfg.Scorer.ONNXLibrary,
+			Input:    cfg.Scorer.ONNXInput,
+			Output:   cfg.Scorer.ONNXOutput,
+			Features: cfg.Scorer.Features,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("impossibile configurare lo scorer dei segnali: %w", err)
+		}
+	}
+
+	// Registra la configurazione di strategia: i nuovi ordini riportano la versione attiva
+	snapshot, err := cfg.Snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("impossibile calcolare la versione della configurazione: %w", err)
+	}
+	configVersion, err := services.NewConfigVersionService(repoManager).Record(context.Background(), snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("impossibile registrare la versione della configurazione: %w", err)
+	}
+	emitter, err := newEventEmitter(cfg.Events)
+	if err != nil {
+		return nil, err
+	}
+	orderService := services.NewOrderService(repoManager)
+	orderService.SetConfigVersion(configVersion.Hash)
+	orderService.SetEvents(emitter)
+	budgets := services.NewBudgetService(repoManager, cfg.Risk.WorkerBudgets)
+	orderService.SetBudgets(budgets)
+	sizer, err := risk.NewSizer(risk.SizingParams{
+		Mode:        cfg.Risk.SizingMode,
+		BaseCapital: cfg.Risk.BaseCapital,
+		Reset:       cfg.Risk.BaseCapitalReset,
+		ResetChange: cfg.Risk.BaseCapitalChange,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("impossibile configurare il dimensionamento degli ordini: %w", err)
+	}
+	levels, err := services.NewLevelsService(repoManager, cfg.Reporting.SessionOpen)
+	if err != nil {
+		return nil, fmt.Errorf("impossibile configurare i livelli chiave: %w", err)
+	}
+	var orderFlow *book.OrderFlow
+	if cfg.OrderFlow.Enabled {
+		orderFlow = book.NewOrderFlow(cfg.OrderFlow.Window)
+	}
+	var liquidations *book.LiquidationFeed
+	if cfg.Liquidation.Enabled {
+		liquidations = book.NewLiquidationFeed(cfg.Liquidation.Retention, cfg.Liquidation.Gap)
+	}
+	var positionPnL *services.PositionPnLTracker
+	if cfg.PnL.Enabled {
+		if orderProcessor == nil {
+			log.Println("⚠️  PnL in tempo reale delle posizioni disabilitato: nessun order processor configurato")
+		} else {
+			positionPnL = services.NewPositionPnLTracker(orderProcessor, repoManager, cfg.PnL.Symbols, cfg.PnL.RefreshInterval, cfg.PnL.SnapshotInterval)
+		}
+	}
+	var regimes *strategy.RegimeTracker
+	if cfg.Regime.Enabled {
+		regimes, err = strategy.NewRegimeTracker(map[strategy.Regime][]string{
+			strategy.RegimeTrending: cfg.Regime.Trending,
+			strategy.RegimeRanging:  cfg.Regime.Ranging,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("impossibile configurare i regimi di mercato: %w", err)
+		}
+	}
+	var latency *LatencySLO
+	if cfg.Latency.Target > 0 {
+		latency = NewLatencySLO(cfg.Latency.Target, cfg.Latency.Breaches, reporter)
+	}
+	flags, err := features.New(cfg.Features.Rules, cfg.Features.Environment, cfg.Features.Account)
+	if err != nil {
+		return nil, fmt.Errorf("impossibile configurare i feature flag: %w", err)
+	}
+	for _, state := range flags.States() {
+		if !state.Enabled {
+			log.Printf("🚩 Feature flag %s disattivato (regola %q, ambiente %s, account %s)",
+				state.Name, state.Rule, cfg.Features.Environment, cfg.Features.Account)
+		}
+	}
+	var throttle *orderprocessor.OrderThrottle
+	if cfg.Throttle.Enabled {
+		throttle, err = orderprocessor.NewOrderThrottle(orderprocessor.ThrottleLimits{
+			SymbolPerMinute:  cfg.Throttle.SymbolPerMinute,
+			SymbolPerHour:    cfg.Throttle.SymbolPerHour,
+			AccountPerMinute: cfg.Throttle.AccountPerMinute,
+			AccountPerHour:   cfg.Throttle.AccountPerHour,
+		}, cfg.Throttle.Mode, cfg.Throttle.MaxWait)
+		if err != nil {
+			return nil, fmt.Errorf("impossibile configurare il limite degli ordini: %w", err)
+		}
+	}
+	var spread *risk.SpreadGuard
+	if cfg.Spread.Enabled {
+		spread, err = risk.NewSpreadGuard(cfg.Spread.MaxBps, cfg.Spread.Mode, cfg.Spread.MaxWait)
+		if err != nil {
+			return nil, fmt.Errorf("impossibile configurare il controllo dello spread: %w", err)
+		}
+	}
+	var slicer *services.OrderSlicer
+	if cfg.Slicing.Enabled && orderProcessor != nil {
+		// PlaceLongOrder e PlaceShortOrder di Bybit arrotondano la quantità a unità intere
+		slicer = services.NewOrderSlicer(repoManager, orderService, orderProcessor, bybitExchange, throttle, spread, services.OrderSlicerParams{
+			Method:          models.SliceMethod(cfg.Slicing.Method),
+			MaxBookFraction: cfg.Slicing.MaxBookFraction,
+			Slices:          cfg.Slicing.Slices,
+			Interval:        cfg.Slicing.Interval,
+			QtyStep:         1,
+		})
+	}
+	// Configurazione degli account e commissioni effettive, lette all'avvio e salvate nel database
+	fees, err := services.NewFeeService(context.Background(), repoManager)
+	if err != nil {
+		return nil, fmt.Errorf("impossibile caricare le commissioni salvate: %w", err)
+	}
+	refreshFees(cfg, fees, orderProcessors)
+
+	riskManager := newRiskManager(cfg.Risk, repoManager, orderProcessors)
+	// In freeze nessuna strategia apre nuove posizioni finché un exchange non è raggiungibile
+	riskManager.AddEntryGate(monitor.CheckEntry)
+	var executor *execution.Executor
+	if orderProcessor != nil {
+		executor, err = newExecutor(cfg, orderProcessor, bybitExchange, throttle, spread, riskManager)
+		if err != nil {
+			return nil, fmt.Errorf("impossibile configurare lo strato di esecuzione: %w", err)
+		}
+	}
+	log.Printf("Configurazione di strategia: versione %s", configVersion.ShortHash())
+
+	return &SystemDependencies{
+		Config:         cfg,
+		DB:             db,
+		RepoManager:    repoManager,
+		OrderService:   orderService,
+		ReportService:  services.NewReportService(repoManager, bybitExchange, cfg.Reporting.RiskFreeRate),
+		Exchange:       bybitExchange,
+		OrderProcessor: orderProcessor,
+		AccountReader:  accountReader,
+		CashFlows:      cashFlows,
+
+		Exchanges:       exchanges,
+		OrderProcessors: orderProcessors,
+		SubAccounts:     subAccounts,
+		PriceAggregator: priceAggregator,
+		Blackout:        blackout,
+		Scorer:          scorer,
+		ConfigVersion:   configVersion,
+		Jobs:            services.NewJobQueue(repoManager, cfg.Jobs.RetryDelay),
+		Cache:           store,
+		Locker:          locker,
+		Events:          emitter,
+		Errors:          reporter,
+		Risk:            riskManager,
+		Budgets:         budgets,
+		Sizer:           sizer,
+		Levels:          levels,
+		OrderFlow:       orderFlow,
+		Liquidations:    liquidations,
+		PositionPnL:     positionPnL,
+		Regimes:         regimes,
+		Latency:         latency,
+		Quality:         quality,
+		Outage:          monitor,
+		Features:        flags,
+		Throttle:        throttle,
+		Slicer:          slicer,
+		Spread:          spread,
+		Fees:            fees,
+		Executor:        executor,
+		Simulation:      simulation,
+	}, nil
+}
+
+// refreshFees legge configurazione dell'account e commissioni dei simboli negoziati da ogni venue con credenziali
+// In paper trading Bybit è simulato e restano in uso le commissioni salvate o configurate
+func refreshFees(cfg *config.Config, fees *services.FeeService, processors map[string]orderprocessor.OrderProcessor) {
+	symbols := []string{"DOGEUSDT"}
+	for _, symbol := range cfg.Prices.Symbols {
+		if !slices.Contains(symbols, strings.ToUpper(symbol)) {
+			symbols = append(symbols, strings.ToUpper(symbol))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for venue, processor := range processors {
+		if venue == "bybit" && cfg.Paper.Enabled {
+			continue
+		}
+		fees.Refresh(ctx, venue, processor, symbols)
+	}
+}
+
+// newExecutor crea lo strato di esecuzione degli ordini Bybit con gli algoritmi configurati per ogni urgenza
+// Ogni ordine a mercato viene ritentato con la policy degli ingressi, sospesa se il risk manager blocca nuove posizioni
+func newExecutor(cfg *config.Config, processor orderprocessor.OrderProcessor, quotes execution.QuoteSource,
+	throttle *orderprocessor.OrderThrottle, spread *risk.SpreadGuard, riskManager *risk.Manager) (*execution.Executor, error) {
+	algos := make(map[execution.Urgency]string)
+	for urgency, algo := range cfg.Execution.Algos {
+		algos[execution.Urgency(urgency)] = algo
+	}
+
+	// PlaceLongOrder e PlaceShortOrder di Bybit arrotondano la quantità a unità intere
+	return execution.NewExecutor(processor, quotes, throttle, execution.ExecutorParams{
+		Venue: "bybit",
+		Algos: algos,
+		Retry: orderprocessor.RetryPolicy{
+			Attempts: cfg.Retry.Attempts,
+			Backoff:  cfg.Retry.Backoff,
+			Allow:    riskManager.CheckEntry,
+		},
+		QtyStep: 1,
+		Spread:  spread,
+	},
+		execution.TWAP{Slices: cfg.Execution.TWAPSlices, Duration: cfg.Execution.TWAPDuration},
+		execution.PassiveThenCross{Timeout: cfg.Execution.PassiveTimeout, Poll: time.Second},
+		execution.Peg{Duration: cfg.Execution.PegDuration, Repeg: cfg.Execution.PegInterval},
+	)
+}
+
+// newOutageMonitor crea il monitor della modalità degradata e lo installa sui client HTTP degli exchange,
+// insieme ai guasti simulati se CHAOS_ENABLED è attivo
+func newOutageMonitor(cfg *config.Config, reporter *errorreport.Reporter, chaosRand *simrand.Source) (*outage.Monitor, error) {
+	monitor, err := outage.NewMonitor(outage.Policy{
+		Action:     cfg.Degraded.Action,
+		Failures:   cfg.Degraded.Failures,
+		Recovery:   cfg.Degraded.Recovery,
+		RecvWindow: cfg.Degraded.RecvWindow,
+	}, reporter)
+	if err != nil {
+		return nil, fmt.Errorf("impossibile configurare la modalità degradata: %w", err)
+	}
+
+	var chaos *outage.Chaos
+	if cfg.Chaos.Enabled {
+		chaos = &outage.Chaos{
+			TimeoutRate: cfg.Chaos.TimeoutRate,
+			ErrorRate:   cfg.Chaos.ErrorRate,
+			Latency:     cfg.Chaos.Latency,
+			Venues:      cfg.Chaos.Venues,
+			Paths:       cfg.Chaos.Paths,
+			Rand:        chaosRand,
+		}
+		log.Printf("🧪 ATTENZIONE: guasti simulati attivi sulle chiamate agli exchange (timeout %.0f%%, errori 5xx %.0f%%, latenza %v, venue %v, path %v)",
+			chaos.TimeoutRate*100, chaos.ErrorRate*100, chaos.Latency, chaos.Venues, chaos.Paths)
+	}
+	if err := outage.Install(monitor, chaos); err != nil {
+		return nil, fmt.Errorf("impossibile configurare i guasti simulati: %w", err)
+	}
+
+	log.Printf("🛡️  Modalità degradata: %s dopo %d errori consecutivi, ripristino dopo %d richieste riuscite",
+		cfg.Degraded.Action, cfg.Degraded.Failures, cfg.Degraded.Recovery)
+	return monitor, nil
+}
+
+// newErrorReporter crea il reporter Sentry; nil se SENTRY_DSN non è configurato
+func newErrorReporter(cfg config.ErrorReportingConfig) (*errorreport.Reporter, error) {
+	if cfg.SentryDSN == "" {
+		return nil, nil
+	}
+	reporter, err := errorreport.NewSentryReporter(errorreport.Options{
+		DSN:         cfg.SentryDSN,
+		Environment: cfg.Environment,
+		Release:     cfg.Release,
+		SampleRate:  cfg.SampleRate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("impossibile configurare Sentry: %w", err)
+	}
+	log.Printf("🛰️  Panic dei worker ed errori delle REST API inviati a Sentry (ambiente %s)", cfg.Environment)
+	return reporter, nil
+}
+
+// newEventEmitter crea il publisher degli eventi di trading configurato; nil se disabilitato
+func newEventEmitter(cfg config.EventsConfig) (*events.Emitter, error) {
+	var publisher events.Publisher
+	switch cfg.Backend {
+	case "nats":
+		natsPublisher, err := events.NewNATSPublisher(cfg.NATSURL)
+		if err != nil {
+			return nil, fmt.Errorf("impossibile connettersi a NATS: %w", err)
+		}
+		publisher = natsPublisher
+	case "kafka":
+		publisher = events.NewKafkaRESTPublisher(cfg.KafkaRESTURL)
+	default:
+		return nil, nil
+	}
+
+	emitter := events.NewEmitter(publisher, cfg.TopicPrefix, cfg.Buffer)
+	log.Printf("📣 Eventi di trading pubblicati su %s (topic %s)", cfg.Backend, emitter.Topic("*"))
+	return emitter, nil
+}
+
+// newCacheStore crea la cache configurata; nil se disabilitata
+func newCacheStore(cfg config.CacheConfig) (cache.Store, error) {
+	switch cfg.Backend {
+	case "memory":
+		log.Println("🗃️  Cache in memoria attiva per prezzi, saldi e posizioni")
+		return cache.NewMemoryStore(), nil
+	case "redis":
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		store, err := cache.NewRedisStore(ctx, cache.RedisOptions{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+			Prefix:   cfg.Prefix,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("impossibile connettersi alla cache Redis: %w", err)
+		}
+		log.Printf("🗃️  Cache Redis attiva su %s per prezzi, saldi e posizioni", cfg.RedisAddr)
+		return store, nil
+	default:
+		return nil, nil
+	}
+}
+
+// newLocker crea il backend dei lease configurato; nil se il lock distribuito è disabilitato
+func newLocker(cfg *config.Config, repoManager repositories.RepositoryManager) (Locker, error) {
+	if !cfg.Lock.Enabled {
+		return nil, nil
+	}
+	if cfg.Lock.Backend != "redis" {
+		log.Println("🔒 Lease nella tabella distributed_locks: il lock coordina solo istanze sullo stesso host")
+		return repoManager.Lock(), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	locker, err := cache.NewRedisLocker(ctx, cache.RedisOptions{
+		Addr:     cfg.Cache.RedisAddr,
+		Password: cfg.Cache.RedisPassword,
+		DB:       cfg.Cache.RedisDB,
+		Prefix:   cfg.Cache.Prefix + "lock:",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("impossibile connettersi a Redis per il lock distribuito: %w", err)
+	}
+	log.Printf("🔒 Lease su Redis %s, condivisi tra istanze su host diversi", cfg.Cache.RedisAddr)
+	return locker, nil
+}
+
+// newBybitProcessor crea un processor Bybit che firma con le credenziali indicate (HMAC o RSA)
+func newBybitProcessor(creds config.BybitCredentials) (*orderprocessor.BybitOrderProcessor, error) {
+	signer, err := orderprocessor.NewSigner(creds.AuthType, creds.SecretKey, creds.RSAPrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("impossibile configurare la firma delle richieste Bybit: %w", err)
+	}
+	return orderprocessor.NewBybitOrderProcessorWithSigner(creds.APIKey, signer), nil
+}
+
+// StrategyProcessor restituisce l'order processor con cui una strategia opera su una venue:
+// su Bybit quello del sub-account dedicato, se configurato, altrimenti quello condiviso della venue
+func (d *SystemDependencies) StrategyProcessor(strategy, venue string) orderprocessor.OrderProcessor {
+	if venue == "bybit" {
+		if processor, ok := d.SubAccounts[strategy]; ok {
+			return processor
+		}
+	}
+	return d.OrderProcessors[venue]
+}
+
+// Close rilascia le risorse condivise
+func (d *SystemDependencies) Close() {
+	if closer, ok := d.Scorer.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("Errore chiusura scorer: %v", err)
+		}
+	}
+	if d.Events != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := d.Events.Close(ctx); err != nil {
+			log.Printf("Errore chiusura publisher eventi: %v", err)
+		}
+		cancel()
+	}
+	if !d.Errors.Flush(5 * time.Second) {
+		log.Println("⚠️  Alcuni errori non sono stati inviati a Sentry prima dell'arresto")
+	}
+	if d.Cache != nil {
+		if err := d.Cache.Close(); err != nil {
+			log.Printf("Errore chiusura cache: %v", err)
+		}
+	}
+	if closer, ok := d.Locker.(interface{ Close() error }); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("Errore chiusura lock distribuito: %v", err)
+		}
+	}
+	if d.DB != nil {
+		if err := database.Close(d.DB); err != nil {
+			log.Printf("Errore chiusura database: %v", err)
+		}
+	}
+}
@@ -2,80 +2,324 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math"
-	"os"
 	"slices"
+	"sync/atomic"
 	"time"
 
+	"cross-exchange-arbitrage/book"
+	"cross-exchange-arbitrage/calendar"
 	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/correlation"
 	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/events"
 	"cross-exchange-arbitrage/exchange"
+	"cross-exchange-arbitrage/execution"
+	"cross-exchange-arbitrage/features"
 	"cross-exchange-arbitrage/models"
 	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/preflight"
 	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/risk"
+	"cross-exchange-arbitrage/scoring"
 	"cross-exchange-arbitrage/services"
+	"cross-exchange-arbitrage/strategy"
+	"cross-exchange-arbitrage/taprocess"
 
-	"github.com/markcheno/go-talib"
 	"gorm.io/gorm"
 )
 
 // DogeTradingSystemWorker rappresenta il worker per il sistema di trading DOGE
 type DogeTradingSystemWorker struct {
-	ctx            context.Context
+	cycleContext   // Contesto del ciclo corrente, con l'identificativo di correlazione
 	cancel         context.CancelFunc
 	exchange       exchange.Exchange
 	orderProcessor orderprocessor.OrderProcessor
+	accountReader  orderprocessor.AccountReader  // Key di sola lettura per gli snapshot di reportistica
+	cashFlows      orderprocessor.CashFlowReader // Depositi e prelievi esclusi dai rendimenti; nil se non disponibili
 	db             *gorm.DB
+	repoManager    repositories.RepositoryManager
 	orderService   *services.OrderService
-	orderPlaced    bool // Flag per indicare se c'è un ordine già piazzato
+	reportService  *services.ReportService
+	snapshotSource models.SnapshotSource     // Live, o backtest in paper trading: l'equity simulata non entra nelle metriche live
+	snapshotRunID  string                    // Sessione di paper trading a cui appartengono gli snapshot; vuoto per il live
+	orderPlaced    bool                      // Flag per indicare se c'è un ordine già piazzato
+	blackout       *calendar.Blackout        // Finestre di blackout attorno agli eventi macro; nil se disabilitato
+	calendarCfg    config.CalendarConfig     // Gestione degli stop durante il blackout
+	sentiment      *strategy.SentimentFilter // Filtro sugli ingressi basato sul Fear & Greed Index; nil se disabilitato
+	sentimentAge   time.Duration             // Età massima del valore dell'indice usato dal filtro
+	trend          *strategy.TrendFilter     // Filtro sugli ingressi nella direzione del trend (EMA); nil se disabilitato
+	retest         *strategy.RetestTracker   // Ingresso sul retest del livello rotto invece che sulla rottura; nil se disabilitato
+	profiler       *strategy.VolumeProfiler  // Volume profile delle sessioni precedenti come supporto/resistenza; nil se disabilitato
+	profileDays    int                       // Sessioni giornaliere chiuse da cui calcolare il volume profile
+	profileRoom    float64                   // Distanza minima dal prossimo livello del volume profile nella direzione dell'ingresso
+	flow           *book.OrderFlow           // Delta dei trade pubblici a conferma delle rotture; nil se disabilitato
+	flowFilter     *strategy.OrderFlowFilter // Sbilanciamento minimo del delta nella direzione della rottura
+	liquidations   *book.LiquidationFeed     // Cluster di liquidazioni per ingressi contrarian e stop più stretti; nil se disabilitato
+	liqCfg         config.LiquidationConfig  // Soglie e uso dei cluster di liquidazioni
+	liqTrigger     time.Time                 // Fine dell'ultimo cluster che ha fatto scattare un ingresso contrarian
+	regimes        *strategy.RegimeTracker   // Regime di mercato del simbolo, che decide quali ingressi sono attivi; nil se disabilitato
+	anchors        *strategy.AnchorTracker   // Ancora del VWAP: la candela dell'ultima rottura di muro o supporto
+	avwapTrail     *strategy.AVWAPTrail      // Stop che segue il VWAP ancorato alla rottura; nil se disabilitato
+	scorer         scoring.SignalScorer      // Modello esterno che può scartare o ridimensionare i trade; nil se disabilitato
+	scorerPolicy   scoring.Policy            // Conversione del punteggio in decisione e dimensionamento
+	scorerFailOpen bool                      // Se lo scorer non risponde il trade procede a quantità piena
+	jobs           *services.JobQueue        // Coda persistente delle azioni differite sugli ordini
+	cancelAfter    time.Duration             // Cancella gli ordini non eseguiti dopo questo intervallo; 0 se disabilitato
+	maxDataAge     time.Duration             // Età massima delle candele usate per piazzare un ordine; 0 se disabilitato
+	orderCheck     preflight.MarginReader    // Regole del simbolo e margine per scartare gli ordini destinati al rifiuto; nil se disabilitato
+	takerFee       float64                   // Commissione taker effettiva di Bybit (o configurata) usata nella stima del margine richiesto
+	latency        *LatencySLO               // Obiettivo di latenza tra chiusura della candela e conferma dell'ordine; nil se disattivato
+	priceCheck     *risk.PriceChecker        // Confronto del prezzo di ingresso con una seconda fonte; nil se disabilitato
+	risk           *risk.Manager             // Limiti di rischio globali (es. simboli esclusi dal trading)
+	budgets        *services.BudgetService   // Quota virtuale del saldo assegnata al worker
+	sizer          *risk.Sizer               // Capitale da cui dimensionare gli ordini (compound o fixed)
+	stops          *risk.StopPlacer          // Stop sotto lo swing o chandelier invece che percentuale; nil con stop fisso
+	pyramid        *strategy.Pyramid         // Incrementi delle posizioni in profitto; nil se disabilitato
+	positions      *services.PositionManager // Ingresso e incrementi della posizione aperta, con stop sul prezzo medio
+	flags          *features.Flags           // Funzionalità rischiose attive su questa istanza (trailing stop, pyramiding, ...)
+	charts         config.TradeChartConfig   // Grafico PNG disegnato all'apertura di ogni trade
+	events         *events.Emitter           // Pubblica le decisioni sui segnali (signal.decided); nil se disabilitato
+	indicators     *taprocess.TalibProcessor // Pipeline degli indicatori tecnici, con le sorgenti di prezzo configurate
+
+	// warmUp è lo storico di candele chiuse richiesto dagli indicatori e dai filtri attivi: finché manca non si opera
+	warmUp *strategy.WarmUp
+
+	// throttle limita gli ordini per simbolo e account, condiviso con le altre strategie; nil se disabilitato
+	throttle *orderprocessor.OrderThrottle
+
+	// spread blocca o rinvia gli incrementi a mercato con spread troppo ampio; nil se disabilitato
+	spread *risk.SpreadGuard
+
+	// slicer fraziona gli ingressi troppo grandi per la liquidità al miglior prezzo; nil se disabilitato
+	slicer *services.OrderSlicer
+
+	// executor invia gli ingressi con l'algoritmo associato all'urgenza configurata
+	executor *execution.Executor
+	urgency  execution.Urgency
+
+	// breakEvenPct è il PnL oltre cui lo stop va sul prezzo di ingresso; trading indica se l'ultimo ciclo non era in standby
+	breakEvenPct   float64
+	trading        atomic.Bool
+	breakEven      map[string]bool // Posizioni (lato e prezzo di ingresso) con lo stop già sull'ingresso
+	breakEvenRetry time.Time       // Prima del prossimo tentativo dopo un aggiornamento dello stop fallito
 }
 
-// NewDogeTradingSystemWorker crea una nuova istanza del worker
-func NewDogeTradingSystemWorker() *DogeTradingSystemWorker {
-	ctx, cancel := context.WithCancel(context.Background())
+// NewDogeTradingSystemWorker crea una nuova istanza del worker usando le dipendenze condivise
+func NewDogeTradingSystemWorker(deps *SystemDependencies) *DogeTradingSystemWorker {
+	// Le modifiche agli ordini fatte dal worker vengono attribuite al worker nell'audit trail
+	ctx, cancel := context.WithCancel(database.WithChangedBy(context.Background(), "doge-trading-system"))
+
+	var sentiment *strategy.SentimentFilter
+	if deps.Config.Sentiment.Enabled {
+		filter, err := strategy.NewSentimentFilter(strategy.SentimentFilterParams{
+			MaxLong:  deps.Config.Sentiment.MaxLong,
+			MinShort: deps.Config.Sentiment.MinShort,
+		})
+		if err != nil {
+			log.Printf("❌ Filtro di sentiment disabilitato: %v", err)
+		} else {
+			sentiment = filter
+		}
+	}
 
-	// Carica la configurazione
-	cfg, err := config.Load()
-	if err != nil {
-		log.Printf("Errore nel caricamento della configurazione: %v", err)
-		// Usa valori di default per testnet
-		cfg = &config.Config{
-			Bybit: config.BybitConfig{
-				APIKey:    os.Getenv("BYBIT_API_KEY"),
-				SecretKey: os.Getenv("BYBIT_SECRET_KEY"),
-			},
+	var trend *strategy.TrendFilter
+	if deps.Config.Trend.Enabled {
+		filter, err := strategy.NewTrendFilter(strategy.TrendFilterParams{
+			Period:       deps.Config.Trend.Period,
+			SlopeCandles: deps.Config.Trend.SlopeCandles,
+			Source:       deps.Config.Indicators.Sources.EMA223,
+		})
+		if err != nil {
+			log.Printf("❌ Filtro di trend disabilitato: %v", err)
+		} else {
+			trend = filter
+		}
+	}
+
+	var retest *strategy.RetestTracker
+	if deps.Config.Retest.Enabled {
+		tracker, err := strategy.NewRetestTracker(strategy.RetestParams{
+			Candles:   deps.Config.Retest.Candles,
+			Tolerance: deps.Config.Retest.Tolerance,
+		})
+		if err != nil {
+			log.Printf("❌ Ingresso su retest disabilitato: %v", err)
+		} else {
+			retest = tracker
+		}
+	}
+
+	var profiler *strategy.VolumeProfiler
+	if deps.Config.Profile.Enabled {
+		calculator, err := strategy.NewVolumeProfiler(strategy.VolumeProfileParams{
+			Bins:      deps.Config.Profile.Bins,
+			ValueArea: deps.Config.Profile.ValueArea,
+		})
+		if err != nil {
+			log.Printf("❌ Volume profile disabilitato: %v", err)
+		} else {
+			profiler = calculator
 		}
 	}
 
-	// Inizializza database
-	log.Println("Inizializzando database per DOGE Trading System...")
-	db, err := database.InitializeDatabaseWithData(database.DefaultConfig())
+	var flowFilter *strategy.OrderFlowFilter
+	if deps.OrderFlow != nil {
+		filter, err := strategy.NewOrderFlowFilter(strategy.OrderFlowFilterParams{
+			MinImbalance: deps.Config.OrderFlow.MinImbalance,
+			MinTrades:    deps.Config.OrderFlow.MinTrades,
+		})
+		if err != nil {
+			log.Printf("❌ Conferma delle rotture con l'order flow disabilitata: %v", err)
+		} else {
+			flowFilter = filter
+		}
+	}
+
+	var avwapTrail *strategy.AVWAPTrail
+	if deps.Config.AVWAP.Enabled {
+		trail, err := strategy.NewAVWAPTrail(strategy.AVWAPTrailParams{
+			Buffer:     deps.Config.AVWAP.Buffer,
+			MinCandles: deps.Config.AVWAP.MinCandles,
+		})
+		if err != nil {
+			log.Printf("❌ Stop sul VWAP ancorato disabilitato: %v", err)
+		} else {
+			avwapTrail = trail
+		}
+	}
+
+	var stops *risk.StopPlacer
+	if mode, ok := deps.Config.Risk.StopModes["doge-trading-system"]; ok {
+		placer, err := risk.NewStopPlacer(risk.StopParams{
+			Mode:          mode,
+			SwingLookback: deps.Config.Risk.StopSwingLookback,
+			ATRPeriod:     deps.Config.Risk.StopATRPeriod,
+			ATRMultiplier: deps.Config.Risk.StopATRMultiplier,
+		})
+		if err != nil {
+			log.Printf("❌ Posizionamento dello stop disabilitato, uso lo stop percentuale: %v", err)
+		} else {
+			stops = placer
+		}
+	}
+
+	var pyramid *strategy.Pyramid
+	if deps.Config.Pyramid.Enabled {
+		scaler, err := strategy.NewPyramid(strategy.PyramidParams{
+			MaxAdds:   deps.Config.Pyramid.MaxAdds,
+			Step:      deps.Config.Pyramid.Step,
+			SizeDecay: deps.Config.Pyramid.SizeDecay,
+		})
+		if err != nil {
+			log.Printf("❌ Pyramiding disabilitato: %v", err)
+		} else {
+			pyramid = scaler
+		}
+	}
+
+	priceCheck, err := newPriceChecker(deps, "bybit")
 	if err != nil {
-		log.Fatalf("ERRORE CRITICO: Impossibile inizializzare database: %v", err)
+		log.Printf("❌ Controllo del prezzo su seconda fonte disabilitato: %v", err)
 	}
 
-	// Crea repository manager e order service
-	repoManager := repositories.NewRepositoryManager(db)
-	orderService := services.NewOrderService(repoManager)
+	var cancelAfter time.Duration
+	if deps.Config.Jobs.Enabled {
+		cancelAfter = deps.Config.Jobs.CancelUnfilledAfter
+	}
 
-	// Crea il processor per gli ordini
-	var orderProcessor orderprocessor.OrderProcessor
-	if cfg.Bybit.APIKey != "" && cfg.Bybit.SecretKey != "" {
-		orderProcessor = orderprocessor.NewBybitOrderProcessor(cfg.Bybit.APIKey, cfg.Bybit.SecretKey)
-	} else {
-		log.Println("ATTENZIONE: Credenziali API Bybit non configurate, ordini non funzioneranno")
-		orderProcessor = nil
+	// Il controllo richiede un processor che esponga regole del simbolo e margine (es. non il paper trading)
+	var orderCheck preflight.MarginReader
+	if reader, ok := deps.OrderProcessor.(preflight.MarginReader); ok && deps.Config.OrderCheck.Enabled {
+		orderCheck = reader
 	}
 
+	// In paper trading l'equity è simulata: ogni avvio è una run di backtest separata, con il proprio saldo iniziale
+	snapshotSource, snapshotRunID := models.SnapshotSourceLive, ""
+	if deps.Config.Paper.Enabled {
+		snapshotSource, snapshotRunID = models.SnapshotSourceBacktest, "paper-"+time.Now().UTC().Format("20060102T150405")
+		log.Printf("📝 Snapshot dell'equity simulata salvati come backtest, run %s", snapshotRunID)
+	}
+
+	indicators := taprocess.NewTalibProcessor()
+	indicators.Sources = deps.Config.Indicators.Sources
+
+	// Ogni componente dichiara le candele chiuse di cui ha bisogno; i componenti disabilitati non contano
+	warmUp := strategy.NewWarmUp().
+		Require("ema223", indicators.MinCandles()).
+		Require("wall", wallCandles+1)
+	if trend != nil {
+		warmUp.Require("trend", trend.MinCandles())
+	}
+	if deps.Scorer != nil {
+		warmUp.Require("scorer", scoring.MinFeatureCandles)
+	}
+	warmUp.Require("stop", stops.MinCandles())
+
 	return &DogeTradingSystemWorker{
-		ctx:            ctx,
+		cycleContext:   newCycleContext(ctx),
 		cancel:         cancel,
-		exchange:       exchange.NewBybitExchange(false), // false = usa produzione, true = usa testnet
-		orderProcessor: orderProcessor,
-		db:             db,
-		orderService:   orderService,
+		exchange:       deps.Exchange,
+		orderProcessor: deps.OrderProcessor,
+		accountReader:  deps.AccountReader,
+		cashFlows:      deps.CashFlows,
+		db:             deps.DB,
+		repoManager:    deps.RepoManager,
+		orderService:   deps.OrderService,
+		reportService:  deps.ReportService,
+		snapshotSource: snapshotSource,
+		snapshotRunID:  snapshotRunID,
+		blackout:       deps.Blackout,
+		calendarCfg:    deps.Config.Calendar,
+		sentiment:      sentiment,
+		sentimentAge:   deps.Config.Sentiment.MaxAge,
+		trend:          trend,
+		retest:         retest,
+		profiler:       profiler,
+		profileDays:    deps.Config.Profile.Sessions,
+		profileRoom:    deps.Config.Profile.MinRoom,
+		flow:           deps.OrderFlow,
+		flowFilter:     flowFilter,
+		liquidations:   deps.Liquidations,
+		liqCfg:         deps.Config.Liquidation,
+		regimes:        deps.Regimes,
+		anchors:        strategy.NewAnchorTracker(),
+		avwapTrail:     avwapTrail,
+		scorer:         deps.Scorer,
+		scorerPolicy: scoring.Policy{
+			MinScore: deps.Config.Scorer.MinScore,
+			MinSize:  deps.Config.Scorer.MinSize,
+			MaxSize:  deps.Config.Scorer.MaxSize,
+		},
+		scorerFailOpen: deps.Config.Scorer.FailOpen,
+		jobs:           deps.Jobs,
+		cancelAfter:    cancelAfter,
+		maxDataAge:     deps.Config.Risk.MaxDataAge,
+		orderCheck:     orderCheck,
+		takerFee:       deps.Fees.TakerFeeOr("bybit", "DOGEUSDT", deps.Config.Arbitrage.TakerFees["bybit"]),
+		latency:        deps.Latency,
+		priceCheck:     priceCheck,
+		risk:           deps.Risk,
+		budgets:        deps.Budgets,
+		sizer:          deps.Sizer,
+		stops:          stops,
+		pyramid:        pyramid,
+		positions:      services.NewPositionManager(deps.RepoManager, deps.OrderService, deps.OrderProcessor),
+		flags:          deps.Features,
+		charts:         deps.Config.Charts,
+		events:         deps.Events,
+		indicators:     indicators,
+		warmUp:         warmUp,
+		throttle:       deps.Throttle,
+		spread:         deps.Spread,
+		slicer:         deps.Slicer,
+		executor:       deps.Executor,
+		urgency:        execution.Urgency(deps.Config.Execution.DogeUrgency),
+		breakEvenPct:   deps.Config.PnL.BreakEvenPct,
+		breakEven:      make(map[string]bool),
 	}
 }
 
@@ -84,9 +328,30 @@ func (w *DogeTradingSystemWorker) ExecuteTradingCycle() {
 	w.executeTradingCycle()
 }
 
+// WarmUp aggiorna candele e stato della posizione sull'istanza in standby, senza operare
+// Se l'istanza diventa leader, il primo ciclo parte con la cache delle candele e il flag orderPlaced allineati
+func (w *DogeTradingSystemWorker) WarmUp() {
+	w.trading.Store(false)
+	if candleResponse := w.fetchLast1000Candles(); candleResponse != nil {
+		w.cacheClosedCandles(candleResponse.Closed())
+	}
+
+	positions, err := w.orderProcessor.GetPositions(w.ctx, "DOGEUSDT")
+	if err != nil {
+		log.Printf("Errore nel controllo delle posizioni in standby: %v", err)
+		return
+	}
+	w.orderPlaced = len(positions) > 0
+	log.Printf("💤 Stato DOGE aggiornato in standby: %d posizioni aperte", len(positions))
+}
+
 // executeTradingCycle esegue un ciclo completo di trading
 func (w *DogeTradingSystemWorker) executeTradingCycle() {
 	log.Println("Executing DOGE Trading Cycle...")
+	w.trading.Store(true)
+
+	// Snapshot dell'equity per la reportistica di performance
+	w.recordBalanceSnapshot()
 
 	// ========================================
 	// FASE 0: Controllo flag orderPlaced
@@ -103,12 +368,37 @@ func (w *DogeTradingSystemWorker) executeTradingCycle() {
 
 	w.orderPlaced = orderPlaced
 
+	// Durante il blackout attorno agli eventi macro non si aprono posizioni
+	// e, se configurato, si stringe lo stop della posizione aperta
+	_, inBlackout := activeBlackout(w.ctx, w.blackout)
+
 	// Se l'ordine + piazzato allora non faccio nulla
 	if w.orderPlaced {
+		if inBlackout && w.calendarCfg.TightenStops {
+			tightenStops(w.ctx, w.orderProcessor, "DOGEUSDT", w.calendarCfg.TightenStopPct)
+		}
+		// Lo stop sul VWAP ancorato e quello dopo le liquidazioni si stringono anche durante il blackout
+		w.trailAVWAP("DOGEUSDT")
+		w.liquidationStops("DOGEUSDT")
+		// Fuori dal blackout una posizione in profitto può essere incrementata, se il simbolo resta negoziabile
+		if !inBlackout && w.risk.CheckSymbol("DOGEUSDT") == nil {
+			w.scaleIn("DOGEUSDT")
+		}
 		log.Println("🔄 orderPlaced=true - Bypass del ciclo di trading, riprova tra 5 minuti")
 		return
 	}
 
+	if inBlackout {
+		log.Println("⏸️  Blackout attivo - Bypass del ciclo di trading")
+		return
+	}
+
+	// Le liste globali dei simboli bloccano i nuovi ingressi, non la gestione della posizione aperta
+	if err := w.risk.CheckSymbol("DOGEUSDT"); err != nil {
+		log.Printf("⏸️  %v - Bypass del ciclo di trading", err)
+		return
+	}
+
 	// ========================================
 	// FASE 1: Fetch delle ultime 1000 candele
 	// ========================================
@@ -119,104 +409,308 @@ func (w *DogeTradingSystemWorker) executeTradingCycle() {
 		return
 	}
 
-	// Estrai le ultime 5 candele chiuse (escludendo quella attualmente aperta e l'ultima chiusa)
-	last40Candles, wall, support, err := w.extractCandlesForChecks(candleResponse.Candles)
-	currentClosedCandle := candleResponse.Candles[len(candleResponse.Candles)-2] // Ultima candela chiusa ovvero la penultima
+	// Il ciclo opera solo sulle candele chiuse: con lo scheduling alla chiusura anche la più recente di solito lo è
+	closedCandles := candleResponse.Closed()
+
+	// Salva le candele chiuse nella cache, usata per ricostruire le feature negli export dei dataset
+	w.cacheClosedCandles(closedCandles)
+
+	// Nessun segnale finché le candele chiuse non coprono il componente più esigente
+	if err := w.warmUp.Check(len(closedCandles)); err != nil {
+		log.Printf("⏳ %v - Bypass del ciclo di trading", err)
+		return
+	}
+
+	// Estrai le candele chiuse del muro (escludendo l'ultima chiusa)
+	last40Candles, wall, support, err := w.extractCandlesForChecks(closedCandles)
 	if err != nil {
 		log.Printf("Error extracting candles for checks: %v", err)
 		return
 	}
+	currentClosedCandle := closedCandles[len(closedCandles)-1] // Ultima candela chiusa
+
+	// Il regime di mercato del simbolo decide quali ingressi sono attivi: le rotture, retest compreso, in trend
+	breakouts := w.regimes.Allows("DOGEUSDT", strategy.EntryBreakout)
+
+	// In modalità retest un ingresso parte dalla conferma di una rottura precedente
+	if w.retest != nil && breakouts {
+		if side, level, ok := w.retest.Advance("DOGEUSDT", closedCandles); ok {
+			explain := w.newSignal(side, "retest")
+			explain.Check("retest", true, currentClosedCandle.Close, level, "ritorno sul livello rotto confermato")
+			w.enterTrade(explain, closedCandles, currentClosedCandle.Close, level)
+			return
+		}
+	}
+
+	// Una cascata di liquidazioni recente è un segnale contrarian, soggetto agli stessi filtri delle rotture
+	if cluster, ok := w.liquidationEntry("DOGEUSDT"); ok {
+		explain := w.newSignal(cluster.Side, "liquidation_cascade")
+		explain.Check("liquidation_notional", true, cluster.Notional, w.liqCfg.MinNotional,
+			fmt.Sprintf("%d liquidazioni a %.6f", cluster.Count, cluster.Price))
+		w.enterTrade(explain, closedCandles, currentClosedCandle.Close, 0)
+		return
+	}
+
+	if !breakouts {
+		state, _ := w.regimes.Get("DOGEUSDT")
+		log.Printf("⏸️  Regime %s: rotture disattivate - Bypass del controllo del muro", state.Regime)
+		return
+	}
 
 	// 3 Controllo rottura muro delle ultime 40 candele precedenti con chiusura sopra il muro o sotto la resistenza
 	wallBreak, supportBreak := w.checkWallAndSupportBreak(currentClosedCandle, last40Candles, wall, support)
 
-	// Se rompe il muro allora faccio i check sul volume per le candele verdi
-	if wallBreak { // Rottura del muro delle 5 candele precedenti
-
-		log.Println("All conditions met! Proceeding with LONG order...")
-		// 4 Calcolo media volume candele verdi delle ultime 5 candele verdi
-		log.Println("Resistance broken! -----> Calculating green candles average volume...")
-		greenCandlesVolumeTotAvg := w.calculateGreenCandlesAverageVolume(candleResponse.Candles)
-		// Adesso prendo il volume dell'ultima candela chiusa che ha chiuso sopra il muro
-		lastCandleVolume := currentClosedCandle.Volume
-
-		log.Printf("Last candle volume: %.2f", lastCandleVolume)
-		log.Printf("Green candles averageVolumeTOT: %.2f", greenCandlesVolumeTotAvg)
-
-		// Se il volume dell'ultima candela è maggiore del rapporto
-		if greenCandlesVolumeTotAvg > 0.6 && currentClosedCandle.Volume > greenCandlesVolumeTotAvg*1.2 {
-			// In questo caso tutti i check sono passati quindi vuol dire che troviamo
-			// di fronte ad una potenziale opportunità di trading
-			log.Println("All conditions met! Proceeding with LONG order...")
-			// ========================================
-			// FASE 3.1: Piazzamento ordine LONG
-			// ========================================
-			price := currentClosedCandle.Close
-			orderID := w.placeLongOrder(price)
-			if orderID == "" {
-				log.Println("Failed to place LONG order")
-				time.Sleep(1 * time.Second)
-				orderID = w.placeLongOrder(price)
-				if orderID == "" {
-					log.Println("Failed to place LONG order second time")
-					log.Println("Trying last time")
-					time.Sleep(1 * time.Second)
-					orderID = w.placeLongOrder(price)
-					if orderID == "" {
-						log.Println("Failed to place LONG order third time")
-						return
-					}
-					return
-				}
-				return
-			}
-		}
-	} else if supportBreak { // Rottura del supporto delle 5 candele precedenti, qui calcolo il volume per le candele rosse
-
-		log.Println("All conditions met! Proceeding with SHORT order...")
-
-		// 5 Calcolo media volume candele rosse delle ultime 5 candele rosse
-		log.Println("Support broken! -----> Calculating red candles average volume...")
-		redCandlesVolumeTotAvg := w.calculateRedCandlesAverageVolume(candleResponse.Candles)
-		// Adesso prendo il volume dell'ultima candela che ha chiuso sotto il supporto
-		lastCandleVolume := currentClosedCandle.Volume
-
-		log.Printf("Last candle volume: %.2f", lastCandleVolume)
-		log.Printf("Green candles averageVolumeTOT: %.2f", redCandlesVolumeTotAvg)
-
-		if redCandlesVolumeTotAvg > 0.6 && currentClosedCandle.Volume > redCandlesVolumeTotAvg*1.2 {
-			// In questo caso tutti i check sono passati quindi vuol dire che troviamo
-			// di fronte ad una potenziale opportunità di trading
-			log.Println("All conditions met! Proceeding with SHORT order...")
-
-			// ========================================
-			// FASE 3.1: Piazzamento ordine SHORT
-			// ========================================
-			price := currentClosedCandle.Close
-			orderID := w.placeShortOrder(price) // Da implementare
-			if orderID == "" {
-				log.Println("Failed to place SHORT order")
-				time.Sleep(1 * time.Second)
-				orderID = w.placeShortOrder(price)
-				if orderID == "" {
-					log.Println("Failed to place SHORT order second time")
-					log.Println("Trying last time")
-					time.Sleep(1 * time.Second)
-					orderID = w.placeShortOrder(price)
-					if orderID == "" {
-						log.Println("Failed to place SHORT order third time")
-						return
-					}
-					return
-				}
-				return
-			}
-		}
+	// La candela di rottura è l'ancora del VWAP usato come riferimento di uscita della posizione
+	if wallBreak || supportBreak {
+		w.anchors.SetAnchor("DOGEUSDT", currentClosedCandle)
+	}
+
+	if wallBreak { // Rottura del muro: i check sul volume usano le candele verdi
+		w.breakoutSignal(models.OrderSideBuy, "wall_break", closedCandles, wall,
+			w.calculateGreenCandlesAverageVolume(closedCandles))
+	} else if supportBreak { // Rottura del supporto: i check sul volume usano le candele rosse
+		w.breakoutSignal(models.OrderSideSell, "support_break", closedCandles, support,
+			w.calculateRedCandlesAverageVolume(closedCandles))
 	} else {
 		log.Println("Trading conditions not met, skipping order placement")
 	}
 }
 
+// breakoutSignal verifica il volume e l'order flow della rottura di level nella direzione indicata e,
+// se confermata, entra subito oppure attende il retest del livello
+// closedCandles terminano con la candela di rottura; averageVolume è il volume medio delle candele nella direzione della rottura
+func (w *DogeTradingSystemWorker) breakoutSignal(side models.OrderSide, trigger string, closedCandles []models.Candle, level, averageVolume float64) {
+	closed := closedCandles[len(closedCandles)-1]
+	explain := w.newSignal(side, trigger)
+	explain.Check("breakout_close", true, closed.Close, level, "chiusura oltre il livello delle candele precedenti")
+	explain.Check("average_volume", averageVolume > 0.6, averageVolume, 0.6, "volume medio minimo delle candele nella direzione della rottura")
+	explain.Check("breakout_volume", closed.Volume > averageVolume*1.2, closed.Volume, averageVolume*1.2, "volume della candela di rottura oltre 1.2 volte la media")
+	// Il volume della candela non distingue il lato: l'order flow conferma che a spingere sono gli aggressori nella direzione della rottura
+	if !explain.Passed() || !w.flowConfirms(explain) {
+		w.recordSignal(explain, models.SignalOutcomeRejected, "")
+		return
+	}
+
+	if w.retest != nil {
+		w.retest.Arm("DOGEUSDT", side, level, closed)
+		w.recordSignal(explain, models.SignalOutcomeArmed, "")
+		return
+	}
+	w.enterTrade(explain, closedCandles, closed.Close, level)
+}
+
+// enterTrade applica i filtri e i controlli pre-trade a un segnale e piazza l'ordine nella direzione del segnale
+// closedCandles sono le candele chiuse del ciclo; price è la chiusura dell'ultima candela chiusa;
+// level è il livello rotto che ha generato il segnale (0 per gli ingressi che non nascono da una rottura)
+func (w *DogeTradingSystemWorker) enterTrade(explain *models.SignalExplanation, closedCandles []models.Candle, price, level float64) {
+	if !w.sentimentAllows(explain) || !w.trendAllows(explain, closedCandles) || !w.profileAllows(explain, price) {
+		w.recordSignal(explain, models.SignalOutcomeRejected, "")
+		return
+	}
+	sizeMultiplier := w.scoreSignal(explain, closedCandles)
+	if sizeMultiplier <= 0 {
+		w.recordSignal(explain, models.SignalOutcomeRejected, "")
+		return
+	}
+	release, ok := w.preTradeChecks(explain, closedCandles, price)
+	if !ok {
+		w.recordSignal(explain, models.SignalOutcomeRejected, "")
+		return
+	}
+	defer release()
+
+	// ========================================
+	// FASE 3.1: Piazzamento ordine LONG o SHORT
+	// ========================================
+	placeOrder := w.placeLongOrder
+	if explain.Side == models.OrderSideSell {
+		placeOrder = w.placeShortOrder
+	}
+	orderID := placeOrder(price, sizeMultiplier, closedCandles)
+	if orderID == "" {
+		w.recordSignal(explain, models.SignalOutcomeFailed, "")
+		return
+	}
+	w.recordSignal(explain, models.SignalOutcomeEntered, orderID)
+	w.renderTradeChart(orderID, explain.Side, closedCandles, level)
+}
+
+// execute invia l'ingresso tramite lo strato di esecuzione, con l'urgenza configurata per il worker
+// Ogni ordine inviato è soggetto al limite di ordini per simbolo e account; con l'exchange in modalità degradata
+// i nuovi tentativi sono sospesi. Il report può essere parziale se l'algoritmo si è interrotto
+func (w *DogeTradingSystemWorker) execute(symbol, prefix string, side models.OrderSide, price, quantity, stopLoss, takeProfit float64) (*execution.Report, error) {
+	return w.executor.Execute(w.ctx, execution.Intent{
+		Symbol:     symbol,
+		Side:       side,
+		Quantity:   quantity,
+		Urgency:    w.urgency,
+		Price:      price,
+		StopLoss:   stopLoss,
+		TakeProfit: takeProfit,
+		Prefix:     prefix + "_" + symbol,
+	})
+}
+
+// orderAllowed prevede se Bybit rifiuterebbe l'ordine (quantità fuori dai limiti del simbolo, margine insufficiente)
+// Un ordine destinato al rifiuto non viene inviato né ritentato; se le informazioni non sono disponibili
+// l'ordine procede e la validazione resta all'exchange
+func (w *DogeTradingSystemWorker) orderAllowed(symbol string, side models.OrderSide, price, quantity float64) bool {
+	if w.orderCheck == nil {
+		return true
+	}
+
+	report, err := preflight.CheckOrder(w.ctx, w.orderCheck, preflight.OrderRequest{
+		Symbol:   symbol,
+		Side:     side,
+		Price:    price,
+		Quantity: quantity,
+		TakerFee: w.takerFee,
+	})
+	var rejected *preflight.OrderRejectedError
+	if errors.As(err, &rejected) {
+		correlation.Logf(w.ctx, "⏸️  Ordine %s %s non inviato, verrebbe rifiutato:", side, symbol)
+		for _, failure := range rejected.Failures {
+			correlation.Logf(w.ctx, "  %s: %s", failure.Reason, failure.Message)
+		}
+		return false
+	}
+	if err != nil {
+		log.Printf("⚠️  Controllo preventivo dell'ordine non eseguito: %v", err)
+		return true
+	}
+
+	log.Printf("Controllo preventivo: quantità %g, costo stimato $%.2f su $%.2f disponibili (leva %gx)",
+		report.Quantity, report.Cost, report.Available, report.Leverage)
+	return true
+}
+
+// preTradeChecks verifica, prima di piazzare l'ordine, i dati di mercato del segnale e i limiti di rischio
+// Se l'ultima candela si è chiusa da più di maxDataAge l'exchange sta restituendo dati vecchi;
+// se il prezzo di ingresso si scosta troppo dalla seconda fonte la candela contiene probabilmente un tick anomalo.
+// Se i controlli passano DOGEUSDT resta prenotato presso il risk manager finché non viene chiamato release
+func (w *DogeTradingSystemWorker) preTradeChecks(explain *models.SignalExplanation, candles []models.Candle, price float64) (release func(), ok bool) {
+	if err := risk.CheckCandles("DOGEUSDT", candles, models.Timeframe1m, w.maxDataAge); err != nil {
+		explain.Verdict("candle_freshness", false, err.Error())
+		return nil, false
+	}
+	if err := w.priceCheck.Check(w.ctx, "DOGEUSDT", price); err != nil {
+		explain.Verdict("price_check", false, err.Error())
+		return nil, false
+	}
+	release, err := w.risk.ReservePosition(w.ctx, "DOGEUSDT")
+	if err != nil {
+		explain.Verdict("position_limit", false, err.Error())
+		return nil, false
+	}
+	return release, true
+}
+
+// sentimentAllows applica il filtro di sentiment a un ingresso nella direzione del segnale
+// Senza un valore recente del Fear & Greed Index il filtro non blocca l'ingresso
+func (w *DogeTradingSystemWorker) sentimentAllows(explain *models.SignalExplanation) bool {
+	if w.sentiment == nil {
+		return true
+	}
+
+	point, err := w.repoManager.DataPoint().GetLatest(w.ctx, models.DataSourceFearGreed)
+	if err != nil {
+		explain.Skipped("sentiment", err.Error())
+		return true
+	}
+	if time.Since(point.ObservedAt) > w.sentimentAge {
+		explain.Skipped("sentiment", "ultimo valore del "+point.ObservedAt.Format("2006-01-02"))
+		return true
+	}
+
+	allowed := w.sentiment.AllowShort(point.Value)
+	if explain.Side == models.OrderSideBuy {
+		allowed = w.sentiment.AllowLong(point.Value)
+	}
+	explain.Check("sentiment", allowed, point.Value, w.sentiment.Threshold(explain.Side), "Fear & Greed "+point.Label)
+	return allowed
+}
+
+// trendAllows applica il filtro di trend a un ingresso nella direzione del segnale, sulle candele chiuse
+func (w *DogeTradingSystemWorker) trendAllows(explain *models.SignalExplanation, closedCandles []models.Candle) bool {
+	if w.trend == nil {
+		return true
+	}
+
+	if err := w.trend.Check(explain.Side, closedCandles); err != nil {
+		explain.Verdict("trend", false, err.Error())
+		return false
+	}
+	explain.Verdict("trend", true, "")
+	return true
+}
+
+// profileAllows blocca un ingresso troppo vicino al prossimo livello del volume profile nella sua direzione:
+// POC, VAH e VAL delle sessioni precedenti fanno da resistenza per i long e da supporto per gli short.
+// I profili sono calcolati dalla cache delle candele; senza dati il filtro non blocca l'ingresso
+func (w *DogeTradingSystemWorker) profileAllows(explain *models.SignalExplanation, price float64) bool {
+	if w.profiler == nil {
+		return true
+	}
+
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	start := end.AddDate(0, 0, -w.profileDays)
+	records, err := w.repoManager.Candle().GetRange(w.ctx, "DOGEUSDT", models.DerivativesMarket, models.Timeframe1m, start, end)
+	if err != nil {
+		explain.Skipped("volume_profile", err.Error())
+		return true
+	}
+	candles := make([]models.Candle, len(records))
+	for i, record := range records {
+		candles[i] = record.ToCandle()
+	}
+	profiles := w.profiler.Sessions(candles, 24*time.Hour)
+	if len(profiles) == 0 {
+		explain.Skipped("volume_profile", "nessuna candela in cache dal "+start.Format("2006-01-02"))
+		return true
+	}
+
+	support, resistance := strategy.NearestLevels(profiles, price)
+	level, room := resistance, resistance/price-1
+	if explain.Side == models.OrderSideSell {
+		level, room = support, 1-support/price
+	}
+	if level <= 0 {
+		explain.Skipped("volume_profile", fmt.Sprintf("nessun livello nella direzione dell'ingresso su %d sessioni", len(profiles)))
+		return true
+	}
+	allowed := room >= w.profileRoom
+	explain.Check("volume_profile", allowed, room, w.profileRoom,
+		fmt.Sprintf("distanza dal livello %.6f su %d sessioni", level, len(profiles)))
+	return allowed
+}
+
+// flowConfirms verifica con il delta dei trade pubblici che la rottura nella direzione del segnale sia spinta dagli aggressori
+// Con lo stream interrotto o troppo pochi trade nella finestra il filtro non blocca l'ingresso
+func (w *DogeTradingSystemWorker) flowConfirms(explain *models.SignalExplanation) bool {
+	if w.flow == nil || w.flowFilter == nil {
+		return true
+	}
+
+	delta, ok := w.flow.Delta("DOGEUSDT")
+	if !ok || delta.Trades < w.flowFilter.MinTrades() {
+		explain.Skipped("order_flow", fmt.Sprintf("%d trade nella finestra di %s", delta.Trades, w.flow.Window()))
+		return true
+	}
+
+	// Sbilanciamento nella direzione della rottura, confrontato con la soglia del filtro
+	var imbalance float64
+	if total := delta.BuyVolume + delta.SellVolume; total > 0 {
+		imbalance = delta.Delta() / total
+	}
+	if explain.Side == models.OrderSideSell {
+		imbalance = -imbalance
+	}
+	detail := fmt.Sprintf("delta %.2f su %d trade (acquisti %.2f, vendite %.2f)", delta.Delta(), delta.Trades, delta.BuyVolume, delta.SellVolume)
+	err := w.flowFilter.Check(explain.Side, delta.BuyVolume, delta.SellVolume)
+	explain.Check("order_flow", err == nil, imbalance, w.flowFilter.MinImbalance(), detail)
+	return err == nil
+}
+
 // GetName implementa l'interfaccia Worker
 func (w *DogeTradingSystemWorker) GetName() string {
 	return "DOGE Trading System Worker"
@@ -231,16 +725,10 @@ func (w *DogeTradingSystemWorker) Start() {
 }
 
 // Stop ferma il worker
+// La connessione al database è condivisa e viene chiusa dal WorkerManager
 func (w *DogeTradingSystemWorker) Stop() {
 	log.Println("Stopping DOGE Trading System Worker...")
 	w.cancel()
-
-	// Chiudi connessione database
-	if w.db != nil {
-		if err := database.Close(w.db); err != nil {
-			log.Printf("Errore chiusura database: %v", err)
-		}
-	}
 }
 
 // mapBybitStatusToOrderStatusID mappa lo stato Bybit al OrderStatusID del database
@@ -266,9 +754,8 @@ func (w *DogeTradingSystemWorker) mapBybitStatusToOrderStatusID(bybitStatus stri
 		log.Printf("Stato Bybit '%s' non mappato, uso 'New' come default", bybitStatus)
 	}
 
-	// Recupera l'ID dal database
-	repoManager := repositories.NewRepositoryManager(w.db)
-	status, err := repoManager.OrderStatus().GetByStatusName(w.ctx, mappedStatus)
+	// Recupera l'ID dalla cache degli stati (condivisa tramite il repository manager)
+	status, err := w.repoManager.OrderStatus().GetByStatusName(w.ctx, mappedStatus)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get order status '%s': %w", mappedStatus, err)
 	}
@@ -276,9 +763,10 @@ func (w *DogeTradingSystemWorker) mapBybitStatusToOrderStatusID(bybitStatus stri
 	return status.ID, nil
 }
 
-// createOrderFromBybitResponse crea un Order dal OrderResponse
+// createOrderFromBybitResponse crea un Order dal OrderResponse, con il lato dell'ingresso (Buy = LONG, Sell = SHORT)
 func (w *DogeTradingSystemWorker) createOrderFromBybitResponse(
 	bybitResponse *models.OrderResponse,
+	side models.OrderSideType,
 	triggerPrice, quantity, takeProfit, stopLoss float64,
 ) (*models.Order, error) {
 	// Mappa lo stato Bybit
@@ -291,7 +779,7 @@ func (w *DogeTradingSystemWorker) createOrderFromBybitResponse(
 	order := &models.Order{
 		OrderID:         bybitResponse.OrderID,
 		Symbol:          "DOGEUSDT",
-		Side:            models.OrderSideTypeBuy, // Sempre Buy per ordini LONG
+		Side:            side,
 		OrderPrice:      triggerPrice,
 		Quantity:        quantity,
 		TakeProfitPrice: &takeProfit,
@@ -305,6 +793,13 @@ func (w *DogeTradingSystemWorker) createOrderFromBybitResponse(
 	return order, nil
 }
 
+// signalLatency misura in millisecondi il tempo dalla chiusura dell'ultima candela del segnale a ora,
+// appena l'exchange ha confermato l'ordine, e lo confronta con lo SLO
+func (w *DogeTradingSystemWorker) signalLatency(symbol string, closedCandles []models.Candle) int64 {
+	signalAt := closedCandles[len(closedCandles)-1].Timestamp.Add(models.Timeframe1m.Duration())
+	return w.latency.Observe("doge-trading-system", symbol, signalAt, time.Now()).Milliseconds()
+}
+
 // saveOrderToDatabase salva un ordine nel database
 func (w *DogeTradingSystemWorker) saveOrderToDatabase(order *models.Order) error {
 	if w.orderService == nil {
@@ -320,6 +815,31 @@ func (w *DogeTradingSystemWorker) saveOrderToDatabase(order *models.Order) error
 	return nil
 }
 
+// recordRejectedOrder salva nel database un ingresso rifiutato dall'exchange con il motivo del rifiuto,
+// per l'analisi dei rifiuti; gli errori che non sono rifiuti dell'exchange (rete, spread, throttle) sono ignorati
+func (w *DogeTradingSystemWorker) recordRejectedOrder(symbol string, side models.OrderSideType, price, quantity, takeProfit, stopLoss float64, err error) {
+	var rejected *orderprocessor.RejectedError
+	if w.orderService == nil || !errors.As(err, &rejected) {
+		return
+	}
+
+	order := &models.Order{
+		OrderID:         rejected.OrderLinkID,
+		Symbol:          symbol,
+		Side:            side,
+		OrderPrice:      price,
+		Quantity:        quantity,
+		TakeProfitPrice: &takeProfit,
+		StopLossPrice:   &stopLoss,
+		RejectReason:    rejected.Reason,
+	}
+	if err := w.orderService.RecordRejectedOrder(w.ctx, order); err != nil {
+		log.Printf("⚠️  Ordine rifiutato %s non salvato nel database: %v", rejected.OrderLinkID, err)
+		return
+	}
+	log.Printf("📝 Ordine rifiutato %s salvato nel database (motivo: %s)", rejected.OrderLinkID, rejected.Reason)
+}
+
 // CalculateMaxQuantity calcola la quantità massima basata su prezzo e saldo disponibile (metodo pubblico per test)
 func (w *DogeTradingSystemWorker) CalculateMaxQuantity(price float64) float64 {
 	return w.calculateMaxQuantity(price)
@@ -334,6 +854,52 @@ func (w *DogeTradingSystemWorker) GetUSDTBalance() (float64, error) {
 	return balance / 100, nil
 }
 
+// recordBalanceSnapshot salva lo snapshot dell'equity USDT corrente
+// Un errore non blocca il ciclo di trading
+func (w *DogeTradingSystemWorker) recordBalanceSnapshot() {
+	if w.accountReader == nil || w.reportService == nil {
+		return
+	}
+
+	walletResp, err := w.accountReader.GetWalletBalance(w.ctx, "UNIFIED", "USDT")
+	if err != nil {
+		log.Printf("Errore nel recupero saldo per snapshot: %v", err)
+		return
+	}
+
+	usdtBalance, found := walletResp.GetCoinBalance("USDT")
+	if !found {
+		log.Println("Saldo USDT non trovato, snapshot saltato")
+		return
+	}
+
+	equity, err := usdtBalance.GetEquityFloat()
+	if err != nil {
+		log.Printf("Errore nella conversione dell'equity per snapshot: %v", err)
+		return
+	}
+	walletBalance, _ := usdtBalance.GetWalletBalanceFloat()
+
+	snapshot := &models.BalanceSnapshot{
+		Source:        w.snapshotSource,
+		RunID:         w.snapshotRunID,
+		Coin:          "USDT",
+		Equity:        equity,
+		WalletBalance: walletBalance,
+		TakenAt:       time.Now().UTC(),
+	}
+	if err := w.reportService.RecordSnapshot(w.ctx, snapshot); err != nil {
+		log.Printf("Errore nel salvataggio snapshot: %v", err)
+	}
+
+	// Depositi e prelievi vanno registrati insieme agli snapshot, per separarli dal PnL di trading
+	if w.cashFlows != nil {
+		if err := w.reportService.SyncCashFlows(w.ctx, "bybit", w.cashFlows); err != nil {
+			log.Printf("Errore nella sincronizzazione di depositi e prelievi: %v", err)
+		}
+	}
+}
+
 // ========================================
 // FASE 1: Fetch delle ultime 100 candele
 // ========================================
@@ -351,8 +917,6 @@ func (w *DogeTradingSystemWorker) fetchLast1000Candles() *models.CandleResponse
 		1000,                     // Limite di 1000 candele
 	)
 
-	slices.Reverse(candleResponse.Candles) // Reverse dell'array in place, il che significa che adesso ho le candele ordine in maniera cronologica inversa (Dalla più recente alla più vecchia)
-
 	if err != nil {
 		log.Printf("Error fetching candles: %v", err)
 		return nil
@@ -363,45 +927,50 @@ func (w *DogeTradingSystemWorker) fetchLast1000Candles() *models.CandleResponse
 		return nil
 	}
 
+	slices.Reverse(candleResponse.Candles) // Reverse dell'array in place: Bybit restituisce le candele dalla più recente, adesso sono in ordine cronologico
+
+	if !candleResponse.Complete() {
+		log.Printf("⚠️  Ricevute %d candele DOGEUSDT su %d richieste in %v", candleResponse.Returned(), candleResponse.Requested, candleResponse.Elapsed.Round(time.Millisecond))
+	}
+
 	log.Printf("Successfully fetched %d candles for DOGEUSDT", len(candleResponse.Candles))
 	return candleResponse
 }
 
+// cacheClosedCandles salva nella cache le candele chiuse restituite da CandleResponse.Closed
+func (w *DogeTradingSystemWorker) cacheClosedCandles(closedCandles []models.Candle) {
+	if len(closedCandles) == 0 {
+		return
+	}
+
+	records := make([]*models.CandleRecord, 0, len(closedCandles))
+	for _, candle := range closedCandles {
+		records = append(records, models.NewCandleRecord("DOGEUSDT", models.DerivativesMarket, models.Timeframe1m, candle))
+	}
+	if err := w.repoManager.Candle().CreateBatch(w.ctx, records); err != nil {
+		log.Printf("⚠️  Errore salvataggio cache candele: %v", err)
+	}
+}
+
 // ========================================
 // FASE 2: Calcolo degli indicatori tecnici
 // ========================================
 
-// calculateTechnicalIndicators calcola gli indicatori tecnici necessari
+// calculateTechnicalIndicators calcola gli indicatori tecnici con la pipeline taprocess,
+// ognuno sulla sorgente di prezzo configurata (TA_PRICE_SOURCES)
 func (w *DogeTradingSystemWorker) calculateTechnicalIndicators(candleResponse *models.CandleResponse) []*models.TACandlestick {
 	log.Printf("Calculating technical indicators for %d candles...", len(candleResponse.Candles))
 
-	// Verifica che ci siano abbastanza candele per calcolare l'RSI (minimo 14)
-	if len(candleResponse.Candles) < 14 {
-		log.Printf("Not enough candles for RSI calculation. Need at least 14, got %d", len(candleResponse.Candles))
+	// Verifica che ci siano abbastanza candele per calcolare l'RSI
+	if len(candleResponse.Candles) < w.indicators.RSIPeriod {
+		log.Printf("Not enough candles for RSI calculation. Need at least %d, got %d", w.indicators.RSIPeriod, len(candleResponse.Candles))
 		return nil
 	}
 
-	// Estrai i prezzi di chiusura per il calcolo dell'RSI
-	closePrices := make([]float64, len(candleResponse.Candles))
-	for i, candle := range candleResponse.Candles {
-		closePrices[i] = candle.Close
-	}
-
-	// Calcola l'RSI con periodo 14
-	rsiValues := talib.Rsi(closePrices, 14)
-
-	// Crea le TACandlestick con i dati OHLCV e l'RSI calcolato
-	taCandlesticks := make([]*models.TACandlestick, len(candleResponse.Candles))
-
-	for i, candle := range candleResponse.Candles {
-		taCandlestick := models.NewTACandlestickFromCandle(candle)
-
-		// Imposta l'RSI14 se disponibile (i primi 13 valori saranno NaN)
-		if i >= 13 && i < len(rsiValues) {
-			taCandlestick.RSI14 = &rsiValues[i]
-		}
-
-		taCandlesticks[i] = taCandlestick
+	taCandlesticks, err := w.indicators.ProcessCandlesWithIndicators(candleResponse.Candles)
+	if err != nil {
+		log.Printf("Errore nel calcolo degli indicatori: %v", err)
+		return nil
 	}
 
 	// Conta quante candele hanno l'RSI calcolato
@@ -412,7 +981,7 @@ func (w *DogeTradingSystemWorker) calculateTechnicalIndicators(candleResponse *m
 		}
 	}
 
-	log.Printf("RSI calculation completed. %d candles have valid RSI values", validRSICount)
+	log.Printf("RSI calculation completed (source %s). %d candles have valid RSI values", w.indicators.Sources.RSI, validRSICount)
 	return taCandlesticks
 }
 
@@ -420,17 +989,19 @@ func (w *DogeTradingSystemWorker) calculateTechnicalIndicators(candleResponse *m
 // FASE 3: Controlli per condizioni di trading
 // ========================================
 
+// wallCandles è il numero di candele chiuse, precedenti all'ultima, da cui si calcolano muro e supporto
+const wallCandles = 72
+
 // extractCandlesForChecks estrae le ultime 40 candele al momento
 func (w *DogeTradingSystemWorker) extractCandlesForChecks(taCandlesticks []models.Candle) ([]models.Candle, float64, float64, error) {
-	// Le candele sono già in ordine cronologico (dalla più vecchia alla più recente)
-	// La candela più recente è quella attualmente aperta, quindi la escludiamo
+	// Le candele sono chiuse e in ordine cronologico (dalla più vecchia alla più recente)
 
-	if len(taCandlesticks) < 72 {
-		return nil, 0.0, 0.0, fmt.Errorf("not enough candles for checks. Need at least 5, got %d", len(taCandlesticks))
+	if len(taCandlesticks) < wallCandles+1 {
+		return nil, 0.0, 0.0, fmt.Errorf("not enough candles for checks. Need at least %d, got %d", wallCandles+1, len(taCandlesticks))
 	}
 
-	// Estrai le ultime 5 candele chiuse (escludendo quella attualmente aperta)
-	last72Candles := taCandlesticks[len(taCandlesticks)-74 : len(taCandlesticks)-2]
+	// Estrai le candele chiuse del muro (escludendo l'ultima chiusa, che è quella da confrontare)
+	last72Candles := taCandlesticks[len(taCandlesticks)-wallCandles-1 : len(taCandlesticks)-1]
 
 	// Prendo il massimo high delle ultime 5 candele chiuse
 	last72CandlesWall := 0.0
@@ -459,7 +1030,7 @@ func (w *DogeTradingSystemWorker) extractCandlesForChecks(taCandlesticks []model
 func (w *DogeTradingSystemWorker) checkWallAndSupportBreak(currentClosedCandle models.Candle, lastFiveCandles []models.Candle, wall float64, support float64) (bool, bool) {
 	log.Printf("Checking wall break...")
 
-	if len(lastFiveCandles) < 72 {
+	if len(lastFiveCandles) < wallCandles {
 		log.Println("Not enough candles for wall break check")
 		return false, false
 	}
@@ -485,7 +1056,9 @@ func (w *DogeTradingSystemWorker) checkWallAndSupportBreak(currentClosedCandle m
 // ========================================
 
 // placeLongOrder piazza un ordine LONG
-func (w *DogeTradingSystemWorker) placeLongOrder(currentPrice float64) string {
+// sizeMultiplier è la frazione della quantità massima da usare (1 = intero saldo disponibile);
+// closedCandles servono al posizionamento dello stop sotto lo swing o chandelier
+func (w *DogeTradingSystemWorker) placeLongOrder(currentPrice, sizeMultiplier float64, closedCandles []models.Candle) string {
 	log.Println("Placing LONG order...")
 
 	// Verifica che il processor sia disponibile
@@ -505,7 +1078,7 @@ func (w *DogeTradingSystemWorker) placeLongOrder(currentPrice float64) string {
 	}
 
 	// Calcola la quantità massima basata sul saldo disponibile
-	quantity := w.calculateMaxQuantity(triggerPrice)
+	quantity := w.calculateMaxQuantity(triggerPrice) * sizeMultiplier
 	if quantity <= 0 {
 		log.Println("ERRORE: Impossibile calcolare la quantità")
 		return ""
@@ -514,6 +1087,9 @@ func (w *DogeTradingSystemWorker) placeLongOrder(currentPrice float64) string {
 	longTriggerPrice := currentPrice
 	takeProfit := w.calculateLongTakeProfit(currentPrice, 0.03)
 	stopLoss := w.calculateLongStopLoss(currentPrice, 0.008)
+	if placed, ok := w.stops.StopLoss(models.OrderSideBuy, closedCandles, currentPrice); ok {
+		stopLoss = placed
+	}
 
 	log.Printf("Parametri ordine LONG:")
 	log.Printf("  Symbol: %s", symbol)
@@ -523,30 +1099,24 @@ func (w *DogeTradingSystemWorker) placeLongOrder(currentPrice float64) string {
 	log.Printf("  Take Profit: $%.6f (%.3f%%)", takeProfit, 0.5)
 	log.Printf("  Valore ordine: $%.2f", triggerPrice*quantity)
 
-	longOrder, err := w.orderProcessor.PlaceLongOrder(
-		w.ctx,
-		symbol,
-		longTriggerPrice, // trigger price
-		quantity,         // quantity
-		stopLoss,         // stop loss
-		takeProfit,       // take profit
-	)
-
-	if err != nil {
-		log.Printf("ERRORE nel piazzamento ordine LONG: %v", err)
+	if !w.orderAllowed(symbol, models.OrderSideBuy, longTriggerPrice, quantity) {
 		return ""
 	}
+	quantity, plan := w.slicePlan(symbol, models.OrderSideBuy, quantity)
 
-	if !longOrder.IsSuccess() {
-		log.Printf("ERRORE: Ordine rifiutato - %s (codice: %s)",
-			longOrder.ErrorMessage, longOrder.ErrorCode)
+	report, err := w.execute(symbol, "long", models.OrderSideBuy, longTriggerPrice, quantity, stopLoss, takeProfit)
+	if err != nil {
+		correlation.Logf(w.ctx, "ERRORE nel piazzamento ordine LONG: %v", err)
+		w.recordRejectedOrder(symbol, models.OrderSideTypeBuy, longTriggerPrice, quantity, takeProfit, stopLoss, err)
 		return ""
 	}
+	longOrder := report.Order()
+	latency := w.signalLatency(symbol, closedCandles)
 
-	log.Printf("✅ Ordine LONG piazzato con successo!")
-	log.Printf("  OrderID: %s", longOrder.OrderID)
-	log.Printf("  OrderLinkID: %s", longOrder.OrderLinkID)
-	log.Printf("  Status: %s", longOrder.Status)
+	correlation.Logf(w.ctx, "✅ Ordine LONG piazzato con successo!")
+	correlation.Logf(w.ctx, "  OrderID: %s", longOrder.OrderID)
+	correlation.Logf(w.ctx, "  OrderLinkID: %s", longOrder.OrderLinkID)
+	correlation.Logf(w.ctx, "  Status: %s", longOrder.Status)
 
 	// ========================================
 	// SALVATAGGIO NEL DATABASE
@@ -556,35 +1126,42 @@ func (w *DogeTradingSystemWorker) placeLongOrder(currentPrice float64) string {
 	// Crea l'ordine dal BybitOrderResponse
 	dbOrder, err := w.createOrderFromBybitResponse(
 		longOrder,
-		longTriggerPrice,
-		quantity,
+		models.OrderSideTypeBuy,
+		report.Price,
+		report.Quantity,
 		takeProfit,
 		stopLoss,
 	)
 	if err != nil {
-		log.Printf("❌ ERRORE: Impossibile creare ordine per database: %v", err)
-		log.Println("⚠️  ATTENZIONE: Ordine piazzato su Bybit ma NON salvato nel database!")
+		correlation.Logf(w.ctx, "❌ ERRORE: Impossibile creare ordine per database: %v", err)
+		correlation.Logf(w.ctx, "⚠️  ATTENZIONE: Ordine piazzato su Bybit ma NON salvato nel database!")
 		return longOrder.OrderID // Ritorna comunque l'ID per continuare il monitoraggio
 	}
+	dbOrder.SignalLatencyMs = &latency
 
 	// Salva nel database
 	if err := w.saveOrderToDatabase(dbOrder); err != nil {
-		log.Printf("❌ ERRORE: Impossibile salvare ordine nel database: %v", err)
-		log.Println("⚠️  ATTENZIONE: Ordine piazzato su Bybit ma NON salvato nel database!")
+		correlation.Logf(w.ctx, "❌ ERRORE: Impossibile salvare ordine nel database: %v", err)
+		correlation.Logf(w.ctx, "⚠️  ATTENZIONE: Ordine piazzato su Bybit ma NON salvato nel database!")
 		return longOrder.OrderID // Ritorna comunque l'ID per continuare il monitoraggio
 	}
 
-	log.Printf("✅ Ordine salvato nel database con successo!")
+	correlation.Logf(w.ctx, "✅ Ordine salvato nel database con successo!")
 
 	// Imposta la flag orderPlaced a true
 	w.orderPlaced = true
 	log.Println("🔄 Flag orderPlaced impostata a true")
 
+	w.scheduleUnfilledCancel(longOrder.OrderID)
+	w.startSlicing(dbOrder, plan)
+
 	return longOrder.OrderID
 }
 
 // placeShortOrder piazza un ordine SHORT
-func (w *DogeTradingSystemWorker) placeShortOrder(currentPrice float64) string {
+// sizeMultiplier è la frazione della quantità massima da usare (1 = intero saldo disponibile);
+// closedCandles servono al posizionamento dello stop sopra lo swing o chandelier
+func (w *DogeTradingSystemWorker) placeShortOrder(currentPrice, sizeMultiplier float64, closedCandles []models.Candle) string {
 	log.Println("Placing SHORT order...")
 
 	// Verifica che il processor sia disponibile
@@ -604,7 +1181,7 @@ func (w *DogeTradingSystemWorker) placeShortOrder(currentPrice float64) string {
 	}
 
 	// Calcola la quantità massima basata sul saldo disponibile
-	quantity := w.calculateMaxQuantity(triggerPrice)
+	quantity := w.calculateMaxQuantity(triggerPrice) * sizeMultiplier
 	if quantity <= 0 {
 		log.Println("ERRORE: Impossibile calcolare la quantità")
 		return ""
@@ -612,6 +1189,9 @@ func (w *DogeTradingSystemWorker) placeShortOrder(currentPrice float64) string {
 
 	takeProfit := w.calculateShortTakeProfit(currentPrice, 0.03)
 	stopLoss := w.calculateShortStopLoss(currentPrice, 0.008)
+	if placed, ok := w.stops.StopLoss(models.OrderSideSell, closedCandles, currentPrice); ok {
+		stopLoss = placed
+	}
 
 	log.Printf("Parametri ordine SHORT:")
 	log.Printf("  Symbol: %s", symbol)
@@ -623,30 +1203,24 @@ func (w *DogeTradingSystemWorker) placeShortOrder(currentPrice float64) string {
 
 	shortTriggerPrice := currentPrice
 
-	shortOrder, err := w.orderProcessor.PlaceShortOrder(
-		w.ctx,
-		symbol,
-		shortTriggerPrice, // trigger price
-		quantity,          // quantity
-		stopLoss,          // stop loss
-		takeProfit,        // take profit
-	)
-
-	if err != nil {
-		log.Printf("ERRORE nel piazzamento ordine SHORT: %v", err)
+	if !w.orderAllowed(symbol, models.OrderSideSell, shortTriggerPrice, quantity) {
 		return ""
 	}
+	quantity, plan := w.slicePlan(symbol, models.OrderSideSell, quantity)
 
-	if !shortOrder.IsSuccess() {
-		log.Printf("ERRORE: Ordine rifiutato - %s (codice: %s)",
-			shortOrder.ErrorMessage, shortOrder.ErrorCode)
+	report, err := w.execute(symbol, "short", models.OrderSideSell, shortTriggerPrice, quantity, stopLoss, takeProfit)
+	if err != nil {
+		correlation.Logf(w.ctx, "ERRORE nel piazzamento ordine SHORT: %v", err)
+		w.recordRejectedOrder(symbol, models.OrderSideTypeSell, shortTriggerPrice, quantity, takeProfit, stopLoss, err)
 		return ""
 	}
+	shortOrder := report.Order()
+	latency := w.signalLatency(symbol, closedCandles)
 
-	log.Printf("✅ Ordine SHORT piazzato con successo!")
-	log.Printf("  OrderID: %s", shortOrder.OrderID)
-	log.Printf("  OrderLinkID: %s", shortOrder.OrderLinkID)
-	log.Printf("  Status: %s", shortOrder.Status)
+	correlation.Logf(w.ctx, "✅ Ordine SHORT piazzato con successo!")
+	correlation.Logf(w.ctx, "  OrderID: %s", shortOrder.OrderID)
+	correlation.Logf(w.ctx, "  OrderLinkID: %s", shortOrder.OrderLinkID)
+	correlation.Logf(w.ctx, "  Status: %s", shortOrder.Status)
 
 	// ========================================
 	// SALVATAGGIO NEL DATABASE
@@ -656,34 +1230,92 @@ func (w *DogeTradingSystemWorker) placeShortOrder(currentPrice float64) string {
 	// Crea l'ordine dal BybitOrderResponse
 	dbOrder, err := w.createOrderFromBybitResponse(
 		shortOrder,
-		shortTriggerPrice,
-		quantity,
+		models.OrderSideTypeSell,
+		report.Price,
+		report.Quantity,
 		takeProfit,
 		stopLoss,
 	)
 
 	if err != nil {
-		log.Printf("❌ ERRORE: Impossibile creare ordine per database: %v", err)
-		log.Println("⚠️  ATTENZIONE: Ordine piazzato su Bybit ma NON salvato nel database!")
+		correlation.Logf(w.ctx, "❌ ERRORE: Impossibile creare ordine per database: %v", err)
+		correlation.Logf(w.ctx, "⚠️  ATTENZIONE: Ordine piazzato su Bybit ma NON salvato nel database!")
 		return shortOrder.OrderID // Ritorna comunque l'ID per continuare il monitoraggio
 	}
+	dbOrder.SignalLatencyMs = &latency
 
 	// Salva nel database
 	if err := w.saveOrderToDatabase(dbOrder); err != nil {
-		log.Printf("❌ ERRORE: Impossibile salvare ordine nel database: %v", err)
-		log.Println("⚠️  ATTENZIONE: Ordine piazzato su Bybit ma NON salvato nel database!")
+		correlation.Logf(w.ctx, "❌ ERRORE: Impossibile salvare ordine nel database: %v", err)
+		correlation.Logf(w.ctx, "⚠️  ATTENZIONE: Ordine piazzato su Bybit ma NON salvato nel database!")
 		return shortOrder.OrderID // Ritorna comunque l'ID per continuare il monitoraggio
 	}
 
-	log.Printf("✅ Ordine salvato nel database con successo!")
+	correlation.Logf(w.ctx, "✅ Ordine salvato nel database con successo!")
 
 	// Imposta la flag orderPlaced a true
 	w.orderPlaced = true
 	log.Println("🔄 Flag orderPlaced impostata a true")
 
+	w.scheduleUnfilledCancel(shortOrder.OrderID)
+	w.startSlicing(dbOrder, plan)
+
 	return shortOrder.OrderID
 }
 
+// slicePlan decide se frazionare l'ingresso e restituisce la quantità da inviare subito
+// Se la liquidità al miglior prezzo non è disponibile l'ingresso viene inviato intero
+func (w *DogeTradingSystemWorker) slicePlan(symbol string, side models.OrderSide, quantity float64) (float64, *services.SlicePlan) {
+	if w.slicer == nil {
+		return quantity, nil
+	}
+	plan, err := w.slicer.Plan(w.ctx, symbol, side, quantity)
+	if err != nil {
+		log.Printf("⚠️  Frazionamento non valutato, ingresso inviato intero: %v", err)
+		return quantity, nil
+	}
+	if plan == nil {
+		return quantity, nil
+	}
+	correlation.Logf(w.ctx, "🧩 Ingresso %s %s di %.2f oltre la liquidità al miglior prezzo (%.2f): primo figlio di %.2f",
+		side, symbol, quantity, plan.Liquidity, plan.First)
+	return plan.First, plan
+}
+
+// startSlicing registra l'ordine logico dell'ingresso frazionato e pianifica la frazione successiva
+// Le frazioni sono job della coda persistente, quindi proseguono anche dopo un riavvio del bot
+func (w *DogeTradingSystemWorker) startSlicing(entry *models.Order, plan *services.SlicePlan) {
+	if plan == nil {
+		return
+	}
+	order, err := w.slicer.Start(w.ctx, entry, plan)
+	if err != nil {
+		correlation.Logf(w.ctx, "❌ Frazionamento dell'ingresso %s non avviato, resta la sola prima frazione: %v", entry.OrderID, err)
+		return
+	}
+	if err := scheduleOrderSlice(w.ctx, w.jobs, order.ID, w.slicer.Interval()); err != nil {
+		correlation.Logf(w.ctx, "❌ %v", err)
+	}
+}
+
+// scheduleUnfilledCancel accoda la cancellazione dell'ordine se non viene eseguito entro cancelAfter
+// Il job è salvato nel database, quindi il controllo avviene anche dopo un riavvio del bot
+func (w *DogeTradingSystemWorker) scheduleUnfilledCancel(orderID string) {
+	if w.jobs == nil || w.cancelAfter <= 0 {
+		return
+	}
+	job, err := w.jobs.Schedule(w.ctx, JobCancelUnfilledOrder, cancelUnfilledOrderPayload{
+		Exchange: "bybit",
+		Symbol:   "DOGEUSDT",
+		OrderID:  orderID,
+	}, w.cancelAfter)
+	if err != nil {
+		correlation.Logf(w.ctx, "❌ Impossibile pianificare il controllo dell'ordine %s: %v", orderID, err)
+		return
+	}
+	correlation.Logf(w.ctx, "⏲️  Controllo dell'ordine %s pianificato alle %s (job %d)", orderID, job.RunAt.Local().Format("15:04:05"), job.ID)
+}
+
 // ========================================
 // FASE 3.1.1: Monitoraggio ordine dopo 5 minuti
 // ========================================
@@ -765,7 +1397,7 @@ func (w *DogeTradingSystemWorker) isPostionActive(symbol string) (bool, error) {
 		log.Printf("Error getting order: %v", err2)
 		return false, err2
 	}
-	log.Printf("Position Status: %s", positions)
+	log.Printf("Position Status: %v", positions)
 	// Se la posizione è attiva vuol dire che l'ordine è stato piazzato correttamente e chequindi devo aggioranre il DB
 	if len(positions) > 0 {
 		orderID := ""
@@ -805,11 +1437,19 @@ func (w *DogeTradingSystemWorker) calculateMaxQuantity(price float64) float64 {
 		return 0
 	}
 
-	availableBalance := usdtBalance
-	quantity := availableBalance / price
+	// Il worker opera solo sul suo budget virtuale, se configurato
+	availableBalance, err := w.budgets.Available(w.ctx, "doge-trading-system", usdtBalance)
+	if err != nil {
+		log.Printf("Errore nel recupero del budget del worker: %v", err)
+		return 0
+	}
+	sizingCapital := w.sizer.Capital("doge-trading-system", availableBalance)
+	// Divisione decimale: sulle monete con prezzo basso la quantità non eredita errori di rappresentazione
+	quantity := models.Decimal(sizingCapital).Div(models.Decimal(price)).InexactFloat64()
 
 	log.Printf("Saldo USDT disponibile: %.2f", usdtBalance)
-	log.Printf("Saldo utilizzabile (90%%): %.2f", availableBalance)
+	log.Printf("Saldo utilizzabile (budget del worker): %.2f", availableBalance)
+	log.Printf("Capitale per il dimensionamento: %.2f", sizingCapital)
 	log.Printf("Quantità calcolata: %.2f", quantity)
 
 	return quantity
@@ -843,14 +1483,14 @@ func (w *DogeTradingSystemWorker) calculateGreenCandlesAverageVolume(taCandlesti
 	generalCandlesAverageVolume := 0.0
 
 	ratio := 0.0
-	for i := len(taCandlesticks) - 2; i > 0 && greenCandlesCount < 10; i-- {
+	for i := len(taCandlesticks) - 1; i > 0 && greenCandlesCount < 10; i-- {
 		if taCandlesticks[i].Close > taCandlesticks[i].Open {
 			greenCandlesAverageVolume += taCandlesticks[i].Volume
 			greenCandlesCount++
 		}
 	}
 
-	for i := len(taCandlesticks) - 2; i > 0 && greenCandlesCount < 10; i-- {
+	for i := len(taCandlesticks) - 1; i > 0 && greenCandlesCount < 10; i-- {
 		greenCandlesAverageVolume += taCandlesticks[i].Volume
 		generalCandlesCount++
 	}
@@ -866,14 +1506,14 @@ func (w *DogeTradingSystemWorker) calculateRedCandlesAverageVolume(taCandlestick
 	generalCandlesAverageVolume := 0.0
 
 	ratio := 0.0
-	for i := len(taCandlesticks) - 2; i > 0 && redCandlesCount < 10; i-- {
+	for i := len(taCandlesticks) - 1; i > 0 && redCandlesCount < 10; i-- {
 		if taCandlesticks[i].Close < taCandlesticks[i].Open {
 			redCandlesAverageVolume += taCandlesticks[i].Volume
 			redCandlesCount++
 		}
 	}
 
-	for i := len(taCandlesticks) - 2; i > 0 && generalCandlesCount < 10; i-- {
+	for i := len(taCandlesticks) - 1; i > 0 && generalCandlesCount < 10; i-- {
 		generalCandlesAverageVolume += taCandlesticks[i].Volume
 		generalCandlesCount++
 	}
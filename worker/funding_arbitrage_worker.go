@@ -0,0 +1,563 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"cross-exchange-arbitrage/calendar"
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/correlation"
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/events"
+	"cross-exchange-arbitrage/exchange"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/risk"
+	"cross-exchange-arbitrage/services"
+	"cross-exchange-arbitrage/strategy"
+)
+
+// FundingArbitrageWorker gestisce una posizione coperta long spot / short perpetual su due venue
+// Ad ogni ciclo registra basis e funding, accumula il funding stimato e decide ingresso e uscita
+type FundingArbitrageWorker struct {
+	cycleContext // Contesto del ciclo corrente, con l'identificativo di correlazione
+	cancel       context.CancelFunc
+	cfg          config.FundingArbConfig
+	strategy     *strategy.FundingArbitrageStrategy
+	spotPrices   exchange.SpotPriceProvider
+	spotOrders   orderprocessor.SpotOrderProcessor
+	perpPrices   exchange.Exchange
+	perpFunding  exchange.FundingRateProvider
+	perpOrders   orderprocessor.OrderProcessor
+	repoManager  repositories.RepositoryManager
+	blackout     *calendar.Blackout              // Finestre di blackout attorno agli eventi macro; nil se disabilitato
+	events       *events.Emitter                 // Pubblicazione degli eventi di trading; nil se disabilitata
+	maxDataAge   time.Duration                   // Età massima dei prezzi usati per operare; 0 se disabilitato
+	priceCheck   *risk.PriceChecker              // Confronto dei prezzi delle due gambe con una seconda fonte; nil se disabilitato
+	risk         *risk.Manager                   // Limiti di rischio globali (es. simboli esclusi dal trading)
+	spotAccount  orderprocessor.AccountReader    // Saldo della venue spot, usato per il budget del worker
+	budgets      *services.BudgetService         // Quota virtuale del saldo assegnata al worker
+	throttle     *orderprocessor.OrderThrottle   // Limite di ordini per simbolo e account; nil se disabilitato
+	spread       *risk.SpreadGuard               // Controllo dello spread delle due gambe all'apertura; nil se disabilitato
+	borrowRates  orderprocessor.BorrowRateReader // Tassi di prestito della venue spot; nil se l'account non è unificato
+}
+
+// fundingMarket contiene i dati di mercato letti in un ciclo
+type fundingMarket struct {
+	spot    *models.RealTimePriceData
+	perp    *models.RealTimePriceData
+	funding *models.FundingRate
+	borrow  *models.BorrowRate // Tasso di prestito della quote coin sulla venue spot; nil se non disponibile
+	basis   float64
+}
+
+// NewFundingArbitrageWorker crea il worker risolvendo le venue configurate tra le dipendenze
+// Restituisce errore se una venue non supporta la gamba richiesta o non ha credenziali
+func NewFundingArbitrageWorker(deps *SystemDependencies) (*FundingArbitrageWorker, error) {
+	cfg := deps.Config.FundingArb
+
+	arbStrategy, err := strategy.NewFundingArbitrageStrategy(strategy.FundingArbitrageParams{
+		EntryAPR:      cfg.EntryAPR,
+		ExitAPR:       cfg.ExitAPR,
+		MinEntryBasis: cfg.MinEntryBasis,
+		MaxBasis:      cfg.MaxBasis,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	spotPrices, ok := deps.Exchanges[cfg.SpotVenue].(exchange.SpotPriceProvider)
+	if !ok {
+		return nil, fmt.Errorf("la venue %s non fornisce prezzi spot", cfg.SpotVenue)
+	}
+	// Con un sub-account dedicato su Bybit la strategia opera con la key del sub-account
+	spotProcessor := deps.StrategyProcessor("funding-arbitrage", cfg.SpotVenue)
+	spotOrders, ok := spotProcessor.(orderprocessor.SpotOrderProcessor)
+	if !ok {
+		return nil, fmt.Errorf("la venue %s non supporta ordini spot o non ha credenziali", cfg.SpotVenue)
+	}
+	perpPrices, ok := deps.Exchanges[cfg.PerpVenue]
+	if !ok {
+		return nil, fmt.Errorf("venue perpetual %s non configurata", cfg.PerpVenue)
+	}
+	perpFunding, ok := perpPrices.(exchange.FundingRateProvider)
+	if !ok {
+		return nil, fmt.Errorf("la venue %s non fornisce il tasso di funding", cfg.PerpVenue)
+	}
+	perpOrders := deps.StrategyProcessor("funding-arbitrage", cfg.PerpVenue)
+	if perpOrders == nil {
+		return nil, fmt.Errorf("la venue %s non ha credenziali per gli ordini", cfg.PerpVenue)
+	}
+
+	priceCheck, err := newPriceChecker(deps, cfg.PerpVenue)
+	if err != nil {
+		return nil, err
+	}
+
+	// Il costo del prestito conta solo se l'account spot è unificato e può comprare a margine
+	var borrowRates orderprocessor.BorrowRateReader
+	if deps.Fees.IsUnified(cfg.SpotVenue) {
+		borrowRates, _ = spotProcessor.(orderprocessor.BorrowRateReader)
+	}
+
+	// Le modifiche fatte dal worker vengono attribuite al worker nell'audit trail
+	ctx, cancel := context.WithCancel(database.WithChangedBy(context.Background(), "funding-arbitrage"))
+
+	return &FundingArbitrageWorker{
+		cycleContext: newCycleContext(ctx),
+		cancel:       cancel,
+		cfg:          cfg,
+		strategy:     arbStrategy,
+		spotPrices:   spotPrices,
+		spotOrders:   spotOrders,
+		perpPrices:   perpPrices,
+		perpFunding:  perpFunding,
+		perpOrders:   perpOrders,
+		repoManager:  deps.RepoManager,
+		blackout:     deps.Blackout,
+		events:       deps.Events,
+		maxDataAge:   deps.Config.Risk.MaxDataAge,
+		priceCheck:   priceCheck,
+		risk:         deps.Risk,
+		spotAccount:  spotProcessor,
+		budgets:      deps.Budgets,
+		throttle:     deps.Throttle,
+		spread:       deps.Spread,
+		borrowRates:  borrowRates,
+	}, nil
+}
+
+// ExecuteTradingCycle esegue un ciclo della strategia di funding arbitrage
+func (w *FundingArbitrageWorker) ExecuteTradingCycle() {
+	ctx, cancel := context.WithTimeout(w.ctx, 2*time.Minute)
+	defer cancel()
+
+	market, err := w.fetchMarket(ctx)
+	if errors.Is(err, risk.ErrStaleData) {
+		correlation.Logf(ctx, "⏸️  Funding arbitrage: ciclo saltato, nessun ordine con prezzi non aggiornati: %v", err)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Funding arbitrage: %v", err)
+		return
+	}
+
+	positions, err := w.repoManager.FundingArb().GetActive(ctx, w.cfg.Symbol)
+	if err != nil {
+		log.Printf("❌ Funding arbitrage: errore lettura posizioni attive: %v", err)
+		return
+	}
+
+	var position *models.FundingArbPosition
+	if len(positions) > 0 {
+		position = positions[0]
+	}
+
+	w.recordSnapshot(ctx, market, position)
+
+	fundingAPR := market.funding.AnnualizedRate()
+	log.Printf("Funding arbitrage %s: spot %.6f (%s), perp %.6f (%s), basis %.4f%%, funding APR %.2f%%",
+		w.cfg.Symbol, market.spot.Price, w.cfg.SpotVenue, market.perp.Price, w.cfg.PerpVenue,
+		market.basis*100, fundingAPR*100)
+
+	if position == nil {
+		decision := w.strategy.Evaluate(w.carryAPR(ctx, market, nil), market.basis, false)
+		correlation.Logf(ctx, "Decisione: %s (%s)", decision.Signal, decision.Reason)
+		if decision.Signal == strategy.FundingSignalEnter {
+			// Le uscite restano attive durante il blackout, gli ingressi no
+			if _, inBlackout := activeBlackout(ctx, w.blackout); inBlackout {
+				return
+			}
+			if !w.pricesConfirmed(ctx, market) || !w.withinBudget(ctx, market) {
+				return
+			}
+			// Il risk manager applica liste dei simboli e limite di posizioni agli ingressi; le uscite restano sempre possibili
+			release, err := w.risk.ReservePosition(ctx, w.cfg.Symbol)
+			if err != nil {
+				correlation.Logf(ctx, "⏸️  Funding arbitrage: ingresso bloccato: %v", err)
+				return
+			}
+			defer release()
+			w.openPosition(ctx, market)
+		}
+		return
+	}
+
+	switch position.Status {
+	case models.FundingArbStatusOpen:
+		w.accrueCarry(ctx, position, market)
+		decision := w.strategy.Evaluate(w.carryAPR(ctx, market, position), market.basis, true)
+		correlation.Logf(ctx, "Decisione posizione #%d: %s (%s)", position.ID, decision.Signal, decision.Reason)
+		if decision.Signal == strategy.FundingSignalExit && w.pricesConfirmed(ctx, market) {
+			w.closePosition(ctx, position, market, decision.Reason)
+		}
+	case models.FundingArbStatusClosing:
+		// Il perpetual è già chiuso: ritenta la vendita della gamba spot
+		log.Printf("⚠️  Posizione #%d in chiusura, ritento la vendita spot", position.ID)
+		w.sellSpotLeg(ctx, position, market)
+	default:
+		log.Printf("⚠️  Posizione #%d in stato %s: richiede intervento manuale (%s)", position.ID, position.Status, position.Note)
+	}
+}
+
+// fetchMarket legge prezzo spot, prezzo perpetual e tasso di funding
+// Restituisce risk.ErrStaleData se uno dei due prezzi è più vecchio di maxDataAge
+func (w *FundingArbitrageWorker) fetchMarket(ctx context.Context) (*fundingMarket, error) {
+	spot, err := w.spotPrices.GetSpotPrice(ctx, w.cfg.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("errore prezzo spot %s: %w", w.cfg.SpotVenue, err)
+	}
+	perp, err := w.perpPrices.GetRealTimePrice(ctx, w.cfg.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("errore prezzo perpetual %s: %w", w.cfg.PerpVenue, err)
+	}
+	funding, err := w.perpFunding.GetFundingRate(ctx, w.cfg.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("errore funding %s: %w", w.cfg.PerpVenue, err)
+	}
+	if spot.Price <= 0 || perp.Price <= 0 {
+		return nil, fmt.Errorf("prezzi non validi (spot %.6f, perp %.6f)", spot.Price, perp.Price)
+	}
+	if err := risk.CheckPrice(spot, w.maxDataAge); err != nil {
+		return nil, fmt.Errorf("gamba spot: %w", err)
+	}
+	if err := risk.CheckPrice(perp, w.maxDataAge); err != nil {
+		return nil, fmt.Errorf("gamba perpetual: %w", err)
+	}
+
+	return &fundingMarket{
+		spot:    spot,
+		perp:    perp,
+		funding: funding,
+		borrow:  w.fetchBorrowRate(ctx),
+		basis:   models.CalculateBasis(spot.Price, perp.Price),
+	}, nil
+}
+
+// fetchBorrowRate legge il tasso di prestito della quote coin sulla venue spot
+// Una lettura fallita non blocca il ciclo: il costo del prestito viene ignorato fino alla lettura successiva
+func (w *FundingArbitrageWorker) fetchBorrowRate(ctx context.Context) *models.BorrowRate {
+	if w.borrowRates == nil {
+		return nil
+	}
+	rate, err := w.borrowRates.GetBorrowRate(ctx, "USDT")
+	if err != nil {
+		log.Printf("⚠️  Funding arbitrage: tasso di prestito %s non disponibile: %v", w.cfg.SpotVenue, err)
+		return nil
+	}
+	return rate
+}
+
+// borrowedNotional stima la parte del nozionale spot finanziata con il prestito
+// Con la posizione aperta è il prestito dell'account, al massimo il nozionale della posizione;
+// prima dell'ingresso è la parte dell'acquisto che supera il saldo USDT della venue spot
+func (w *FundingArbitrageWorker) borrowedNotional(ctx context.Context, market *fundingMarket, position *models.FundingArbPosition) (borrowed, notional float64) {
+	if market.borrow == nil {
+		return 0, 0
+	}
+	if position != nil {
+		notional = position.Quantity * market.spot.Price
+		return math.Min(market.borrow.BorrowAmount, notional), notional
+	}
+
+	notional = w.cfg.Quantity * market.spot.AskPrice
+	balance, err := w.spotAccount.GetUSDTBalance(ctx)
+	if err != nil {
+		log.Printf("⚠️  Funding arbitrage: saldo %s non disponibile, stimo l'acquisto interamente a prestito: %v", w.cfg.SpotVenue, err)
+		return notional, notional
+	}
+	return math.Max(notional-math.Max(balance, 0), 0), notional
+}
+
+// carryAPR restituisce il funding annualizzato al netto del costo del prestito della gamba spot
+func (w *FundingArbitrageWorker) carryAPR(ctx context.Context, market *fundingMarket, position *models.FundingArbPosition) float64 {
+	fundingAPR := market.funding.AnnualizedRate()
+	borrowed, notional := w.borrowedNotional(ctx, market, position)
+	if borrowed <= 0 || notional <= 0 {
+		return fundingAPR
+	}
+
+	carry := strategy.NetCarryAPR(fundingAPR, market.borrow.AnnualizedRate(), borrowed/notional)
+	correlation.Logf(ctx, "Funding arbitrage %s: %.2f USDT a prestito al %.2f%% annuo, rendimento netto %.2f%%",
+		w.cfg.Symbol, borrowed, market.borrow.AnnualizedRate()*100, carry*100)
+	return carry
+}
+
+// pricesConfirmed confronta i prezzi delle due gambe con la seconda fonte prima di aprire o chiudere la posizione
+// Il completamento di una chiusura già avviata non viene bloccato: lascerebbe il long spot scoperto
+func (w *FundingArbitrageWorker) pricesConfirmed(ctx context.Context, market *fundingMarket) bool {
+	for _, leg := range []*models.RealTimePriceData{market.spot, market.perp} {
+		if err := w.priceCheck.Check(ctx, w.cfg.Symbol, leg.Price); err != nil {
+			correlation.Logf(ctx, "⏸️  Funding arbitrage: ordini bloccati, prezzo %s non confermato: %v", leg.Exchange, err)
+			return false
+		}
+	}
+	return true
+}
+
+// withinBudget verifica che l'acquisto della gamba spot rientri nel budget virtuale del worker
+func (w *FundingArbitrageWorker) withinBudget(ctx context.Context, market *fundingMarket) bool {
+	if !w.budgets.HasBudget("funding-arbitrage") {
+		return true
+	}
+	balance, err := w.spotAccount.GetUSDTBalance(ctx)
+	if err != nil {
+		correlation.Logf(ctx, "⏸️  Funding arbitrage: saldo %s non disponibile: %v", w.cfg.SpotVenue, err)
+		return false
+	}
+	available, err := w.budgets.Available(ctx, "funding-arbitrage", balance)
+	if err != nil {
+		correlation.Logf(ctx, "⏸️  Funding arbitrage: %v", err)
+		return false
+	}
+	if notional := w.cfg.Quantity * market.spot.AskPrice; notional > available {
+		correlation.Logf(ctx, "⏸️  Funding arbitrage: ingresso da %.2f USDT oltre il budget disponibile (%.2f USDT)", notional, available)
+		return false
+	}
+	return true
+}
+
+// recordSnapshot salva la rilevazione di basis e funding del ciclo
+func (w *FundingArbitrageWorker) recordSnapshot(ctx context.Context, market *fundingMarket, position *models.FundingArbPosition) {
+	snapshot := &models.BasisSnapshot{
+		Symbol:        w.cfg.Symbol,
+		SpotExchange:  w.cfg.SpotVenue,
+		PerpExchange:  w.cfg.PerpVenue,
+		SpotPrice:     market.spot.Price,
+		PerpPrice:     market.perp.Price,
+		Basis:         market.basis,
+		FundingRate:   market.funding.Rate,
+		IntervalHours: market.funding.IntervalHours,
+		FundingAPR:    market.funding.AnnualizedRate(),
+		TakenAt:       time.Now().UTC(),
+	}
+	if position != nil {
+		snapshot.PositionID = &position.ID
+	}
+
+	if err := w.repoManager.BasisSnapshot().Create(ctx, snapshot); err != nil {
+		log.Printf("❌ Errore salvataggio snapshot basis: %v", err)
+	}
+}
+
+// accrueCarry aggiunge alla posizione il funding e gli interessi sul prestito stimati dall'ultimo aggiornamento
+func (w *FundingArbitrageWorker) accrueCarry(ctx context.Context, position *models.FundingArbPosition, market *fundingMarket) {
+	elapsed := time.Since(position.UpdatedAt).Hours()
+	accrued := strategy.AccruedFunding(position.Quantity, market.perp.Price, market.funding.Rate, elapsed, market.funding.IntervalHours)
+
+	var interest float64
+	if borrowed, _ := w.borrowedNotional(ctx, market, position); borrowed > 0 {
+		interest = strategy.AccruedBorrowInterest(borrowed, market.borrow.HourlyRate, elapsed)
+	}
+	if accrued == 0 && interest == 0 {
+		return
+	}
+
+	position.FundingCollected += accrued
+	position.BorrowCost += interest
+	if err := w.repoManager.FundingArb().Update(ctx, position); err != nil {
+		log.Printf("❌ Errore aggiornamento funding posizione #%d: %v", position.ID, err)
+		return
+	}
+	log.Printf("Posizione #%d: funding stimato %+.6f USDT (totale %.6f)", position.ID, accrued, position.FundingCollected)
+	if interest > 0 {
+		log.Printf("Posizione #%d: interessi sul prestito %.6f USDT (totale %.6f)", position.ID, interest, position.BorrowCost)
+	}
+}
+
+// openPosition apre la posizione coperta
+// Sequenza: acquisto spot e poi short perpetual. Se il perpetual fallisce la gamba spot viene rivenduta;
+// se anche la rivendita fallisce la posizione resta unhedged e richiede intervento manuale
+func (w *FundingArbitrageWorker) openPosition(ctx context.Context, market *fundingMarket) {
+	// Uno spread ampio su una delle due gambe può costare più del funding atteso
+	if err := w.spread.Check(ctx, w.cfg.Symbol, w.spotPrices.GetSpotPrice); err != nil {
+		correlation.Logf(ctx, "⏸️  Funding arbitrage: posizione non aperta, gamba spot %s: %v", w.cfg.SpotVenue, err)
+		return
+	}
+	if err := w.spread.Check(ctx, w.cfg.Symbol, w.perpPrices.GetRealTimePrice); err != nil {
+		correlation.Logf(ctx, "⏸️  Funding arbitrage: posizione non aperta, gamba perpetual %s: %v", w.cfg.PerpVenue, err)
+		return
+	}
+	// Il limite viene controllato per entrambe le gambe prima di aprire: una gamba scartata lascerebbe l'altra scoperta
+	if err := w.throttle.Acquire(ctx, w.cfg.SpotVenue, w.cfg.Symbol); err != nil {
+		return
+	}
+	if err := w.throttle.Acquire(ctx, w.cfg.PerpVenue, w.cfg.Symbol); err != nil {
+		return
+	}
+
+	position := &models.FundingArbPosition{
+		Symbol:          w.cfg.Symbol,
+		SpotExchange:    w.cfg.SpotVenue,
+		PerpExchange:    w.cfg.PerpVenue,
+		Status:          models.FundingArbStatusOpening,
+		Quantity:        w.cfg.Quantity,
+		EntryFundingAPR: market.funding.AnnualizedRate(),
+		OpenedAt:        time.Now().UTC(),
+	}
+	// La posizione viene registrata prima degli ordini: un crash tra le due gambe resta visibile
+	if err := w.repoManager.FundingArb().Create(ctx, position); err != nil {
+		correlation.Logf(ctx, "❌ Errore creazione posizione funding arbitrage: %v", err)
+		return
+	}
+
+	spotResp, err := orderResult(w.spotOrders.PlaceSpotMarketOrder(ctx, w.cfg.Symbol, models.OrderSideBuy, w.cfg.Quantity))
+	if err != nil {
+		w.finish(ctx, position, models.FundingArbStatusFailed, fmt.Sprintf("acquisto spot fallito: %v", err))
+		return
+	}
+	position.SpotOrderID = spotResp.OrderID
+	position.SpotEntryPrice = fillPrice(spotResp, market.spot.AskPrice)
+
+	perpResp, err := orderResult(w.perpOrders.PlaceShortOrder(ctx, w.cfg.Symbol, market.perp.BidPrice, w.cfg.Quantity, 0, 0))
+	if err != nil {
+		correlation.Logf(ctx, "❌ Short perpetual fallito, rivendo la gamba spot: %v", err)
+		note := fmt.Sprintf("short perpetual fallito: %v", err)
+		if _, unwindErr := orderResult(w.spotOrders.PlaceSpotMarketOrder(ctx, w.cfg.Symbol, models.OrderSideSell, w.cfg.Quantity)); unwindErr != nil {
+			w.finish(ctx, position, models.FundingArbStatusUnhedged, fmt.Sprintf("%s; rivendita spot fallita: %v", note, unwindErr))
+			return
+		}
+		w.finish(ctx, position, models.FundingArbStatusFailed, note)
+		return
+	}
+	position.PerpOrderID = perpResp.OrderID
+	position.PerpEntryPrice = fillPrice(perpResp, market.perp.BidPrice)
+	position.EntryBasis = models.CalculateBasis(position.SpotEntryPrice, position.PerpEntryPrice)
+	position.Status = models.FundingArbStatusOpen
+
+	if err := w.repoManager.FundingArb().Update(ctx, position); err != nil {
+		correlation.Logf(ctx, "❌ Errore aggiornamento posizione #%d: %v", position.ID, err)
+		return
+	}
+	correlation.Logf(ctx, "✅ Posizione #%d aperta: spot %.6f, perp %.6f, basis %.4f%%",
+		position.ID, position.SpotEntryPrice, position.PerpEntryPrice, position.EntryBasis*100)
+}
+
+// closePosition chiude la posizione coperta
+// Sequenza: prima si ricompra il perpetual (se fallisce la copertura resta intatta e si ritenta al ciclo
+// successivo), poi si vende lo spot. Se la vendita spot fallisce la posizione resta in closing
+func (w *FundingArbitrageWorker) closePosition(ctx context.Context, position *models.FundingArbPosition, market *fundingMarket, reason string) {
+	// In modalità one-way un ordine long della stessa quantità azzera lo short
+	perpResp, err := orderResult(w.perpOrders.PlaceLongOrder(ctx, w.cfg.Symbol, market.perp.AskPrice, position.Quantity, 0, 0))
+	if err != nil {
+		correlation.Logf(ctx, "❌ Chiusura perpetual posizione #%d fallita, ritento al prossimo ciclo: %v", position.ID, err)
+		return
+	}
+	perpExit := fillPrice(perpResp, market.perp.AskPrice)
+	position.PerpExitPrice = &perpExit
+	position.Status = models.FundingArbStatusClosing
+	position.Note = reason
+
+	if err := w.repoManager.FundingArb().Update(ctx, position); err != nil {
+		correlation.Logf(ctx, "❌ Errore aggiornamento posizione #%d: %v", position.ID, err)
+	}
+
+	w.sellSpotLeg(ctx, position, market)
+}
+
+// sellSpotLeg vende la gamba spot e completa la chiusura della posizione
+func (w *FundingArbitrageWorker) sellSpotLeg(ctx context.Context, position *models.FundingArbPosition, market *fundingMarket) {
+	spotResp, err := orderResult(w.spotOrders.PlaceSpotMarketOrder(ctx, w.cfg.Symbol, models.OrderSideSell, position.Quantity))
+	if err != nil {
+		correlation.Logf(ctx, "❌ Vendita spot posizione #%d fallita, long spot scoperto: %v", position.ID, err)
+		return
+	}
+
+	spotExit := fillPrice(spotResp, market.spot.BidPrice)
+	position.SpotExitPrice = &spotExit
+
+	perpExit := market.perp.AskPrice
+	if position.PerpExitPrice != nil {
+		perpExit = *position.PerpExitPrice
+	}
+	exitBasis := models.CalculateBasis(spotExit, perpExit)
+	pnl := position.CalculatePnL(spotExit, perpExit)
+	position.ExitBasis = &exitBasis
+	position.PnL = &pnl
+
+	w.finish(ctx, position, models.FundingArbStatusClosed, position.Note)
+	correlation.Logf(ctx, "✅ Posizione #%d chiusa: PnL %.6f USDT (funding %.6f, interessi %.6f)", position.ID, pnl, position.FundingCollected, position.BorrowCost)
+	if err := w.budgets.RecordPnL(ctx, "funding-arbitrage", pnl); err != nil {
+		log.Printf("⚠️  Budget: %v", err)
+	}
+	w.emitClosed(position, spotExit, pnl)
+}
+
+// emitClosed pubblica position.closed per la posizione coperta; prezzi e quantità sono quelli della gamba spot
+func (w *FundingArbitrageWorker) emitClosed(position *models.FundingArbPosition, spotExit, pnl float64) {
+	result := models.OrderResultProfit
+	if pnl < 0 {
+		result = models.OrderResultLoss
+	}
+
+	positionID := fmt.Sprintf("funding-%d", position.ID)
+	event := events.NewEvent(events.TypePositionClosed, positionID, "funding-arbitrage", position.Symbol)
+	event.Position = &events.Position{
+		PositionID: positionID,
+		Symbol:     position.Symbol,
+		Side:       string(models.OrderSideTypeBuy),
+		Quantity:   position.Quantity,
+		EntryPrice: position.SpotEntryPrice,
+		ExitPrice:  &spotExit,
+		PnL:        pnl,
+		Result:     string(result),
+		OpenedAt:   position.OpenedAt,
+		ClosedAt:   position.ClosedAt,
+	}
+	w.events.Emit(event)
+}
+
+// finish porta la posizione in uno stato finale registrando la nota
+func (w *FundingArbitrageWorker) finish(ctx context.Context, position *models.FundingArbPosition, status models.FundingArbStatus, note string) {
+	now := time.Now().UTC()
+	position.Status = status
+	position.Note = note
+	if status != models.FundingArbStatusUnhedged {
+		position.ClosedAt = &now
+	}
+
+	if status != models.FundingArbStatusClosed {
+		correlation.Logf(ctx, "❌ Posizione #%d %s: %s", position.ID, status, note)
+	}
+	if err := w.repoManager.FundingArb().Update(ctx, position); err != nil {
+		correlation.Logf(ctx, "❌ Errore aggiornamento posizione #%d: %v", position.ID, err)
+	}
+}
+
+// orderResult restituisce errore se l'ordine non è stato accettato dalla venue
+func orderResult(resp *models.OrderResponse, err error) (*models.OrderResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("risposta ordine vuota")
+	}
+	if resp.Status == models.OrderStatusRejected || !resp.IsSuccess() {
+		return nil, fmt.Errorf("ordine rifiutato: %s %s", resp.ErrorCode, resp.ErrorMessage)
+	}
+	return resp, nil
+}
+
+// fillPrice restituisce il prezzo medio di esecuzione, o il prezzo quotato se la venue non lo riporta
+func fillPrice(resp *models.OrderResponse, quoted float64) float64 {
+	if resp.AveragePrice > 0 {
+		return resp.AveragePrice
+	}
+	return quoted
+}
+
+// GetName implementa l'interfaccia Worker
+func (w *FundingArbitrageWorker) GetName() string {
+	return "Funding Arbitrage Worker"
+}
+
+// Stop ferma il worker
+func (w *FundingArbitrageWorker) Stop() {
+	log.Println("Stopping Funding Arbitrage Worker...")
+	w.cancel()
+}
@@ -0,0 +1,246 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/correlation"
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/exchange"
+	"cross-exchange-arbitrage/features"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/portfolio"
+	"cross-exchange-arbitrage/risk"
+)
+
+// HedgeOverlayWorker copre i long aperti sulle altcoin con uno short sul perpetual indice durante i segnali di risk-off
+// Ad ogni ciclo somma i long di tutte le venue, valuta i segnali e riporta lo short alla copertura obiettivo;
+// fuori dal risk-off lo chiude. Le posizioni delle strategie non vengono toccate
+type HedgeOverlayWorker struct {
+	cycleContext // Contesto del ciclo corrente, con l'identificativo di correlazione
+	cancel       context.CancelFunc
+	cfg          config.HedgeConfig
+	overlay      *portfolio.HedgeOverlay
+	riskOff      *portfolio.RiskOffDetector
+	prices       exchange.Exchange
+	orders       orderprocessor.OrderProcessor
+	accounts     map[string]orderprocessor.OrderProcessor // Venue da cui leggere i long da coprire
+	risk         *risk.Manager                            // Limiti di rischio globali applicati all'apertura della copertura
+	maxDataAge   time.Duration                            // Età massima del prezzo usato per dimensionare lo short; 0 se disabilitato
+	flags        *features.Flags                          // Con hedge_overlay disattivato la copertura può solo ridursi
+	throttle     *orderprocessor.OrderThrottle            // Limite di ordini per simbolo e account, applicato solo agli aumenti
+	spread       *risk.SpreadGuard                        // Controllo dello spread, applicato solo agli aumenti; nil se disabilitato
+}
+
+// NewHedgeOverlayWorker crea il worker risolvendo la venue di copertura tra le dipendenze
+// Restituisce errore se la venue non è configurata o non ha credenziali
+func NewHedgeOverlayWorker(deps *SystemDependencies) (*HedgeOverlayWorker, error) {
+	cfg := deps.Config.Hedge
+
+	overlay, err := portfolio.NewHedgeOverlay(portfolio.HedgeParams{
+		Symbol:    cfg.Symbol,
+		Ratio:     cfg.Ratio,
+		Tolerance: cfg.Tolerance,
+		QtyStep:   cfg.QtyStep,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prices, ok := deps.Exchanges[cfg.Venue]
+	if !ok {
+		return nil, fmt.Errorf("venue di copertura %s non configurata", cfg.Venue)
+	}
+	orders, ok := deps.OrderProcessors[cfg.Venue]
+	if !ok {
+		return nil, fmt.Errorf("la venue %s non ha credenziali per gli ordini", cfg.Venue)
+	}
+
+	riskOff, err := portfolio.NewRiskOffDetector(portfolio.RiskOffParams{
+		Signals:      cfg.Signals,
+		Symbol:       cfg.Symbol,
+		FearGreedMax: cfg.FearGreedMax,
+		FearGreedAge: cfg.FearGreedAge,
+		TrendPeriod:  cfg.TrendPeriod,
+	}, deps.RepoManager.DataPoint(), prices)
+	if err != nil {
+		return nil, err
+	}
+
+	// Le modifiche fatte dal worker vengono attribuite al worker nell'audit trail
+	ctx, cancel := context.WithCancel(database.WithChangedBy(context.Background(), "hedge-overlay"))
+
+	return &HedgeOverlayWorker{
+		cycleContext: newCycleContext(ctx),
+		cancel:       cancel,
+		cfg:          cfg,
+		overlay:      overlay,
+		riskOff:      riskOff,
+		prices:       prices,
+		orders:       orders,
+		accounts:     deps.OrderProcessors,
+		risk:         deps.Risk,
+		maxDataAge:   deps.Config.Risk.MaxDataAge,
+		flags:        deps.Features,
+		throttle:     deps.Throttle,
+		spread:       deps.Spread,
+	}, nil
+}
+
+// ExecuteTradingCycle esegue un ciclo della copertura del portafoglio
+// La copertura riduce il rischio, quindi resta attiva anche durante i blackout del calendario macro
+func (w *HedgeOverlayWorker) ExecuteTradingCycle() {
+	ctx, cancel := context.WithTimeout(w.ctx, 2*time.Minute)
+	defer cancel()
+
+	current, err := w.currentHedge(ctx)
+	if err != nil {
+		log.Printf("❌ Copertura %s: %v", w.cfg.Symbol, err)
+		return
+	}
+
+	exposure, err := w.exposure(ctx)
+	if err != nil {
+		log.Printf("❌ Copertura %s: %v", w.cfg.Symbol, err)
+		return
+	}
+
+	riskOff, reasons, err := w.riskOff.RiskOff(ctx)
+	if err != nil {
+		// Senza segnali valutabili la copertura esistente resta invariata
+		log.Printf("⚠️  Copertura %s: segnali di risk-off non disponibili: %v", w.cfg.Symbol, err)
+		return
+	}
+
+	price, err := w.prices.GetRealTimePrice(ctx, w.cfg.Symbol)
+	if err != nil {
+		log.Printf("❌ Copertura %s: errore prezzo: %v", w.cfg.Symbol, err)
+		return
+	}
+	if err := risk.CheckPrice(price, w.maxDataAge); err != nil {
+		correlation.Logf(ctx, "⏸️  Copertura %s: ciclo saltato: %v", w.cfg.Symbol, err)
+		return
+	}
+
+	target := w.overlay.Target(exposure, price.Price, riskOff)
+	log.Printf("🛡️  Copertura %s: long altcoin %.2f USDT %v, risk-off %t %v, short attuale %.6f, obiettivo %.6f",
+		w.cfg.Symbol, exposure.Notional, exposure.Symbols, riskOff, reasons, current, target)
+
+	delta, ok := w.overlay.Adjustment(current, target)
+	if !ok {
+		return
+	}
+	if delta > 0 {
+		w.increase(ctx, current, delta, price)
+	} else {
+		w.reduce(ctx, -delta, price)
+	}
+}
+
+// currentHedge restituisce la quantità dello short aperto sul simbolo di copertura
+// Restituisce errore se sul simbolo c'è un long: la copertura non lo modifica
+func (w *HedgeOverlayWorker) currentHedge(ctx context.Context) (float64, error) {
+	positions, err := w.orders.GetPositions(ctx, w.cfg.Symbol)
+	if err != nil {
+		return 0, fmt.Errorf("errore lettura posizione di copertura: %w", err)
+	}
+
+	var current float64
+	for _, position := range positions {
+		if !position.Parsed.IsActive() {
+			continue
+		}
+		if position.Parsed.IsLong() {
+			return 0, fmt.Errorf("posizione long aperta su %s, copertura sospesa", w.cfg.Symbol)
+		}
+		current += position.Parsed.Size
+	}
+	return current, nil
+}
+
+// exposure somma i long aperti sulle altcoin in tutte le venue con credenziali
+func (w *HedgeOverlayWorker) exposure(ctx context.Context) (portfolio.Exposure, error) {
+	var positions []models.Position
+	for venue, processor := range w.accounts {
+		venuePositions, err := processor.GetPositions(ctx, "")
+		if err != nil {
+			return portfolio.Exposure{}, fmt.Errorf("errore lettura posizioni %s: %w", venue, err)
+		}
+		positions = append(positions, venuePositions...)
+	}
+	return w.overlay.Exposure(positions), nil
+}
+
+// increase apre o aumenta lo short di copertura; l'apertura passa dal risk manager come ogni nuova posizione
+func (w *HedgeOverlayWorker) increase(ctx context.Context, current, quantity float64, price *models.RealTimePriceData) {
+	if !w.flags.Enabled(features.HedgeOverlay) {
+		correlation.Logf(ctx, "⏸️  Copertura %s non aumentata: feature flag %s disattivato", w.cfg.Symbol, features.HedgeOverlay)
+		return
+	}
+	if current == 0 {
+		release, err := w.risk.ReservePosition(ctx, w.cfg.Symbol)
+		if err != nil {
+			correlation.Logf(ctx, "⏸️  Copertura %s non aperta: %v", w.cfg.Symbol, err)
+			return
+		}
+		defer release()
+	} else if err := w.risk.CheckSymbol(w.cfg.Symbol); err != nil {
+		correlation.Logf(ctx, "⏸️  Copertura %s non aumentata: %v", w.cfg.Symbol, err)
+		return
+	}
+
+	if err := w.spread.Check(ctx, w.cfg.Symbol, w.prices.GetRealTimePrice); err != nil {
+		correlation.Logf(ctx, "⏸️  Copertura %s non aumentata: %v", w.cfg.Symbol, err)
+		return
+	}
+	if err := w.throttle.Acquire(ctx, w.cfg.Venue, w.cfg.Symbol); err != nil {
+		return
+	}
+
+	resp, err := orderResult(w.placeOrder(ctx, models.OrderSideSell, quantity, price.BidPrice))
+	if err != nil {
+		correlation.Logf(ctx, "❌ Short di copertura %s fallito: %v", w.cfg.Symbol, err)
+		return
+	}
+	correlation.Logf(ctx, "✅ Copertura %s aumentata di %.6f a %.6f (ordine %s)",
+		w.cfg.Symbol, quantity, fillPrice(resp, price.BidPrice), resp.OrderID)
+}
+
+// reduce riduce o chiude lo short di copertura
+func (w *HedgeOverlayWorker) reduce(ctx context.Context, quantity float64, price *models.RealTimePriceData) {
+	resp, err := orderResult(w.placeOrder(ctx, models.OrderSideBuy, quantity, price.AskPrice))
+	if err != nil {
+		correlation.Logf(ctx, "❌ Riduzione copertura %s fallita, ritento al prossimo ciclo: %v", w.cfg.Symbol, err)
+		return
+	}
+	correlation.Logf(ctx, "✅ Copertura %s ridotta di %.6f a %.6f (ordine %s)",
+		w.cfg.Symbol, quantity, fillPrice(resp, price.AskPrice), resp.OrderID)
+}
+
+// placeOrder piazza un ordine a mercato sul perpetual di copertura
+// Con i processor che supportano la quantità esatta gli acquisti sono reduce-only, così possono solo ridurre lo short;
+// gli altri usano PlaceLongOrder e PlaceShortOrder, e in modalità one-way un ordine long riduce lo short
+func (w *HedgeOverlayWorker) placeOrder(ctx context.Context, side models.OrderSide, quantity, quoted float64) (*models.OrderResponse, error) {
+	if market, ok := w.orders.(orderprocessor.MarketOrderProcessor); ok {
+		return market.PlaceMarketOrder(ctx, w.cfg.Symbol, side, quantity, side == models.OrderSideBuy)
+	}
+	if side == models.OrderSideBuy {
+		return w.orders.PlaceLongOrder(ctx, w.cfg.Symbol, quoted, quantity, 0, 0)
+	}
+	return w.orders.PlaceShortOrder(ctx, w.cfg.Symbol, quoted, quantity, 0, 0)
+}
+
+// GetName implementa l'interfaccia Worker
+func (w *HedgeOverlayWorker) GetName() string {
+	return "Hedge Overlay Worker"
+}
+
+// Stop ferma il worker
+func (w *HedgeOverlayWorker) Stop() {
+	log.Println("Stopping Hedge Overlay Worker...")
+	w.cancel()
+}
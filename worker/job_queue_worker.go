@@ -0,0 +1,160 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/services"
+)
+
+// JobCancelUnfilledOrder cancella un ordine se non è stato eseguito entro il tempo previsto
+const JobCancelUnfilledOrder = "cancel_unfilled_order"
+
+// JobPlaceOrderSlice invia la frazione successiva di un ingresso frazionato
+const JobPlaceOrderSlice = "place_order_slice"
+
+// cancelUnfilledOrderPayload identifica l'ordine da controllare
+type cancelUnfilledOrderPayload struct {
+	Exchange string `json:"exchange"`
+	Symbol   string `json:"symbol"`
+	OrderID  string `json:"order_id"`
+}
+
+// placeOrderSlicePayload identifica l'ingresso frazionato
+type placeOrderSlicePayload struct {
+	SlicedOrderID uint `json:"sliced_order_id"`
+}
+
+// JobQueueWorker esegue i job differiti scaduti della coda persistente
+// I job sopravvivono ai riavvii: quelli interrotti durante l'esecuzione vengono ripresi all'avvio
+type JobQueueWorker struct {
+	cycleContext // Contesto del ciclo corrente, con l'identificativo di correlazione
+	cancel       context.CancelFunc
+	queue        *services.JobQueue
+}
+
+// NewJobQueueWorker crea il worker della coda e registra gli handler dei job
+func NewJobQueueWorker(deps *SystemDependencies) *JobQueueWorker {
+	// Le modifiche agli ordini fatte dai job vengono attribuite alla coda nell'audit trail
+	ctx, cancel := context.WithCancel(database.WithChangedBy(context.Background(), "job-queue"))
+
+	deps.Jobs.Register(JobCancelUnfilledOrder, cancelUnfilledOrderHandler(deps))
+	deps.Jobs.Register(JobPlaceOrderSlice, placeOrderSliceHandler(deps))
+
+	if released, err := deps.Jobs.Recover(ctx); err != nil {
+		log.Printf("❌ Errore ripristino job interrotti: %v", err)
+	} else if released > 0 {
+		log.Printf("🔄 %d job interrotti rimessi in coda", released)
+	}
+
+	return &JobQueueWorker{cycleContext: newCycleContext(ctx), cancel: cancel, queue: deps.Jobs}
+}
+
+// ExecuteTradingCycle esegue i job scaduti
+func (w *JobQueueWorker) ExecuteTradingCycle() {
+	processed, err := w.queue.RunDue(w.ctx)
+	if err != nil {
+		log.Printf("❌ Errore esecuzione job differiti: %v", err)
+		return
+	}
+	if processed > 0 {
+		log.Printf("📋 %d job differiti elaborati", processed)
+	}
+}
+
+// GetName implementa l'interfaccia Worker
+func (w *JobQueueWorker) GetName() string {
+	return "Job Queue Worker"
+}
+
+// Stop ferma il worker
+func (w *JobQueueWorker) Stop() {
+	log.Println("Stopping Job Queue Worker...")
+	w.cancel()
+}
+
+// cancelUnfilledOrderHandler controlla lo stato dell'ordine e cancella la parte non eseguita
+// Un ordine parzialmente eseguito mantiene la posizione aperta sulla quantità già fillata
+func cancelUnfilledOrderHandler(deps *SystemDependencies) services.JobHandler {
+	return func(ctx context.Context, data []byte) error {
+		var payload cancelUnfilledOrderPayload
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return fmt.Errorf("%w: payload non valido: %v", services.ErrPermanentJobFailure, err)
+		}
+		processor, ok := deps.OrderProcessors[payload.Exchange]
+		if !ok {
+			return fmt.Errorf("%w: nessun order processor per %s", services.ErrPermanentJobFailure, payload.Exchange)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		status, err := processor.GetOrderStatus(ctx, payload.Symbol, payload.OrderID)
+		if err != nil {
+			return fmt.Errorf("errore recupero stato ordine %s: %w", payload.OrderID, err)
+		}
+
+		var cancelled models.OrderStatus
+		switch status.Status {
+		case models.OrderStatusNew, models.OrderStatusUntriggered:
+			cancelled = models.OrderStatusCancelled
+		case models.OrderStatusPartiallyFilled:
+			cancelled = models.OrderStatusPartiallyFilledCanceled
+		default:
+			log.Printf("Ordine %s in stato %s, nessuna cancellazione necessaria", payload.OrderID, status.Status)
+			return nil
+		}
+
+		if _, err := processor.DeleteOrder(ctx, payload.Symbol, payload.OrderID); err != nil {
+			return fmt.Errorf("errore cancellazione ordine %s: %w", payload.OrderID, err)
+		}
+		log.Printf("🗑️  Ordine %s non eseguito cancellato (stato %s, eseguiti %.4f di %.4f)",
+			payload.OrderID, status.Status, status.FilledQuantity, status.Quantity)
+
+		// L'ordine può mancare dal database (es. salvataggio fallito): la cancellazione sull'exchange resta valida
+		if err := deps.OrderService.UpdateOrderStatus(ctx, payload.OrderID, string(cancelled)); err != nil {
+			log.Printf("⚠️  Ordine %s cancellato ma stato non aggiornato nel database: %v", payload.OrderID, err)
+		}
+		return nil
+	}
+}
+
+// placeOrderSliceHandler invia la frazione successiva e pianifica quella dopo finché l'ingresso non è completo
+func placeOrderSliceHandler(deps *SystemDependencies) services.JobHandler {
+	return func(ctx context.Context, data []byte) error {
+		var payload placeOrderSlicePayload
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return fmt.Errorf("%w: payload non valido: %v", services.ErrPermanentJobFailure, err)
+		}
+		if deps.Slicer == nil {
+			return fmt.Errorf("%w: frazionamento degli ordini disabilitato", services.ErrPermanentJobFailure)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		order, err := deps.Slicer.PlaceNext(ctx, payload.SlicedOrderID)
+		if err != nil {
+			return err
+		}
+		if order.Status != models.SlicedOrderStatusActive {
+			return nil
+		}
+		return scheduleOrderSlice(ctx, deps.Jobs, order.ID, deps.Slicer.Interval())
+	}
+}
+
+// scheduleOrderSlice accoda l'invio della frazione successiva dopo delay
+func scheduleOrderSlice(ctx context.Context, jobs *services.JobQueue, slicedOrderID uint, delay time.Duration) error {
+	job, err := jobs.Schedule(ctx, JobPlaceOrderSlice, placeOrderSlicePayload{SlicedOrderID: slicedOrderID}, delay)
+	if err != nil {
+		return fmt.Errorf("impossibile pianificare la frazione successiva dell'ingresso %d: %w", slicedOrderID, err)
+	}
+	log.Printf("⏲️  Frazione successiva dell'ingresso %d pianificata alle %s (job %d)", slicedOrderID, job.RunAt.Local().Format("15:04:05"), job.ID)
+	return nil
+}
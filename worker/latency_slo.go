@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"cross-exchange-arbitrage/errorreport"
+	"cross-exchange-arbitrage/i18n"
+)
+
+// LatencySLO misura il tempo tra il segnale (chiusura della candela) e la conferma dell'ordine da parte dell'exchange
+// e segnala i worker che superano l'obiettivo più volte di seguito: un ritardo isolato è rumore,
+// una serie indica un problema (exchange lento, ciclo troppo pesante, rate limit)
+// Un LatencySLO nil misura la latenza senza controllarla
+type LatencySLO struct {
+	mu       sync.Mutex
+	target   time.Duration
+	breaches int                   // Superamenti consecutivi che fanno scattare l'alert
+	streaks  map[string]int        // Superamenti consecutivi per worker
+	reporter *errorreport.Reporter // Invio a Sentry degli alert; nil se disabilitato
+}
+
+// NewLatencySLO crea il controllo con obiettivo target, segnalando ogni breaches superamenti consecutivi
+func NewLatencySLO(target time.Duration, breaches int, reporter *errorreport.Reporter) *LatencySLO {
+	return &LatencySLO{target: target, breaches: breaches, streaks: make(map[string]int), reporter: reporter}
+}
+
+// Observe registra la latenza di un ordine confermato in ackAt per un segnale generato in signalAt
+// e la restituisce, per salvarla con l'ordine
+func (s *LatencySLO) Observe(worker, symbol string, signalAt, ackAt time.Time) time.Duration {
+	latency := ackAt.Sub(signalAt)
+	if s == nil {
+		log.Printf("⏱️  Latenza ordine %s: %v dal segnale", symbol, latency.Round(time.Millisecond))
+		return latency
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if latency <= s.target {
+		s.streaks[worker] = 0
+		log.Printf("⏱️  Latenza ordine %s: %v dal segnale (SLO %v)", symbol, latency.Round(time.Millisecond), s.target)
+		return latency
+	}
+
+	s.streaks[worker]++
+	streak := s.streaks[worker]
+	log.Printf("⚠️  Latenza ordine %s: %v dal segnale, oltre lo SLO di %v (%d/%d consecutivi)",
+		symbol, latency.Round(time.Millisecond), s.target, streak, s.breaches)
+	if streak >= s.breaches {
+		// La serie riparte da zero: un nuovo alert solo dopo altri breaches superamenti
+		s.streaks[worker] = 0
+		message := i18n.T("alert.latency_slo", worker, streak, s.target, latency.Round(time.Millisecond), symbol)
+		log.Println(message)
+		s.reporter.CaptureError(errors.New(message), errorreport.Context{
+			Component:   "latency",
+			Worker:      worker,
+			Symbol:      symbol,
+			Fingerprint: []string{"latency-slo", worker},
+		})
+	}
+	return latency
+}
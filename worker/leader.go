@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// leaderResource è la risorsa del lease di leadership, unica per account
+const leaderResource = "leader"
+
+// StandbyWorker è implementato dai worker che, sull'istanza in standby, mantengono aggiornato lo stato
+// (candele, posizioni) senza operare, così da subentrare al leader senza ripartire a freddo
+type StandbyWorker interface {
+	// WarmUp aggiorna lo stato del worker senza piazzare né modificare ordini
+	WarmUp()
+}
+
+// leaderElection elegge tra le istanze sullo stesso account quella che esegue i worker di trading
+// Il leader rinnova il lease di leadership come heartbeat; se smette, alla scadenza del TTL
+// un'istanza in standby acquisisce il lease e diventa leader
+type leaderElection struct {
+	leases *leaseKeeper
+	leader atomic.Bool
+}
+
+// newLeaderElection crea l'elezione sui lease dell'istanza
+func newLeaderElection(leases *leaseKeeper) *leaderElection {
+	return &leaderElection{leases: leases}
+}
+
+// isLeader indica se questa istanza è il leader
+func (e *leaderElection) isLeader() bool {
+	return e.leader.Load()
+}
+
+// campaign rinnova o tenta di acquisire la leadership ogni terzo di TTL fino alla cancellazione del context
+func (e *leaderElection) campaign(ctx context.Context) {
+	ticker := time.NewTicker(e.leases.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.heartbeat(ctx)
+		}
+	}
+}
+
+// heartbeat acquisisce o rinnova il lease di leadership e aggiorna il ruolo dell'istanza
+// Se il lock non risponde l'istanza passa in standby: il suo lease potrebbe scadere senza che se ne accorga
+func (e *leaderElection) heartbeat(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.leases.ttl/3)
+	defer cancel()
+
+	acquired, err := e.leases.acquire(ctx, leaderResource)
+	if err != nil {
+		log.Printf("❌ Heartbeat leadership fallito: %v", err)
+		acquired = false
+	}
+
+	if was := e.leader.Swap(acquired); was != acquired {
+		if acquired {
+			log.Printf("👑 Istanza %s eletta leader: i worker di trading sono attivi", e.leases.owner)
+		} else {
+			log.Printf("💤 Istanza %s in standby: i worker di trading mantengono solo lo stato aggiornato", e.leases.owner)
+		}
+	}
+}
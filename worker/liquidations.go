@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/book"
+	"cross-exchange-arbitrage/correlation"
+	"cross-exchange-arbitrage/features"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/strategy"
+)
+
+// liquidationCluster restituisce il cluster di liquidazioni più recente del simbolo con controvalore
+// almeno pari a MinNotional, se è terminato negli ultimi Lookback
+func (w *DogeTradingSystemWorker) liquidationCluster(symbol string) (book.LiquidationCluster, bool) {
+	clusters := w.liquidations.Clusters(symbol, time.Now().Add(-w.liqCfg.Lookback))
+	for i := len(clusters) - 1; i >= 0; i-- {
+		if clusters[i].Notional >= w.liqCfg.MinNotional {
+			return clusters[i], true
+		}
+	}
+	return book.LiquidationCluster{}, false
+}
+
+// liquidationEntry restituisce il cluster che fa scattare un ingresso contrarian dopo una cascata di liquidazioni,
+// nella direzione cluster.Side: long dopo la liquidazione di long (vendite forzate), short dopo quella di short.
+// Ogni cluster fa scattare un solo ingresso, anche se i filtri lo bloccano
+func (w *DogeTradingSystemWorker) liquidationEntry(symbol string) (book.LiquidationCluster, bool) {
+	if w.liquidations == nil || !w.liqCfg.ContrarianEntry || !w.flags.Enabled(features.LiquidationEntry) ||
+		!w.regimes.Allows(symbol, strategy.EntryLiquidation) {
+		return book.LiquidationCluster{}, false
+	}
+
+	cluster, ok := w.liquidationCluster(symbol)
+	if !ok || !cluster.End.After(w.liqTrigger) {
+		return book.LiquidationCluster{}, false
+	}
+	w.liqTrigger = cluster.End
+	log.Printf("💥 Cascata di liquidazioni %s %s: %d liquidazioni per %.0f USDT a %.6f (%s - %s)",
+		symbol, cluster.Side, cluster.Count, cluster.Notional, cluster.Price,
+		cluster.Start.Format("15:04:05"), cluster.End.Format("15:04:05"))
+	return cluster, true
+}
+
+// liquidationStops stringe lo stop della posizione aperta del simbolo se un cluster recente ha liquidato
+// posizioni dello stesso lato: la cascata spinge il prezzo contro la posizione
+func (w *DogeTradingSystemWorker) liquidationStops(symbol string) {
+	if w.liquidations == nil || !w.liqCfg.TightenStops {
+		return
+	}
+
+	cluster, ok := w.liquidationCluster(symbol)
+	if !ok {
+		return
+	}
+	positions, err := w.orderProcessor.GetPositions(w.ctx, symbol)
+	if err != nil {
+		log.Printf("⚠️  Liquidazioni: errore nel recupero della posizione %s: %v", symbol, err)
+		return
+	}
+	if len(positions) == 0 || !positions[0].Parsed.IsActive() {
+		return
+	}
+	position := positions[0]
+	if position.Parsed.IsLong() != (cluster.Side == models.OrderSideBuy) {
+		return
+	}
+	stopLoss, ok := tightenedStopLoss(position, w.liqCfg.TightenStopPct)
+	if !ok {
+		return
+	}
+
+	resp, err := w.orderProcessor.UpdateOrder(w.ctx, orderprocessor.UpdateOrderParams{
+		Symbol:      symbol,
+		StopLoss:    &stopLoss,
+		PositionIdx: position.PositionIdx,
+	})
+	if err != nil {
+		correlation.Logf(w.ctx, "❌ Liquidazioni: errore aggiornamento stop loss %s: %v", symbol, err)
+		return
+	}
+	if !resp.IsSuccess() {
+		correlation.Logf(w.ctx, "❌ Liquidazioni: stop loss %s rifiutato - %s (codice: %s)", symbol, resp.ErrorMessage, resp.ErrorCode)
+		return
+	}
+	correlation.Logf(w.ctx, "🛡️  Liquidazioni: %.0f USDT di posizioni %s liquidate, stop loss %s spostato da %s a %.6f",
+		cluster.Notional, cluster.Side, symbol, position.StopLoss, stopLoss)
+}
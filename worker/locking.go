@@ -0,0 +1,162 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Locker acquisisce lease condivisi tra più istanze del bot (implementato da repositories.LockRepository,
+// valido solo per istanze sullo stesso host, e da cache.RedisLocker, condiviso tra host diversi)
+type Locker interface {
+	// TryAcquire acquisisce o rinnova il lease di key per owner; false se è detenuto da un'altra istanza
+	TryAcquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+
+	// Release rilascia il lease se detenuto da owner
+	Release(ctx context.Context, key, owner string) error
+}
+
+// FencedLocker è implementato dai Locker che numerano le acquisizioni con un fencing token crescente
+type FencedLocker interface {
+	Locker
+
+	// TryAcquireToken acquisisce o rinnova il lease e ne restituisce il fencing token; 0 se è detenuto da un'altra istanza
+	TryAcquireToken(ctx context.Context, key, owner string, ttl time.Duration) (int64, error)
+}
+
+// leaseKeeper tiene i lease acquisiti da questa istanza e li rinnova in background
+// Un lease resta all'istanza che lo ha acquisito finché la rinnova; se l'istanza si ferma
+// o si blocca, alla scadenza del TTL un'altra istanza lo acquisisce al ciclo successivo
+type leaseKeeper struct {
+	locker    Locker
+	owner     string
+	namespace string // Account a cui si riferiscono i lease (es. bybit-main)
+	ttl       time.Duration
+	mu        sync.Mutex
+	held      map[string]int64 // Fencing token dei lease detenuti (0 se il Locker non li fornisce)
+}
+
+// newLeaseKeeper crea il gestore dei lease; owner vuoto = hostname e pid del processo
+func newLeaseKeeper(locker Locker, namespace, owner string, ttl time.Duration) *leaseKeeper {
+	if owner == "" {
+		host, _ := os.Hostname()
+		owner = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	return &leaseKeeper{
+		locker:    locker,
+		owner:     owner,
+		namespace: namespace,
+		ttl:       ttl,
+		held:      make(map[string]int64),
+	}
+}
+
+// key restituisce la chiave completa del lease di una risorsa
+func (lk *leaseKeeper) key(resource string) string {
+	return lk.namespace + "/" + resource
+}
+
+// tryAcquire acquisisce o rinnova il lease di key; token è 0 se il Locker non fornisce fencing token
+func (lk *leaseKeeper) tryAcquire(ctx context.Context, key string) (acquired bool, token int64, err error) {
+	if fenced, ok := lk.locker.(FencedLocker); ok {
+		token, err = fenced.TryAcquireToken(ctx, key, lk.owner, lk.ttl)
+		return token > 0, token, err
+	}
+	acquired, err = lk.locker.TryAcquire(ctx, key, lk.owner, lk.ttl)
+	return acquired, 0, err
+}
+
+// acquire acquisisce o rinnova il lease della risorsa
+func (lk *leaseKeeper) acquire(ctx context.Context, resource string) (bool, error) {
+	key := lk.key(resource)
+	acquired, token, err := lk.tryAcquire(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("errore acquisizione lock %s: %w", key, err)
+	}
+
+	lk.mu.Lock()
+	defer lk.mu.Unlock()
+	lk.track(key, acquired, token)
+	return acquired, nil
+}
+
+// track aggiorna i lease detenuti dopo un'acquisizione o un rinnovo; va chiamato con mu bloccato
+// Un fencing token cambiato su un lease che risultava detenuto indica che è scaduto tra due rinnovi:
+// nel frattempo un'altra istanza potrebbe averlo acquisito e aver operato sulla risorsa
+func (lk *leaseKeeper) track(key string, acquired bool, token int64) {
+	previous, held := lk.held[key]
+	switch {
+	case acquired && !held:
+		if token > 0 {
+			log.Printf("🔒 Lock %s acquisito da %s (fencing token %d)", key, lk.owner, token)
+		} else {
+			log.Printf("🔒 Lock %s acquisito da %s", key, lk.owner)
+		}
+	case acquired && token != previous:
+		log.Printf("⚠️  Lock %s scaduto e riacquisito (fencing token %d → %d): un'altra istanza potrebbe averlo detenuto nel frattempo", key, previous, token)
+	case !acquired && held:
+		log.Printf("⚠️  Lock %s perso: acquisito da un'altra istanza", key)
+	}
+	if acquired {
+		lk.held[key] = token
+	} else {
+		delete(lk.held, key)
+	}
+}
+
+// renewLoop rinnova i lease detenuti ogni terzo di TTL fino alla cancellazione del context
+func (lk *leaseKeeper) renewLoop(ctx context.Context) {
+	ticker := time.NewTicker(lk.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			lk.renew(ctx)
+		}
+	}
+}
+
+// renew rinnova tutti i lease detenuti
+func (lk *leaseKeeper) renew(ctx context.Context) {
+	lk.mu.Lock()
+	keys := make([]string, 0, len(lk.held))
+	for key := range lk.held {
+		keys = append(keys, key)
+	}
+	lk.mu.Unlock()
+
+	for _, key := range keys {
+		acquired, token, err := lk.tryAcquire(ctx, key)
+		if err != nil {
+			// Il lease resta valido fino alla scadenza: si riprova al prossimo giro
+			log.Printf("❌ Errore rinnovo lock %s: %v", key, err)
+			continue
+		}
+		lk.mu.Lock()
+		lk.track(key, acquired, token)
+		lk.mu.Unlock()
+	}
+}
+
+// releaseAll rilascia i lease detenuti, così un'altra istanza può subentrare senza attendere il TTL
+func (lk *leaseKeeper) releaseAll() {
+	lk.mu.Lock()
+	defer lk.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for key := range lk.held {
+		if err := lk.locker.Release(ctx, key, lk.owner); err != nil {
+			log.Printf("❌ Errore rilascio lock %s: %v", key, err)
+			continue
+		}
+		log.Printf("🔓 Lock %s rilasciato", key)
+	}
+	lk.held = make(map[string]int64)
+}
@@ -0,0 +1,184 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/database"
+	"cross-exchange-arbitrage/repositories"
+
+	"gorm.io/gorm"
+)
+
+// RetentionPolicy definisce per quanto tempo conservare i record di una tabella
+type RetentionPolicy struct {
+	Name      string                                            // Nome della tabella o del dato (per i log)
+	Retention time.Duration                                     // Durata di conservazione
+	Cleanup   func(ctx context.Context, before time.Time) error // Elimina i record più vecchi di before
+}
+
+// MaintenanceWorker esegue la pulizia periodica del database secondo le retention policy
+type MaintenanceWorker struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	db       *gorm.DB
+	policies []RetentionPolicy
+	vacuum   bool
+}
+
+// NewMaintenanceWorker crea una nuova istanza del worker di manutenzione
+// Le retention policy vengono costruite dalla configurazione; una retention a 0 disabilita la pulizia
+func NewMaintenanceWorker(deps *SystemDependencies) *MaintenanceWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	worker := &MaintenanceWorker{
+		ctx:    ctx,
+		cancel: cancel,
+		db:     deps.DB,
+		vacuum: deps.Config.Maintenance.Vacuum,
+	}
+
+	if days := deps.Config.Maintenance.OrderArchiveDays; days > 0 {
+		worker.AddPolicy(orderArchivePolicy(deps.RepoManager, days))
+	}
+	if days := deps.Config.Maintenance.AuditRetentionDays; days > 0 {
+		worker.AddPolicy(auditRetentionPolicy(deps.RepoManager, days))
+		worker.AddPolicy(signalDecisionRetentionPolicy(deps.RepoManager, days))
+	}
+	if days := deps.Config.Jobs.RetentionDays; days > 0 {
+		worker.AddPolicy(jobRetentionPolicy(deps.RepoManager, days))
+	}
+	if days := deps.Config.PnL.RetentionDays; days > 0 {
+		worker.AddPolicy(positionPnLRetentionPolicy(deps.RepoManager, days))
+	}
+
+	return worker
+}
+
+// AddPolicy registra una nuova retention policy (es. per i log dei segnali)
+func (w *MaintenanceWorker) AddPolicy(policy RetentionPolicy) {
+	w.policies = append(w.policies, policy)
+}
+
+// auditRetentionPolicy crea la retention policy per l'audit trail degli ordini
+func auditRetentionPolicy(repoManager repositories.RepositoryManager, days int) RetentionPolicy {
+	return RetentionPolicy{
+		Name:      "order_audit",
+		Retention: time.Duration(days) * 24 * time.Hour,
+		Cleanup: func(ctx context.Context, before time.Time) error {
+			// changed_at è salvato in UTC nel formato di CURRENT_TIMESTAMP
+			return repoManager.OrderAudit().DeleteOldRecords(ctx, before.UTC().Format("2006-01-02 15:04:05"))
+		},
+	}
+}
+
+// signalDecisionRetentionPolicy crea la retention policy per le spiegazioni dei segnali, conservate quanto l'audit trail
+func signalDecisionRetentionPolicy(repoManager repositories.RepositoryManager, days int) RetentionPolicy {
+	return RetentionPolicy{
+		Name:      "signal_decisions",
+		Retention: time.Duration(days) * 24 * time.Hour,
+		Cleanup: func(ctx context.Context, before time.Time) error {
+			_, err := repoManager.SignalDecision().DeleteBefore(ctx, before)
+			return err
+		},
+	}
+}
+
+// jobRetentionPolicy crea la retention policy per i job differiti già conclusi
+func jobRetentionPolicy(repoManager repositories.RepositoryManager, days int) RetentionPolicy {
+	return RetentionPolicy{
+		Name:      "scheduled_jobs",
+		Retention: time.Duration(days) * 24 * time.Hour,
+		Cleanup: func(ctx context.Context, before time.Time) error {
+			_, err := repoManager.ScheduledJob().DeleteFinishedBefore(ctx, before)
+			return err
+		},
+	}
+}
+
+// orderArchivePolicy crea la policy che sposta gli ordini chiusi nella tabella di archivio
+// Tag, audit ed esecuzioni restano collegati tramite OrderID
+func orderArchivePolicy(repoManager repositories.RepositoryManager, days int) RetentionPolicy {
+	return RetentionPolicy{
+		Name:      "orders",
+		Retention: time.Duration(days) * 24 * time.Hour,
+		Cleanup: func(ctx context.Context, before time.Time) error {
+			archived, err := repoManager.OrderArchive().ArchiveClosedOrders(ctx, before, 0)
+			if archived > 0 {
+				log.Printf("📦 %d ordini chiusi spostati in orders_archive", archived)
+			}
+			return err
+		},
+	}
+}
+
+// positionPnLRetentionPolicy crea la retention policy per gli snapshot periodici del PnL delle posizioni
+func positionPnLRetentionPolicy(repoManager repositories.RepositoryManager, days int) RetentionPolicy {
+	return RetentionPolicy{
+		Name:      "position_pnl_snapshots",
+		Retention: time.Duration(days) * 24 * time.Hour,
+		Cleanup: func(ctx context.Context, before time.Time) error {
+			_, err := repoManager.PositionPnL().DeleteBefore(ctx, before)
+			return err
+		},
+	}
+}
+
+// ExecuteTradingCycle esegue un ciclo di manutenzione
+func (w *MaintenanceWorker) ExecuteTradingCycle() {
+	log.Println("Executing database maintenance...")
+
+	if len(w.policies) == 0 {
+		log.Println("Nessuna retention policy attiva, manutenzione saltata")
+		return
+	}
+
+	failed := 0
+	for _, policy := range w.policies {
+		if err := w.applyPolicy(policy); err != nil {
+			log.Printf("❌ %v", err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		log.Printf("⚠️  %d retention policy fallite, VACUUM/ANALYZE saltati", failed)
+		return
+	}
+
+	if w.vacuum {
+		start := time.Now()
+		if err := database.Optimize(w.db); err != nil {
+			log.Printf("❌ Errore ottimizzazione database: %v", err)
+			return
+		}
+		log.Printf("✅ VACUUM/ANALYZE completati in %v", time.Since(start))
+	}
+}
+
+// applyPolicy applica una singola retention policy
+func (w *MaintenanceWorker) applyPolicy(policy RetentionPolicy) error {
+	ctx, cancel := context.WithTimeout(w.ctx, 5*time.Minute)
+	defer cancel()
+
+	before := time.Now().Add(-policy.Retention)
+	if err := policy.Cleanup(ctx, before); err != nil {
+		return fmt.Errorf("errore pulizia %s: %w", policy.Name, err)
+	}
+
+	log.Printf("✅ %s: eliminati i record precedenti al %s", policy.Name, before.Format("2006-01-02"))
+	return nil
+}
+
+// GetName implementa l'interfaccia Worker
+func (w *MaintenanceWorker) GetName() string {
+	return "Database Maintenance Worker"
+}
+
+// Stop ferma il worker
+func (w *MaintenanceWorker) Stop() {
+	log.Println("Stopping Database Maintenance Worker...")
+	w.cancel()
+}
@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/services"
+)
+
+// orphanSweepTimeout è il tempo massimo concesso alla pulizia degli ordini orfani all'avvio
+const orphanSweepTimeout = 30 * time.Second
+
+// runOrphanSweep cancella, o registra nel database, gli ordini condizionali che il bot non conosce
+// prima di avviare i worker: un'esecuzione precedente interrotta tra l'invio e il salvataggio
+// può aver lasciato trigger che scatterebbero senza nessuno a gestire la posizione
+// Un errore viene solo registrato: l'account watch worker continua a segnalare gli ordini sconosciuti
+func runOrphanSweep(deps *SystemDependencies) {
+	cfg := deps.Config.Orphans
+	if !cfg.Enabled || deps.Config.Paper.Enabled || deps.OrderProcessor == nil {
+		return
+	}
+
+	service, err := services.NewOrphanOrderService(deps.OrderProcessor, deps.OrderService, deps.RepoManager, services.OrphanSweepParams{
+		Action:  cfg.Action,
+		Grace:   cfg.Grace,
+		Symbols: cfg.Symbols,
+	})
+	if err != nil {
+		log.Printf("⚠️  Pulizia degli ordini orfani non disponibile: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), orphanSweepTimeout)
+	defer cancel()
+
+	report, err := service.Sweep(ctx)
+	if err != nil {
+		log.Printf("❌ Errore pulizia ordini orfani: %v", err)
+		return
+	}
+	if len(report.Orders) == 0 {
+		log.Printf("✅ Nessun ordine condizionale orfano su %v", cfg.Symbols)
+		return
+	}
+
+	for _, orphan := range report.Orders {
+		order := orphan.Order
+		switch {
+		case orphan.Err != nil:
+			log.Println(i18n.T("alert.orphan_failed", order.OrderID, order.Symbol, order.Side, order.Quantity, order.TriggerPrice, orphan.Err))
+		case report.Action == services.OrphanActionAdopt:
+			log.Println(i18n.T("alert.orphan_adopted", order.OrderID, order.Symbol, order.Side, order.Quantity, order.TriggerPrice))
+		default:
+			log.Println(i18n.T("alert.orphan_canceled", order.OrderID, order.Symbol, order.Side, order.Quantity, order.TriggerPrice))
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/events"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/services"
+)
+
+// PortfolioRebalanceWorker riporta periodicamente il capitale del master e dei sub-account delle strategie ai pesi obiettivo
+// Ogni trasferimento viene registrato, segnalato nei log e pubblicato come evento funds.transfer
+type PortfolioRebalanceWorker struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	service *services.PortfolioRebalanceService
+	events  *events.Emitter // Pubblicazione dei trasferimenti; nil se disabilitata
+}
+
+// NewPortfolioRebalanceWorker crea il worker sul processor di trading Bybit, che usa la key del master
+// In paper trading e in modalità observer i trasferimenti non sono disponibili
+func NewPortfolioRebalanceWorker(deps *SystemDependencies) (*PortfolioRebalanceWorker, error) {
+	transferer, ok := deps.OrderProcessors["bybit"].(orderprocessor.MemberTransferer)
+	if !ok {
+		return nil, fmt.Errorf("trasferimenti tra sub-account non disponibili (credenziali Bybit mancanti, paper trading o modalità observer)")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PortfolioRebalanceWorker{
+		ctx:     ctx,
+		cancel:  cancel,
+		service: services.NewPortfolioRebalanceService(transferer, deps.RepoManager, deps.Config.Rebalance),
+		events:  deps.Events,
+	}, nil
+}
+
+// ExecuteTradingCycle esegue un ribilanciamento
+func (w *PortfolioRebalanceWorker) ExecuteTradingCycle() {
+	ctx, cancel := context.WithTimeout(w.ctx, time.Minute)
+	defer cancel()
+
+	report, err := w.service.Rebalance(ctx)
+	if err != nil {
+		log.Printf("❌ Errore ribilanciamento portafoglio: %v", err)
+		return
+	}
+
+	for _, allocation := range report.Allocations {
+		log.Printf("Allocazione %s: %.2f %s (obiettivo %.2f, peso %.1f%%, scostamento %+.1f%%)",
+			allocation.Account, allocation.Balance, report.Coin, allocation.Target, allocation.Weight*100, allocation.Drift*100)
+	}
+	if len(report.Transfers) == 0 {
+		return
+	}
+
+	for _, transfer := range report.Transfers {
+		if transfer.Status == "FAILED" {
+			log.Println(i18n.T("alert.rebalance_failed", transfer.Amount, transfer.Coin, transfer.FromAccount, transfer.ToAccount, transfer.Error))
+		} else {
+			log.Println(i18n.T("alert.rebalance_transfer", transfer.Amount, transfer.Coin, transfer.FromAccount, transfer.ToAccount, transfer.TransferID, transfer.Status))
+		}
+		w.emitTransfer(transfer)
+	}
+}
+
+// emitTransfer pubblica funds.transfer per un trasferimento del ribilanciamento
+func (w *PortfolioRebalanceWorker) emitTransfer(transfer *models.RebalanceTransfer) {
+	ref := transfer.TransferID
+	if ref == "" {
+		ref = fmt.Sprintf("rebalance-%d-%d", transfer.ID, transfer.CreatedAt.UnixMilli())
+	}
+	event := events.NewEvent(events.TypeFundsTransfer, ref, "portfolio-rebalance", transfer.Coin)
+	event.Transfer = &events.Transfer{
+		TransferID: transfer.TransferID,
+		Coin:       transfer.Coin,
+		Amount:     transfer.Amount,
+		From:       transfer.FromAccount,
+		To:         transfer.ToAccount,
+		Status:     transfer.Status,
+		Error:      transfer.Error,
+	}
+	w.events.Emit(event)
+}
+
+// GetName implementa l'interfaccia Worker
+func (w *PortfolioRebalanceWorker) GetName() string {
+	return "Portfolio Rebalance Worker"
+}
+
+// Stop ferma il worker
+func (w *PortfolioRebalanceWorker) Stop() {
+	log.Println("Stopping Portfolio Rebalance Worker...")
+	w.cancel()
+}
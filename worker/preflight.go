@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"time"
+
+	"cross-exchange-arbitrage/preflight"
+)
+
+// preflightTimeout è il tempo massimo concesso ai controlli di avvio
+const preflightTimeout = 15 * time.Second
+
+// runPreflightChecks verifica IP di uscita e API key prima di avviare i worker, così che
+// permessi mancanti, key scadute o IP non autorizzati blocchino l'avvio invece di far
+// fallire gli ordini più tardi (es. con errori 401 improvvisi)
+func runPreflightChecks(deps *SystemDependencies) error {
+	cfg := deps.Config.Preflight
+	if !cfg.Enabled {
+		log.Println("⏭️  Controlli di avvio disabilitati (PREFLIGHT_ENABLED=false)")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+	defer cancel()
+
+	detector := preflight.NewHTTPIPDetector(cfg.IPCheckURL)
+	var egressIP net.IP
+
+	// IP attesi da configurazione: verificati prima della key, perché con un IP errato
+	// anche la chiamata a Bybit fallirebbe con un errore poco chiaro
+	if len(cfg.ExpectedIPs) > 0 {
+		ip, err := preflight.CheckEgressIP(ctx, detector, cfg.ExpectedIPs)
+		if err != nil {
+			return fmt.Errorf("controllo IP di uscita fallito: %w", err)
+		}
+		egressIP = ip
+		log.Printf("🌐 IP di uscita verificato: %s", egressIP)
+	}
+
+	// Key di sola lettura: deve essere davvero di sola lettura, altrimenti la separazione è inutile
+	if deps.Config.Bybit.ReadOnly.HasCredentials() {
+		if inspector, ok := deps.AccountReader.(preflight.APIKeyInspector); ok {
+			report, err := preflight.CheckReadOnlyAPIKey(ctx, inspector, cfg.KeyExpiryWarnDays, time.Now())
+			if err != nil {
+				return fmt.Errorf("controllo key di sola lettura fallito: %w", err)
+			}
+			logAPIKeyReport("key di sola lettura", report)
+		}
+	}
+
+	inspector, ok := deps.OrderProcessor.(preflight.APIKeyInspector)
+	if !ok {
+		// Nessun processor configurato (credenziali mancanti): non c'è una key da verificare
+		return nil
+	}
+
+	report, err := preflight.CheckAPIKey(ctx, inspector, preflight.TradingPermissions, cfg.KeyExpiryWarnDays, time.Now())
+	if err != nil {
+		return fmt.Errorf("controllo API key fallito: %w", err)
+	}
+	logAPIKeyReport("key di trading", report)
+
+	// IP autorizzati sulla key stessa: un IP di uscita diverso provocherebbe errori 401
+	if len(report.Info.IPs) > 0 && !slices.Contains(report.Info.IPs, "*") {
+		if egressIP == nil {
+			if egressIP, err = detector.PublicIP(ctx); err != nil {
+				return fmt.Errorf("controllo IP di uscita fallito: %w", err)
+			}
+		}
+		if err := preflight.MatchEgressIP(egressIP, report.Info.IPs); err != nil {
+			return fmt.Errorf("IP di uscita non autorizzato sulla API key: %w", err)
+		}
+		log.Printf("🌐 IP di uscita %s autorizzato sulla API key", egressIP)
+	}
+
+	return nil
+}
+
+// logAPIKeyReport registra avvisi e scadenza di una key verificata
+func logAPIKeyReport(label string, report *preflight.APIKeyReport) {
+	for _, warning := range report.Warnings {
+		log.Printf("⚠️  %s: %s", label, warning)
+	}
+	if report.ExpiresAt != nil {
+		log.Printf("🔑 %s verificata, scadenza %s", label, report.ExpiresAt.Format("2006-01-02"))
+	} else {
+		log.Printf("🔑 %s verificata, nessuna scadenza", label)
+	}
+}
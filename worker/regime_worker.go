@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/events"
+	"cross-exchange-arbitrage/exchange"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/strategy"
+)
+
+// regimeCandles è il numero di candele richieste per misurare il regime: oltre il minimo dell'indicatore,
+// le candele in più stabilizzano le medie di Wilder dell'ADX
+const regimeCandles = 500
+
+// RegimeWorker misura il regime di mercato (trend o laterale) dei simboli configurati e aggiorna il tracker
+// da cui le strategie leggono gli ingressi attivi; ogni cambio di regime viene segnalato e pubblicato come evento
+type RegimeWorker struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	cfg       config.RegimeConfig
+	timeframe models.Timeframe
+	detector  *strategy.RegimeDetector
+	regimes   *strategy.RegimeTracker
+	candles   exchange.Exchange
+	events    *events.Emitter // Pubblicazione dei cambi di regime; nil se disabilitata
+}
+
+// NewRegimeWorker crea il worker validando timeframe e parametri del metodo di misura
+func NewRegimeWorker(deps *SystemDependencies) (*RegimeWorker, error) {
+	cfg := deps.Config.Regime
+	if deps.Regimes == nil {
+		return nil, fmt.Errorf("tracker dei regimi non configurato")
+	}
+
+	timeframe := models.Timeframe(cfg.Timeframe)
+	if timeframe.Duration() == 0 {
+		return nil, fmt.Errorf("timeframe %q non supportato", cfg.Timeframe)
+	}
+	detector, err := strategy.NewRegimeDetector(strategy.RegimeParams{
+		Method:      cfg.Method,
+		ADXPeriod:   cfg.ADXPeriod,
+		ADXTrending: cfg.ADXTrending,
+		ADXRanging:  cfg.ADXRanging,
+		VRLag:       cfg.VRLag,
+		VRWindow:    cfg.VRWindow,
+		VRTrending:  cfg.VRTrending,
+		VRRanging:   cfg.VRRanging,
+		Source:      deps.Config.Indicators.Sources.Regime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if detector.MinCandles() > regimeCandles {
+		return nil, fmt.Errorf("il metodo %s richiede %d candele, al massimo %d", cfg.Method, detector.MinCandles(), regimeCandles)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RegimeWorker{
+		ctx:       ctx,
+		cancel:    cancel,
+		cfg:       cfg,
+		timeframe: timeframe,
+		detector:  detector,
+		regimes:   deps.Regimes,
+		candles:   deps.Exchange,
+		events:    deps.Events,
+	}, nil
+}
+
+// ExecuteTradingCycle misura il regime di ogni simbolo configurato
+func (w *RegimeWorker) ExecuteTradingCycle() {
+	ctx, cancel := context.WithTimeout(w.ctx, time.Minute)
+	defer cancel()
+
+	for _, symbol := range w.cfg.Symbols {
+		if err := w.measure(ctx, symbol); err != nil {
+			// Senza una nuova misura il simbolo resta nel regime precedente
+			log.Printf("❌ Regime %s: %v", symbol, err)
+		}
+	}
+}
+
+// measure classifica il regime del simbolo sulle candele chiuse e segnala i cambi
+func (w *RegimeWorker) measure(ctx context.Context, symbol string) error {
+	resp, err := w.candles.FetchLastCandles(ctx, symbol, models.DerivativesMarket, w.timeframe, regimeCandles)
+	if err != nil {
+		return fmt.Errorf("errore candele: %w", err)
+	}
+	value, err := w.detector.Measure(resp.Closed())
+	if err != nil {
+		return err
+	}
+
+	current, _ := w.regimes.Get(symbol)
+	regime := w.detector.Classify(value, current.Regime)
+	previous, changed := w.regimes.Update(symbol, regime, value, time.Now().UTC())
+	if !changed {
+		log.Printf("Regime %s: %s (%s %.2f, dal %s)", symbol, regime, w.detector.Method(), value, previous.Since.Format("2006-01-02 15:04"))
+		return nil
+	}
+
+	from := string(previous.Regime)
+	if from == "" {
+		from = "-"
+	}
+	log.Println(i18n.T("alert.regime_change", symbol, from, regime, w.detector.Method(), value))
+
+	event := events.NewEvent(events.TypeRegimeChanged, fmt.Sprintf("%s:%d", symbol, time.Now().Unix()), "regime", symbol)
+	event.Regime = &events.Regime{
+		Symbol:   symbol,
+		Regime:   string(regime),
+		Previous: string(previous.Regime),
+		Method:   w.detector.Method(),
+		Value:    value,
+	}
+	w.events.Emit(event)
+	return nil
+}
+
+// GetName implementa l'interfaccia CronWorker
+func (w *RegimeWorker) GetName() string {
+	return "Regime Worker"
+}
+
+// Stop ferma il worker
+func (w *RegimeWorker) Stop() {
+	log.Println("Stopping Regime Worker...")
+	w.cancel()
+}
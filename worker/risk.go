@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/exchange"
+	"cross-exchange-arbitrage/kraken"
+	"cross-exchange-arbitrage/orderprocessor"
+	"cross-exchange-arbitrage/repositories"
+	"cross-exchange-arbitrage/risk"
+)
+
+// newPriceChecker crea il controllo del prezzo di esecuzione configurato in RISK_PRICE_REFERENCE
+// Con "index" il riferimento è l'index price di perpVenue, la venue perpetual su cui opera il worker;
+// restituisce nil se il controllo è disabilitato
+func newPriceChecker(deps *SystemDependencies, perpVenue string) (*risk.PriceChecker, error) {
+	cfg := deps.Config.Risk
+
+	var feed risk.ReferenceFeed
+	switch cfg.PriceReference {
+	case "none":
+		return nil, nil
+	case "index":
+		provider, ok := deps.Exchanges[perpVenue].(exchange.FundingRateProvider)
+		if !ok {
+			return nil, fmt.Errorf("la venue %s non fornisce l'index price", perpVenue)
+		}
+		feed = risk.NewIndexFeed(perpVenue, provider)
+	default:
+		exch, ok := deps.Exchanges[cfg.PriceReference]
+		if !ok {
+			return nil, fmt.Errorf("venue di riferimento %s non configurata", cfg.PriceReference)
+		}
+		feed = risk.NewVenueFeed(cfg.PriceReference, exch)
+	}
+
+	return risk.NewPriceChecker(feed, cfg.MaxPriceDeviation)
+}
+
+// newRiskManager crea il risk manager con le sorgenti delle posizioni aperte di tutte le strategie:
+// le posizioni derivati di ogni venue con credenziali e le posizioni di funding arbitrage nel database
+func newRiskManager(cfg config.RiskConfig, repoManager repositories.RepositoryManager, processors map[string]orderprocessor.OrderProcessor) *risk.Manager {
+	manager := risk.NewManager(risk.Limits{
+		Symbols:          risk.SymbolLists{Allow: cfg.SymbolAllowList, Deny: cfg.SymbolDenyList},
+		MaxOpenPositions: cfg.MaxOpenPositions,
+	})
+
+	venues := make([]string, 0, len(processors))
+	for venue := range processors {
+		venues = append(venues, venue)
+	}
+	sort.Strings(venues)
+	for _, venue := range venues {
+		manager.AddPositionSource(venue, venuePositions(venue, processors[venue]))
+	}
+	manager.AddPositionSource("funding-arbitrage", risk.PositionSourceFunc(func(ctx context.Context) ([]string, error) {
+		positions, err := repoManager.FundingArb().GetAllActive(ctx)
+		if err != nil {
+			return nil, err
+		}
+		symbols := make([]string, 0, len(positions))
+		for _, position := range positions {
+			symbols = append(symbols, position.Symbol)
+		}
+		return symbols, nil
+	}))
+
+	log.Printf("🛡️  Limite posizioni: una per simbolo, massimo %d (0 = nessun limite), sorgenti %v + funding-arbitrage",
+		cfg.MaxOpenPositions, venues)
+	return manager
+}
+
+// venuePositions legge le posizioni derivati aperte di una venue, con i simboli nel formato del bot
+func venuePositions(venue string, processor orderprocessor.OrderProcessor) risk.PositionSource {
+	return risk.PositionSourceFunc(func(ctx context.Context) ([]string, error) {
+		positions, err := processor.GetPositions(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		symbols := make([]string, 0, len(positions))
+		for _, position := range positions {
+			if position.Parsed.Size == 0 {
+				continue
+			}
+			symbol := position.Symbol
+			if venue == "kraken" {
+				symbol = kraken.BotSymbol(symbol)
+			}
+			symbols = append(symbols, symbol)
+		}
+		return symbols, nil
+	})
+}
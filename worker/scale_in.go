@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"log"
+	"math"
+
+	"cross-exchange-arbitrage/correlation"
+	"cross-exchange-arbitrage/features"
+	"cross-exchange-arbitrage/models"
+)
+
+// scaleIn incrementa la posizione aperta del simbolo quando il prezzo si è mosso a favore di un passo
+// dall'ultimo ingresso (pyramiding). L'incremento è salvato come ordine collegato all'ingresso iniziale;
+// stop loss e take profit dell'intera posizione vengono ricalcolati sul nuovo prezzo medio di ingresso
+func (w *DogeTradingSystemWorker) scaleIn(symbol string) {
+	if w.pyramid == nil || !w.flags.Enabled(features.Pyramiding) {
+		return
+	}
+
+	positions, err := w.orderProcessor.GetPositions(w.ctx, symbol)
+	if err != nil {
+		log.Printf("⚠️  Pyramiding: errore nel recupero della posizione %s: %v", symbol, err)
+		return
+	}
+	if len(positions) == 0 || !positions[0].Parsed.IsActive() {
+		return
+	}
+	exchangePosition := positions[0].Parsed
+	markPrice := exchangePosition.MarkPrice
+	if markPrice <= 0 {
+		log.Printf("⚠️  Pyramiding: mark price di %s non disponibile", symbol)
+		return
+	}
+
+	position, err := w.positions.OpenPosition(w.ctx, symbol)
+	if err != nil {
+		log.Printf("⚠️  Pyramiding: %v", err)
+		return
+	}
+	side := models.OrderSideBuy
+	if exchangePosition.IsShort() {
+		side = models.OrderSideSell
+	}
+	if position == nil || string(position.Entry.Side) != string(side) {
+		log.Printf("⚠️  Pyramiding: la posizione %s %s non corrisponde a un ingresso aperto nel database", side, symbol)
+		return
+	}
+	// Gli incrementi partono solo dopo l'ultima frazione di un ingresso frazionato
+	if w.slicer != nil {
+		slicing, err := w.slicer.Active(w.ctx, position.Entry.OrderID)
+		if err != nil {
+			log.Printf("⚠️  Pyramiding: %v", err)
+			return
+		}
+		if slicing {
+			return
+		}
+	}
+
+	quantity, ok := w.pyramid.NextAdd(side, position.Level(), position.LastPrice(), markPrice, position.Entry.Quantity)
+	if !ok {
+		return
+	}
+	quantity = math.Min(quantity, w.calculateMaxQuantity(markPrice))
+	if quantity <= 0 {
+		log.Printf("⚠️  Pyramiding: saldo insufficiente per incrementare %s", symbol)
+		return
+	}
+
+	// Stop loss e take profit dell'intera posizione, dal prezzo medio dopo l'incremento
+	average := position.AverageEntryAfter(markPrice, quantity)
+	placeOrder := w.orderProcessor.PlaceLongOrder
+	stopLoss := w.calculateLongStopLoss(average, 0.008)
+	takeProfit := w.calculateLongTakeProfit(average, 0.03)
+	valid := stopLoss < markPrice && takeProfit > markPrice
+	if side == models.OrderSideSell {
+		placeOrder = w.orderProcessor.PlaceShortOrder
+		stopLoss = w.calculateShortStopLoss(average, 0.008)
+		takeProfit = w.calculateShortTakeProfit(average, 0.03)
+		valid = stopLoss > markPrice && takeProfit < markPrice
+	}
+	if !valid {
+		log.Printf("⏸️  Pyramiding: prezzo %.6f oltre stop o take profit ricalcolati (%.6f / %.6f), nessun incremento",
+			markPrice, stopLoss, takeProfit)
+		return
+	}
+
+	level := position.Level() + 1
+	correlation.Logf(w.ctx, "📈 Incremento %d %s %s: %.2f a %.6f (prezzo medio %.6f -> %.6f)",
+		level, side, symbol, quantity, markPrice, position.AverageEntry(), average)
+
+	if err := w.spread.Check(w.ctx, symbol, w.exchange.GetRealTimePrice); err != nil {
+		correlation.Logf(w.ctx, "⏸️  Pyramiding: incremento non inviato: %v", err)
+		return
+	}
+	if err := w.throttle.Acquire(w.ctx, "bybit", symbol); err != nil {
+		return
+	}
+	response, err := placeOrder(w.ctx, symbol, markPrice, quantity, stopLoss, takeProfit)
+	if err != nil {
+		correlation.Logf(w.ctx, "❌ Pyramiding: errore nel piazzamento dell'incremento: %v", err)
+		return
+	}
+	if !response.IsSuccess() {
+		correlation.Logf(w.ctx, "❌ Pyramiding: incremento rifiutato - %s (codice: %s)", response.ErrorMessage, response.ErrorCode)
+		return
+	}
+
+	add, err := w.createOrderFromBybitResponse(response, models.OrderSideType(side), markPrice, quantity, takeProfit, stopLoss)
+	if err == nil {
+		err = w.positions.RecordAdd(w.ctx, position, add)
+	}
+	if err != nil {
+		correlation.Logf(w.ctx, "⚠️  ATTENZIONE: incremento %s piazzato ma NON salvato nel database: %v", response.OrderID, err)
+	}
+
+	if err := w.positions.AdjustStops(w.ctx, symbol, stopLoss, takeProfit); err != nil {
+		correlation.Logf(w.ctx, "⚠️  Pyramiding: stop della posizione non aggiornati: %v", err)
+	}
+	w.scheduleUnfilledCancel(response.OrderID)
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"log"
+
+	"cross-exchange-arbitrage/correlation"
+	"cross-exchange-arbitrage/events"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/models"
+)
+
+// newSignal crea la spiegazione di un segnale DOGEUSDT del ciclo corrente, che i filtri completano con le condizioni valutate
+func (w *DogeTradingSystemWorker) newSignal(side models.OrderSide, trigger string) *models.SignalExplanation {
+	explain := models.NewSignalExplanation("doge-trading-system", "DOGEUSDT", side, trigger)
+	explain.CorrelationID = correlation.ID(w.ctx)
+	return explain
+}
+
+// recordSignal chiude la spiegazione con l'esito, la registra nei log, la salva e la pubblica nell'evento signal.decided
+// Gli ingressi piazzati e quelli falliti vengono segnalati anche con un alert
+func (w *DogeTradingSystemWorker) recordSignal(explain *models.SignalExplanation, outcome models.SignalOutcome, orderID string) {
+	explain.Outcome = outcome
+	explain.OrderID = orderID
+	correlation.Logf(w.ctx, "🧭 %s", explain)
+
+	switch outcome {
+	case models.SignalOutcomeEntered:
+		log.Println(i18n.T("alert.signal_entered", explain.Symbol, explain.Side, explain.Trigger, orderID, explain.Conditions()))
+	case models.SignalOutcomeFailed:
+		log.Println(i18n.T("alert.signal_failed", explain.Symbol, explain.Side, explain.Trigger, explain.Conditions()))
+	}
+
+	decision, err := models.NewSignalDecision(explain)
+	if err == nil {
+		err = w.repoManager.SignalDecision().Create(w.ctx, decision)
+	}
+	if err != nil {
+		log.Printf("⚠️  Spiegazione del segnale %s non salvata nel database: %v", explain.Trigger, err)
+	}
+
+	if w.events == nil {
+		return
+	}
+	ref := explain.CorrelationID
+	if ref == "" {
+		ref = explain.CreatedAt.Format("20060102T150405.000000000")
+	}
+	event := events.NewEvent(events.TypeSignalDecided, explain.Strategy+":"+ref, explain.Strategy, explain.Symbol)
+	event.Signal = signalEventData(explain)
+	w.events.Emit(event)
+}
+
+// signalEventData converte la spiegazione nel payload dell'evento
+func signalEventData(explain *models.SignalExplanation) *events.Signal {
+	checks := make([]events.SignalCheck, len(explain.Checks))
+	for i, check := range explain.Checks {
+		checks[i] = events.SignalCheck{
+			Name:      check.Name,
+			Passed:    check.Passed,
+			Applied:   check.Applied,
+			Value:     check.Value,
+			Threshold: check.Threshold,
+			Detail:    check.Detail,
+		}
+	}
+	return &events.Signal{
+		Strategy: explain.Strategy,
+		Side:     string(explain.Side),
+		Trigger:  explain.Trigger,
+		Outcome:  string(explain.Outcome),
+		OrderID:  explain.OrderID,
+		Checks:   checks,
+	}
+}
@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/scoring"
+)
+
+// scoreSignal sottopone il segnale al modello esterno e restituisce la frazione della quantità da usare
+// candles sono le candele chiuse in ordine cronologico, l'ultima è quella del segnale
+// Restituisce 0 se il modello scarta il trade; senza scorer configurato restituisce 1
+func (w *DogeTradingSystemWorker) scoreSignal(explain *models.SignalExplanation, candles []models.Candle) float64 {
+	if w.scorer == nil {
+		return 1
+	}
+
+	features, err := scoring.BreakoutFeatures("DOGEUSDT", explain.Side, candles, w.indicators.Sources)
+	if err != nil {
+		return w.scorerUnavailable(explain, err)
+	}
+	score, err := w.scorer.Score(w.ctx, features)
+	if err != nil {
+		return w.scorerUnavailable(explain, err)
+	}
+
+	decision := w.scorerPolicy.Decide(score)
+	explain.Check("scorer", decision.Allow, decision.Score, w.scorerPolicy.MinScore, decision.Reason)
+	if !decision.Allow {
+		return 0
+	}
+	return decision.SizeMultiplier
+}
+
+// scorerUnavailable applica SCORER_FAIL_OPEN quando il segnale non può essere valutato
+func (w *DogeTradingSystemWorker) scorerUnavailable(explain *models.SignalExplanation, err error) float64 {
+	if w.scorerFailOpen {
+		explain.Skipped("scorer", "scorer non disponibile, quantità piena: "+err.Error())
+		return 1
+	}
+	explain.Verdict("scorer", false, "scorer non disponibile: "+err.Error())
+	return 0
+}
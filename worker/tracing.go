@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+
+	"cross-exchange-arbitrage/correlation"
+)
+
+// cycleContext è il contesto dei worker tracciati: a ogni ciclo deriva dal contesto base, cancellato da Stop,
+// un contesto con l'identificativo del ciclo, usato per log, chiamate Bybit e scritture sul database
+// Il contesto del ciclo viene cancellato a fine ciclo o dal watchdog se il ciclo resta bloccato
+type cycleContext struct {
+	base context.Context
+	ctx  context.Context
+}
+
+// newCycleContext crea il contesto a partire da quello base del worker
+func newCycleContext(base context.Context) cycleContext {
+	return cycleContext{base: base, ctx: base}
+}
+
+// BeginCycle implementa TracedWorker
+func (c *cycleContext) BeginCycle(cycleID string) context.CancelFunc {
+	ctx, cancel := context.WithCancel(correlation.WithCycleID(c.base, cycleID))
+	c.ctx = ctx
+	return cancel
+}
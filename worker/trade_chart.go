@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"log"
+
+	"cross-exchange-arbitrage/chart"
+	"cross-exchange-arbitrage/models"
+)
+
+// renderTradeChart disegna il grafico dell'ingresso appena aperto (ultime candele chiuse con ingresso,
+// stop loss, take profit e livello rotto) e lo salva accanto agli altri, con l'ID dell'ordine come nome
+// Il grafico è un supporto alla verifica visiva: un errore viene solo registrato e non tocca il trade
+func (w *DogeTradingSystemWorker) renderTradeChart(orderID string, side models.OrderSide, closedCandles []models.Candle, level float64) {
+	if !w.charts.Enabled || len(closedCandles) == 0 {
+		return
+	}
+
+	order, err := w.repoManager.Order().GetByOrderID(w.ctx, orderID)
+	if err != nil {
+		log.Printf("⚠️  Grafico dell'ordine %s non disegnato: ordine non trovato nel database: %v", orderID, err)
+		return
+	}
+
+	candles := closedCandles[max(0, len(closedCandles)-w.charts.Candles):]
+	annotations := chart.Annotations{
+		Side:  side,
+		Entry: order.OrderPrice,
+		Level: level,
+	}
+	if order.StopLossPrice != nil {
+		annotations.StopLoss = *order.StopLossPrice
+	}
+	if order.TakeProfitPrice != nil {
+		annotations.TakeProfit = *order.TakeProfitPrice
+	}
+
+	data, err := chart.RenderPNG(candles, annotations, chart.Options{})
+	if err != nil {
+		log.Printf("⚠️  Grafico dell'ordine %s non disegnato: %v", orderID, err)
+		return
+	}
+	path, err := chart.Save(w.charts.Dir, orderID, data)
+	if err != nil {
+		log.Printf("⚠️  Grafico dell'ordine %s non salvato: %v", orderID, err)
+		return
+	}
+	log.Printf("📈 Grafico dell'ingresso %s salvato in %s (GET /orders/%s/chart)", orderID, path, orderID)
+}
@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cross-exchange-arbitrage/errorreport"
+	"cross-exchange-arbitrage/i18n"
+)
+
+// restartTimeout è il tempo massimo concesso al riavvio di un componente
+const restartTimeout = 30 * time.Second
+
+// Monitored è un componente di lunga durata controllato dal watchdog (es. il WebSocket dei prezzi Bybit)
+type Monitored interface {
+	// LastActivity restituisce l'ultimo segno di vita del componente; zero se non è attivo
+	LastActivity() time.Time
+
+	// Restart riavvia il componente scartando lo stato non più aggiornato
+	Restart(ctx context.Context) error
+}
+
+// monitoredComponent è un componente registrato con la soglia oltre cui è considerato bloccato
+type monitoredComponent struct {
+	name      string
+	component Monitored
+	timeout   time.Duration
+}
+
+// watchdog controlla periodicamente i cicli dei worker e i componenti registrati
+type watchdog struct {
+	interval      time.Duration
+	workerTimeout time.Duration // Durata oltre cui un ciclo è considerato bloccato
+	components    []monitoredComponent
+}
+
+// SetWatchdog attiva il watchdog: ogni interval controlla i cicli in esecuzione da più di workerTimeout
+// e i componenti registrati con Monitor; va chiamato prima di Start
+func (wm *WorkerManager) SetWatchdog(interval, workerTimeout time.Duration) {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	wm.watchdog = &watchdog{interval: interval, workerTimeout: workerTimeout}
+	log.Printf("🐕 Watchdog attivo: controllo ogni %v, cicli bloccati oltre %v", interval, workerTimeout)
+}
+
+// Monitor registra un componente da riavviare se non dà segni di vita da più di timeout
+func (wm *WorkerManager) Monitor(name string, component Monitored, timeout time.Duration) error {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	if wm.watchdog == nil {
+		return fmt.Errorf("watchdog non attivo: chiamare prima SetWatchdog")
+	}
+	wm.watchdog.components = append(wm.watchdog.components, monitoredComponent{name: name, component: component, timeout: timeout})
+	log.Printf("🐕 Watchdog: %s riavviato dopo %v senza attività", name, timeout)
+	return nil
+}
+
+// watch esegue i controlli del watchdog fino alla cancellazione del context
+func (wm *WorkerManager) watch(ctx context.Context) {
+	ticker := time.NewTicker(wm.watchdog.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			wm.checkWorkers(now)
+			wm.checkComponents(ctx, now)
+		}
+	}
+}
+
+// checkWorkers interrompe i cicli in esecuzione da più della soglia
+// Il context del ciclo viene cancellato, così le chiamate in corso terminano e il ciclo successivo parte da capo;
+// i worker che non implementano TracedWorker non sono interrompibili e vengono solo segnalati
+func (wm *WorkerManager) checkWorkers(now time.Time) {
+	wm.mutex.RLock()
+	defer wm.mutex.RUnlock()
+
+	for _, name := range wm.order {
+		state, ok := wm.states[name]
+		if !ok || !state.running.Load() {
+			continue
+		}
+
+		state.mu.Lock()
+		if state.hung || state.cycleStart.IsZero() || now.Sub(state.cycleStart) < wm.watchdog.workerTimeout {
+			state.mu.Unlock()
+			continue
+		}
+		state.hung = true
+		cycleID, started, cancel := state.cycleID, state.cycleStart, state.cancelCycle
+		state.mu.Unlock()
+
+		if cancel == nil {
+			wm.alert(name, cycleID, i18n.T("alert.watchdog_stuck", name, now.Sub(started).Round(time.Second), cycleID))
+			continue
+		}
+		wm.alert(name, cycleID, i18n.T("alert.watchdog_interrupted", name, now.Sub(started).Round(time.Second), cycleID))
+		cancel()
+	}
+}
+
+// checkComponents riavvia i componenti senza attività da più della loro soglia
+func (wm *WorkerManager) checkComponents(ctx context.Context, now time.Time) {
+	for _, monitored := range wm.watchdog.components {
+		last := monitored.component.LastActivity()
+		if last.IsZero() || now.Sub(last) < monitored.timeout {
+			continue
+		}
+
+		wm.alert(monitored.name, "", i18n.T("alert.watchdog_silent", monitored.name, now.Sub(last).Round(time.Second)))
+		restartCtx, cancel := context.WithTimeout(ctx, restartTimeout)
+		err := monitored.component.Restart(restartCtx)
+		cancel()
+		if err != nil {
+			log.Printf("❌ Watchdog: riavvio di %s fallito, nuovo tentativo al prossimo controllo: %v", monitored.name, err)
+			continue
+		}
+		log.Printf("✅ Watchdog: %s riavviato", monitored.name)
+	}
+}
+
+// alert segnala un intervento del watchdog nei log e, se configurato, su Sentry
+func (wm *WorkerManager) alert(name, cycleID, message string) {
+	log.Println(i18n.T("alert.watchdog", message))
+	wm.errors.CaptureError(errors.New(message), errorreport.Context{
+		Component:   "watchdog",
+		Worker:      name,
+		CycleID:     cycleID,
+		Fingerprint: []string{"watchdog", name},
+	})
+}
@@ -4,12 +4,26 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"cross-exchange-arbitrage/api"
+	"cross-exchange-arbitrage/book"
+	"cross-exchange-arbitrage/config"
+	"cross-exchange-arbitrage/correlation"
+	"cross-exchange-arbitrage/errorreport"
+	"cross-exchange-arbitrage/i18n"
+	"cross-exchange-arbitrage/models"
+	"cross-exchange-arbitrage/services"
+	"cross-exchange-arbitrage/simrand"
+
 	"github.com/robfig/cron/v3"
 )
 
@@ -25,23 +39,82 @@ type CronWorker interface {
 	GetName() string
 }
 
+// TracedWorker è implementato dai worker che propagano l'identificativo del ciclo a log, chiamate Bybit
+// e audit trail degli ordini, così un trade si ricostruisce cercando un solo identificativo
+type TracedWorker interface {
+	// BeginCycle riceve l'identificativo del ciclo prima di ExecuteTradingCycle o WarmUp e restituisce
+	// la funzione che interrompe le operazioni del ciclo, usata dal watchdog e chiamata a fine ciclo
+	BeginCycle(cycleID string) context.CancelFunc
+}
+
 // WorkerConfig contiene la configurazione per un worker
 type WorkerConfig struct {
 	Name        string     // Nome identificativo del worker
-	Schedule    string     // Cron schedule (es: "* * * * *" per ogni minuto)
+	Schedule    string     // Cron schedule (es: "* * * * *" per ogni minuto); vuoto se eseguito solo a valle di DependsOn
 	Worker      CronWorker // Istanza del worker
 	Enabled     bool       // Se il worker è abilitato
 	Description string     // Descrizione del worker
+	// Jitter è il ritardo casuale massimo prima di ogni esecuzione (0 = jitter di default del manager)
+	Jitter time.Duration
+	// DependsOn elenca i worker a monte: completati tutti i loro cicli, questo worker viene eseguito
+	// subito dopo nella stessa pipeline (es. sincronizzazione candele → strategia → reportistica)
+	DependsOn []string
+	// LockKey è la risorsa da detenere in esclusiva tra più istanze del bot (es. trading:DOGEUSDT)
+	// Con il lock distribuito attivo, il ciclo viene saltato se un'altra istanza detiene il lease
+	LockKey string
+	// LeaderOnly limita le esecuzioni all'istanza leader; in standby viene chiamato WarmUp se il worker
+	// implementa StandbyWorker, altrimenti il ciclo viene saltato
+	LeaderOnly bool
+	// Symbol è il simbolo su cui opera il worker, riportato nel contesto dei panic (vuoto se non legato a un simbolo)
+	Symbol string
+	// Timeframe è il timeframe delle candele su cui lavora il worker: con lo scheduler alla chiusura delle candele
+	// il worker parte dopo ogni chiusura invece che su Schedule (vuoto = sempre Schedule)
+	Timeframe models.Timeframe
+}
+
+// workerState contiene lo stato runtime di un worker abilitato
+type workerState struct {
+	entryID      cron.EntryID  // Entry del cron; non valida mentre il worker è in pausa
+	job          func()        // Job registrato sul cron, riusato alla ripresa dalla pausa
+	schedule     cron.Schedule // Schedule alla chiusura delle candele; nil se il worker segue la schedule cron
+	label        string        // Schedule effettiva mostrata nello stato dei worker
+	paused       bool
+	running      atomic.Bool // Un ciclo è in esecuzione: evita esecuzioni sovrapposte
+	mu           sync.Mutex  // Protegge lastRun e lastDuration, aggiornati a fine ciclo
+	lastRun      time.Time
+	lastDuration time.Duration
+	lastComplete time.Time // Fine dell'ultimo ciclo completato senza panic, usata dalle pipeline
+
+	// Ciclo in esecuzione, controllato dal watchdog (protetti da mu)
+	cycleID     string
+	cycleStart  time.Time
+	cancelCycle context.CancelFunc // nil se il worker non implementa TracedWorker
+	hung        bool               // Il watchdog ha già interrotto o segnalato il ciclo
 }
 
 // WorkerManager gestisce tutti i worker con cron scheduling
 type WorkerManager struct {
 	cron      *cron.Cron
 	workers   map[string]*WorkerConfig
+	states    map[string]*workerState // Stato runtime dei worker abilitati
+	order     []string                // Nomi in ordine di registrazione, per eseguire le pipeline in modo deterministico
 	ctx       context.Context
 	cancel    context.CancelFunc
 	mutex     sync.RWMutex
 	isRunning bool
+	hooks     []func()              // Funzioni eseguite dopo l'arresto dei worker (es. chiusura API e database)
+	jitter    time.Duration         // Ritardo casuale massimo di default prima di ogni esecuzione
+	jitterSrc *simrand.Source       // Sorgente delle estrazioni del jitter (nil = non riproducibile)
+	stagger   time.Duration         // Sfasamento tra worker con la stessa schedule
+	slots     map[string]int        // Worker abilitati per schedule, per calcolare lo sfasamento
+	leases    *leaseKeeper          // Lease condivisi tra istanze; nil se il lock distribuito è disabilitato
+	election  *leaderElection       // Elezione del leader per l'hot standby; nil se disabilitata
+	errors    *errorreport.Reporter // Invio dei panic a Sentry; nil se disabilitato
+	watchdog  *watchdog             // Controllo dei cicli bloccati e dei componenti; nil se disabilitato
+
+	// Scheduler alla chiusura delle candele: i worker con Timeframe partono dopo ogni chiusura, con candleDelay di ritardo
+	candleClose bool
+	candleDelay time.Duration
 }
 
 // NewWorkerManager crea una nuova istanza di WorkerManager
@@ -54,11 +127,68 @@ func NewWorkerManager() *WorkerManager {
 	return &WorkerManager{
 		cron:    cron.New(cron.WithLogger(cronLogger), cron.WithSeconds()),
 		workers: make(map[string]*WorkerConfig),
+		states:  make(map[string]*workerState),
+		slots:   make(map[string]int),
 		ctx:     ctx,
 		cancel:  cancel,
 	}
 }
 
+// SetExecutionSpread distribuisce le esecuzioni dei worker per non colpire le API tutti allo stesso secondo
+// I worker con la stessa schedule partono sfasati di stagger nell'ordine di registrazione, più un ritardo
+// casuale fino a jitter estratto da src; va chiamato prima di registrare i worker
+func (wm *WorkerManager) SetExecutionSpread(jitter, stagger time.Duration, src *simrand.Source) {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	wm.jitter = jitter
+	wm.stagger = stagger
+	wm.jitterSrc = src
+}
+
+// SetCandleCloseTrigger esegue i worker con un Timeframe subito dopo la chiusura di ogni loro candela, con il ritardo
+// indicato, invece che sulla schedule cron, che non segue i confini delle candele; va chiamato prima di registrare i worker
+func (wm *WorkerManager) SetCandleCloseTrigger(delay time.Duration) {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	wm.candleClose = true
+	wm.candleDelay = delay
+	log.Printf("🕯️  Worker con timeframe eseguiti alla chiusura delle candele (ritardo %v)", delay)
+}
+
+// SetLocker abilita il lock distribuito: i worker con LockKey eseguono i cicli solo se questa istanza
+// detiene il lease della risorsa per l'account indicato; va chiamato prima di Start
+func (wm *WorkerManager) SetLocker(locker Locker, account, owner string, ttl time.Duration) {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	wm.leases = newLeaseKeeper(locker, account, owner, ttl)
+	log.Printf("🔒 Lock distribuito attivo per l'account %s (istanza %s, TTL %v)", account, wm.leases.owner, ttl)
+}
+
+// SetErrorReporter invia a Sentry i panic dei worker con nome, simbolo e identificativo del ciclo
+func (wm *WorkerManager) SetErrorReporter(reporter *errorreport.Reporter) {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	wm.errors = reporter
+}
+
+// EnableStandby abilita l'elezione del leader sui lease di SetLocker: i worker LeaderOnly operano solo
+// sull'istanza leader, mentre le istanze in standby ne mantengono lo stato aggiornato con WarmUp
+func (wm *WorkerManager) EnableStandby() error {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	if wm.leases == nil {
+		return fmt.Errorf("standby richiede il lock distribuito: chiamare prima SetLocker")
+	}
+	wm.election = newLeaderElection(wm.leases)
+	log.Printf("💤 Hot standby attivo: l'istanza %s parte in standby fino all'elezione", wm.leases.owner)
+	return nil
+}
+
 // RegisterWorker registra un nuovo worker con la sua schedulazione
 func (wm *WorkerManager) RegisterWorker(config *WorkerConfig) error {
 	wm.mutex.Lock()
@@ -68,51 +198,400 @@ func (wm *WorkerManager) RegisterWorker(config *WorkerConfig) error {
 		return fmt.Errorf("worker %s già registrato", config.Name)
 	}
 
+	// Le dipendenze vanno registrate prima: l'ordine di registrazione esclude i cicli
+	for _, dependency := range config.DependsOn {
+		upstream, exists := wm.workers[dependency]
+		if !exists {
+			return fmt.Errorf("worker %s dipende da %s, non registrato: registrare prima i worker a monte", config.Name, dependency)
+		}
+		if config.Enabled && !upstream.Enabled {
+			log.Printf("⚠️  Worker %s dipende da %s, che è DISABILITATO: la pipeline non lo eseguirà", config.Name, dependency)
+		}
+	}
+	if config.Schedule == "" && len(config.DependsOn) == 0 {
+		return fmt.Errorf("worker %s senza schedule né dipendenze", config.Name)
+	}
+
 	if !config.Enabled {
 		log.Printf("⚠️  Worker %s registrato ma DISABILITATO", config.Name)
 		wm.workers[config.Name] = config
+		wm.order = append(wm.order, config.Name)
 		return nil
 	}
 
-	// Wrapper per il job che gestisce errori e context
-	jobWrapper := func() {
-		select {
-		case <-wm.ctx.Done():
-			log.Printf("🛑 Worker %s: Context cancellato, salto esecuzione", config.Name)
-			return
-		default:
+	// Con lo scheduler alla chiusura delle candele la schedule cron resta solo per i worker senza timeframe
+	state := &workerState{label: config.Schedule}
+	if wm.candleClose && config.Timeframe != "" && config.Schedule != "" {
+		schedule, err := newCandleCloseSchedule(config.Timeframe, wm.candleDelay)
+		if err != nil {
+			return fmt.Errorf("worker %s: %w", config.Name, err)
 		}
+		state.schedule = schedule
+		state.label = schedule.String()
+	}
 
-		log.Printf("🚀 Worker %s: Inizio esecuzione ciclo", config.Name)
-		start := time.Now()
+	// Sfasamento fisso rispetto agli altri worker con la stessa schedule, più il jitter casuale
+	offset := time.Duration(wm.slots[state.label]) * wm.stagger
+	jitter := config.Jitter
+	if jitter <= 0 {
+		jitter = wm.jitter
+	}
 
-		// Recupera panic per evitare crash del cron
-		defer func() {
-			if r := recover(); r != nil {
-				log.Printf("❌ Worker %s: PANIC recuperato: %v", config.Name, r)
-			}
-		}()
+	// Wrapper per il job che gestisce errori, context e sovrapposizioni
+	state.job = func() {
+		if !wm.waitSpread(offset, jitter) {
+			log.Printf("🛑 Worker %s: Context cancellato, salto esecuzione", config.Name)
+			return
+		}
+		if !state.running.CompareAndSwap(false, true) {
+			log.Printf("⏭️  Worker %s: ciclo precedente ancora in esecuzione, salto esecuzione", config.Name)
+			return
+		}
+		wm.runCycle(config, state)
+	}
 
-		// Esegui il worker
-		config.Worker.ExecuteTradingCycle()
+	wm.workers[config.Name] = config
+	wm.states[config.Name] = state
+	wm.order = append(wm.order, config.Name)
 
-		duration := time.Since(start)
-		log.Printf("✅ Worker %s: Ciclo completato in %v", config.Name, duration)
+	// I worker senza schedule vengono eseguiti solo a valle delle loro dipendenze
+	if config.Schedule == "" {
+		log.Printf("✅ Worker %s registrato a valle di %v", config.Name, config.DependsOn)
+		return nil
 	}
 
 	// Aggiungi il job al cron
-	entryID, err := wm.cron.AddFunc(config.Schedule, jobWrapper)
+	entryID, err := wm.addEntry(config, state)
 	if err != nil {
+		delete(wm.workers, config.Name)
+		delete(wm.states, config.Name)
+		wm.order = wm.order[:len(wm.order)-1]
 		return fmt.Errorf("errore aggiunta job cron per worker %s: %w", config.Name, err)
 	}
+	state.entryID = entryID
 
-	wm.workers[config.Name] = config
-	log.Printf("✅ Worker %s registrato con schedule '%s' (Entry ID: %d)",
-		config.Name, config.Schedule, entryID)
+	wm.slots[state.label]++
+	log.Printf("✅ Worker %s registrato con schedule '%s' (Entry ID: %d, sfasamento %v, jitter %v)",
+		config.Name, state.label, entryID, offset, jitter)
+
+	return nil
+}
+
+// addEntry registra il job del worker sul cron, alla chiusura delle candele o con la schedule cron
+func (wm *WorkerManager) addEntry(config *WorkerConfig, state *workerState) (cron.EntryID, error) {
+	if state.schedule != nil {
+		return wm.cron.Schedule(state.schedule, cron.FuncJob(state.job)), nil
+	}
+	return wm.cron.AddFunc(config.Schedule, state.job)
+}
+
+// runCycle esegue un ciclo del worker e poi la pipeline dei worker a valle
+// Il chiamante ha già impostato il flag running
+func (wm *WorkerManager) runCycle(config *WorkerConfig, state *workerState) {
+	if wm.executeCycle(config, state) {
+		wm.runDependents(config.Name)
+	}
+}
+
+// executeCycle esegue un ciclo del worker; restituisce false se il ciclo è terminato con un panic
+func (wm *WorkerManager) executeCycle(config *WorkerConfig, state *workerState) (completed bool) {
+	defer state.running.Store(false)
+
+	// In standby i worker del leader aggiornano solo lo stato; il ciclo non attiva la pipeline a valle
+	run := config.Worker.ExecuteTradingCycle
+	standby := config.LeaderOnly && wm.election != nil && !wm.election.isLeader()
+	if standby {
+		warmer, ok := config.Worker.(StandbyWorker)
+		if !ok {
+			log.Printf("💤 Worker %s: istanza in standby, salto esecuzione", config.Name)
+			return false
+		}
+		run = warmer.WarmUp
+	} else if !wm.holdsLease(config) {
+		return false
+	}
+
+	// L'identificativo del ciclo accompagna log, chiamate Bybit e audit trail dei worker che implementano TracedWorker
+	cycleID := correlation.NewID()
+	var cancelCycle context.CancelFunc
+	if traced, ok := config.Worker.(TracedWorker); ok {
+		cancelCycle = traced.BeginCycle(cycleID)
+	}
+
+	if standby {
+		log.Printf("💤 Worker %s: istanza in standby, aggiornamento dello stato (ciclo %s)", config.Name, cycleID)
+	} else {
+		log.Printf("🚀 Worker %s: Inizio esecuzione ciclo %s", config.Name, cycleID)
+	}
+	start := time.Now()
+	state.mu.Lock()
+	state.cycleID, state.cycleStart, state.cancelCycle, state.hung = cycleID, start, cancelCycle, false
+	state.mu.Unlock()
+
+	// Recupera panic per evitare crash del cron
+	defer func() {
+		if cancelCycle != nil {
+			cancelCycle()
+		}
+		if r := recover(); r != nil {
+			log.Printf("❌ Worker %s: PANIC recuperato (ciclo %s): %v", config.Name, cycleID, r)
+			// Raggruppa per worker e stack trace: lo stesso bug su worker diversi resta distinto
+			wm.errors.CapturePanic(r, errorreport.Context{
+				Component:   "worker",
+				Worker:      config.Name,
+				Symbol:      config.Symbol,
+				CycleID:     cycleID,
+				Fingerprint: []string{"worker-panic", config.Name, "{{ default }}"},
+				Extra:       map[string]interface{}{"standby": standby},
+			})
+			completed = false
+		}
+		state.mu.Lock()
+		state.lastRun = start
+		state.lastDuration = time.Since(start)
+		state.cycleStart, state.cancelCycle = time.Time{}, nil
+		// Un ciclo interrotto dal watchdog non attiva la pipeline a valle
+		if state.hung {
+			log.Printf("⚠️  Worker %s: ciclo %s terminato dopo l'intervento del watchdog", config.Name, cycleID)
+			completed = false
+		}
+		if completed {
+			state.lastComplete = time.Now()
+		}
+		state.mu.Unlock()
+	}()
+
+	// Esegui il worker
+	run()
+
+	duration := time.Since(start)
+	log.Printf("✅ Worker %s: Ciclo %s completato in %v", config.Name, cycleID, duration)
+	return !standby
+}
+
+// holdsLease acquisisce o rinnova il lease del worker; senza lock distribuito o LockKey il ciclo procede
+// In caso di errore del lock il ciclo viene saltato: meglio perdere un ciclo che operare due volte
+func (wm *WorkerManager) holdsLease(config *WorkerConfig) bool {
+	if wm.leases == nil || config.LockKey == "" {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(wm.ctx, 10*time.Second)
+	defer cancel()
+	acquired, err := wm.leases.acquire(ctx, config.LockKey)
+	if err != nil {
+		log.Printf("❌ Worker %s: %v, salto esecuzione", config.Name, err)
+		return false
+	}
+	if !acquired {
+		log.Printf("⏭️  Worker %s: lock %s detenuto da un'altra istanza, salto esecuzione", config.Name, wm.leases.key(config.LockKey))
+		return false
+	}
+	return true
+}
+
+// runDependents esegue in ordine di registrazione i worker che dipendono da upstream
+// Un worker a valle parte solo se tutte le sue dipendenze hanno completato un ciclo dopo il suo ultimo avvio
+func (wm *WorkerManager) runDependents(upstream string) {
+	if wm.ctx.Err() != nil {
+		return
+	}
+
+	type downstream struct {
+		config *WorkerConfig
+		state  *workerState
+	}
+	var ready []downstream
+
+	wm.mutex.RLock()
+	for _, name := range wm.order {
+		config := wm.workers[name]
+		state, ok := wm.states[name]
+		if !ok || state.paused || !slices.Contains(config.DependsOn, upstream) || !wm.dependenciesCompletedLocked(config, state) {
+			continue
+		}
+		ready = append(ready, downstream{config: config, state: state})
+	}
+	wm.mutex.RUnlock()
+
+	for _, next := range ready {
+		if !next.state.running.CompareAndSwap(false, true) {
+			log.Printf("⏭️  Worker %s: ciclo precedente ancora in esecuzione, salto esecuzione a valle di %s", next.config.Name, upstream)
+			continue
+		}
+		log.Printf("🔗 Worker %s: esecuzione a valle di %s", next.config.Name, upstream)
+		wm.runCycle(next.config, next.state)
+	}
+}
+
+// dependenciesCompletedLocked verifica che ogni dipendenza abbia completato un ciclo dopo l'ultimo avvio del worker
+func (wm *WorkerManager) dependenciesCompletedLocked(config *WorkerConfig, state *workerState) bool {
+	state.mu.Lock()
+	lastRun := state.lastRun
+	state.mu.Unlock()
+
+	for _, dependency := range config.DependsOn {
+		upstream, ok := wm.states[dependency]
+		if !ok {
+			return false
+		}
+		upstream.mu.Lock()
+		completed := upstream.lastComplete
+		upstream.mu.Unlock()
+		if completed.IsZero() || !completed.After(lastRun) {
+			return false
+		}
+	}
+	return true
+}
+
+// PauseWorker sospende le esecuzioni pianificate di un worker rimuovendone l'entry dal cron
+// Un ciclo già in corso viene completato
+func (wm *WorkerManager) PauseWorker(name string) error {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	state, err := wm.stateLocked(name)
+	if err != nil {
+		return err
+	}
+	if state.paused {
+		return fmt.Errorf("%w: worker %s già in pausa", api.ErrWorkerConflict, name)
+	}
+
+	if state.entryID != 0 {
+		wm.cron.Remove(state.entryID)
+	}
+	state.paused = true
+	log.Printf("⏸️  Worker %s in pausa", name)
+	return nil
+}
+
+// ResumeWorker riprende le esecuzioni pianificate di un worker in pausa registrando una nuova entry sul cron
+func (wm *WorkerManager) ResumeWorker(name string) error {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	state, err := wm.stateLocked(name)
+	if err != nil {
+		return err
+	}
+	if !state.paused {
+		return fmt.Errorf("%w: worker %s non è in pausa", api.ErrWorkerConflict, name)
+	}
+
+	if config := wm.workers[name]; config.Schedule != "" {
+		entryID, err := wm.addEntry(config, state)
+		if err != nil {
+			return fmt.Errorf("errore aggiunta job cron per worker %s: %w", name, err)
+		}
+		state.entryID = entryID
+	}
+	state.paused = false
+	log.Printf("▶️  Worker %s ripreso", name)
+	return nil
+}
+
+// RunWorkerNow avvia subito un ciclo fuori schedule, senza sfasamento né jitter
+// Funziona anche sui worker in pausa; fallisce se un ciclo è già in esecuzione
+func (wm *WorkerManager) RunWorkerNow(name string) error {
+	wm.mutex.RLock()
+	defer wm.mutex.RUnlock()
 
+	state, err := wm.stateLocked(name)
+	if err != nil {
+		return err
+	}
+	if wm.ctx.Err() != nil {
+		return fmt.Errorf("%w: WorkerManager fermato", api.ErrWorkerConflict)
+	}
+	if !state.running.CompareAndSwap(false, true) {
+		return fmt.Errorf("%w: worker %s già in esecuzione", api.ErrWorkerConflict, name)
+	}
+
+	log.Printf("⚡ Worker %s: esecuzione manuale richiesta", name)
+	go wm.runCycle(wm.workers[name], state)
 	return nil
 }
 
+// Workers restituisce lo stato di tutti i worker registrati ordinati per nome
+func (wm *WorkerManager) Workers() []api.WorkerStatus {
+	wm.mutex.RLock()
+	defer wm.mutex.RUnlock()
+
+	statuses := make([]api.WorkerStatus, 0, len(wm.workers))
+	for name, config := range wm.workers {
+		status := api.WorkerStatus{
+			Name:        name,
+			Description: config.Description,
+			Schedule:    config.Schedule,
+			Enabled:     config.Enabled,
+			DependsOn:   config.DependsOn,
+			LeaderOnly:  config.LeaderOnly,
+			Standby:     config.LeaderOnly && wm.election != nil && !wm.election.isLeader(),
+		}
+		if state, ok := wm.states[name]; ok {
+			status.Schedule = state.label
+			status.Paused = state.paused
+			status.Running = state.running.Load()
+			if !state.paused && wm.isRunning && state.entryID != 0 {
+				if next := wm.cron.Entry(state.entryID).Next; !next.IsZero() {
+					status.NextRun = &next
+				}
+			}
+			state.mu.Lock()
+			if !state.lastRun.IsZero() {
+				lastRun := state.lastRun
+				status.LastRun = &lastRun
+				status.LastDuration = state.lastDuration.String()
+			}
+			state.mu.Unlock()
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// stateLocked restituisce lo stato runtime di un worker abilitato; richiede il lock del manager
+func (wm *WorkerManager) stateLocked(name string) (*workerState, error) {
+	config, exists := wm.workers[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", api.ErrWorkerNotFound, name)
+	}
+	state, ok := wm.states[name]
+	if !ok || !config.Enabled {
+		return nil, fmt.Errorf("%w: worker %s disabilitato", api.ErrWorkerConflict, name)
+	}
+	return state, nil
+}
+
+// waitSpread attende lo sfasamento del worker più un ritardo casuale fino a jitter
+// Restituisce false se il manager viene fermato durante l'attesa
+func (wm *WorkerManager) waitSpread(offset, jitter time.Duration) bool {
+	delay := offset
+	if jitter > 0 {
+		delay += time.Duration(wm.jitterSrc.Int64N(int64(jitter)))
+	}
+	if delay <= 0 {
+		select {
+		case <-wm.ctx.Done():
+			return false
+		default:
+			return true
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-wm.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
 // RemoveWorker rimuove un worker dal sistema
 func (wm *WorkerManager) RemoveWorker(name string) error {
 	wm.mutex.Lock()
@@ -123,16 +602,32 @@ func (wm *WorkerManager) RemoveWorker(name string) error {
 		return fmt.Errorf("worker %s non trovato", name)
 	}
 
-	// Ferma il worker
+	// Rimuovi il job dal cron e ferma il worker
+	if state, ok := wm.states[name]; ok {
+		if !state.paused && state.entryID != 0 {
+			wm.cron.Remove(state.entryID)
+		}
+		delete(wm.states, name)
+	}
 	config.Worker.Stop()
 
 	// Rimuovi dal map
 	delete(wm.workers, name)
+	wm.order = slices.DeleteFunc(wm.order, func(registered string) bool { return registered == name })
 
 	log.Printf("🗑️  Worker %s rimosso", name)
 	return nil
 }
 
+// AddShutdownHook registra una funzione da eseguire in fase di arresto, dopo i worker
+// Gli hook vengono eseguiti in ordine inverso rispetto alla registrazione
+func (wm *WorkerManager) AddShutdownHook(hook func()) {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	wm.hooks = append(wm.hooks, hook)
+}
+
 // Start avvia il WorkerManager e tutti i worker registrati
 func (wm *WorkerManager) Start() {
 	wm.mutex.Lock()
@@ -152,7 +647,11 @@ func (wm *WorkerManager) Start() {
 	for name, config := range wm.workers {
 		if config.Enabled {
 			enabledCount++
-			log.Printf("   ✅ %s: %s (Schedule: %s)", name, config.Description, config.Schedule)
+			if len(config.DependsOn) > 0 {
+				log.Printf("   ✅ %s: %s (Schedule: %s, a valle di %v)", name, config.Description, wm.states[name].label, config.DependsOn)
+			} else {
+				log.Printf("   ✅ %s: %s (Schedule: %s)", name, config.Description, wm.states[name].label)
+			}
 		} else {
 			log.Printf("   ⚠️  %s: %s (DISABILITATO)", name, config.Description)
 		}
@@ -164,6 +663,22 @@ func (wm *WorkerManager) Start() {
 		return
 	}
 
+	// Rinnova in background i lease acquisiti dai worker
+	if wm.leases != nil {
+		go wm.leases.renewLoop(wm.ctx)
+	}
+
+	// Controlla cicli bloccati e componenti senza attività
+	if wm.watchdog != nil {
+		go wm.watch(wm.ctx)
+	}
+
+	// Il ruolo va stabilito prima del primo ciclo, poi l'heartbeat prosegue in background
+	if wm.election != nil {
+		wm.election.heartbeat(wm.ctx)
+		go wm.election.campaign(wm.ctx)
+	}
+
 	// Avvia il cron
 	wm.cron.Start()
 	log.Printf("✅ WorkerManager avviato con %d worker attivi", enabledCount)
@@ -183,6 +698,9 @@ func (wm *WorkerManager) Stop() {
 
 	log.Println("🛑 Arresto WorkerManager...")
 
+	// Cancella il context: le esecuzioni in attesa di sfasamento o jitter vengono saltate
+	wm.cancel()
+
 	// Ferma il cron
 	ctx := wm.cron.Stop()
 	select {
@@ -198,8 +716,16 @@ func (wm *WorkerManager) Stop() {
 		config.Worker.Stop()
 	}
 
-	// Cancella il context
-	wm.cancel()
+	// Rilascia i lease prima di chiudere il database, così un'altra istanza subentra subito
+	if wm.leases != nil {
+		wm.leases.releaseAll()
+	}
+
+	// Esegui gli hook di arresto (l'ultimo registrato per primo)
+	for i := len(wm.hooks) - 1; i >= 0; i-- {
+		wm.hooks[i]()
+	}
+
 	wm.isRunning = false
 
 	log.Println("✅ WorkerManager fermato")
@@ -235,29 +761,242 @@ func (wm *WorkerManager) setupGracefulShutdown() {
 // ===================================================================
 
 // InitializeWorkers configura e avvia tutti i worker del sistema
-func InitializeWorkers() *WorkerManager {
+func InitializeWorkers(deps *SystemDependencies) *WorkerManager {
 	log.Println("🔧 Inizializzazione sistema worker...")
 
 	// Crea il WorkerManager
 	manager := NewWorkerManager()
+	manager.SetExecutionSpread(deps.Config.Workers.Jitter, deps.Config.Workers.Stagger, deps.Simulation.Derive("workers"))
+	if deps.Config.Workers.Mode == config.WorkerModeCandleClose {
+		manager.SetCandleCloseTrigger(deps.Config.Workers.CandleCloseDelay)
+	}
+	manager.SetErrorReporter(deps.Errors)
+	if watchdog := deps.Config.Watchdog; watchdog.Enabled {
+		manager.SetWatchdog(watchdog.Interval, watchdog.WorkerTimeout)
+		// Il WebSocket dei prezzi Bybit può smettere di ricevere senza chiudersi: va riavviato, non letto
+		if stream, ok := deps.Exchange.(Monitored); ok {
+			if err := manager.Monitor("bybit-websocket", stream, watchdog.StreamTimeout); err != nil {
+				log.Printf("❌ Errore registrazione WebSocket nel watchdog: %v", err)
+			}
+		}
+	}
+	if lock := deps.Config.Lock; lock.Enabled {
+		manager.SetLocker(deps.Locker, lock.Account, lock.Owner, lock.TTL)
+		if lock.Standby {
+			if err := manager.EnableStandby(); err != nil {
+				log.Printf("❌ Errore attivazione hot standby: %v", err)
+			}
+		}
+	}
 
 	// ====================================================================
 	// 🔥 TRADING WORKERS
 	// ====================================================================
 
 	// Worker principale per il trading system DOGE
-	dogeWorker := NewDogeTradingSystemWorker()
+	dogeWorker := NewDogeTradingSystemWorker(deps)
 	dogeConfig := &WorkerConfig{
 		Name:        "doge-trading-system",
 		Schedule:    "0 0 * * * *", // Ogni ora al secondo 0
 		Worker:      dogeWorker,
 		Enabled:     true, // ✅ ABILITATO - Cambia a false per disabilitare
 		Description: "Sistema di trading automatico per DOGEUSDT",
+		LockKey:     "trading:DOGEUSDT",
+		LeaderOnly:  true,
+		Symbol:      "DOGEUSDT",
+		Timeframe:   models.Timeframe1m, // Candele analizzate dal worker
 	}
 
 	if err := manager.RegisterWorker(dogeConfig); err != nil {
 		log.Printf("❌ Errore registrazione DOGE worker: %v", err)
 	}
+
+	// Lo stop della posizione DOGE va sull'ingresso appena il PnL copre la soglia; il channel si chiude con il tracker
+	if deps.PositionPnL != nil && deps.Config.PnL.BreakEvenPct > 0 {
+		go dogeWorker.followBreakEven(deps.PositionPnL.Subscribe())
+		log.Printf("✅ Stop a break-even DOGE oltre il %.2f%% di PnL", deps.Config.PnL.BreakEvenPct*100)
+	}
+
+	// Worker per il funding arbitrage (long spot / short perpetual), attivo solo se configurato
+	if deps.Config.FundingArb.Enabled {
+		fundingWorker, err := NewFundingArbitrageWorker(deps)
+		if err != nil {
+			log.Printf("❌ Errore configurazione funding arbitrage worker: %v", err)
+		} else {
+			fundingConfig := &WorkerConfig{
+				Name:        "funding-arbitrage",
+				Schedule:    deps.Config.FundingArb.Schedule,
+				Worker:      fundingWorker,
+				Enabled:     true,
+				Description: "Funding arbitrage long spot / short perpetual tra due venue",
+				LockKey:     "trading:funding-arbitrage:" + deps.Config.FundingArb.Symbol,
+				LeaderOnly:  true,
+				Symbol:      deps.Config.FundingArb.Symbol,
+			}
+			if err := manager.RegisterWorker(fundingConfig); err != nil {
+				log.Printf("❌ Errore registrazione funding arbitrage worker: %v", err)
+			}
+		}
+	}
+
+	// Worker per la copertura del portafoglio con uno short sul perpetual indice, attivo solo se configurato
+	if deps.Config.Hedge.Enabled {
+		hedgeWorker, err := NewHedgeOverlayWorker(deps)
+		if err != nil {
+			log.Printf("❌ Errore configurazione hedge overlay worker: %v", err)
+		} else {
+			hedgeConfig := &WorkerConfig{
+				Name:        "hedge-overlay",
+				Schedule:    deps.Config.Hedge.Schedule,
+				Worker:      hedgeWorker,
+				Enabled:     true,
+				Description: "Short di copertura sul perpetual indice contro i long sulle altcoin durante il risk-off",
+				LockKey:     "trading:" + deps.Config.Hedge.Symbol,
+				LeaderOnly:  true,
+				Symbol:      deps.Config.Hedge.Symbol,
+			}
+			if err := manager.RegisterWorker(hedgeConfig); err != nil {
+				log.Printf("❌ Errore registrazione hedge overlay worker: %v", err)
+			}
+		}
+	}
+
+	// Worker per il rilevamento del regime di mercato, che attiva gli ingressi DOGE adatti al regime
+	if deps.Regimes != nil {
+		regimeWorker, err := NewRegimeWorker(deps)
+		if err != nil {
+			log.Printf("❌ Errore configurazione regime worker: %v", err)
+		} else {
+			regimeConfig := &WorkerConfig{
+				Name:        "regime",
+				Schedule:    deps.Config.Regime.Schedule,
+				Worker:      regimeWorker,
+				Enabled:     true,
+				Description: "Regime di mercato (trend o laterale) per simbolo con ADX o rapporto di varianza",
+				LeaderOnly:  true,
+				Timeframe:   models.Timeframe(deps.Config.Regime.Timeframe),
+			}
+			if err := manager.RegisterWorker(regimeConfig); err != nil {
+				log.Printf("❌ Errore registrazione regime worker: %v", err)
+			}
+		}
+	}
+
+	// Worker per il controllo dei margini tra le venue
+	balanceSyncConfig := &WorkerConfig{
+		Name:        "balance-sync",
+		Schedule:    deps.Config.BalanceSync.Schedule,
+		Worker:      NewBalanceSyncWorker(deps),
+		Enabled:     deps.Config.BalanceSync.Enabled,
+		Description: "Controllo del margine disponibile per venue e alert di ribilanciamento",
+		LockKey:     "balance-sync",
+		LeaderOnly:  true,
+	}
+
+	if err := manager.RegisterWorker(balanceSyncConfig); err != nil {
+		log.Printf("❌ Errore registrazione balance sync worker: %v", err)
+	}
+
+	// Worker per il ribilanciamento del capitale tra master e sub-account delle strategie
+	if deps.Config.Rebalance.Enabled {
+		rebalanceWorker, err := NewPortfolioRebalanceWorker(deps)
+		if err != nil {
+			log.Printf("❌ Errore configurazione portfolio rebalance worker: %v", err)
+		} else {
+			rebalanceConfig := &WorkerConfig{
+				Name:        "portfolio-rebalance",
+				Schedule:    deps.Config.Rebalance.Schedule,
+				Worker:      rebalanceWorker,
+				Enabled:     true,
+				Description: "Trasferimenti tra master e sub-account per mantenere i pesi obiettivo delle strategie",
+				LockKey:     "portfolio-rebalance",
+				LeaderOnly:  true,
+			}
+			if err := manager.RegisterWorker(rebalanceConfig); err != nil {
+				log.Printf("❌ Errore registrazione portfolio rebalance worker: %v", err)
+			}
+		}
+	}
+
+	// Worker per il rilevamento di ordini e posizioni non creati dal bot
+	if deps.Config.Watch.Enabled {
+		accountWatchWorker, err := NewAccountWatchWorker(deps)
+		if err != nil {
+			log.Printf("❌ Errore configurazione account watch worker: %v", err)
+		} else {
+			accountWatchConfig := &WorkerConfig{
+				Name:        "account-watch",
+				Schedule:    deps.Config.Watch.Schedule,
+				Worker:      accountWatchWorker,
+				Enabled:     true,
+				Description: "Confronto di ordini e posizioni aperti su Bybit con quelli del bot e alert sulle anomalie",
+				LockKey:     "account-watch",
+				LeaderOnly:  true,
+			}
+			if err := manager.RegisterWorker(accountWatchConfig); err != nil {
+				log.Printf("❌ Errore registrazione account watch worker: %v", err)
+			}
+		}
+	}
+
+	// Worker per il monitoraggio del basis spot/perpetual
+	basisConfig := &WorkerConfig{
+		Name:        "basis-tracker",
+		Schedule:    deps.Config.Basis.Schedule,
+		Worker:      NewBasisTrackerWorker(deps),
+		Enabled:     deps.Config.Basis.Enabled,
+		Description: "Serie storica del basis spot/perpetual Bybit e alert sulle soglie",
+	}
+
+	if err := manager.RegisterWorker(basisConfig); err != nil {
+		log.Printf("❌ Errore registrazione basis tracker worker: %v", err)
+	}
+
+	// Worker per l'importazione delle sorgenti dati esterne (sentiment)
+	dataIngestionConfig := &WorkerConfig{
+		Name:        "data-ingestion",
+		Schedule:    deps.Config.DataSources.Schedule,
+		Worker:      NewDataIngestionWorker(deps),
+		Enabled:     deps.Config.DataSources.Enabled,
+		Description: "Importazione giornaliera delle sorgenti dati esterne (Fear & Greed Index)",
+	}
+
+	if err := manager.RegisterWorker(dataIngestionConfig); err != nil {
+		log.Printf("❌ Errore registrazione data ingestion worker: %v", err)
+	}
+
+	// Worker per l'esecuzione dei job differiti (es. cancellazione degli ordini non eseguiti)
+	jobQueueConfig := &WorkerConfig{
+		Name:        "job-queue",
+		Schedule:    deps.Config.Jobs.Schedule,
+		Worker:      NewJobQueueWorker(deps),
+		Enabled:     deps.Config.Jobs.Enabled,
+		Description: "Esecuzione dei job differiti salvati nel database",
+		LeaderOnly:  true,
+	}
+
+	if err := manager.RegisterWorker(jobQueueConfig); err != nil {
+		log.Printf("❌ Errore registrazione job queue worker: %v", err)
+	}
+
+	// ====================================================================
+	// 🧹 MAINTENANCE WORKERS
+	// ====================================================================
+
+	// Worker per la pulizia dell'audit trail e l'ottimizzazione del database
+	maintenanceConfig := &WorkerConfig{
+		Name:        "database-maintenance",
+		Schedule:    "0 30 3 * * *", // Ogni giorno alle 3:30
+		Worker:      NewMaintenanceWorker(deps),
+		Enabled:     true,
+		Description: "Retention dell'audit trail e VACUUM/ANALYZE del database",
+		LeaderOnly:  true,
+	}
+
+	if err := manager.RegisterWorker(maintenanceConfig); err != nil {
+		log.Printf("❌ Errore registrazione maintenance worker: %v", err)
+	}
+
 	// CRON EXPRESSIONS UTILI:
 	// - "0 * * * * *"     = Ogni minuto
 	// - "0 */5 * * * *"   = Ogni 5 minuti
@@ -274,8 +1013,121 @@ func InitializeWorkers() *WorkerManager {
 func StartWorkerSystem() {
 	log.Println("🎯 === AVVIO SISTEMA WORKER TRADING ===")
 
-	// Inizializza e avvia il sistema
-	manager := InitializeWorkers()
+	// Carica la configurazione
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("❌ Errore nel caricamento della configurazione: %v", err)
+	}
+	i18n.SetLocale(cfg.Locale)
+
+	// Dipendenze condivise tra worker e API
+	deps, err := NewSystemDependencies(cfg)
+	if err != nil {
+		log.Fatalf("❌ ERRORE CRITICO: %v", err)
+	}
+
+	// Verifica permessi e scadenza della API key prima di operare
+	if err := runPreflightChecks(deps); err != nil {
+		deps.Close()
+		log.Fatalf("❌ ERRORE CRITICO: %v", err)
+	}
+
+	// Ordini condizionali lasciati aperti da un'esecuzione precedente interrotta
+	runOrphanSweep(deps)
+
+	// Inizializza il sistema
+	manager := InitializeWorkers(deps)
+	manager.AddShutdownHook(deps.Close)
+
+	// Avvia l'aggregatore dei prezzi tra venue, usato dalle REST API e dal rilevamento degli arbitraggi
+	if deps.PriceAggregator != nil {
+		aggregatorCtx, stopAggregator := context.WithCancel(context.Background())
+		deps.PriceAggregator.Start(aggregatorCtx)
+		manager.AddShutdownHook(stopAggregator)
+	}
+
+	// Avvia il rilevamento degli arbitraggi al netto di fee, slippage e latenza
+	if cfg.Arbitrage.Enabled {
+		detectorCtx, stopDetector := context.WithCancel(context.Background())
+		startArbitrageDetector(detectorCtx, deps)
+		manager.AddShutdownHook(stopDetector)
+	}
+
+	// Avvia gli stream dei trade pubblici da cui le strategie leggono il delta dell'order flow
+	if deps.OrderFlow != nil {
+		flowCtx, stopFlow := context.WithCancel(context.Background())
+		for _, symbol := range cfg.OrderFlow.Symbols {
+			deps.OrderFlow.Run(flowCtx, book.NewBybitLinearTradeStreamer(), symbol)
+		}
+		manager.AddShutdownHook(stopFlow)
+		log.Printf("✅ Order flow avviato su %v (finestra %s)", cfg.OrderFlow.Symbols, cfg.OrderFlow.Window)
+	}
+
+	// Avvia gli stream delle liquidazioni, raggruppate in cluster per le strategie e le REST API
+	if deps.Liquidations != nil {
+		liquidationCtx, stopLiquidations := context.WithCancel(context.Background())
+		for _, symbol := range cfg.Liquidation.Symbols {
+			deps.Liquidations.Run(liquidationCtx, book.NewBybitLinearLiquidationStreamer(), symbol)
+		}
+		manager.AddShutdownHook(stopLiquidations)
+		log.Printf("✅ Feed delle liquidazioni avviato su %v", cfg.Liquidation.Symbols)
+	}
+
+	// Avvia il ricalcolo del PnL delle posizioni sul mark price, pubblicato alle REST API e salvato a intervalli regolari
+	if deps.PositionPnL != nil {
+		pnlCtx, stopPnL := context.WithCancel(context.Background())
+		deps.PositionPnL.Start(pnlCtx)
+		for _, symbol := range deps.PositionPnL.Symbols() {
+			book.FollowMarkPrice(pnlCtx, book.NewBybitLinearTickerStreamer(), symbol, func(mark book.MarkPrice) {
+				deps.PositionPnL.UpdateMark(mark.Symbol, mark.Price, mark.Time)
+			})
+		}
+		manager.AddShutdownHook(stopPnL)
+		log.Printf("✅ PnL delle posizioni avviato su %v (snapshot ogni %s)", cfg.PnL.Symbols, cfg.PnL.SnapshotInterval)
+	}
+
+	// Avvia le REST API
+	if cfg.API.Enabled {
+		server := api.NewServer(cfg.API.Addr, deps.OrderService, deps.ReportService, deps.PriceAggregator)
+		server.SetWorkerController(manager)
+		server.SetErrorReporter(deps.Errors)
+		server.SetRiskManager(deps.Risk)
+		server.SetFeatureFlags(deps.Features)
+		server.SetLevels(deps.Levels)
+		server.SetLiquidations(deps.Liquidations)
+		server.SetPositionPnL(deps.PositionPnL)
+		server.SetCandleQuality(deps.Quality)
+		server.SetFees(deps.Fees)
+		if cfg.Charts.Enabled {
+			server.SetTradeCharts(cfg.Charts.Dir)
+		}
+		server.SetQueryConsole(deps.RepoManager.Query(), cfg.API.QueryToken)
+		credentials := make([]api.Credential, 0, len(cfg.API.Tokens))
+		for _, token := range cfg.API.Tokens {
+			credentials = append(credentials, api.Credential{Name: token.Name, Role: api.Role(token.Role), Token: token.Token})
+		}
+		server.SetCredentials(credentials)
+		server.SetOperatorAudit(services.NewOperatorAuditService(deps.RepoManager))
+		if cfg.API.PublicStatus {
+			server.SetPublicStatus(cfg.API.PublicStatusRate)
+			log.Printf("📣 Pagina di stato pubblica abilitata su /public/status (%d richieste al minuto per IP)", cfg.API.PublicStatusRate)
+		}
+		if !server.AuthEnabled() && !isLoopbackAddr(cfg.API.Addr) {
+			log.Printf("⚠️  REST API senza autenticazione in ascolto su %s: configurare API_TOKENS prima di esporla oltre localhost", cfg.API.Addr)
+		}
+		if account, ok := deps.AccountReader.(api.AccountView); ok {
+			server.SetAccount(account)
+		}
+		server.Start()
+		manager.AddShutdownHook(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := server.Shutdown(ctx); err != nil {
+				log.Printf("⚠️  Errore arresto server API: %v", err)
+			}
+		})
+	}
+
 	manager.Start()
 
 	// Il sistema rimarrà in esecuzione fino a ricevere un segnale di stop
@@ -285,3 +1137,17 @@ func StartWorkerSystem() {
 	// Mantieni il programma in esecuzione
 	select {}
 }
+
+// isLoopbackAddr indica se l'indirizzo di ascolto accetta solo connessioni locali (es. 127.0.0.1:8080)
+// Un host vuoto (":8080") ascolta su tutte le interfacce
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}